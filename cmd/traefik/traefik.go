@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	stdlog "log"
@@ -14,12 +15,14 @@ import (
 	"github.com/containous/traefik/autogen/genstatic"
 	"github.com/containous/traefik/cmd"
 	"github.com/containous/traefik/cmd/healthcheck"
+	"github.com/containous/traefik/cmd/validate"
 	cmdVersion "github.com/containous/traefik/cmd/version"
 	"github.com/containous/traefik/pkg/cli"
 	"github.com/containous/traefik/pkg/collector"
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/config/static"
 	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/provider/acme"
 	"github.com/containous/traefik/pkg/provider/aggregator"
 	"github.com/containous/traefik/pkg/safe"
 	"github.com/containous/traefik/pkg/server"
@@ -69,6 +72,12 @@ Complete documentation is available at https://traefik.io`,
 		os.Exit(1)
 	}
 
+	err = cmdTraefik.AddCommand(validate.NewCmd())
+	if err != nil {
+		stdlog.Println(err)
+		os.Exit(1)
+	}
+
 	err = cli.Execute(cmdTraefik)
 	if err != nil {
 		stdlog.Println(err)
@@ -112,14 +121,18 @@ func runCmd(staticConfiguration *static.Configuration, configFile string) error
 
 	providerAggregator := aggregator.NewProviderAggregator(*staticConfiguration.Providers)
 
-	acmeProvider, err := staticConfiguration.InitACMEProvider()
+	var acmeProviders []*acme.Provider
+	providers, err := staticConfiguration.InitACMEProviders()
 	if err != nil {
 		log.WithoutContext().Errorf("Unable to initialize ACME provider: %v", err)
-	} else if acmeProvider != nil {
+	}
+
+	for _, acmeProvider := range providers {
 		if err := providerAggregator.AddProvider(acmeProvider); err != nil {
 			log.WithoutContext().Errorf("Unable to add ACME provider to the providers list: %v", err)
-			acmeProvider = nil
+			continue
 		}
+		acmeProviders = append(acmeProviders, acmeProvider)
 	}
 
 	serverEntryPointsTCP := make(server.TCPEntryPoints)
@@ -129,26 +142,41 @@ func runCmd(staticConfiguration *static.Configuration, configFile string) error
 		if err != nil {
 			return fmt.Errorf("error while building entryPoint %s: %v", entryPointName, err)
 		}
-		serverEntryPointsTCP[entryPointName].RouteAppenderFactory = router.NewRouteAppenderFactory(*staticConfiguration, entryPointName, acmeProvider)
+		serverEntryPointsTCP[entryPointName].RouteAppenderFactory = router.NewRouteAppenderFactory(*staticConfiguration, entryPointName, acmeProviders)
 
 	}
 
 	tlsManager := traefiktls.NewManager()
 
-	if acmeProvider != nil {
+	var tlsAlpnGetters []func(string) (*tls.Certificate, error)
+	for _, acmeProvider := range acmeProviders {
 		acmeProvider.SetTLSManager(tlsManager)
 		if acmeProvider.TLSChallenge != nil &&
 			acmeProvider.HTTPChallenge == nil &&
 			acmeProvider.DNSChallenge == nil {
-			tlsManager.TLSAlpnGetter = acmeProvider.GetTLSALPNCertificate
+			tlsAlpnGetters = append(tlsAlpnGetters, acmeProvider.GetTLSALPNCertificate)
+		}
+	}
+
+	if len(tlsAlpnGetters) > 0 {
+		tlsManager.TLSAlpnGetter = func(domain string) (*tls.Certificate, error) {
+			for _, getter := range tlsAlpnGetters {
+				cert, err := getter(domain)
+				if err != nil || cert != nil {
+					return cert, err
+				}
+			}
+			return nil, nil
 		}
 	}
 
 	svr := server.NewServer(*staticConfiguration, providerAggregator, serverEntryPointsTCP, tlsManager)
 
-	if acmeProvider != nil && acmeProvider.OnHostRule {
-		acmeProvider.SetConfigListenerChan(make(chan config.Configuration))
-		svr.AddListener(acmeProvider.ListenConfiguration)
+	for _, acmeProvider := range acmeProviders {
+		if acmeProvider.ListensToRouters() {
+			acmeProvider.SetConfigListenerChan(make(chan config.Configuration))
+			svr.AddListener(acmeProvider.ListenConfiguration)
+		}
 	}
 	ctx := cmd.ContextWithSignal(context.Background())
 
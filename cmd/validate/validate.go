@@ -0,0 +1,48 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/containous/traefik/pkg/cli"
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/provider/file"
+)
+
+// Configuration holds the options of the validate command.
+type Configuration struct {
+	File string `description:"Dynamic configuration file to validate."`
+}
+
+// NewCmd builds a new Validate command.
+func NewCmd() *cli.Command {
+	configuration := &Configuration{}
+
+	return &cli.Command{
+		Name:          "validate",
+		Description:   `Validates a dynamic configuration file, as loaded by the file provider.`,
+		Configuration: configuration,
+		Run:           runCmd(configuration),
+		Resources:     []cli.ResourceLoader{&cli.FlagLoader{}},
+	}
+}
+
+func runCmd(configuration *Configuration) func(_ []string) error {
+	return func(_ []string) error {
+		if configuration.File == "" {
+			return errors.New("the file to validate must be set with --file")
+		}
+
+		errs := file.Validate(configuration.File, &config.Configuration{})
+		for _, err := range errs {
+			fmt.Printf("%s: %v\n", configuration.File, err)
+		}
+
+		if len(errs) > 0 {
+			return fmt.Errorf("%d problem(s) found in %s", len(errs), configuration.File)
+		}
+
+		fmt.Printf("OK: %s\n", configuration.File)
+		return nil
+	}
+}
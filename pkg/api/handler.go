@@ -8,6 +8,7 @@ import (
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/config/static"
 	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/provider/file"
 	"github.com/containous/traefik/pkg/types"
 	"github.com/containous/traefik/pkg/version"
 	assetfs "github.com/elazarl/go-bindata-assetfs"
@@ -32,6 +33,7 @@ type RunTimeRepresentation struct {
 	Services    map[string]*serviceInfoRepresentation `json:"services,omitempty"`
 	TCPRouters  map[string]*config.TCPRouterInfo      `json:"tcpRouters,omitempty"`
 	TCPServices map[string]*config.TCPServiceInfo     `json:"tcpServices,omitempty"`
+	FileErrors  map[string]file.FileError             `json:"fileErrors,omitempty"`
 }
 
 // Handler serves the configuration and status of Traefik on API endpoints.
@@ -40,7 +42,9 @@ type Handler struct {
 	debug     bool
 	// runtimeConfiguration is the data set used to create all the data representations exposed by the API.
 	runtimeConfiguration *config.RuntimeConfiguration
-	statistics           *types.Statistics
+	// fileErrors returns, per file, the errors of the configuration files of the file provider failing to load.
+	fileErrors func() map[string]file.FileError
+	statistics *types.Statistics
 	// stats                *thoasstats.Stats // FIXME stats
 	// StatsRecorder         *middlewares.StatsRecorder // FIXME stats
 	dashboardAssets *assetfs.AssetFS
@@ -54,13 +58,19 @@ func New(staticConfig static.Configuration, runtimeConfig *config.RuntimeConfigu
 		rConfig = &config.RuntimeConfiguration{}
 	}
 
-	return &Handler{
+	handler := &Handler{
 		dashboard:            staticConfig.API.Dashboard,
 		statistics:           staticConfig.API.Statistics,
 		dashboardAssets:      staticConfig.API.DashboardAssets,
 		runtimeConfiguration: rConfig,
 		debug:                staticConfig.Global.Debug,
 	}
+
+	if staticConfig.Providers != nil && staticConfig.Providers.File != nil {
+		handler.fileErrors = staticConfig.Providers.File.FileErrors
+	}
+
+	return handler
 }
 
 // Append add api routes on a router
@@ -99,6 +109,10 @@ func (h Handler) getRuntimeConfiguration(rw http.ResponseWriter, request *http.R
 		TCPServices: h.runtimeConfiguration.TCPServices,
 	}
 
+	if h.fileErrors != nil {
+		rtRepr.FileErrors = h.fileErrors()
+	}
+
 	err := templateRenderer.JSON(rw, http.StatusOK, rtRepr)
 	if err != nil {
 		log.FromContext(request.Context()).Error(err)
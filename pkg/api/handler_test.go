@@ -6,11 +6,14 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/containous/mux"
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/config/static"
+	"github.com/containous/traefik/pkg/provider/file"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -170,3 +173,45 @@ func TestHandler_Configuration(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_FileErrors(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "traefik-api")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	filename := filepath.Join(tempDir, "dynamic.toml")
+	err = ioutil.WriteFile(filename, []byte("[http.routers\n"), 0644)
+	require.NoError(t, err)
+
+	fileProvider := &file.Provider{Filename: filename}
+	require.NoError(t, fileProvider.Init())
+
+	_, err = fileProvider.BuildConfiguration()
+	require.Error(t, err)
+
+	staticConfig := static.Configuration{
+		API:       &static.API{},
+		Global:    &static.Global{},
+		Providers: &static.Providers{File: fileProvider},
+	}
+	handler := New(staticConfig, &config.RuntimeConfiguration{})
+	router := mux.NewRouter()
+	handler.Append(router)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.DefaultClient.Get(server.URL + "/api/rawdata")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var rtRepr RunTimeRepresentation
+	err = json.NewDecoder(resp.Body).Decode(&rtRepr)
+	require.NoError(t, err)
+
+	require.Contains(t, rtRepr.FileErrors, filename)
+	assert.Contains(t, rtRepr.FileErrors[filename].Error, filename)
+	assert.False(t, rtRepr.FileErrors[filename].Since.IsZero())
+}
@@ -91,14 +91,13 @@ func run(cmd *Command, args []string) error {
 		return cmd.Run(args)
 	}
 
+	// Every resource is loaded, in order, over the options set by the previous ones:
+	// with a file, an environment variables and a flags loader, the flags win over the environment variables,
+	// which win over the file.
 	for _, resource := range cmd.Resources {
-		done, err := resource.Load(args, cmd)
-		if err != nil {
+		if _, err := resource.Load(args, cmd); err != nil {
 			return err
 		}
-		if done {
-			break
-		}
 	}
 
 	return cmd.Run(args)
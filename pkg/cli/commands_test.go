@@ -571,7 +571,7 @@ func Test_execute_configuration_file(t *testing.T) {
 		},
 	}
 
-	element := &Yo{
+	element := &Yc{
 		Fuu: "test",
 	}
 
@@ -592,13 +592,68 @@ func Test_execute_configuration_file(t *testing.T) {
 	err = execute(rootCmd, args, true)
 	require.NoError(t, err)
 
-	expected := &Yo{
-		Foo: "bar",
-		Fii: "bir",
+	expected := &Yc{
+		ConfigFile: "./fixtures/config.toml",
+		Foo:        "bar",
+		Fii:        "bir",
+		Fuu:        "test",
+		Yi: &Yi{
+			Foo: "foo",
+			Fii: "fii",
+		},
+	}
+	assert.Equal(t, expected, element)
+}
+
+func Test_execute_configuration_precedence(t *testing.T) {
+	rootCmd := &Command{
+		Name:          "root",
+		Description:   "This is a test",
+		Configuration: nil,
+		Run: func(_ []string) error {
+			return nil
+		},
+	}
+
+	element := &Yc{
 		Fuu: "test",
+	}
+
+	sub1 := &Command{
+		Name:          "sub1",
+		Description:   "sub1",
+		Configuration: element,
+		Resources:     []ResourceLoader{&FileLoader{}, &EnvLoader{}, &FlagLoader{}},
+		Run: func(args []string) error {
+			return nil
+		},
+	}
+	err := rootCmd.AddCommand(sub1)
+	require.NoError(t, err)
+
+	require.NoError(t, os.Setenv("TRAEFIK_FII", "env"))
+	require.NoError(t, os.Setenv("TRAEFIK_YI_FUU", "env"))
+	defer func() {
+		_ = os.Unsetenv("TRAEFIK_FII")
+		_ = os.Unsetenv("TRAEFIK_YI_FUU")
+	}()
+
+	args := []string{"", "sub1", "--configFile=./fixtures/config.toml", "--yi.fuu=flag"}
+
+	err = execute(rootCmd, args, true)
+	require.NoError(t, err)
+
+	// foo comes from the file, fii from the environment variables, which win over the file,
+	// and yi.fuu from the flags, which win over the environment variables.
+	expected := &Yc{
+		ConfigFile: "./fixtures/config.toml",
+		Foo:        "bar",
+		Fii:        "env",
+		Fuu:        "test",
 		Yi: &Yi{
 			Foo: "foo",
 			Fii: "fii",
+			Fuu: "flag",
 		},
 	}
 	assert.Equal(t, expected, element)
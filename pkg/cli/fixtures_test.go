@@ -23,3 +23,12 @@ func (y *Yi) SetDefaults() {
 	y.Foo = "foo"
 	y.Fii = "fii"
 }
+
+// Yc holds the flag of its configuration file, as the flags are loaded along with the file.
+type Yc struct {
+	ConfigFile string
+	Foo        string
+	Fii        string
+	Fuu        string
+	Yi         *Yi `label:"allowEmpty"`
+}
@@ -2,7 +2,9 @@ package cli
 
 // ResourceLoader is a configuration resource loader.
 type ResourceLoader interface {
-	// Load populates cmd.Configuration, optionally using args to do so.
+	// Load populates cmd.Configuration, optionally using args to do so,
+	// over the options already set by the previous resources.
+	// It tells whether the resource held any configuration.
 	Load(args []string, cmd *Command) (bool, error)
 }
 
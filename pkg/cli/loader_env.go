@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/containous/traefik/pkg/config/env"
+	"github.com/containous/traefik/pkg/config/parser"
 	"github.com/containous/traefik/pkg/log"
 )
 
@@ -30,7 +31,7 @@ func (*EnvLoader) load(environ []string, cmd *Command) (bool, error) {
 		return false, nil
 	}
 
-	if err := env.Decode(environ, cmd.Configuration); err != nil {
+	if err := env.DecodeWithOpts(environ, cmd.Configuration, parser.DecodeOpts{CollectErrors: true}); err != nil {
 		return false, fmt.Errorf("failed to decode configuration from environment variables: %v", err)
 	}
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/containous/traefik/pkg/config/file"
 	"github.com/containous/traefik/pkg/config/flag"
+	"github.com/containous/traefik/pkg/config/parser"
 	"github.com/containous/traefik/pkg/log"
 )
 
@@ -71,7 +72,7 @@ func loadConfigFiles(configFile string, element interface{}) (string, error) {
 		return "", nil
 	}
 
-	if err = file.Decode(filePath, element); err != nil {
+	if err = file.DecodeWithOpts(filePath, element, parser.DecodeOpts{CollectErrors: true}); err != nil {
 		return "", err
 	}
 	return filePath, nil
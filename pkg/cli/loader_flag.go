@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/containous/traefik/pkg/config/flag"
+	"github.com/containous/traefik/pkg/config/parser"
 	"github.com/containous/traefik/pkg/log"
 )
 
@@ -12,7 +13,11 @@ type FlagLoader struct{}
 
 // Load loads the command's configuration from flag arguments.
 func (*FlagLoader) Load(args []string, cmd *Command) (bool, error) {
-	if err := flag.Decode(args, cmd.Configuration); err != nil {
+	if len(args) == 0 {
+		return false, nil
+	}
+
+	if err := flag.DecodeWithOpts(args, cmd.Configuration, parser.DecodeOpts{CollectErrors: true}); err != nil {
 		return false, fmt.Errorf("failed to decode configuration from flags: %v", err)
 	}
 
@@ -0,0 +1,414 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/config/static"
+	"github.com/containous/traefik/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type durationsConfiguration struct {
+	ForwardingTimeouts *static.ForwardingTimeouts
+	Interval           time.Duration
+}
+
+func TestLoaders_durations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traefik-loader-test")
+	require.NoError(t, err)
+
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	loaders := map[string]func(element interface{}, dialTimeout, interval string) error{
+		"flags": func(element interface{}, dialTimeout, interval string) error {
+			args := []string{"--forwardingtimeouts.dialtimeout=" + dialTimeout, "--interval=" + interval}
+			_, err := (&FlagLoader{}).Load(args, &Command{Configuration: element})
+			return err
+		},
+		"environment variables": func(element interface{}, dialTimeout, interval string) error {
+			environ := []string{"TRAEFIK_FORWARDINGTIMEOUTS_DIALTIMEOUT=" + dialTimeout, "TRAEFIK_INTERVAL=" + interval}
+			_, err := (&EnvLoader{}).load(environ, &Command{Configuration: element})
+			return err
+		},
+		"TOML file": func(element interface{}, dialTimeout, interval string) error {
+			path := writeFile("traefik.toml", "interval = \""+interval+"\"\n[forwardingTimeouts]\n  dialTimeout = \""+dialTimeout+"\"\n")
+			_, err := loadConfigFiles(path, element)
+			return err
+		},
+		"YAML file": func(element interface{}, dialTimeout, interval string) error {
+			path := writeFile("traefik.yml", "interval: "+interval+"\nforwardingTimeouts:\n  dialTimeout: "+dialTimeout+"\n")
+			_, err := loadConfigFiles(path, element)
+			return err
+		},
+	}
+
+	testCases := []struct {
+		desc             string
+		dialTimeout      string
+		interval         string
+		expectedTimeout  time.Duration
+		expectedInterval time.Duration
+		expectedError    string
+	}{
+		{
+			desc:             "duration strings",
+			dialTimeout:      "1m30s",
+			interval:         "500ms",
+			expectedTimeout:  90 * time.Second,
+			expectedInterval: 500 * time.Millisecond,
+		},
+		{
+			desc:             "numbers of seconds",
+			dialTimeout:      "42",
+			interval:         "10",
+			expectedTimeout:  42 * time.Second,
+			expectedInterval: 10 * time.Second,
+		},
+		{
+			desc:          "invalid duration",
+			dialTimeout:   "foobar",
+			interval:      "10s",
+			expectedError: `invalid duration "foobar": a duration such as 10s or 1m30s, or a number of seconds, is expected`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		for source, load := range loaders {
+			load := load
+			t.Run(test.desc+" from "+source, func(t *testing.T) {
+				element := &durationsConfiguration{}
+
+				err := load(element, test.dialTimeout, test.interval)
+
+				if test.expectedError != "" {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), test.expectedError)
+					return
+				}
+
+				require.NoError(t, err)
+				require.NotNil(t, element.ForwardingTimeouts)
+				assert.Equal(t, test.expectedTimeout, time.Duration(element.ForwardingTimeouts.DialTimeout))
+				assert.Equal(t, test.expectedInterval, element.Interval)
+			})
+		}
+	}
+}
+
+type defaultsConfiguration struct {
+	MaxConn      *config.MaxConn
+	LoadBalancer *config.LoadBalancerService
+}
+
+func TestLoaders_defaults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traefik-loader-test")
+	require.NoError(t, err)
+
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	// The options are set to the given values, or left to their defaults when the values are empty.
+	loaders := map[string]func(element interface{}, extractorFunc, passHostHeader string) error{
+		"flags": func(element interface{}, extractorFunc, passHostHeader string) error {
+			args := []string{"--maxconn.amount=10", "--loadbalancer.sticky.cookie.name=foo"}
+			if extractorFunc != "" {
+				args = append(args, "--maxconn.extractorfunc="+extractorFunc)
+			}
+			if passHostHeader != "" {
+				args = append(args, "--loadbalancer.passhostheader="+passHostHeader)
+			}
+			_, err := (&FlagLoader{}).Load(args, &Command{Configuration: element})
+			return err
+		},
+		"environment variables": func(element interface{}, extractorFunc, passHostHeader string) error {
+			environ := []string{"TRAEFIK_MAXCONN_AMOUNT=10", "TRAEFIK_LOADBALANCER_STICKY_COOKIE_NAME=foo"}
+			if extractorFunc != "" {
+				environ = append(environ, "TRAEFIK_MAXCONN_EXTRACTORFUNC="+extractorFunc)
+			}
+			if passHostHeader != "" {
+				environ = append(environ, "TRAEFIK_LOADBALANCER_PASSHOSTHEADER="+passHostHeader)
+			}
+			_, err := (&EnvLoader{}).load(environ, &Command{Configuration: element})
+			return err
+		},
+		"TOML file": func(element interface{}, extractorFunc, passHostHeader string) error {
+			content := "[maxConn]\n  amount = 10\n"
+			if extractorFunc != "" {
+				content += "  extractorFunc = \"" + extractorFunc + "\"\n"
+			}
+			content += "[loadBalancer]\n"
+			if passHostHeader != "" {
+				content += "  passHostHeader = " + passHostHeader + "\n"
+			}
+			content += "  [loadBalancer.sticky.cookie]\n    name = \"foo\"\n"
+			_, err := loadConfigFiles(writeFile("traefik.toml", content), element)
+			return err
+		},
+		"YAML file": func(element interface{}, extractorFunc, passHostHeader string) error {
+			content := "maxConn:\n  amount: 10\n"
+			if extractorFunc != "" {
+				content += "  extractorFunc: " + extractorFunc + "\n"
+			}
+			content += "loadBalancer:\n"
+			if passHostHeader != "" {
+				content += "  passHostHeader: " + passHostHeader + "\n"
+			}
+			content += "  sticky:\n    cookie:\n      name: foo\n"
+			_, err := loadConfigFiles(writeFile("traefik.yml", content), element)
+			return err
+		},
+	}
+
+	testCases := []struct {
+		desc                   string
+		extractorFunc          string
+		passHostHeader         string
+		expectedExtractorFunc  string
+		expectedPassHostHeader bool
+	}{
+		{
+			desc:                   "defaults",
+			expectedExtractorFunc:  "request.host",
+			expectedPassHostHeader: true,
+		},
+		{
+			desc:                   "explicit values",
+			extractorFunc:          "client.ip",
+			passHostHeader:         "false",
+			expectedExtractorFunc:  "client.ip",
+			expectedPassHostHeader: false,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		for source, load := range loaders {
+			load := load
+			t.Run(test.desc+" from "+source, func(t *testing.T) {
+				element := &defaultsConfiguration{}
+
+				err := load(element, test.extractorFunc, test.passHostHeader)
+				require.NoError(t, err)
+
+				require.NotNil(t, element.MaxConn)
+				assert.Equal(t, int64(10), element.MaxConn.Amount)
+				assert.Equal(t, test.expectedExtractorFunc, element.MaxConn.ExtractorFunc)
+
+				require.NotNil(t, element.LoadBalancer)
+				assert.Equal(t, test.expectedPassHostHeader, element.LoadBalancer.PassHostHeader)
+			})
+		}
+	}
+}
+
+type namedConfiguration struct {
+	EntryPoints map[string]*static.EntryPoint
+	Domains     []types.Domain
+}
+
+func TestLoaders_namedAndIndexed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traefik-loader-test")
+	require.NoError(t, err)
+
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	loaders := map[string]func(element interface{}) error{
+		"flags": func(element interface{}) error {
+			args := []string{
+				"--entrypoints.web.address=:80",
+				"--entrypoints.websecure.address=:443",
+				"--domains[0].main=foo.com",
+				"--domains[0].sans=www.foo.com,api.foo.com",
+				"--domains[1].main=bar.com",
+			}
+			_, err := (&FlagLoader{}).Load(args, &Command{Configuration: element})
+			return err
+		},
+		"environment variables": func(element interface{}) error {
+			environ := []string{
+				"TRAEFIK_ENTRYPOINTS_WEB_ADDRESS=:80",
+				"TRAEFIK_ENTRYPOINTS_WEBSECURE_ADDRESS=:443",
+				"TRAEFIK_DOMAINS_0_MAIN=foo.com",
+				"TRAEFIK_DOMAINS_0_SANS=www.foo.com,api.foo.com",
+				"TRAEFIK_DOMAINS[1]_MAIN=bar.com",
+			}
+			_, err := (&EnvLoader{}).load(environ, &Command{Configuration: element})
+			return err
+		},
+		"TOML file": func(element interface{}) error {
+			content := `
+[entryPoints.web]
+  address = ":80"
+[entryPoints.websecure]
+  address = ":443"
+
+[[domains]]
+  main = "foo.com"
+  sans = ["www.foo.com", "api.foo.com"]
+[[domains]]
+  main = "bar.com"
+`
+			_, err := loadConfigFiles(writeFile("traefik.toml", content), element)
+			return err
+		},
+		"YAML file": func(element interface{}) error {
+			content := `
+entryPoints:
+  web:
+    address: ":80"
+  websecure:
+    address: ":443"
+domains:
+  - main: foo.com
+    sans: [www.foo.com, api.foo.com]
+  - main: bar.com
+`
+			_, err := loadConfigFiles(writeFile("traefik.yml", content), element)
+			return err
+		},
+	}
+
+	for source, load := range loaders {
+		load := load
+		t.Run(source, func(t *testing.T) {
+			element := &namedConfiguration{}
+
+			err := load(element)
+			require.NoError(t, err)
+
+			require.Len(t, element.EntryPoints, 2)
+			require.NotNil(t, element.EntryPoints["web"])
+			assert.Equal(t, ":80", element.EntryPoints["web"].Address)
+			require.NotNil(t, element.EntryPoints["websecure"])
+			assert.Equal(t, ":443", element.EntryPoints["websecure"].Address)
+
+			expected := []types.Domain{
+				{Main: "foo.com", SANs: []string{"www.foo.com", "api.foo.com"}},
+				{Main: "bar.com"},
+			}
+			assert.Equal(t, expected, element.Domains)
+		})
+	}
+}
+
+type byteQuantitiesConfiguration struct {
+	MaxBodyBytes int64 `unit:"bytes"`
+}
+
+func TestLoaders_byteQuantities(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traefik-loader-test")
+	require.NoError(t, err)
+
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	loaders := map[string]func(element interface{}, maxBodyBytes string) error{
+		"flags": func(element interface{}, maxBodyBytes string) error {
+			_, err := (&FlagLoader{}).Load([]string{"--maxbodybytes=" + maxBodyBytes}, &Command{Configuration: element})
+			return err
+		},
+		"environment variables": func(element interface{}, maxBodyBytes string) error {
+			_, err := (&EnvLoader{}).load([]string{"TRAEFIK_MAXBODYBYTES=" + maxBodyBytes}, &Command{Configuration: element})
+			return err
+		},
+		"TOML file": func(element interface{}, maxBodyBytes string) error {
+			_, err := loadConfigFiles(writeFile("traefik.toml", "maxBodyBytes = \""+maxBodyBytes+"\"\n"), element)
+			return err
+		},
+		"YAML file": func(element interface{}, maxBodyBytes string) error {
+			_, err := loadConfigFiles(writeFile("traefik.yml", "maxBodyBytes: "+maxBodyBytes+"\n"), element)
+			return err
+		},
+	}
+
+	testCases := []struct {
+		desc          string
+		maxBodyBytes  string
+		expected      int64
+		expectedError string
+	}{
+		{
+			desc:         "number of bytes",
+			maxBodyBytes: "1024",
+			expected:     1024,
+		},
+		{
+			desc:         "SI suffix",
+			maxBodyBytes: "10MB",
+			expected:     10000000,
+		},
+		{
+			desc:         "binary suffix",
+			maxBodyBytes: "10Mi",
+			expected:     10485760,
+		},
+		{
+			desc:          "negative",
+			maxBodyBytes:  "-1KiB",
+			expectedError: `MaxBodyBytes: invalid byte quantity "-1KiB": a byte quantity cannot be negative`,
+		},
+		{
+			desc:          "overflow",
+			maxBodyBytes:  "9000PiB",
+			expectedError: `MaxBodyBytes: invalid byte quantity "9000PiB": it overflows the maximum of 9223372036854775807 bytes`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		for source, load := range loaders {
+			load := load
+			t.Run(test.desc+" from "+source, func(t *testing.T) {
+				element := &byteQuantitiesConfiguration{}
+
+				err := load(element, test.maxBodyBytes)
+
+				if test.expectedError != "" {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), test.expectedError)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, element.MaxBodyBytes)
+			})
+		}
+	}
+}
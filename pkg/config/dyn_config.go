@@ -1,35 +1,107 @@
 package config
 
 import (
+	"context"
 	"crypto/tls"
-	"crypto/x509"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"os"
 	"reflect"
+	"strings"
+	"time"
 
+	"github.com/containous/traefik/pkg/config/parser"
 	traefiktls "github.com/containous/traefik/pkg/tls"
+	"github.com/containous/traefik/pkg/types"
 )
 
 // Router holds the router configuration.
 type Router struct {
-	EntryPoints []string         `json:"entryPoints"`
-	Middlewares []string         `json:"middlewares,omitempty" toml:",omitempty"`
-	Service     string           `json:"service,omitempty" toml:",omitempty"`
-	Rule        string           `json:"rule,omitempty" toml:",omitempty"`
-	Priority    int              `json:"priority,omitempty" toml:"priority,omitzero"`
-	TLS         *RouterTLSConfig `json:"tls,omitempty" toml:"tls,omitzero" label:"allowEmpty"`
+	Description               string           `json:"description,omitempty" toml:",omitempty"`
+	EntryPoints               []string         `json:"entryPoints"`
+	Middlewares               []string         `json:"middlewares,omitempty" toml:",omitempty"`
+	Service                   string           `json:"service,omitempty" toml:",omitempty"`
+	Rule                      string           `json:"rule,omitempty" toml:",omitempty"`
+	Priority                  int              `json:"priority,omitempty" toml:"priority,omitzero"`
+	TLS                       *RouterTLSConfig `json:"tls,omitempty" toml:"tls,omitzero" label:"allowEmpty"`
+	SkipEntryPointMiddlewares bool             `json:"skipEntryPointMiddlewares,omitempty" toml:",omitempty"`
+	Fallback                  bool             `json:"fallback,omitempty" toml:",omitempty"`
+}
+
+// Validate checks the Router configuration.
+// A fallback router serves the requests which match no other router of its entry points, so it has no rule.
+func (r *Router) Validate() error {
+	if r.Fallback {
+		if strings.TrimSpace(r.Rule) != "" {
+			return errors.New("a fallback router must not have a rule")
+		}
+		return nil
+	}
+
+	if strings.TrimSpace(r.Rule) == "" {
+		return errors.New("rule must not be empty")
+	}
+	return nil
+}
+
+// Mergeable tells if the given router is mergeable, i.e. if both routers only differ by their description.
+func (r *Router) Mergeable(router *Router) bool {
+	a, b := *r, *router
+	a.Description, b.Description = "", ""
+
+	return reflect.DeepEqual(a, b)
 }
 
 // RouterTLSConfig holds the TLS configuration for a router
-type RouterTLSConfig struct{}
+type RouterTLSConfig struct {
+	// Options is the name of the TLS options used for the hosts of the router,
+	// the TLS options of another provider being referenced by their qualified name, e.g. file.modern.
+	Options string `json:"options,omitempty" toml:"options,omitzero"`
+	// CertResolver is the name of the certificates resolver which obtains the certificates of the router,
+	// for its domains, or for the domains of its rule.
+	CertResolver string         `json:"certResolver,omitempty" toml:"certResolver,omitzero"`
+	Domains      []types.Domain `json:"domains,omitempty" toml:"domains,omitzero"`
+}
 
 // TCPRouter holds the router configuration.
 type TCPRouter struct {
+	Description string              `json:"description,omitempty" toml:",omitempty"`
 	EntryPoints []string            `json:"entryPoints"`
 	Service     string              `json:"service,omitempty" toml:",omitempty"`
 	Rule        string              `json:"rule,omitempty" toml:",omitempty"`
 	TLS         *RouterTCPTLSConfig `json:"tls,omitempty" toml:"tls,omitzero" label:"allowEmpty"`
+	Fallback    bool                `json:"fallback,omitempty" toml:",omitempty"`
+}
+
+// Validate checks the TCPRouter configuration.
+// A fallback router serves the connections which match no other router of its entry points, so it has no rule.
+func (r *TCPRouter) Validate() error {
+	if r.Fallback && strings.TrimSpace(r.Rule) != "" {
+		return errors.New("a fallback router must not have a rule")
+	}
+	return nil
+}
+
+// Mergeable tells if the given router is mergeable, i.e. if both routers only differ by their description.
+func (r *TCPRouter) Mergeable(router *TCPRouter) bool {
+	a, b := *r, *router
+	a.Description, b.Description = "", ""
+
+	return reflect.DeepEqual(a, b)
+}
+
+// UDPRouter holds the UDP router configuration.
+type UDPRouter struct {
+	Description string   `json:"description,omitempty" toml:",omitempty"`
+	EntryPoints []string `json:"entryPoints"`
+	Service     string   `json:"service,omitempty" toml:",omitempty"`
+}
+
+// Mergeable tells if the given router is mergeable, i.e. if both routers only differ by their description.
+func (r *UDPRouter) Mergeable(router *UDPRouter) bool {
+	a, b := *r, *router
+	a.Description, b.Description = "", ""
+
+	return reflect.DeepEqual(a, b)
 }
 
 // RouterTCPTLSConfig holds the TLS configuration for a router
@@ -39,11 +111,112 @@ type RouterTCPTLSConfig struct {
 
 // LoadBalancerService holds the LoadBalancerService configuration.
 type LoadBalancerService struct {
-	Stickiness         *Stickiness         `json:"stickiness,omitempty" toml:",omitempty" label:"allowEmpty"`
+	Sticky             *Sticky             `json:"sticky,omitempty" toml:",omitempty"`
 	Servers            []Server            `json:"servers,omitempty" toml:",omitempty" label-slice-as-struct:"server"`
 	HealthCheck        *HealthCheck        `json:"healthCheck,omitempty" toml:",omitempty"`
-	PassHostHeader     bool                `json:"passHostHeader" toml:",omitempty"`
+	PassHostHeader     bool                `json:"passHostHeader" toml:",omitempty" default:"true"`
 	ResponseForwarding *ResponseForwarding `json:"forwardingResponse,omitempty" toml:",omitempty"`
+	// ServersTransport is the name of the servers transport used to reach the servers,
+	// the servers transport of another provider being referenced by its qualified name, e.g. file.mytransport.
+	// The default transport, of the static configuration, is used when it is empty.
+	ServersTransport string `json:"serversTransport,omitempty" toml:",omitempty"`
+}
+
+// WeightedService holds the configuration of a service splitting the requests between other services, by weight.
+type WeightedService struct {
+	Services []WeightedServiceRef `json:"services,omitempty" toml:",omitempty"`
+}
+
+// WeightedServiceRef references a service of a WeightedService, by its name, qualified with its provider
+// when it is defined by another provider, e.g. docker.my-service.
+// A service without weight has a weight of 1, and a service with a zero weight does not receive any request.
+type WeightedServiceRef struct {
+	Name   string `json:"name"`
+	Weight *int   `json:"weight,omitempty" toml:",omitempty"`
+}
+
+// Validate checks the WeightedService configuration.
+// The existence of the referenced services is checked when the service is built, once all the providers are merged.
+func (w *WeightedService) Validate() error {
+	if len(w.Services) == 0 {
+		return errors.New("at least one service is required")
+	}
+
+	for i, service := range w.Services {
+		if service.Name == "" {
+			return fmt.Errorf("the service [%d] has no name", i)
+		}
+		if service.Weight != nil && *service.Weight < 0 {
+			return fmt.Errorf("the weight of the service %s must be positive or zero: %d", service.Name, *service.Weight)
+		}
+	}
+
+	return nil
+}
+
+// Mirroring holds the configuration of a service sending the requests to a main service,
+// and a copy of a percentage of them to mirror services, whose responses are discarded.
+// The requests whose body is larger than MaxBodySize are not mirrored; there is no limit when it is not set.
+type Mirroring struct {
+	Service     string          `json:"service,omitempty" toml:",omitempty"`
+	MaxBodySize *int64          `json:"maxBodySize,omitempty" toml:",omitempty" unit:"bytes"`
+	Mirrors     []MirrorService `json:"mirrors,omitempty" toml:",omitempty"`
+}
+
+// Validate checks the Mirroring configuration.
+// The existence of the referenced services is checked when the service is built, once all the providers are merged.
+func (m *Mirroring) Validate() error {
+	if m.Service == "" {
+		return errors.New("the main service is required")
+	}
+
+	if m.MaxBodySize != nil && *m.MaxBodySize < 0 {
+		return fmt.Errorf("the maximum body size must be positive or zero: %d", *m.MaxBodySize)
+	}
+
+	for i, mirror := range m.Mirrors {
+		if mirror.Name == "" {
+			return fmt.Errorf("the mirror [%d] has no name", i)
+		}
+		if mirror.Percent < 0 || mirror.Percent > 100 {
+			return fmt.Errorf("the percentage of the mirror %s must be between 0 and 100: %d", mirror.Name, mirror.Percent)
+		}
+	}
+
+	return nil
+}
+
+// MirrorService references a mirror service of a Mirroring service, by its name,
+// and sets the percentage of the requests it receives a copy of.
+type MirrorService struct {
+	Name    string `json:"name"`
+	Percent int    `json:"percent,omitempty" toml:",omitempty"`
+}
+
+// Failover holds the configuration of a service sending the requests to a main service,
+// and to a fallback service while the health check of the main service reports all its servers down.
+// The requests go back to the main service as soon as one of its servers is up again.
+type Failover struct {
+	Service  string `json:"service,omitempty" toml:",omitempty"`
+	Fallback string `json:"fallback,omitempty" toml:",omitempty"`
+}
+
+// Validate checks the Failover configuration.
+// The existence of the referenced services is checked when the service is built, once all the providers are merged.
+func (f *Failover) Validate() error {
+	if f.Service == "" {
+		return errors.New("the main service is required")
+	}
+
+	if f.Fallback == "" {
+		return errors.New("the fallback service is required")
+	}
+
+	if f.Service == f.Fallback {
+		return fmt.Errorf("the fallback service must be different from the main service: %s", f.Service)
+	}
+
+	return nil
 }
 
 // TCPLoadBalancerService holds the LoadBalancerService configuration.
@@ -68,6 +241,19 @@ func (l *TCPLoadBalancerService) Mergeable(loadBalancer *TCPLoadBalancerService)
 	return reflect.DeepEqual(l, loadBalancer)
 }
 
+// UDPLoadBalancerService holds the UDP LoadBalancerService configuration.
+type UDPLoadBalancerService struct {
+	Servers []UDPServer `json:"servers,omitempty" toml:",omitempty" label-slice-as-struct:"server"`
+}
+
+// Mergeable tells if the given service is mergeable.
+func (l *UDPLoadBalancerService) Mergeable(loadBalancer *UDPLoadBalancerService) bool {
+	a, b := *l, *loadBalancer
+	a.Servers, b.Servers = nil, nil
+
+	return reflect.DeepEqual(a, b)
+}
+
 // Mergeable tells if the given service is mergeable.
 func (l *LoadBalancerService) Mergeable(loadBalancer *LoadBalancerService) bool {
 	savedServers := l.Servers
@@ -85,26 +271,67 @@ func (l *LoadBalancerService) Mergeable(loadBalancer *LoadBalancerService) bool
 	return reflect.DeepEqual(l, loadBalancer)
 }
 
-// SetDefaults Default values for a LoadBalancerService.
+// SetDefaults Default values for a LoadBalancerService, declared by the default tags of its fields.
 func (l *LoadBalancerService) SetDefaults() {
-	l.PassHostHeader = true
+	_ = parser.ApplyDefaults(l)
+}
+
+// Validate checks the LoadBalancerService configuration.
+func (l *LoadBalancerService) Validate() error {
+	if len(l.Servers) == 0 && l.Sticky == nil {
+		return errors.New("at least one server, or a sticky configuration, is required")
+	}
+	return nil
 }
 
 // ResponseForwarding holds configuration for the forward of the response.
+// FlushInterval is the interval between the flushes of the response to the client, 100ms by default.
+// A negative interval, e.g. -1, flushes the response immediately after each write.
+// The streamed responses, e.g. with the text/event-stream content type, are always flushed immediately.
 type ResponseForwarding struct {
 	FlushInterval string `json:"flushInterval,omitempty" toml:",omitempty"`
 }
 
-// Stickiness holds the stickiness configuration.
-type Stickiness struct {
-	CookieName     string `json:"cookieName,omitempty" toml:",omitempty"`
-	SecureCookie   bool   `json:"secureCookie,omitempty" toml:",omitempty"`
-	HTTPOnlyCookie bool   `json:"httpOnlyCookie,omitempty" toml:",omitempty"`
+// Validate checks the ResponseForwarding configuration.
+func (r *ResponseForwarding) Validate() error {
+	if r.FlushInterval == "" {
+		return nil
+	}
+
+	_, err := types.ParseDuration(r.FlushInterval)
+	return err
+}
+
+// Sticky holds the sticky sessions configuration.
+type Sticky struct {
+	Cookie *Cookie `json:"cookie,omitempty" toml:",omitempty" label:"allowEmpty"`
+}
+
+// Cookie holds the configuration of the cookie pinning the clients to a server.
+// Its name is generated from the name of the service when it is empty.
+// SameSite is either none, lax, or strict, and the cookie has no SameSite attribute when it is empty.
+type Cookie struct {
+	Name     string `json:"name,omitempty" toml:",omitempty"`
+	Secure   bool   `json:"secure,omitempty" toml:",omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty" toml:",omitempty"`
+	SameSite string `json:"sameSite,omitempty" toml:",omitempty"`
+}
+
+// Validate checks the Cookie configuration.
+func (c *Cookie) Validate() error {
+	switch strings.ToLower(c.SameSite) {
+	case "", "none", "lax", "strict":
+		return nil
+	default:
+		return fmt.Errorf("invalid sameSite %q: it must be none, lax, or strict", c.SameSite)
+	}
 }
 
 // Server holds the server configuration.
 type Server struct {
 	URL    string `json:"url" label:"-"`
+	Weight int    `json:"weight,omitempty" toml:",omitempty,omitzero"`
+	Stale  bool   `json:"stale,omitempty" toml:"-" label:"-"` // reused by the provider from a previous configuration
 	Scheme string `toml:"-" json:"-"`
 	Port   string `toml:"-" json:"-"`
 }
@@ -115,6 +342,12 @@ type TCPServer struct {
 	Port    string `toml:"-" json:"-"`
 }
 
+// UDPServer holds a UDP Server configuration
+type UDPServer struct {
+	Address string `json:"address" label:"-"`
+	Port    string `toml:"-" json:"-"`
+}
+
 // SetDefaults Default values for a Server.
 func (s *Server) SetDefaults() {
 	s.Scheme = "http"
@@ -133,73 +366,36 @@ type HealthCheck struct {
 	Headers  map[string]string `json:"headers,omitempty" toml:",omitempty"`
 }
 
+// ServersTransport holds the configuration of the connections to the servers of the load-balancers referencing it,
+// e.g. to the servers whose certificates are signed by a private authority, or which require a client certificate.
+type ServersTransport struct {
+	ServerName          string                     `json:"serverName,omitempty" toml:",omitempty"`
+	InsecureSkipVerify  bool                       `json:"insecureSkipVerify,omitempty" toml:",omitempty"`
+	RootCAs             []traefiktls.FileOrContent `json:"rootCAs,omitempty" toml:",omitempty"`
+	Certificates        traefiktls.Certificates    `json:"certificates,omitempty" toml:",omitempty"`
+	MaxIdleConnsPerHost int                        `json:"maxIdleConnsPerHost,omitempty" toml:",omitempty,omitzero"`
+	ForwardingTimeouts  *ForwardingTimeouts        `json:"forwardingTimeouts,omitempty" toml:",omitempty"`
+}
+
+// ForwardingTimeouts holds the timeouts of the requests forwarded to the servers.
+// A zero timeout means no timeout.
+type ForwardingTimeouts struct {
+	DialTimeout           types.Duration `json:"dialTimeout,omitempty" toml:",omitempty"`
+	ResponseHeaderTimeout types.Duration `json:"responseHeaderTimeout,omitempty" toml:",omitempty"`
+}
+
+// SetDefaults sets the default values.
+func (f *ForwardingTimeouts) SetDefaults() {
+	f.DialTimeout = types.Duration(30 * time.Second)
+}
+
 // CreateTLSConfig creates a TLS config from ClientTLS structures.
 func (clientTLS *ClientTLS) CreateTLSConfig() (*tls.Config, error) {
 	if clientTLS == nil {
 		return nil, nil
 	}
 
-	var err error
-	caPool := x509.NewCertPool()
-	clientAuth := tls.NoClientCert
-	if clientTLS.CA != "" {
-		var ca []byte
-		if _, errCA := os.Stat(clientTLS.CA); errCA == nil {
-			ca, err = ioutil.ReadFile(clientTLS.CA)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read CA. %s", err)
-			}
-		} else {
-			ca = []byte(clientTLS.CA)
-		}
-
-		if !caPool.AppendCertsFromPEM(ca) {
-			return nil, fmt.Errorf("failed to parse CA")
-		}
-
-		if clientTLS.CAOptional {
-			clientAuth = tls.VerifyClientCertIfGiven
-		} else {
-			clientAuth = tls.RequireAndVerifyClientCert
-		}
-	}
-
-	cert := tls.Certificate{}
-	_, errKeyIsFile := os.Stat(clientTLS.Key)
-
-	if !clientTLS.InsecureSkipVerify && (len(clientTLS.Cert) == 0 || len(clientTLS.Key) == 0) {
-		return nil, fmt.Errorf("TLS Certificate or Key file must be set when TLS configuration is created")
-	}
-
-	if len(clientTLS.Cert) > 0 && len(clientTLS.Key) > 0 {
-		if _, errCertIsFile := os.Stat(clientTLS.Cert); errCertIsFile == nil {
-			if errKeyIsFile == nil {
-				cert, err = tls.LoadX509KeyPair(clientTLS.Cert, clientTLS.Key)
-				if err != nil {
-					return nil, fmt.Errorf("failed to load TLS keypair: %v", err)
-				}
-			} else {
-				return nil, fmt.Errorf("tls cert is a file, but tls key is not")
-			}
-		} else {
-			if errKeyIsFile != nil {
-				cert, err = tls.X509KeyPair([]byte(clientTLS.Cert), []byte(clientTLS.Key))
-				if err != nil {
-					return nil, fmt.Errorf("failed to load TLS keypair: %v", err)
-
-				}
-			} else {
-				return nil, fmt.Errorf("TLS key is a file, but tls cert is not")
-			}
-		}
-	}
-
-	return &tls.Config{
-		Certificates:       []tls.Certificate{cert},
-		RootCAs:            caPool,
-		InsecureSkipVerify: clientTLS.InsecureSkipVerify,
-		ClientAuth:         clientAuth,
-	}, nil
+	return (*types.ClientTLS)(clientTLS).CreateTLSConfig(context.Background())
 }
 
 // Message holds configuration information exchanged between parts of traefik.
@@ -212,9 +408,52 @@ type Message struct {
 type Configuration struct {
 	HTTP       *HTTPConfiguration
 	TCP        *TCPConfiguration
+	UDP        *UDPConfiguration
 	TLS        []*traefiktls.Configuration `json:"-" label:"-"`
 	TLSOptions map[string]traefiktls.TLS
 	TLSStores  map[string]traefiktls.Store
+	Sources    *Sources `json:"-" toml:"-" label:"-"`
+}
+
+// Source locates the definition of an element in a configuration file.
+// The line is zero when it is unknown, e.g. for an element generated by a template.
+type Source struct {
+	File string `json:"file"`
+	Line int    `json:"line,omitempty"`
+}
+
+func (s Source) String() string {
+	if s.Line == 0 {
+		return s.File
+	}
+	return fmt.Sprintf("%s:%d", s.File, s.Line)
+}
+
+// Sources holds, per element name, where the elements of a configuration are defined,
+// for the providers reading the configuration from files.
+type Sources struct {
+	Routers           map[string]Source
+	Middlewares       map[string]Source
+	Services          map[string]Source
+	ServersTransports map[string]Source
+	TCPRouters        map[string]Source
+	TCPServices       map[string]Source
+	UDPRouters        map[string]Source
+	UDPServices       map[string]Source
+}
+
+// NewSources returns empty Sources.
+func NewSources() *Sources {
+	return &Sources{
+		Routers:           make(map[string]Source),
+		Middlewares:       make(map[string]Source),
+		Services:          make(map[string]Source),
+		ServersTransports: make(map[string]Source),
+		TCPRouters:        make(map[string]Source),
+		TCPServices:       make(map[string]Source),
+		UDPRouters:        make(map[string]Source),
+		UDPServices:       make(map[string]Source),
+	}
 }
 
 // Configurations is for currentConfigurations Map.
@@ -222,9 +461,10 @@ type Configurations map[string]*Configuration
 
 // HTTPConfiguration FIXME better name?
 type HTTPConfiguration struct {
-	Routers     map[string]*Router     `json:"routers,omitempty" toml:",omitempty"`
-	Middlewares map[string]*Middleware `json:"middlewares,omitempty" toml:",omitempty"`
-	Services    map[string]*Service    `json:"services,omitempty" toml:",omitempty"`
+	Routers           map[string]*Router           `json:"routers,omitempty" toml:",omitempty"`
+	Middlewares       map[string]*Middleware       `json:"middlewares,omitempty" toml:",omitempty"`
+	Services          map[string]*Service          `json:"services,omitempty" toml:",omitempty"`
+	ServersTransports map[string]*ServersTransport `json:"serversTransports,omitempty" toml:",omitempty"`
 }
 
 // TCPConfiguration FIXME better name?
@@ -233,12 +473,29 @@ type TCPConfiguration struct {
 	Services map[string]*TCPService `json:"services,omitempty" toml:",omitempty"`
 }
 
+// UDPConfiguration contains the UDP routers and services.
+type UDPConfiguration struct {
+	Routers  map[string]*UDPRouter  `json:"routers,omitempty" toml:",omitempty"`
+	Services map[string]*UDPService `json:"services,omitempty" toml:",omitempty"`
+}
+
 // Service holds a service configuration (can only be of one type at the same time).
 type Service struct {
+	Description  string               `json:"description,omitempty" toml:",omitempty"`
 	LoadBalancer *LoadBalancerService `json:"loadbalancer,omitempty" toml:",omitempty,omitzero"`
+	Weighted     *WeightedService     `json:"weighted,omitempty" toml:",omitempty,omitzero"`
+	Mirroring    *Mirroring           `json:"mirroring,omitempty" toml:",omitempty,omitzero"`
+	Failover     *Failover            `json:"failover,omitempty" toml:",omitempty,omitzero"`
 }
 
 // TCPService holds a tcp service configuration (can only be of one type at the same time).
 type TCPService struct {
+	Description  string                  `json:"description,omitempty" toml:",omitempty"`
 	LoadBalancer *TCPLoadBalancerService `json:"loadbalancer,omitempty" toml:",omitempty,omitzero"`
 }
+
+// UDPService holds a udp service configuration (can only be of one type at the same time).
+type UDPService struct {
+	Description  string                  `json:"description,omitempty" toml:",omitempty"`
+	LoadBalancer *UDPLoadBalancerService `json:"loadbalancer,omitempty" toml:",omitempty,omitzero"`
+}
@@ -13,17 +13,55 @@ import (
 // map -> tree of untyped nodes
 // untyped nodes -> nodes augmented with metadata such as kind (inferred from element)
 // "typed" nodes -> typed element
+// The environment variables share the tree of nodes of the labels and of the flags,
+// see nameToKey for the conversion of their names.
+// It stops at the first error, see DecodeWithOpts to collect all of them.
 func Decode(environ []string, element interface{}) error {
+	return DecodeWithOpts(environ, element, parser.DecodeOpts{})
+}
+
+// DecodeWithOpts decodes the given environment variables into the given element, with the given options.
+func DecodeWithOpts(environ []string, element interface{}, opts parser.DecodeOpts) error {
 	vars := make(map[string]string)
 	for _, evr := range environ {
 		n := strings.SplitN(evr, "=", 2)
-		if strings.HasPrefix(strings.ToUpper(n[0]), "TRAEFIK_") {
-			key := strings.ReplaceAll(strings.ToLower(n[0]), "_", ".")
-			vars[key] = n[1]
+		if len(n) == 2 && strings.HasPrefix(strings.ToUpper(n[0]), "TRAEFIK_") {
+			vars[nameToKey(n[0])] = n[1]
+		}
+	}
+
+	return parser.DecodeWithOpts(vars, element, opts)
+}
+
+// nameToKey converts the name of an environment variable to the key of a label,
+// e.g. TRAEFIK_ENTRYPOINTS_WEB_ADDRESS to traefik.entrypoints.web.address.
+// As the brackets are not allowed in the names of the environment variables by every tool,
+// a name made of digits is the index of an item of a slice:
+// TRAEFIK_ACME_DOMAINS_0_MAIN, as TRAEFIK_ACME_DOMAINS[0]_MAIN, is traefik.acme.domains[0].main.
+func nameToKey(name string) string {
+	var parts []string
+	for _, part := range strings.Split(strings.ToLower(name), "_") {
+		if len(parts) > 0 && isIndex(part) {
+			parts[len(parts)-1] += "[" + part + "]"
+			continue
 		}
+		parts = append(parts, part)
 	}
 
-	return parser.Decode(vars, element)
+	return strings.Join(parts, ".")
+}
+
+func isIndex(part string) bool {
+	if part == "" {
+		return false
+	}
+
+	for _, c := range part {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
 }
 
 // Encode encodes the configuration in element into the environment variables represented in the returned Flats.
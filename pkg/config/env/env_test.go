@@ -154,6 +154,66 @@ func TestDecode(t *testing.T) {
 				Foo: []string{"bar", "baz"},
 			},
 		},
+		{
+			desc:    "slice of struct with brackets",
+			environ: []string{"TRAEFIK_FOO[0]_NAME=bar", "TRAEFIK_FOO[1]_NAME=baz"},
+			element: &struct {
+				Foo []struct{ Name string }
+			}{},
+			expected: &struct {
+				Foo []struct{ Name string }
+			}{
+				Foo: []struct{ Name string }{{Name: "bar"}, {Name: "baz"}},
+			},
+		},
+		{
+			desc:    "slice of struct with indexes",
+			environ: []string{"TRAEFIK_FOO_1_NAME=baz", "TRAEFIK_FOO_0_NAME=bar", "TRAEFIK_FOO_0_VALUES=a,b"},
+			element: &struct {
+				Foo []struct {
+					Name   string
+					Values []string
+				}
+			}{},
+			expected: &struct {
+				Foo []struct {
+					Name   string
+					Values []string
+				}
+			}{
+				Foo: []struct {
+					Name   string
+					Values []string
+				}{{Name: "bar", Values: []string{"a", "b"}}, {Name: "baz"}},
+			},
+		},
+		{
+			desc:    "map of struct by name",
+			environ: []string{"TRAEFIK_ENTRYPOINTS_WEB_ADDRESS=:80", "TRAEFIK_ENTRYPOINTS_WEBSECURE_ADDRESS=:443"},
+			element: &struct {
+				EntryPoints map[string]*struct{ Address string }
+			}{},
+			expected: &struct {
+				EntryPoints map[string]*struct{ Address string }
+			}{
+				EntryPoints: map[string]*struct{ Address string }{
+					"web":       {Address: ":80"},
+					"websecure": {Address: ":443"},
+				},
+			},
+		},
+		{
+			desc:    "ignored variables",
+			environ: []string{"TRAEFIK_FOO=bar", "HOME=/root", "TRAEFIK"},
+			element: &struct {
+				Foo string
+			}{},
+			expected: &struct {
+				Foo string
+			}{
+				Foo: "bar",
+			},
+		},
 		{
 			desc:    "struct pointer value",
 			environ: []string{"TRAEFIK_FOO=true"},
@@ -10,7 +10,13 @@ import (
 // file contents -> tree of untyped nodes
 // untyped nodes -> nodes augmented with metadata such as kind (inferred from element)
 // "typed" nodes -> typed element
+// It stops at the first error, see DecodeWithOpts to collect all of them.
 func Decode(filePath string, element interface{}) error {
+	return DecodeWithOpts(filePath, element, parser.DecodeOpts{})
+}
+
+// DecodeWithOpts decodes the given configuration file into the given element, with the given options.
+func DecodeWithOpts(filePath string, element interface{}, opts parser.DecodeOpts) error {
 	if element == nil {
 		return nil
 	}
@@ -22,10 +28,5 @@ func Decode(filePath string, element interface{}) error {
 		return err
 	}
 
-	err = parser.AddMetadata(element, root)
-	if err != nil {
-		return err
-	}
-
-	return parser.Fill(element, root)
+	return parser.DecodeNode(root, element, opts)
 }
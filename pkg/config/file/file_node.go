@@ -1,10 +1,13 @@
 package file
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
 	"reflect"
+	"strconv"
 
 	"github.com/BurntSushi/toml"
 	"github.com/containous/traefik/pkg/config/parser"
@@ -36,8 +39,23 @@ func decodeFileToNode(filePath string, filters ...string) (*parser.Node, error)
 			return nil, err
 		}
 
+		err = NormalizeYAML(data)
+		if err != nil {
+			return nil, err
+		}
+
 		return decodeRawToNode(data, filters...)
 
+	case ".json":
+		// The numbers are kept as they are written, so that an integer does not go through a float.
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+
+		err = decoder.Decode(&data)
+		if err != nil {
+			return nil, err
+		}
+
 	default:
 		return nil, fmt.Errorf("unsupported file extension: %s", filePath)
 	}
@@ -45,6 +63,53 @@ func decodeFileToNode(filePath string, filters ...string) (*parser.Node, error)
 	return decodeRawToNode(data, filters...)
 }
 
+// NormalizeYAML converts, in place, the maps decoded by the YAML parser into maps with string keys.
+// The merge keys (<<) are already expanded by the parser, so that the merged fields are regular keys of the maps.
+func NormalizeYAML(data map[string]interface{}) error {
+	for key, value := range data {
+		normalized, err := normalizeYAMLValue(value, key)
+		if err != nil {
+			return err
+		}
+		data[key] = normalized
+	}
+
+	return nil
+}
+
+func normalizeYAMLValue(value interface{}, path string) (interface{}, error) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		normalized := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			name, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid key %v in %s: keys must be strings", key, path)
+			}
+
+			var err error
+			normalized[name], err = normalizeYAMLValue(item, path+"."+name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return normalized, nil
+
+	case []interface{}:
+		for i, item := range v {
+			var err error
+			v[i], err = normalizeYAMLValue(item, path+"["+strconv.Itoa(i)+"]")
+			if err != nil {
+				return nil, err
+			}
+		}
+		return v, nil
+
+	default:
+		return v, nil
+	}
+}
+
 func getRootFieldNames(element interface{}) []string {
 	if element == nil {
 		return nil
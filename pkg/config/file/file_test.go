@@ -1,10 +1,17 @@
 package file
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/config/parser"
+	"github.com/containous/traefik/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -74,3 +81,764 @@ yi: {}
 	}
 	assert.Equal(t, expected, element)
 }
+
+func TestDecode_JSON(t *testing.T) {
+	f, err := ioutil.TempFile("", "traefik-config-*.json")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+
+	_, err = f.Write([]byte(`
+{
+  "foo": "bar",
+  "fii": "bir",
+  "yi": {}
+}
+`))
+	require.NoError(t, err)
+
+	element := &Yo{
+		Fuu: "test",
+	}
+
+	err = Decode(f.Name(), element)
+	require.NoError(t, err)
+
+	expected := &Yo{
+		Foo: "bar",
+		Fii: "bir",
+		Fuu: "test",
+		Yi: &Yi{
+			Foo: "foo",
+			Fii: "fii",
+		},
+	}
+	assert.Equal(t, expected, element)
+}
+
+// The servers of a load balancer are given as a single server, as with the labels.
+func TestDecode_JSON_dynamicConfiguration(t *testing.T) {
+	f, err := ioutil.TempFile("", "traefik-config-*.json")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+
+	_, err = f.Write([]byte(`
+{
+  "http": {
+    "routers": {
+      "router0": {
+        "entryPoints": ["web"],
+        "middlewares": ["maxconn", "retry"],
+        "service": "service0",
+        "rule": "Host(` + "`example.com`" + `)",
+        "priority": 42
+      }
+    },
+    "middlewares": {
+      "maxconn": {
+        "maxConn": {
+          "amount": 9007199254740993
+        }
+      },
+      "retry": {
+        "retry": {
+          "attempts": 3
+        }
+      }
+    },
+    "services": {
+      "service0": {
+        "loadBalancer": {
+          "passHostHeader": true,
+          "server": {
+            "url": "http://10.0.0.1:80",
+            "weight": 10
+          }
+        }
+      }
+    }
+  }
+}
+`))
+	require.NoError(t, err)
+
+	element := &config.Configuration{}
+
+	err = Decode(f.Name(), element)
+	require.NoError(t, err)
+
+	expected := &config.Configuration{
+		HTTP: &config.HTTPConfiguration{
+			Routers: map[string]*config.Router{
+				"router0": {
+					EntryPoints: []string{"web"},
+					Middlewares: []string{"maxconn", "retry"},
+					Service:     "service0",
+					Rule:        "Host(`example.com`)",
+					Priority:    42,
+				},
+			},
+			Middlewares: map[string]*config.Middleware{
+				"maxconn": {
+					MaxConn: &config.MaxConn{
+						Amount:        9007199254740993,
+						ExtractorFunc: "request.host",
+					},
+				},
+				"retry": {
+					Retry: &config.Retry{
+						Attempts: 3,
+					},
+				},
+			},
+			Services: map[string]*config.Service{
+				"service0": {
+					LoadBalancer: &config.LoadBalancerService{
+						PassHostHeader: true,
+						Servers: []config.Server{
+							{URL: "http://10.0.0.1:80", Scheme: "http", Weight: 10},
+						},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, element)
+}
+
+func TestDecode_YAML_anchors(t *testing.T) {
+	f, err := ioutil.TempFile("", "traefik-config-*.yml")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+
+	_, err = f.Write([]byte(`
+x-defaults: &defaults
+  passHostHeader: false
+  healthCheck:
+    path: /health
+    interval: 10s
+  responseForwarding:
+    flushInterval: 1s
+
+http:
+  services:
+    service1:
+      loadBalancer:
+        <<: *defaults
+        server:
+          url: http://10.0.0.1:80
+    service2:
+      loadBalancer:
+        <<: *defaults
+        server:
+          url: http://10.0.0.2:80
+    service3:
+      loadBalancer:
+        <<: *defaults
+        healthCheck:
+          path: /ping
+        server:
+          url: http://10.0.0.3:80
+`))
+	require.NoError(t, err)
+
+	element := &config.Configuration{}
+
+	err = Decode(f.Name(), element)
+	require.NoError(t, err)
+
+	service := func(url string, healthCheck *config.HealthCheck) *config.Service {
+		return &config.Service{
+			LoadBalancer: &config.LoadBalancerService{
+				Servers:     []config.Server{{URL: url, Scheme: "http"}},
+				HealthCheck: healthCheck,
+				ResponseForwarding: &config.ResponseForwarding{
+					FlushInterval: "1s",
+				},
+			},
+		}
+	}
+
+	expected := &config.Configuration{
+		HTTP: &config.HTTPConfiguration{
+			Services: map[string]*config.Service{
+				"service1": service("http://10.0.0.1:80", &config.HealthCheck{Path: "/health", Interval: "10s"}),
+				"service2": service("http://10.0.0.2:80", &config.HealthCheck{Path: "/health", Interval: "10s"}),
+				// The keys of the service replace the merged ones.
+				"service3": service("http://10.0.0.3:80", &config.HealthCheck{Path: "/ping"}),
+			},
+		},
+	}
+	assert.Equal(t, expected, element)
+}
+
+func TestDecode_YAML_invalidKey(t *testing.T) {
+	f, err := ioutil.TempFile("", "traefik-config-*.yml")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+
+	_, err = f.Write([]byte(`
+http:
+  services:
+    service1:
+      loadBalancer:
+        servers:
+          - 80: http://10.0.0.1
+`))
+	require.NoError(t, err)
+
+	err = Decode(f.Name(), &config.Configuration{})
+	assert.EqualError(t, err, "invalid key 80 in http.services.service1.loadBalancer.servers[0]: keys must be strings")
+}
+
+func TestDecode_YAML_unknownField(t *testing.T) {
+	f, err := ioutil.TempFile("", "traefik-config-*.yml")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+
+	_, err = f.Write([]byte(`
+http:
+  services:
+    service1:
+      loadBalancer:
+        passHostHeaders: true
+        servers:
+          - url: http://10.0.0.1
+`))
+	require.NoError(t, err)
+
+	err = Decode(f.Name(), &config.Configuration{})
+	assert.EqualError(t, err, `HTTP.Services.service1.LoadBalancer: unknown field "passHostHeaders" (did you mean "passHostHeader"?)`)
+}
+
+// middlewareNames returns 20 middleware names, in an order which is neither sorted nor reverse sorted.
+func middlewareNames() []string {
+	var names []string
+	for i := 0; i < 20; i++ {
+		names = append(names, fmt.Sprintf("middleware%d", (i*7)%20))
+	}
+	return names
+}
+
+func TestDecode_middlewaresOrder(t *testing.T) {
+	names := middlewareNames()
+
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = `"` + name + `"`
+	}
+
+	var yamlList string
+	for _, name := range names {
+		yamlList += "\n        - " + name
+	}
+
+	testCases := []struct {
+		desc    string
+		ext     string
+		content string
+	}{
+		{
+			desc: "TOML",
+			ext:  "toml",
+			content: `
+[http.routers.router0]
+  rule = "Path(` + "`/`" + `)"
+  service = "service0"
+  middlewares = [` + strings.Join(quoted, ", ") + `]
+`,
+		},
+		{
+			desc: "YAML",
+			ext:  "yml",
+			content: `
+http:
+  routers:
+    router0:
+      rule: Path(` + "`/`" + `)
+      service: service0
+      middlewares:` + yamlList + `
+`,
+		},
+		{
+			desc: "JSON",
+			ext:  "json",
+			content: `
+{
+  "http": {
+    "routers": {
+      "router0": {
+        "rule": "Path(` + "`/`" + `)",
+        "service": "service0",
+        "middlewares": [` + strings.Join(quoted, ", ") + `]
+      }
+    }
+  }
+}
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := ioutil.TempFile("", "traefik-config-*."+test.ext)
+			require.NoError(t, err)
+			defer func() {
+				_ = os.Remove(f.Name())
+			}()
+
+			_, err = f.Write([]byte(test.content))
+			require.NoError(t, err)
+
+			element := &config.Configuration{}
+
+			err = Decode(f.Name(), element)
+			require.NoError(t, err)
+
+			require.Contains(t, element.HTTP.Routers, "router0")
+			assert.Equal(t, names, element.HTTP.Routers["router0"].Middlewares)
+		})
+	}
+}
+
+func TestDecode_tablesOrder(t *testing.T) {
+	names := middlewareNames()
+
+	var tomlTables, yamlList string
+	jsonTables := make([]string, len(names))
+	for i, name := range names {
+		tomlTables += fmt.Sprintf("\n[[chain]]\n  name = %q\n  middlewares = [%q, \"retry\"]\n", "chain-"+name, name)
+		yamlList += fmt.Sprintf("\n  - name: %s\n    middlewares:\n      - %s\n      - retry", "chain-"+name, name)
+		jsonTables[i] = fmt.Sprintf(`{"name": %q, "middlewares": [%q, "retry"]}`, "chain-"+name, name)
+	}
+
+	testCases := []struct {
+		desc    string
+		ext     string
+		content string
+	}{
+		{
+			desc:    "TOML",
+			ext:     "toml",
+			content: tomlTables,
+		},
+		{
+			desc:    "YAML",
+			ext:     "yml",
+			content: "chain:" + yamlList + "\n",
+		},
+		{
+			desc:    "JSON",
+			ext:     "json",
+			content: `{"chain": [` + strings.Join(jsonTables, ", ") + `]}`,
+		},
+	}
+
+	var expected []Chain
+	for _, name := range names {
+		expected = append(expected, Chain{Name: "chain-" + name, Middlewares: []string{name, "retry"}})
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := ioutil.TempFile("", "traefik-config-*."+test.ext)
+			require.NoError(t, err)
+			defer func() {
+				_ = os.Remove(f.Name())
+			}()
+
+			_, err = f.Write([]byte(test.content))
+			require.NoError(t, err)
+
+			element := &Chains{}
+
+			err = Decode(f.Name(), element)
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, element.Chain)
+		})
+	}
+}
+
+func TestDecode_encodeOrder(t *testing.T) {
+	var tomlContent, yamlRouters, yamlServices string
+	for _, name := range middlewareNames() {
+		tomlContent += fmt.Sprintf("\n[http.routers.%[1]s]\n  rule = \"Path(`/%[1]s`)\"\n  service = %[1]q\n", name)
+		tomlContent += fmt.Sprintf("\n[http.services.%s.loadBalancer.server]\n  url = \"http://10.0.0.1:80\"\n", name)
+		yamlRouters += fmt.Sprintf("\n    %[1]s:\n      rule: Path(`/%[1]s`)\n      service: %[1]s", name)
+		yamlServices += fmt.Sprintf("\n    %s:\n      loadBalancer:\n        server:\n          url: http://10.0.0.1:80", name)
+	}
+
+	contents := map[string]string{
+		"toml": tomlContent,
+		"yml":  "http:\n  routers:" + yamlRouters + "\n  services:" + yamlServices + "\n",
+	}
+
+	nodes := make(map[string]*parser.Node)
+	for ext, content := range contents {
+		f, err := ioutil.TempFile("", "traefik-config-*."+ext)
+		require.NoError(t, err)
+		defer func() {
+			_ = os.Remove(f.Name())
+		}()
+
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+
+		element := &config.Configuration{}
+		require.NoError(t, Decode(f.Name(), element))
+
+		nodes[ext], err = parser.EncodeToNode(element, true)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, nodes["toml"], nodes["yml"])
+
+	require.Len(t, nodes["toml"].Children, 1)
+	require.Len(t, nodes["toml"].Children[0].Children, 2)
+
+	routers := nodes["toml"].Children[0].Children[0]
+	var names []string
+	for _, child := range routers.Children {
+		names = append(names, child.Name)
+	}
+	assert.True(t, sort.StringsAreSorted(names), "routers not sorted: %v", names)
+}
+
+func TestDecode_raw(t *testing.T) {
+	type element struct {
+		Name   string
+		Config map[string]interface{} `label:"raw"`
+	}
+
+	expected := &element{
+		Name: "demo",
+		Config: map[string]interface{}{
+			"retries": int64(3),
+			"ratio":   0.5,
+			"enabled": true,
+			"headers": map[string]interface{}{"X-Foo": "bar"},
+			"rules": []interface{}{
+				map[string]interface{}{"path": "/foo"},
+				map[string]interface{}{"path": "/bar"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc    string
+		ext     string
+		content string
+	}{
+		{
+			desc: "TOML",
+			ext:  "toml",
+			content: `
+name = "demo"
+[config]
+  retries = 3
+  ratio = 0.5
+  enabled = true
+  [config.headers]
+    X-Foo = "bar"
+  [[config.rules]]
+    path = "/foo"
+  [[config.rules]]
+    path = "/bar"
+`,
+		},
+		{
+			desc: "YAML",
+			ext:  "yml",
+			content: `
+name: demo
+config:
+  retries: 3
+  ratio: 0.5
+  enabled: true
+  headers:
+    X-Foo: bar
+  rules:
+    - path: /foo
+    - path: /bar
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := ioutil.TempFile("", "traefik-config-*."+test.ext)
+			require.NoError(t, err)
+			defer func() {
+				_ = os.Remove(f.Name())
+			}()
+
+			_, err = f.Write([]byte(test.content))
+			require.NoError(t, err)
+
+			actual := &element{}
+			err = Decode(f.Name(), actual)
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestDecode_coercions(t *testing.T) {
+	type element struct {
+		Port    string
+		Ratio   string
+		Count   int
+		Small   int8
+		Big     uint64
+		Weight  float64
+		Enabled bool
+		Timeout types.Duration
+		Ports   []int
+		Names   []string
+	}
+
+	expected := &element{
+		Port:    "8080",
+		Ratio:   "0.5",
+		Count:   42,
+		Small:   -12,
+		Big:     4294967296,
+		Weight:  1.5,
+		Enabled: true,
+		Timeout: types.Duration(10 * time.Second),
+		Ports:   []int{80, 443},
+		Names:   []string{"foo", "bar"},
+	}
+
+	sources := map[string]string{
+		"toml": `
+port = 8080
+ratio = 0.5
+count = 42
+small = -12
+big = 4294967296
+weight = 1.5
+enabled = true
+timeout = 10
+ports = [80, 443]
+names = ["foo", "bar"]
+`,
+		"yml": `
+port: 8080
+ratio: 0.5
+count: 42
+small: -12
+big: 4294967296
+weight: 1.5
+enabled: TRUE
+timeout: 10s
+ports: [80, 443]
+names: [foo, bar]
+`,
+		"json": `{
+  "port": 8080,
+  "ratio": 0.5,
+  "count": 4.2e1,
+  "small": -12,
+  "big": 4294967296,
+  "weight": 1.5,
+  "enabled": true,
+  "timeout": 10,
+  "ports": [80, 443.0],
+  "names": ["foo", "bar"]
+}`,
+	}
+
+	for ext, content := range sources {
+		ext, content := ext, content
+		t.Run(ext, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := ioutil.TempFile("", "traefik-config-*."+ext)
+			require.NoError(t, err)
+			defer func() {
+				_ = os.Remove(f.Name())
+			}()
+
+			_, err = f.Write([]byte(content))
+			require.NoError(t, err)
+
+			actual := &element{}
+			err = Decode(f.Name(), actual)
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, actual)
+		})
+	}
+
+	t.Run("labels", func(t *testing.T) {
+		t.Parallel()
+
+		labels := map[string]string{
+			"traefik.port":    "8080",
+			"traefik.ratio":   "0.5",
+			"traefik.count":   "42",
+			"traefik.small":   "-12",
+			"traefik.big":     "4294967296",
+			"traefik.weight":  "1.5",
+			"traefik.enabled": "True",
+			"traefik.timeout": "10s",
+			"traefik.ports":   "80, 443",
+			"traefik.names":   "foo, bar",
+		}
+
+		actual := &element{}
+		err := parser.Decode(labels, actual)
+		require.NoError(t, err)
+
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func TestDecode_time(t *testing.T) {
+	type element struct {
+		NotAfter  time.Time
+		NotBefore time.Time
+		Renewals  []time.Time
+	}
+
+	expected := &element{
+		NotAfter:  time.Date(2019, 10, 16, 10, 0, 0, 0, time.FixedZone("", 2*60*60)),
+		NotBefore: time.Date(2019, 10, 16, 0, 0, 0, 0, time.UTC),
+		Renewals: []time.Time{
+			time.Date(2019, 9, 16, 8, 0, 0, 0, time.UTC),
+			time.Date(2019, 10, 1, 8, 30, 0, 0, time.UTC),
+		},
+	}
+
+	sources := map[string]string{
+		"toml": `
+notAfter = 2019-10-16T10:00:00+02:00
+notBefore = "2019-10-16"
+renewals = [2019-09-16T08:00:00Z, 2019-10-01T08:30:00Z]
+`,
+		"yml": `
+notAfter: "2019-10-16T10:00:00+02:00"
+notBefore: "2019-10-16"
+renewals: ["2019-09-16T08:00:00Z", "2019-10-01T08:30:00Z"]
+`,
+		"json": `{
+  "notAfter": "2019-10-16T10:00:00+02:00",
+  "notBefore": "2019-10-16",
+  "renewals": ["2019-09-16T08:00:00Z", "2019-10-01T08:30:00Z"]
+}`,
+	}
+
+	for ext, content := range sources {
+		ext, content := ext, content
+		t.Run(ext, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := ioutil.TempFile("", "traefik-config-*."+ext)
+			require.NoError(t, err)
+			defer func() {
+				_ = os.Remove(f.Name())
+			}()
+
+			_, err = f.Write([]byte(content))
+			require.NoError(t, err)
+
+			actual := &element{}
+			err = Decode(f.Name(), actual)
+			require.NoError(t, err)
+
+			assert.Equal(t, len(expected.Renewals), len(actual.Renewals))
+			assert.True(t, expected.NotAfter.Equal(actual.NotAfter), "NotAfter: %s", actual.NotAfter)
+			assert.True(t, expected.NotBefore.Equal(actual.NotBefore), "NotBefore: %s", actual.NotBefore)
+			for i, renewal := range actual.Renewals {
+				assert.True(t, expected.Renewals[i].Equal(renewal), "Renewals[%d]: %s", i, renewal)
+			}
+		})
+	}
+}
+
+func TestDecode_timeInvalid(t *testing.T) {
+	type element struct {
+		NotAfter time.Time
+	}
+
+	sources := map[string]string{
+		"toml": `notAfter = "16/10/2019"`,
+		"yml":  `notAfter: 16/10/2019`,
+		"json": `{"notAfter": "16/10/2019"}`,
+	}
+
+	for ext, content := range sources {
+		ext, content := ext, content
+		t.Run(ext, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := ioutil.TempFile("", "traefik-config-*."+ext)
+			require.NoError(t, err)
+			defer func() {
+				_ = os.Remove(f.Name())
+			}()
+
+			_, err = f.Write([]byte(content))
+			require.NoError(t, err)
+
+			err = Decode(f.Name(), &element{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `NotAfter: invalid time "16/10/2019"`)
+			assert.Contains(t, err.Error(), "RFC 3339")
+		})
+	}
+}
+
+func TestDecode_coercionsOutOfRange(t *testing.T) {
+	type element struct {
+		Small int8
+	}
+
+	sources := map[string]string{
+		"toml": "small = 128",
+		"yml":  "small: 128",
+		"json": `{"small": 1.28e2}`,
+	}
+
+	for ext, content := range sources {
+		ext, content := ext, content
+		t.Run(ext, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := ioutil.TempFile("", "traefik-config-*."+ext)
+			require.NoError(t, err)
+			defer func() {
+				_ = os.Remove(f.Name())
+			}()
+
+			_, err = f.Write([]byte(content))
+			require.NoError(t, err)
+
+			err = Decode(f.Name(), &element{})
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "Small: strconv.ParseInt: parsing")
+			assert.Contains(t, err.Error(), "value out of range")
+		})
+	}
+}
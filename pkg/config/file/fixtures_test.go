@@ -32,3 +32,12 @@ type Yu struct {
 type Ye struct {
 	*Yi
 }
+
+type Chains struct {
+	Chain []Chain
+}
+
+type Chain struct {
+	Name        string
+	Middlewares []string
+}
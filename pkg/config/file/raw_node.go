@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/containous/traefik/pkg/config/parser"
 )
@@ -37,42 +38,12 @@ func decodeRaw(node *parser.Node, vData reflect.Value, filters ...string) {
 			fallthrough
 		case reflect.Bool:
 			fallthrough
+		case reflect.Struct:
+			fallthrough
 		case reflect.String:
 			child.Value = getSimpleValue(value)
 		case reflect.Slice:
-			var values []string
-
-			for i := 0; i < value.Len(); i++ {
-				item := value.Index(i)
-				switch item.Kind() {
-				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-					fallthrough
-				case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-					fallthrough
-				case reflect.Bool:
-					fallthrough
-				case reflect.String:
-					fallthrough
-				case reflect.Map:
-					fallthrough
-				case reflect.Interface:
-					sValue := reflect.ValueOf(item.Interface())
-					if sValue.Kind() == reflect.Map {
-						ch := &parser.Node{
-							Name: "[" + strconv.Itoa(i) + "]",
-						}
-
-						child.Children = append(child.Children, ch)
-						decodeRaw(ch, sValue)
-					} else {
-						values = append(values, getSimpleValue(sValue))
-					}
-				default:
-					panic("Unsupported slice type: " + item.Kind().String())
-				}
-			}
-
-			child.Value = strings.Join(values, ",")
+			decodeRawSlice(child, value)
 		case reflect.Map:
 			decodeRaw(child, value)
 		default:
@@ -83,6 +54,49 @@ func decodeRaw(node *parser.Node, vData reflect.Value, filters ...string) {
 	}
 }
 
+// decodeRawSlice decodes the items of the slice, keeping their order.
+// The tables are children of the node, named after their index, in the order of the slice,
+// and the scalars are joined, in the order of the slice, in the value of the node.
+func decodeRawSlice(node *parser.Node, value reflect.Value) {
+	var values []string
+
+	for i := 0; i < value.Len(); i++ {
+		item := value.Index(i)
+		switch item.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			fallthrough
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			fallthrough
+		case reflect.Float32, reflect.Float64:
+			fallthrough
+		case reflect.Bool:
+			fallthrough
+		case reflect.Struct:
+			fallthrough
+		case reflect.String:
+			fallthrough
+		case reflect.Map:
+			fallthrough
+		case reflect.Interface:
+			sValue := reflect.ValueOf(item.Interface())
+			if sValue.Kind() == reflect.Map {
+				ch := &parser.Node{
+					Name: "[" + strconv.Itoa(i) + "]",
+				}
+
+				node.Children = append(node.Children, ch)
+				decodeRaw(ch, sValue)
+			} else {
+				values = append(values, getSimpleValue(sValue))
+			}
+		default:
+			panic("Unsupported slice type: " + item.Kind().String())
+		}
+	}
+
+	node.Value = strings.Join(values, ",")
+}
+
 func getSimpleValue(item reflect.Value) string {
 	switch item.Kind() {
 	case reflect.String:
@@ -92,9 +106,15 @@ func getSimpleValue(item reflect.Value) string {
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		return strconv.FormatUint(item.Uint(), 10)
 	case reflect.Float32, reflect.Float64:
-		return strings.TrimSuffix(strconv.FormatFloat(item.Float(), 'f', 6, 64), ".000000")
+		return strconv.FormatFloat(item.Float(), 'f', -1, item.Type().Bits())
 	case reflect.Bool:
 		return strconv.FormatBool(item.Bool())
+	case reflect.Struct:
+		// A native datetime of a TOML file.
+		if t, ok := item.Interface().(time.Time); ok {
+			return t.Format(time.RFC3339Nano)
+		}
+		panic("Unsupported Simple value type: " + item.Type().String())
 	default:
 		panic("Unsupported Simple value type: " + item.Kind().String())
 	}
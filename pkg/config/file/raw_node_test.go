@@ -285,6 +285,18 @@ func Test_decodeRawToNode(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "float64 slice",
+			data: map[string]interface{}{
+				"foo": []float64{1.5, 2},
+			},
+			expected: &parser.Node{
+				Name: "traefik",
+				Children: []*parser.Node{
+					{Name: "foo", Value: "1.5,2"},
+				},
+			},
+		},
 		{
 			desc: "interface (string) slice",
 			data: map[string]interface{}{
@@ -524,6 +536,34 @@ func Test_decodeRawToNode(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "slice struct, more than 10 items",
+			data: map[string]interface{}{
+				"foo": []map[string]interface{}{
+					{"field1": "K"}, {"field1": "J"}, {"field1": "I"}, {"field1": "H"},
+					{"field1": "G"}, {"field1": "F"}, {"field1": "E"}, {"field1": "D"},
+					{"field1": "C"}, {"field1": "B"}, {"field1": "A"},
+				},
+			},
+			expected: &parser.Node{
+				Name: "traefik",
+				Children: []*parser.Node{
+					{Name: "foo", Children: []*parser.Node{
+						{Name: "[0]", Children: []*parser.Node{{Name: "field1", Value: "K"}}},
+						{Name: "[1]", Children: []*parser.Node{{Name: "field1", Value: "J"}}},
+						{Name: "[2]", Children: []*parser.Node{{Name: "field1", Value: "I"}}},
+						{Name: "[3]", Children: []*parser.Node{{Name: "field1", Value: "H"}}},
+						{Name: "[4]", Children: []*parser.Node{{Name: "field1", Value: "G"}}},
+						{Name: "[5]", Children: []*parser.Node{{Name: "field1", Value: "F"}}},
+						{Name: "[6]", Children: []*parser.Node{{Name: "field1", Value: "E"}}},
+						{Name: "[7]", Children: []*parser.Node{{Name: "field1", Value: "D"}}},
+						{Name: "[8]", Children: []*parser.Node{{Name: "field1", Value: "C"}}},
+						{Name: "[9]", Children: []*parser.Node{{Name: "field1", Value: "B"}}},
+						{Name: "[10]", Children: []*parser.Node{{Name: "field1", Value: "A"}}},
+					}},
+				},
+			},
+		},
 	}
 
 	for _, test := range testCases {
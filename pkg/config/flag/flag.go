@@ -11,13 +11,19 @@ import (
 // map -> tree of untyped nodes
 // untyped nodes -> nodes augmented with metadata such as kind (inferred from element)
 // "typed" nodes -> typed element
+// It stops at the first error, see DecodeWithOpts to collect all of them.
 func Decode(args []string, element interface{}) error {
+	return DecodeWithOpts(args, element, parser.DecodeOpts{})
+}
+
+// DecodeWithOpts decodes the given flag arguments into the given element, with the given options.
+func DecodeWithOpts(args []string, element interface{}, opts parser.DecodeOpts) error {
 	ref, err := Parse(args, element)
 	if err != nil {
 		return err
 	}
 
-	return parser.Decode(ref, element)
+	return parser.DecodeWithOpts(ref, element, opts)
 }
 
 // Encode encodes the configuration in element into the flags represented in the returned Flats.
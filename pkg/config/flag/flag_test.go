@@ -44,7 +44,7 @@ func TestDecode(t *testing.T) {
 			expected: &struct {
 				Foo time.Duration
 			}{
-				Foo: 1 * time.Nanosecond,
+				Foo: 1 * time.Second,
 			},
 		},
 		{
@@ -376,6 +376,18 @@ func TestDecode(t *testing.T) {
 	}
 }
 
+func TestDecode_unknownField(t *testing.T) {
+	element := &struct {
+		Foo struct {
+			FieldName string
+			Other     string
+		}
+	}{}
+
+	err := Decode([]string{"--foo.fieldnames=bar"}, element)
+	assert.EqualError(t, err, `Foo: unknown field "fieldnames" (did you mean "fieldname"?)`)
+}
+
 func TestEncode(t *testing.T) {
 	testCases := []struct {
 		desc     string
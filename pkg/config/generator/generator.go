@@ -56,6 +56,9 @@ func fill(field reflect.Value) {
 func setPtr(field reflect.Value) {
 	if field.IsNil() {
 		field.Set(reflect.New(field.Type().Elem()))
+
+		// The default tags are valid, as they are checked by the decoding tests.
+		_ = parser.ApplyDefaults(field.Interface())
 	}
 
 	if field.Type().Implements(reflect.TypeOf((*initializer)(nil)).Elem()) {
@@ -2,18 +2,36 @@
 package label
 
 import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/config/parser"
 )
 
 // DecodeConfiguration converts the labels to a configuration.
+// The elements are not validated, as the providers complete them afterwards,
+// e.g. with the default rule of the routers, or with the servers of the services:
+// the completed configuration is validated with ValidateConfiguration.
 func DecodeConfiguration(labels map[string]string) (*config.Configuration, error) {
 	conf := &config.Configuration{
 		HTTP: &config.HTTPConfiguration{},
 		TCP:  &config.TCPConfiguration{},
 	}
 
-	err := parser.Decode(labels, conf, "traefik.http", "traefik.tcp")
+	node, err := parser.DecodeToNode(labels, "traefik.http", "traefik.tcp", "traefik.udp")
+	if err != nil {
+		return nil, err
+	}
+
+	err = parser.AddMetadata(conf, node)
+	if err != nil {
+		return nil, err
+	}
+
+	err = parser.Fill(conf, node)
 	if err != nil {
 		return nil, err
 	}
@@ -21,11 +39,248 @@ func DecodeConfiguration(labels map[string]string) (*config.Configuration, error
 	return conf, nil
 }
 
+// ValidateConfiguration validates the elements of the configuration, once completed by the provider.
+// An invalid element is dropped, and the corresponding error is returned.
+func ValidateConfiguration(conf *config.Configuration) []*ElementError {
+	var errs []*ElementError
+
+	check := func(element, name string, value interface{}) bool {
+		if err := parser.Validate(value); err != nil {
+			errs = append(errs, &ElementError{Element: element + "." + parser.QuoteName(name), Err: err})
+			return false
+		}
+		return true
+	}
+
+	if conf.HTTP != nil {
+		for _, name := range sortedNames(conf.HTTP.Routers) {
+			if !check("traefik.http.routers", name, conf.HTTP.Routers[name]) {
+				delete(conf.HTTP.Routers, name)
+			}
+		}
+		for _, name := range sortedNames(conf.HTTP.Middlewares) {
+			if !check("traefik.http.middlewares", name, conf.HTTP.Middlewares[name]) {
+				delete(conf.HTTP.Middlewares, name)
+			}
+		}
+		for _, name := range sortedNames(conf.HTTP.Services) {
+			if !check("traefik.http.services", name, conf.HTTP.Services[name]) {
+				delete(conf.HTTP.Services, name)
+			}
+		}
+	}
+
+	if conf.TCP != nil {
+		for _, name := range sortedNames(conf.TCP.Routers) {
+			if !check("traefik.tcp.routers", name, conf.TCP.Routers[name]) {
+				delete(conf.TCP.Routers, name)
+			}
+		}
+		for _, name := range sortedNames(conf.TCP.Services) {
+			if !check("traefik.tcp.services", name, conf.TCP.Services[name]) {
+				delete(conf.TCP.Services, name)
+			}
+		}
+	}
+
+	if conf.UDP != nil {
+		for _, name := range sortedNames(conf.UDP.Routers) {
+			if !check("traefik.udp.routers", name, conf.UDP.Routers[name]) {
+				delete(conf.UDP.Routers, name)
+			}
+		}
+		for _, name := range sortedNames(conf.UDP.Services) {
+			if !check("traefik.udp.services", name, conf.UDP.Services[name]) {
+				delete(conf.UDP.Services, name)
+			}
+		}
+	}
+
+	return errs
+}
+
+// sortedNames returns the names of the elements of the map, which must have string keys, sorted.
+func sortedNames(elements interface{}) []string {
+	value := reflect.ValueOf(elements)
+
+	names := make([]string, 0, value.Len())
+	for _, key := range value.MapKeys() {
+		names = append(names, key.String())
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ElementError is an error which occurred while decoding the labels of a top-level element,
+// i.e. a router, a service or a middleware.
+type ElementError struct {
+	// Element is the prefix shared by the labels of the element, e.g. "traefik.http.routers.foo".
+	Element string
+	Err     error
+}
+
+func (e *ElementError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Element, e.Err)
+}
+
+// DecodeConfigurationPerElement converts the labels to a configuration, element by element.
+// Unlike DecodeConfiguration, an invalid element does not invalidate the whole configuration:
+// it is dropped, and the corresponding error is returned along with the configuration made of the valid elements.
+func DecodeConfigurationPerElement(labels map[string]string) (*config.Configuration, []*ElementError) {
+	conf, err := DecodeConfiguration(labels)
+	if err == nil {
+		return conf, nil
+	}
+
+	conf = &config.Configuration{
+		HTTP: &config.HTTPConfiguration{},
+		TCP:  &config.TCPConfiguration{},
+	}
+
+	groups := groupByElement(labels)
+
+	var elements []string
+	for element := range groups {
+		elements = append(elements, element)
+	}
+	sort.Strings(elements)
+
+	var errs []*ElementError
+	for _, element := range elements {
+		elementConf, err := DecodeConfiguration(groups[element])
+		if err != nil {
+			errs = append(errs, &ElementError{Element: element, Err: err})
+			continue
+		}
+
+		mergeElement(conf, elementConf)
+	}
+
+	return conf, errs
+}
+
+// groupByElement groups the labels by top-level element (traefik.<http|tcp|udp>.<routers|services|middlewares>.<name>).
+// The labels which are not related to the HTTP, TCP or UDP configuration are skipped.
+// As the parser, the grouping ignores the casing: the element is named after its first label in order.
+func groupByElement(labels map[string]string) map[string]map[string]string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	elements := make(map[string]string)
+	groups := make(map[string]map[string]string)
+
+	for _, key := range keys {
+		parts, err := parser.SplitKey(key)
+		if err != nil {
+			// The key is kept on its own, so that decoding it reports the error.
+			groups[key] = map[string]string{key: labels[key]}
+			continue
+		}
+
+		if len(parts) < 2 || !strings.EqualFold(parts[0], "traefik") ||
+			!(strings.EqualFold(parts[1], "http") || strings.EqualFold(parts[1], "tcp") || strings.EqualFold(parts[1], "udp")) {
+			continue
+		}
+
+		if len(parts) > 4 {
+			parts = parts[:4]
+		}
+		for i := range parts {
+			if i < 3 {
+				parts[i] = strings.ToLower(parts[i])
+			} else {
+				parts[i] = parser.QuoteName(parts[i])
+			}
+		}
+		element := strings.Join(parts, ".")
+
+		folded := strings.ToLower(element)
+		if _, ok := elements[folded]; !ok {
+			elements[folded] = element
+			groups[element] = make(map[string]string)
+		}
+		groups[elements[folded]][key] = labels[key]
+	}
+
+	return groups
+}
+
+func mergeElement(conf *config.Configuration, element *config.Configuration) {
+	for name, router := range element.HTTP.Routers {
+		if conf.HTTP.Routers == nil {
+			conf.HTTP.Routers = make(map[string]*config.Router)
+		}
+		conf.HTTP.Routers[name] = router
+	}
+
+	for name, middleware := range element.HTTP.Middlewares {
+		if conf.HTTP.Middlewares == nil {
+			conf.HTTP.Middlewares = make(map[string]*config.Middleware)
+		}
+		conf.HTTP.Middlewares[name] = middleware
+	}
+
+	for name, service := range element.HTTP.Services {
+		if conf.HTTP.Services == nil {
+			conf.HTTP.Services = make(map[string]*config.Service)
+		}
+		conf.HTTP.Services[name] = service
+	}
+
+	for name, router := range element.TCP.Routers {
+		if conf.TCP.Routers == nil {
+			conf.TCP.Routers = make(map[string]*config.TCPRouter)
+		}
+		conf.TCP.Routers[name] = router
+	}
+
+	for name, service := range element.TCP.Services {
+		if conf.TCP.Services == nil {
+			conf.TCP.Services = make(map[string]*config.TCPService)
+		}
+		conf.TCP.Services[name] = service
+	}
+
+	if element.UDP == nil {
+		return
+	}
+
+	if conf.UDP == nil {
+		conf.UDP = &config.UDPConfiguration{}
+	}
+
+	for name, router := range element.UDP.Routers {
+		if conf.UDP.Routers == nil {
+			conf.UDP.Routers = make(map[string]*config.UDPRouter)
+		}
+		conf.UDP.Routers[name] = router
+	}
+
+	for name, service := range element.UDP.Services {
+		if conf.UDP.Services == nil {
+			conf.UDP.Services = make(map[string]*config.UDPService)
+		}
+		conf.UDP.Services[name] = service
+	}
+}
+
 // EncodeConfiguration converts a configuration to labels.
+// The secrets are redacted, see EncodeConfigurationWithOpts to keep them.
 func EncodeConfiguration(conf *config.Configuration) (map[string]string, error) {
 	return parser.Encode(conf)
 }
 
+// EncodeConfigurationWithOpts converts a configuration to labels, with the given options.
+// Without redaction, the labels are decoded back into the same configuration,
+// as long as it can be expressed with labels, e.g. a service has at most one server.
+func EncodeConfigurationWithOpts(conf *config.Configuration, opts parser.NodeOpts) (map[string]string, error) {
+	return parser.EncodeWithOpts(conf, opts)
+}
+
 // Decode converts the labels to an element.
 // labels -> [ node -> node + metadata (type) ] -> element (node)
 func Decode(labels map[string]string, element interface{}, filters ...string) error {
@@ -2,10 +2,15 @@ package label
 
 import (
 	"fmt"
+	"math/rand"
+	"strings"
 	"testing"
 	"time"
+	"unicode"
 
 	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/config/parser"
+	"github.com/containous/traefik/pkg/tls"
 	"github.com/containous/traefik/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -25,7 +30,11 @@ func TestDecodeConfiguration(t *testing.T) {
 		"traefik.http.middlewares.Middleware2.buffering.memresponsebodybytes":                  "42",
 		"traefik.http.middlewares.Middleware2.buffering.retryexpression":                       "foobar",
 		"traefik.http.middlewares.Middleware3.chain.middlewares":                               "foobar, fiibar",
+		"traefik.http.middlewares.Middleware4.circuitbreaker.checkperiod":                      "42",
 		"traefik.http.middlewares.Middleware4.circuitbreaker.expression":                       "foobar",
+		"traefik.http.middlewares.Middleware4.circuitbreaker.fallbackduration":                 "42",
+		"traefik.http.middlewares.Middleware4.circuitbreaker.recoveryduration":                 "42",
+		"traefik.http.middlewares.Middleware4.circuitbreaker.responsecode":                     "429",
 		"traefik.http.middlewares.Middleware5.digestauth.headerfield":                          "foobar",
 		"traefik.http.middlewares.Middleware5.digestauth.realm":                                "foobar",
 		"traefik.http.middlewares.Middleware5.digestauth.removeheader":                         "true",
@@ -97,13 +106,12 @@ func TestDecodeConfiguration(t *testing.T) {
 		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.issuer.province":         "true",
 		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.issuer.serialnumber":     "true",
 		"traefik.http.middlewares.Middleware11.passtlsclientcert.pem":                          "true",
-		"traefik.http.middlewares.Middleware12.ratelimit.extractorfunc":                        "foobar",
-		"traefik.http.middlewares.Middleware12.ratelimit.rateset.Rate0.average":                "42",
-		"traefik.http.middlewares.Middleware12.ratelimit.rateset.Rate0.burst":                  "42",
-		"traefik.http.middlewares.Middleware12.ratelimit.rateset.Rate0.period":                 "42",
-		"traefik.http.middlewares.Middleware12.ratelimit.rateset.Rate1.average":                "42",
-		"traefik.http.middlewares.Middleware12.ratelimit.rateset.Rate1.burst":                  "42",
-		"traefik.http.middlewares.Middleware12.ratelimit.rateset.Rate1.period":                 "42",
+		"traefik.http.middlewares.Middleware12.ratelimit.average":                              "42",
+		"traefik.http.middlewares.Middleware12.ratelimit.burst":                                "42",
+		"traefik.http.middlewares.Middleware12.ratelimit.period":                               "42",
+		"traefik.http.middlewares.Middleware12.ratelimit.sourcecriterion.ipstrategy.depth":     "42",
+		"traefik.http.middlewares.Middleware12.ratelimit.sourcecriterion.requestheadername":    "foobar",
+		"traefik.http.middlewares.Middleware12.ratelimit.sourcecriterion.requesthost":          "true",
 		"traefik.http.middlewares.Middleware13.redirectregex.permanent":                        "true",
 		"traefik.http.middlewares.Middleware13.redirectregex.regex":                            "foobar",
 		"traefik.http.middlewares.Middleware13.redirectregex.replacement":                      "foobar",
@@ -114,21 +122,29 @@ func TestDecodeConfiguration(t *testing.T) {
 		"traefik.http.middlewares.Middleware15.replacepathregex.regex":                         "foobar",
 		"traefik.http.middlewares.Middleware15.replacepathregex.replacement":                   "foobar",
 		"traefik.http.middlewares.Middleware16.retry.attempts":                                 "42",
+		"traefik.http.middlewares.Middleware16.retry.initialinterval":                          "42",
+		"traefik.http.middlewares.Middleware16.retry.pertrytimeout":                            "42",
 		"traefik.http.middlewares.Middleware17.stripprefix.prefixes":                           "foobar, fiibar",
 		"traefik.http.middlewares.Middleware18.stripprefixregex.regex":                         "foobar, fiibar",
 		"traefik.http.middlewares.Middleware19.compress":                                       "true",
 
-		"traefik.http.routers.Router0.entrypoints": "foobar, fiibar",
-		"traefik.http.routers.Router0.middlewares": "foobar, fiibar",
-		"traefik.http.routers.Router0.priority":    "42",
-		"traefik.http.routers.Router0.rule":        "foobar",
-		"traefik.http.routers.Router0.tls":         "true",
-		"traefik.http.routers.Router0.service":     "foobar",
-		"traefik.http.routers.Router1.entrypoints": "foobar, fiibar",
-		"traefik.http.routers.Router1.middlewares": "foobar, fiibar",
-		"traefik.http.routers.Router1.priority":    "42",
-		"traefik.http.routers.Router1.rule":        "foobar",
-		"traefik.http.routers.Router1.service":     "foobar",
+		"traefik.http.routers.Router0.description":               "foobar",
+		"traefik.http.routers.Router0.entrypoints":               "foobar, fiibar",
+		"traefik.http.routers.Router0.middlewares":               "foobar, fiibar",
+		"traefik.http.routers.Router0.priority":                  "42",
+		"traefik.http.routers.Router0.rule":                      "foobar",
+		"traefik.http.routers.Router0.tls.options":               "foobar",
+		"traefik.http.routers.Router0.tls.certresolver":          "foobar",
+		"traefik.http.routers.Router0.tls.domains[0].main":       "foobar",
+		"traefik.http.routers.Router0.tls.domains[0].sans":       "foobar, fiibar",
+		"traefik.http.routers.Router0.tls.domains[1].main":       "fiibar",
+		"traefik.http.routers.Router0.service":                   "foobar",
+		"traefik.http.routers.Router1.entrypoints":               "foobar, fiibar",
+		"traefik.http.routers.Router1.middlewares":               "foobar, fiibar",
+		"traefik.http.routers.Router1.priority":                  "42",
+		"traefik.http.routers.Router1.rule":                      "foobar",
+		"traefik.http.routers.Router1.service":                   "foobar",
+		"traefik.http.routers.Router1.skipentrypointmiddlewares": "true",
 
 		"traefik.http.services.Service0.loadbalancer.healthcheck.headers.name0":        "foobar",
 		"traefik.http.services.Service0.loadbalancer.healthcheck.headers.name1":        "foobar",
@@ -142,8 +158,9 @@ func TestDecodeConfiguration(t *testing.T) {
 		"traefik.http.services.Service0.loadbalancer.responseforwarding.flushinterval": "foobar",
 		"traefik.http.services.Service0.loadbalancer.server.scheme":                    "foobar",
 		"traefik.http.services.Service0.loadbalancer.server.port":                      "8080",
-		"traefik.http.services.Service0.loadbalancer.stickiness.cookiename":            "foobar",
-		"traefik.http.services.Service0.loadbalancer.stickiness.securecookie":          "true",
+		"traefik.http.services.Service0.loadbalancer.server.weight":                    "42",
+		"traefik.http.services.Service0.loadbalancer.sticky.cookie.name":               "foobar",
+		"traefik.http.services.Service0.loadbalancer.sticky.cookie.secure":             "true",
 		"traefik.http.services.Service1.loadbalancer.healthcheck.headers.name0":        "foobar",
 		"traefik.http.services.Service1.loadbalancer.healthcheck.headers.name1":        "foobar",
 		"traefik.http.services.Service1.loadbalancer.healthcheck.hostname":             "foobar",
@@ -156,8 +173,18 @@ func TestDecodeConfiguration(t *testing.T) {
 		"traefik.http.services.Service1.loadbalancer.responseforwarding.flushinterval": "foobar",
 		"traefik.http.services.Service1.loadbalancer.server.scheme":                    "foobar",
 		"traefik.http.services.Service1.loadbalancer.server.port":                      "8080",
-		"traefik.http.services.Service1.loadbalancer.stickiness":                       "false",
-		"traefik.http.services.Service1.loadbalancer.stickiness.cookiename":            "fui",
+		"traefik.http.services.Service1.loadbalancer.server.weight":                    "42",
+		"traefik.http.services.Service1.loadbalancer.sticky.cookie":                    "false",
+		"traefik.http.services.Service1.loadbalancer.sticky.cookie.name":               "fui",
+		"traefik.http.services.Service2.weighted.services[0].name":                     "Service0",
+		"traefik.http.services.Service2.weighted.services[0].weight":                   "3",
+		"traefik.http.services.Service2.weighted.services[1].name":                     "foobar.Service1",
+		"traefik.http.services.Service3.mirroring.service":                             "Service0",
+		"traefik.http.services.Service3.mirroring.maxbodysize":                         "1Mi",
+		"traefik.http.services.Service3.mirroring.mirrors[0].name":                     "Service1",
+		"traefik.http.services.Service3.mirroring.mirrors[0].percent":                  "10",
+		"traefik.http.services.Service4.failover.service":                              "Service0",
+		"traefik.http.services.Service4.failover.fallback":                             "foobar.Service1",
 		"traefik.tcp.routers.Router0.rule":                                             "foobar",
 		"traefik.tcp.routers.Router0.entrypoints":                                      "foobar, fiibar",
 		"traefik.tcp.routers.Router0.service":                                          "foobar",
@@ -223,6 +250,7 @@ func TestDecodeConfiguration(t *testing.T) {
 		HTTP: &config.HTTPConfiguration{
 			Routers: map[string]*config.Router{
 				"Router0": {
+					Description: "foobar",
 					EntryPoints: []string{
 						"foobar",
 						"fiibar",
@@ -234,7 +262,14 @@ func TestDecodeConfiguration(t *testing.T) {
 					Service:  "foobar",
 					Rule:     "foobar",
 					Priority: 42,
-					TLS:      &config.RouterTLSConfig{},
+					TLS: &config.RouterTLSConfig{
+						Options:      "foobar",
+						CertResolver: "foobar",
+						Domains: []types.Domain{
+							{Main: "foobar", SANs: []string{"foobar", "fiibar"}},
+							{Main: "fiibar"},
+						},
+					},
 				},
 				"Router1": {
 					EntryPoints: []string{
@@ -245,9 +280,10 @@ func TestDecodeConfiguration(t *testing.T) {
 						"foobar",
 						"fiibar",
 					},
-					Service:  "foobar",
-					Rule:     "foobar",
-					Priority: 42,
+					Service:                   "foobar",
+					Rule:                      "foobar",
+					Priority:                  42,
+					SkipEntryPointMiddlewares: true,
 				},
 			},
 			Middlewares: map[string]*config.Middleware{
@@ -304,19 +340,14 @@ func TestDecodeConfiguration(t *testing.T) {
 				},
 				"Middleware12": {
 					RateLimit: &config.RateLimit{
-						RateSet: map[string]*config.Rate{
-							"Rate0": {
-								Period:  types.Duration(42 * time.Second),
-								Average: 42,
-								Burst:   42,
-							},
-							"Rate1": {
-								Period:  types.Duration(42 * time.Second),
-								Average: 42,
-								Burst:   42,
-							},
+						Average: 42,
+						Period:  types.Duration(42 * time.Second),
+						Burst:   42,
+						SourceCriterion: &config.SourceCriterion{
+							IPStrategy:        &config.IPStrategy{Depth: 42},
+							RequestHeaderName: "foobar",
+							RequestHost:       true,
 						},
-						ExtractorFunc: "foobar",
 					},
 				},
 				"Middleware13": {
@@ -346,7 +377,9 @@ func TestDecodeConfiguration(t *testing.T) {
 				},
 				"Middleware16": {
 					Retry: &config.Retry{
-						Attempts: 42,
+						Attempts:        42,
+						InitialInterval: types.Duration(42 * time.Second),
+						PerTryTimeout:   types.Duration(42 * time.Second),
 					},
 				},
 				"Middleware17": {
@@ -355,6 +388,7 @@ func TestDecodeConfiguration(t *testing.T) {
 							"foobar",
 							"fiibar",
 						},
+						ForceSlash: true,
 					},
 				},
 				"Middleware18": {
@@ -387,7 +421,11 @@ func TestDecodeConfiguration(t *testing.T) {
 				},
 				"Middleware4": {
 					CircuitBreaker: &config.CircuitBreaker{
-						Expression: "foobar",
+						Expression:       "foobar",
+						CheckPeriod:      types.Duration(42 * time.Second),
+						FallbackDuration: types.Duration(42 * time.Second),
+						RecoveryDuration: types.Duration(42 * time.Second),
+						ResponseCode:     429,
 					},
 				},
 				"Middleware5": {
@@ -505,15 +543,18 @@ func TestDecodeConfiguration(t *testing.T) {
 			Services: map[string]*config.Service{
 				"Service0": {
 					LoadBalancer: &config.LoadBalancerService{
-						Stickiness: &config.Stickiness{
-							CookieName:     "foobar",
-							SecureCookie:   true,
-							HTTPOnlyCookie: false,
+						Sticky: &config.Sticky{
+							Cookie: &config.Cookie{
+								Name:     "foobar",
+								Secure:   true,
+								HTTPOnly: false,
+							},
 						},
 						Servers: []config.Server{
 							{
 								Scheme: "foobar",
 								Port:   "8080",
+								Weight: 42,
 							},
 						},
 						HealthCheck: &config.HealthCheck{
@@ -536,10 +577,13 @@ func TestDecodeConfiguration(t *testing.T) {
 				},
 				"Service1": {
 					LoadBalancer: &config.LoadBalancerService{
+						// The cookie is disabled, and its name ignored.
+						Sticky: &config.Sticky{},
 						Servers: []config.Server{
 							{
 								Scheme: "foobar",
 								Port:   "8080",
+								Weight: 42,
 							},
 						},
 						HealthCheck: &config.HealthCheck{
@@ -560,6 +604,27 @@ func TestDecodeConfiguration(t *testing.T) {
 						},
 					},
 				},
+				"Service2": {
+					Weighted: &config.WeightedService{
+						Services: []config.WeightedServiceRef{
+							{Name: "Service0", Weight: intPtr(3)},
+							{Name: "foobar.Service1"},
+						},
+					},
+				},
+				"Service3": {
+					Mirroring: &config.Mirroring{
+						Service:     "Service0",
+						MaxBodySize: int64Ptr(1048576),
+						Mirrors:     []config.MirrorService{{Name: "Service1", Percent: 10}},
+					},
+				},
+				"Service4": {
+					Failover: &config.Failover{
+						Service:  "Service0",
+						Fallback: "foobar.Service1",
+					},
+				},
 			},
 		},
 	}
@@ -618,6 +683,7 @@ func TestEncodeConfiguration(t *testing.T) {
 		HTTP: &config.HTTPConfiguration{
 			Routers: map[string]*config.Router{
 				"Router0": {
+					Description: "foobar",
 					EntryPoints: []string{
 						"foobar",
 						"fiibar",
@@ -629,7 +695,14 @@ func TestEncodeConfiguration(t *testing.T) {
 					Service:  "foobar",
 					Rule:     "foobar",
 					Priority: 42,
-					TLS:      &config.RouterTLSConfig{},
+					TLS: &config.RouterTLSConfig{
+						Options:      "foobar",
+						CertResolver: "foobar",
+						Domains: []types.Domain{
+							{Main: "foobar", SANs: []string{"foobar", "fiibar"}},
+							{Main: "fiibar"},
+						},
+					},
 				},
 				"Router1": {
 					EntryPoints: []string{
@@ -640,9 +713,10 @@ func TestEncodeConfiguration(t *testing.T) {
 						"foobar",
 						"fiibar",
 					},
-					Service:  "foobar",
-					Rule:     "foobar",
-					Priority: 42,
+					Service:                   "foobar",
+					Rule:                      "foobar",
+					Priority:                  42,
+					SkipEntryPointMiddlewares: true,
 				},
 			},
 			Middlewares: map[string]*config.Middleware{
@@ -698,19 +772,14 @@ func TestEncodeConfiguration(t *testing.T) {
 				},
 				"Middleware12": {
 					RateLimit: &config.RateLimit{
-						RateSet: map[string]*config.Rate{
-							"Rate0": {
-								Period:  types.Duration(42 * time.Nanosecond),
-								Average: 42,
-								Burst:   42,
-							},
-							"Rate1": {
-								Period:  types.Duration(42 * time.Nanosecond),
-								Average: 42,
-								Burst:   42,
-							},
+						Average: 42,
+						Period:  types.Duration(42 * time.Nanosecond),
+						Burst:   42,
+						SourceCriterion: &config.SourceCriterion{
+							IPStrategy:        &config.IPStrategy{Depth: 42},
+							RequestHeaderName: "foobar",
+							RequestHost:       true,
 						},
-						ExtractorFunc: "foobar",
 					},
 				},
 				"Middleware13": {
@@ -740,7 +809,9 @@ func TestEncodeConfiguration(t *testing.T) {
 				},
 				"Middleware16": {
 					Retry: &config.Retry{
-						Attempts: 42,
+						Attempts:        42,
+						InitialInterval: types.Duration(42 * time.Nanosecond),
+						PerTryTimeout:   types.Duration(42 * time.Nanosecond),
 					},
 				},
 				"Middleware17": {
@@ -781,7 +852,11 @@ func TestEncodeConfiguration(t *testing.T) {
 				},
 				"Middleware4": {
 					CircuitBreaker: &config.CircuitBreaker{
-						Expression: "foobar",
+						Expression:       "foobar",
+						CheckPeriod:      types.Duration(42 * time.Nanosecond),
+						FallbackDuration: types.Duration(42 * time.Nanosecond),
+						RecoveryDuration: types.Duration(42 * time.Nanosecond),
+						ResponseCode:     429,
 					},
 				},
 				"Middleware5": {
@@ -899,14 +974,17 @@ func TestEncodeConfiguration(t *testing.T) {
 			Services: map[string]*config.Service{
 				"Service0": {
 					LoadBalancer: &config.LoadBalancerService{
-						Stickiness: &config.Stickiness{
-							CookieName:     "foobar",
-							HTTPOnlyCookie: true,
+						Sticky: &config.Sticky{
+							Cookie: &config.Cookie{
+								Name:     "foobar",
+								HTTPOnly: true,
+							},
 						},
 						Servers: []config.Server{
 							{
 								Scheme: "foobar",
 								Port:   "8080",
+								Weight: 42,
 							},
 						},
 						HealthCheck: &config.HealthCheck{
@@ -933,6 +1011,7 @@ func TestEncodeConfiguration(t *testing.T) {
 							{
 								Scheme: "foobar",
 								Port:   "8080",
+								Weight: 42,
 							},
 						},
 						HealthCheck: &config.HealthCheck{
@@ -953,6 +1032,27 @@ func TestEncodeConfiguration(t *testing.T) {
 						},
 					},
 				},
+				"Service2": {
+					Weighted: &config.WeightedService{
+						Services: []config.WeightedServiceRef{
+							{Name: "Service0", Weight: intPtr(3)},
+							{Name: "foobar.Service1"},
+						},
+					},
+				},
+				"Service3": {
+					Mirroring: &config.Mirroring{
+						Service:     "Service0",
+						MaxBodySize: int64Ptr(1048576),
+						Mirrors:     []config.MirrorService{{Name: "Service1", Percent: 10}},
+					},
+				},
+				"Service4": {
+					Failover: &config.Failover{
+						Service:  "Service0",
+						Fallback: "foobar.Service1",
+					},
+				},
 			},
 		},
 	}
@@ -965,7 +1065,7 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Middlewares.Middleware1.BasicAuth.HeaderField":                           "foobar",
 		"traefik.HTTP.Middlewares.Middleware1.BasicAuth.Realm":                                 "foobar",
 		"traefik.HTTP.Middlewares.Middleware1.BasicAuth.RemoveHeader":                          "true",
-		"traefik.HTTP.Middlewares.Middleware1.BasicAuth.Users":                                 "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware1.BasicAuth.Users":                                 "xxxx",
 		"traefik.HTTP.Middlewares.Middleware1.BasicAuth.UsersFile":                             "foobar",
 		"traefik.HTTP.Middlewares.Middleware2.Buffering.MaxRequestBodyBytes":                   "42",
 		"traefik.HTTP.Middlewares.Middleware2.Buffering.MaxResponseBodyBytes":                  "42",
@@ -973,11 +1073,15 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Middlewares.Middleware2.Buffering.MemResponseBodyBytes":                  "42",
 		"traefik.HTTP.Middlewares.Middleware2.Buffering.RetryExpression":                       "foobar",
 		"traefik.HTTP.Middlewares.Middleware3.Chain.Middlewares":                               "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware4.CircuitBreaker.CheckPeriod":                      "42ns",
 		"traefik.HTTP.Middlewares.Middleware4.CircuitBreaker.Expression":                       "foobar",
+		"traefik.HTTP.Middlewares.Middleware4.CircuitBreaker.FallbackDuration":                 "42ns",
+		"traefik.HTTP.Middlewares.Middleware4.CircuitBreaker.RecoveryDuration":                 "42ns",
+		"traefik.HTTP.Middlewares.Middleware4.CircuitBreaker.ResponseCode":                     "429",
 		"traefik.HTTP.Middlewares.Middleware5.DigestAuth.HeaderField":                          "foobar",
 		"traefik.HTTP.Middlewares.Middleware5.DigestAuth.Realm":                                "foobar",
 		"traefik.HTTP.Middlewares.Middleware5.DigestAuth.RemoveHeader":                         "true",
-		"traefik.HTTP.Middlewares.Middleware5.DigestAuth.Users":                                "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware5.DigestAuth.Users":                                "xxxx",
 		"traefik.HTTP.Middlewares.Middleware5.DigestAuth.UsersFile":                            "foobar",
 		"traefik.HTTP.Middlewares.Middleware6.Errors.Query":                                    "foobar",
 		"traefik.HTTP.Middlewares.Middleware6.Errors.Service":                                  "foobar",
@@ -988,7 +1092,7 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TLS.CAOptional":                      "true",
 		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TLS.Cert":                            "foobar",
 		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TLS.InsecureSkipVerify":              "true",
-		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TLS.Key":                             "foobar",
+		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TLS.Key":                             "xxxx",
 		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TrustForwardHeader":                  "true",
 		"traefik.HTTP.Middlewares.Middleware8.Headers.AccessControlAllowCredentials":           "true",
 		"traefik.HTTP.Middlewares.Middleware8.Headers.AccessControlAllowHeaders":               "X-foobar, X-fiibar",
@@ -1045,13 +1149,12 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Issuer.SerialNumber":     "true",
 		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Issuer.DomainComponent":  "true",
 		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.PEM":                          "true",
-		"traefik.HTTP.Middlewares.Middleware12.RateLimit.ExtractorFunc":                        "foobar",
-		"traefik.HTTP.Middlewares.Middleware12.RateLimit.RateSet.Rate0.Average":                "42",
-		"traefik.HTTP.Middlewares.Middleware12.RateLimit.RateSet.Rate0.Burst":                  "42",
-		"traefik.HTTP.Middlewares.Middleware12.RateLimit.RateSet.Rate0.Period":                 "42",
-		"traefik.HTTP.Middlewares.Middleware12.RateLimit.RateSet.Rate1.Average":                "42",
-		"traefik.HTTP.Middlewares.Middleware12.RateLimit.RateSet.Rate1.Burst":                  "42",
-		"traefik.HTTP.Middlewares.Middleware12.RateLimit.RateSet.Rate1.Period":                 "42",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.Average":                              "42",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.Burst":                                "42",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.Period":                               "42ns",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.SourceCriterion.IPStrategy.Depth":     "42",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.SourceCriterion.RequestHeaderName":    "foobar",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.SourceCriterion.RequestHost":          "true",
 		"traefik.HTTP.Middlewares.Middleware13.RedirectRegex.Regex":                            "foobar",
 		"traefik.HTTP.Middlewares.Middleware13.RedirectRegex.Replacement":                      "foobar",
 		"traefik.HTTP.Middlewares.Middleware13.RedirectRegex.Permanent":                        "true",
@@ -1062,21 +1165,33 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Middlewares.Middleware15.ReplacePathRegex.Regex":                         "foobar",
 		"traefik.HTTP.Middlewares.Middleware15.ReplacePathRegex.Replacement":                   "foobar",
 		"traefik.HTTP.Middlewares.Middleware16.Retry.Attempts":                                 "42",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.InitialInterval":                          "42ns",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.PerTryTimeout":                            "42ns",
+		"traefik.HTTP.Middlewares.Middleware17.StripPrefix.ForceSlash":                         "false",
 		"traefik.HTTP.Middlewares.Middleware17.StripPrefix.Prefixes":                           "foobar, fiibar",
 		"traefik.HTTP.Middlewares.Middleware18.StripPrefixRegex.Regex":                         "foobar, fiibar",
 		"traefik.HTTP.Middlewares.Middleware19.Compress":                                       "true",
 
-		"traefik.HTTP.Routers.Router0.EntryPoints": "foobar, fiibar",
-		"traefik.HTTP.Routers.Router0.Middlewares": "foobar, fiibar",
-		"traefik.HTTP.Routers.Router0.Priority":    "42",
-		"traefik.HTTP.Routers.Router0.Rule":        "foobar",
-		"traefik.HTTP.Routers.Router0.Service":     "foobar",
-		"traefik.HTTP.Routers.Router0.TLS":         "true",
-		"traefik.HTTP.Routers.Router1.EntryPoints": "foobar, fiibar",
-		"traefik.HTTP.Routers.Router1.Middlewares": "foobar, fiibar",
-		"traefik.HTTP.Routers.Router1.Priority":    "42",
-		"traefik.HTTP.Routers.Router1.Rule":        "foobar",
-		"traefik.HTTP.Routers.Router1.Service":     "foobar",
+		"traefik.HTTP.Routers.Router0.Description":               "foobar",
+		"traefik.HTTP.Routers.Router0.EntryPoints":               "foobar, fiibar",
+		"traefik.HTTP.Routers.Router0.Middlewares":               "foobar, fiibar",
+		"traefik.HTTP.Routers.Router0.Priority":                  "42",
+		"traefik.HTTP.Routers.Router0.Rule":                      "foobar",
+		"traefik.HTTP.Routers.Router0.Service":                   "foobar",
+		"traefik.HTTP.Routers.Router0.SkipEntryPointMiddlewares": "false",
+		"traefik.HTTP.Routers.Router0.Fallback":                  "false",
+		"traefik.HTTP.Routers.Router0.TLS.Options":               "foobar",
+		"traefik.HTTP.Routers.Router0.TLS.CertResolver":          "foobar",
+		"traefik.HTTP.Routers.Router0.TLS.Domains[0].Main":       "foobar",
+		"traefik.HTTP.Routers.Router0.TLS.Domains[0].SANs":       "foobar, fiibar",
+		"traefik.HTTP.Routers.Router0.TLS.Domains[1].Main":       "fiibar",
+		"traefik.HTTP.Routers.Router1.EntryPoints":               "foobar, fiibar",
+		"traefik.HTTP.Routers.Router1.Middlewares":               "foobar, fiibar",
+		"traefik.HTTP.Routers.Router1.Priority":                  "42",
+		"traefik.HTTP.Routers.Router1.Rule":                      "foobar",
+		"traefik.HTTP.Routers.Router1.Service":                   "foobar",
+		"traefik.HTTP.Routers.Router1.SkipEntryPointMiddlewares": "true",
+		"traefik.HTTP.Routers.Router1.Fallback":                  "false",
 
 		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Headers.name1":        "foobar",
 		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Hostname":             "foobar",
@@ -1089,9 +1204,10 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Services.Service0.LoadBalancer.ResponseForwarding.FlushInterval": "foobar",
 		"traefik.HTTP.Services.Service0.LoadBalancer.server.Port":                      "8080",
 		"traefik.HTTP.Services.Service0.LoadBalancer.server.Scheme":                    "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.Stickiness.CookieName":            "foobar",
-		"traefik.HTTP.Services.Service0.LoadBalancer.Stickiness.HTTPOnlyCookie":        "true",
-		"traefik.HTTP.Services.Service0.LoadBalancer.Stickiness.SecureCookie":          "false",
+		"traefik.HTTP.Services.Service0.LoadBalancer.server.Weight":                    "42",
+		"traefik.HTTP.Services.Service0.LoadBalancer.Sticky.Cookie.Name":               "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.Sticky.Cookie.HTTPOnly":           "true",
+		"traefik.HTTP.Services.Service0.LoadBalancer.Sticky.Cookie.Secure":             "false",
 		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Headers.name0":        "foobar",
 		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Headers.name1":        "foobar",
 		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Hostname":             "foobar",
@@ -1104,16 +1220,28 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Services.Service1.LoadBalancer.ResponseForwarding.FlushInterval": "foobar",
 		"traefik.HTTP.Services.Service1.LoadBalancer.server.Port":                      "8080",
 		"traefik.HTTP.Services.Service1.LoadBalancer.server.Scheme":                    "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.server.Weight":                    "42",
+		"traefik.HTTP.Services.Service2.Weighted.Services[0].Name":                     "Service0",
+		"traefik.HTTP.Services.Service2.Weighted.Services[0].Weight":                   "3",
+		"traefik.HTTP.Services.Service2.Weighted.Services[1].Name":                     "foobar.Service1",
+		"traefik.HTTP.Services.Service3.Mirroring.Service":                             "Service0",
+		"traefik.HTTP.Services.Service3.Mirroring.MaxBodySize":                         "1048576",
+		"traefik.HTTP.Services.Service3.Mirroring.Mirrors[0].Name":                     "Service1",
+		"traefik.HTTP.Services.Service3.Mirroring.Mirrors[0].Percent":                  "10",
+		"traefik.HTTP.Services.Service4.Failover.Service":                              "Service0",
+		"traefik.HTTP.Services.Service4.Failover.Fallback":                             "foobar.Service1",
 		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Headers.name0":        "foobar",
 
 		"traefik.TCP.Routers.Router0.Rule":                       "foobar",
 		"traefik.TCP.Routers.Router0.EntryPoints":                "foobar, fiibar",
 		"traefik.TCP.Routers.Router0.Service":                    "foobar",
 		"traefik.TCP.Routers.Router0.TLS.Passthrough":            "false",
+		"traefik.TCP.Routers.Router0.Fallback":                   "false",
 		"traefik.TCP.Routers.Router1.Rule":                       "foobar",
 		"traefik.TCP.Routers.Router1.EntryPoints":                "foobar, fiibar",
 		"traefik.TCP.Routers.Router1.Service":                    "foobar",
 		"traefik.TCP.Routers.Router1.TLS.Passthrough":            "false",
+		"traefik.TCP.Routers.Router1.Fallback":                   "false",
 		"traefik.TCP.Services.Service0.LoadBalancer.server.Port": "42",
 		"traefik.TCP.Services.Service1.LoadBalancer.server.Port": "42",
 	}
@@ -1131,3 +1259,924 @@ func TestEncodeConfiguration(t *testing.T) {
 	}
 	assert.Equal(t, expected, labels)
 }
+
+func TestDecodeConfigurationPerElement(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		labels           map[string]string
+		expected         *config.Configuration
+		expectedElements []string
+	}{
+		{
+			desc: "valid labels",
+			labels: map[string]string{
+				"traefik.http.routers.router0.rule":    "Host(`foo`)",
+				"traefik.http.routers.router0.service": "service0",
+			},
+			expected: &config.Configuration{
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"router0": {Rule: "Host(`foo`)", Service: "service0"},
+					},
+				},
+				TCP: &config.TCPConfiguration{},
+			},
+		},
+		{
+			desc: "invalid elements are dropped",
+			labels: map[string]string{
+				"traefik.http.routers.router0.rule":                                  "Host(`foo`)",
+				"traefik.http.routers.router1.rule":                                  "Host(`bar`)",
+				"traefik.http.routers.router1.unknown":                               "foobar",
+				"traefik.http.middlewares.Middleware0.addprefix.prefix":              "/foo",
+				"traefik.http.middlewares.Middleware1.buffering.maxrequestbodybytes": "foobar",
+				"traefik.tcp.routers.router0.rule":                                   "HostSNI(`foo`)",
+				"traefik.tcp.services.service0.loadbalancer.server.port":             "foobar",
+				"traefik.docker.network":                                             "foobar",
+			},
+			expected: &config.Configuration{
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"router0": {Rule: "Host(`foo`)"},
+					},
+					Middlewares: map[string]*config.Middleware{
+						"Middleware0": {AddPrefix: &config.AddPrefix{Prefix: "/foo"}},
+					},
+				},
+				TCP: &config.TCPConfiguration{
+					Routers: map[string]*config.TCPRouter{
+						"router0": {Rule: "HostSNI(`foo`)"},
+					},
+					Services: map[string]*config.TCPService{
+						"service0": {
+							LoadBalancer: &config.TCPLoadBalancerService{
+								Servers: []config.TCPServer{{Port: "foobar"}},
+							},
+						},
+					},
+				},
+			},
+			expectedElements: []string{
+				"traefik.http.middlewares.Middleware1",
+				"traefik.http.routers.router1",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			conf, errs := DecodeConfigurationPerElement(test.labels)
+
+			var elements []string
+			for _, err := range errs {
+				elements = append(elements, err.Element)
+			}
+
+			assert.Equal(t, test.expectedElements, elements)
+			assert.Equal(t, test.expected, conf)
+		})
+	}
+}
+
+func TestDecodeConfigurationUDP(t *testing.T) {
+	labels := map[string]string{
+		"traefik.udp.routers.Router0.entrypoints":                "foobar, fiibar",
+		"traefik.udp.routers.Router0.service":                    "Service0",
+		"traefik.udp.services.Service0.loadbalancer.server.port": "53",
+		"traefik.udp.services.Service1.loadbalancer.server.port": "index:1",
+		"traefik.http.routers.Router0.rule":                      "Host(`foo`)",
+		"traefik.tcp.services.Service0.loadbalancer.server.port": "42",
+		"traefik.udp.services.Service2.loadbalancer.server.port": "name:dns",
+	}
+
+	conf, err := DecodeConfiguration(labels)
+	require.NoError(t, err)
+
+	expected := &config.UDPConfiguration{
+		Routers: map[string]*config.UDPRouter{
+			"Router0": {
+				EntryPoints: []string{"foobar", "fiibar"},
+				Service:     "Service0",
+			},
+		},
+		Services: map[string]*config.UDPService{
+			"Service0": {
+				LoadBalancer: &config.UDPLoadBalancerService{
+					Servers: []config.UDPServer{{Port: "53"}},
+				},
+			},
+			"Service1": {
+				LoadBalancer: &config.UDPLoadBalancerService{
+					Servers: []config.UDPServer{{Port: "index:1"}},
+				},
+			},
+			"Service2": {
+				LoadBalancer: &config.UDPLoadBalancerService{
+					Servers: []config.UDPServer{{Port: "name:dns"}},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, conf.UDP)
+}
+
+func TestDecodeConfigurationWithoutUDP(t *testing.T) {
+	conf, err := DecodeConfiguration(map[string]string{
+		"traefik.http.routers.Router0.rule": "Host(`foo`)",
+	})
+	require.NoError(t, err)
+
+	assert.Nil(t, conf.UDP)
+}
+
+func TestDecodeConfigurationServersTransport(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.serverstransports.Transport0.servername":                               "foo.com",
+		"traefik.http.serverstransports.Transport0.insecureskipverify":                       "true",
+		"traefik.http.serverstransports.Transport0.rootcas":                                  "ca0.pem, ca1.pem",
+		"traefik.http.serverstransports.Transport0.certificates[0].certfile":                 "cert.pem",
+		"traefik.http.serverstransports.Transport0.certificates[0].keyfile":                  "key.pem",
+		"traefik.http.serverstransports.Transport0.maxidleconnsperhost":                      "42",
+		"traefik.http.serverstransports.Transport0.forwardingtimeouts.responseheadertimeout": "10s",
+		"traefik.http.services.Service0.loadbalancer.server.port":                            "8443",
+		"traefik.http.services.Service0.loadbalancer.serverstransport":                       "Transport0",
+	}
+
+	conf, err := DecodeConfiguration(labels)
+	require.NoError(t, err)
+
+	expectedTransports := map[string]*config.ServersTransport{
+		"Transport0": {
+			ServerName:         "foo.com",
+			InsecureSkipVerify: true,
+			RootCAs:            []tls.FileOrContent{"ca0.pem", "ca1.pem"},
+			Certificates: tls.Certificates{
+				{CertFile: "cert.pem", KeyFile: "key.pem"},
+			},
+			MaxIdleConnsPerHost: 42,
+			ForwardingTimeouts: &config.ForwardingTimeouts{
+				DialTimeout:           types.Duration(30 * time.Second),
+				ResponseHeaderTimeout: types.Duration(10 * time.Second),
+			},
+		},
+	}
+
+	assert.Equal(t, expectedTransports, conf.HTTP.ServersTransports)
+	assert.Equal(t, "Transport0", conf.HTTP.Services["Service0"].LoadBalancer.ServersTransport)
+}
+
+func TestDecodeConfigurationRetryDurations(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		value            string
+		expectedDuration types.Duration
+	}{
+		{
+			desc:             "duration string",
+			value:            "100ms",
+			expectedDuration: types.Duration(100 * time.Millisecond),
+		},
+		{
+			desc:             "number of seconds",
+			value:            "2",
+			expectedDuration: types.Duration(2 * time.Second),
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			conf, err := DecodeConfiguration(map[string]string{
+				"traefik.http.middlewares.Middleware0.retry.attempts":        "4",
+				"traefik.http.middlewares.Middleware0.retry.initialinterval": test.value,
+				"traefik.http.middlewares.Middleware0.retry.pertrytimeout":   test.value,
+			})
+			require.NoError(t, err)
+
+			expected := &config.Retry{
+				Attempts:        4,
+				InitialInterval: test.expectedDuration,
+				PerTryTimeout:   test.expectedDuration,
+			}
+			assert.Equal(t, expected, conf.HTTP.Middlewares["Middleware0"].Retry)
+		})
+	}
+}
+
+func TestDecodeConfigurationStickyCookie(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.services.Service0.loadbalancer.server.port":            "8080",
+		"traefik.http.services.Service0.loadbalancer.sticky.cookie.name":     "foobar",
+		"traefik.http.services.Service0.loadbalancer.sticky.cookie.secure":   "true",
+		"traefik.http.services.Service0.loadbalancer.sticky.cookie.httponly": "true",
+		"traefik.http.services.Service0.loadbalancer.sticky.cookie.samesite": "lax",
+		"traefik.http.services.Service1.loadbalancer.server.port":            "8080",
+		"traefik.http.services.Service1.loadbalancer.sticky.cookie":          "true",
+		"traefik.http.services.Service2.loadbalancer.server.port":            "8080",
+		"traefik.http.services.Service2.loadbalancer.sticky.cookie.samesite": "foobar",
+	}
+
+	conf, err := DecodeConfiguration(labels)
+	require.NoError(t, err)
+
+	assert.Equal(t, &config.Sticky{
+		Cookie: &config.Cookie{Name: "foobar", Secure: true, HTTPOnly: true, SameSite: "lax"},
+	}, conf.HTTP.Services["Service0"].LoadBalancer.Sticky)
+	assert.Equal(t, &config.Sticky{Cookie: &config.Cookie{}}, conf.HTTP.Services["Service1"].LoadBalancer.Sticky)
+
+	var messages []string
+	for _, err := range ValidateConfiguration(conf) {
+		messages = append(messages, err.Error())
+	}
+
+	expected := []string{
+		`traefik.http.services.Service2: LoadBalancer.Sticky.Cookie: invalid sameSite "foobar": it must be none, lax, or strict`,
+	}
+	assert.Equal(t, expected, messages)
+}
+
+func TestDecodeConfigurationFailover(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.services.Service0.failover.service":  "app",
+		"traefik.http.services.Service0.failover.fallback": "file.maintenance",
+		"traefik.http.services.Service1.failover.service":  "app",
+		"traefik.http.services.Service2.failover.service":  "app",
+		"traefik.http.services.Service2.failover.fallback": "app",
+	}
+
+	conf, err := DecodeConfiguration(labels)
+	require.NoError(t, err)
+
+	assert.Equal(t, &config.Failover{Service: "app", Fallback: "file.maintenance"}, conf.HTTP.Services["Service0"].Failover)
+
+	var messages []string
+	for _, err := range ValidateConfiguration(conf) {
+		messages = append(messages, err.Error())
+	}
+
+	expected := []string{
+		"traefik.http.services.Service1: Failover: the fallback service is required",
+		"traefik.http.services.Service2: Failover: the fallback service must be different from the main service: app",
+	}
+	assert.Equal(t, expected, messages)
+}
+
+func TestDecodeConfigurationHeaders(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.middlewares.Middleware0.headers.customrequestheaders.X-Script-Name":   "test",
+		"traefik.http.middlewares.Middleware0.headers.customrequestheaders.X-Token":         "",
+		"traefik.http.middlewares.Middleware0.headers.customresponseheaders.X-Powered-By":   "",
+		"traefik.http.middlewares.Middleware0.headers.stsseconds":                           "31536000",
+		"traefik.http.middlewares.Middleware0.headers.stsincludesubdomains":                 "true",
+		"traefik.http.middlewares.Middleware0.headers.stspreload":                           "true",
+		"traefik.http.middlewares.Middleware0.headers.customframeoptionsvalue":              "SAMEORIGIN",
+		"traefik.http.middlewares.Middleware0.headers.contentsecuritypolicy":                "default-src 'self'",
+		"traefik.http.middlewares.Middleware0.headers.customresponseheaders.X-Custom-Value": "foo, bar",
+	}
+
+	conf, err := DecodeConfiguration(labels)
+	require.NoError(t, err)
+
+	expected := &config.Headers{
+		CustomRequestHeaders:    map[string]string{"X-Script-Name": "test", "X-Token": ""},
+		CustomResponseHeaders:   map[string]string{"X-Powered-By": "", "X-Custom-Value": "foo, bar"},
+		STSSeconds:              31536000,
+		STSIncludeSubdomains:    true,
+		STSPreload:              true,
+		CustomFrameOptionsValue: "SAMEORIGIN",
+		ContentSecurityPolicy:   "default-src 'self'",
+	}
+	assert.Equal(t, expected, conf.HTTP.Middlewares["Middleware0"].Headers)
+
+	assert.Empty(t, ValidateConfiguration(conf))
+}
+
+func TestDecodeConfigurationRedirect(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.middlewares.Middleware0.redirectregex.regex":       `^https?://([^/]+)/(a{1,3})/(.*)$`,
+		"traefik.http.middlewares.Middleware0.redirectregex.replacement": "https://${1}/$2/{{ .Request.Host }}/$3",
+		"traefik.http.middlewares.Middleware1.redirectregex.regex":       "^(.*",
+		"traefik.http.middlewares.Middleware1.redirectregex.replacement": "$1",
+		"traefik.http.middlewares.Middleware2.redirectregex.regex":       "^(.*)$",
+		"traefik.http.middlewares.Middleware2.redirectregex.replacement": "{{ .Request.Host",
+		"traefik.http.middlewares.Middleware3.redirectscheme.scheme":     "https",
+		"traefik.http.middlewares.Middleware3.redirectscheme.port":       "8443",
+		"traefik.http.middlewares.Middleware3.redirectscheme.permanent":  "true",
+		"traefik.http.middlewares.Middleware4.redirectscheme.port":       "8443",
+		"traefik.http.middlewares.Middleware5.redirectscheme.scheme":     "https",
+		"traefik.http.middlewares.Middleware5.redirectscheme.port":       "foo",
+	}
+
+	conf, err := DecodeConfiguration(labels)
+	require.NoError(t, err)
+
+	assert.Equal(t, &config.RedirectRegex{
+		Regex:       `^https?://([^/]+)/(a{1,3})/(.*)$`,
+		Replacement: "https://${1}/$2/{{ .Request.Host }}/$3",
+	}, conf.HTTP.Middlewares["Middleware0"].RedirectRegex)
+	assert.Equal(t, &config.RedirectScheme{Scheme: "https", Port: "8443", Permanent: true}, conf.HTTP.Middlewares["Middleware3"].RedirectScheme)
+
+	var messages []string
+	for _, err := range ValidateConfiguration(conf) {
+		messages = append(messages, err.Error())
+	}
+
+	expected := []string{
+		"traefik.http.middlewares.Middleware1: RedirectRegex: invalid regex \"^(.*\": error parsing regexp: missing closing ): `^(.*`",
+		"traefik.http.middlewares.Middleware2: RedirectRegex: invalid replacement \"{{ .Request.Host\": template: replacement:1: unclosed action",
+		"traefik.http.middlewares.Middleware4: RedirectScheme: scheme must not be empty",
+		"traefik.http.middlewares.Middleware5: RedirectScheme: invalid port \"foo\": a number between 1 and 65535 is expected",
+	}
+	assert.Equal(t, expected, messages)
+}
+
+func TestDecodeConfigurationPath(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.middlewares.Middleware0.addprefix.prefix":             "/v2",
+		"traefik.http.middlewares.Middleware1.addprefix.prefix":             "",
+		"traefik.http.middlewares.Middleware2.replacepath.path":             "/foo%2Fbar",
+		"traefik.http.middlewares.Middleware3.replacepath.path":             "/foo%zz",
+		"traefik.http.middlewares.Middleware4.replacepathregex.regex":       "^/foo/(.*)",
+		"traefik.http.middlewares.Middleware4.replacepathregex.replacement": "/bar/$1",
+		"traefik.http.middlewares.Middleware5.replacepathregex.regex":       "^/foo/(.*",
+		"traefik.http.middlewares.Middleware5.replacepathregex.replacement": "/bar/$1",
+		"traefik.http.middlewares.Middleware6.replacepathregex.regex":       "^/foo/(.*)",
+	}
+
+	conf, err := DecodeConfiguration(labels)
+	require.NoError(t, err)
+
+	assert.Equal(t, &config.AddPrefix{Prefix: "/v2"}, conf.HTTP.Middlewares["Middleware0"].AddPrefix)
+	assert.Equal(t, &config.ReplacePath{Path: "/foo%2Fbar"}, conf.HTTP.Middlewares["Middleware2"].ReplacePath)
+	assert.Equal(t, &config.ReplacePathRegex{Regex: "^/foo/(.*)", Replacement: "/bar/$1"}, conf.HTTP.Middlewares["Middleware4"].ReplacePathRegex)
+
+	var messages []string
+	for _, err := range ValidateConfiguration(conf) {
+		messages = append(messages, err.Error())
+	}
+
+	expected := []string{
+		"traefik.http.middlewares.Middleware1: AddPrefix: prefix must not be empty",
+		"traefik.http.middlewares.Middleware3: ReplacePath: invalid path \"/foo%zz\": invalid URL escape \"%zz\"",
+		"traefik.http.middlewares.Middleware5: ReplacePathRegex: invalid regex \"^/foo/(.*\": error parsing regexp: missing closing ): `^/foo/(.*`",
+		"traefik.http.middlewares.Middleware6: ReplacePathRegex: replacement must not be empty",
+	}
+	assert.Equal(t, expected, messages)
+}
+
+func TestDecodeConfigurationChain(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.middlewares.Middleware0.chain.middlewares": "https-only, file.known-ips,auth-users",
+		"traefik.http.middlewares.Middleware1.chain.middlewares": "",
+		"traefik.http.middlewares.Middleware2.chain.middlewares": "https-only,,auth-users",
+	}
+
+	conf, err := DecodeConfiguration(labels)
+	require.NoError(t, err)
+
+	assert.Equal(t, &config.Chain{Middlewares: []string{"https-only", "file.known-ips", "auth-users"}}, conf.HTTP.Middlewares["Middleware0"].Chain)
+
+	var messages []string
+	for _, err := range ValidateConfiguration(conf) {
+		messages = append(messages, err.Error())
+	}
+
+	expected := []string{
+		"traefik.http.middlewares.Middleware1: Chain: middlewares must not be empty",
+		"traefik.http.middlewares.Middleware2: Chain: the middleware name at index 1 must not be empty",
+	}
+	assert.Equal(t, expected, messages)
+}
+
+func TestDecodeConfigurationCircuitBreaker(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.middlewares.Middleware0.circuitbreaker.expression":       "NetworkErrorRatio() > 0.3",
+		"traefik.http.middlewares.Middleware0.circuitbreaker.fallbackduration": "30s",
+		"traefik.http.middlewares.Middleware1.circuitbreaker.expression":       "NetworkErrorRatio( > 0.3",
+		"traefik.http.middlewares.Middleware2.circuitbreaker.expression":       "NetworkErrorRatio() > 0.3",
+		"traefik.http.middlewares.Middleware2.circuitbreaker.responsecode":     "42",
+		"traefik.http.middlewares.Middleware3.circuitbreaker.responsecode":     "503",
+	}
+
+	conf, err := DecodeConfiguration(labels)
+	require.NoError(t, err)
+
+	assert.Equal(t, &config.CircuitBreaker{
+		Expression:       "NetworkErrorRatio() > 0.3",
+		CheckPeriod:      types.Duration(100 * time.Millisecond),
+		FallbackDuration: types.Duration(30 * time.Second),
+		RecoveryDuration: types.Duration(10 * time.Second),
+		ResponseCode:     503,
+	}, conf.HTTP.Middlewares["Middleware0"].CircuitBreaker)
+
+	var messages []string
+	for _, err := range ValidateConfiguration(conf) {
+		messages = append(messages, err.Error())
+	}
+
+	require.Len(t, messages, 3)
+	assert.Contains(t, messages[0], "traefik.http.middlewares.Middleware1: CircuitBreaker: invalid expression \"NetworkErrorRatio( > 0.3\"")
+	assert.Equal(t, "traefik.http.middlewares.Middleware2: CircuitBreaker: invalid response code 42: a status code between 100 and 599 is expected", messages[1])
+	assert.Equal(t, "traefik.http.middlewares.Middleware3: CircuitBreaker: expression must not be empty", messages[2])
+}
+
+func TestDecodeConfigurationForwardAuth(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.middlewares.Middleware0.forwardauth.address":                "https://auth.example.com/verify",
+		"traefik.http.middlewares.Middleware0.forwardauth.authresponseheaders":    "X-Auth-User, X-Auth-Group",
+		"traefik.http.middlewares.Middleware0.forwardauth.trustforwardheader":     "true",
+		"traefik.http.middlewares.Middleware0.forwardauth.tls.ca":                 "path/to/ca.crt",
+		"traefik.http.middlewares.Middleware0.forwardauth.tls.insecureskipverify": "false",
+		"traefik.http.middlewares.Middleware1.forwardauth.trustforwardheader":     "true",
+		"traefik.http.middlewares.Middleware2.forwardauth.address":                "auth.example.com",
+	}
+
+	conf, err := DecodeConfiguration(labels)
+	require.NoError(t, err)
+
+	assert.Equal(t, &config.ForwardAuth{
+		Address:             "https://auth.example.com/verify",
+		TLS:                 &config.ClientTLS{CA: "path/to/ca.crt"},
+		TrustForwardHeader:  true,
+		AuthResponseHeaders: []string{"X-Auth-User", "X-Auth-Group"},
+	}, conf.HTTP.Middlewares["Middleware0"].ForwardAuth)
+
+	var messages []string
+	for _, err := range ValidateConfiguration(conf) {
+		messages = append(messages, err.Error())
+	}
+
+	expected := []string{
+		"traefik.http.middlewares.Middleware1: ForwardAuth: address must not be empty",
+		"traefik.http.middlewares.Middleware2: ForwardAuth: invalid address \"auth.example.com\": an http or https URL is expected",
+	}
+	assert.Equal(t, expected, messages)
+}
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func int64Ptr(value int64) *int64 {
+	return &value
+}
+
+func TestDecodeConfigurationCaseInsensitive(t *testing.T) {
+	// The field names are lowercase, and the element names, which are kept as they are, are not.
+	labels := map[string]string{
+		"traefik.http.routers.Router0.rule":                                       "Host(`foo`)",
+		"traefik.http.routers.Router0.entrypoints":                                "web, websecure",
+		"traefik.http.routers.Router0.middlewares":                                "Middleware0",
+		"traefik.http.routers.Router0.service":                                    "Service0",
+		"traefik.http.routers.Router0.tls":                                        "true",
+		"traefik.http.services.Service0.loadbalancer.server.port":                 "8080",
+		"traefik.http.services.Service0.loadbalancer.passhostheader":              "false",
+		"traefik.http.services.Service0.loadbalancer.sticky.cookie.name":          "Cookie0",
+		"traefik.http.middlewares.Middleware0.headers.customrequestheaders.X-Foo": "Bar",
+		"traefik.http.middlewares.Middleware1.forwardauth.address":                "https://auth",
+		"traefik.http.middlewares.Middleware1.forwardauth.tls.insecureskipverify": "true",
+		"traefik.tcp.routers.Router0.rule":                                        "HostSNI(`foo`)",
+		"traefik.tcp.routers.Router0.tls.passthrough":                             "true",
+		"traefik.tcp.services.Service0.loadbalancer.server.port":                  "8443",
+	}
+
+	expected, err := DecodeConfiguration(labels)
+	require.NoError(t, err)
+	require.Len(t, expected.HTTP.Routers, 1)
+	require.NotNil(t, expected.HTTP.Routers["Router0"].TLS)
+	require.True(t, expected.TCP.Routers["Router0"].TLS.Passthrough)
+
+	random := rand.New(rand.NewSource(1))
+
+	randomCase := func(key string) string {
+		parts := strings.Split(key, ".")
+		for i, part := range parts {
+			if strings.ToLower(part) != part {
+				continue
+			}
+
+			runes := []rune(part)
+			for j := range runes {
+				if random.Intn(2) == 0 {
+					runes[j] = unicode.ToUpper(runes[j])
+				}
+			}
+			parts[i] = string(runes)
+		}
+		return strings.Join(parts, ".")
+	}
+
+	for i := 0; i < 100; i++ {
+		mixed := make(map[string]string, len(labels))
+		for key, value := range labels {
+			mixed[randomCase(key)] = value
+		}
+
+		conf, err := DecodeConfiguration(mixed)
+		require.NoError(t, err, "%v", mixed)
+		require.Equal(t, expected, conf, "%v", mixed)
+
+		conf, errs := DecodeConfigurationPerElement(mixed)
+		require.Empty(t, errs, "%v", mixed)
+		require.Equal(t, expected, conf, "%v", mixed)
+	}
+}
+
+func TestDecodeConfigurationCaseInsensitiveNames(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.routers.Router0.rule":    "Host(`foo`)",
+		"traefik.http.routers.router0.service": "Service0",
+	}
+
+	expected := map[string]*config.Router{
+		"Router0": {Rule: "Host(`foo`)", Service: "Service0"},
+	}
+
+	conf, err := DecodeConfiguration(labels)
+	require.NoError(t, err)
+	assert.Equal(t, expected, conf.HTTP.Routers)
+
+	labels["traefik.http.routers.router0.unknown"] = "foobar"
+
+	conf, errs := DecodeConfigurationPerElement(labels)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "traefik.http.routers.Router0", errs[0].Element)
+	assert.Empty(t, conf.HTTP.Routers)
+}
+
+func TestDecodeConfigurationErrorPath(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.services.Service0.loadbalancer.PASSHOSTHEADER": "foobar",
+	}
+
+	_, err := DecodeConfiguration(labels)
+	assert.EqualError(t, err, `HTTP.Services.Service0.LoadBalancer.PassHostHeader: strconv.ParseBool: parsing "foobar": invalid syntax`)
+
+	labels = map[string]string{
+		"traefik.tcp.services.Service0.LOADBALANCER.server.unknown": "foobar",
+	}
+
+	_, err = DecodeConfiguration(labels)
+	assert.EqualError(t, err, `TCP.Services.Service0.LoadBalancer.server: unknown field "unknown"`)
+}
+
+func TestDecodeConfigurationDottedNames(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.routers.`my.app`.rule":                      "Host(`my.app`)",
+		"traefik.http.routers.`my.app`.middlewares":               "my.auth",
+		"traefik.http.middlewares.`my.auth`.basicauth.realm":      "foobar",
+		"traefik.http.services.`my.app`.loadbalancer.server.port": "8080",
+	}
+
+	conf, err := DecodeConfiguration(labels)
+	require.NoError(t, err)
+
+	require.Contains(t, conf.HTTP.Routers, "my.app")
+	assert.Equal(t, "Host(`my.app`)", conf.HTTP.Routers["my.app"].Rule)
+	assert.Equal(t, []string{"my.auth"}, conf.HTTP.Routers["my.app"].Middlewares)
+	assert.Contains(t, conf.HTTP.Middlewares, "my.auth")
+	assert.Contains(t, conf.HTTP.Services, "my.app")
+
+	encoded, err := EncodeConfiguration(conf)
+	require.NoError(t, err)
+	assert.Equal(t, "Host(`my.app`)", encoded["traefik.HTTP.Routers.`my.app`.Rule"])
+	assert.Equal(t, "foobar", encoded["traefik.HTTP.Middlewares.`my.auth`.BasicAuth.Realm"])
+	assert.Equal(t, "8080", encoded["traefik.HTTP.Services.`my.app`.LoadBalancer.server.Port"])
+}
+
+func TestDecodeConfigurationUnquotedDottedName(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.routers.my.app.rule": "Host(`my.app`)",
+	}
+
+	_, err := DecodeConfiguration(labels)
+	assert.EqualError(t, err, `HTTP.Routers.my: unknown field "app": a name containing dots must be quoted with backticks, e.g. `+"`my.app`")
+
+	labels = map[string]string{
+		"traefik.http.routers.`my.app`.rulle": "Host(`my.app`)",
+	}
+
+	_, err = DecodeConfiguration(labels)
+	assert.EqualError(t, err, `HTTP.Routers.`+"`my.app`"+`: unknown field "rulle" (did you mean "rule"?)`)
+}
+
+func TestDecodeConfigurationPerElementDottedNames(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.routers.`my.app`.rule":  "Host(`my.app`)",
+		"traefik.http.routers.`my.api`.rulle": "Host(`my.api`)",
+	}
+
+	conf, errs := DecodeConfigurationPerElement(labels)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "traefik.http.routers.`my.api`", errs[0].Element)
+	assert.Contains(t, conf.HTTP.Routers, "my.app")
+}
+
+func TestDecodeConfigurationSuggestion(t *testing.T) {
+	testCases := []struct {
+		label    string
+		expected string
+	}{
+		{
+			label:    "traefik.http.routers.R.rulle",
+			expected: `HTTP.Routers.R: unknown field "rulle" (did you mean "rule"?)`,
+		},
+		{
+			label:    "traefik.http.routers.R.entrypoint",
+			expected: `HTTP.Routers.R: unknown field "entrypoint" (did you mean "entrypoints"?)`,
+		},
+		{
+			label:    "traefik.http.routers.R.midlewares",
+			expected: `HTTP.Routers.R: unknown field "midlewares" (did you mean "middlewares"?)`,
+		},
+		{
+			label:    "traefik.http.services.S.loadbalancer.passhostheaders",
+			expected: `HTTP.Services.S.LoadBalancer: unknown field "passhostheaders" (did you mean "passhostheader"?)`,
+		},
+		{
+			label:    "traefik.http.services.S.loadbalancr.passhostheader",
+			expected: `HTTP.Services.S: unknown field "loadbalancr" (did you mean "loadbalancer"?)`,
+		},
+		{
+			label:    "traefik.http.routers.R.foobar",
+			expected: `HTTP.Routers.R: unknown field "foobar"`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.label, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := DecodeConfiguration(map[string]string{test.label: "true"})
+			assert.EqualError(t, err, test.expected)
+		})
+	}
+}
+
+func TestValidateConfiguration(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.routers.Router0.rule":                                            "Host(`foo`)",
+		"traefik.http.routers.Router1.rule":                                            " ",
+		"traefik.http.routers.Router2.entrypoints":                                     "web",
+		"traefik.http.routers.Router3.fallback":                                        "true",
+		"traefik.http.routers.Router4.fallback":                                        "true",
+		"traefik.http.routers.Router4.rule":                                            "Host(`foo`)",
+		"traefik.http.middlewares.Middleware0.maxconn.amount":                          "42",
+		"traefik.http.middlewares.Middleware1.maxconn.amount":                          "0",
+		"traefik.http.middlewares.Middleware2.ipwhitelist.sourcerange":                 "foo",
+		"traefik.http.middlewares.Middleware3.ipwhitelist.ipstrategy.depth":            "2",
+		"traefik.http.middlewares.Middleware3.ipwhitelist.sourcerange":                 "2001:db8::/32",
+		"traefik.http.middlewares.Middleware4.ipwhitelist.ipstrategy.depth":            "-1",
+		"traefik.http.middlewares.Middleware4.ipwhitelist.sourcerange":                 "2001:db8::/32",
+		"traefik.http.middlewares.Middleware5.ratelimit.average":                       "10",
+		"traefik.http.middlewares.Middleware5.ratelimit.period":                        "0",
+		"traefik.http.services.Service0.loadbalancer.server.port":                      "8080",
+		"traefik.http.services.Service1.loadbalancer.sticky.cookie":                    "true",
+		"traefik.http.services.Service2.loadbalancer.passhostheader":                   "true",
+		"traefik.http.services.Service3.loadbalancer.server.port":                      "8080",
+		"traefik.http.services.Service3.loadbalancer.responseforwarding.flushinterval": "foobar",
+	}
+
+	conf, err := DecodeConfiguration(labels)
+	require.NoError(t, err)
+
+	err = parser.Validate(conf)
+	assert.EqualError(t, err, "HTTP.Routers.Router1: rule must not be empty, "+
+		"HTTP.Routers.Router2: rule must not be empty, "+
+		"HTTP.Routers.Router4: a fallback router must not have a rule, "+
+		"HTTP.Middlewares.Middleware1.MaxConn: amount must be greater than 0, "+
+		"HTTP.Middlewares.Middleware2.IPWhiteList: invalid sourceRange: parsing CIDR trusted IPs foo: invalid CIDR address: foo, "+
+		"HTTP.Middlewares.Middleware4.IPWhiteList.IPStrategy: depth must not be negative, "+
+		"HTTP.Middlewares.Middleware5.RateLimit: period must be greater than 0, "+
+		"HTTP.Services.Service2.LoadBalancer: at least one server, or a sticky configuration, is required, "+
+		`HTTP.Services.Service3.LoadBalancer.ResponseForwarding: invalid duration "foobar": a duration such as 10s or 1m30s, or a number of seconds, is expected`)
+
+	errs := ValidateConfiguration(conf)
+
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+
+	expected := []string{
+		"traefik.http.routers.Router1: rule must not be empty",
+		"traefik.http.routers.Router2: rule must not be empty",
+		"traefik.http.routers.Router4: a fallback router must not have a rule",
+		"traefik.http.middlewares.Middleware1: MaxConn: amount must be greater than 0",
+		"traefik.http.middlewares.Middleware2: IPWhiteList: invalid sourceRange: parsing CIDR trusted IPs foo: invalid CIDR address: foo",
+		"traefik.http.middlewares.Middleware4: IPWhiteList.IPStrategy: depth must not be negative",
+		"traefik.http.middlewares.Middleware5: RateLimit: period must be greater than 0",
+		"traefik.http.services.Service2: LoadBalancer: at least one server, or a sticky configuration, is required",
+		`traefik.http.services.Service3: LoadBalancer.ResponseForwarding: invalid duration "foobar": a duration such as 10s or 1m30s, or a number of seconds, is expected`,
+	}
+	assert.Equal(t, expected, messages)
+
+	assert.Len(t, conf.HTTP.Routers, 2)
+	assert.Contains(t, conf.HTTP.Routers, "Router0")
+	assert.Contains(t, conf.HTTP.Routers, "Router3")
+	assert.Len(t, conf.HTTP.Middlewares, 2)
+	assert.Contains(t, conf.HTTP.Middlewares, "Middleware0")
+	assert.Contains(t, conf.HTTP.Middlewares, "Middleware3")
+	assert.Len(t, conf.HTTP.Services, 2)
+	assert.Contains(t, conf.HTTP.Services, "Service0")
+	assert.Contains(t, conf.HTTP.Services, "Service1")
+
+	assert.NoError(t, parser.Validate(conf))
+}
+
+func TestEncodeConfigurationRedaction(t *testing.T) {
+	configuration := &config.Configuration{
+		HTTP: &config.HTTPConfiguration{
+			Middlewares: map[string]*config.Middleware{
+				"Middleware0": {BasicAuth: &config.BasicAuth{Users: []string{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"}}},
+				"Middleware1": {DigestAuth: &config.DigestAuth{Users: []string{"test:traefik:a2688e031edb4be6a3797f3882655c05"}}},
+				"Middleware2": {ForwardAuth: &config.ForwardAuth{Address: "https://auth", TLS: &config.ClientTLS{Key: "/etc/traefik/auth.key"}}},
+			},
+		},
+	}
+
+	labels, err := EncodeConfiguration(configuration)
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"traefik.HTTP.Middlewares.Middleware0.BasicAuth.Users":     "xxxx",
+		"traefik.HTTP.Middlewares.Middleware1.DigestAuth.Users":    "xxxx",
+		"traefik.HTTP.Middlewares.Middleware2.ForwardAuth.Address": "https://auth",
+		"traefik.HTTP.Middlewares.Middleware2.ForwardAuth.TLS.Key": "xxxx",
+	}
+	for key, value := range expected {
+		assert.Equal(t, value, labels[key], key)
+	}
+
+	labels, err = parser.EncodeWithOpts(configuration, parser.NodeOpts{OmitEmpty: true, NoRedact: true})
+	require.NoError(t, err)
+
+	expected = map[string]string{
+		"traefik.HTTP.Middlewares.Middleware0.BasicAuth.Users":     "test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/",
+		"traefik.HTTP.Middlewares.Middleware1.DigestAuth.Users":    "test:traefik:a2688e031edb4be6a3797f3882655c05",
+		"traefik.HTTP.Middlewares.Middleware2.ForwardAuth.Address": "https://auth",
+		"traefik.HTTP.Middlewares.Middleware2.ForwardAuth.TLS.Key": "/etc/traefik/auth.key",
+	}
+	for key, value := range expected {
+		assert.Equal(t, value, labels[key], key)
+	}
+
+	// The configuration keeps the real values.
+	assert.Equal(t, "/etc/traefik/auth.key", configuration.HTTP.Middlewares["Middleware2"].ForwardAuth.TLS.Key)
+}
+
+func TestDecodeConfigurationDefaults(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.middlewares.Middleware0.maxconn.amount":        "10",
+		"traefik.http.middlewares.Middleware1.maxconn.amount":        "10",
+		"traefik.http.middlewares.Middleware1.maxconn.extractorfunc": "client.ip",
+		"traefik.http.services.Service0.loadbalancer.server.port":    "8080",
+		"traefik.http.services.Service1.loadbalancer.server.port":    "8080",
+		"traefik.http.services.Service1.loadbalancer.passhostheader": "false",
+	}
+
+	conf, err := DecodeConfiguration(labels)
+	require.NoError(t, err)
+
+	assert.Equal(t, "request.host", conf.HTTP.Middlewares["Middleware0"].MaxConn.ExtractorFunc)
+	assert.Equal(t, "client.ip", conf.HTTP.Middlewares["Middleware1"].MaxConn.ExtractorFunc)
+
+	assert.True(t, conf.HTTP.Services["Service0"].LoadBalancer.PassHostHeader)
+	assert.False(t, conf.HTTP.Services["Service1"].LoadBalancer.PassHostHeader)
+}
+
+func TestEncodeConfigurationRoundTrip(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		labels map[string]string
+	}{
+		{
+			desc: "router and service",
+			labels: map[string]string{
+				"traefik.http.routers.Router1.rule":                       "Host(`foo.bar`)",
+				"traefik.http.routers.Router1.entrypoints":                "web",
+				"traefik.http.routers.Router1.service":                    "Service1",
+				"traefik.http.services.Service1.loadbalancer.server.port": "8080",
+			},
+		},
+		{
+			desc: "middlewares",
+			labels: map[string]string{
+				"traefik.http.routers.Test.rule":                         "Host(`foo.bar`)",
+				"traefik.http.routers.Test.middlewares":                  "Middleware1, Middleware2",
+				"traefik.http.middlewares.Middleware1.addprefix.prefix":  "/foo",
+				"traefik.http.middlewares.Middleware1.basicauth.users":   "test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/,test2:$apr1$d9hr9HBB$4HxwgUir3HP4EsggP/QNo0",
+				"traefik.http.middlewares.Middleware2.maxconn.amount":    "42",
+				"traefik.http.middlewares.Middleware3.ratelimit.period":  "10s",
+				"traefik.http.middlewares.Middleware3.ratelimit.average": "100",
+			},
+		},
+		{
+			desc: "boolean differing from its default",
+			labels: map[string]string{
+				"traefik.http.services.Service1.loadbalancer.passhostheader": "false",
+				"traefik.http.services.Service1.loadbalancer.server.port":    "8080",
+				"traefik.http.services.Service1.loadbalancer.server.scheme":  "h2c",
+			},
+		},
+		{
+			desc: "pointers",
+			labels: map[string]string{
+				"traefik.http.routers.Router1.rule":                       "Host(`foo.bar`)",
+				"traefik.http.routers.Router1.tls":                        "true",
+				"traefik.http.services.app.loadbalancer.server.port":      "80",
+				"traefik.http.services.app.loadbalancer.server.weight":    "20",
+				"traefik.http.services.app.loadbalancer.sticky.cookie":    "true",
+				"traefik.http.services.app.loadbalancer.healthcheck.path": "/health",
+			},
+		},
+		{
+			desc: "TCP",
+			labels: map[string]string{
+				"traefik.tcp.routers.foo.rule":                      "HostSNI(`foo.bar`)",
+				"traefik.tcp.routers.foo.tls":                       "true",
+				"traefik.tcp.routers.bar.rule":                      "HostSNI(`bar.foo`)",
+				"traefik.tcp.routers.bar.service":                   "bar",
+				"traefik.tcp.routers.bar.tls.passthrough":           "true",
+				"traefik.tcp.services.foo.loadbalancer.server.port": "8080",
+			},
+		},
+		{
+			desc: "names containing dots",
+			labels: map[string]string{
+				"traefik.http.routers.`my.app`.rule":                      "Host(`my.app`)",
+				"traefik.http.services.`my.app`.loadbalancer.server.port": "8080",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			conf, err := DecodeConfiguration(test.labels)
+			require.NoError(t, err)
+
+			labels, err := EncodeConfigurationWithOpts(conf, parser.NodeOpts{OmitEmpty: true, NoRedact: true})
+			require.NoError(t, err)
+
+			decoded, err := DecodeConfiguration(labels)
+			require.NoError(t, err)
+
+			assert.Equal(t, conf, decoded)
+		})
+	}
+}
+
+func TestEncodeConfigurationRoundTripTyped(t *testing.T) {
+	conf := &config.Configuration{
+		HTTP: &config.HTTPConfiguration{
+			Routers: map[string]*config.Router{
+				"Router0": {
+					Rule:     "Host(`foo.bar`)",
+					Priority: 42,
+					TLS:      &config.RouterTLSConfig{},
+				},
+			},
+			Middlewares: map[string]*config.Middleware{
+				"Middleware0": {
+					MaxConn: &config.MaxConn{Amount: 42},
+				},
+				"Middleware1": {
+					RateLimit: &config.RateLimit{Period: types.Duration(90 * time.Second), Average: 100},
+				},
+			},
+			Services: map[string]*config.Service{
+				"Service0": {
+					LoadBalancer: &config.LoadBalancerService{
+						Sticky: &config.Sticky{Cookie: &config.Cookie{}},
+						Servers: []config.Server{
+							{Scheme: "http", Port: "8080"},
+						},
+					},
+				},
+			},
+		},
+		TCP: &config.TCPConfiguration{},
+	}
+
+	labels, err := EncodeConfigurationWithOpts(conf, parser.NodeOpts{OmitEmpty: true, NoRedact: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, "", labels["traefik.HTTP.Middlewares.Middleware0.MaxConn.ExtractorFunc"])
+	assert.Equal(t, "false", labels["traefik.HTTP.Services.Service0.LoadBalancer.PassHostHeader"])
+	assert.Equal(t, "1m30s", labels["traefik.HTTP.Middlewares.Middleware1.RateLimit.Period"])
+
+	decoded, err := DecodeConfiguration(labels)
+	require.NoError(t, err)
+
+	assert.Equal(t, conf, decoded)
+}
@@ -1,14 +1,28 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/containous/traefik/pkg/config/parser"
 	"github.com/containous/traefik/pkg/ip"
 	"github.com/containous/traefik/pkg/types"
+	"github.com/vulcand/oxy/cbreaker"
 )
 
 // +k8s:deepcopy-gen=true
 
 // Middleware holds the Middleware configuration.
 type Middleware struct {
+	Description       string             `json:"description,omitempty" toml:",omitempty"`
 	AddPrefix         *AddPrefix         `json:"addPrefix,omitempty"`
 	StripPrefix       *StripPrefix       `json:"stripPrefix,omitempty"`
 	StripPrefixRegex  *StripPrefixRegex  `json:"stripPrefixRegex,omitempty"`
@@ -32,6 +46,14 @@ type Middleware struct {
 	Retry             *Retry             `json:"retry,omitempty"`
 }
 
+// Mergeable tells if the given middleware is mergeable, i.e. if both middlewares only differ by their description.
+func (m *Middleware) Mergeable(middleware *Middleware) bool {
+	a, b := *m, *middleware
+	a.Description, b.Description = "", ""
+
+	return reflect.DeepEqual(a, b)
+}
+
 // +k8s:deepcopy-gen=true
 
 // AddPrefix holds the AddPrefix configuration.
@@ -39,6 +61,15 @@ type AddPrefix struct {
 	Prefix string `json:"prefix,omitempty"`
 }
 
+// Validate checks the AddPrefix configuration.
+func (a *AddPrefix) Validate() error {
+	if a.Prefix == "" {
+		return errors.New("prefix must not be empty")
+	}
+
+	return nil
+}
+
 // +k8s:deepcopy-gen=true
 
 // Auth holds the authentication configuration (BASIC, DIGEST, users).
@@ -52,7 +83,7 @@ type Auth struct {
 
 // BasicAuth holds the HTTP basic authentication configuration.
 type BasicAuth struct {
-	Users        Users  `json:"users,omitempty"`
+	Users        Users  `json:"users,omitempty" export:"redact"`
 	UsersFile    string `json:"usersFile,omitempty"`
 	Realm        string `json:"realm,omitempty"`
 	RemoveHeader bool   `json:"removeHeader,omitempty"`
@@ -77,11 +108,64 @@ type Chain struct {
 	Middlewares []string `json:"middlewares"`
 }
 
+// Validate checks the Chain configuration.
+func (c *Chain) Validate() error {
+	if len(c.Middlewares) == 0 {
+		return errors.New("middlewares must not be empty")
+	}
+
+	for i, name := range c.Middlewares {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("the middleware name at index %d must not be empty", i)
+		}
+	}
+
+	return nil
+}
+
 // +k8s:deepcopy-gen=true
 
 // CircuitBreaker holds the circuit breaker configuration.
+// The expression is evaluated every CheckPeriod: once it matches, the circuit breaker answers with ResponseCode
+// during FallbackDuration, then progressively sends the requests to the service again during RecoveryDuration.
 type CircuitBreaker struct {
-	Expression string `json:"expression,omitempty"`
+	Expression       string         `json:"expression,omitempty"`
+	CheckPeriod      types.Duration `json:"checkPeriod,omitempty" default:"100ms"`
+	FallbackDuration types.Duration `json:"fallbackDuration,omitempty" default:"10s"`
+	RecoveryDuration types.Duration `json:"recoveryDuration,omitempty" default:"10s"`
+	ResponseCode     int            `json:"responseCode,omitempty" default:"503"`
+}
+
+// SetDefaults Default values for a CircuitBreaker, declared by the default tags of its fields.
+func (c *CircuitBreaker) SetDefaults() {
+	_ = parser.ApplyDefaults(c)
+}
+
+// Validate checks the CircuitBreaker configuration.
+func (c *CircuitBreaker) Validate() error {
+	if c.Expression == "" {
+		return errors.New("expression must not be empty")
+	}
+
+	if _, err := cbreaker.New(http.NotFoundHandler(), c.Expression); err != nil {
+		return fmt.Errorf("invalid expression %q: %v", c.Expression, err)
+	}
+
+	if c.CheckPeriod < 0 {
+		return fmt.Errorf("the check period must not be negative: %s", time.Duration(c.CheckPeriod))
+	}
+	if c.FallbackDuration < 0 {
+		return fmt.Errorf("the fallback duration must not be negative: %s", time.Duration(c.FallbackDuration))
+	}
+	if c.RecoveryDuration < 0 {
+		return fmt.Errorf("the recovery duration must not be negative: %s", time.Duration(c.RecoveryDuration))
+	}
+
+	if c.ResponseCode != 0 && (c.ResponseCode < 100 || c.ResponseCode > 599) {
+		return fmt.Errorf("invalid response code %d: a status code between 100 and 599 is expected", c.ResponseCode)
+	}
+
+	return nil
 }
 
 // +k8s:deepcopy-gen=true
@@ -93,7 +177,7 @@ type Compress struct{}
 
 // DigestAuth holds the Digest HTTP authentication configuration.
 type DigestAuth struct {
-	Users        Users  `json:"users,omitempty"`
+	Users        Users  `json:"users,omitempty" export:"redact"`
 	UsersFile    string `json:"usersFile,omitempty"`
 	RemoveHeader bool   `json:"removeHeader,omitempty"`
 	Realm        string `json:"realm,omitempty" mapstructure:","`
@@ -119,6 +203,23 @@ type ForwardAuth struct {
 	AuthResponseHeaders []string   `description:"Headers to be forwarded from auth response" json:"authResponseHeaders,omitempty"`
 }
 
+// Validate checks the ForwardAuth configuration.
+func (f *ForwardAuth) Validate() error {
+	if f.Address == "" {
+		return errors.New("address must not be empty")
+	}
+
+	u, err := url.Parse(f.Address)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %v", f.Address, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid address %q: an http or https URL is expected", f.Address)
+	}
+
+	return nil
+}
+
 // +k8s:deepcopy-gen=true
 
 // Headers holds the custom header configuration.
@@ -240,6 +341,21 @@ func (s *IPStrategy) Get() (ip.Strategy, error) {
 	return &ip.RemoteAddrStrategy{}, nil
 }
 
+// Validate checks the IPStrategy configuration.
+func (s *IPStrategy) Validate() error {
+	if s.Depth < 0 {
+		return errors.New("depth must not be negative")
+	}
+
+	if len(s.ExcludedIPs) > 0 {
+		if _, err := ip.NewChecker(s.ExcludedIPs); err != nil {
+			return fmt.Errorf("invalid excludedIPs: %v", err)
+		}
+	}
+
+	return nil
+}
+
 // +k8s:deepcopy-gen=true
 
 // IPWhiteList holds the ip white list configuration.
@@ -248,17 +364,38 @@ type IPWhiteList struct {
 	IPStrategy  *IPStrategy `json:"ipStrategy,omitempty" label:"allowEmpty"`
 }
 
+// Validate checks the IPWhiteList configuration.
+func (w *IPWhiteList) Validate() error {
+	if len(w.SourceRange) == 0 {
+		return errors.New("sourceRange must not be empty")
+	}
+
+	if _, err := ip.NewChecker(w.SourceRange); err != nil {
+		return fmt.Errorf("invalid sourceRange: %v", err)
+	}
+
+	return nil
+}
+
 // +k8s:deepcopy-gen=true
 
 // MaxConn holds maximum connection configuration.
 type MaxConn struct {
 	Amount        int64  `json:"amount,omitempty"`
-	ExtractorFunc string `json:"extractorFunc,omitempty"`
+	ExtractorFunc string `json:"extractorFunc,omitempty" default:"request.host"`
 }
 
-// SetDefaults Default values for a MaxConn.
+// SetDefaults Default values for a MaxConn, declared by the default tags of its fields.
 func (m *MaxConn) SetDefaults() {
-	m.ExtractorFunc = "request.host"
+	_ = parser.ApplyDefaults(m)
+}
+
+// Validate checks the MaxConn configuration.
+func (m *MaxConn) Validate() error {
+	if m.Amount <= 0 {
+		return errors.New("amount must be greater than 0")
+	}
+	return nil
 }
 
 // +k8s:deepcopy-gen=true
@@ -271,25 +408,61 @@ type PassTLSClientCert struct {
 
 // +k8s:deepcopy-gen=true
 
-// Rate holds the rate limiting configuration for a specific time period.
-type Rate struct {
-	Period  types.Duration `json:"period,omitempty"`
-	Average int64          `json:"average,omitempty"`
-	Burst   int64          `json:"burst,omitempty"`
+// RateLimit holds the rate limiting configuration for a given router.
+// The requests of each source are limited to Average requests by Period, with bursts of up to Burst requests.
+type RateLimit struct {
+	Average         int64            `json:"average,omitempty"`
+	Period          types.Duration   `json:"period,omitempty" default:"1s"`
+	Burst           int64            `json:"burst,omitempty" default:"1"`
+	SourceCriterion *SourceCriterion `json:"sourceCriterion,omitempty" label:"allowEmpty"`
+}
+
+// SetDefaults Default values for a RateLimit, declared by the default tags of its fields.
+func (r *RateLimit) SetDefaults() {
+	_ = parser.ApplyDefaults(r)
+}
+
+// Validate checks the RateLimit configuration.
+func (r *RateLimit) Validate() error {
+	if r.Average <= 0 {
+		return errors.New("average must be greater than 0")
+	}
+	if r.Period <= 0 {
+		return errors.New("period must be greater than 0")
+	}
+	if r.Burst <= 0 {
+		return errors.New("burst must be greater than 0")
+	}
+	return nil
 }
 
 // +k8s:deepcopy-gen=true
 
-// RateLimit holds the rate limiting configuration for a given frontend.
-type RateLimit struct {
-	RateSet map[string]*Rate `json:"rateset,omitempty"`
-	// FIXME replace by ipStrategy see oxy and replace
-	ExtractorFunc string `json:"extractorFunc,omitempty"`
+// SourceCriterion defines what identifies the source of a request.
+// Only one criterion can be set: by default, the source is the client IP, i.e. the remote address of the request.
+type SourceCriterion struct {
+	IPStrategy        *IPStrategy `json:"ipStrategy,omitempty" label:"allowEmpty"`
+	RequestHeaderName string      `json:"requestHeaderName,omitempty"`
+	RequestHost       bool        `json:"requestHost,omitempty"`
 }
 
-// SetDefaults Default values for a MaxConn.
-func (r *RateLimit) SetDefaults() {
-	r.ExtractorFunc = "request.host"
+// Validate checks the SourceCriterion configuration.
+func (s *SourceCriterion) Validate() error {
+	var criteria int
+	if s.IPStrategy != nil {
+		criteria++
+	}
+	if s.RequestHeaderName != "" {
+		criteria++
+	}
+	if s.RequestHost {
+		criteria++
+	}
+
+	if criteria > 1 {
+		return errors.New("only one of ipStrategy, requestHeaderName and requestHost can be set")
+	}
+	return nil
 }
 
 // +k8s:deepcopy-gen=true
@@ -301,6 +474,27 @@ type RedirectRegex struct {
 	Permanent   bool   `json:"permanent,omitempty"`
 }
 
+// Validate checks the RedirectRegex configuration.
+func (r *RedirectRegex) Validate() error {
+	if r.Regex == "" {
+		return errors.New("regex must not be empty")
+	}
+
+	if _, err := regexp.Compile(r.Regex); err != nil {
+		return fmt.Errorf("invalid regex %q: %v", r.Regex, err)
+	}
+
+	if r.Replacement == "" {
+		return errors.New("replacement must not be empty")
+	}
+
+	if _, err := template.New("replacement").Parse(r.Replacement); err != nil {
+		return fmt.Errorf("invalid replacement %q: %v", r.Replacement, err)
+	}
+
+	return nil
+}
+
 // +k8s:deepcopy-gen=true
 
 // RedirectScheme holds the scheme redirection configuration.
@@ -310,6 +504,21 @@ type RedirectScheme struct {
 	Permanent bool   `json:"permanent,omitempty"`
 }
 
+// Validate checks the RedirectScheme configuration.
+func (r *RedirectScheme) Validate() error {
+	if r.Scheme == "" {
+		return errors.New("scheme must not be empty")
+	}
+
+	if r.Port != "" {
+		if port, err := strconv.Atoi(r.Port); err != nil || port <= 0 || port > 65535 {
+			return fmt.Errorf("invalid port %q: a number between 1 and 65535 is expected", r.Port)
+		}
+	}
+
+	return nil
+}
+
 // +k8s:deepcopy-gen=true
 
 // ReplacePath holds the ReplacePath configuration.
@@ -317,6 +526,19 @@ type ReplacePath struct {
 	Path string `json:"path,omitempty"`
 }
 
+// Validate checks the ReplacePath configuration.
+func (r *ReplacePath) Validate() error {
+	if r.Path == "" {
+		return errors.New("path must not be empty")
+	}
+
+	if _, err := url.PathUnescape(r.Path); err != nil {
+		return fmt.Errorf("invalid path %q: %v", r.Path, err)
+	}
+
+	return nil
+}
+
 // +k8s:deepcopy-gen=true
 
 // ReplacePathRegex holds the ReplacePathRegex configuration.
@@ -325,11 +547,46 @@ type ReplacePathRegex struct {
 	Replacement string `json:"replacement,omitempty"`
 }
 
+// Validate checks the ReplacePathRegex configuration.
+func (r *ReplacePathRegex) Validate() error {
+	if r.Regex == "" {
+		return errors.New("regex must not be empty")
+	}
+
+	if _, err := regexp.Compile(r.Regex); err != nil {
+		return fmt.Errorf("invalid regex %q: %v", r.Regex, err)
+	}
+
+	if r.Replacement == "" {
+		return errors.New("replacement must not be empty")
+	}
+
+	return nil
+}
+
 // +k8s:deepcopy-gen=true
 
 // Retry holds the retry configuration.
+// The attempts after the first one wait for an exponential backoff, starting at InitialInterval, unless it is zero.
+// Each attempt is canceled after PerTryTimeout, unless it is zero, and is retried if the response headers were not sent yet.
 type Retry struct {
-	Attempts int `description:"Number of attempts" export:"true"`
+	Attempts        int            `description:"Number of attempts" json:"attempts,omitempty" export:"true"`
+	InitialInterval types.Duration `description:"Interval before the first retry, increased exponentially for the next ones" json:"initialInterval,omitempty" export:"true"`
+	PerTryTimeout   types.Duration `description:"Timeout of each attempt" json:"perTryTimeout,omitempty" export:"true"`
+}
+
+// Validate checks the Retry configuration.
+func (r *Retry) Validate() error {
+	if r.Attempts <= 0 {
+		return errors.New("attempts must be greater than 0")
+	}
+	if r.InitialInterval < 0 {
+		return fmt.Errorf("the initial interval must be positive or zero: %s", time.Duration(r.InitialInterval))
+	}
+	if r.PerTryTimeout < 0 {
+		return fmt.Errorf("the per try timeout must be positive or zero: %s", time.Duration(r.PerTryTimeout))
+	}
+	return nil
 }
 
 // +k8s:deepcopy-gen=true
@@ -337,6 +594,13 @@ type Retry struct {
 // StripPrefix holds the StripPrefix configuration.
 type StripPrefix struct {
 	Prefixes []string `json:"prefixes,omitempty"`
+	// ForceSlash forwards the path / when a prefix is the whole path of the request, rather than an empty path.
+	ForceSlash bool `json:"forceSlash" default:"true"`
+}
+
+// SetDefaults Default values for a StripPrefix, declared by the default tags of its fields.
+func (s *StripPrefix) SetDefaults() {
+	_ = parser.ApplyDefaults(s)
 }
 
 // +k8s:deepcopy-gen=true
@@ -384,6 +648,6 @@ type ClientTLS struct {
 	CA                 string `description:"TLS CA" json:"ca,omitempty"`
 	CAOptional         bool   `description:"TLS CA.Optional" json:"caOptional,omitempty"`
 	Cert               string `description:"TLS cert" json:"cert,omitempty"`
-	Key                string `description:"TLS key" json:"key,omitempty"`
+	Key                string `description:"TLS key" json:"key,omitempty" export:"redact"`
 	InsecureSkipVerify bool   `description:"TLS insecure skip verify" json:"insecureSkipVerify,omitempty"`
 }
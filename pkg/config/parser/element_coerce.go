@@ -0,0 +1,198 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/pkg/types"
+)
+
+// coercion sets the field to the value of a node.
+type coercion func(field reflect.Value, value string) error
+
+// The coercions of the filler, from the value of a node to a field, by type then by kind.
+// The value of a node is a string whatever its source: labels, flags, environment variables, or a TOML, YAML or JSON file,
+// whose numbers and booleans are formatted in their shortest form, e.g. 8080, 0.5 or true.
+// So that a same configuration gives a same result from every source:
+//   - a string field takes the value as is, e.g. a port given as a number in a file;
+//   - a bool field takes true or false case-insensitively, or any value accepted by strconv.ParseBool, e.g. 1 or 0;
+//   - an integer field takes an integer, possibly written as a number without fractional part, e.g. 4.2e1 in JSON,
+//     and a value out of the range of the field is an error;
+//   - a float field takes a number;
+//   - a duration field takes a duration such as 10s, or a number of seconds;
+//   - a time field takes a RFC 3339 time, with its time zone, or a date;
+//   - an int64 field tagged unit:"bytes" takes a number of bytes, with an optional SI or binary suffix, e.g. 10MB or 10Mi.
+var (
+	typeCoercions = map[reflect.Type]coercion{
+		reflect.TypeOf(types.Duration(0)): setDuration,
+		reflect.TypeOf(time.Duration(0)):  setDuration,
+		reflect.TypeOf(time.Time{}):       setTime,
+	}
+
+	kindCoercions = map[reflect.Kind]coercion{
+		reflect.String:  setString,
+		reflect.Bool:    setBool,
+		reflect.Int:     setInt,
+		reflect.Int8:    setInt,
+		reflect.Int16:   setInt,
+		reflect.Int32:   setInt,
+		reflect.Int64:   setInt,
+		reflect.Uint:    setUint,
+		reflect.Uint8:   setUint,
+		reflect.Uint16:  setUint,
+		reflect.Uint32:  setUint,
+		reflect.Uint64:  setUint,
+		reflect.Float32: setFloat,
+		reflect.Float64: setFloat,
+	}
+)
+
+// isScalar tells whether a field of the type is set by a coercion of the value of its node.
+func isScalar(rType reflect.Type) bool {
+	if _, ok := typeCoercions[rType]; ok {
+		return true
+	}
+
+	_, ok := kindCoercions[rType.Kind()]
+	return ok
+}
+
+// setScalar sets the field to the value, with the coercion of its type.
+func setScalar(field reflect.Value, value string) error {
+	if coerce, ok := typeCoercions[field.Type()]; ok {
+		return coerce(field, value)
+	}
+
+	return kindCoercions[field.Kind()](field, value)
+}
+
+func setString(field reflect.Value, value string) error {
+	field.SetString(value)
+	return nil
+}
+
+func setBool(field reflect.Value, value string) error {
+	switch {
+	case strings.EqualFold(value, "true"):
+		field.SetBool(true)
+		return nil
+	case strings.EqualFold(value, "false"):
+		field.SetBool(false)
+		return nil
+	}
+
+	val, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+
+	field.SetBool(val)
+	return nil
+}
+
+func setInt(field reflect.Value, value string) error {
+	bitSize := field.Type().Bits()
+
+	val, err := strconv.ParseInt(value, 10, bitSize)
+	if err != nil {
+		f, ok := parseInteger(value)
+		if !ok {
+			return err
+		}
+
+		limit := math.Ldexp(1, bitSize-1)
+		if f < -limit || f >= limit {
+			return &strconv.NumError{Func: "ParseInt", Num: value, Err: strconv.ErrRange}
+		}
+		val = int64(f)
+	}
+
+	field.SetInt(val)
+	return nil
+}
+
+func setUint(field reflect.Value, value string) error {
+	bitSize := field.Type().Bits()
+
+	val, err := strconv.ParseUint(value, 10, bitSize)
+	if err != nil {
+		f, ok := parseInteger(value)
+		if !ok {
+			return err
+		}
+
+		if f < 0 || f >= math.Ldexp(1, bitSize) {
+			return &strconv.NumError{Func: "ParseUint", Num: value, Err: strconv.ErrRange}
+		}
+		val = uint64(f)
+	}
+
+	field.SetUint(val)
+	return nil
+}
+
+// parseInteger parses a number without fractional part written as a float, e.g. 4.2e1 or 8080.0.
+func parseInteger(value string) (float64, bool) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsInf(f, 0) || f != math.Trunc(f) {
+		return 0, false
+	}
+	return f, true
+}
+
+func setFloat(field reflect.Value, value string) error {
+	val, err := strconv.ParseFloat(value, field.Type().Bits())
+	if err != nil {
+		return err
+	}
+
+	field.SetFloat(val)
+	return nil
+}
+
+// setDuration sets a types.Duration or a time.Duration field,
+// which accept the same values whatever the source of the node: a duration such as 10s, or a number of seconds.
+func setDuration(field reflect.Value, value string) error {
+	duration, err := types.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+
+	field.SetInt(int64(duration))
+	return nil
+}
+
+// setByteQuantity sets an int64 field tagged unit:"bytes", from a byte quantity such as 10MB or 10Mi,
+// or from a number of bytes, possibly written as a number without fractional part, e.g. 1e+06 in JSON.
+func setByteQuantity(field reflect.Value, value string) error {
+	val, err := types.ParseByteQuantity(value)
+	if err != nil {
+		f, ok := parseInteger(value)
+		if !ok || f < 0 || f >= math.Ldexp(1, 63) {
+			return err
+		}
+		val = int64(f)
+	}
+
+	field.SetInt(val)
+	return nil
+}
+
+// dateLayout is the layout of the dates accepted by the time fields, which are at midnight UTC.
+const dateLayout = "2006-01-02"
+
+// setTime sets a time.Time field, from a RFC 3339 time such as 2019-10-16T10:00:00+02:00, or from a date such as 2019-10-16.
+func setTime(field reflect.Value, value string) error {
+	for _, layout := range []string{time.RFC3339, dateLayout} {
+		if t, err := time.Parse(layout, value); err == nil {
+			field.Set(reflect.ValueOf(t))
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid time %q: a RFC 3339 time such as 2019-10-16T10:00:00Z, or a date such as 2019-10-16, is expected", value)
+}
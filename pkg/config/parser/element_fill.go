@@ -5,9 +5,6 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
-	"time"
-
-	"github.com/containous/traefik/pkg/types"
 )
 
 type initializer interface {
@@ -15,7 +12,14 @@ type initializer interface {
 }
 
 // Fill populates the fields of the element using the information in node.
+// It stops at the first error, see FillWithOpts to collect all of them.
 func Fill(element interface{}, node *Node) error {
+	return FillWithOpts(element, node, DecodeOpts{})
+}
+
+// FillWithOpts populates the fields of the element using the information in node, with the given options.
+// With CollectErrors, a field which cannot be filled is skipped, and the filling goes on with the next one.
+func FillWithOpts(element interface{}, node *Node, opts DecodeOpts) error {
 	if element == nil || node == nil {
 		return nil
 	}
@@ -29,63 +33,112 @@ func Fill(element interface{}, node *Node) error {
 		return fmt.Errorf("struct are not supported, use pointer instead")
 	}
 
-	return fill(root.Elem(), node)
+	return filler{opts}.fill(root.Elem(), node)
+}
+
+type filler struct {
+	DecodeOpts
 }
 
-func fill(field reflect.Value, node *Node) error {
+func (f filler) fill(field reflect.Value, node *Node) error {
 	// related to allow-empty tag
 	if node.Disabled {
 		return nil
 	}
 
+	if node.Tag.Get(TagLabel) == TagLabelRaw {
+		return setRaw(field, node)
+	}
+
+	if node.Tag.Get(TagUnit) == TagUnitBytes && field.Kind() == reflect.Int64 {
+		return setByteQuantity(field, node.Value)
+	}
+
+	// A scalar may be a struct, e.g. a time.Time.
+	if isScalar(field.Type()) {
+		return setScalar(field, node.Value)
+	}
+
 	switch field.Kind() {
-	case reflect.String:
-		field.SetString(node.Value)
-		return nil
-	case reflect.Bool:
-		val, err := strconv.ParseBool(node.Value)
-		if err != nil {
-			return err
-		}
-		field.SetBool(val)
-		return nil
-	case reflect.Int8:
-		return setInt(field, node.Value, 8)
-	case reflect.Int16:
-		return setInt(field, node.Value, 16)
-	case reflect.Int32:
-		return setInt(field, node.Value, 32)
-	case reflect.Int64, reflect.Int:
-		return setInt(field, node.Value, 64)
-	case reflect.Uint8:
-		return setUint(field, node.Value, 8)
-	case reflect.Uint16:
-		return setUint(field, node.Value, 16)
-	case reflect.Uint32:
-		return setUint(field, node.Value, 32)
-	case reflect.Uint64, reflect.Uint:
-		return setUint(field, node.Value, 64)
-	case reflect.Float32:
-		return setFloat(field, node.Value, 32)
-	case reflect.Float64:
-		return setFloat(field, node.Value, 64)
 	case reflect.Struct:
-		return setStruct(field, node)
+		return f.setStruct(field, node)
 	case reflect.Ptr:
-		return setPtr(field, node)
+		return f.setPtr(field, node)
 	case reflect.Map:
-		return setMap(field, node)
+		return f.setMap(field, node)
 	case reflect.Slice:
-		return setSlice(field, node)
+		return f.setSlice(field, node)
 	default:
 		return nil
 	}
 }
 
-func setPtr(field reflect.Value, node *Node) error {
+// setRaw sets the map of a raw field to the subtree of the node.
+func setRaw(field reflect.Value, node *Node) error {
+	if field.IsNil() {
+		field.Set(reflect.MakeMap(field.Type()))
+	}
+
+	for _, child := range node.Children {
+		field.SetMapIndex(reflect.ValueOf(child.Name), reflect.ValueOf(rawValue(child)))
+	}
+
+	return nil
+}
+
+// rawValue returns the value of a node of a raw subtree:
+// a map for a node with named children, a slice for a node with indexed children, and a scalar for a leaf.
+// The type of a scalar is inferred from its value, as a file or a label gives it as a string:
+// true and false are booleans, a number is an int64, or a float64 when it has a decimal part, and any other value is a string.
+func rawValue(node *Node) interface{} {
+	if len(node.Children) == 0 {
+		return rawScalar(node.Value)
+	}
+
+	if strings.HasPrefix(node.Children[0].Name, "[") {
+		values := make([]interface{}, 0, len(node.Children))
+		for _, child := range node.Children {
+			values = append(values, rawValue(child))
+		}
+		return values
+	}
+
+	values := make(map[string]interface{}, len(node.Children))
+	for _, child := range node.Children {
+		values[child.Name] = rawValue(child)
+	}
+	return values
+}
+
+func rawScalar(value string) interface{} {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return i
+	}
+
+	if strings.Trim(value, "-0123456789.") == "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+
+	return value
+}
+
+func (f filler) setPtr(field reflect.Value, node *Node) error {
 	if field.IsNil() {
 		field.Set(reflect.New(field.Type().Elem()))
 
+		if err := setDefaults(field.Elem()); err != nil {
+			return err
+		}
+
 		if field.Type().Implements(reflect.TypeOf((*initializer)(nil)).Elem()) {
 			method := field.MethodByName("SetDefaults")
 			if method.IsValid() {
@@ -94,152 +147,142 @@ func setPtr(field reflect.Value, node *Node) error {
 		}
 	}
 
-	return fill(field.Elem(), node)
+	return f.fill(field.Elem(), node)
+}
+
+// ApplyDefaults sets the fields of the element, which must be a pointer to a struct,
+// to the values of their default tags, as the parser does when it instantiates a struct.
+// It is meant for the elements built by code, rather than decoded.
+func ApplyDefaults(element interface{}) error {
+	value := reflect.ValueOf(element)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return fmt.Errorf("unsupported element %T, a pointer to a struct is expected", element)
+	}
+
+	return setDefaults(value.Elem())
+}
+
+// setDefaults sets the fields of the struct, and of its struct fields, to the values of their default tags.
+// The pointer fields are left nil, their defaults are applied once they are instantiated.
+func setDefaults(field reflect.Value) error {
+	if field.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < field.NumField(); i++ {
+		structField := field.Type().Field(i)
+		if !IsExported(structField) {
+			continue
+		}
+
+		defaultValue, ok := structField.Tag.Lookup(TagDefault)
+		if !ok {
+			if err := setDefaults(field.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		err := filler{}.fill(field.Field(i), &Node{Name: structField.Name, FieldName: structField.Name, Value: defaultValue, Tag: structField.Tag})
+		if err != nil {
+			return fmt.Errorf("invalid default value of the field %s: %v", structField.Name, err)
+		}
+	}
+
+	return nil
 }
 
-func setStruct(field reflect.Value, node *Node) error {
+func (f filler) setStruct(field reflect.Value, node *Node) error {
+	var errs DecodeErrors
+
 	for _, child := range node.Children {
 		fd := field.FieldByName(child.FieldName)
 
 		zeroValue := reflect.Value{}
 		if fd == zeroValue {
-			return fmt.Errorf("field not found, node: %s (%s)", child.Name, child.FieldName)
+			err := fmt.Errorf("field not found, node: %s (%s)", child.Name, child.FieldName)
+			if !f.CollectErrors {
+				return err
+			}
+			errs = append(errs, err)
+			continue
 		}
 
-		err := fill(fd, child)
+		err := f.fill(fd, child)
 		if err != nil {
-			return err
+			if !f.CollectErrors {
+				return wrapPath(canonicalName(child.FieldName, child.Tag), err)
+			}
+			errs = appendErrors(errs, wrapPath(canonicalName(child.FieldName, child.Tag), err))
 		}
 	}
 
-	return nil
+	return errs.asError()
 }
 
-func setSlice(field reflect.Value, node *Node) error {
-	if field.Type().Elem().Kind() == reflect.Struct ||
-		field.Type().Elem().Kind() == reflect.Ptr && field.Type().Elem().Elem().Kind() == reflect.Struct {
-		return setSliceStruct(field, node)
+func (f filler) setSlice(field reflect.Value, node *Node) error {
+	if !isScalar(field.Type().Elem()) && (field.Type().Elem().Kind() == reflect.Struct ||
+		field.Type().Elem().Kind() == reflect.Ptr && field.Type().Elem().Elem().Kind() == reflect.Struct) {
+		return f.setSliceStruct(field, node)
 	}
 
 	if len(node.Value) == 0 {
 		return nil
 	}
 
+	if !isScalar(field.Type().Elem()) {
+		return fmt.Errorf("unsupported type: %s", field.Type().Elem())
+	}
+
 	values := strings.Split(node.Value, ",")
 
 	slice := reflect.MakeSlice(field.Type(), len(values), len(values))
 	field.Set(slice)
 
 	for i := 0; i < len(values); i++ {
-		value := strings.TrimSpace(values[i])
-
-		switch field.Type().Elem().Kind() {
-		case reflect.String:
-			field.Index(i).SetString(value)
-		case reflect.Int:
-			val, err := strconv.ParseInt(value, 10, 64)
-			if err != nil {
-				return err
-			}
-			field.Index(i).SetInt(val)
-		case reflect.Int8:
-			err := setInt(field.Index(i), value, 8)
-			if err != nil {
-				return err
-			}
-		case reflect.Int16:
-			err := setInt(field.Index(i), value, 16)
-			if err != nil {
-				return err
-			}
-		case reflect.Int32:
-			err := setInt(field.Index(i), value, 32)
-			if err != nil {
-				return err
-			}
-		case reflect.Int64:
-			err := setInt(field.Index(i), value, 64)
-			if err != nil {
-				return err
-			}
-		case reflect.Uint:
-			val, err := strconv.ParseUint(value, 10, 64)
-			if err != nil {
-				return err
-			}
-			field.Index(i).SetUint(val)
-		case reflect.Uint8:
-			err := setUint(field.Index(i), value, 8)
-			if err != nil {
-				return err
-			}
-		case reflect.Uint16:
-			err := setUint(field.Index(i), value, 16)
-			if err != nil {
-				return err
-			}
-		case reflect.Uint32:
-			err := setUint(field.Index(i), value, 32)
-			if err != nil {
-				return err
-			}
-		case reflect.Uint64:
-			err := setUint(field.Index(i), value, 64)
-			if err != nil {
-				return err
-			}
-		case reflect.Float32:
-			err := setFloat(field.Index(i), value, 32)
-			if err != nil {
-				return err
-			}
-		case reflect.Float64:
-			err := setFloat(field.Index(i), value, 64)
-			if err != nil {
-				return err
-			}
-		case reflect.Bool:
-			val, err := strconv.ParseBool(value)
-			if err != nil {
-				return err
-			}
-			field.Index(i).SetBool(val)
-		default:
-			return fmt.Errorf("unsupported type: %s", field.Type().Elem())
+		if err := setScalar(field.Index(i), strings.TrimSpace(values[i])); err != nil {
+			return err
 		}
 	}
+
 	return nil
 }
 
-func setSliceStruct(field reflect.Value, node *Node) error {
+func (f filler) setSliceStruct(field reflect.Value, node *Node) error {
 	if node.Tag.Get(TagLabelSliceAsStruct) != "" {
-		return setSliceAsStruct(field, node)
+		return f.setSliceAsStruct(field, node)
 	}
 
 	field.Set(reflect.MakeSlice(field.Type(), len(node.Children), len(node.Children)))
 
+	var errs DecodeErrors
+
 	for i, child := range node.Children {
 		// use Ptr to allow "SetDefaults"
 		value := reflect.New(reflect.PtrTo(field.Type().Elem()))
-		err := setPtr(value, child)
+		err := f.setPtr(value, child)
 		if err != nil {
-			return err
+			if !f.CollectErrors {
+				return wrapPath(child.Name, err)
+			}
+			errs = appendErrors(errs, wrapPath(child.Name, err))
+			continue
 		}
 
 		field.Index(i).Set(value.Elem().Elem())
 	}
 
-	return nil
+	return errs.asError()
 }
 
-func setSliceAsStruct(field reflect.Value, node *Node) error {
+func (f filler) setSliceAsStruct(field reflect.Value, node *Node) error {
 	if len(node.Children) == 0 {
 		return fmt.Errorf("invalid slice: node %s", node.Name)
 	}
 
 	// use Ptr to allow "SetDefaults"
 	value := reflect.New(reflect.PtrTo(field.Type().Elem()))
-	err := setPtr(value, node)
+	err := f.setPtr(value, node)
 	if err != nil {
 		return err
 	}
@@ -252,17 +295,23 @@ func setSliceAsStruct(field reflect.Value, node *Node) error {
 	return nil
 }
 
-func setMap(field reflect.Value, node *Node) error {
+func (f filler) setMap(field reflect.Value, node *Node) error {
 	if field.IsNil() {
 		field.Set(reflect.MakeMap(field.Type()))
 	}
 
+	var errs DecodeErrors
+
 	for _, child := range node.Children {
 		ptrValue := reflect.New(reflect.PtrTo(field.Type().Elem()))
 
-		err := fill(ptrValue, child)
+		err := f.fill(ptrValue, child)
 		if err != nil {
-			return err
+			if !f.CollectErrors {
+				return wrapPath(child.Name, err)
+			}
+			errs = appendErrors(errs, wrapPath(child.Name, err))
+			continue
 		}
 
 		value := ptrValue.Elem().Elem()
@@ -270,58 +319,6 @@ func setMap(field reflect.Value, node *Node) error {
 		key := reflect.ValueOf(child.Name)
 		field.SetMapIndex(key, value)
 	}
-	return nil
-}
-
-func setInt(field reflect.Value, value string, bitSize int) error {
-	switch field.Type() {
-	case reflect.TypeOf(types.Duration(0)):
-		return setDuration(field, value, bitSize, time.Second)
-	case reflect.TypeOf(time.Duration(0)):
-		return setDuration(field, value, bitSize, time.Nanosecond)
-	default:
-		val, err := strconv.ParseInt(value, 10, bitSize)
-		if err != nil {
-			return err
-		}
-
-		field.Set(reflect.ValueOf(val).Convert(field.Type()))
-		return nil
-	}
-}
-
-func setDuration(field reflect.Value, value string, bitSize int, defaultUnit time.Duration) error {
-	val, err := strconv.ParseInt(value, 10, bitSize)
-	if err == nil {
-		field.Set(reflect.ValueOf(time.Duration(val) * defaultUnit).Convert(field.Type()))
-		return nil
-	}
-
-	duration, err := time.ParseDuration(value)
-	if err != nil {
-		return err
-	}
-
-	field.Set(reflect.ValueOf(duration).Convert(field.Type()))
-	return nil
-}
-
-func setUint(field reflect.Value, value string, bitSize int) error {
-	val, err := strconv.ParseUint(value, 10, bitSize)
-	if err != nil {
-		return err
-	}
 
-	field.Set(reflect.ValueOf(val).Convert(field.Type()))
-	return nil
-}
-
-func setFloat(field reflect.Value, value string, bitSize int) error {
-	val, err := strconv.ParseFloat(value, bitSize)
-	if err != nil {
-		return err
-	}
-
-	field.Set(reflect.ValueOf(val).Convert(field.Type()))
-	return nil
+	return errs.asError()
 }
@@ -149,6 +149,102 @@ func TestFill(t *testing.T) {
 			element:  &struct{ Foo int }{},
 			expected: expected{error: true},
 		},
+		{
+			desc: "int written as a float",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "4.2e1", Kind: reflect.Int},
+				},
+			},
+			element:  &struct{ Foo int }{},
+			expected: expected{element: &struct{ Foo int }{Foo: 42}},
+		},
+		{
+			desc: "int with a fractional part",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "4.2", Kind: reflect.Int},
+				},
+			},
+			element:  &struct{ Foo int }{},
+			expected: expected{error: true},
+		},
+		{
+			desc: "int8 out of range",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "128", Kind: reflect.Int8},
+				},
+			},
+			element:  &struct{ Foo int8 }{},
+			expected: expected{error: true},
+		},
+		{
+			desc: "int8 written as a float out of range",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "1.28e2", Kind: reflect.Int8},
+				},
+			},
+			element:  &struct{ Foo int8 }{},
+			expected: expected{error: true},
+		},
+		{
+			desc: "uint written as a float",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "255.0", Kind: reflect.Uint8},
+				},
+			},
+			element:  &struct{ Foo uint8 }{},
+			expected: expected{element: &struct{ Foo uint8 }{Foo: 255}},
+		},
+		{
+			desc: "negative uint written as a float",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "-1.0", Kind: reflect.Uint},
+				},
+			},
+			element:  &struct{ Foo uint }{},
+			expected: expected{error: true},
+		},
+		{
+			desc: "bool in mixed case",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "tRuE", Kind: reflect.Bool},
+				},
+			},
+			element:  &struct{ Foo bool }{},
+			expected: expected{element: &struct{ Foo bool }{Foo: true}},
+		},
+		{
+			desc: "slice of bools in mixed case",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "tRuE, False", Kind: reflect.Slice},
+				},
+			},
+			element:  &struct{ Foo []bool }{},
+			expected: expected{element: &struct{ Foo []bool }{Foo: []bool{true, false}}},
+		},
 		{
 			desc: "int8",
 			node: &Node{
@@ -387,7 +483,19 @@ func TestFill(t *testing.T) {
 				},
 			},
 			element:  &struct{ Foo time.Duration }{},
-			expected: expected{element: &struct{ Foo time.Duration }{Foo: 4 * time.Nanosecond}},
+			expected: expected{element: &struct{ Foo time.Duration }{Foo: 4 * time.Second}},
+		},
+		{
+			desc: "time.Duration invalid",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "four", Kind: reflect.Int64},
+				},
+			},
+			element:  &struct{ Foo time.Duration }{},
+			expected: expected{error: true},
 		},
 		{
 			desc: "types.Duration with unit",
@@ -413,6 +521,128 @@ func TestFill(t *testing.T) {
 			element:  &struct{ Foo types.Duration }{},
 			expected: expected{element: &struct{ Foo types.Duration }{Foo: types.Duration(4 * time.Second)}},
 		},
+		{
+			desc: "byte quantity",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "10Mi", Kind: reflect.Int64, Tag: `unit:"bytes"`},
+				},
+			},
+			element: &struct {
+				Foo int64 `unit:"bytes"`
+			}{},
+			expected: expected{element: &struct {
+				Foo int64 `unit:"bytes"`
+			}{Foo: 10485760}},
+		},
+		{
+			desc: "byte quantity pointer",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "2kB", Kind: reflect.Ptr, Tag: `unit:"bytes"`},
+				},
+			},
+			element: &struct {
+				Foo *int64 `unit:"bytes"`
+			}{},
+			expected: expected{element: &struct {
+				Foo *int64 `unit:"bytes"`
+			}{Foo: func(v int64) *int64 { return &v }(2000)}},
+		},
+		{
+			desc: "byte quantity as a JSON number",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "1e+06", Kind: reflect.Int64, Tag: `unit:"bytes"`},
+				},
+			},
+			element: &struct {
+				Foo int64 `unit:"bytes"`
+			}{},
+			expected: expected{element: &struct {
+				Foo int64 `unit:"bytes"`
+			}{Foo: 1000000}},
+		},
+		{
+			desc: "byte quantity negative",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "-1", Kind: reflect.Int64, Tag: `unit:"bytes"`},
+				},
+			},
+			element: &struct {
+				Foo int64 `unit:"bytes"`
+			}{},
+			expected: expected{error: true},
+		},
+		{
+			desc: "int64 without unit tag",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "10Mi", Kind: reflect.Int64},
+				},
+			},
+			element:  &struct{ Foo int64 }{},
+			expected: expected{error: true},
+		},
+		{
+			desc: "time.Time RFC 3339",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "2019-10-16T10:00:00+02:00", Kind: reflect.Struct},
+				},
+			},
+			element:  &struct{ Foo time.Time }{},
+			expected: expected{element: &struct{ Foo time.Time }{Foo: time.Date(2019, 10, 16, 10, 0, 0, 0, time.FixedZone("", 2*60*60))}},
+		},
+		{
+			desc: "time.Time date",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "2019-10-16", Kind: reflect.Struct},
+				},
+			},
+			element:  &struct{ Foo time.Time }{},
+			expected: expected{element: &struct{ Foo time.Time }{Foo: time.Date(2019, 10, 16, 0, 0, 0, 0, time.UTC)}},
+		},
+		{
+			desc: "time.Time pointer",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "2019-10-16T08:00:00Z", Kind: reflect.Ptr},
+				},
+			},
+			element:  &struct{ Foo *time.Time }{},
+			expected: expected{element: &struct{ Foo *time.Time }{Foo: func(v time.Time) *time.Time { return &v }(time.Date(2019, 10, 16, 8, 0, 0, 0, time.UTC))}},
+		},
+		{
+			desc: "time.Time invalid",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "16/10/2019", Kind: reflect.Struct},
+				},
+			},
+			element:  &struct{ Foo time.Time }{},
+			expected: expected{error: true},
+		},
 		{
 			desc: "bool",
 			node: &Node{
@@ -1225,6 +1455,143 @@ func TestFill(t *testing.T) {
 				},
 			}},
 		},
+		{
+			desc: "pointer default tags",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{
+						Name:      "Foo",
+						FieldName: "Foo",
+						Kind:      reflect.Struct,
+						Children: []*Node{
+							{Name: "Fuu", FieldName: "Fuu", Value: "huu", Kind: reflect.String},
+						}},
+				}},
+			element: &struct {
+				Foo *DefaultedFoo
+			}{},
+			expected: expected{element: &struct {
+				Foo *DefaultedFoo
+			}{
+				Foo: &DefaultedFoo{
+					Fii:   "default",
+					Fuu:   "huu",
+					Fyy:   true,
+					Fzz:   42,
+					Inner: DefaultedInner{Delay: types.Duration(10 * time.Second)},
+				},
+			}},
+		},
+		{
+			desc: "pointer default tags overridden",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{
+						Name:      "Foo",
+						FieldName: "Foo",
+						Kind:      reflect.Struct,
+						Children: []*Node{
+							{Name: "Fii", FieldName: "Fii", Value: "hii", Kind: reflect.String},
+							{Name: "Fyy", FieldName: "Fyy", Value: "false", Kind: reflect.Bool},
+							{Name: "Fzz", FieldName: "Fzz", Value: "0", Kind: reflect.Int},
+							{
+								Name:      "Inner",
+								FieldName: "Inner",
+								Kind:      reflect.Struct,
+								Children: []*Node{
+									{Name: "Delay", FieldName: "Delay", Value: "1m", Kind: reflect.Int64},
+								},
+							},
+						}},
+				}},
+			element: &struct {
+				Foo *DefaultedFoo
+			}{},
+			expected: expected{element: &struct {
+				Foo *DefaultedFoo
+			}{
+				Foo: &DefaultedFoo{
+					Fii:   "hii",
+					Inner: DefaultedInner{Delay: types.Duration(time.Minute)},
+				},
+			}},
+		},
+		{
+			desc: "pointer default tags applied before SetDefaults method",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{
+						Name:      "Foo",
+						FieldName: "Foo",
+						Kind:      reflect.Struct,
+						Children: []*Node{
+							{Name: "Fuu", FieldName: "Fuu", Value: "huu", Kind: reflect.String},
+						}},
+				}},
+			element: &struct {
+				Foo *DefaultedInitializedFoo
+			}{},
+			expected: expected{element: &struct {
+				Foo *DefaultedInitializedFoo
+			}{
+				Foo: &DefaultedInitializedFoo{
+					Fii: "method",
+					Fuu: "huu",
+				},
+			}},
+		},
+		{
+			desc: "existing pointer default tags not applied",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{
+						Name:      "Foo",
+						FieldName: "Foo",
+						Kind:      reflect.Struct,
+						Children: []*Node{
+							{Name: "Fuu", FieldName: "Fuu", Value: "huu", Kind: reflect.String},
+						}},
+				}},
+			element: &struct {
+				Foo *DefaultedFoo
+			}{Foo: &DefaultedFoo{}},
+			expected: expected{element: &struct {
+				Foo *DefaultedFoo
+			}{
+				Foo: &DefaultedFoo{
+					Fuu: "huu",
+				},
+			}},
+		},
+		{
+			desc: "pointer invalid default tag",
+			node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{
+						Name:      "Foo",
+						FieldName: "Foo",
+						Kind:      reflect.Struct,
+						Children: []*Node{
+							{Name: "Fii", FieldName: "Fii", Value: "true", Kind: reflect.Bool},
+						}},
+				}},
+			element: &struct {
+				Foo *struct {
+					Fii bool `default:"yes please"`
+				}
+			}{},
+			expected: expected{error: true},
+		},
 		{
 			desc: "int pointer",
 			node: &Node{
@@ -1401,6 +1768,47 @@ func TestFill(t *testing.T) {
 	}
 }
 
+func TestDecodeWithOpts_collectErrors(t *testing.T) {
+	labels := map[string]string{
+		"traefik.foo.bar":     "a",
+		"traefik.foo.baz":     "1",
+		"traefik.foo.unknown": "b",
+		"traefik.fii.bar":     "c",
+		"traefik.fii.baz":     "two",
+		"traefik.fuu.fyy":     "d",
+	}
+
+	type Sub struct {
+		Bar string
+		Baz int
+	}
+
+	type Root struct {
+		Foo *Sub
+		Fii *Sub
+		Fuu *Sub
+	}
+
+	element := &Root{}
+	err := DecodeWithOpts(labels, element, DecodeOpts{CollectErrors: true})
+	require.Error(t, err)
+
+	errs, ok := err.(DecodeErrors)
+	require.True(t, ok, "expected DecodeErrors, got %T", err)
+	require.Len(t, errs, 3)
+
+	assert.EqualError(t, errs[0], `Foo: unknown field "unknown"`)
+	assert.EqualError(t, errs[1], `Fuu: unknown field "fyy"`)
+	assert.Contains(t, errs[2].Error(), "Fii.Baz: ")
+
+	assert.Equal(t, &Root{Foo: &Sub{Bar: "a", Baz: 1}, Fii: &Sub{Bar: "c"}, Fuu: &Sub{}}, element)
+
+	err = Decode(labels, &Root{})
+	require.Error(t, err)
+	_, ok = err.(DecodeErrors)
+	assert.False(t, ok)
+}
+
 type NamedType string
 type NamedTypeInt int
 
@@ -1413,6 +1821,27 @@ func (t *InitializedFoo) SetDefaults() {
 	t.Fii = "default"
 }
 
+type DefaultedFoo struct {
+	Fii   string `default:"default"`
+	Fuu   string
+	Fyy   bool `default:"true"`
+	Fzz   int  `default:"42"`
+	Inner DefaultedInner
+}
+
+type DefaultedInner struct {
+	Delay types.Duration `default:"10s"`
+}
+
+type DefaultedInitializedFoo struct {
+	Fii string `default:"tag"`
+	Fuu string
+}
+
+func (t *DefaultedInitializedFoo) SetDefaults() {
+	t.Fii = "method"
+}
+
 type wrongInitialledFoo struct {
 	Fii string
 	Fuu string
@@ -3,17 +3,35 @@ package parser
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/containous/traefik/pkg/types"
 )
 
+// NodeOpts holds the options of the encoding of an element to a node.
+type NodeOpts struct {
+	OmitEmpty bool
+	// NoRedact keeps the values of the fields tagged with export:"redact", e.g. for a trusted debugging.
+	NoRedact bool
+}
+
 // EncodeToNode converts an element to a node.
+// The values of the fields tagged with export:"redact" are redacted.
 // element -> nodes
 func EncodeToNode(element interface{}, omitEmpty bool) (*Node, error) {
+	return EncodeToNodeWithOpts(element, NodeOpts{OmitEmpty: omitEmpty})
+}
+
+// EncodeToNodeWithOpts converts an element to a node, with the given options.
+// element -> nodes
+func EncodeToNodeWithOpts(element interface{}, opts NodeOpts) (*Node, error) {
 	rValue := reflect.ValueOf(element)
 	node := &Node{Name: "traefik"}
 
-	encoder := encoderToNode{omitEmpty: omitEmpty}
+	encoder := encoderToNode{omitEmpty: opts.OmitEmpty, redact: !opts.NoRedact}
 
 	err := encoder.setNodeValue(node, rValue)
 	if err != nil {
@@ -25,9 +43,21 @@ func EncodeToNode(element interface{}, omitEmpty bool) (*Node, error) {
 
 type encoderToNode struct {
 	omitEmpty bool
+	redact    bool
 }
 
 func (e encoderToNode) setNodeValue(node *Node, rValue reflect.Value) error {
+	if rValue.Kind() == reflect.Int64 && (rValue.Type() == reflect.TypeOf(types.Duration(0)) || rValue.Type() == reflect.TypeOf(time.Duration(0))) {
+		// A duration string, as a bare number is a number of seconds for the decoder.
+		node.Value = time.Duration(rValue.Int()).String()
+		return nil
+	}
+
+	if rValue.Kind() == reflect.Struct && rValue.Type() == reflect.TypeOf(time.Time{}) {
+		node.Value = rValue.Interface().(time.Time).Format(time.RFC3339Nano)
+		return nil
+	}
+
 	switch rValue.Kind() {
 	case reflect.String:
 		node.Value = rValue.String()
@@ -47,6 +77,9 @@ func (e encoderToNode) setNodeValue(node *Node, rValue reflect.Value) error {
 		return e.setMapValue(node, rValue)
 	case reflect.Slice:
 		return e.setSliceValue(node, rValue)
+	case reflect.Interface:
+		// The values of a raw field.
+		return e.setNodeValue(node, rValue.Elem())
 	default:
 		// noop
 	}
@@ -95,6 +128,10 @@ func (e encoderToNode) setStructValue(node *Node, rValue reflect.Value) error {
 			return err
 		}
 
+		if e.redact && field.Tag.Get(TagExport) == TagExportRedact {
+			redactNode(child)
+		}
+
 		if field.Type.Kind() == reflect.Ptr {
 			if field.Type.Elem().Kind() != reflect.Struct && fieldValue.IsNil() {
 				continue
@@ -115,8 +152,13 @@ func (e encoderToNode) setStructValue(node *Node, rValue reflect.Value) error {
 	return nil
 }
 
+// setMapValue sets the entries of the map as children of the node, sorted by key,
+// so that the encoded element does not depend on the iteration order of the map.
 func (e encoderToNode) setMapValue(node *Node, rValue reflect.Value) error {
-	for _, key := range rValue.MapKeys() {
+	keys := rValue.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	for _, key := range keys {
 		child := &Node{Name: key.String(), FieldName: key.String()}
 		node.Children = append(node.Children, child)
 
@@ -137,8 +179,9 @@ func (e encoderToNode) setSliceValue(node *Node, rValue reflect.Value) error {
 		return e.setNodeValue(node, rValue.Index(0))
 	}
 
-	if rValue.Type().Elem().Kind() == reflect.Struct ||
-		rValue.Type().Elem().Kind() == reflect.Ptr && rValue.Type().Elem().Elem().Kind() == reflect.Struct {
+	// The items of a slice of a raw field are indexed, as the scalars and the maps can be mixed.
+	if !isScalar(rValue.Type().Elem()) && (rValue.Type().Elem().Kind() == reflect.Struct || rValue.Type().Elem().Kind() == reflect.Interface ||
+		rValue.Type().Elem().Kind() == reflect.Ptr && rValue.Type().Elem().Elem().Kind() == reflect.Struct) {
 		for i := 0; i < rValue.Len(); i++ {
 			child := &Node{Name: "[" + strconv.Itoa(i) + "]"}
 
@@ -171,6 +214,10 @@ func (e encoderToNode) setSliceValue(node *Node, rValue reflect.Value) error {
 			values = append(values, strconv.FormatFloat(eValue.Float(), 'f', 6, 64))
 		case reflect.Bool:
 			values = append(values, strconv.FormatBool(eValue.Bool()))
+		case reflect.Struct:
+			if t, ok := eValue.Interface().(time.Time); ok {
+				values = append(values, t.Format(time.RFC3339Nano))
+			}
 		default:
 			// noop
 		}
@@ -180,9 +227,21 @@ func (e encoderToNode) setSliceValue(node *Node, rValue reflect.Value) error {
 	return nil
 }
 
+// redactNode replaces the values of the node, and of its children, by RedactedValue.
+func redactNode(node *Node) {
+	if node.Value != "" {
+		node.Value = RedactedValue
+	}
+
+	for _, child := range node.Children {
+		redactNode(child)
+	}
+}
+
 func (e encoderToNode) isSkippedField(field reflect.StructField, fieldValue reflect.Value) bool {
 	if e.omitEmpty && field.Type.Kind() == reflect.String && fieldValue.Len() == 0 {
-		return true
+		// An empty value is kept when it differs from the default, which would apply when decoding otherwise.
+		return field.Tag.Get(TagDefault) == ""
 	}
 
 	if field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct && fieldValue.IsNil() {
@@ -2,7 +2,9 @@ package parser
 
 import (
 	"testing"
+	"time"
 
+	"github.com/containous/traefik/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -50,6 +52,49 @@ func TestEncodeToNode(t *testing.T) {
 			}},
 			},
 		},
+		{
+			desc: "empty string with a default",
+			element: struct {
+				Foo string `default:"bar"`
+				Fii string
+			}{},
+			expected: expected{node: &Node{Name: "traefik", Children: []*Node{
+				{Name: "Foo", FieldName: "Foo", Value: ""},
+			}},
+			},
+		},
+		{
+			desc: "durations",
+			element: struct {
+				Foo types.Duration
+				Fii time.Duration
+			}{Foo: types.Duration(90 * time.Second), Fii: 10 * time.Millisecond},
+			expected: expected{node: &Node{Name: "traefik", Children: []*Node{
+				{Name: "Foo", FieldName: "Foo", Value: "1m30s"},
+				{Name: "Fii", FieldName: "Fii", Value: "10ms"},
+			}},
+			},
+		},
+		{
+			desc: "time",
+			element: struct {
+				Foo time.Time
+			}{Foo: time.Date(2019, 10, 16, 10, 0, 0, 0, time.FixedZone("", 2*60*60))},
+			expected: expected{node: &Node{Name: "traefik", Children: []*Node{
+				{Name: "Foo", FieldName: "Foo", Value: "2019-10-16T10:00:00+02:00"},
+			}},
+			},
+		},
+		{
+			desc: "slice of time",
+			element: struct {
+				Foo []time.Time
+			}{Foo: []time.Time{time.Date(2019, 9, 16, 8, 0, 0, 0, time.UTC), time.Date(2019, 10, 16, 8, 0, 0, 0, time.UTC)}},
+			expected: expected{node: &Node{Name: "traefik", Children: []*Node{
+				{Name: "Foo", FieldName: "Foo", Value: "2019-09-16T08:00:00Z, 2019-10-16T08:00:00Z"},
+			}},
+			},
+		},
 		{
 			desc: "int",
 			element: struct {
@@ -735,3 +780,68 @@ func TestEncodeToNode(t *testing.T) {
 		})
 	}
 }
+
+func TestEncodeToNodeWithOpts_redact(t *testing.T) {
+	type secrets struct {
+		Users    []string          `export:"redact"`
+		Key      string            `export:"redact"`
+		Empty    string            `export:"redact"`
+		Password *string           `export:"redact"`
+		Public   string            `export:"true"`
+		Headers  map[string]string `export:"redact"`
+	}
+
+	password := "bar"
+	element := &secrets{
+		Users:    []string{"foo:hash", "bar:hash"},
+		Key:      "/path/to/key.pem",
+		Password: &password,
+		Public:   "foo",
+		Headers:  map[string]string{"Authorization": "Basic foo"},
+	}
+
+	testCases := []struct {
+		desc     string
+		opts     NodeOpts
+		expected *Node
+	}{
+		{
+			desc: "redacted",
+			opts: NodeOpts{OmitEmpty: true},
+			expected: &Node{Name: "traefik", Children: []*Node{
+				{Name: "Users", FieldName: "Users", Value: RedactedValue},
+				{Name: "Key", FieldName: "Key", Value: RedactedValue},
+				{Name: "Password", FieldName: "Password", Value: RedactedValue},
+				{Name: "Public", FieldName: "Public", Value: "foo"},
+				{Name: "Headers", FieldName: "Headers", Children: []*Node{
+					{Name: "Authorization", FieldName: "Authorization", Value: RedactedValue},
+				}},
+			}},
+		},
+		{
+			desc: "not redacted",
+			opts: NodeOpts{OmitEmpty: true, NoRedact: true},
+			expected: &Node{Name: "traefik", Children: []*Node{
+				{Name: "Users", FieldName: "Users", Value: "foo:hash, bar:hash"},
+				{Name: "Key", FieldName: "Key", Value: "/path/to/key.pem"},
+				{Name: "Password", FieldName: "Password", Value: "bar"},
+				{Name: "Public", FieldName: "Public", Value: "foo"},
+				{Name: "Headers", FieldName: "Headers", Children: []*Node{
+					{Name: "Authorization", FieldName: "Authorization", Value: "Basic foo"},
+				}},
+			}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			node, err := EncodeToNodeWithOpts(element, test.opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, node)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Validator is implemented by the elements which check their semantic consistency once decoded,
+// e.g. the ranges of their values, or the relationships between their fields.
+type Validator interface {
+	Validate() error
+}
+
+// ValidationErrors holds the errors of all the invalid structs of an element.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, ", ")
+}
+
+// Validate invokes the Validate method of the element, and of all the structs it holds, which implement Validator.
+// It collects the errors, each one prefixed with the path of the invalid struct, as for the decoding errors,
+// e.g. HTTP.Middlewares.foo.MaxConn: amount must be greater than 0.
+func Validate(element interface{}) error {
+	if element == nil {
+		return nil
+	}
+
+	errs := validate(reflect.ValueOf(element))
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return errs
+	}
+}
+
+func validate(field reflect.Value) ValidationErrors {
+	var errs ValidationErrors
+
+	switch field.Kind() {
+	case reflect.Ptr:
+		if field.IsNil() {
+			return nil
+		}
+		return validate(field.Elem())
+	case reflect.Struct:
+		for i := 0; i < field.NumField(); i++ {
+			fieldType := field.Type().Field(i)
+			if fieldType.PkgPath != "" {
+				continue
+			}
+
+			for _, err := range validate(field.Field(i)) {
+				errs = append(errs, wrapPath(canonicalName(fieldType.Name, fieldType.Tag), err))
+			}
+		}
+
+		if err := validateStruct(field); err != nil {
+			errs = append(errs, err)
+		}
+	case reflect.Map:
+		keys := field.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+		for _, key := range keys {
+			for _, err := range validate(field.MapIndex(key)) {
+				errs = append(errs, wrapPath(key.String(), err))
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < field.Len(); i++ {
+			for _, err := range validate(field.Index(i)) {
+				errs = append(errs, wrapPath("["+strconv.Itoa(i)+"]", err))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateStruct invokes the Validate method of the struct, whether it has a value or a pointer receiver.
+func validateStruct(field reflect.Value) error {
+	if !field.CanAddr() {
+		// The map values are not addressable.
+		ptr := reflect.New(field.Type())
+		ptr.Elem().Set(field)
+		field = ptr.Elem()
+	}
+
+	validator, ok := field.Addr().Interface().(Validator)
+	if !ok {
+		return nil
+	}
+
+	return validator.Validate()
+}
@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validatedPort struct {
+	Port int
+}
+
+func (p *validatedPort) Validate() error {
+	if p.Port < 1 || p.Port > 65535 {
+		return errors.New("port must be between 1 and 65535")
+	}
+	return nil
+}
+
+type validatedRange struct {
+	Min int
+	Max int
+}
+
+func (r validatedRange) Validate() error {
+	if r.Min > r.Max {
+		return errors.New("min must not be greater than max")
+	}
+	return nil
+}
+
+type validatedRoot struct {
+	Server   *validatedPort
+	Ranges   map[string]validatedRange
+	Backends []*validatedPort `label-slice-as-struct:"backend"`
+	unused   *validatedPort
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		element       interface{}
+		expectedError string
+	}{
+		{
+			desc:    "nil",
+			element: nil,
+		},
+		{
+			desc:    "no validator",
+			element: &struct{ Foo string }{},
+		},
+		{
+			desc: "valid",
+			element: &validatedRoot{
+				Server:   &validatedPort{Port: 80},
+				Ranges:   map[string]validatedRange{"foo": {Min: 1, Max: 2}},
+				Backends: []*validatedPort{{Port: 8080}},
+				unused:   &validatedPort{},
+			},
+		},
+		{
+			desc:          "invalid pointer receiver",
+			element:       &validatedRoot{Server: &validatedPort{}},
+			expectedError: "Server: port must be between 1 and 65535",
+		},
+		{
+			desc:          "invalid value receiver in a map",
+			element:       &validatedRoot{Ranges: map[string]validatedRange{"foo": {Min: 1, Max: 2}, "bar": {Min: 2, Max: 1}}},
+			expectedError: "Ranges.bar: min must not be greater than max",
+		},
+		{
+			desc:          "invalid slice item",
+			element:       &validatedRoot{Backends: []*validatedPort{{Port: 8080}, {Port: 70000}}},
+			expectedError: "backend[1]: port must be between 1 and 65535",
+		},
+		{
+			desc: "all the errors are collected",
+			element: &validatedRoot{
+				Server: &validatedPort{},
+				Ranges: map[string]validatedRange{"foo": {Min: 2, Max: 1}, "bar": {Min: 2, Max: 1}},
+			},
+			expectedError: "Server: port must be between 1 and 65535, Ranges.bar: min must not be greater than max, Ranges.foo: min must not be greater than max",
+		},
+		{
+			desc:          "root validator",
+			element:       &validatedPort{},
+			expectedError: "port must be between 1 and 65535",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := Validate(test.element)
+
+			if test.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, test.expectedError)
+		})
+	}
+}
+
+func TestDecode_validate(t *testing.T) {
+	labels := map[string]string{
+		"traefik.server.port":    "0",
+		"traefik.ranges.foo.min": "10",
+		"traefik.ranges.foo.max": "1",
+	}
+
+	element := &validatedRoot{}
+
+	err := Decode(labels, element)
+	assert.EqualError(t, err, "Server: port must be between 1 and 65535, Ranges.foo: min must not be greater than max")
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+)
+
+// PathError is an error located by the path of the node it occurred on.
+// The path is made of the names of the struct fields, with their canonical casing whatever the casing of the labels,
+// and of the names of the map entries and the indexes of the slice items, e.g. HTTP.Services.foo.LoadBalancer.PassHostHeader.
+// As in the labels, the names containing dots are quoted with backticks, e.g. HTTP.Routers.`my.app`.Rule.
+type PathError struct {
+	Path []string
+	Err  error
+}
+
+func (e *PathError) Error() string {
+	var path strings.Builder
+	for i, name := range e.Path {
+		if i > 0 && !strings.HasPrefix(name, "[") {
+			path.WriteString(".")
+		}
+		path.WriteString(QuoteName(name))
+	}
+
+	return path.String() + ": " + e.Err.Error()
+}
+
+// DecodeErrors holds all the errors of a decoding with CollectErrors, each one located by its path.
+type DecodeErrors []error
+
+func (e DecodeErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, ", ")
+}
+
+// asError returns nil without error, the error itself when there is a single one, and the errors otherwise.
+func (e DecodeErrors) asError() error {
+	switch len(e) {
+	case 0:
+		return nil
+	case 1:
+		return e[0]
+	default:
+		return e
+	}
+}
+
+// appendErrors appends the error to the errors, or its errors one by one when it is a DecodeErrors.
+func appendErrors(errs DecodeErrors, err error) DecodeErrors {
+	if children, ok := err.(DecodeErrors); ok {
+		return append(errs, children...)
+	}
+	return append(errs, err)
+}
+
+// wrapPath prefixes the path of the error, or of each one of the errors of a DecodeErrors, with the given name.
+func wrapPath(name string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errs, ok := err.(DecodeErrors); ok {
+		for i, e := range errs {
+			errs[i] = wrapPath(name, e)
+		}
+		return errs
+	}
+
+	if pathErr, ok := err.(*PathError); ok {
+		pathErr.Path = append([]string{name}, pathErr.Path...)
+		return pathErr
+	}
+
+	return &PathError{Path: []string{name}, Err: err}
+}
+
+// canonicalName returns the name of the field in the labels, with its canonical casing.
+func canonicalName(fieldName string, tag reflect.StructTag) string {
+	if name := tag.Get(TagLabelSliceAsStruct); name != "" {
+		return name
+	}
+	return fieldName
+}
@@ -138,13 +138,13 @@ func (e encoderToFlat) getNodeValue(field reflect.Value, node *Node) string {
 	}
 
 	if field.Kind() == reflect.Int64 {
-		i, _ := strconv.ParseInt(node.Value, 10, 64)
-
 		switch field.Type() {
 		case reflect.TypeOf(types.Duration(time.Second)):
-			return strconv.Itoa(int(i) / int(time.Second))
+			d, _ := time.ParseDuration(node.Value)
+			return strconv.Itoa(int(d / time.Second))
 		case reflect.TypeOf(time.Second):
-			return time.Duration(i).String()
+			d, _ := time.ParseDuration(node.Value)
+			return d.String()
 		}
 	}
 
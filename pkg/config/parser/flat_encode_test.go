@@ -878,7 +878,7 @@ func TestEncodeToFlat(t *testing.T) {
 						Name:        "Field",
 						Description: "field description",
 						FieldName:   "Field",
-						Value:       "1000000000",
+						Value:       "1s",
 						Kind:        reflect.Int64,
 						Tag:         `description:"field description"`,
 					},
@@ -1050,7 +1050,7 @@ func TestEncodeToFlat(t *testing.T) {
 								Name:        "Field",
 								Description: "field description",
 								FieldName:   "Field",
-								Value:       "1000000000",
+								Value:       "1s",
 								Kind:        reflect.Int64,
 								Tag:         `description:"field description"`,
 							},
@@ -1104,7 +1104,7 @@ func TestEncodeToFlat(t *testing.T) {
 										Name:        "Field",
 										Description: "field description",
 										FieldName:   "Field",
-										Value:       "1000000000",
+										Value:       "1s",
 										Kind:        reflect.Int64,
 										Tag:         `description:"field description"`,
 									},
@@ -1136,7 +1136,7 @@ func TestEncodeToFlat(t *testing.T) {
 						Name:        "Field",
 						Description: "field description",
 						FieldName:   "Field",
-						Value:       "180000000000",
+						Value:       "3m0s",
 						Kind:        reflect.Int64,
 						Tag:         `description:"field description"`,
 					},
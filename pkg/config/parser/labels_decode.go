@@ -3,32 +3,48 @@ package parser
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 )
 
 const labelRoot = "traefik"
 
+// quote is the delimiter of the names containing dots in the labels, e.g. traefik.http.routers.`my.app`.rule.
+const quote = "`"
+
 // DecodeToNode converts the labels to a tree of nodes.
 // If any filters are present, labels which do not match the filters are skipped.
+// The names containing dots are quoted with backticks, e.g. traefik.http.routers.`my.app`.rule.
 func DecodeToNode(labels map[string]string, filters ...string) (*Node, error) {
 	sortedKeys := sortKeys(labels, filters)
 
 	var node *Node
 	for i, key := range sortedKeys {
-		split := strings.Split(key, ".")
+		split, err := splitKey(key)
+		if err != nil {
+			return nil, err
+		}
 
-		if split[0] != labelRoot {
+		if !strings.EqualFold(split[0], labelRoot) {
 			return nil, fmt.Errorf("invalid label root %s", split[0])
 		}
 
 		var parts []string
 		for _, v := range split {
+			if isQuoted(v) {
+				parts = append(parts, v[1:len(v)-1])
+				continue
+			}
+
 			if v[0] == '[' {
 				return nil, fmt.Errorf("invalid leading character '[' in field name (bracket is a slice delimiter): %s", v)
 			}
 
 			if strings.HasSuffix(v, "]") && v[0] != '[' {
 				indexLeft := strings.Index(v, "[")
+				if indexLeft < 0 {
+					return nil, fmt.Errorf("invalid trailing character ']' in field name (bracket is a slice delimiter): %s", v)
+				}
 				parts = append(parts, v[:indexLeft], v[indexLeft:])
 			} else {
 				parts = append(parts, v)
@@ -41,9 +57,93 @@ func DecodeToNode(labels map[string]string, filters ...string) (*Node, error) {
 		decodeToNode(node, parts, labels[key])
 	}
 
+	if node != nil {
+		if err := sortChildren(node); err != nil {
+			return nil, err
+		}
+	}
+
 	return node, nil
 }
 
+// SplitKey splits the key of a label into the names it is made of, unquoting the names quoted with backticks:
+// traefik.http.routers.`my.app`.rule is made of traefik, http, routers, my.app and rule.
+func SplitKey(key string) ([]string, error) {
+	parts, err := splitKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, part := range parts {
+		if isQuoted(part) {
+			parts[i] = part[1 : len(part)-1]
+		}
+	}
+
+	return parts, nil
+}
+
+// QuoteName quotes the name with backticks when it contains dots, so that it is a single name in the key of a label.
+func QuoteName(name string) string {
+	if strings.Contains(name, ".") {
+		return quote + name + quote
+	}
+	return name
+}
+
+// splitKey splits the key of a label on the dots, except the dots of the names quoted with backticks,
+// which are kept quoted.
+func splitKey(key string) ([]string, error) {
+	var parts []string
+
+	rest := key
+	for {
+		var part string
+
+		if strings.HasPrefix(rest, quote) {
+			end := strings.Index(rest[1:], quote)
+			if end < 0 {
+				return nil, fmt.Errorf("invalid label %s: unterminated backtick", key)
+			}
+
+			part, rest = rest[:end+2], rest[end+2:]
+			if len(rest) > 0 && rest[0] != '.' {
+				return nil, fmt.Errorf("invalid label %s: a quoted name must be followed by a dot", key)
+			}
+		} else {
+			end := strings.Index(rest, ".")
+			if end < 0 {
+				end = len(rest)
+			}
+
+			part, rest = rest[:end], rest[end:]
+			if strings.Contains(part, quote) {
+				return nil, fmt.Errorf("invalid label %s: the backticks must quote a whole name", key)
+			}
+		}
+
+		if len(part) == 0 || part == quote+quote {
+			return nil, fmt.Errorf("invalid label %s: empty name", key)
+		}
+
+		parts = append(parts, part)
+
+		if len(rest) == 0 {
+			return parts, nil
+		}
+
+		// skips the dot.
+		rest = rest[1:]
+		if len(rest) == 0 {
+			return nil, fmt.Errorf("invalid label %s: empty name", key)
+		}
+	}
+}
+
+func isQuoted(part string) bool {
+	return len(part) > 1 && strings.HasPrefix(part, quote) && strings.HasSuffix(part, quote)
+}
+
 func decodeToNode(root *Node, path []string, value string) {
 	if len(root.Name) == 0 {
 		root.Name = path[0]
@@ -65,9 +165,62 @@ func decodeToNode(root *Node, path []string, value string) {
 	}
 }
 
+// sortChildren orders the children of the nodes by name, as the file decoder does,
+// and by index the items of the slices, e.g. foo[0] and foo[1].
+// The labels are sorted as strings, which puts foo-bar.rule before foo.rule, and foo[10] before foo[2],
+// so that the order of the nodes, hence of the errors and of the encoded elements, would otherwise depend on the source.
+// The indexes must be contiguous, starting from 0, so that an item is not silently lost.
+func sortChildren(node *Node) error {
+	indexes := make(map[*Node]int)
+	for _, child := range node.Children {
+		if !strings.HasPrefix(child.Name, "[") {
+			continue
+		}
+
+		index, err := strconv.Atoi(child.Name[1 : len(child.Name)-1])
+		if err != nil || index < 0 {
+			return fmt.Errorf("invalid index %s in %s", child.Name, node.Name)
+		}
+		indexes[child] = index
+	}
+
+	if len(indexes) == 0 {
+		sort.SliceStable(node.Children, func(i, j int) bool {
+			return node.Children[i].Name < node.Children[j].Name
+		})
+	} else {
+		if len(indexes) != len(node.Children) {
+			return fmt.Errorf("invalid slice %s: the items must all be indexed", node.Name)
+		}
+
+		sort.SliceStable(node.Children, func(i, j int) bool {
+			return indexes[node.Children[i]] < indexes[node.Children[j]]
+		})
+
+		for i, child := range node.Children {
+			switch {
+			case indexes[child] < i:
+				return fmt.Errorf("invalid slice %s: duplicate index [%d]", node.Name, indexes[child])
+			case indexes[child] > i:
+				return fmt.Errorf("invalid slice %s: missing index [%d]", node.Name, i)
+			}
+		}
+	}
+
+	for _, child := range node.Children {
+		if err := sortChildren(child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// containsNode returns the node with the given name, whatever its casing:
+// the labels of a same element with different casings are merged into the node of the first one in order.
 func containsNode(nodes []*Node, name string) *Node {
 	for _, n := range nodes {
-		if name == n.Name {
+		if strings.EqualFold(name, n.Name) {
 			return n
 		}
 	}
@@ -211,6 +211,175 @@ func TestDecodeToNode(t *testing.T) {
 			},
 			expected: expected{error: true},
 		},
+		{
+			desc: "several entries, slice syntax, ordered by index",
+			in: map[string]string{
+				"traefik.foo[0].aaa":  "bar0",
+				"traefik.foo[1].aaa":  "bar1",
+				"traefik.foo[2].aaa":  "bar2",
+				"traefik.foo[3].aaa":  "bar3",
+				"traefik.foo[4].aaa":  "bar4",
+				"traefik.foo[5].aaa":  "bar5",
+				"traefik.foo[6].aaa":  "bar6",
+				"traefik.foo[7].aaa":  "bar7",
+				"traefik.foo[8].aaa":  "bar8",
+				"traefik.foo[9].aaa":  "bar9",
+				"traefik.foo[10].aaa": "bar10",
+			},
+			expected: expected{node: &Node{
+				Name: "traefik",
+				Children: []*Node{
+					{Name: "foo", Children: []*Node{
+						{Name: "[0]", Children: []*Node{{Name: "aaa", Value: "bar0"}}},
+						{Name: "[1]", Children: []*Node{{Name: "aaa", Value: "bar1"}}},
+						{Name: "[2]", Children: []*Node{{Name: "aaa", Value: "bar2"}}},
+						{Name: "[3]", Children: []*Node{{Name: "aaa", Value: "bar3"}}},
+						{Name: "[4]", Children: []*Node{{Name: "aaa", Value: "bar4"}}},
+						{Name: "[5]", Children: []*Node{{Name: "aaa", Value: "bar5"}}},
+						{Name: "[6]", Children: []*Node{{Name: "aaa", Value: "bar6"}}},
+						{Name: "[7]", Children: []*Node{{Name: "aaa", Value: "bar7"}}},
+						{Name: "[8]", Children: []*Node{{Name: "aaa", Value: "bar8"}}},
+						{Name: "[9]", Children: []*Node{{Name: "aaa", Value: "bar9"}}},
+						{Name: "[10]", Children: []*Node{{Name: "aaa", Value: "bar10"}}},
+					}},
+				},
+			}},
+		},
+		{
+			desc: "several entries, slice syntax, gap in the indexes",
+			in: map[string]string{
+				"traefik.foo[0].aaa": "bar0",
+				"traefik.foo[2].aaa": "bar2",
+			},
+			expected: expected{error: true},
+		},
+		{
+			desc: "several entries, slice syntax, duplicate index",
+			in: map[string]string{
+				"traefik.foo[1].aaa":  "bar1",
+				"traefik.foo[01].aaa": "bar01",
+				"traefik.foo[0].aaa":  "bar0",
+			},
+			expected: expected{error: true},
+		},
+		{
+			desc: "several entries, slice syntax, invalid index",
+			in: map[string]string{
+				"traefik.foo[a].aaa": "bar0",
+			},
+			expected: expected{error: true},
+		},
+		{
+			desc: "several entries, slice syntax, indexed and named items",
+			in: map[string]string{
+				"traefik.foo[0].aaa": "bar0",
+				"traefik.foo.bar":    "bar",
+			},
+			expected: expected{error: true},
+		},
+		{
+			desc: "invalid slice delimiter",
+			in: map[string]string{
+				"traefik.foo].aaa": "bar0",
+			},
+			expected: expected{error: true},
+		},
+		{
+			desc: "quoted name with dots",
+			in: map[string]string{
+				"traefik.foo.`my.app`.aaa": "bar",
+				"traefik.`foo[0]`":         "bur",
+			},
+			expected: expected{node: &Node{
+				Name: "traefik",
+				Children: []*Node{
+					{Name: "foo", Children: []*Node{
+						{Name: "my.app", Children: []*Node{
+							{Name: "aaa", Value: "bar"},
+						}},
+					}},
+					{Name: "foo[0]", Value: "bur"},
+				},
+			}},
+		},
+		{
+			desc: "children sorted by name",
+			in: map[string]string{
+				"traefik.http.routers.foo-bar.rule": "bar",
+				"traefik.http.routers.foo.rule":     "bur",
+			},
+			expected: expected{node: &Node{
+				Name: "traefik",
+				Children: []*Node{
+					{Name: "http", Children: []*Node{
+						{Name: "routers", Children: []*Node{
+							{Name: "foo", Children: []*Node{
+								{Name: "rule", Value: "bur"},
+							}},
+							{Name: "foo-bar", Children: []*Node{
+								{Name: "rule", Value: "bar"},
+							}},
+						}},
+					}},
+				},
+			}},
+		},
+		{
+			desc: "quoted leaf",
+			in: map[string]string{
+				"traefik.foo.`my.app`": "bar",
+			},
+			expected: expected{node: &Node{
+				Name: "traefik",
+				Children: []*Node{
+					{Name: "foo", Children: []*Node{
+						{Name: "my.app", Value: "bar"},
+					}},
+				},
+			}},
+		},
+		{
+			desc: "unterminated backtick",
+			in: map[string]string{
+				"traefik.foo.`my.app.aaa": "bar",
+			},
+			expected: expected{error: true},
+		},
+		{
+			desc: "quoted name followed by characters",
+			in: map[string]string{
+				"traefik.foo.`my.app`aaa": "bar",
+			},
+			expected: expected{error: true},
+		},
+		{
+			desc: "backtick in a name",
+			in: map[string]string{
+				"traefik.foo.my`app`.aaa": "bar",
+			},
+			expected: expected{error: true},
+		},
+		{
+			desc: "empty quoted name",
+			in: map[string]string{
+				"traefik.foo.``.aaa": "bar",
+			},
+			expected: expected{error: true},
+		},
+		{
+			desc: "empty name",
+			in: map[string]string{
+				"traefik.foo..aaa": "bar",
+			},
+			expected: expected{error: true},
+		},
+		{
+			desc: "trailing dot",
+			in: map[string]string{
+				"traefik.foo.": "bar",
+			},
+			expected: expected{error: true},
+		},
 	}
 
 	for _, test := range testCases {
@@ -234,3 +403,113 @@ func TestDecodeToNode(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitKey(t *testing.T) {
+	parts, err := SplitKey("traefik.http.routers.`my.app`.rule")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"traefik", "http", "routers", "my.app", "rule"}, parts)
+
+	_, err = SplitKey("traefik.http.routers.`my.app.rule")
+	assert.EqualError(t, err, "invalid label traefik.http.routers.`my.app.rule: unterminated backtick")
+}
+
+func TestDecodeSliceOfStructs(t *testing.T) {
+	type item struct {
+		Main string
+		SANs []string
+	}
+
+	type element struct {
+		Domains []item
+	}
+
+	labels := map[string]string{
+		"traefik.domains[0].main":  "foo.com",
+		"traefik.domains[0].sans":  "a.foo.com, b.foo.com",
+		"traefik.domains[1].main":  "bar.com",
+		"traefik.domains[10].main": "baz.com",
+	}
+	for i := 2; i < 10; i++ {
+		labels[fmt.Sprintf("traefik.domains[%d].main", i)] = fmt.Sprintf("%d.com", i)
+	}
+
+	var actual element
+	err := Decode(labels, &actual)
+	require.NoError(t, err)
+
+	require.Len(t, actual.Domains, 11)
+	assert.Equal(t, item{Main: "foo.com", SANs: []string{"a.foo.com", "b.foo.com"}}, actual.Domains[0])
+	assert.Equal(t, item{Main: "bar.com"}, actual.Domains[1])
+	assert.Equal(t, item{Main: "2.com"}, actual.Domains[2])
+	assert.Equal(t, item{Main: "baz.com"}, actual.Domains[10])
+
+	delete(labels, "traefik.domains[1].main")
+	err = Decode(labels, &element{})
+	assert.EqualError(t, err, "invalid slice domains: missing index [1]")
+}
+
+func TestDecodeRaw(t *testing.T) {
+	type plugin struct {
+		Name   string
+		Config map[string]interface{} `label:"raw"`
+	}
+
+	type element struct {
+		Plugin *plugin
+	}
+
+	labels := map[string]string{
+		"traefik.plugin.name":                      "demo",
+		"traefik.plugin.config.headers.X-Foo":      "bar",
+		"traefik.plugin.config.retries":            "3",
+		"traefik.plugin.config.ratio":              "0.5",
+		"traefik.plugin.config.enabled":            "true",
+		"traefik.plugin.config.rules[0].path":      "/foo",
+		"traefik.plugin.config.rules[1].path":      "/bar",
+		"traefik.plugin.config.rules[1].methods":   "GET, POST",
+		"traefik.plugin.config.deeply.nested.leaf": "value",
+	}
+
+	var actual element
+	err := Decode(labels, &actual)
+	require.NoError(t, err)
+
+	expected := map[string]interface{}{
+		"headers": map[string]interface{}{"X-Foo": "bar"},
+		"retries": int64(3),
+		"ratio":   0.5,
+		"enabled": true,
+		"rules": []interface{}{
+			map[string]interface{}{"path": "/foo"},
+			map[string]interface{}{"path": "/bar", "methods": "GET, POST"},
+		},
+		"deeply": map[string]interface{}{
+			"nested": map[string]interface{}{"leaf": "value"},
+		},
+	}
+
+	require.NotNil(t, actual.Plugin)
+	assert.Equal(t, "demo", actual.Plugin.Name)
+	assert.Equal(t, expected, actual.Plugin.Config)
+
+	encoded, err := Encode(actual)
+	require.NoError(t, err)
+
+	var decoded element
+	err = Decode(encoded, &decoded)
+	require.NoError(t, err)
+	assert.Equal(t, actual, decoded)
+
+	labels["traefik.plugin.unknown"] = "foo"
+	err = Decode(labels, &element{})
+	assert.EqualError(t, err, `Plugin: unknown field "unknown"`)
+}
+
+func TestDecodeRawUnsupportedType(t *testing.T) {
+	type element struct {
+		Config map[string]string `label:"raw"`
+	}
+
+	err := Decode(map[string]string{"traefik.config.foo": "bar"}, &element{})
+	assert.EqualError(t, err, "unsupported raw field type: map[string]string, map[string]interface{} is expected")
+}
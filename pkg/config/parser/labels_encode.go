@@ -1,6 +1,7 @@
 package parser
 
 // EncodeNode Converts a node to labels.
+// The names containing dots are quoted with backticks, e.g. traefik.http.routers.`my.app`.rule.
 // nodes -> labels
 func EncodeNode(node *Node) map[string]string {
 	labels := make(map[string]string)
@@ -19,7 +20,7 @@ func encodeNode(labels map[string]string, root string, node *Node) {
 			sep = "."
 		}
 
-		childName := root + sep + child.Name
+		childName := root + sep + QuoteName(child.Name)
 
 		if len(child.Children) > 0 {
 			encodeNode(labels, childName, child)
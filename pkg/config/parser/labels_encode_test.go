@@ -165,6 +165,24 @@ func TestEncodeNode(t *testing.T) {
 				"traefik.foo[1].bbb": "bur1",
 			},
 		},
+		{
+			desc: "names with dots",
+			node: &Node{
+				Name: "traefik",
+				Children: []*Node{
+					{Name: "foo", Children: []*Node{
+						{Name: "my.app", Children: []*Node{
+							{Name: "aaa", Value: "bar"},
+						}},
+						{Name: "my.other.app", Value: "bur"},
+					}},
+				},
+			},
+			expected: map[string]string{
+				"traefik.foo.`my.app`.aaa":   "bar",
+				"traefik.foo.`my.other.app`": "bur",
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -8,7 +8,15 @@ import (
 )
 
 // AddMetadata adds metadata such as type, inferred from element, to a node.
+// It stops at the first error, see AddMetadataWithOpts to collect all of them.
 func AddMetadata(element interface{}, node *Node) error {
+	return AddMetadataWithOpts(element, node, DecodeOpts{})
+}
+
+// AddMetadataWithOpts adds metadata such as type, inferred from element, to a node, with the given options.
+// With CollectErrors, the invalid nodes, such as the unknown fields, are removed from the tree,
+// so that the element can still be filled with the valid ones.
+func AddMetadataWithOpts(element interface{}, node *Node, opts DecodeOpts) error {
 	if node == nil {
 		return nil
 	}
@@ -24,19 +32,48 @@ func AddMetadata(element interface{}, node *Node) error {
 	rootType := reflect.TypeOf(element)
 	node.Kind = rootType.Kind()
 
-	return browseChildren(rootType, node)
+	return metadataAdder{opts}.browseChildren(rootType, node)
+}
+
+type metadataAdder struct {
+	DecodeOpts
 }
 
-func browseChildren(fType reflect.Type, node *Node) error {
+// browseChildren adds the metadata to the children of the node, the fields of the struct of type fType.
+// A child which is not a valid field is an error, and is removed from the tree with CollectErrors.
+func (m metadataAdder) browseChildren(fType reflect.Type, node *Node) error {
+	var errs DecodeErrors
+
+	children := make([]*Node, 0, len(node.Children))
 	for _, child := range node.Children {
-		if err := addMetadata(fType, child); err != nil {
-			return err
+		field, err := m.addFieldMetadata(fType, child)
+		if err != nil {
+			if !m.CollectErrors {
+				return err
+			}
+			errs = append(errs, err)
+			continue
+		}
+
+		children = append(children, child)
+
+		if err = m.browseField(field, child); err != nil {
+			if !m.CollectErrors {
+				return err
+			}
+			errs = appendErrors(errs, err)
 		}
 	}
-	return nil
+
+	if len(children) != len(node.Children) {
+		node.Children = children
+	}
+
+	return errs.asError()
 }
 
-func addMetadata(rootType reflect.Type, node *Node) error {
+// addFieldMetadata adds to the node the metadata of the field of the struct of type rootType it matches.
+func (m metadataAdder) addFieldMetadata(rootType reflect.Type, node *Node) (reflect.StructField, error) {
 	rType := rootType
 	if rootType.Kind() == reflect.Ptr {
 		rType = rootType.Elem()
@@ -44,68 +81,130 @@ func addMetadata(rootType reflect.Type, node *Node) error {
 
 	field, err := findTypedField(rType, node)
 	if err != nil {
-		return err
+		return field, err
 	}
 
 	if err = isSupportedType(field); err != nil {
-		return err
+		return field, err
 	}
 
 	fType := field.Type
 	node.Kind = fType.Kind()
 	node.Tag = field.Tag
 
+	name := canonicalName(field.Name, field.Tag)
+
+	if isScalar(fType) || fType.Kind() == reflect.Ptr && isScalar(fType.Elem()) {
+		if len(node.Children) > 0 {
+			return field, fmt.Errorf("%s cannot have children (type %s)", name, fType)
+		}
+		return field, nil
+	}
+
 	if fType.Kind() == reflect.Struct || fType.Kind() == reflect.Ptr && fType.Elem().Kind() == reflect.Struct ||
 		fType.Kind() == reflect.Map {
 		if len(node.Children) == 0 && field.Tag.Get(TagLabel) != TagLabelAllowEmpty {
-			return fmt.Errorf("%s cannot be a standalone element (type %s)", node.Name, fType)
+			return field, fmt.Errorf("%s cannot be a standalone element (type %s)", name, fType)
 		}
 
 		node.Disabled = len(node.Value) > 0 && !strings.EqualFold(node.Value, "true") && field.Tag.Get(TagLabel) == TagLabelAllowEmpty
 	}
 
-	if len(node.Children) == 0 {
+	return field, nil
+}
+
+// browseField adds the metadata to the children of the node of the field.
+func (m metadataAdder) browseField(field reflect.StructField, node *Node) error {
+	fType := field.Type
+	name := canonicalName(field.Name, field.Tag)
+
+	if len(node.Children) == 0 || isScalar(fType) || fType.Kind() == reflect.Ptr && isScalar(fType.Elem()) {
+		return nil
+	}
+
+	// The subtree of a raw field is kept as is: its nodes do not match any struct field.
+	if field.Tag.Get(TagLabel) == TagLabelRaw {
 		return nil
 	}
 
 	if fType.Kind() == reflect.Struct || fType.Kind() == reflect.Ptr && fType.Elem().Kind() == reflect.Struct {
-		return browseChildren(fType, node)
+		return wrapPath(name, m.browseChildren(fType, node))
 	}
 
 	if fType.Kind() == reflect.Map {
+		var errs DecodeErrors
+
+		children := make([]*Node, 0, len(node.Children))
 		for _, child := range node.Children {
 			// elem is a map entry value type
 			elem := fType.Elem()
 			child.Kind = elem.Kind()
 
+			if elem.Kind() == reflect.Struct || (elem.Kind() == reflect.Ptr && elem.Elem().Kind() == reflect.Struct) {
+				if err := checkUnquotedName(elem, child); err != nil {
+					if !m.CollectErrors {
+						return wrapPath(name, wrapPath(child.Name, err))
+					}
+					errs = append(errs, wrapPath(name, wrapPath(child.Name, err)))
+					continue
+				}
+			}
+
+			children = append(children, child)
+
 			if elem.Kind() == reflect.Map || elem.Kind() == reflect.Struct ||
 				(elem.Kind() == reflect.Ptr && elem.Elem().Kind() == reflect.Struct) {
-				if err = browseChildren(elem, child); err != nil {
-					return err
+				if err := m.browseChildren(elem, child); err != nil {
+					if !m.CollectErrors {
+						return wrapPath(name, wrapPath(child.Name, err))
+					}
+					errs = appendErrors(errs, wrapPath(name, wrapPath(child.Name, err)))
 				}
 			}
 		}
-		return nil
+
+		if len(children) != len(node.Children) {
+			node.Children = children
+		}
+
+		return errs.asError()
 	}
 
 	if fType.Kind() == reflect.Slice {
 		if field.Tag.Get(TagLabelSliceAsStruct) != "" {
-			return browseChildren(fType.Elem(), node)
+			return wrapPath(name, m.browseChildren(fType.Elem(), node))
 		}
 
+		var errs DecodeErrors
 		for _, ch := range node.Children {
 			ch.Kind = fType.Elem().Kind()
-			if err = browseChildren(fType.Elem(), ch); err != nil {
-				return err
+			if err := m.browseChildren(fType.Elem(), ch); err != nil {
+				if !m.CollectErrors {
+					return wrapPath(name, wrapPath(ch.Name, err))
+				}
+				errs = appendErrors(errs, wrapPath(name, wrapPath(ch.Name, err)))
 			}
 		}
-		return nil
+
+		return errs.asError()
 	}
 
-	return fmt.Errorf("invalid node %s: %v", node.Name, fType.Kind())
+	return fmt.Errorf("invalid node %s: %v", name, fType.Kind())
 }
 
 func findTypedField(rType reflect.Type, node *Node) (reflect.StructField, error) {
+	if field, ok := lookupTypedField(rType, node); ok {
+		return field, nil
+	}
+
+	if suggestion := suggestFieldName(rType, node.Name); suggestion != "" {
+		return reflect.StructField{}, fmt.Errorf("unknown field %q (did you mean %q?)", node.Name, suggestion)
+	}
+
+	return reflect.StructField{}, fmt.Errorf("unknown field %q", node.Name)
+}
+
+func lookupTypedField(rType reflect.Type, node *Node) (reflect.StructField, bool) {
 	for i := 0; i < rType.NumField(); i++ {
 		cField := rType.Field(i)
 
@@ -117,23 +216,137 @@ func findTypedField(rType reflect.Type, node *Node) (reflect.StructField, error)
 		if IsExported(cField) {
 			if cField.Anonymous {
 				if cField.Type.Kind() == reflect.Struct {
-					structField, err := findTypedField(cField.Type, node)
-					if err != nil {
+					structField, ok := lookupTypedField(cField.Type, node)
+					if !ok {
 						continue
 					}
-					return structField, nil
+					return structField, true
 				}
 			}
 
 			if strings.EqualFold(fieldName, node.Name) {
 				node.FieldName = cField.Name
-				return cField, nil
+				return cField, true
+			}
+		}
+
+	}
+
+	return reflect.StructField{}, false
+}
+
+// checkUnquotedName rejects the map entry, of a struct type, which looks like the first part of a name containing dots
+// not quoted with backticks, e.g. my in traefik.http.routers.my.app.rule: its child is neither a field of the struct,
+// nor a misspelling of one, but has children itself, as app.
+func checkUnquotedName(elem reflect.Type, entry *Node) error {
+	rType := elem
+	if rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+
+	for _, child := range entry.Children {
+		if len(child.Children) == 0 {
+			continue
+		}
+
+		if _, ok := lookupTypedField(rType, child); ok || suggestFieldName(rType, child.Name) != "" {
+			continue
+		}
+
+		return fmt.Errorf("unknown field %q: a name containing dots must be quoted with backticks, e.g. `%s.%s`", child.Name, entry.Name, child.Name)
+	}
+
+	return nil
+}
+
+// suggestFieldName returns the field name of the struct which is the closest to the unknown name, by edit distance,
+// or an empty string if none is close enough to be a likely misspelling.
+// The suggestion follows the casing of the name: lowercase for a lowercase name, as in the labels and the flags,
+// and camel case otherwise, as in the files.
+func suggestFieldName(rType reflect.Type, name string) string {
+	maxDistance := len(name) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	var suggestion string
+	for _, fieldName := range fieldNames(rType) {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(fieldName))
+		if distance <= maxDistance {
+			suggestion = fieldName
+			maxDistance = distance - 1
+		}
+	}
+
+	switch {
+	case suggestion == "":
+		return ""
+	case name == strings.ToLower(name):
+		return strings.ToLower(suggestion)
+	case len(suggestion) > 1 && strings.ToUpper(suggestion[:2]) == suggestion[:2]:
+		// An acronym, such as HTTP.
+		return suggestion
+	default:
+		return strings.ToLower(suggestion[:1]) + suggestion[1:]
+	}
+}
+
+// fieldNames returns the names of the exported fields of the struct, as they are written in the labels.
+func fieldNames(rType reflect.Type) []string {
+	var names []string
+
+	for i := 0; i < rType.NumField(); i++ {
+		cField := rType.Field(i)
+		if !IsExported(cField) {
+			continue
+		}
+
+		if cField.Anonymous && cField.Type.Kind() == reflect.Struct {
+			names = append(names, fieldNames(cField.Type)...)
+			continue
+		}
+
+		names = append(names, canonicalName(cField.Name, cField.Tag))
+	}
+
+	return names
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
 			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
 		}
 
+		previous, current = current, previous
 	}
 
-	return reflect.StructField{}, fmt.Errorf("field not found, node: %s", node.Name)
+	return previous[len(b)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
 }
 
 // IsExported reports whether f is exported.
@@ -145,6 +358,20 @@ func IsExported(f reflect.StructField) bool {
 func isSupportedType(field reflect.StructField) error {
 	fType := field.Type
 
+	if field.Tag.Get(TagLabel) == TagLabelRaw && fType != reflect.TypeOf(map[string]interface{}{}) {
+		return fmt.Errorf("unsupported raw field type: %v, map[string]interface{} is expected", fType)
+	}
+
+	if unit := field.Tag.Get(TagUnit); unit != "" {
+		if unit != TagUnitBytes {
+			return fmt.Errorf("unsupported unit: %s", unit)
+		}
+
+		if fType.Kind() != reflect.Int64 && (fType.Kind() != reflect.Ptr || fType.Elem().Kind() != reflect.Int64) {
+			return fmt.Errorf("unsupported byte quantity field type: %v, int64 is expected", fType)
+		}
+	}
+
 	if fType.Kind() == reflect.Slice {
 		switch fType.Elem().Kind() {
 		case reflect.String,
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -193,6 +194,51 @@ func TestAddMetadata(t *testing.T) {
 			}{},
 			expected: expected{error: true},
 		},
+		{
+			desc: "level 1, byte quantity",
+			tree: &Node{
+				Name: "traefik",
+				Children: []*Node{
+					{Name: "Foo", Value: "10Mi"},
+				},
+			},
+			structure: struct {
+				Foo int64 `unit:"bytes"`
+			}{},
+			expected: expected{node: &Node{
+				Name: "traefik",
+				Kind: reflect.Struct,
+				Children: []*Node{
+					{Name: "Foo", FieldName: "Foo", Value: "10Mi", Kind: reflect.Int64, Tag: `unit:"bytes"`},
+				},
+			}},
+		},
+		{
+			desc: "level 1, byte quantity of an unsupported type",
+			tree: &Node{
+				Name: "traefik",
+				Children: []*Node{
+					{Name: "Foo", Value: "10Mi"},
+				},
+			},
+			structure: struct {
+				Foo int `unit:"bytes"`
+			}{},
+			expected: expected{error: true},
+		},
+		{
+			desc: "level 1, unknown unit",
+			tree: &Node{
+				Name: "traefik",
+				Children: []*Node{
+					{Name: "Foo", Value: "10"},
+				},
+			},
+			structure: struct {
+				Foo int64 `unit:"seconds"`
+			}{},
+			expected: expected{error: true},
+		},
 		{
 			desc: "level 1, int pointer",
 			tree: &Node{
@@ -256,6 +302,42 @@ func TestAddMetadata(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "level 1, time",
+			tree: &Node{
+				Name: "traefik",
+				Children: []*Node{
+					{Name: "Foo", Value: "2019-10-16"},
+				},
+			},
+			structure: struct {
+				Foo time.Time
+			}{},
+			expected: expected{
+				node: &Node{
+					Name: "traefik",
+					Kind: reflect.Struct,
+					Children: []*Node{
+						{Name: "Foo", FieldName: "Foo", Value: "2019-10-16", Kind: reflect.Struct},
+					},
+				},
+			},
+		},
+		{
+			desc: "level 1, time with children",
+			tree: &Node{
+				Name: "traefik",
+				Children: []*Node{
+					{Name: "Foo", Children: []*Node{
+						{Name: "Bar", Value: "2019-10-16"},
+					}},
+				},
+			},
+			structure: struct {
+				Foo time.Time
+			}{},
+			expected: expected{error: true},
+		},
 		{
 			desc: "level 1, 2 children with different types",
 			tree: &Node{
@@ -1009,3 +1091,45 @@ func TestAddMetadata(t *testing.T) {
 }
 
 type MySliceType []string
+
+func Test_suggestFieldName(t *testing.T) {
+	type Inner struct {
+		Embedded string
+	}
+
+	rType := reflect.TypeOf(struct {
+		Inner
+		Rule           string
+		EntryPoints    []string
+		HTTP           string
+		PassHost       bool
+		Servers        []string `label-slice-as-struct:"server"`
+		unexported     string
+		PassHostHeader bool
+	}{})
+
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "rulle", expected: "rule"},
+		{name: "RULLE", expected: "rule"},
+		{name: "entryPoint", expected: "entryPoints"},
+		{name: "HTTPS", expected: "HTTP"},
+		{name: "passhostheaders", expected: "passhostheader"},
+		{name: "passhots", expected: "passhost"},
+		{name: "serve", expected: "server"},
+		{name: "embeded", expected: "embedded"},
+		{name: "unexported", expected: ""},
+		{name: "foo", expected: ""},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, suggestFieldName(rType, test.name))
+		})
+	}
+}
@@ -1,35 +1,76 @@
 // Package parser implements decoding and encoding between a flat map of labels and a typed Configuration.
 package parser
 
+// DecodeOpts holds the options of the decoding of a tree of nodes into an element.
+type DecodeOpts struct {
+	// CollectErrors goes on decoding after an error, skipping the invalid nodes,
+	// and returns all the errors at once as DecodeErrors, e.g. to report all the mistakes of a configuration file.
+	CollectErrors bool
+}
+
 // Decode decodes the given map of labels into the given element.
 // If any filters are present, labels which do not match the filters are skipped.
 // The operation goes through three stages roughly summarized as:
 // labels -> tree of untyped nodes
 // untyped nodes -> nodes augmented with metadata such as kind (inferred from element)
 // "typed" nodes -> typed element
+// The element is then validated, see Validate.
+// It stops at the first error, see DecodeWithOpts to collect all of them.
 func Decode(labels map[string]string, element interface{}, filters ...string) error {
+	return DecodeWithOpts(labels, element, DecodeOpts{}, filters...)
+}
+
+// DecodeWithOpts decodes the given map of labels into the given element, with the given options.
+// If any filters are present, labels which do not match the filters are skipped.
+func DecodeWithOpts(labels map[string]string, element interface{}, opts DecodeOpts, filters ...string) error {
 	node, err := DecodeToNode(labels, filters...)
 	if err != nil {
 		return err
 	}
 
-	err = AddMetadata(element, node)
+	return DecodeNode(node, element, opts)
+}
+
+// DecodeNode adds the metadata to the tree of untyped nodes, fills the element with it, and validates the element.
+// With CollectErrors, the errors of the metadata and of the filling are returned together,
+// and the element is only validated when there is none, as its validation would be misleading otherwise.
+func DecodeNode(node *Node, element interface{}, opts DecodeOpts) error {
+	var errs DecodeErrors
+
+	err := AddMetadataWithOpts(element, node, opts)
 	if err != nil {
-		return err
+		if !opts.CollectErrors || len(node.Children) == 0 {
+			return err
+		}
+		errs = appendErrors(errs, err)
 	}
 
-	err = Fill(element, node)
+	err = FillWithOpts(element, node, opts)
 	if err != nil {
-		return err
+		if !opts.CollectErrors {
+			return err
+		}
+		errs = appendErrors(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errs.asError()
 	}
 
-	return nil
+	return Validate(element)
 }
 
 // Encode converts an element to labels.
+// The values of the fields tagged with export:"redact" are redacted, see EncodeWithOpts to keep them.
 // element -> node (value) -> label (node)
 func Encode(element interface{}) (map[string]string, error) {
-	node, err := EncodeToNode(element, true)
+	return EncodeWithOpts(element, NodeOpts{OmitEmpty: true})
+}
+
+// EncodeWithOpts converts an element to labels, with the given options.
+// element -> node (value) -> label (node)
+func EncodeWithOpts(element interface{}, opts NodeOpts) (map[string]string, error) {
+	node, err := EncodeToNodeWithOpts(element, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -4,6 +4,7 @@ const (
 	// TagLabel allows to apply a custom behavior.
 	// - "allowEmpty": allows to create an empty struct.
 	// - "-": ignore the field.
+	// - "raw": the field, a map[string]interface{}, holds the subtree of its node as is, without matching any struct field.
 	TagLabel = "label"
 
 	// TagLabelSliceAsStruct allows to use a slice of struct by creating one entry into the slice.
@@ -15,4 +16,29 @@ const (
 
 	// TagLabelAllowEmpty is related to TagLabel.
 	TagLabelAllowEmpty = "allowEmpty"
+
+	// TagLabelRaw is related to TagLabel.
+	TagLabelRaw = "raw"
+
+	// TagDefault is the default value of the field, set when its struct is instantiated by the parser,
+	// before the struct is filled: a value given by the user overrides it.
+	TagDefault = "default"
+
+	// TagExport tells how the field is exported when the element is encoded for display.
+	// - "redact": the value is replaced by RedactedValue, as it is sensitive (e.g. a password hash, or a private key).
+	TagExport = "export"
+
+	// TagExportRedact is related to TagExport.
+	TagExportRedact = "redact"
+
+	// TagUnit is the unit of the value of the field.
+	// - "bytes": the field, an int64, holds a byte quantity, given as a number of bytes with an optional suffix, e.g. 10MB or 10Mi,
+	//   see types.ParseByteQuantity.
+	TagUnit = "unit"
+
+	// TagUnitBytes is related to TagUnit.
+	TagUnitBytes = "bytes"
 )
+
+// RedactedValue replaces the values of the redacted fields.
+const RedactedValue = "xxxx"
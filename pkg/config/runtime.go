@@ -25,12 +25,17 @@ func NewRuntimeConfig(conf Configuration) *RuntimeConfiguration {
 
 	runtimeConfig := &RuntimeConfiguration{}
 
+	sources := conf.Sources
+	if sources == nil {
+		sources = NewSources()
+	}
+
 	if conf.HTTP != nil {
 		routers := conf.HTTP.Routers
 		if len(routers) > 0 {
 			runtimeConfig.Routers = make(map[string]*RouterInfo, len(routers))
 			for k, v := range routers {
-				runtimeConfig.Routers[k] = &RouterInfo{Router: v}
+				runtimeConfig.Routers[k] = &RouterInfo{Router: v, Source: sourceOf(sources.Routers, k)}
 			}
 		}
 
@@ -38,7 +43,7 @@ func NewRuntimeConfig(conf Configuration) *RuntimeConfiguration {
 		if len(services) > 0 {
 			runtimeConfig.Services = make(map[string]*ServiceInfo, len(services))
 			for k, v := range services {
-				runtimeConfig.Services[k] = &ServiceInfo{Service: v}
+				runtimeConfig.Services[k] = &ServiceInfo{Service: v, Source: sourceOf(sources.Services, k)}
 			}
 		}
 
@@ -46,7 +51,7 @@ func NewRuntimeConfig(conf Configuration) *RuntimeConfiguration {
 		if len(middlewares) > 0 {
 			runtimeConfig.Middlewares = make(map[string]*MiddlewareInfo, len(middlewares))
 			for k, v := range middlewares {
-				runtimeConfig.Middlewares[k] = &MiddlewareInfo{Middleware: v}
+				runtimeConfig.Middlewares[k] = &MiddlewareInfo{Middleware: v, Source: sourceOf(sources.Middlewares, k)}
 			}
 		}
 	}
@@ -55,14 +60,14 @@ func NewRuntimeConfig(conf Configuration) *RuntimeConfiguration {
 		if len(conf.TCP.Routers) > 0 {
 			runtimeConfig.TCPRouters = make(map[string]*TCPRouterInfo, len(conf.TCP.Routers))
 			for k, v := range conf.TCP.Routers {
-				runtimeConfig.TCPRouters[k] = &TCPRouterInfo{TCPRouter: v}
+				runtimeConfig.TCPRouters[k] = &TCPRouterInfo{TCPRouter: v, Source: sourceOf(sources.TCPRouters, k)}
 			}
 		}
 
 		if len(conf.TCP.Services) > 0 {
 			runtimeConfig.TCPServices = make(map[string]*TCPServiceInfo, len(conf.TCP.Services))
 			for k, v := range conf.TCP.Services {
-				runtimeConfig.TCPServices[k] = &TCPServiceInfo{TCPService: v}
+				runtimeConfig.TCPServices[k] = &TCPServiceInfo{TCPService: v, Source: sourceOf(sources.TCPServices, k)}
 			}
 		}
 	}
@@ -70,6 +75,14 @@ func NewRuntimeConfig(conf Configuration) *RuntimeConfiguration {
 	return runtimeConfig
 }
 
+func sourceOf(sources map[string]Source, name string) *Source {
+	source, ok := sources[name]
+	if !ok {
+		return nil
+	}
+	return &source
+}
+
 // PopulateUsedBy populates all the UsedBy lists of the underlying fields of r,
 // based on the relations between the included services, routers, and middlewares.
 func (r *RuntimeConfiguration) PopulateUsedBy() {
@@ -130,14 +143,16 @@ func (r *RuntimeConfiguration) PopulateUsedBy() {
 
 // RouterInfo holds information about a currently running HTTP router
 type RouterInfo struct {
-	*Router        // dynamic configuration
-	Err     string `json:"error,omitempty"` // initialization error
+	*Router         // dynamic configuration
+	Err     string  `json:"error,omitempty"`  // initialization error
+	Source  *Source `json:"source,omitempty"` // configuration file defining the router
 }
 
 // TCPRouterInfo holds information about a currently running TCP router
 type TCPRouterInfo struct {
-	*TCPRouter        // dynamic configuration
-	Err        string `json:"error,omitempty"` // initialization error
+	*TCPRouter         // dynamic configuration
+	Err        string  `json:"error,omitempty"`  // initialization error
+	Source     *Source `json:"source,omitempty"` // configuration file defining the router
 }
 
 // MiddlewareInfo holds information about a currently running middleware
@@ -145,6 +160,7 @@ type MiddlewareInfo struct {
 	*Middleware          // dynamic configuration
 	Err         error    `json:"error,omitempty"`  // initialization error
 	UsedBy      []string `json:"usedBy,omitempty"` // list of routers and services using that middleware
+	Source      *Source  `json:"source,omitempty"` // configuration file defining the middleware
 }
 
 // ServiceInfo holds information about a currently running service
@@ -152,6 +168,7 @@ type ServiceInfo struct {
 	*Service          // dynamic configuration
 	Err      error    `json:"error,omitempty"`  // initialization error
 	UsedBy   []string `json:"usedBy,omitempty"` // list of routers using that service
+	Source   *Source  `json:"source,omitempty"` // configuration file defining the service
 
 	statusMu sync.RWMutex
 	status   map[string]string // keyed by server URL
@@ -191,6 +208,7 @@ type TCPServiceInfo struct {
 	*TCPService          // dynamic configuration
 	Err         error    `json:"error,omitempty"`  // initialization error
 	UsedBy      []string `json:"usedBy,omitempty"` // list of routers using that service
+	Source      *Source  `json:"source,omitempty"` // configuration file defining the service
 }
 
 func getProviderName(elementName string) string {
@@ -688,3 +688,40 @@ func TestPopulateUsedby(t *testing.T) {
 	}
 
 }
+
+func TestNewRuntimeConfigSources(t *testing.T) {
+	conf := config.Configuration{
+		HTTP: &config.HTTPConfiguration{
+			Routers: map[string]*config.Router{
+				"file.router":   {Service: "file.service"},
+				"docker.router": {Service: "file.service"},
+			},
+			Services: map[string]*config.Service{
+				"file.service": {},
+			},
+		},
+		TCP: &config.TCPConfiguration{
+			Routers: map[string]*config.TCPRouter{
+				"file.router": {Service: "file.service"},
+			},
+		},
+		Sources: &config.Sources{
+			Routers: map[string]config.Source{
+				"file.router": {File: "dynamic.toml", Line: 3},
+			},
+			Services: map[string]config.Source{
+				"file.service": {File: "dynamic.toml", Line: 12},
+			},
+			TCPRouters: map[string]config.Source{
+				"file.router": {File: "dynamic.toml", Line: 20},
+			},
+		},
+	}
+
+	runtimeConfig := config.NewRuntimeConfig(conf)
+
+	assert.Equal(t, &config.Source{File: "dynamic.toml", Line: 3}, runtimeConfig.Routers["file.router"].Source)
+	assert.Nil(t, runtimeConfig.Routers["docker.router"].Source)
+	assert.Equal(t, &config.Source{File: "dynamic.toml", Line: 12}, runtimeConfig.Services["file.service"].Source)
+	assert.Equal(t, &config.Source{File: "dynamic.toml", Line: 20}, runtimeConfig.TCPRouters["file.router"].Source)
+}
@@ -6,6 +6,7 @@ type EntryPoint struct {
 	Transport        *EntryPointsTransport `description:"Configures communication between clients and Traefik."`
 	ProxyProtocol    *ProxyProtocol        `description:"Proxy-Protocol configuration." label:"allowEmpty"`
 	ForwardedHeaders *ForwardedHeaders     `description:"Trust client forwarding headers."`
+	Middlewares      []string              `description:"Default middlewares of the routers of the entry point, applied before their own middlewares." export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -19,6 +20,7 @@ func (e *EntryPoint) SetDefaults() {
 type ForwardedHeaders struct {
 	Insecure   bool     `description:"Trust all forwarded headers." export:"true"`
 	TrustedIPs []string `description:"Trust only forwarded headers from selected IPs."`
+	Depth      int      `description:"Depth, from the right, of the client IP in the trusted X-Forwarded-For header, used by the ClientIP matcher of the rules (0: the remote address)." export:"true"`
 }
 
 // ProxyProtocol contains Proxy-Protocol configuration.
@@ -2,6 +2,9 @@ package static
 
 import (
 	"errors"
+	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -62,6 +65,13 @@ type Configuration struct {
 	HostResolver *types.HostResolverConfig `description:"Enable CNAME Flattening." export:"true" label:"allowEmpty"`
 
 	ACME *acmeprovider.Configuration `description:"Enable ACME (Let's Encrypt): automatic SSL." export:"true"`
+
+	CertificatesResolvers map[string]CertificateResolver `description:"Certificates resolvers configuration, referenced by the routers with their tls.certResolver option." export:"true"`
+}
+
+// CertificateResolver holds the configuration of a certificates resolver.
+type CertificateResolver struct {
+	ACME *acmeprovider.Configuration `description:"Enable ACME (Let's Encrypt): automatic SSL." export:"true"`
 }
 
 // Global holds the global configuration.
@@ -159,6 +169,18 @@ type Providers struct {
 	Rancher                   *rancher.Provider  `description:"Enable Rancher backend with default settings." export:"true" label:"allowEmpty"`
 }
 
+// rootKeys returns the names of the root options of the static configuration.
+func rootKeys() []string {
+	rootType := reflect.TypeOf(Configuration{})
+
+	keys := make([]string, 0, rootType.NumField())
+	for i := 0; i < rootType.NumField(); i++ {
+		keys = append(keys, rootType.Field(i).Name)
+	}
+
+	return keys
+}
+
 // SetEffectiveConfiguration adds missing configuration parameters derived from existing ones.
 // It also takes care of maintaining backwards compatibility.
 func (c *Configuration) SetEffectiveConfiguration(configFile string) {
@@ -185,10 +207,16 @@ func (c *Configuration) SetEffectiveConfiguration(configFile string) {
 		if c.Providers.Docker.SwarmModeRefreshSeconds <= 0 {
 			c.Providers.Docker.SwarmModeRefreshSeconds = types.Duration(15 * time.Second)
 		}
+
+		c.Providers.Docker.EntryPoints = nil
+		for name := range c.EntryPoints {
+			c.Providers.Docker.EntryPoints = append(c.Providers.Docker.EntryPoints, name)
+		}
 	}
 
 	if c.Providers.File != nil {
 		c.Providers.File.TraefikFile = configFile
+		c.Providers.File.StaticKeys = rootKeys()
 	}
 
 	if c.Providers.Rancher != nil {
@@ -299,52 +327,91 @@ func (c *Configuration) initTracing() {
 
 // FIXME handle on new configuration ACME struct
 func (c *Configuration) initACMEProvider() {
-	if c.ACME != nil {
-		c.ACME.CAServer = getSafeACMECAServer(c.ACME.CAServer)
+	initACMEConfiguration(c.ACME)
 
-		if c.ACME.DNSChallenge != nil && c.ACME.HTTPChallenge != nil {
+	for _, resolver := range c.CertificatesResolvers {
+		initACMEConfiguration(resolver.ACME)
+	}
+}
+
+func initACMEConfiguration(acme *acmeprovider.Configuration) {
+	if acme != nil {
+		acme.CAServer = getSafeACMECAServer(acme.CAServer)
+
+		if acme.DNSChallenge != nil && acme.HTTPChallenge != nil {
 			log.Warn("Unable to use DNS challenge and HTTP challenge at the same time. Fallback to DNS challenge.")
-			c.ACME.HTTPChallenge = nil
+			acme.HTTPChallenge = nil
 		}
 
-		if c.ACME.DNSChallenge != nil && c.ACME.TLSChallenge != nil {
+		if acme.DNSChallenge != nil && acme.TLSChallenge != nil {
 			log.Warn("Unable to use DNS challenge and TLS challenge at the same time. Fallback to DNS challenge.")
-			c.ACME.TLSChallenge = nil
+			acme.TLSChallenge = nil
 		}
 
-		if c.ACME.HTTPChallenge != nil && c.ACME.TLSChallenge != nil {
+		if acme.HTTPChallenge != nil && acme.TLSChallenge != nil {
 			log.Warn("Unable to use HTTP challenge and TLS challenge at the same time. Fallback to TLS challenge.")
-			c.ACME.HTTPChallenge = nil
+			acme.HTTPChallenge = nil
 		}
 	}
 }
 
-// InitACMEProvider create an acme provider from the ACME part of globalConfiguration
-func (c *Configuration) InitACMEProvider() (*acmeprovider.Provider, error) {
+// InitACMEProviders creates the acme providers from the certificates resolvers, sorted by name,
+// followed by the one of the ACME part of globalConfiguration,
+// whose HTTP challenge route matches the tokens of any resolver, hence is the last one.
+// Each certificates resolver stores its account and certificates in its own storage.
+func (c *Configuration) InitACMEProviders() ([]*acmeprovider.Provider, error) {
+	var providers []*acmeprovider.Provider
+	storages := make(map[string]string)
+
 	if c.ACME != nil {
 		if len(c.ACME.Storage) == 0 {
 			return nil, errors.New("unable to initialize ACME provider with no storage location for the certificates")
 		}
-		return &acmeprovider.Provider{
+		storages[c.ACME.Storage] = "acme"
+	}
+
+	var resolverNames []string
+	for name := range c.CertificatesResolvers {
+		resolverNames = append(resolverNames, name)
+	}
+	sort.Strings(resolverNames)
+
+	for _, name := range resolverNames {
+		resolver := c.CertificatesResolvers[name]
+		if resolver.ACME == nil {
+			return nil, fmt.Errorf("unable to initialize the certificates resolver %s with no ACME configuration", name)
+		}
+
+		if len(resolver.ACME.Storage) == 0 {
+			return nil, fmt.Errorf("unable to initialize the certificates resolver %s with no storage location for the certificates", name)
+		}
+
+		if owner, ok := storages[resolver.ACME.Storage]; ok {
+			return nil, fmt.Errorf("unable to initialize the certificates resolver %s: its storage %s is already used by %s", name, resolver.ACME.Storage, owner)
+		}
+		storages[resolver.ACME.Storage] = name
+
+		providers = append(providers, &acmeprovider.Provider{
+			Configuration: resolver.ACME,
+			ResolverName:  name,
+		})
+	}
+
+	if c.ACME != nil {
+		providers = append(providers, &acmeprovider.Provider{
 			Configuration: c.ACME,
-		}, nil
+		})
 	}
-	return nil, nil
+
+	return providers, nil
 }
 
 // ValidateConfiguration validate that configuration is coherent
 func (c *Configuration) ValidateConfiguration() {
-	if c.ACME != nil {
-		for _, domain := range c.ACME.Domains {
-			if domain.Main != dns01.UnFqdn(domain.Main) {
-				log.Warnf("FQDN detected, please remove the trailing dot: %s", domain.Main)
-			}
-			for _, san := range domain.SANs {
-				if san != dns01.UnFqdn(san) {
-					log.Warnf("FQDN detected, please remove the trailing dot: %s", san)
-				}
-			}
-		}
+	validateACMEDomains(c.ACME)
+
+	for _, resolver := range c.CertificatesResolvers {
+		validateACMEDomains(resolver.ACME)
 	}
 	// FIXME Validate store config?
 	// if c.ACME != nil {
@@ -357,6 +424,21 @@ func (c *Configuration) ValidateConfiguration() {
 	// }
 }
 
+func validateACMEDomains(acme *acmeprovider.Configuration) {
+	if acme != nil {
+		for _, domain := range acme.Domains {
+			if domain.Main != dns01.UnFqdn(domain.Main) {
+				log.Warnf("FQDN detected, please remove the trailing dot: %s", domain.Main)
+			}
+			for _, san := range domain.SANs {
+				if san != dns01.UnFqdn(san) {
+					log.Warnf("FQDN detected, please remove the trailing dot: %s", san)
+				}
+			}
+		}
+	}
+}
+
 func getSafeACMECAServer(caServerSrc string) string {
 	if len(caServerSrc) == 0 {
 		return DefaultAcmeCAServer
@@ -20,17 +20,65 @@ import (
 const (
 	serverUp   = "UP"
 	serverDown = "DOWN"
+	// serverStale is the status of the servers reused by a provider from a previous configuration.
+	serverStale = "STALE"
 )
 
 var singleton *HealthCheck
 var once sync.Once
 
-// BalancerHandler includes functionality for load-balancing management.
-type BalancerHandler interface {
-	ServeHTTP(w http.ResponseWriter, req *http.Request)
+// Balancer is the set of operations required to manage the list of servers in a load-balancer.
+type Balancer interface {
 	Servers() []*url.URL
 	RemoveServer(u *url.URL) error
 	UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error
+	ServerWeight(u *url.URL) (int, bool)
+}
+
+// BalancerHandler includes functionality for load-balancing management.
+type BalancerHandler interface {
+	ServeHTTP(w http.ResponseWriter, req *http.Request)
+	Balancer
+}
+
+// Balancers is a list of the balancers of a same service, e.g. built for several entry points,
+// which share the health check of the service: a server removed from the rotation is removed from all of them.
+type Balancers []Balancer
+
+// Servers returns the servers of the first balancer, as they all have the same servers.
+func (b Balancers) Servers() []*url.URL {
+	if len(b) == 0 {
+		return nil
+	}
+	return b[0].Servers()
+}
+
+// RemoveServer removes the server from all the balancers.
+func (b Balancers) RemoveServer(u *url.URL) error {
+	for _, balancer := range b {
+		if err := balancer.RemoveServer(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpsertServer adds the server to all the balancers.
+func (b Balancers) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	for _, balancer := range b {
+		if err := balancer.UpsertServer(u, options...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServerWeight returns the weight of the server in the first balancer, as they all have the same servers.
+func (b Balancers) ServerWeight(u *url.URL) (int, bool) {
+	if len(b) == 0 {
+		return 0, false
+	}
+	return b[0].ServerWeight(u)
 }
 
 // metricsRegistry is a local interface in the health check package, exposing only the required metrics
@@ -49,7 +97,7 @@ type Options struct {
 	Transport http.RoundTripper
 	Interval  time.Duration
 	Timeout   time.Duration
-	LB        BalancerHandler
+	LB        Balancer
 }
 
 func (opt Options) String() string {
@@ -60,7 +108,13 @@ func (opt Options) String() string {
 type BackendConfig struct {
 	Options
 	name         string
-	disabledURLs []*url.URL
+	disabledURLs []backendURL
+}
+
+// backendURL is a server removed from the rotation, with its weight, restored when it is healthy again.
+type backendURL struct {
+	url    *url.URL
+	weight int
 }
 
 func (b *BackendConfig) newRequest(serverURL *url.URL) (*http.Request, error) {
@@ -135,21 +189,21 @@ func (hc *HealthCheck) execute(ctx context.Context, backend *BackendConfig) {
 
 func (hc *HealthCheck) checkBackend(backend *BackendConfig) {
 	enabledURLs := backend.LB.Servers()
-	var newDisabledURLs []*url.URL
+	var newDisabledURLs []backendURL
 	// FIXME re enable metrics
-	for _, disableURL := range backend.disabledURLs {
+	for _, disabledURL := range backend.disabledURLs {
 		// FIXME serverUpMetricValue := float64(0)
-		if err := checkHealth(disableURL, backend); err == nil {
-			log.Warnf("Health check up: Returning to server list. Backend: %q URL: %q", backend.name, disableURL.String())
-			if err = backend.LB.UpsertServer(disableURL, roundrobin.Weight(1)); err != nil {
+		if err := checkHealth(disabledURL.url, backend); err == nil {
+			log.Warnf("Health check up: Returning to server list. Backend: %q URL: %q Weight: %d", backend.name, disabledURL.url.String(), disabledURL.weight)
+			if err = backend.LB.UpsertServer(disabledURL.url, roundrobin.Weight(disabledURL.weight)); err != nil {
 				log.Error(err)
 			}
 			// FIXME serverUpMetricValue = 1
 		} else {
-			log.Warnf("Health check still failing. Backend: %q URL: %q Reason: %s", backend.name, disableURL.String(), err)
-			newDisabledURLs = append(newDisabledURLs, disableURL)
+			log.Warnf("Health check still failing. Backend: %q URL: %q Reason: %s", backend.name, disabledURL.url.String(), err)
+			newDisabledURLs = append(newDisabledURLs, disabledURL)
 		}
-		// FIXME labelValues := []string{"backend", backend.name, "url", disableURL.String()}
+		// FIXME labelValues := []string{"backend", backend.name, "url", disabledURL.url.String()}
 		// FIXME hc.metrics.BackendServerUpGauge().With(labelValues...).Set(serverUpMetricValue)
 	}
 	backend.disabledURLs = newDisabledURLs
@@ -158,11 +212,16 @@ func (hc *HealthCheck) checkBackend(backend *BackendConfig) {
 	for _, enableURL := range enabledURLs {
 		// FIXME serverUpMetricValue := float64(1)
 		if err := checkHealth(enableURL, backend); err != nil {
-			log.Warnf("Health check failed: Remove from server list. Backend: %q URL: %q Reason: %s", backend.name, enableURL.String(), err)
+			weight, ok := backend.LB.ServerWeight(enableURL)
+			if !ok {
+				weight = 1
+			}
+
+			log.Warnf("Health check failed: Remove from server list. Backend: %q URL: %q Weight: %d Reason: %s", backend.name, enableURL.String(), weight, err)
 			if err := backend.LB.RemoveServer(enableURL); err != nil {
 				log.Error(err)
 			}
-			backend.disabledURLs = append(backend.disabledURLs, enableURL)
+			backend.disabledURLs = append(backend.disabledURLs, backendURL{url: enableURL, weight: weight})
 			// FIXME serverUpMetricValue = 0
 		}
 		// FIXME labelValues := []string{"backend", backend.name, "url", enableURL.String()}
@@ -253,6 +312,14 @@ func (lb *LbStatusUpdater) RemoveServer(u *url.URL) error {
 	return err
 }
 
+// MarkStale updates the status of the given server to "STALE",
+// until the health check removes or restores it.
+func (lb *LbStatusUpdater) MarkStale(u *url.URL) {
+	if lb.serviceInfo != nil {
+		lb.serviceInfo.UpdateStatus(u.String(), serverStale)
+	}
+}
+
 // UpsertServer adds the given server to the BalancerHandler,
 // and updates the status of the server to "UP".
 func (lb *LbStatusUpdater) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
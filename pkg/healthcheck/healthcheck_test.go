@@ -115,7 +115,7 @@ func TestSetBackendsConfiguration(t *testing.T) {
 			if test.startHealthy {
 				lb.servers = append(lb.servers, serverURL)
 			} else {
-				backend.disabledURLs = append(backend.disabledURLs, serverURL)
+				backend.disabledURLs = append(backend.disabledURLs, backendURL{url: serverURL, weight: 1})
 			}
 
 			collectingMetrics := testhelpers.NewCollectingHealthCheckMetrics()
@@ -397,6 +397,10 @@ func (lb *testLoadBalancer) Servers() []*url.URL {
 	return lb.servers
 }
 
+func (lb *testLoadBalancer) ServerWeight(u *url.URL) (int, bool) {
+	return 0, false
+}
+
 func (lb *testLoadBalancer) Options() []roundrobin.ServerOption {
 	return lb.options
 }
@@ -469,3 +473,50 @@ func TestLBStatusUpdater(t *testing.T) {
 		break
 	}
 }
+
+func TestCheckBackendKeepsWeight(t *testing.T) {
+	healthy := true
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if !healthy {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	serverURL := testhelpers.MustParseURL(ts.URL)
+
+	lb, err := roundrobin.New(http.NotFoundHandler())
+	require.NoError(t, err)
+	require.NoError(t, lb.UpsertServer(serverURL, roundrobin.Weight(3)))
+
+	backend := NewBackendConfig(Options{Path: "/health", Timeout: healthCheckTimeout, LB: lb}, "backendName")
+	check := newHealthCheck()
+
+	healthy = false
+	check.checkBackend(backend)
+	assert.Empty(t, lb.Servers())
+
+	healthy = true
+	check.checkBackend(backend)
+	require.Len(t, lb.Servers(), 1)
+
+	weight, ok := lb.ServerWeight(serverURL)
+	require.True(t, ok)
+	assert.Equal(t, 3, weight)
+}
+
+func TestBalancers(t *testing.T) {
+	lb1 := &testLoadBalancer{RWMutex: &sync.RWMutex{}}
+	lb2 := &testLoadBalancer{RWMutex: &sync.RWMutex{}}
+	balancers := Balancers{lb1, lb2}
+
+	serverURL := testhelpers.MustParseURL("http://foo.com")
+
+	require.NoError(t, balancers.UpsertServer(serverURL, roundrobin.Weight(1)))
+	assert.Equal(t, []*url.URL{serverURL}, balancers.Servers())
+	assert.Equal(t, []*url.URL{serverURL}, lb2.Servers())
+
+	require.NoError(t, balancers.RemoveServer(serverURL))
+	assert.Empty(t, balancers.Servers())
+	assert.Empty(t, lb2.Servers())
+}
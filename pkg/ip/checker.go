@@ -27,7 +27,7 @@ func NewChecker(trustedIPs []string) (*Checker, error) {
 		} else {
 			_, ipAddr, err := net.ParseCIDR(ipMask)
 			if err != nil {
-				return nil, fmt.Errorf("parsing CIDR trusted IPs %s: %v", ipAddr, err)
+				return nil, fmt.Errorf("parsing CIDR trusted IPs %s: %v", ipMask, err)
 			}
 			checker.authorizedIPsNet = append(checker.authorizedIPsNet, ipAddr)
 		}
@@ -89,8 +89,10 @@ func (ip *Checker) ContainsIP(addr net.IP) bool {
 	return false
 }
 
+// parseIP parses an IP address, which may be an IPv6 address enclosed in square brackets,
+// as found in the X-Forwarded-For header.
 func parseIP(addr string) (net.IP, error) {
-	userIP := net.ParseIP(addr)
+	userIP := net.ParseIP(strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]"))
 	if userIP == nil {
 		return nil, fmt.Errorf("can't parse IP from address %s", addr)
 	}
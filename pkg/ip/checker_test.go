@@ -27,6 +27,24 @@ func TestIsAuthorized(t *testing.T) {
 			remoteAddr: "1.2.3.1:123",
 			authorized: true,
 		},
+		{
+			desc:       "IPv6 remoteAddr not in range",
+			whiteList:  []string{"2001:db8::/32"},
+			remoteAddr: "[2001:db9::1]:123",
+			authorized: false,
+		},
+		{
+			desc:       "IPv6 remoteAddr in range",
+			whiteList:  []string{"2001:db8::/32"},
+			remoteAddr: "[2001:db8::1]:123",
+			authorized: true,
+		},
+		{
+			desc:       "IPv6 address in brackets without port",
+			whiteList:  []string{"2001:db8::/32"},
+			remoteAddr: "[2001:db8::1]",
+			authorized: true,
+		},
 	}
 
 	for _, test := range testCases {
@@ -72,21 +90,21 @@ func TestNew(t *testing.T) {
 				"fe80::/16",
 			},
 			expectedAuthorizedIPs: nil,
-			errMessage:            "parsing CIDR trusted IPs <nil>: invalid CIDR address: ",
+			errMessage:            "parsing CIDR trusted IPs : invalid CIDR address: ",
 		}, {
 			desc: "trusted IPs containing only an empty string",
 			trustedIPs: []string{
 				"",
 			},
 			expectedAuthorizedIPs: nil,
-			errMessage:            "parsing CIDR trusted IPs <nil>: invalid CIDR address: ",
+			errMessage:            "parsing CIDR trusted IPs : invalid CIDR address: ",
 		}, {
 			desc: "trusted IPs containing an invalid string",
 			trustedIPs: []string{
 				"foo",
 			},
 			expectedAuthorizedIPs: nil,
-			errMessage:            "parsing CIDR trusted IPs <nil>: invalid CIDR address: foo",
+			errMessage:            "parsing CIDR trusted IPs foo: invalid CIDR address: foo",
 		}, {
 			desc: "IPv4 & IPv6 trusted IPs",
 			trustedIPs: []string{
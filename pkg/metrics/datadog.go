@@ -32,6 +32,10 @@ const (
 	ddEntrypointOpenConnsName     = "entrypoint.connections.open"
 	ddOpenConnsName               = "backend.connections.open"
 	ddServerUpName                = "backend.server.up"
+	ddFailoverActiveName          = "backend.failover.active"
+	ddProviderLabelErrorsName     = "provider.label.errors.total"
+	ddProviderSkippedName         = "provider.skipped"
+	ddProviderSuppressedReloads   = "provider.suppressed.reloads.total"
 )
 
 // RegisterDatadog registers the metrics pusher if this didn't happen yet and creates a datadog Registry instance.
@@ -54,6 +58,10 @@ func RegisterDatadog(ctx context.Context, config *types.Datadog) Registry {
 		backendRetriesCounter:          datadogClient.NewCounter(ddRetriesTotalName, 1.0),
 		backendOpenConnsGauge:          datadogClient.NewGauge(ddOpenConnsName),
 		backendServerUpGauge:           datadogClient.NewGauge(ddServerUpName),
+		backendFailoverActiveGauge:     datadogClient.NewGauge(ddFailoverActiveName),
+		providerLabelErrorsCounter:     datadogClient.NewCounter(ddProviderLabelErrorsName, 1.0),
+		providerSkippedGauge:           datadogClient.NewGauge(ddProviderSkippedName),
+		providerSuppressedReloads:      datadogClient.NewCounter(ddProviderSuppressedReloads, 1.0),
 	}
 
 	return registry
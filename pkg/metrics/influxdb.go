@@ -38,6 +38,10 @@ const (
 	influxDBEntrypointOpenConnsName     = "traefik.entrypoint.connections.open"
 	influxDBOpenConnsName               = "traefik.backend.connections.open"
 	influxDBServerUpName                = "traefik.backend.server.up"
+	influxDBFailoverActiveName          = "traefik.backend.failover.active"
+	influxDBProviderLabelErrorsName     = "traefik.provider.label.errors.total"
+	influxDBProviderSkippedName         = "traefik.provider.skipped"
+	influxDBProviderSuppressedReloads   = "traefik.provider.suppressed.reloads.total"
 )
 
 const (
@@ -68,6 +72,10 @@ func RegisterInfluxDB(ctx context.Context, config *types.InfluxDB) Registry {
 		backendRetriesCounter:          influxDBClient.NewCounter(influxDBRetriesTotalName),
 		backendOpenConnsGauge:          influxDBClient.NewGauge(influxDBOpenConnsName),
 		backendServerUpGauge:           influxDBClient.NewGauge(influxDBServerUpName),
+		backendFailoverActiveGauge:     influxDBClient.NewGauge(influxDBFailoverActiveName),
+		providerLabelErrorsCounter:     influxDBClient.NewCounter(influxDBProviderLabelErrorsName),
+		providerSkippedGauge:           influxDBClient.NewGauge(influxDBProviderSkippedName),
+		providerSuppressedReloads:      influxDBClient.NewCounter(influxDBProviderSuppressedReloads),
 	}
 }
 
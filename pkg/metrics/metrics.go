@@ -27,6 +27,12 @@ type Registry interface {
 	BackendOpenConnsGauge() metrics.Gauge
 	BackendRetriesCounter() metrics.Counter
 	BackendServerUpGauge() metrics.Gauge
+	BackendFailoverActiveGauge() metrics.Gauge
+
+	// provider metrics
+	ProviderLabelErrorsCounter() metrics.Counter
+	ProviderSkippedGauge() metrics.Gauge
+	ProviderSuppressedReloadsCounter() metrics.Counter
 }
 
 // NewVoidRegistry is a noop implementation of metrics.Registry.
@@ -51,6 +57,10 @@ func NewMultiRegistry(registries []Registry) Registry {
 	var backendOpenConnsGauge []metrics.Gauge
 	var backendRetriesCounter []metrics.Counter
 	var backendServerUpGauge []metrics.Gauge
+	var backendFailoverActiveGauge []metrics.Gauge
+	var providerLabelErrorsCounter []metrics.Counter
+	var providerSkippedGauge []metrics.Gauge
+	var providerSuppressedReloads []metrics.Counter
 
 	for _, r := range registries {
 		if r.ConfigReloadsCounter() != nil {
@@ -89,6 +99,18 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.BackendServerUpGauge() != nil {
 			backendServerUpGauge = append(backendServerUpGauge, r.BackendServerUpGauge())
 		}
+		if r.BackendFailoverActiveGauge() != nil {
+			backendFailoverActiveGauge = append(backendFailoverActiveGauge, r.BackendFailoverActiveGauge())
+		}
+		if r.ProviderLabelErrorsCounter() != nil {
+			providerLabelErrorsCounter = append(providerLabelErrorsCounter, r.ProviderLabelErrorsCounter())
+		}
+		if r.ProviderSkippedGauge() != nil {
+			providerSkippedGauge = append(providerSkippedGauge, r.ProviderSkippedGauge())
+		}
+		if r.ProviderSuppressedReloadsCounter() != nil {
+			providerSuppressedReloads = append(providerSuppressedReloads, r.ProviderSuppressedReloadsCounter())
+		}
 	}
 
 	return &standardRegistry{
@@ -105,6 +127,10 @@ func NewMultiRegistry(registries []Registry) Registry {
 		backendOpenConnsGauge:          multi.NewGauge(backendOpenConnsGauge...),
 		backendRetriesCounter:          multi.NewCounter(backendRetriesCounter...),
 		backendServerUpGauge:           multi.NewGauge(backendServerUpGauge...),
+		backendFailoverActiveGauge:     multi.NewGauge(backendFailoverActiveGauge...),
+		providerLabelErrorsCounter:     multi.NewCounter(providerLabelErrorsCounter...),
+		providerSkippedGauge:           multi.NewGauge(providerSkippedGauge...),
+		providerSuppressedReloads:      multi.NewCounter(providerSuppressedReloads...),
 	}
 }
 
@@ -122,6 +148,10 @@ type standardRegistry struct {
 	backendOpenConnsGauge          metrics.Gauge
 	backendRetriesCounter          metrics.Counter
 	backendServerUpGauge           metrics.Gauge
+	backendFailoverActiveGauge     metrics.Gauge
+	providerLabelErrorsCounter     metrics.Counter
+	providerSkippedGauge           metrics.Gauge
+	providerSuppressedReloads      metrics.Counter
 }
 
 func (r *standardRegistry) IsEnabled() bool {
@@ -175,3 +205,19 @@ func (r *standardRegistry) BackendRetriesCounter() metrics.Counter {
 func (r *standardRegistry) BackendServerUpGauge() metrics.Gauge {
 	return r.backendServerUpGauge
 }
+
+func (r *standardRegistry) BackendFailoverActiveGauge() metrics.Gauge {
+	return r.backendFailoverActiveGauge
+}
+
+func (r *standardRegistry) ProviderLabelErrorsCounter() metrics.Counter {
+	return r.providerLabelErrorsCounter
+}
+
+func (r *standardRegistry) ProviderSkippedGauge() metrics.Gauge {
+	return r.providerSkippedGauge
+}
+
+func (r *standardRegistry) ProviderSuppressedReloadsCounter() metrics.Counter {
+	return r.providerSuppressedReloads
+}
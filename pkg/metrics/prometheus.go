@@ -37,12 +37,19 @@ const (
 	// backend level.
 
 	// MetricBackendPrefix prefix of all backend metric names
-	MetricBackendPrefix     = MetricNamePrefix + "backend_"
-	backendReqsTotalName    = MetricBackendPrefix + "requests_total"
-	backendReqDurationName  = MetricBackendPrefix + "request_duration_seconds"
-	backendOpenConnsName    = MetricBackendPrefix + "open_connections"
-	backendRetriesTotalName = MetricBackendPrefix + "retries_total"
-	backendServerUpName     = MetricBackendPrefix + "server_up"
+	MetricBackendPrefix       = MetricNamePrefix + "backend_"
+	backendReqsTotalName      = MetricBackendPrefix + "requests_total"
+	backendReqDurationName    = MetricBackendPrefix + "request_duration_seconds"
+	backendOpenConnsName      = MetricBackendPrefix + "open_connections"
+	backendRetriesTotalName   = MetricBackendPrefix + "retries_total"
+	backendServerUpName       = MetricBackendPrefix + "server_up"
+	backendFailoverActiveName = MetricBackendPrefix + "failover_active"
+
+	// provider level
+	metricProviderPrefix     = MetricNamePrefix + "provider_"
+	providerLabelErrorsTotal = metricProviderPrefix + "label_errors_total"
+	providerSkippedName      = metricProviderPrefix + "skipped"
+	providerSuppressedTotal  = metricProviderPrefix + "suppressed_reloads_total"
 )
 
 // promState holds all metric state internally and acts as the only Collector we register for Prometheus.
@@ -141,6 +148,23 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		Name: backendServerUpName,
 		Help: "Backend server is up, described by gauge value of 0 or 1.",
 	}, []string{"backend", "url"})
+	backendFailover := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: backendFailoverActiveName,
+		Help: "Service of a failover backend receiving the requests, described by gauge value of 0 or 1.",
+	}, []string{"backend", "service"})
+
+	providerLabelErrors := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: providerLabelErrorsTotal,
+		Help: "How many invalid elements were dropped from the labels, partitioned by provider.",
+	}, []string{"provider"})
+	providerSkipped := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: providerSkippedName,
+		Help: "How many elements were skipped during the last configuration build, partitioned by provider and reason.",
+	}, []string{"provider", "reason"})
+	providerSuppressed := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: providerSuppressedTotal,
+		Help: "How many reloads were suppressed because the configuration sources did not change, partitioned by provider.",
+	}, []string{"provider"})
 
 	promState.describers = []func(chan<- *stdprometheus.Desc){
 		configReloads.cv.Describe,
@@ -155,6 +179,10 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		backendOpenConns.gv.Describe,
 		backendRetries.cv.Describe,
 		backendServerUp.gv.Describe,
+		backendFailover.gv.Describe,
+		providerLabelErrors.cv.Describe,
+		providerSkipped.gv.Describe,
+		providerSuppressed.cv.Describe,
 	}
 
 	return &standardRegistry{
@@ -171,6 +199,10 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		backendOpenConnsGauge:          backendOpenConns,
 		backendRetriesCounter:          backendRetries,
 		backendServerUpGauge:           backendServerUp,
+		backendFailoverActiveGauge:     backendFailover,
+		providerLabelErrorsCounter:     providerLabelErrors,
+		providerSkippedGauge:           providerSkipped,
+		providerSuppressedReloads:      providerSuppressed,
 	}
 }
 
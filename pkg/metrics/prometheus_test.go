@@ -147,6 +147,22 @@ func TestPrometheus(t *testing.T) {
 		BackendServerUpGauge().
 		With("backend", "backend1", "url", "http://127.0.0.10:80").
 		Set(1)
+	prometheusRegistry.
+		BackendFailoverActiveGauge().
+		With("backend", "backend1", "service", "backend2").
+		Set(1)
+	prometheusRegistry.
+		ProviderLabelErrorsCounter().
+		With("provider", "docker").
+		Add(1)
+	prometheusRegistry.
+		ProviderSkippedGauge().
+		With("provider", "marathon", "reason", "disabled").
+		Set(2)
+	prometheusRegistry.
+		ProviderSuppressedReloadsCounter().
+		With("provider", "file").
+		Add(1)
 
 	delayForTrackingCompletion()
 
@@ -246,6 +262,36 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildGaugeAssert(t, backendServerUpName, 1),
 		},
+		{
+			name: backendFailoverActiveName,
+			labels: map[string]string{
+				"backend": "backend1",
+				"service": "backend2",
+			},
+			assert: buildGaugeAssert(t, backendFailoverActiveName, 1),
+		},
+		{
+			name: providerLabelErrorsTotal,
+			labels: map[string]string{
+				"provider": "docker",
+			},
+			assert: buildCounterAssert(t, providerLabelErrorsTotal, 1),
+		},
+		{
+			name: providerSkippedName,
+			labels: map[string]string{
+				"provider": "marathon",
+				"reason":   "disabled",
+			},
+			assert: buildGaugeAssert(t, providerSkippedName, 2),
+		},
+		{
+			name: providerSuppressedTotal,
+			labels: map[string]string{
+				"provider": "file",
+			},
+			assert: buildCounterAssert(t, providerSuppressedTotal, 1),
+		},
 	}
 
 	for _, test := range tests {
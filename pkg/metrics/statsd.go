@@ -31,6 +31,10 @@ const (
 	statsdEntrypointOpenConnsName     = "entrypoint.connections.open"
 	statsdOpenConnsName               = "backend.connections.open"
 	statsdServerUpName                = "backend.server.up"
+	statsdFailoverActiveName          = "backend.failover.active"
+	statsdProviderLabelErrorsName     = "provider.label.errors.total"
+	statsdProviderSkippedName         = "provider.skipped"
+	statsdProviderSuppressedReloads   = "provider.suppressed.reloads.total"
 )
 
 // RegisterStatsd registers the metrics pusher if this didn't happen yet and creates a statsd Registry instance.
@@ -53,6 +57,10 @@ func RegisterStatsd(ctx context.Context, config *types.Statsd) Registry {
 		backendRetriesCounter:          statsdClient.NewCounter(statsdRetriesTotalName, 1.0),
 		backendOpenConnsGauge:          statsdClient.NewGauge(statsdOpenConnsName),
 		backendServerUpGauge:           statsdClient.NewGauge(statsdServerUpName),
+		backendFailoverActiveGauge:     statsdClient.NewGauge(statsdFailoverActiveName),
+		providerLabelErrorsCounter:     statsdClient.NewCounter(statsdProviderLabelErrorsName, 1.0),
+		providerSkippedGauge:           statsdClient.NewGauge(statsdProviderSkippedName),
+		providerSuppressedReloads:      statsdClient.NewCounter(statsdProviderSuppressedReloads, 1.0),
 	}
 }
 
@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/middlewares"
@@ -28,7 +29,7 @@ type forwardAuth struct {
 	authResponseHeaders []string
 	next                http.Handler
 	name                string
-	tlsConfig           *tls.Config
+	client              http.Client
 	trustForwardHeader  bool
 }
 
@@ -42,15 +43,22 @@ func NewForward(ctx context.Context, next http.Handler, config config.ForwardAut
 		next:                next,
 		name:                name,
 		trustForwardHeader:  config.TrustForwardHeader,
+		client: http.Client{
+			// Ensure our request client does not follow redirects
+			CheckRedirect: func(r *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
 	}
 
+	// The transport is created once, so that the connections to the authentication server are reused.
 	if config.TLS != nil {
 		tlsConfig, err := config.TLS.CreateTLSConfig()
 		if err != nil {
 			return nil, err
 		}
 
-		fa.tlsConfig = tlsConfig
+		fa.client.Transport = newTransport(tlsConfig)
 	}
 
 	return fa, nil
@@ -63,54 +71,44 @@ func (fa *forwardAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
 func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	logger := middlewares.GetLogger(req.Context(), fa.name, forwardedTypeName)
 
-	// Ensure our request client does not follow redirects
-	httpClient := http.Client{
-		CheckRedirect: func(r *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
-	}
-
-	if fa.tlsConfig != nil {
-		httpClient.Transport = &http.Transport{
-			TLSClientConfig: fa.tlsConfig,
-		}
-	}
-
+	// The request to the authentication server never has a body:
+	// the incoming request is described by its headers, and by the X-Forwarded-* headers.
 	forwardReq, err := http.NewRequest(http.MethodGet, fa.address, nil)
-	tracing.LogRequest(tracing.GetSpan(req), forwardReq)
 	if err != nil {
 		logMessage := fmt.Sprintf("Error calling %s. Cause %s", fa.address, err)
 		logger.Debug(logMessage)
-		tracing.SetErrorWithEvent(req, logMessage)
+		tracing.SetErrorWithEvent(req, "%s", logMessage)
 
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+	forwardReq = forwardReq.WithContext(req.Context())
+	tracing.LogRequest(tracing.GetSpan(req), forwardReq)
 
 	writeHeader(req, forwardReq, fa.trustForwardHeader)
 
 	tracing.InjectRequestHeaders(forwardReq)
 
-	forwardResponse, forwardErr := httpClient.Do(forwardReq)
+	forwardResponse, forwardErr := fa.client.Do(forwardReq)
 	if forwardErr != nil {
 		logMessage := fmt.Sprintf("Error calling %s. Cause: %s", fa.address, forwardErr)
 		logger.Debug(logMessage)
-		tracing.SetErrorWithEvent(req, logMessage)
+		tracing.SetErrorWithEvent(req, "%s", logMessage)
 
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+	defer forwardResponse.Body.Close()
 
 	body, readError := ioutil.ReadAll(forwardResponse.Body)
 	if readError != nil {
 		logMessage := fmt.Sprintf("Error reading body %s. Cause: %s", fa.address, readError)
 		logger.Debug(logMessage)
-		tracing.SetErrorWithEvent(req, logMessage)
+		tracing.SetErrorWithEvent(req, "%s", logMessage)
 
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	defer forwardResponse.Body.Close()
 
 	// Pass the forward response's body and selected headers if it
 	// didn't return a response within the range of [200, 300).
@@ -127,7 +125,7 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			if err != http.ErrNoLocation {
 				logMessage := fmt.Sprintf("Error reading response location header %s. Cause: %s", fa.address, err)
 				logger.Debug(logMessage)
-				tracing.SetErrorWithEvent(req, logMessage)
+				tracing.SetErrorWithEvent(req, "%s", logMessage)
 
 				rw.WriteHeader(http.StatusInternalServerError)
 				return
@@ -158,9 +156,27 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	fa.next.ServeHTTP(rw, req)
 }
 
+// newTransport creates the transport of the requests to the authentication server, with the given TLS configuration.
+func newTransport(tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       tlsConfig,
+	}
+}
+
 func writeHeader(req *http.Request, forwardReq *http.Request, trustForwardHeader bool) {
 	utils.CopyHeaders(forwardReq.Header, req.Header)
 	utils.RemoveHeaders(forwardReq.Header, forward.HopHeaders...)
+	// The body of the incoming request is not forwarded.
+	utils.RemoveHeaders(forwardReq.Header, forward.ContentLength)
 
 	if clientIP, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
 		if trustForwardHeader {
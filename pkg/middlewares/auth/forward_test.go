@@ -2,10 +2,12 @@ package auth
 
 import (
 	"context"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/containous/traefik/pkg/config"
@@ -234,6 +236,116 @@ func TestForwardAuthFailResponseHeaders(t *testing.T) {
 	assert.Equal(t, "Forbidden\n", string(body))
 }
 
+func TestForwardAuthRequest(t *testing.T) {
+	authTs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		assert.Empty(t, body)
+		assert.Equal(t, int64(0), r.ContentLength)
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, http.MethodPost, r.Header.Get(xForwardedMethod))
+		assert.Equal(t, "/foo/bar?q=1", r.Header.Get(xForwardedURI))
+		assert.Equal(t, "http", r.Header.Get(forward.XForwardedProto))
+		assert.Equal(t, "example.com", r.Header.Get(forward.XForwardedHost))
+		assert.Equal(t, "127.0.0.1", r.Header.Get(forward.XForwardedFor))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		w.Header().Set("X-Auth-User", "user@example.com")
+	}))
+	defer authTs.Close()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, "payload", string(body))
+		assert.Equal(t, "user@example.com", r.Header.Get("X-Auth-User"))
+		fmt.Fprintln(w, "traefik")
+	})
+
+	middleware, err := NewForward(context.Background(), next, config.ForwardAuth{
+		Address:             authTs.URL,
+		AuthResponseHeaders: []string{"X-Auth-User"},
+	}, "authTest")
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(middleware)
+	defer ts.Close()
+
+	req := testhelpers.MustNewRequest(http.MethodPost, ts.URL+"/foo/bar?q=1", strings.NewReader("payload"))
+	req.Host = "example.com"
+	req.Header.Set("Authorization", "Bearer token")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	err = res.Body.Close()
+	require.NoError(t, err)
+
+	assert.Equal(t, "traefik\n", string(body))
+}
+
+func TestForwardAuthTLS(t *testing.T) {
+	authTs := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "Success")
+	}))
+	defer authTs.Close()
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: authTs.Certificate().Raw}))
+
+	testCases := []struct {
+		desc           string
+		tls            *config.ClientTLS
+		expectedStatus int
+	}{
+		{
+			desc:           "CA of the authentication server",
+			tls:            &config.ClientTLS{CA: caPEM},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "insecure skip verify",
+			tls:            &config.ClientTLS{InsecureSkipVerify: true},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "unknown authority",
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, "traefik")
+			})
+
+			middleware, err := NewForward(context.Background(), next, config.ForwardAuth{
+				Address: authTs.URL,
+				TLS:     test.tls,
+			}, "authTest")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			middleware.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo", nil))
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestNewForward_invalidTLS(t *testing.T) {
+	_, err := NewForward(context.Background(), http.NotFoundHandler(), config.ForwardAuth{
+		Address: "https://auth",
+		TLS:     &config.ClientTLS{Cert: "foo"},
+	}, "authTest")
+	require.Error(t, err)
+}
+
 func Test_writeHeader(t *testing.T) {
 	testCases := []struct {
 		name                      string
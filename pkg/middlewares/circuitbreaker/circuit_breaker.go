@@ -3,6 +3,7 @@ package circuitbreaker
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/log"
@@ -23,13 +24,20 @@ type circuitBreaker struct {
 
 // New creates a new circuit breaker middleware.
 func New(ctx context.Context, next http.Handler, confCircuitBreaker config.CircuitBreaker, name string) (http.Handler, error) {
+	return newCircuitBreaker(ctx, next, confCircuitBreaker, name)
+}
+
+// newCircuitBreaker creates a circuit breaker middleware, with additional options for the oxy circuit breaker, e.g. its clock.
+func newCircuitBreaker(ctx context.Context, next http.Handler, confCircuitBreaker config.CircuitBreaker, name string, options ...cbreaker.CircuitBreakerOption) (http.Handler, error) {
 	expression := confCircuitBreaker.Expression
 
 	logger := middlewares.GetLogger(ctx, name, typeName)
 	logger.Debug("Creating middleware")
-	logger.Debug("Setting up with expression: %s", expression)
+	logger.Debugf("Setting up with expression: %s", expression)
 
-	oxyCircuitBreaker, err := cbreaker.New(next, expression, createCircuitBreakerOptions(expression))
+	options = append(createCircuitBreakerOptions(confCircuitBreaker), options...)
+
+	oxyCircuitBreaker, err := cbreaker.New(next, expression, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -39,16 +47,38 @@ func New(ctx context.Context, next http.Handler, confCircuitBreaker config.Circu
 	}, nil
 }
 
-// NewCircuitBreakerOptions returns a new CircuitBreakerOption
-func createCircuitBreakerOptions(expression string) cbreaker.CircuitBreakerOption {
-	return cbreaker.Fallback(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
-		tracing.SetErrorWithEvent(req, "blocked by circuit-breaker (%q)", expression)
-		rw.WriteHeader(http.StatusServiceUnavailable)
+// createCircuitBreakerOptions returns the options of the oxy circuit breaker,
+// falling back on the defaults for the zero values of the configuration.
+func createCircuitBreakerOptions(confCircuitBreaker config.CircuitBreaker) []cbreaker.CircuitBreakerOption {
+	expression := confCircuitBreaker.Expression
+
+	responseCode := confCircuitBreaker.ResponseCode
+	if responseCode == 0 {
+		responseCode = http.StatusServiceUnavailable
+	}
+
+	options := []cbreaker.CircuitBreakerOption{
+		cbreaker.Fallback(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			tracing.SetErrorWithEvent(req, "blocked by circuit-breaker (%q)", expression)
+			rw.WriteHeader(responseCode)
+
+			if _, err := rw.Write([]byte(http.StatusText(responseCode))); err != nil {
+				log.FromContext(req.Context()).Error(err)
+			}
+		})),
+	}
+
+	if confCircuitBreaker.CheckPeriod > 0 {
+		options = append(options, cbreaker.CheckPeriod(time.Duration(confCircuitBreaker.CheckPeriod)))
+	}
+	if confCircuitBreaker.FallbackDuration > 0 {
+		options = append(options, cbreaker.FallbackDuration(time.Duration(confCircuitBreaker.FallbackDuration)))
+	}
+	if confCircuitBreaker.RecoveryDuration > 0 {
+		options = append(options, cbreaker.RecoveryDuration(time.Duration(confCircuitBreaker.RecoveryDuration)))
+	}
 
-		if _, err := rw.Write([]byte(http.StatusText(http.StatusServiceUnavailable))); err != nil {
-			log.FromContext(req.Context()).Error(err)
-		}
-	}))
+	return options
 }
 
 func (c *circuitBreaker) GetTracingInformation() (string, ext.SpanKindEnum) {
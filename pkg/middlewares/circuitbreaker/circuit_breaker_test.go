@@ -0,0 +1,168 @@
+package circuitbreaker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/types"
+	"github.com/mailgun/timetools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/cbreaker"
+)
+
+func TestNew_invalidExpression(t *testing.T) {
+	_, err := New(context.Background(), http.NotFoundHandler(), config.CircuitBreaker{Expression: "foobar"}, "cb")
+	require.Error(t, err)
+}
+
+func TestCircuitBreaker_transitions(t *testing.T) {
+	testCases := []struct {
+		desc                 string
+		responseCode         int
+		expectedFallbackCode int
+	}{
+		{
+			desc:                 "default fallback response",
+			expectedFallbackCode: http.StatusServiceUnavailable,
+		},
+		{
+			desc:                 "custom fallback response",
+			responseCode:         http.StatusTooManyRequests,
+			expectedFallbackCode: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			clock := &timetools.FreezedTime{CurrentTime: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+			serverCode := http.StatusOK
+			var served int
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				served++
+				rw.WriteHeader(serverCode)
+			})
+
+			conf := config.CircuitBreaker{
+				Expression:       "ResponseCodeRatio(500, 600, 0, 600) > 0.5",
+				CheckPeriod:      types.Duration(time.Second),
+				FallbackDuration: types.Duration(10 * time.Second),
+				RecoveryDuration: types.Duration(10 * time.Second),
+				ResponseCode:     test.responseCode,
+			}
+
+			handler, err := newCircuitBreaker(context.Background(), next, conf, "cb", cbreaker.Clock(clock))
+			require.NoError(t, err)
+
+			send := func() int {
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo", nil))
+				return recorder.Code
+			}
+
+			// Closed: the requests reach the service, whose errors are collected.
+			assert.Equal(t, http.StatusOK, send())
+
+			serverCode = http.StatusInternalServerError
+			for i := 0; i < 5; i++ {
+				assert.Equal(t, http.StatusInternalServerError, send())
+			}
+
+			// The expression is evaluated once the check period is elapsed, and trips the circuit breaker.
+			clock.Sleep(2 * time.Second)
+			assert.Equal(t, http.StatusInternalServerError, send())
+
+			// Open: the fallback answers, without reaching the service.
+			served = 0
+			for i := 0; i < 5; i++ {
+				assert.Equal(t, test.expectedFallbackCode, send())
+			}
+			assert.Equal(t, 0, served)
+
+			// Half-open: once the fallback duration is elapsed, a part of the requests reach the service again.
+			serverCode = http.StatusOK
+			clock.Sleep(10 * time.Second)
+			assert.Equal(t, test.expectedFallbackCode, send())
+
+			clock.Sleep(5 * time.Second)
+
+			var fallbacks int
+			for i := 0; i < 20; i++ {
+				if send() == test.expectedFallbackCode {
+					fallbacks++
+				}
+			}
+			assert.NotZero(t, fallbacks)
+			assert.NotZero(t, served)
+			assert.True(t, fallbacks+served == 20)
+
+			// Closed: once the recovery duration is elapsed, all the requests reach the service.
+			clock.Sleep(6 * time.Second)
+
+			served = 0
+			for i := 0; i < 5; i++ {
+				assert.Equal(t, http.StatusOK, send())
+			}
+			assert.Equal(t, 5, served)
+		})
+	}
+}
+
+func TestCircuitBreaker_recoveryFailure(t *testing.T) {
+	clock := &timetools.FreezedTime{CurrentTime: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	serverCode := http.StatusInternalServerError
+	var served int
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		served++
+		rw.WriteHeader(serverCode)
+	})
+
+	conf := config.CircuitBreaker{
+		Expression:       "ResponseCodeRatio(500, 600, 0, 600) > 0.5",
+		CheckPeriod:      types.Duration(time.Second),
+		FallbackDuration: types.Duration(10 * time.Second),
+		RecoveryDuration: types.Duration(10 * time.Second),
+	}
+
+	handler, err := newCircuitBreaker(context.Background(), next, conf, "cb", cbreaker.Clock(clock))
+	require.NoError(t, err)
+
+	send := func() int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo", nil))
+		return recorder.Code
+	}
+
+	// The first request is checked right away, and trips the circuit breaker.
+	assert.Equal(t, http.StatusInternalServerError, send())
+	assert.Equal(t, http.StatusServiceUnavailable, send())
+
+	// Half-open: a request reaches the service, which still fails.
+	clock.Sleep(10 * time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, send())
+
+	clock.Sleep(5 * time.Second)
+	for i := 0; i < 20 && served < 2; i++ {
+		send()
+	}
+	require.Equal(t, 2, served)
+
+	// Open: the circuit breaker is tripped again, and the fallback answers during a new fallback duration.
+	served = 0
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusServiceUnavailable, send())
+	}
+
+	clock.Sleep(9 * time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, send())
+	assert.Equal(t, 0, served)
+}
@@ -1,6 +1,7 @@
 package forwardedheaders
 
 import (
+	"context"
 	"net"
 	"net/http"
 	"os"
@@ -20,6 +21,10 @@ const (
 	upgrade          = "Upgrade"
 )
 
+type key string
+
+const clientIPKey key = "ClientIP"
+
 var xHeaders = []string{
 	xForwardedProto,
 	xForwardedFor,
@@ -35,13 +40,15 @@ var xHeaders = []string{
 type XForwarded struct {
 	insecure   bool
 	trustedIps []string
+	depth      int
 	ipChecker  *ip.Checker
 	next       http.Handler
 	hostname   string
 }
 
 // NewXForwarded creates a new XForwarded.
-func NewXForwarded(insecure bool, trustedIps []string, next http.Handler) (*XForwarded, error) {
+// When depth is positive, the IP at this depth of the X-Forwarded-For header is the client IP of the request, see ClientIP.
+func NewXForwarded(insecure bool, trustedIps []string, depth int, next http.Handler) (*XForwarded, error) {
 	var ipChecker *ip.Checker
 	if len(trustedIps) > 0 {
 		var err error
@@ -59,6 +66,7 @@ func NewXForwarded(insecure bool, trustedIps []string, next http.Handler) (*XFor
 	return &XForwarded{
 		insecure:   insecure,
 		trustedIps: trustedIps,
+		depth:      depth,
 		ipChecker:  ipChecker,
 		next:       next,
 		hostname:   hostname,
@@ -161,7 +169,28 @@ func (x *XForwarded) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if x.depth > 0 {
+		// The header has been removed above unless it is trusted.
+		clientIP := (&ip.DepthStrategy{Depth: x.depth}).GetIP(r)
+		r = r.WithContext(context.WithValue(r.Context(), clientIPKey, clientIP))
+	}
+
 	x.rewrite(r)
 
 	x.next.ServeHTTP(w, r)
 }
+
+// ClientIP returns the IP of the client of the request:
+// the IP at the depth of the X-Forwarded-For header configured on the entry point, if any, or the remote address otherwise.
+// It returns an empty string if the header holds less IPs than the depth.
+func ClientIP(req *http.Request) string {
+	if clientIP, ok := req.Context().Value(clientIPKey).(string); ok {
+		return removeIPv6Zone(clientIP)
+	}
+
+	clientIP, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		clientIP = req.RemoteAddr
+	}
+	return removeIPv6Zone(clientIP)
+}
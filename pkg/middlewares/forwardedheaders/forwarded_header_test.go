@@ -219,7 +219,7 @@ func TestServeHTTP(t *testing.T) {
 				req.Header.Set(k, v)
 			}
 
-			m, err := NewXForwarded(test.insecure, test.trustedIps,
+			m, err := NewXForwarded(test.insecure, test.trustedIps, 0,
 				http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}))
 			require.NoError(t, err)
 
@@ -235,3 +235,76 @@ func TestServeHTTP(t *testing.T) {
 		})
 	}
 }
+
+func TestClientIP(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		insecure      bool
+		trustedIps    []string
+		depth         int
+		remoteAddr    string
+		xForwardedFor string
+		expected      string
+	}{
+		{
+			desc:          "remote address without depth",
+			insecure:      true,
+			remoteAddr:    "10.0.1.0:80",
+			xForwardedFor: "10.0.2.0",
+			expected:      "10.0.1.0",
+		},
+		{
+			desc:       "IPv6 remote address with zone",
+			remoteAddr: "[fe80::d806:a55d:eb1b:49cc%eth0]:64692",
+			expected:   "fe80::d806:a55d:eb1b:49cc",
+		},
+		{
+			desc:          "depth from a trusted IP",
+			trustedIps:    []string{"10.0.1.0"},
+			depth:         2,
+			remoteAddr:    "10.0.1.0:80",
+			xForwardedFor: "10.0.2.0, 10.0.3.0",
+			expected:      "10.0.2.0",
+		},
+		{
+			desc:          "depth from an untrusted IP",
+			trustedIps:    []string{"10.0.1.0"},
+			depth:         1,
+			remoteAddr:    "10.0.4.0:80",
+			xForwardedFor: "10.0.2.0",
+			expected:      "",
+		},
+		{
+			desc:          "depth larger than the header",
+			insecure:      true,
+			depth:         3,
+			remoteAddr:    "10.0.1.0:80",
+			xForwardedFor: "10.0.2.0, 10.0.3.0",
+			expected:      "",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest(http.MethodGet, "", nil)
+			require.NoError(t, err)
+
+			req.RemoteAddr = test.remoteAddr
+			if test.xForwardedFor != "" {
+				req.Header.Set(xForwardedFor, test.xForwardedFor)
+			}
+
+			var clientIP string
+			m, err := NewXForwarded(test.insecure, test.trustedIps, test.depth,
+				http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) { clientIP = ClientIP(r) }))
+			require.NoError(t, err)
+
+			m.ServeHTTP(nil, req)
+
+			assert.Equal(t, test.expected, clientIP)
+		})
+	}
+}
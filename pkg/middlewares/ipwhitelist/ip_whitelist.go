@@ -65,7 +65,7 @@ func (wl *ipWhiteLister) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	if err != nil {
 		logMessage := fmt.Sprintf("rejecting request %+v: %v", req, err)
 		logger.Debug(logMessage)
-		tracing.SetErrorWithEvent(req, logMessage)
+		tracing.SetErrorWithEvent(req, "%s", logMessage)
 		reject(logger, rw)
 		return
 	}
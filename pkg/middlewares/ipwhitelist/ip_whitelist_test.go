@@ -30,6 +30,22 @@ func TestNewIPWhiteLister(t *testing.T) {
 				SourceRange: []string{"10.10.10.10"},
 			},
 		},
+		{
+			desc: "valid IPv6 range",
+			whiteList: config.IPWhiteList{
+				SourceRange: []string{"2001:db8::/32"},
+			},
+		},
+		{
+			desc: "invalid excluded IP",
+			whiteList: config.IPWhiteList{
+				SourceRange: []string{"10.10.10.10"},
+				IPStrategy: &config.IPStrategy{
+					ExcludedIPs: []string{"foo"},
+				},
+			},
+			expectedError: true,
+		},
 	}
 
 	for _, test := range testCases {
@@ -52,10 +68,11 @@ func TestNewIPWhiteLister(t *testing.T) {
 
 func TestIPWhiteLister_ServeHTTP(t *testing.T) {
 	testCases := []struct {
-		desc       string
-		whiteList  config.IPWhiteList
-		remoteAddr string
-		expected   int
+		desc          string
+		whiteList     config.IPWhiteList
+		remoteAddr    string
+		xForwardedFor string
+		expected      int
 	}{
 		{
 			desc: "authorized with remote address",
@@ -73,6 +90,94 @@ func TestIPWhiteLister_ServeHTTP(t *testing.T) {
 			remoteAddr: "20.20.20.21:1234",
 			expected:   403,
 		},
+		{
+			desc: "authorized with IPv6 remote address",
+			whiteList: config.IPWhiteList{
+				SourceRange: []string{"2001:db8::/32"},
+			},
+			remoteAddr: "[2001:db8::1]:1234",
+			expected:   200,
+		},
+		{
+			desc: "non authorized with IPv6 remote address",
+			whiteList: config.IPWhiteList{
+				SourceRange: []string{"2001:db8::/32"},
+			},
+			remoteAddr: "[2001:db9::1]:1234",
+			expected:   403,
+		},
+		{
+			// The client 20.20.20.20 goes through the proxies 10.0.0.1 and 10.0.0.2:
+			// the first one adds the client to X-Forwarded-For, the second one adds the first one.
+			desc: "authorized through two trusted proxies with depth",
+			whiteList: config.IPWhiteList{
+				SourceRange: []string{"20.20.20.0/24"},
+				IPStrategy:  &config.IPStrategy{Depth: 2},
+			},
+			remoteAddr:    "10.0.0.2:1234",
+			xForwardedFor: "20.20.20.20, 10.0.0.1",
+			expected:      200,
+		},
+		{
+			desc: "non authorized through two trusted proxies with depth",
+			whiteList: config.IPWhiteList{
+				SourceRange: []string{"20.20.20.0/24"},
+				IPStrategy:  &config.IPStrategy{Depth: 2},
+			},
+			remoteAddr:    "10.0.0.2:1234",
+			xForwardedFor: "30.30.30.30, 10.0.0.1",
+			expected:      403,
+		},
+		{
+			desc: "non authorized with a spoofed X-Forwarded-For through two trusted proxies with depth",
+			whiteList: config.IPWhiteList{
+				SourceRange: []string{"20.20.20.0/24"},
+				IPStrategy:  &config.IPStrategy{Depth: 2},
+			},
+			remoteAddr:    "10.0.0.2:1234",
+			xForwardedFor: "20.20.20.20, 30.30.30.30, 10.0.0.1",
+			expected:      403,
+		},
+		{
+			desc: "non authorized with depth greater than the number of proxies",
+			whiteList: config.IPWhiteList{
+				SourceRange: []string{"20.20.20.0/24"},
+				IPStrategy:  &config.IPStrategy{Depth: 2},
+			},
+			remoteAddr:    "20.20.20.20:1234",
+			xForwardedFor: "20.20.20.20",
+			expected:      403,
+		},
+		{
+			desc: "authorized with IPv6 through two trusted proxies with depth",
+			whiteList: config.IPWhiteList{
+				SourceRange: []string{"2001:db8::/32"},
+				IPStrategy:  &config.IPStrategy{Depth: 2},
+			},
+			remoteAddr:    "[fd00::2]:1234",
+			xForwardedFor: "2001:db8::1, fd00::1",
+			expected:      200,
+		},
+		{
+			desc: "authorized with excluded IPs",
+			whiteList: config.IPWhiteList{
+				SourceRange: []string{"20.20.20.0/24"},
+				IPStrategy:  &config.IPStrategy{ExcludedIPs: []string{"10.0.0.0/8"}},
+			},
+			remoteAddr:    "10.0.0.2:1234",
+			xForwardedFor: "20.20.20.20, 10.0.0.1",
+			expected:      200,
+		},
+		{
+			desc: "non authorized with excluded IPs",
+			whiteList: config.IPWhiteList{
+				SourceRange: []string{"20.20.20.0/24"},
+				IPStrategy:  &config.IPStrategy{ExcludedIPs: []string{"10.0.0.0/8"}},
+			},
+			remoteAddr:    "10.0.0.2:1234",
+			xForwardedFor: "20.20.20.20, 30.30.30.30, 10.0.0.1",
+			expected:      403,
+		},
 	}
 
 	for _, test := range testCases {
@@ -92,6 +197,10 @@ func TestIPWhiteLister_ServeHTTP(t *testing.T) {
 				req.RemoteAddr = test.remoteAddr
 			}
 
+			if len(test.xForwardedFor) > 0 {
+				req.Header.Set("X-Forwarded-For", test.xForwardedFor)
+			}
+
 			whiteLister.ServeHTTP(recorder, req)
 
 			assert.Equal(t, test.expected, recorder.Code)
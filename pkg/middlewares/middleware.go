@@ -2,12 +2,55 @@ package middlewares
 
 import (
 	"context"
+	"errors"
+	"net"
+	"net/http"
 
+	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/log"
 	"github.com/sirupsen/logrus"
+	"github.com/vulcand/oxy/utils"
 )
 
 // GetLogger creates a logger configured with the middleware fields.
 func GetLogger(ctx context.Context, middleware string, middlewareType string) logrus.FieldLogger {
 	return log.FromContext(ctx).WithField(log.MiddlewareName, middleware).WithField(log.MiddlewareType, middlewareType)
 }
+
+// GetSourceExtractor returns the extractor of the source of the requests, defined by the source criterion:
+// the client IP, selected by the IP strategy, the host of the request, or the value of a request header.
+// Without criterion, the source is the client IP, i.e. the remote address of the request, without its port.
+func GetSourceExtractor(ctx context.Context, sourceCriterion *config.SourceCriterion) (utils.SourceExtractor, error) {
+	if sourceCriterion == nil || (sourceCriterion.IPStrategy == nil && sourceCriterion.RequestHeaderName == "" && !sourceCriterion.RequestHost) {
+		sourceCriterion = &config.SourceCriterion{IPStrategy: &config.IPStrategy{}}
+	}
+
+	logger := log.FromContext(ctx)
+
+	if sourceCriterion.IPStrategy != nil {
+		strategy, err := sourceCriterion.IPStrategy.Get()
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Debug("Using the client IP as the source of the requests")
+		return utils.ExtractorFunc(func(req *http.Request) (string, int64, error) {
+			source := strategy.GetIP(req)
+			if host, _, err := net.SplitHostPort(source); err == nil {
+				source = host
+			}
+			if source == "" {
+				return "", 0, errors.New("no client IP found")
+			}
+			return source, 1, nil
+		}), nil
+	}
+
+	if sourceCriterion.RequestHeaderName != "" {
+		logger.Debugf("Using the %s request header as the source of the requests", sourceCriterion.RequestHeaderName)
+		return utils.NewExtractor("request.header." + sourceCriterion.RequestHeaderName)
+	}
+
+	logger.Debug("Using the request host as the source of the requests")
+	return utils.NewExtractor("request.host")
+}
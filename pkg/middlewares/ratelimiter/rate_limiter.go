@@ -2,47 +2,68 @@ package ratelimiter
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/middlewares"
 	"github.com/containous/traefik/pkg/tracing"
+	"github.com/hashicorp/golang-lru/simplelru"
 	"github.com/opentracing/opentracing-go/ext"
-	"github.com/vulcand/oxy/ratelimit"
 	"github.com/vulcand/oxy/utils"
+	"golang.org/x/time/rate"
 )
 
 const (
 	typeName = "RateLimiterType"
+
+	// maxSources is the maximum number of sources whose token buckets are kept:
+	// beyond it, the bucket of the least recently seen source is evicted.
+	maxSources = 65536
 )
 
+// rateLimiter limits the requests of each source with a token bucket,
+// which is refilled at the configured rate, and holds up to the configured burst of tokens.
 type rateLimiter struct {
-	handler http.Handler
-	name    string
+	name          string
+	rate          rate.Limit
+	burst         int
+	next          http.Handler
+	sourceMatcher utils.SourceExtractor
+
+	bucketsLock sync.Mutex
+	buckets     *simplelru.LRU
 }
 
 // New creates rate limiter middleware.
 func New(ctx context.Context, next http.Handler, config config.RateLimit, name string) (http.Handler, error) {
 	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
 
-	extractFunc, err := utils.NewExtractor(config.ExtractorFunc)
-	if err != nil {
+	if err := config.Validate(); err != nil {
 		return nil, err
 	}
 
-	rateSet := ratelimit.NewRateSet()
-	for _, rate := range config.RateSet {
-		if err = rateSet.Add(time.Duration(rate.Period), rate.Average, rate.Burst); err != nil {
-			return nil, err
-		}
+	sourceMatcher, err := middlewares.GetSourceExtractor(ctx, config.SourceCriterion)
+	if err != nil {
+		return nil, err
 	}
 
-	rl, err := ratelimit.New(next, extractFunc, rateSet)
+	buckets, err := simplelru.NewLRU(maxSources, nil)
 	if err != nil {
 		return nil, err
 	}
-	return &rateLimiter{handler: rl, name: name}, nil
+
+	return &rateLimiter{
+		name:          name,
+		rate:          rate.Limit(float64(config.Average) * float64(time.Second) / float64(config.Period)),
+		burst:         int(config.Burst),
+		next:          next,
+		sourceMatcher: sourceMatcher,
+		buckets:       buckets,
+	}, nil
 }
 
 func (r *rateLimiter) GetTracingInformation() (string, ext.SpanKindEnum) {
@@ -50,5 +71,45 @@ func (r *rateLimiter) GetTracingInformation() (string, ext.SpanKindEnum) {
 }
 
 func (r *rateLimiter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	r.handler.ServeHTTP(rw, req)
+	logger := middlewares.GetLogger(req.Context(), r.name, typeName)
+
+	source, amount, err := r.sourceMatcher.Extract(req)
+	if err != nil {
+		logger.Errorf("Could not extract the source of the request: %v", err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := r.getBucket(source).ReserveN(time.Now(), int(amount))
+	if !res.OK() {
+		logger.Debugf("The request of %s exceeds the burst", source)
+		http.Error(rw, "No bursty traffic allowed", http.StatusTooManyRequests)
+		return
+	}
+
+	if delay := res.Delay(); delay > 0 {
+		// the token is given back, as the request is rejected instead of being delayed.
+		res.Cancel()
+
+		logger.Debugf("Too many requests from %s, retry after %s", source, delay)
+		rw.Header().Set("Retry-After", fmt.Sprintf("%.0f", math.Ceil(delay.Seconds())))
+		http.Error(rw, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+
+	r.next.ServeHTTP(rw, req)
+}
+
+// getBucket returns the token bucket of the source, creating it if needed.
+func (r *rateLimiter) getBucket(source string) *rate.Limiter {
+	r.bucketsLock.Lock()
+	defer r.bucketsLock.Unlock()
+
+	if bucket, ok := r.buckets.Get(source); ok {
+		return bucket.(*rate.Limiter)
+	}
+
+	bucket := rate.NewLimiter(r.rate, r.burst)
+	r.buckets.Add(source, bucket)
+	return bucket
 }
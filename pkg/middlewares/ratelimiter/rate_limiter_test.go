@@ -0,0 +1,192 @@
+package ratelimiter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        config.RateLimit
+		expectedRate  float64
+		expectedBurst int
+		expectedError string
+	}{
+		{
+			desc:          "average by second",
+			config:        config.RateLimit{Average: 100, Period: types.Duration(time.Second), Burst: 1},
+			expectedRate:  100,
+			expectedBurst: 1,
+		},
+		{
+			desc:          "average by minute",
+			config:        config.RateLimit{Average: 120, Period: types.Duration(time.Minute), Burst: 10},
+			expectedRate:  2,
+			expectedBurst: 10,
+		},
+		{
+			desc:          "no average",
+			config:        config.RateLimit{Period: types.Duration(time.Second), Burst: 1},
+			expectedError: "average must be greater than 0",
+		},
+		{
+			desc:          "no period",
+			config:        config.RateLimit{Average: 100, Burst: 1},
+			expectedError: "period must be greater than 0",
+		},
+		{
+			desc:          "no burst",
+			config:        config.RateLimit{Average: 100, Period: types.Duration(time.Second)},
+			expectedError: "burst must be greater than 0",
+		},
+		{
+			desc: "invalid IP strategy",
+			config: config.RateLimit{
+				Average:         100,
+				Period:          types.Duration(time.Second),
+				Burst:           1,
+				SourceCriterion: &config.SourceCriterion{IPStrategy: &config.IPStrategy{ExcludedIPs: []string{"foo"}}},
+			},
+			expectedError: `parsing CIDR trusted IPs foo: invalid CIDR address: foo`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler, err := New(context.Background(), http.NotFoundHandler(), test.config, "rate")
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			limiter := handler.(*rateLimiter)
+			assert.InDelta(t, test.expectedRate, float64(limiter.rate), 1e-9)
+			assert.Equal(t, test.expectedBurst, limiter.burst)
+		})
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		sourceCriterion *config.SourceCriterion
+		requests        []func(req *http.Request)
+		expectedCodes   []int
+	}{
+		{
+			desc: "remote address, without its port",
+			requests: []func(req *http.Request){
+				func(req *http.Request) { req.RemoteAddr = "10.0.0.1:1234" },
+				func(req *http.Request) { req.RemoteAddr = "10.0.0.1:1235" },
+				func(req *http.Request) { req.RemoteAddr = "10.0.0.1:1236" },
+				func(req *http.Request) { req.RemoteAddr = "10.0.0.2:1234" },
+			},
+			expectedCodes: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			desc:            "X-Forwarded-For depth",
+			sourceCriterion: &config.SourceCriterion{IPStrategy: &config.IPStrategy{Depth: 1}},
+			requests: []func(req *http.Request){
+				func(req *http.Request) { req.Header.Set("X-Forwarded-For", "10.0.0.1") },
+				func(req *http.Request) { req.Header.Set("X-Forwarded-For", "10.0.0.2, 10.0.0.1") },
+				func(req *http.Request) { req.Header.Set("X-Forwarded-For", "10.0.0.1, 10.0.0.2") },
+				func(req *http.Request) { req.Header.Set("X-Forwarded-For", "10.0.0.1") },
+			},
+			expectedCodes: []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			desc:            "request host",
+			sourceCriterion: &config.SourceCriterion{RequestHost: true},
+			requests: []func(req *http.Request){
+				func(req *http.Request) { req.Host = "foo.localhost" },
+				func(req *http.Request) { req.Host = "foo.localhost" },
+				func(req *http.Request) { req.Host = "bar.localhost" },
+				func(req *http.Request) { req.Host = "foo.localhost" },
+			},
+			expectedCodes: []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			desc:            "request header",
+			sourceCriterion: &config.SourceCriterion{RequestHeaderName: "X-Api-Key"},
+			requests: []func(req *http.Request){
+				func(req *http.Request) { req.Header.Set("X-Api-Key", "foo") },
+				func(req *http.Request) { req.Header.Set("X-Api-Key", "bar") },
+				func(req *http.Request) { req.Header.Set("X-Api-Key", "foo") },
+				func(req *http.Request) { req.Header.Set("X-Api-Key", "foo") },
+			},
+			expectedCodes: []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			// 2 requests by minute, with bursts of 2 requests.
+			conf := config.RateLimit{
+				Average:         2,
+				Period:          types.Duration(time.Minute),
+				Burst:           2,
+				SourceCriterion: test.sourceCriterion,
+			}
+
+			handler, err := New(context.Background(), next, conf, "rate")
+			require.NoError(t, err)
+
+			for i, setRequest := range test.requests {
+				req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+				setRequest(req)
+
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, req)
+
+				assert.Equal(t, test.expectedCodes[i], recorder.Code, "request %d", i)
+				if recorder.Code == http.StatusTooManyRequests {
+					assert.Equal(t, "30", recorder.Header().Get("Retry-After"))
+				} else {
+					assert.Empty(t, recorder.Header().Get("Retry-After"))
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiter_refill(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	handler, err := New(context.Background(), next, config.RateLimit{Average: 20, Period: types.Duration(time.Second), Burst: 1}, "rate")
+	require.NoError(t, err)
+
+	send := func() int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send())
+	assert.Equal(t, http.StatusTooManyRequests, send())
+
+	// a token is added every 50ms.
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, send())
+}
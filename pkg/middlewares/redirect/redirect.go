@@ -3,12 +3,13 @@ package redirect
 import (
 	"bytes"
 	"context"
-	"html/template"
-	"io"
+	"fmt"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
+	"text/template"
+	"text/template/parse"
 
 	"github.com/containous/traefik/pkg/tracing"
 	"github.com/opentracing/opentracing-go/ext"
@@ -18,7 +19,7 @@ import (
 type redirect struct {
 	next        http.Handler
 	regex       *regexp.Regexp
-	replacement string
+	replacement *template.Template
 	permanent   bool
 	errHandler  utils.ErrorHandler
 	name        string
@@ -31,9 +32,18 @@ func newRedirect(_ context.Context, next http.Handler, regex string, replacement
 		return nil, err
 	}
 
+	tmpl, err := template.New("replacement").Funcs(template.FuncMap{escapeFuncName: escapeDollar}).Parse(replacement)
+	if err != nil {
+		return nil, fmt.Errorf("invalid replacement %q: %v", replacement, err)
+	}
+
+	for _, t := range tmpl.Templates() {
+		escapeActions(t.Tree.Root)
+	}
+
 	return &redirect{
 		regex:       re,
-		replacement: replacement,
+		replacement: tmpl,
 		permanent:   permanent,
 		errHandler:  utils.DefaultHandler,
 		next:        next,
@@ -41,6 +51,47 @@ func newRedirect(_ context.Context, next http.Handler, regex string, replacement
 	}, nil
 }
 
+// escapeFuncName is the name of the function escaping the values printed by the actions of the replacement.
+const escapeFuncName = "traefikEscapeDollar"
+
+// escapeDollar escapes the $ signs of a value printed by the replacement,
+// so that the regexp replacement does not expand the request data.
+func escapeDollar(value interface{}) string {
+	return strings.Replace(fmt.Sprint(value), "$", "$$", -1)
+}
+
+// escapeActions pipes the value printed by each action of the node to the escape function.
+func escapeActions(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			escapeActions(child)
+		}
+	case *parse.ActionNode:
+		// An action declaring a variable does not print anything.
+		if len(n.Pipe.Decl) > 0 {
+			return
+		}
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      n.Pos,
+			Args:     []parse.Node{parse.NewIdentifier(escapeFuncName).SetPos(n.Pos)},
+		})
+	case *parse.IfNode:
+		escapeActions(n.List)
+		escapeActions(n.ElseList)
+	case *parse.RangeNode:
+		escapeActions(n.List)
+		escapeActions(n.ElseList)
+	case *parse.WithNode:
+		escapeActions(n.List)
+		escapeActions(n.ElseList)
+	}
+}
+
 func (r *redirect) GetTracingInformation() (string, ext.SpanKindEnum) {
 	return r.name, tracing.SpanKindNoneEnum
 }
@@ -54,23 +105,27 @@ func (r *redirect) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// apply a rewrite regexp to the URL
-	newURL := r.regex.ReplaceAllString(oldURL, r.replacement)
-
-	// replace any variables that may be in there
-	rewrittenURL := &bytes.Buffer{}
-	if err := applyString(newURL, rewrittenURL, req); err != nil {
+	// replace any variables that may be in the replacement,
+	// before applying it to the URL, so that the URL sent by the client is never evaluated as a template.
+	replacement := &bytes.Buffer{}
+	data := struct{ Request *http.Request }{Request: req}
+	if err := r.replacement.Execute(replacement, data); err != nil {
 		r.errHandler.ServeHTTP(rw, req, err)
 		return
 	}
 
+	// apply a rewrite regexp to the URL
+	newURL := r.regex.ReplaceAllString(oldURL, replacement.String())
+
 	// parse the rewritten URL and replace request URL with it
-	parsedURL, err := url.Parse(rewrittenURL.String())
+	parsedURL, err := url.Parse(newURL)
 	if err != nil {
 		r.errHandler.ServeHTTP(rw, req, err)
 		return
 	}
 
+	// A request whose URL is already the rewritten one is not redirected again,
+	// so that the middleware does not loop when it is attached to the router of the redirection target.
 	if newURL != oldURL {
 		handler := &moveHandler{location: parsedURL, permanent: r.permanent}
 		handler.ServeHTTP(rw, req)
@@ -116,7 +171,7 @@ func rawURL(req *http.Request) string {
 	port := ""
 	uri := req.RequestURI
 
-	schemeRegex := `^(https?):\/\/([\w\._-]+)(:\d+)?(.*)$`
+	schemeRegex := `^(https?):\/\/(\[[\w:.%]+\]|[\w\._-]+)(:\d+)?(.*)$`
 	re, _ := regexp.Compile(schemeRegex)
 	if re.Match([]byte(req.RequestURI)) {
 		match := re.FindStringSubmatch(req.RequestURI)
@@ -145,14 +200,3 @@ func isXForwardedHTTPS(request *http.Request) bool {
 
 	return len(xForwardedProto) > 0 && xForwardedProto == "https"
 }
-
-func applyString(in string, out io.Writer, req *http.Request) error {
-	t, err := template.New("t").Parse(in)
-	if err != nil {
-		return err
-	}
-
-	data := struct{ Request *http.Request }{Request: req}
-
-	return t.Execute(out, data)
-}
@@ -196,3 +196,123 @@ func TestRedirectRegexHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestRedirectRegexHandler_requestURI(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		config         config.RedirectRegex
+		url            string
+		secured        bool
+		header         string
+		expectedURL    string
+		expectedStatus int
+	}{
+		{
+			desc: "capture groups with the host and the query preserved",
+			config: config.RedirectRegex{
+				Regex:       `^https?://([^/]+)/old/(.*)$`,
+				Replacement: "https://$1/new/$2",
+			},
+			url:            "http://foo.com/old/bar?a=1&b=2",
+			expectedURL:    "https://foo.com/new/bar?a=1&b=2",
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc: "permanent redirection to the target router",
+			config: config.RedirectRegex{
+				Regex:       `^https?://foo\.com/(.*)$`,
+				Replacement: "https://foo.com/${1}",
+				Permanent:   true,
+			},
+			url:            "http://foo.com/bar",
+			expectedURL:    "https://foo.com/bar",
+			expectedStatus: http.StatusMovedPermanently,
+		},
+		{
+			desc: "no redirection loop on the target router",
+			config: config.RedirectRegex{
+				Regex:       `^https?://foo\.com/(.*)$`,
+				Replacement: "https://foo.com/${1}",
+				Permanent:   true,
+			},
+			url:            "https://foo.com/bar",
+			secured:        true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "request URL not evaluated as a template",
+			config: config.RedirectRegex{
+				Regex:       `^http://foo\.com/(.*)$`,
+				Replacement: "https://foo.com/$1",
+			},
+			url:            "http://foo.com/{{.Request.Host}}",
+			expectedURL:    "https://foo.com/%7B%7B.Request.Host%7D%7D",
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc: "request header not escaped",
+			config: config.RedirectRegex{
+				Regex:       `^http://foo\.com/(.*)$`,
+				Replacement: `https://foo.com/$1?q={{ .Request.Header.Get "X-Foo" }}`,
+			},
+			url:            "http://foo.com/bar",
+			header:         "a&b",
+			expectedURL:    "https://foo.com/bar?q=a&b",
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc: "request header not expanded by the regexp",
+			config: config.RedirectRegex{
+				Regex:       `^http://foo\.com/(.*)$`,
+				Replacement: `https://foo.com/$1?q={{ .Request.Header.Get "X-Foo" }}`,
+			},
+			url:            "http://foo.com/bar",
+			header:         "$1${1}$$",
+			expectedURL:    "https://foo.com/bar?q=$1${1}$$",
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc: "request header not expanded by the regexp, in a conditional",
+			config: config.RedirectRegex{
+				Regex:       `^http://foo\.com/(.*)$`,
+				Replacement: `https://foo.com/$1{{ with .Request.Header.Get "X-Foo" }}?q={{ . }}{{ end }}`,
+			},
+			url:            "http://foo.com/bar",
+			header:         "$1",
+			expectedURL:    "https://foo.com/bar?q=$1",
+			expectedStatus: http.StatusFound,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			handler, err := NewRedirectRegex(context.Background(), next, test.config, "traefikTest")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, test.url, nil)
+			if test.secured {
+				req.TLS = &tls.ConnectionState{}
+			}
+			req.Header.Set("X-Foo", test.header)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedURL, recorder.Header().Get("Location"))
+		})
+	}
+}
+
+func TestNewRedirectRegex_invalidReplacement(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	_, err := NewRedirectRegex(context.Background(), next, config.RedirectRegex{
+		Regex:       `^(.*)$`,
+		Replacement: "https://foo.com/{{ .Request.Host",
+	}, "traefikTest")
+	assert.Error(t, err)
+}
@@ -164,6 +164,35 @@ func TestRedirectSchemeHandler(t *testing.T) {
 			expectedURL:    "http://foo:8181",
 			expectedStatus: http.StatusFound,
 		},
+		{
+			desc: "no redirection loop on the target router",
+			config: config.RedirectScheme{
+				Scheme: "https",
+				Port:   "443",
+			},
+			url:            "https://foo/bar?a=1",
+			secured:        true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "HTTP to HTTPS with query",
+			config: config.RedirectScheme{
+				Scheme: "https",
+			},
+			url:            "http://foo:80/bar?a=1&b=2",
+			expectedURL:    "https://foo/bar?a=1&b=2",
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc: "HTTP to HTTPS with IPv6 host",
+			config: config.RedirectScheme{
+				Scheme: "https",
+				Port:   "8443",
+			},
+			url:            "http://[::1]:8080/bar",
+			expectedURL:    "https://[::1]:8443/bar",
+			expectedStatus: http.StatusFound,
+		},
 		{
 			desc: "HTTPS with port 80 to HTTPS without port",
 			config: config.RedirectScheme{
@@ -2,7 +2,9 @@ package replacepath
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/middlewares"
@@ -39,8 +41,35 @@ func (r *replacePath) GetTracingInformation() (string, ext.SpanKindEnum) {
 }
 
 func (r *replacePath) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	req.Header.Add(ReplacedPathHeader, req.URL.Path)
-	req.URL.Path = r.path
+	if req.URL.RawPath == "" {
+		req.Header.Add(ReplacedPathHeader, req.URL.Path)
+	} else {
+		req.Header.Add(ReplacedPathHeader, req.URL.RawPath)
+	}
+
+	if err := SetPath(req.URL, r.path); err != nil {
+		middlewares.GetLogger(req.Context(), r.name, typeName).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	req.RequestURI = req.URL.RequestURI()
+
 	r.next.ServeHTTP(rw, req)
 }
+
+// SetPath sets the path of the URL from its escaped form,
+// so that the encoded characters, e.g. %2F, are kept as is in the RawPath instead of being decoded twice.
+func SetPath(u *url.URL, escapedPath string) error {
+	path, err := url.PathUnescape(escapedPath)
+	if err != nil {
+		return fmt.Errorf("invalid path %q: %v", escapedPath, err)
+	}
+
+	u.Path = path
+	u.RawPath = ""
+	if path != escapedPath {
+		u.RawPath = escapedPath
+	}
+
+	return nil
+}
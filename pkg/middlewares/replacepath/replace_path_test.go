@@ -3,6 +3,7 @@ package replacepath
 import (
 	"context"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/containous/traefik/pkg/config"
@@ -44,3 +45,81 @@ func TestReplacePath(t *testing.T) {
 		})
 	}
 }
+
+func TestReplacePath_escapedPath(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		path               string
+		replacement        string
+		expectedPath       string
+		expectedRawPath    string
+		expectedHeader     string
+		expectedRequestURI string
+		expectedStatus     int
+	}{
+		{
+			desc:               "encoded slash in the original path",
+			path:               "/foo%2Fbar",
+			replacement:        "/replacement-path",
+			expectedPath:       "/replacement-path",
+			expectedHeader:     "/foo%2Fbar",
+			expectedRequestURI: "/replacement-path",
+			expectedStatus:     http.StatusOK,
+		},
+		{
+			desc:               "encoded slash in the replacement",
+			path:               "/example",
+			replacement:        "/foo%2Fbar",
+			expectedPath:       "/foo/bar",
+			expectedRawPath:    "/foo%2Fbar",
+			expectedHeader:     "/example",
+			expectedRequestURI: "/foo%2Fbar",
+			expectedStatus:     http.StatusOK,
+		},
+		{
+			desc:               "encoded percent is decoded only once",
+			path:               "/example",
+			replacement:        "/foo%252Fbar",
+			expectedPath:       "/foo%2Fbar",
+			expectedRawPath:    "/foo%252Fbar",
+			expectedHeader:     "/example",
+			expectedRequestURI: "/foo%252Fbar",
+			expectedStatus:     http.StatusOK,
+		},
+		{
+			desc:           "invalid escaped replacement",
+			path:           "/example",
+			replacement:    "/foo%zz",
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var actualPath, actualRawPath, actualHeader, requestURI string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actualPath = r.URL.Path
+				actualRawPath = r.URL.RawPath
+				actualHeader = r.Header.Get(ReplacedPathHeader)
+				requestURI = r.RequestURI
+			})
+
+			handler, err := New(context.Background(), next, config.ReplacePath{Path: test.replacement}, "foo-replace-path")
+			require.NoError(t, err)
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost"+test.path, nil)
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedPath, actualPath, "Unexpected path.")
+			assert.Equal(t, test.expectedRawPath, actualRawPath, "Unexpected raw path.")
+			assert.Equal(t, test.expectedHeader, actualHeader, "Unexpected '%s' header.", ReplacedPathHeader)
+			assert.Equal(t, test.expectedRequestURI, requestURI, "Unexpected request URI.")
+		})
+	}
+}
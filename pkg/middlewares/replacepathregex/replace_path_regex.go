@@ -48,10 +48,22 @@ func (rp *replacePathRegex) GetTracingInformation() (string, ext.SpanKindEnum) {
 }
 
 func (rp *replacePathRegex) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	if rp.regexp != nil && len(rp.replacement) > 0 && rp.regexp.MatchString(req.URL.Path) {
-		req.Header.Add(replacepath.ReplacedPathHeader, req.URL.Path)
-		req.URL.Path = rp.regexp.ReplaceAllString(req.URL.Path, rp.replacement)
+	// The regex applies to the escaped path, so that an encoded slash (%2F) is not mistaken for a path separator.
+	currentPath := req.URL.RawPath
+	if currentPath == "" {
+		currentPath = req.URL.EscapedPath()
+	}
+
+	if rp.regexp != nil && len(rp.replacement) > 0 && rp.regexp.MatchString(currentPath) {
+		req.Header.Add(replacepath.ReplacedPathHeader, currentPath)
+
+		if err := replacepath.SetPath(req.URL, rp.regexp.ReplaceAllString(currentPath, rp.replacement)); err != nil {
+			middlewares.GetLogger(req.Context(), rp.name, typeName).Error(err)
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
 		req.RequestURI = req.URL.RequestURI()
 	}
+
 	rp.next.ServeHTTP(rw, req)
 }
@@ -14,12 +14,13 @@ import (
 
 func TestReplacePathRegex(t *testing.T) {
 	testCases := []struct {
-		desc           string
-		path           string
-		config         config.ReplacePathRegex
-		expectedPath   string
-		expectedHeader string
-		expectsError   bool
+		desc            string
+		path            string
+		config          config.ReplacePathRegex
+		expectedPath    string
+		expectedRawPath string
+		expectedHeader  string
+		expectsError    bool
 	}{
 		{
 			desc: "simple regex",
@@ -60,6 +61,28 @@ func TestReplacePathRegex(t *testing.T) {
 			expectedPath:   "/downloads/src-source.go",
 			expectedHeader: "/downloads/src/source.go",
 		},
+		{
+			desc: "encoded slash is not a path separator",
+			path: "/downloads/src%2Fsource.go",
+			config: config.ReplacePathRegex{
+				Replacement: "/downloads/$1",
+				Regex:       `^/downloads/([^/]+)$`,
+			},
+			expectedPath:    "/downloads/src/source.go",
+			expectedRawPath: "/downloads/src%2Fsource.go",
+			expectedHeader:  "/downloads/src%2Fsource.go",
+		},
+		{
+			desc: "encoded slash in the replacement",
+			path: "/foo/bar",
+			config: config.ReplacePathRegex{
+				Replacement: "/foo%2F$1",
+				Regex:       `^/foo/(.*)`,
+			},
+			expectedPath:    "/foo/bar",
+			expectedRawPath: "/foo%2Fbar",
+			expectedHeader:  "/foo/bar",
+		},
 		{
 			desc: "invalid regular expression",
 			path: "/invalid/regexp/test",
@@ -75,9 +98,10 @@ func TestReplacePathRegex(t *testing.T) {
 	for _, test := range testCases {
 		t.Run(test.desc, func(t *testing.T) {
 
-			var actualPath, actualHeader, requestURI string
+			var actualPath, actualRawPath, actualHeader, requestURI string
 			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				actualPath = r.URL.Path
+				actualRawPath = r.URL.RawPath
 				actualHeader = r.Header.Get(replacepath.ReplacedPathHeader)
 				requestURI = r.RequestURI
 			})
@@ -94,7 +118,8 @@ func TestReplacePathRegex(t *testing.T) {
 				handler.ServeHTTP(nil, req)
 
 				assert.Equal(t, test.expectedPath, actualPath, "Unexpected path.")
-				assert.Equal(t, actualPath, requestURI, "Unexpected request URI.")
+				assert.Equal(t, test.expectedRawPath, actualRawPath, "Unexpected raw path.")
+				assert.Equal(t, req.URL.RequestURI(), requestURI, "Unexpected request URI.")
 				if test.expectedHeader != "" {
 					assert.Equal(t, test.expectedHeader, actualHeader, "Unexpected '%s' header.", replacepath.ReplacedPathHeader)
 				}
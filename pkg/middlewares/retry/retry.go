@@ -8,7 +8,9 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptrace"
+	"time"
 
+	"github.com/cenkalti/backoff"
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/middlewares"
 	"github.com/containous/traefik/pkg/tracing"
@@ -35,10 +37,12 @@ type Listeners []Listener
 
 // retry is a middleware that retries requests.
 type retry struct {
-	attempts int
-	next     http.Handler
-	listener Listener
-	name     string
+	attempts        int
+	initialInterval time.Duration
+	perTryTimeout   time.Duration
+	next            http.Handler
+	listener        Listener
+	name            string
 }
 
 // New returns a new retry middleware.
@@ -51,10 +55,12 @@ func New(ctx context.Context, next http.Handler, config config.Retry, listener L
 	}
 
 	return &retry{
-		attempts: config.Attempts,
-		next:     next,
-		listener: listener,
-		name:     name,
+		attempts:        config.Attempts,
+		initialInterval: time.Duration(config.InitialInterval),
+		perTryTimeout:   time.Duration(config.PerTryTimeout),
+		next:            next,
+		listener:        listener,
+		name:            name,
 	}, nil
 }
 
@@ -63,6 +69,9 @@ func (r *retry) GetTracingInformation() (string, ext.SpanKindEnum) {
 }
 
 func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// The idempotent requests can be sent again once a server received them, until it answers.
+	idempotent := isIdempotent(req)
+
 	// if we might make multiple attempts, swap the body for an ioutil.NopCloser
 	// cf https://github.com/containous/traefik/issues/1008
 	if r.attempts > 1 {
@@ -71,23 +80,35 @@ func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		req.Body = ioutil.NopCloser(body)
 	}
 
+	backOff := r.newBackOff()
+
 	attempts := 1
 	for {
 		shouldRetry := attempts < r.attempts
 		retryResponseWriter := newResponseWriter(rw, shouldRetry)
 
-		// Disable retries when the backend already received request data
 		trace := &httptrace.ClientTrace{
+			// Disable retries when the backend already received request data
 			WroteHeaders: func() {
-				retryResponseWriter.DisableRetries()
+				if !idempotent {
+					retryResponseWriter.DisableRetries()
+				}
 			},
 			WroteRequest: func(httptrace.WroteRequestInfo) {
+				if !idempotent {
+					retryResponseWriter.DisableRetries()
+				}
+			},
+			// Disable retries when the backend answered, as its response headers are about to be written.
+			GotFirstResponseByte: func() {
 				retryResponseWriter.DisableRetries()
 			},
 		}
-		newCtx := httptrace.WithClientTrace(req.Context(), trace)
+		newCtx, cancel := r.attemptContext(req.Context())
+		newCtx = httptrace.WithClientTrace(newCtx, trace)
 
 		r.next.ServeHTTP(retryResponseWriter, req.WithContext(newCtx))
+		cancel()
 
 		if !retryResponseWriter.ShouldRetry() {
 			break
@@ -95,11 +116,60 @@ func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 		attempts++
 		logger := middlewares.GetLogger(req.Context(), r.name, typeName)
+
+		if backOff != nil {
+			interval := backOff.NextBackOff()
+			logger.Debugf("Waiting %s before the attempt %d for request: %v", interval, attempts, req.URL)
+
+			timer := time.NewTimer(interval)
+			select {
+			case <-timer.C:
+			case <-req.Context().Done():
+				timer.Stop()
+				logger.Debugf("Giving up the attempt %d for request: %v: %v", attempts, req.URL, req.Context().Err())
+				return
+			}
+		}
+
 		logger.Debugf("New attempt %d for request: %v", attempts, req.URL)
 		r.listener.Retried(req, attempts)
 	}
 }
 
+// attemptContext returns the context of an attempt, canceled after the per try timeout, unless it is zero.
+func (r *retry) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.perTryTimeout > 0 {
+		return context.WithTimeout(ctx, r.perTryTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// newBackOff returns the exponential backoff between the attempts, or nil when they do not wait.
+func (r *retry) newBackOff() backoff.BackOff {
+	if r.attempts < 2 || r.initialInterval <= 0 {
+		return nil
+	}
+
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = r.initialInterval
+	// The attempts are bounded by their number, not by their duration.
+	b.MaxElapsedTime = 0
+	b.Reset()
+
+	return b
+}
+
+// isIdempotent tells whether the request can be sent to a server again once a server received it:
+// its method must be idempotent, and it must not have a body, which cannot be read twice.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return req.Body == nil || req.Body == http.NoBody
+	default:
+		return false
+	}
+}
+
 // Retried exists to implement the Listener interface. It calls Retried on each of its slice entries.
 func (l Listeners) Retried(req *http.Request, attempt int) {
 	for _, listener := range l {
@@ -193,6 +263,11 @@ func (r *responseWriterWithoutCloseNotify) Hijack() (net.Conn, *bufio.ReadWriter
 }
 
 func (r *responseWriterWithoutCloseNotify) Flush() {
+	// Flushing would write the response headers of an attempt which is going to be retried.
+	if r.ShouldRetry() {
+		return
+	}
+
 	if flusher, ok := r.responseWriter.(http.Flusher); ok {
 		flusher.Flush()
 	}
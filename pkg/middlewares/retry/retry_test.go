@@ -3,15 +3,18 @@ package retry
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/http/httptrace"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/middlewares/emptybackendhandler"
 	"github.com/containous/traefik/pkg/testhelpers"
+	"github.com/containous/traefik/pkg/types"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -38,7 +41,7 @@ func TestRetry(t *testing.T) {
 			desc:                  "no retry when max request attempts is one",
 			config:                config.Retry{Attempts: 1},
 			wantRetryAttempts:     0,
-			wantResponseStatus:    http.StatusInternalServerError,
+			wantResponseStatus:    http.StatusBadGateway,
 			amountFaultyEndpoints: 1,
 		},
 		{
@@ -59,7 +62,7 @@ func TestRetry(t *testing.T) {
 			desc:                  "max attempts exhausted delivers the 5xx response",
 			config:                config.Retry{Attempts: 3},
 			wantRetryAttempts:     2,
-			wantResponseStatus:    http.StatusInternalServerError,
+			wantResponseStatus:    http.StatusBadGateway,
 			amountFaultyEndpoints: 3,
 		},
 	}
@@ -83,15 +86,19 @@ func TestRetry(t *testing.T) {
 			loadBalancer, err := roundrobin.New(forwarder)
 			require.NoError(t, err)
 
-			basePort := 33444
+			// The faulty servers refuse the connections, as they are closed.
+			// They are closed once they are all started, so that they have distinct URLs.
+			var faultyServers []*httptest.Server
 			for i := 0; i < test.amountFaultyEndpoints; i++ {
-				// 192.0.2.0 is a non-routable IP for testing purposes.
-				// See: https://stackoverflow.com/questions/528538/non-routable-ip-address/18436928#18436928
-				// We only use the port specification here because the URL is used as identifier
-				// in the load balancer and using the exact same URL would not add a new server.
-				err = loadBalancer.UpsertServer(testhelpers.MustParseURL("http://192.0.2.0:" + string(basePort+i)))
+				faultyServer := httptest.NewServer(http.NotFoundHandler())
+				faultyServers = append(faultyServers, faultyServer)
+
+				err = loadBalancer.UpsertServer(testhelpers.MustParseURL(faultyServer.URL))
 				require.NoError(t, err)
 			}
+			for _, faultyServer := range faultyServers {
+				faultyServer.Close()
+			}
 
 			// add the functioning server to the end of the load balancer list
 			err = loadBalancer.UpsertServer(testhelpers.MustParseURL(backendServer.URL))
@@ -136,6 +143,158 @@ func TestRetryEmptyServerList(t *testing.T) {
 	assert.Equal(t, 0, retryListener.timesCalled)
 }
 
+func TestRetryPerTryTimeout(t *testing.T) {
+	stop := make(chan struct{})
+	slowServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-stop:
+		}
+	}))
+	defer slowServer.Close()
+	defer close(stop)
+
+	backendServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer backendServer.Close()
+
+	testCases := []struct {
+		desc               string
+		method             string
+		body               string
+		wantRetryAttempts  int
+		wantResponseStatus int
+	}{
+		{
+			desc:               "idempotent request retried on another server",
+			method:             http.MethodGet,
+			wantRetryAttempts:  1,
+			wantResponseStatus: http.StatusOK,
+		},
+		{
+			desc:               "request with a body not retried once sent",
+			method:             http.MethodPost,
+			body:               "foo",
+			wantRetryAttempts:  0,
+			wantResponseStatus: http.StatusGatewayTimeout,
+		},
+	}
+
+	forwarder, err := forward.New()
+	require.NoError(t, err)
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			loadBalancer, err := roundrobin.New(forwarder)
+			require.NoError(t, err)
+
+			err = loadBalancer.UpsertServer(testhelpers.MustParseURL(slowServer.URL))
+			require.NoError(t, err)
+			err = loadBalancer.UpsertServer(testhelpers.MustParseURL(backendServer.URL))
+			require.NoError(t, err)
+
+			retryListener := &countingRetryListener{}
+			conf := config.Retry{Attempts: 2, PerTryTimeout: types.Duration(50 * time.Millisecond)}
+			retry, err := New(context.Background(), loadBalancer, conf, retryListener, "traefikTest")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(test.method, "http://localhost:3000/ok", strings.NewReader(test.body))
+			if test.body == "" {
+				req.Body = http.NoBody
+			}
+
+			retry.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.wantResponseStatus, recorder.Code)
+			assert.Equal(t, test.wantRetryAttempts, retryListener.timesCalled)
+		})
+	}
+}
+
+func TestRetryInitialInterval(t *testing.T) {
+	forwarder, err := forward.New()
+	require.NoError(t, err)
+
+	loadBalancer, err := roundrobin.New(forwarder)
+	require.NoError(t, err)
+
+	faultyServer := httptest.NewServer(http.NotFoundHandler())
+	err = loadBalancer.UpsertServer(testhelpers.MustParseURL(faultyServer.URL))
+	require.NoError(t, err)
+	faultyServer.Close()
+
+	backendServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer backendServer.Close()
+
+	err = loadBalancer.UpsertServer(testhelpers.MustParseURL(backendServer.URL))
+	require.NoError(t, err)
+
+	retryListener := &countingRetryListener{}
+	conf := config.Retry{Attempts: 2, InitialInterval: types.Duration(100 * time.Millisecond)}
+	retry, err := New(context.Background(), loadBalancer, conf, retryListener, "traefikTest")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil)
+
+	start := time.Now()
+	retry.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, 1, retryListener.timesCalled)
+	// The interval is randomized by half of its value.
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+}
+
+func TestIsIdempotent(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		method   string
+		body     io.Reader
+		expected bool
+	}{
+		{
+			desc:     "GET without body",
+			method:   http.MethodGet,
+			expected: true,
+		},
+		{
+			desc:     "HEAD without body",
+			method:   http.MethodHead,
+			expected: true,
+		},
+		{
+			desc:   "GET with a body",
+			method: http.MethodGet,
+			body:   strings.NewReader("foo"),
+		},
+		{
+			desc:   "PUT without body",
+			method: http.MethodPut,
+		},
+		{
+			desc:   "POST without body",
+			method: http.MethodPost,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(test.method, "http://localhost:3000/ok", test.body)
+
+			assert.Equal(t, test.expected, isIdempotent(req))
+		})
+	}
+}
+
 func TestRetryListeners(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	retryListeners := Listeners{&countingRetryListener{}, &countingRetryListener{}}
@@ -277,13 +436,17 @@ func TestRetryWebsocket(t *testing.T) {
 				t.Fatalf("Error creating load balancer: %v", err)
 			}
 
-			basePort := 33444
+			// The faulty servers refuse the connections, as they are closed.
+			// They are closed once they are all started, so that they have distinct URLs.
+			var faultyServers []*httptest.Server
 			for i := 0; i < test.amountFaultyEndpoints; i++ {
-				// 192.0.2.0 is a non-routable IP for testing purposes.
-				// See: https://stackoverflow.com/questions/528538/non-routable-ip-address/18436928#18436928
-				// We only use the port specification here because the URL is used as identifier
-				// in the load balancer and using the exact same URL would not add a new server.
-				_ = loadBalancer.UpsertServer(testhelpers.MustParseURL("http://192.0.2.0:" + string(basePort+i)))
+				faultyServer := httptest.NewServer(http.NotFoundHandler())
+				faultyServers = append(faultyServers, faultyServer)
+
+				_ = loadBalancer.UpsertServer(testhelpers.MustParseURL(faultyServer.URL))
+			}
+			for _, faultyServer := range faultyServers {
+				faultyServer.Close()
 			}
 
 			// add the functioning server to the end of the load balancer list
@@ -19,18 +19,20 @@ const (
 
 // stripPrefix is a middleware used to strip prefix from an URL request.
 type stripPrefix struct {
-	next     http.Handler
-	prefixes []string
-	name     string
+	next       http.Handler
+	prefixes   []string
+	forceSlash bool
+	name       string
 }
 
 // New creates a new strip prefix middleware.
 func New(ctx context.Context, next http.Handler, config config.StripPrefix, name string) (http.Handler, error) {
 	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
 	return &stripPrefix{
-		prefixes: config.Prefixes,
-		next:     next,
-		name:     name,
+		prefixes:   config.Prefixes,
+		forceSlash: config.ForceSlash,
+		next:       next,
+		name:       name,
 	}, nil
 }
 
@@ -40,10 +42,10 @@ func (s *stripPrefix) GetTracingInformation() (string, ext.SpanKindEnum) {
 
 func (s *stripPrefix) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	for _, prefix := range s.prefixes {
-		if strings.HasPrefix(req.URL.Path, prefix) {
-			req.URL.Path = getPrefixStripped(req.URL.Path, prefix)
+		if HasPathPrefix(req.URL.Path, prefix) {
+			req.URL.Path = s.getPrefixStripped(req.URL.Path, prefix)
 			if req.URL.RawPath != "" {
-				req.URL.RawPath = getPrefixStripped(req.URL.RawPath, prefix)
+				req.URL.RawPath = s.getPrefixStripped(req.URL.RawPath, prefix)
 			}
 			s.serveRequest(rw, req, strings.TrimSpace(prefix))
 			return
@@ -58,8 +60,25 @@ func (s *stripPrefix) serveRequest(rw http.ResponseWriter, req *http.Request, pr
 	s.next.ServeHTTP(rw, req)
 }
 
-func getPrefixStripped(s, prefix string) string {
-	return ensureLeadingSlash(strings.TrimPrefix(s, prefix))
+// getPrefixStripped strips the prefix from the path, keeping its trailing slash, if any, as the leading slash of the path.
+// A path equal to a prefix without trailing slash becomes empty, or / when forceSlash is set.
+func (s *stripPrefix) getPrefixStripped(urlPath, prefix string) string {
+	stripped := strings.TrimPrefix(urlPath, strings.TrimSuffix(prefix, "/"))
+	if stripped == "" && !s.forceSlash {
+		return ""
+	}
+
+	return ensureLeadingSlash(stripped)
+}
+
+// HasPathPrefix tells whether the path starts with the prefix, on a boundary of the path segments:
+// the prefix /api matches the paths /api and /api/v2, but not /apiv2.
+func HasPathPrefix(urlPath, prefix string) bool {
+	if !strings.HasPrefix(urlPath, prefix) {
+		return false
+	}
+
+	return len(urlPath) == len(prefix) || strings.HasSuffix(prefix, "/") || urlPath[len(prefix)] == '/'
 }
 
 func ensureLeadingSlash(str string) string {
@@ -20,6 +20,7 @@ func TestStripPrefix(t *testing.T) {
 		expectedStatusCode int
 		expectedPath       string
 		expectedRawPath    string
+		expectedRequestURI string
 		expectedHeader     string
 	}{
 		{
@@ -43,13 +44,25 @@ func TestStripPrefix(t *testing.T) {
 		{
 			desc: "prefix and path matching",
 			config: config.StripPrefix{
-				Prefixes: []string{"/stat"},
+				Prefixes:   []string{"/stat"},
+				ForceSlash: true,
 			},
 			path:               "/stat",
 			expectedStatusCode: http.StatusOK,
 			expectedPath:       "/",
 			expectedHeader:     "/stat",
 		},
+		{
+			desc: "prefix and path matching without force slash",
+			config: config.StripPrefix{
+				Prefixes: []string{"/stat"},
+			},
+			path:               "/stat",
+			expectedStatusCode: http.StatusOK,
+			expectedPath:       "",
+			expectedRequestURI: "/",
+			expectedHeader:     "/stat",
+		},
 		{
 			desc: "path prefix on exactly matching path",
 			config: config.StripPrefix{
@@ -102,7 +115,8 @@ func TestStripPrefix(t *testing.T) {
 		{
 			desc: "later prefix matching",
 			config: config.StripPrefix{
-				Prefixes: []string{"/mismatch", "/stat"},
+				Prefixes:   []string{"/mismatch", "/stat"},
+				ForceSlash: true,
 			},
 			path:               "/stat",
 			expectedStatusCode: http.StatusOK,
@@ -110,14 +124,22 @@ func TestStripPrefix(t *testing.T) {
 			expectedHeader:     "/stat",
 		},
 		{
-			desc: "prefix matching within slash boundaries",
+			desc: "prefix not matching within a path segment",
 			config: config.StripPrefix{
-				Prefixes: []string{"/stat"},
+				Prefixes: []string{"/api"},
 			},
-			path:               "/status",
+			path:               "/apiv2",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			desc: "later prefix matching on a path segment",
+			config: config.StripPrefix{
+				Prefixes: []string{"/api", "/apiv2"},
+			},
+			path:               "/apiv2/users",
 			expectedStatusCode: http.StatusOK,
-			expectedPath:       "/us",
-			expectedHeader:     "/stat",
+			expectedPath:       "/users",
+			expectedHeader:     "/apiv2",
 		},
 		{
 			desc: "raw path is also stripped",
@@ -163,6 +185,9 @@ func TestStripPrefix(t *testing.T) {
 				// go HTTP uses the raw path when existent in the RequestURI
 				expectedURI = test.expectedRawPath
 			}
+			if test.expectedRequestURI != "" {
+				expectedURI = test.expectedRequestURI
+			}
 			assert.Equal(t, expectedURI, requestURI, "Unexpected request URI.")
 		})
 	}
@@ -2,6 +2,7 @@ package stripprefixregex
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -20,7 +21,7 @@ const (
 // StripPrefixRegex is a middleware used to strip prefix from an URL request.
 type stripPrefixRegex struct {
 	next   http.Handler
-	router *mux.Router
+	routes []*mux.Route
 	name   string
 }
 
@@ -29,13 +30,17 @@ func New(ctx context.Context, next http.Handler, config config.StripPrefixRegex,
 	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
 
 	stripPrefix := stripPrefixRegex{
-		next:   next,
-		router: mux.NewRouter(),
-		name:   name,
+		next: next,
+		name: name,
 	}
 
+	// Each prefix has its own route, so that a prefix matching a path within a segment does not hide the next prefixes.
 	for _, prefix := range config.Regex {
-		stripPrefix.router.PathPrefix(prefix)
+		route := mux.NewRouter().PathPrefix(prefix)
+		if err := route.GetError(); err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %v", prefix, err)
+		}
+		stripPrefix.routes = append(stripPrefix.routes, route)
 	}
 
 	return &stripPrefix, nil
@@ -46,31 +51,40 @@ func (s *stripPrefixRegex) GetTracingInformation() (string, ext.SpanKindEnum) {
 }
 
 func (s *stripPrefixRegex) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	var match mux.RouteMatch
-	if s.router.Match(req, &match) {
+	for _, route := range s.routes {
+		var match mux.RouteMatch
+		if !route.Match(req, &match) {
+			continue
+		}
+
 		params := make([]string, 0, len(match.Vars)*2)
 		for key, val := range match.Vars {
 			params = append(params, key)
 			params = append(params, val)
 		}
 
-		prefix, err := match.Route.URL(params...)
+		prefix, err := route.URL(params...)
 		if err != nil || len(prefix.Path) > len(req.URL.Path) {
 			logger := middlewares.GetLogger(req.Context(), s.name, typeName)
 			logger.Error("Error in stripPrefix middleware", err)
 			return
 		}
 
-		req.URL.Path = req.URL.Path[len(prefix.Path):]
+		if !stripprefix.HasPathPrefix(req.URL.Path, prefix.Path) {
+			continue
+		}
+
+		req.URL.Path = ensureLeadingSlash(req.URL.Path[len(prefix.Path):])
 		if req.URL.RawPath != "" {
-			req.URL.RawPath = req.URL.RawPath[len(prefix.Path):]
+			req.URL.RawPath = ensureLeadingSlash(req.URL.RawPath[len(prefix.Path):])
 		}
 		req.Header.Add(stripprefix.ForwardedPrefixHeader, prefix.Path)
-		req.RequestURI = ensureLeadingSlash(req.URL.RequestURI())
+		req.RequestURI = req.URL.RequestURI()
 
 		s.next.ServeHTTP(rw, req)
 		return
 	}
+
 	http.NotFound(rw, req)
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/cenkalti/backoff"
@@ -35,35 +36,50 @@ func (c *challengeHTTP) Timeout() (timeout, interval time.Duration) {
 	return 60 * time.Second, 5 * time.Second
 }
 
-// Append adds routes on internal router
+// Append adds routes on internal router.
+// The route of a certificates resolver only matches its own tokens,
+// so that the certificates resolvers can share the entry point of their HTTP challenge.
 func (p *Provider) Append(router *mux.Router) {
-	router.Methods(http.MethodGet).
-		Path(http01.ChallengePath("{token}")).
-		Handler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
-			vars := mux.Vars(req)
+	route := router.Methods(http.MethodGet).
+		Path(http01.ChallengePath("{token}"))
+
+	if p.ResolverName != "" {
+		route.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
+			domain, _, err := net.SplitHostPort(req.Host)
+			if err != nil {
+				domain = req.Host
+			}
 
-			ctx := log.With(context.Background(), log.Str(log.ProviderName, "acme"))
-			logger := log.FromContext(ctx)
+			_, err = p.Store.GetHTTPChallengeToken(strings.TrimPrefix(req.URL.Path, http01.ChallengePath("")), domain)
+			return err == nil
+		})
+	}
 
-			if token, ok := vars["token"]; ok {
-				domain, _, err := net.SplitHostPort(req.Host)
-				if err != nil {
-					logger.Debugf("Unable to split host and port: %v. Fallback to request host.", err)
-					domain = req.Host
-				}
+	route.Handler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
 
-				tokenValue := getTokenValue(ctx, token, domain, p.Store)
-				if len(tokenValue) > 0 {
-					rw.WriteHeader(http.StatusOK)
-					_, err = rw.Write(tokenValue)
-					if err != nil {
-						logger.Errorf("Unable to write token: %v", err)
-					}
-					return
+		ctx := log.With(context.Background(), log.Str(log.ProviderName, "acme"))
+		logger := log.FromContext(ctx)
+
+		if token, ok := vars["token"]; ok {
+			domain, _, err := net.SplitHostPort(req.Host)
+			if err != nil {
+				logger.Debugf("Unable to split host and port: %v. Fallback to request host.", err)
+				domain = req.Host
+			}
+
+			tokenValue := getTokenValue(ctx, token, domain, p.Store)
+			if len(tokenValue) > 0 {
+				rw.WriteHeader(http.StatusOK)
+				_, err = rw.Write(tokenValue)
+				if err != nil {
+					logger.Errorf("Unable to write token: %v", err)
 				}
+				return
 			}
-			rw.WriteHeader(http.StatusNotFound)
-		}))
+		}
+		rw.WriteHeader(http.StatusNotFound)
+	}))
 }
 
 func getTokenValue(ctx context.Context, token, domain string, store Store) []byte {
@@ -84,6 +84,8 @@ type TLSChallenge struct{}
 // Provider holds configurations of the provider.
 type Provider struct {
 	*Configuration
+	// ResolverName is the name of the certificates resolver, empty for the ACME section of the static configuration.
+	ResolverName           string
 	Store                  Store
 	certificates           []*Certificate
 	account                *Account
@@ -108,6 +110,29 @@ func (p *Provider) SetConfigListenerChan(configFromListenerChan chan config.Conf
 	p.configFromListenerChan = configFromListenerChan
 }
 
+// ListensToRouters tells whether the provider obtains the certificates of the routers:
+// a certificates resolver obtains the ones of the routers referencing it,
+// and the ACME section of the static configuration the ones of the routers referencing none, with OnHostRule.
+func (p *Provider) ListensToRouters() bool {
+	return p.ResolverName != "" || p.OnHostRule
+}
+
+// resolvesRouter tells whether the provider obtains the certificates of a router referencing the certificates resolver.
+func (p *Provider) resolvesRouter(certResolver string) bool {
+	if p.ResolverName == "" {
+		return p.OnHostRule && certResolver == ""
+	}
+	return certResolver == p.ResolverName
+}
+
+// providerName is the name of the configurations of the provider, which is the name of its certificates resolver if any.
+func (p *Provider) providerName() string {
+	if p.ResolverName == "" {
+		return "ACME"
+	}
+	return "ACME-" + p.ResolverName
+}
+
 // ListenConfiguration sets a new Configuration into the configFromListenerChan
 func (p *Provider) ListenConfiguration(config config.Configuration) {
 	p.configFromListenerChan <- config
@@ -374,9 +399,10 @@ func (p *Provider) watchNewDomains(ctx context.Context) {
 		for {
 			select {
 			case config := <-p.configFromListenerChan:
-				if config.TCP != nil {
+				if config.TCP != nil && p.ResolverName == "" {
 					for routerName, route := range config.TCP.Routers {
-						if route.TLS == nil {
+						// the fallback routers have no rule, and so no domain.
+						if route.TLS == nil || route.Fallback {
 							continue
 						}
 						ctxRouter := log.With(ctx, log.Str(log.RouterName, routerName), log.Str(log.Rule, route.Rule))
@@ -391,17 +417,11 @@ func (p *Provider) watchNewDomains(ctx context.Context) {
 				}
 
 				for routerName, route := range config.HTTP.Routers {
-					if route.TLS == nil {
-						continue
-					}
 					ctxRouter := log.With(ctx, log.Str(log.RouterName, routerName), log.Str(log.Rule, route.Rule))
 
-					domains, err := rules.ParseDomains(route.Rule)
-					if err != nil {
-						log.FromContext(ctxRouter).Errorf("Error parsing domains in provider ACME: %v", err)
-						continue
+					for _, domains := range p.getRouterDomains(ctxRouter, route) {
+						p.resolveDomains(ctxRouter, domains)
 					}
-					p.resolveDomains(ctxRouter, domains)
 				}
 			case <-stop:
 				return
@@ -410,6 +430,42 @@ func (p *Provider) watchNewDomains(ctx context.Context) {
 	})
 }
 
+// getRouterDomains returns the domains of the certificates to obtain for the router,
+// which are the ones of its tls.domains option, or else the ones of its rule.
+func (p *Provider) getRouterDomains(ctx context.Context, route *config.Router) [][]string {
+	if route.TLS == nil || !p.resolvesRouter(route.TLS.CertResolver) {
+		return nil
+	}
+
+	if len(route.TLS.Domains) > 0 {
+		var domains [][]string
+		for _, domain := range route.TLS.Domains {
+			domains = append(domains, domain.ToStrArray())
+		}
+		return domains
+	}
+
+	// the fallback routers have no rule, their domains can only be set in the tls.domains option.
+	if route.Fallback {
+		return nil
+	}
+
+	domains, err := rules.ParseDomains(route.Rule)
+	if err != nil {
+		log.FromContext(ctx).Errorf("Error parsing domains in provider ACME: %v", err)
+		return nil
+	}
+
+	if len(domains) == 0 {
+		if p.ResolverName != "" {
+			log.FromContext(ctx).Warnf("No domain found in rule %s: the domains of the certificates resolver %s must be set in the tls.domains option of the router", route.Rule, p.ResolverName)
+		}
+		return nil
+	}
+
+	return [][]string{domains}
+}
+
 func (p *Provider) resolveCertificate(ctx context.Context, domain types.Domain, domainFromConfigurationFile bool) (*certificate.Resource, error) {
 	domains, err := p.getValidDomains(ctx, domain, domainFromConfigurationFile)
 	if err != nil {
@@ -582,7 +638,7 @@ func (p *Provider) saveCertificates() error {
 
 func (p *Provider) refreshCertificates() {
 	conf := config.Message{
-		ProviderName: "ACME",
+		ProviderName: p.providerName(),
 		Configuration: &config.Configuration{
 			HTTP: &config.HTTPConfiguration{
 				Routers:     map[string]*config.Router{},
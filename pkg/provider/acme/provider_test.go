@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"testing"
 
+	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/safe"
 	"github.com/containous/traefik/pkg/types"
 	"github.com/go-acme/lego/certcrypto"
@@ -522,6 +523,111 @@ func TestIsAccountMatchingCaServer(t *testing.T) {
 	}
 }
 
+func TestGetRouterDomains(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		resolverName string
+		onHostRule   bool
+		router       *config.Router
+		expected     [][]string
+	}{
+		{
+			desc:         "router without TLS",
+			resolverName: "letsencrypt",
+			router:       &config.Router{Rule: "Host(`foo.com`)"},
+		},
+		{
+			desc:         "router referencing the resolver",
+			resolverName: "letsencrypt",
+			router: &config.Router{
+				Rule: "Host(`foo.com`, `bar.com`)",
+				TLS:  &config.RouterTLSConfig{CertResolver: "letsencrypt"},
+			},
+			expected: [][]string{{"foo.com", "bar.com"}},
+		},
+		{
+			desc:         "router referencing another resolver",
+			resolverName: "letsencrypt",
+			router: &config.Router{
+				Rule: "Host(`foo.com`)",
+				TLS:  &config.RouterTLSConfig{CertResolver: "other"},
+			},
+		},
+		{
+			desc:         "router referencing no resolver",
+			resolverName: "letsencrypt",
+			onHostRule:   true,
+			router: &config.Router{
+				Rule: "Host(`foo.com`)",
+				TLS:  &config.RouterTLSConfig{},
+			},
+		},
+		{
+			desc:         "router with domains",
+			resolverName: "letsencrypt",
+			router: &config.Router{
+				Rule: "Host(`foo.com`)",
+				TLS: &config.RouterTLSConfig{
+					CertResolver: "letsencrypt",
+					Domains: []types.Domain{
+						{Main: "example.com", SANs: []string{"*.example.com"}},
+						{Main: "example.org"},
+					},
+				},
+			},
+			expected: [][]string{{"example.com", "*.example.com"}, {"example.org"}},
+		},
+		{
+			desc:         "router without domain",
+			resolverName: "letsencrypt",
+			router: &config.Router{
+				Rule: "PathPrefix(`/foo`)",
+				TLS:  &config.RouterTLSConfig{CertResolver: "letsencrypt"},
+			},
+		},
+		{
+			desc:       "ACME section with OnHostRule, and router referencing no resolver",
+			onHostRule: true,
+			router: &config.Router{
+				Rule: "Host(`foo.com`)",
+				TLS:  &config.RouterTLSConfig{},
+			},
+			expected: [][]string{{"foo.com"}},
+		},
+		{
+			desc:       "ACME section with OnHostRule, and router referencing a resolver",
+			onHostRule: true,
+			router: &config.Router{
+				Rule: "Host(`foo.com`)",
+				TLS:  &config.RouterTLSConfig{CertResolver: "letsencrypt"},
+			},
+		},
+		{
+			desc: "ACME section without OnHostRule",
+			router: &config.Router{
+				Rule: "Host(`foo.com`)",
+				TLS:  &config.RouterTLSConfig{},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			acmeProvider := Provider{
+				Configuration: &Configuration{OnHostRule: test.onHostRule},
+				ResolverName:  test.resolverName,
+			}
+
+			domains := acmeProvider.getRouterDomains(context.Background(), test.router)
+
+			assert.Equal(t, test.expected, domains)
+		})
+	}
+}
+
 func TestInitAccount(t *testing.T) {
 	testCases := []struct {
 		desc            string
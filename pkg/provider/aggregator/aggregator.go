@@ -6,6 +6,7 @@ import (
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/config/static"
 	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/metrics"
 	"github.com/containous/traefik/pkg/provider"
 	"github.com/containous/traefik/pkg/provider/file"
 	"github.com/containous/traefik/pkg/safe"
@@ -79,6 +80,19 @@ func (p ProviderAggregator) Init() error {
 	return nil
 }
 
+// SetMetricsRegistry passes the metrics registry to the providers which report metrics.
+func (p ProviderAggregator) SetMetricsRegistry(registry metrics.Registry) {
+	if p.fileProvider != nil {
+		p.fileProvider.SetMetricsRegistry(registry)
+	}
+
+	for _, prd := range p.providers {
+		if metricsAware, ok := prd.(provider.MetricsAware); ok {
+			metricsAware.SetMetricsRegistry(registry)
+		}
+	}
+}
+
 // Provide calls the provide method of every providers
 func (p ProviderAggregator) Provide(configurationChan chan<- config.Message, pool *safe.Pool) error {
 	if p.fileProvider != nil {
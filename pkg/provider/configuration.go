@@ -11,10 +11,13 @@ import (
 
 	"github.com/Masterminds/sprig"
 	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/config/label"
 	"github.com/containous/traefik/pkg/log"
 )
 
 // Merge Merges multiple configurations.
+// The configurations are validated first, as they are complete at this point:
+// their invalid elements are dropped.
 func Merge(ctx context.Context, configurations map[string]*config.Configuration) *config.Configuration {
 	logger := log.FromContext(ctx)
 
@@ -42,9 +45,26 @@ func Merge(ctx context.Context, configurations map[string]*config.Configuration)
 	routersTCPToDelete := map[string]struct{}{}
 	routersTCP := map[string][]string{}
 
+	servicesUDPToDelete := map[string]struct{}{}
+	servicesUDP := map[string][]string{}
+
+	routersUDPToDelete := map[string]struct{}{}
+	routersUDP := map[string][]string{}
+
 	middlewaresToDelete := map[string]struct{}{}
 	middlewares := map[string][]string{}
 
+	transportsToDelete := map[string]struct{}{}
+	transports := map[string][]string{}
+
+	servicesDescriptionConflicts := newDescriptionConflicts("Service", log.ServiceName)
+	routersDescriptionConflicts := newDescriptionConflicts("Router", log.RouterName)
+	servicesTCPDescriptionConflicts := newDescriptionConflicts("Service TCP", log.ServiceName)
+	routersTCPDescriptionConflicts := newDescriptionConflicts("Router TCP", log.RouterName)
+	servicesUDPDescriptionConflicts := newDescriptionConflicts("Service UDP", log.ServiceName)
+	routersUDPDescriptionConflicts := newDescriptionConflicts("Router UDP", log.RouterName)
+	middlewaresDescriptionConflicts := newDescriptionConflicts("Middleware", log.MiddlewareName)
+
 	var sortedKeys []string
 	for key := range configurations {
 		sortedKeys = append(sortedKeys, key)
@@ -53,8 +73,16 @@ func Merge(ctx context.Context, configurations map[string]*config.Configuration)
 
 	for _, root := range sortedKeys {
 		conf := configurations[root]
+
+		for _, err := range label.ValidateConfiguration(conf) {
+			logger.Errorf("Skipping invalid element %s, from %s", err, root)
+		}
+
 		for serviceName, service := range conf.HTTP.Services {
 			services[serviceName] = append(services[serviceName], root)
+			if existing, ok := configuration.HTTP.Services[serviceName]; ok {
+				servicesDescriptionConflicts.check(serviceName, existing.Description, service.Description)
+			}
 			if !AddService(configuration.HTTP, serviceName, service) {
 				servicesToDelete[serviceName] = struct{}{}
 			}
@@ -62,6 +90,9 @@ func Merge(ctx context.Context, configurations map[string]*config.Configuration)
 
 		for routerName, router := range conf.HTTP.Routers {
 			routers[routerName] = append(routers[routerName], root)
+			if existing, ok := configuration.HTTP.Routers[routerName]; ok {
+				routersDescriptionConflicts.check(routerName, existing.Description, router.Description)
+			}
 			if !AddRouter(configuration.HTTP, routerName, router) {
 				routersToDelete[routerName] = struct{}{}
 			}
@@ -69,6 +100,9 @@ func Merge(ctx context.Context, configurations map[string]*config.Configuration)
 
 		for serviceName, service := range conf.TCP.Services {
 			servicesTCP[serviceName] = append(servicesTCP[serviceName], root)
+			if existing, ok := configuration.TCP.Services[serviceName]; ok {
+				servicesTCPDescriptionConflicts.check(serviceName, existing.Description, service.Description)
+			}
 			if !AddServiceTCP(configuration.TCP, serviceName, service) {
 				servicesTCPToDelete[serviceName] = struct{}{}
 			}
@@ -76,17 +110,65 @@ func Merge(ctx context.Context, configurations map[string]*config.Configuration)
 
 		for routerName, router := range conf.TCP.Routers {
 			routersTCP[routerName] = append(routersTCP[routerName], root)
+			if existing, ok := configuration.TCP.Routers[routerName]; ok {
+				routersTCPDescriptionConflicts.check(routerName, existing.Description, router.Description)
+			}
 			if !AddRouterTCP(configuration.TCP, routerName, router) {
 				routersTCPToDelete[routerName] = struct{}{}
 			}
 		}
 
+		if conf.UDP != nil {
+			// The UDP section is only present in the merged configuration when at least one configuration defines it.
+			if configuration.UDP == nil {
+				configuration.UDP = &config.UDPConfiguration{
+					Routers:  make(map[string]*config.UDPRouter),
+					Services: make(map[string]*config.UDPService),
+				}
+			}
+
+			for serviceName, service := range conf.UDP.Services {
+				servicesUDP[serviceName] = append(servicesUDP[serviceName], root)
+				if existing, ok := configuration.UDP.Services[serviceName]; ok {
+					servicesUDPDescriptionConflicts.check(serviceName, existing.Description, service.Description)
+				}
+				if !AddServiceUDP(configuration.UDP, serviceName, service) {
+					servicesUDPToDelete[serviceName] = struct{}{}
+				}
+			}
+
+			for routerName, router := range conf.UDP.Routers {
+				routersUDP[routerName] = append(routersUDP[routerName], root)
+				if existing, ok := configuration.UDP.Routers[routerName]; ok {
+					routersUDPDescriptionConflicts.check(routerName, existing.Description, router.Description)
+				}
+				if !AddRouterUDP(configuration.UDP, routerName, router) {
+					routersUDPToDelete[routerName] = struct{}{}
+				}
+			}
+		}
+
 		for middlewareName, middleware := range conf.HTTP.Middlewares {
 			middlewares[middlewareName] = append(middlewares[middlewareName], root)
+			if existing, ok := configuration.HTTP.Middlewares[middlewareName]; ok {
+				middlewaresDescriptionConflicts.check(middlewareName, existing.Description, middleware.Description)
+			}
 			if !AddMiddleware(configuration.HTTP, middlewareName, middleware) {
 				middlewaresToDelete[middlewareName] = struct{}{}
 			}
 		}
+
+		// The servers transports are only present in the merged configuration when at least one configuration defines them.
+		if len(conf.HTTP.ServersTransports) > 0 && configuration.HTTP.ServersTransports == nil {
+			configuration.HTTP.ServersTransports = make(map[string]*config.ServersTransport)
+		}
+
+		for transportName, transport := range conf.HTTP.ServersTransports {
+			transports[transportName] = append(transports[transportName], root)
+			if !AddServersTransport(configuration.HTTP, transportName, transport) {
+				transportsToDelete[transportName] = struct{}{}
+			}
+		}
 	}
 
 	for serviceName := range servicesToDelete {
@@ -113,12 +195,38 @@ func Merge(ctx context.Context, configurations map[string]*config.Configuration)
 		delete(configuration.TCP.Routers, routerName)
 	}
 
+	for serviceName := range servicesUDPToDelete {
+		logger.WithField(log.ServiceName, serviceName).
+			Errorf("Service UDP defined multiple times with different configurations in %v", servicesUDP[serviceName])
+		delete(configuration.UDP.Services, serviceName)
+	}
+
+	for routerName := range routersUDPToDelete {
+		logger.WithField(log.RouterName, routerName).
+			Errorf("Router UDP defined multiple times with different configurations in %v", routersUDP[routerName])
+		delete(configuration.UDP.Routers, routerName)
+	}
+
 	for middlewareName := range middlewaresToDelete {
 		logger.WithField(log.MiddlewareName, middlewareName).
 			Errorf("Middleware defined multiple times with different configurations in %v", middlewares[middlewareName])
 		delete(configuration.HTTP.Middlewares, middlewareName)
 	}
 
+	for transportName := range transportsToDelete {
+		logger.WithField(log.ServersTransport, transportName).
+			Errorf("Servers transport defined multiple times with different configurations in %v", transports[transportName])
+		delete(configuration.HTTP.ServersTransports, transportName)
+	}
+
+	servicesDescriptionConflicts.warn(logger, services, servicesToDelete)
+	routersDescriptionConflicts.warn(logger, routers, routersToDelete)
+	servicesTCPDescriptionConflicts.warn(logger, servicesTCP, servicesTCPToDelete)
+	routersTCPDescriptionConflicts.warn(logger, routersTCP, routersTCPToDelete)
+	servicesUDPDescriptionConflicts.warn(logger, servicesUDP, servicesUDPToDelete)
+	routersUDPDescriptionConflicts.warn(logger, routersUDP, routersUDPToDelete)
+	middlewaresDescriptionConflicts.warn(logger, middlewares, middlewaresToDelete)
+
 	return configuration
 }
 
@@ -134,6 +242,7 @@ func AddServiceTCP(configuration *config.TCPConfiguration, serviceName string, s
 	}
 
 	configuration.Services[serviceName].LoadBalancer.Servers = append(configuration.Services[serviceName].LoadBalancer.Servers, service.LoadBalancer.Servers...)
+	mergeDescription(&configuration.Services[serviceName].Description, service.Description)
 	return true
 }
 
@@ -144,11 +253,16 @@ func AddRouterTCP(configuration *config.TCPConfiguration, routerName string, rou
 		return true
 	}
 
-	return reflect.DeepEqual(configuration.Routers[routerName], router)
+	if !configuration.Routers[routerName].Mergeable(router) {
+		return false
+	}
+
+	mergeDescription(&configuration.Routers[routerName].Description, router.Description)
+	return true
 }
 
-// AddService Adds a service to a configurations.
-func AddService(configuration *config.HTTPConfiguration, serviceName string, service *config.Service) bool {
+// AddServiceUDP Adds a service to a configurations.
+func AddServiceUDP(configuration *config.UDPConfiguration, serviceName string, service *config.UDPService) bool {
 	if _, ok := configuration.Services[serviceName]; !ok {
 		configuration.Services[serviceName] = service
 		return true
@@ -159,6 +273,51 @@ func AddService(configuration *config.HTTPConfiguration, serviceName string, ser
 	}
 
 	configuration.Services[serviceName].LoadBalancer.Servers = append(configuration.Services[serviceName].LoadBalancer.Servers, service.LoadBalancer.Servers...)
+	mergeDescription(&configuration.Services[serviceName].Description, service.Description)
+	return true
+}
+
+// AddRouterUDP Adds a router to a configurations.
+func AddRouterUDP(configuration *config.UDPConfiguration, routerName string, router *config.UDPRouter) bool {
+	if _, ok := configuration.Routers[routerName]; !ok {
+		configuration.Routers[routerName] = router
+		return true
+	}
+
+	if !configuration.Routers[routerName].Mergeable(router) {
+		return false
+	}
+
+	mergeDescription(&configuration.Routers[routerName].Description, router.Description)
+	return true
+}
+
+// AddService Adds a service to a configurations.
+func AddService(configuration *config.HTTPConfiguration, serviceName string, service *config.Service) bool {
+	if _, ok := configuration.Services[serviceName]; !ok {
+		configuration.Services[serviceName] = service
+		return true
+	}
+
+	existing := configuration.Services[serviceName]
+
+	// Only the servers of the load-balancers are merged: the other services must be identical.
+	if !reflect.DeepEqual(existing.Weighted, service.Weighted) || !reflect.DeepEqual(existing.Mirroring, service.Mirroring) ||
+		!reflect.DeepEqual(existing.Failover, service.Failover) {
+		return false
+	}
+
+	if existing.LoadBalancer != nil && service.LoadBalancer != nil {
+		if !existing.LoadBalancer.Mergeable(service.LoadBalancer) {
+			return false
+		}
+
+		existing.LoadBalancer.Servers = append(existing.LoadBalancer.Servers, service.LoadBalancer.Servers...)
+	} else if existing.LoadBalancer != service.LoadBalancer {
+		return false
+	}
+
+	mergeDescription(&existing.Description, service.Description)
 	return true
 }
 
@@ -169,7 +328,12 @@ func AddRouter(configuration *config.HTTPConfiguration, routerName string, route
 		return true
 	}
 
-	return reflect.DeepEqual(configuration.Routers[routerName], router)
+	if !configuration.Routers[routerName].Mergeable(router) {
+		return false
+	}
+
+	mergeDescription(&configuration.Routers[routerName].Description, router.Description)
+	return true
 }
 
 // AddMiddleware Adds a middleware to a configurations.
@@ -179,7 +343,65 @@ func AddMiddleware(configuration *config.HTTPConfiguration, middlewareName strin
 		return true
 	}
 
-	return reflect.DeepEqual(configuration.Middlewares[middlewareName], middleware)
+	if !configuration.Middlewares[middlewareName].Mergeable(middleware) {
+		return false
+	}
+
+	mergeDescription(&configuration.Middlewares[middlewareName].Description, middleware.Description)
+	return true
+}
+
+// AddServersTransport Adds a servers transport to a configurations.
+func AddServersTransport(configuration *config.HTTPConfiguration, transportName string, transport *config.ServersTransport) bool {
+	if _, ok := configuration.ServersTransports[transportName]; !ok {
+		configuration.ServersTransports[transportName] = transport
+		return true
+	}
+
+	return reflect.DeepEqual(configuration.ServersTransports[transportName], transport)
+}
+
+// mergeDescription keeps the first non-empty description.
+func mergeDescription(current *string, description string) {
+	if len(*current) == 0 {
+		*current = description
+	}
+}
+
+// descriptionConflicts records the elements of a section defined multiple times with different descriptions,
+// with the description they use: the first non-empty one, kept by mergeDescription.
+type descriptionConflicts struct {
+	kind         string
+	field        string
+	descriptions map[string]string
+}
+
+func newDescriptionConflicts(kind, field string) *descriptionConflicts {
+	return &descriptionConflicts{kind: kind, field: field, descriptions: make(map[string]string)}
+}
+
+// check records the element when its description conflicts with the one of the element already merged.
+func (c *descriptionConflicts) check(name, current, description string) {
+	if descriptionsConflict(current, description) {
+		c.descriptions[name] = current
+	}
+}
+
+// warn logs the conflicts of the elements kept in the merged configuration, i.e. which are not deleted.
+func (c *descriptionConflicts) warn(logger log.Logger, origins map[string][]string, deleted map[string]struct{}) {
+	for name, description := range c.descriptions {
+		if _, ok := deleted[name]; ok {
+			continue
+		}
+
+		logger.WithField(c.field, name).
+			Warnf("%s defined multiple times with different descriptions in %v, using %q", c.kind, origins[name], description)
+	}
+}
+
+// descriptionsConflict tells whether two elements defined multiple times have different non-empty descriptions.
+func descriptionsConflict(current, description string) bool {
+	return len(current) > 0 && len(description) > 0 && current != description
 }
 
 // MakeDefaultRuleTemplate Creates the default rule template.
@@ -198,7 +420,7 @@ func MakeDefaultRuleTemplate(defaultRule string, funcMap template.FuncMap) (*tem
 func BuildTCPRouterConfiguration(ctx context.Context, configuration *config.TCPConfiguration) {
 	for routerName, router := range configuration.Routers {
 		loggerRouter := log.FromContext(ctx).WithField(log.RouterName, routerName)
-		if len(router.Rule) == 0 {
+		if len(router.Rule) == 0 && !router.Fallback {
 			delete(configuration.Routers, routerName)
 			loggerRouter.Errorf("Empty rule")
 			continue
@@ -219,6 +441,27 @@ func BuildTCPRouterConfiguration(ctx context.Context, configuration *config.TCPC
 	}
 }
 
+// BuildUDPRouterConfiguration Builds a router configuration.
+// UDP routers have no rule: a router without service is bound to the only service of the configuration, if any.
+func BuildUDPRouterConfiguration(ctx context.Context, configuration *config.UDPConfiguration) {
+	for routerName, router := range configuration.Routers {
+		if len(router.Service) > 0 {
+			continue
+		}
+
+		if len(configuration.Services) > 1 {
+			delete(configuration.Routers, routerName)
+			log.FromContext(ctx).WithField(log.RouterName, routerName).
+				Error("Could not define the service name for the router: too many services")
+			continue
+		}
+
+		for serviceName := range configuration.Services {
+			router.Service = serviceName
+		}
+	}
+}
+
 // BuildRouterConfiguration Builds a router configuration.
 func BuildRouterConfiguration(ctx context.Context, configuration *config.HTTPConfiguration, defaultRouterName string, defaultRuleTpl *template.Template, model interface{}) {
 	if len(configuration.Routers) == 0 {
@@ -232,7 +475,8 @@ func BuildRouterConfiguration(ctx context.Context, configuration *config.HTTPCon
 
 	for routerName, router := range configuration.Routers {
 		loggerRouter := log.FromContext(ctx).WithField(log.RouterName, routerName)
-		if len(router.Rule) == 0 {
+		// A fallback router has no rule, it serves the requests which match no other router.
+		if len(router.Rule) == 0 && !router.Fallback {
 			writer := &bytes.Buffer{}
 			if err := defaultRuleTpl.Execute(writer, model); err != nil {
 				loggerRouter.Errorf("Error while parsing default rule: %v", err)
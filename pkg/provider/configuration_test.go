@@ -0,0 +1,304 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeDescription(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		configurations map[string]*config.Configuration
+		expected       *config.Configuration
+	}{
+		{
+			desc: "description is ignored when comparing routers",
+			configurations: map[string]*config.Configuration{
+				"provider-1": {
+					HTTP: &config.HTTPConfiguration{
+						Routers: map[string]*config.Router{
+							"router": {Rule: "Host(`foo`)", Service: "service"},
+						},
+					},
+				},
+				"provider-2": {
+					HTTP: &config.HTTPConfiguration{
+						Routers: map[string]*config.Router{
+							"router": {Description: "my router", Rule: "Host(`foo`)", Service: "service"},
+						},
+					},
+				},
+			},
+			expected: &config.Configuration{
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"router": {Description: "my router", Rule: "Host(`foo`)", Service: "service"},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+			},
+		},
+		{
+			desc: "first non-empty description wins on conflict",
+			configurations: map[string]*config.Configuration{
+				"provider-1": {
+					HTTP: &config.HTTPConfiguration{
+						Middlewares: map[string]*config.Middleware{
+							"middleware": {Description: "first", AddPrefix: &config.AddPrefix{Prefix: "/foo"}},
+						},
+					},
+				},
+				"provider-2": {
+					HTTP: &config.HTTPConfiguration{
+						Middlewares: map[string]*config.Middleware{
+							"middleware": {Description: "second", AddPrefix: &config.AddPrefix{Prefix: "/foo"}},
+						},
+					},
+				},
+			},
+			expected: &config.Configuration{
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{
+						"middleware": {Description: "first", AddPrefix: &config.AddPrefix{Prefix: "/foo"}},
+					},
+					Services: map[string]*config.Service{},
+				},
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+			},
+		},
+		{
+			desc: "description is ignored when merging services",
+			configurations: map[string]*config.Configuration{
+				"provider-1": {
+					HTTP: &config.HTTPConfiguration{
+						Services: map[string]*config.Service{
+							"service": {
+								LoadBalancer: &config.LoadBalancerService{
+									Servers: []config.Server{{URL: "http://10.0.0.1"}},
+								},
+							},
+						},
+					},
+				},
+				"provider-2": {
+					HTTP: &config.HTTPConfiguration{
+						Services: map[string]*config.Service{
+							"service": {
+								Description: "my service",
+								LoadBalancer: &config.LoadBalancerService{
+									Servers: []config.Server{{URL: "http://10.0.0.2"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: &config.Configuration{
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"service": {
+							Description: "my service",
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{{URL: "http://10.0.0.1"}, {URL: "http://10.0.0.2"}},
+							},
+						},
+					},
+				},
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+			},
+		},
+		{
+			desc: "TCP routers differing by more than their description are dropped",
+			configurations: map[string]*config.Configuration{
+				"provider-1": {
+					TCP: &config.TCPConfiguration{
+						Routers: map[string]*config.TCPRouter{
+							"router": {Description: "same", Rule: "HostSNI(`foo`)", Service: "service"},
+						},
+					},
+				},
+				"provider-2": {
+					TCP: &config.TCPConfiguration{
+						Routers: map[string]*config.TCPRouter{
+							"router": {Description: "same", Rule: "HostSNI(`bar`)", Service: "service"},
+						},
+					},
+				},
+			},
+			expected: &config.Configuration{
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			for _, conf := range test.configurations {
+				if conf.HTTP == nil {
+					conf.HTTP = &config.HTTPConfiguration{}
+				}
+				if conf.TCP == nil {
+					conf.TCP = &config.TCPConfiguration{}
+				}
+			}
+
+			actual := Merge(context.Background(), test.configurations)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestMergeInvalidElements(t *testing.T) {
+	configurations := map[string]*config.Configuration{
+		"container-1": {
+			HTTP: &config.HTTPConfiguration{
+				Routers: map[string]*config.Router{
+					"router":  {Rule: "Host(`foo`)", Service: "service"},
+					"invalid": {Service: "service"},
+				},
+				Middlewares: map[string]*config.Middleware{
+					"invalid": {MaxConn: &config.MaxConn{}},
+				},
+				Services: map[string]*config.Service{
+					"service": {LoadBalancer: &config.LoadBalancerService{Servers: []config.Server{{URL: "http://127.0.0.1"}}}},
+				},
+			},
+			TCP: &config.TCPConfiguration{},
+		},
+	}
+
+	expected := &config.Configuration{
+		HTTP: &config.HTTPConfiguration{
+			Routers: map[string]*config.Router{
+				"router": {Rule: "Host(`foo`)", Service: "service"},
+			},
+			Middlewares: map[string]*config.Middleware{},
+			Services: map[string]*config.Service{
+				"service": {LoadBalancer: &config.LoadBalancerService{Servers: []config.Server{{URL: "http://127.0.0.1"}}}},
+			},
+		},
+		TCP: &config.TCPConfiguration{
+			Routers:  map[string]*config.TCPRouter{},
+			Services: map[string]*config.TCPService{},
+		},
+	}
+
+	assert.Equal(t, expected, Merge(context.Background(), configurations))
+}
+
+func TestMergeWeightedServices(t *testing.T) {
+	weighted := func(weight int) *config.Service {
+		return &config.Service{
+			Weighted: &config.WeightedService{
+				Services: []config.WeightedServiceRef{{Name: "v1", Weight: &weight}, {Name: "v2"}},
+			},
+		}
+	}
+
+	configurations := map[string]*config.Configuration{
+		"container-1": {
+			HTTP: &config.HTTPConfiguration{
+				Services: map[string]*config.Service{
+					"canary":   weighted(3),
+					"conflict": weighted(3),
+					"mixed":    weighted(3),
+				},
+			},
+			TCP: &config.TCPConfiguration{},
+		},
+		"container-2": {
+			HTTP: &config.HTTPConfiguration{
+				Services: map[string]*config.Service{
+					"canary":   weighted(3),
+					"conflict": weighted(1),
+					"mixed":    {LoadBalancer: &config.LoadBalancerService{Servers: []config.Server{{URL: "http://127.0.0.1"}}}},
+				},
+			},
+			TCP: &config.TCPConfiguration{},
+		},
+	}
+
+	expected := &config.Configuration{
+		HTTP: &config.HTTPConfiguration{
+			Routers:     map[string]*config.Router{},
+			Middlewares: map[string]*config.Middleware{},
+			Services: map[string]*config.Service{
+				"canary": weighted(3),
+			},
+		},
+		TCP: &config.TCPConfiguration{
+			Routers:  map[string]*config.TCPRouter{},
+			Services: map[string]*config.TCPService{},
+		},
+	}
+
+	assert.Equal(t, expected, Merge(context.Background(), configurations))
+}
+
+func TestMergeServersTransports(t *testing.T) {
+	configurations := map[string]*config.Configuration{
+		"container-1": {
+			HTTP: &config.HTTPConfiguration{
+				ServersTransports: map[string]*config.ServersTransport{
+					"secure":   {ServerName: "app.internal"},
+					"conflict": {MaxIdleConnsPerHost: 10},
+				},
+			},
+			TCP: &config.TCPConfiguration{},
+		},
+		"container-2": {
+			HTTP: &config.HTTPConfiguration{
+				ServersTransports: map[string]*config.ServersTransport{
+					"secure":   {ServerName: "app.internal"},
+					"conflict": {MaxIdleConnsPerHost: 20},
+				},
+			},
+			TCP: &config.TCPConfiguration{},
+		},
+	}
+
+	expected := &config.Configuration{
+		HTTP: &config.HTTPConfiguration{
+			Routers:     map[string]*config.Router{},
+			Middlewares: map[string]*config.Middleware{},
+			Services:    map[string]*config.Service{},
+			ServersTransports: map[string]*config.ServersTransport{
+				"secure": {ServerName: "app.internal"},
+			},
+		},
+		TCP: &config.TCPConfiguration{
+			Routers:  map[string]*config.TCPRouter{},
+			Services: map[string]*config.TCPService{},
+		},
+	}
+
+	assert.Equal(t, expected, Merge(context.Background(), configurations))
+}
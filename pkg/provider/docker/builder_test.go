@@ -80,6 +80,8 @@ func ipv4(ip string) func(*network.EndpointSettings) {
 func swarmTask(id string, ops ...func(*swarm.Task)) swarm.Task {
 	task := &swarm.Task{
 		ID: id,
+		// The vast majority of tests expect the task to be desired running.
+		DesiredState: swarm.TaskStateRunning,
 	}
 
 	for _, op := range ops {
@@ -95,6 +97,18 @@ func taskSlot(slot int) func(*swarm.Task) {
 	}
 }
 
+func taskDesiredState(state swarm.TaskState) func(*swarm.Task) {
+	return func(task *swarm.Task) {
+		task.DesiredState = state
+	}
+}
+
+func taskNodeID(id string) func(*swarm.Task) {
+	return func(task *swarm.Task) {
+		task.NodeID = id
+	}
+}
+
 func taskNetworkAttachment(id string, name string, driver string, addresses []string) func(*swarm.Task) {
 	return func(task *swarm.Task) {
 		task.NetworksAttachments = append(task.NetworksAttachments, swarm.NetworkAttachment{
@@ -163,12 +177,37 @@ func serviceName(name string) func(service *swarm.Service) {
 	}
 }
 
+func serviceUpdateState(state swarm.UpdateState) func(service *swarm.Service) {
+	return func(service *swarm.Service) {
+		service.UpdateStatus = &swarm.UpdateStatus{State: state}
+	}
+}
+
+func serviceUpdateOrder(order string) func(service *swarm.Service) {
+	return func(service *swarm.Service) {
+		service.Spec.UpdateConfig = &swarm.UpdateConfig{Order: order}
+		service.Spec.RollbackConfig = &swarm.UpdateConfig{Order: order}
+	}
+}
+
+func globalMode() func(service *swarm.Service) {
+	return func(service *swarm.Service) {
+		service.Spec.Mode.Global = &swarm.GlobalService{}
+	}
+}
+
 func serviceLabels(labels map[string]string) func(service *swarm.Service) {
 	return func(service *swarm.Service) {
 		service.Spec.Annotations.Labels = labels
 	}
 }
 
+func containerLabels(labels map[string]string) func(service *swarm.Service) {
+	return func(service *swarm.Service) {
+		service.Spec.TaskTemplate.ContainerSpec = &swarm.ContainerSpec{Labels: labels}
+	}
+}
+
 func withEndpoint(ops ...func(*swarm.Endpoint)) func(*swarm.Service) {
 	return func(service *swarm.Service) {
 		endpoint := &swarm.Endpoint{}
@@ -193,6 +232,27 @@ func virtualIP(networkID, addr string) func(*swarm.Endpoint) {
 	}
 }
 
+func publishedPort(targetPort, publishedPort uint32) func(*swarm.Endpoint) {
+	return func(endpoint *swarm.Endpoint) {
+		endpoint.Ports = append(endpoint.Ports, swarm.PortConfig{
+			Protocol:      swarm.PortConfigProtocolTCP,
+			TargetPort:    targetPort,
+			PublishedPort: publishedPort,
+			PublishMode:   swarm.PortConfigPublishModeIngress,
+		})
+	}
+}
+
+func swarmNode(id, addr string, state swarm.NodeState) swarm.Node {
+	return swarm.Node{
+		ID: id,
+		Status: swarm.NodeStatus{
+			State: state,
+			Addr:  addr,
+		},
+	}
+}
+
 func withEndpointSpec(ops ...func(*swarm.EndpointSpec)) func(*swarm.Service) {
 	return func(service *swarm.Service) {
 		endpointSpec := &swarm.EndpointSpec{}
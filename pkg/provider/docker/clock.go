@@ -0,0 +1,30 @@
+package docker
+
+import (
+	"time"
+)
+
+// clock abstracts the time, so that the polling loops of the provider can be driven by the tests.
+type clock interface {
+	NewTicker(d time.Duration) ticker
+}
+
+// ticker abstracts a time.Ticker.
+type ticker interface {
+	Chan() <-chan time.Time
+	Stop()
+}
+
+type realClock struct{}
+
+func (realClock) NewTicker(d time.Duration) ticker {
+	return &realTicker{Ticker: time.NewTicker(d)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t *realTicker) Chan() <-chan time.Time {
+	return t.C
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/containous/traefik/pkg/config"
@@ -16,6 +18,8 @@ import (
 
 func (p *Provider) buildConfiguration(ctx context.Context, containersInspected []dockerData) *config.Configuration {
 	configurations := make(map[string]*config.Configuration)
+	weighted := make(weightedServers)
+	redirect := false
 
 	for _, container := range containersInspected {
 		containerName := getServiceName(container) + "-" + container.ID
@@ -27,10 +31,12 @@ func (p *Provider) buildConfiguration(ctx context.Context, containersInspected [
 
 		logger := log.FromContext(ctxContainer)
 
-		confFromLabel, err := label.DecodeConfiguration(container.Labels)
-		if err != nil {
-			logger.Error(err)
-			continue
+		confFromLabel, labelErrs := label.DecodeConfigurationPerElement(container.Labels)
+		if len(labelErrs) > 0 {
+			for _, labelErr := range labelErrs {
+				logger.Errorf("Skipping invalid element %s", labelErr)
+			}
+			p.metricsRegistry.ProviderLabelErrorsCounter().With("provider", "docker").Add(float64(len(labelErrs)))
 		}
 
 		if len(confFromLabel.TCP.Routers) > 0 || len(confFromLabel.TCP.Services) > 0 {
@@ -42,13 +48,14 @@ func (p *Provider) buildConfiguration(ctx context.Context, containersInspected [
 			provider.BuildTCPRouterConfiguration(ctxContainer, confFromLabel.TCP)
 			if len(confFromLabel.HTTP.Routers) == 0 &&
 				len(confFromLabel.HTTP.Middlewares) == 0 &&
+				len(confFromLabel.HTTP.ServersTransports) == 0 &&
 				len(confFromLabel.HTTP.Services) == 0 {
 				configurations[containerName] = confFromLabel
 				continue
 			}
 		}
 
-		err = p.buildServiceConfiguration(ctxContainer, container, confFromLabel.HTTP)
+		err := p.buildServiceConfiguration(ctxContainer, container, confFromLabel.HTTP)
 		if err != nil {
 			logger.Error(err)
 			continue
@@ -64,18 +71,256 @@ func (p *Provider) buildConfiguration(ctx context.Context, containersInspected [
 			Labels: container.Labels,
 		}
 
-		provider.BuildRouterConfiguration(ctx, confFromLabel.HTTP, serviceName, p.defaultRuleTpl, model)
+		defaultRouter := len(confFromLabel.HTTP.Routers) == 0
+
+		if !defaultRouter || container.ExtraConf.Docker.CreateDefaultRouter {
+			provider.BuildRouterConfiguration(ctx, confFromLabel.HTTP, serviceName, p.defaultRuleTpl, model)
+		} else {
+			logger.Debug("Skipping the creation of the default router: it is disabled")
+		}
+
+		if defaultRouter && p.addRedirectRouter(ctxContainer, serviceName, confFromLabel.HTTP) {
+			redirect = true
+		}
+
+		p.addConcurrencyMiddleware(ctxContainer, container, serviceName, confFromLabel.HTTP)
+
+		weighted.add(container, confFromLabel.HTTP)
 
 		configurations[containerName] = confFromLabel
 	}
 
-	return provider.Merge(ctx, configurations)
+	weighted.balance()
+
+	configuration := provider.Merge(ctx, configurations)
+	sortServers(configuration)
+
+	if redirect {
+		p.addRedirectMiddleware(ctx, configuration.HTTP)
+	}
+
+	return configuration
+}
+
+// addConcurrencyMiddleware generates, from the value of the concurrency label, a MaxConn middleware attached to the default router of the container.
+// A middleware explicitly defined with the same name takes precedence.
+func (p *Provider) addConcurrencyMiddleware(ctx context.Context, container dockerData, serviceName string, configuration *config.HTTPConfiguration) {
+	if len(p.ConcurrencyLabel) == 0 {
+		return
+	}
+
+	value, ok := container.Labels[p.ConcurrencyLabel]
+	if !ok {
+		return
+	}
+
+	middlewareName := serviceName + "-concurrency"
+
+	if _, exists := configuration.Middlewares[middlewareName]; !exists {
+		amount, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || amount <= 0 {
+			log.FromContext(ctx).Errorf("Invalid value %q for the label %s: the value must be a positive integer", value, p.ConcurrencyLabel)
+			return
+		}
+
+		if configuration.Middlewares == nil {
+			configuration.Middlewares = make(map[string]*config.Middleware)
+		}
+
+		// The generated middleware uses the default extractor of MaxConn, i.e. the connections are counted by requested host.
+		maxConn := &config.MaxConn{}
+		maxConn.SetDefaults()
+		maxConn.Amount = amount
+
+		configuration.Middlewares[middlewareName] = &config.Middleware{
+			MaxConn: maxConn,
+		}
+	}
+
+	router, ok := configuration.Routers[serviceName]
+	if !ok {
+		log.FromContext(ctx).Debugf("No default router to attach the middleware %s to", middlewareName)
+		return
+	}
+
+	for _, name := range router.Middlewares {
+		if name == middlewareName {
+			return
+		}
+	}
+	router.Middlewares = append(router.Middlewares, middlewareName)
+}
+
+// redirectMiddlewareName is the name of the middleware shared by the routers generated by the AutoRedirect option.
+const redirectMiddlewareName = "autoredirect-https"
+
+// addRedirectRouter turns the default router into an HTTPS router,
+// and adds a sibling router bound to the insecure entry point, redirecting to HTTPS.
+// It tells whether the redirecting router was added.
+func (p *Provider) addRedirectRouter(ctx context.Context, routerName string, configuration *config.HTTPConfiguration) bool {
+	if p.AutoRedirect == nil || !p.AutoRedirect.Enable {
+		return false
+	}
+
+	router, ok := configuration.Routers[routerName]
+	if !ok {
+		return false
+	}
+
+	redirectRouterName := routerName + "-redirect"
+	if _, exists := configuration.Routers[redirectRouterName]; exists {
+		log.FromContext(ctx).WithField(log.RouterName, redirectRouterName).
+			Warn("Could not generate the redirecting router: a router with the same name already exists")
+		return false
+	}
+
+	if router.TLS == nil {
+		router.TLS = &config.RouterTLSConfig{}
+	}
+
+	configuration.Routers[redirectRouterName] = &config.Router{
+		EntryPoints: []string{p.AutoRedirect.EntryPoint},
+		Middlewares: []string{redirectMiddlewareName},
+		Service:     router.Service,
+		Rule:        router.Rule,
+	}
+
+	return true
+}
+
+// addRedirectMiddleware adds the middleware shared by the redirecting routers.
+func (p *Provider) addRedirectMiddleware(ctx context.Context, configuration *config.HTTPConfiguration) {
+	if _, exists := configuration.Middlewares[redirectMiddlewareName]; exists {
+		log.FromContext(ctx).Warnf("The middleware %s is already defined: it is used by the redirecting routers as is", redirectMiddlewareName)
+		return
+	}
+
+	if configuration.Middlewares == nil {
+		configuration.Middlewares = make(map[string]*config.Middleware)
+	}
+
+	configuration.Middlewares[redirectMiddlewareName] = &config.Middleware{
+		RedirectScheme: &config.RedirectScheme{
+			Scheme:    "https",
+			Permanent: p.AutoRedirect.Permanent,
+		},
+	}
+}
+
+// weightedServers holds the servers of each service.
+type weightedServers map[string]*serviceServers
+
+// serviceServers holds the servers of a service: the ones declaring a weight by deployment, and the other ones.
+type serviceServers struct {
+	deployments map[string][]*config.Server
+	unweighted  []*config.Server
+}
+
+// add records the servers of the container.
+// The containers are grouped by the traefik.docker.deployment label, and by default by the name of their service.
+func (w weightedServers) add(container dockerData, configuration *config.HTTPConfiguration) {
+	deployment := container.ExtraConf.Docker.Deployment
+	if deployment == "" {
+		deployment = getServiceName(container)
+	}
+
+	for serviceName, service := range configuration.Services {
+		if service.LoadBalancer == nil {
+			continue
+		}
+
+		for i := range service.LoadBalancer.Servers {
+			if _, ok := w[serviceName]; !ok {
+				w[serviceName] = &serviceServers{deployments: make(map[string][]*config.Server)}
+			}
+
+			server := &service.LoadBalancer.Servers[i]
+			if server.Weight <= 0 {
+				w[serviceName].unweighted = append(w[serviceName].unweighted, server)
+				continue
+			}
+
+			w[serviceName].deployments[deployment] = append(w[serviceName].deployments[deployment], server)
+		}
+	}
+}
+
+// balance spreads the weight of each deployment across its replicas,
+// so that the weights sum per deployment rather than per replica.
+// The weights are scaled by the least common multiple of the numbers of replicas, to remain integers,
+// and so is the default weight, 1, of the servers of the same service declaring no weight.
+func (w weightedServers) balance() {
+	for _, service := range w {
+		scale := 1
+		for _, servers := range service.deployments {
+			scale = lcm(scale, len(servers))
+		}
+
+		for _, servers := range service.deployments {
+			for _, server := range servers {
+				server.Weight = server.Weight * scale / len(servers)
+			}
+		}
+
+		if scale == 1 {
+			continue
+		}
+
+		for _, server := range service.unweighted {
+			server.Weight = scale
+		}
+	}
+}
+
+func lcm(a, b int) int {
+	return a / gcd(a, b) * b
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// sortServers sorts the servers of the services, so that identical environments always produce identical configurations.
+func sortServers(configuration *config.Configuration) {
+	for _, service := range configuration.HTTP.Services {
+		if service.LoadBalancer == nil {
+			continue
+		}
+		servers := service.LoadBalancer.Servers
+		sort.SliceStable(servers, func(i, j int) bool {
+			return servers[i].URL < servers[j].URL
+		})
+	}
+
+	for _, service := range configuration.TCP.Services {
+		if service.LoadBalancer == nil {
+			continue
+		}
+		servers := service.LoadBalancer.Servers
+		sort.SliceStable(servers, func(i, j int) bool {
+			return servers[i].Address < servers[j].Address
+		})
+	}
 }
 
 func (p *Provider) buildTCPServiceConfiguration(ctx context.Context, container dockerData, configuration *config.TCPConfiguration) error {
 	serviceName := getServiceName(container)
 
 	if len(configuration.Services) == 0 {
+		if !container.ExtraConf.Docker.CreateDefaultTCPService {
+			for routerName, router := range configuration.Routers {
+				if len(router.Service) == 0 {
+					delete(configuration.Routers, routerName)
+					log.FromContext(ctx).WithField(log.RouterName, routerName).
+						Warn("Could not define the service name for the router: the creation of a default TCP service is disabled")
+				}
+			}
+			return nil
+		}
+
 		configuration.Services = make(map[string]*config.TCPService)
 		lb := &config.TCPLoadBalancerService{}
 		configuration.Services[serviceName] = &config.TCPService{
@@ -106,6 +351,11 @@ func (p *Provider) buildServiceConfiguration(ctx context.Context, container dock
 	}
 
 	for _, service := range configuration.Services {
+		// The weighted, mirroring, and failover services reference other services, and have no servers of their own.
+		if service.LoadBalancer == nil {
+			continue
+		}
+
 		err := p.addServer(ctx, container, service.LoadBalancer)
 		if err != nil {
 			return err
@@ -155,7 +405,9 @@ func (p *Provider) addServerTCP(ctx context.Context, container dockerData, loadB
 	}
 
 	if serverPort != "" {
-		port = serverPort
+		if !isPortIndex(serverPort) && !container.Published {
+			port = serverPort
+		}
 		loadBalancer.Servers[0].Port = ""
 	}
 
@@ -182,7 +434,9 @@ func (p *Provider) addServer(ctx context.Context, container dockerData, loadBala
 	}
 
 	if serverPort != "" {
-		port = serverPort
+		if !isPortIndex(serverPort) && !container.Published {
+			port = serverPort
+		}
 		loadBalancer.Servers[0].Port = ""
 	}
 
@@ -202,7 +456,7 @@ func (p *Provider) getIPPort(ctx context.Context, container dockerData, serverPo
 	var ip, port string
 	usedBound := false
 
-	if p.UseBindPortIP {
+	if p.UseBindPortIP || container.Published {
 		portBinding, err := p.getPortBinding(container, serverPort)
 		switch {
 		case err != nil:
@@ -294,12 +548,44 @@ func getLBServerPort(loadBalancer *config.LoadBalancerService) string {
 	return ""
 }
 
+// getPort returns the explicitly specified port if any.
+// Otherwise it selects one of the ports exposed by the container:
+// the first one, unless an optional index ("index:N") is provided.
 func getPort(container dockerData, serverPort string) string {
-	if len(serverPort) > 0 {
+	if len(serverPort) > 0 && !isPortIndex(serverPort) {
 		return serverPort
 	}
 
+	ports := getExposedPorts(container)
+
+	portIndex := 0
+	if isPortIndex(serverPort) {
+		index, err := strconv.Atoi(strings.TrimPrefix(serverPort, "index:"))
+		if err != nil || index < 0 || index > len(ports)-1 {
+			return ""
+		}
+		portIndex = index
+	}
+
+	if len(ports) > 0 {
+		return ports[portIndex].Port()
+	}
+
+	return ""
+}
+
+// getExposedPorts returns the ports exposed by the container, sorted in ascending order.
+// For a container using the host network, the ports listed in the hostPorts label are used as is.
+func getExposedPorts(container dockerData) []nat.Port {
 	var ports []nat.Port
+
+	if container.NetworkSettings.NetworkMode.IsHost() && len(container.ExtraConf.Docker.HostPorts) > 0 {
+		for _, hostPort := range container.ExtraConf.Docker.HostPorts {
+			ports = append(ports, nat.Port(hostPort+"/tcp"))
+		}
+		return ports
+	}
+
 	for port := range container.NetworkSettings.Ports {
 		ports = append(ports, port)
 	}
@@ -309,12 +595,11 @@ func getPort(container dockerData, serverPort string) string {
 	}
 	nat.Sort(ports, less)
 
-	if len(ports) > 0 {
-		min := ports[0]
-		return min.Port()
-	}
+	return ports
+}
 
-	return ""
+func isPortIndex(serverPort string) bool {
+	return strings.HasPrefix(serverPort, "index:")
 }
 
 func getServiceName(container dockerData) string {
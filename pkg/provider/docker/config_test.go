@@ -2,14 +2,22 @@ package docker
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/metrics"
+	"github.com/containous/traefik/pkg/middlewares/requestdecorator"
+	"github.com/containous/traefik/pkg/rules"
+	"github.com/containous/traefik/pkg/testhelpers"
 	"github.com/containous/traefik/pkg/types"
 	docker "github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/swarm"
 	"github.com/docker/go-connections/nat"
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -315,8 +323,9 @@ func TestDefaultRule(t *testing.T) {
 			t.Parallel()
 
 			p := Provider{
-				ExposedByDefault: true,
-				DefaultRule:      test.defaultRule,
+				ExposedByDefault:    true,
+				CreateDefaultRouter: true,
+				DefaultRule:         test.defaultRule,
 			}
 
 			err := p.Init()
@@ -689,6 +698,56 @@ func Test_buildConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "one container with fallback router label",
+			containers: []dockerData{
+				{
+					ServiceName: "Test",
+					Name:        "Test",
+					Labels: map[string]string{
+						"traefik.http.routers.Router1.fallback": "true",
+					},
+					NetworkSettings: networkSettings{
+						Ports: nat.PortMap{
+							nat.Port("80/tcp"): []nat.PortBinding{},
+						},
+						Networks: map[string]*networkData{
+							"bridge": {
+								Name: "bridge",
+								Addr: "127.0.0.1",
+							},
+						},
+					},
+				},
+			},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Test": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://127.0.0.1:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+					Routers: map[string]*config.Router{
+						"Router1": {
+							Service:  "Test",
+							Fallback: true,
+						},
+					},
+				},
+			},
+		},
 		{
 			desc: "one container with rule label and one service",
 			containers: []dockerData{
@@ -1064,6 +1123,66 @@ func Test_buildConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "one container with Retry in label",
+			containers: []dockerData{
+				{
+					ServiceName: "Test",
+					Name:        "Test",
+					Labels: map[string]string{
+						"traefik.http.middlewares.Middleware1.retry.attempts":        "3",
+						"traefik.http.middlewares.Middleware1.retry.initialinterval": "100ms",
+						"traefik.http.middlewares.Middleware1.retry.pertrytimeout":   "2s",
+					},
+					NetworkSettings: networkSettings{
+						Ports: nat.PortMap{
+							nat.Port("80/tcp"): []nat.PortBinding{},
+						},
+						Networks: map[string]*networkData{
+							"bridge": {
+								Name: "bridge",
+								Addr: "127.0.0.1",
+							},
+						},
+					},
+				},
+			},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"Test": {
+							Service: "Test",
+							Rule:    "Host(`Test.traefik.wtf`)",
+						},
+					},
+					Services: map[string]*config.Service{
+						"Test": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://127.0.0.1:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+					Middlewares: map[string]*config.Middleware{
+						"Middleware1": {
+							Retry: &config.Retry{
+								Attempts:        3,
+								InitialInterval: types.Duration(100 * time.Millisecond),
+								PerTryTimeout:   types.Duration(2 * time.Second),
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			desc: "two containers with two identical middlewares",
 			containers: []dockerData{
@@ -2062,14 +2181,17 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "tcp with label",
+			desc: "router with TLS certificates resolver and domains",
 			containers: []dockerData{
 				{
 					ServiceName: "Test",
 					Name:        "Test",
 					Labels: map[string]string{
-						"traefik.tcp.routers.foo.rule": "HostSNI(`foo.bar`)",
-						"traefik.tcp.routers.foo.tls":  "true",
+						"traefik.http.routers.Router1.rule":                "PathPrefix(`/foo`)",
+						"traefik.http.routers.Router1.tls.certresolver":    "letsencrypt",
+						"traefik.http.routers.Router1.tls.domains[0].main": "foo.com",
+						"traefik.http.routers.Router1.tls.domains[0].sans": "*.foo.com, bar.com",
+						"traefik.http.routers.Router1.tls.domains[1].main": "foo.org",
 					},
 					NetworkSettings: networkSettings{
 						Ports: nat.PortMap{
@@ -2086,40 +2208,52 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers: map[string]*config.TCPRouter{
-						"foo": {
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"Router1": {
 							Service: "Test",
-							Rule:    "HostSNI(`foo.bar`)",
-							TLS:     &config.RouterTCPTLSConfig{},
+							Rule:    "PathPrefix(`/foo`)",
+							TLS: &config.RouterTLSConfig{
+								CertResolver: "letsencrypt",
+								Domains: []types.Domain{
+									{Main: "foo.com", SANs: []string{"*.foo.com", "bar.com"}},
+									{Main: "foo.org"},
+								},
+							},
 						},
 					},
-					Services: map[string]*config.TCPService{
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
 						"Test": {
-							LoadBalancer: &config.TCPLoadBalancerService{
-								Servers: []config.TCPServer{
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
 									{
-										Address: "127.0.0.1:80",
+										URL: "http://127.0.0.1:80",
 									},
 								},
+								PassHostHeader: true,
 							},
 						},
 					},
 				},
-				HTTP: &config.HTTPConfiguration{
-					Routers:     map[string]*config.Router{},
-					Middlewares: map[string]*config.Middleware{},
-					Services:    map[string]*config.Service{},
-				},
 			},
 		},
 		{
-			desc: "tcp with label without rule",
+			desc: "weighted service",
 			containers: []dockerData{
 				{
 					ServiceName: "Test",
 					Name:        "Test",
 					Labels: map[string]string{
-						"traefik.tcp.routers.foo.tls": "true",
+						"traefik.http.routers.Router1.rule":                        "Host(`foo.com`)",
+						"traefik.http.routers.Router1.service":                     "canary",
+						"traefik.http.services.canary.weighted.services[0].name":   "Test",
+						"traefik.http.services.canary.weighted.services[0].weight": "3",
+						"traefik.http.services.canary.weighted.services[1].name":   "file.legacy",
+						"traefik.http.services.Test.loadbalancer.server.port":      "80",
 					},
 					NetworkSettings: networkSettings{
 						Ports: nat.PortMap{
@@ -2136,36 +2270,49 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers: map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"Router1": {
+							Service: "canary",
+							Rule:    "Host(`foo.com`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"canary": {
+							Weighted: &config.WeightedService{
+								Services: []config.WeightedServiceRef{
+									{Name: "Test", Weight: intPtr(3)},
+									{Name: "file.legacy"},
+								},
+							},
+						},
 						"Test": {
-							LoadBalancer: &config.TCPLoadBalancerService{
-								Servers: []config.TCPServer{
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
 									{
-										Address: "127.0.0.1:80",
+										URL: "http://127.0.0.1:80",
 									},
 								},
+								PassHostHeader: true,
 							},
 						},
 					},
 				},
-				HTTP: &config.HTTPConfiguration{
-					Routers:     map[string]*config.Router{},
-					Middlewares: map[string]*config.Middleware{},
-					Services:    map[string]*config.Service{},
-				},
 			},
 		},
 		{
-			desc: "tcp with label and port",
+			desc: "tcp with label",
 			containers: []dockerData{
 				{
 					ServiceName: "Test",
 					Name:        "Test",
 					Labels: map[string]string{
-						"traefik.tcp.routers.foo.rule":                      "HostSNI(`foo.bar`)",
-						"traefik.tcp.routers.foo.tls":                       "true",
-						"traefik.tcp.services.foo.loadbalancer.server.port": "8080",
+						"traefik.tcp.routers.foo.rule": "HostSNI(`foo.bar`)",
+						"traefik.tcp.routers.foo.tls":  "true",
 					},
 					NetworkSettings: networkSettings{
 						Ports: nat.PortMap{
@@ -2184,17 +2331,17 @@ func Test_buildConfiguration(t *testing.T) {
 				TCP: &config.TCPConfiguration{
 					Routers: map[string]*config.TCPRouter{
 						"foo": {
-							Service: "foo",
+							Service: "Test",
 							Rule:    "HostSNI(`foo.bar`)",
 							TLS:     &config.RouterTCPTLSConfig{},
 						},
 					},
 					Services: map[string]*config.TCPService{
-						"foo": {
+						"Test": {
 							LoadBalancer: &config.TCPLoadBalancerService{
 								Servers: []config.TCPServer{
 									{
-										Address: "127.0.0.1:8080",
+										Address: "127.0.0.1:80",
 									},
 								},
 							},
@@ -2209,16 +2356,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "tcp with label and port and http service",
+			desc: "tcp with label without rule",
 			containers: []dockerData{
 				{
 					ServiceName: "Test",
 					Name:        "Test",
 					Labels: map[string]string{
-						"traefik.tcp.routers.foo.rule":                               "HostSNI(`foo.bar`)",
-						"traefik.tcp.routers.foo.tls":                                "true",
-						"traefik.tcp.services.foo.loadbalancer.server.port":          "8080",
-						"traefik.http.services.Service1.loadbalancer.passhostheader": "true",
+						"traefik.tcp.routers.foo.tls": "true",
 					},
 					NetworkSettings: networkSettings{
 						Ports: nat.PortMap{
@@ -2232,47 +2376,16 @@ func Test_buildConfiguration(t *testing.T) {
 						},
 					},
 				},
-				{
-					ID:          "2",
-					ServiceName: "Test",
-					Name:        "Test",
-					Labels: map[string]string{
-						"traefik.tcp.routers.foo.rule":                               "HostSNI(`foo.bar`)",
-						"traefik.tcp.routers.foo.tls":                                "true",
-						"traefik.tcp.services.foo.loadbalancer.server.port":          "8080",
-						"traefik.http.services.Service1.loadbalancer.passhostheader": "true",
-					},
-					NetworkSettings: networkSettings{
-						Ports: nat.PortMap{
-							nat.Port("80/tcp"): []nat.PortBinding{},
-						},
-						Networks: map[string]*networkData{
-							"bridge": {
-								Name: "bridge",
-								Addr: "127.0.0.2",
-							},
-						},
-					},
-				},
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers: map[string]*config.TCPRouter{
-						"foo": {
-							Service: "foo",
-							Rule:    "HostSNI(`foo.bar`)",
-							TLS:     &config.RouterTCPTLSConfig{},
-						},
-					},
+					Routers: map[string]*config.TCPRouter{},
 					Services: map[string]*config.TCPService{
-						"foo": {
+						"Test": {
 							LoadBalancer: &config.TCPLoadBalancerService{
 								Servers: []config.TCPServer{
 									{
-										Address: "127.0.0.1:8080",
-									},
-									{
-										Address: "127.0.0.2:8080",
+										Address: "127.0.0.1:80",
 									},
 								},
 							},
@@ -2280,39 +2393,24 @@ func Test_buildConfiguration(t *testing.T) {
 					},
 				},
 				HTTP: &config.HTTPConfiguration{
-					Routers: map[string]*config.Router{
-						"Test": {
-							Service: "Service1",
-							Rule:    "Host(`Test.traefik.wtf`)",
-						},
-					},
+					Routers:     map[string]*config.Router{},
 					Middlewares: map[string]*config.Middleware{},
-					Services: map[string]*config.Service{
-						"Service1": {
-							LoadBalancer: &config.LoadBalancerService{
-								Servers: []config.Server{
-									{
-										URL: "http://127.0.0.1:80",
-									},
-									{
-										URL: "http://127.0.0.2:80",
-									},
-								},
-								PassHostHeader: true,
-							},
-						},
-					},
+					Services:    map[string]*config.Service{},
 				},
 			},
 		},
 		{
-			desc: "tcp with label for tcp service",
+			desc: "tcp with label and default TCP service disabled",
 			containers: []dockerData{
 				{
 					ServiceName: "Test",
 					Name:        "Test",
 					Labels: map[string]string{
-						"traefik.tcp.services.foo.loadbalancer.server.port": "8080",
+						"traefik.tcp.routers.foo.rule":           "HostSNI(`foo.bar`)",
+						"traefik.tcp.routers.foo.tls":            "true",
+						"traefik.docker.createDefaultTCPService": "false",
+						"traefik.tcp.routers.bar.rule":           "HostSNI(`bar.foo`)",
+						"traefik.tcp.routers.bar.service":        "bar",
 					},
 					NetworkSettings: networkSettings{
 						Ports: nat.PortMap{
@@ -2329,18 +2427,13 @@ func Test_buildConfiguration(t *testing.T) {
 			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers: map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{
-						"foo": {
-							LoadBalancer: &config.TCPLoadBalancerService{
-								Servers: []config.TCPServer{
-									{
-										Address: "127.0.0.1:8080",
-									},
-								},
-							},
+					Routers: map[string]*config.TCPRouter{
+						"bar": {
+							Service: "bar",
+							Rule:    "HostSNI(`bar.foo`)",
 						},
 					},
+					Services: map[string]*config.TCPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -2349,11 +2442,811 @@ func Test_buildConfiguration(t *testing.T) {
 				},
 			},
 		},
-	}
-
-	for _, test := range testCases {
+		{
+			desc: "default router disabled",
+			containers: []dockerData{
+				{
+					ServiceName: "Test",
+					Name:        "Test",
+					Labels: map[string]string{
+						"traefik.docker.createDefaultRouter": "false",
+					},
+					NetworkSettings: networkSettings{
+						Ports: nat.PortMap{
+							nat.Port("80/tcp"): []nat.PortBinding{},
+						},
+						Networks: map[string]*networkData{
+							"bridge": {
+								Name: "bridge",
+								Addr: "127.0.0.1",
+							},
+						},
+					},
+				},
+			},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Test": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://127.0.0.1:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "default router disabled with an explicit router",
+			containers: []dockerData{
+				{
+					ServiceName: "Test",
+					Name:        "Test",
+					Labels: map[string]string{
+						"traefik.docker.createDefaultRouter":       "false",
+						"traefik.http.routers.Router1.entrypoints": "web",
+					},
+					NetworkSettings: networkSettings{
+						Ports: nat.PortMap{
+							nat.Port("80/tcp"): []nat.PortBinding{},
+						},
+						Networks: map[string]*networkData{
+							"bridge": {
+								Name: "bridge",
+								Addr: "127.0.0.1",
+							},
+						},
+					},
+				},
+			},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"Router1": {
+							EntryPoints: []string{"web"},
+							Service:     "Test",
+							Rule:        "Host(`Test.traefik.wtf`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Test": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://127.0.0.1:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "tcp with label and port",
+			containers: []dockerData{
+				{
+					ServiceName: "Test",
+					Name:        "Test",
+					Labels: map[string]string{
+						"traefik.tcp.routers.foo.rule":                      "HostSNI(`foo.bar`)",
+						"traefik.tcp.routers.foo.tls":                       "true",
+						"traefik.tcp.services.foo.loadbalancer.server.port": "8080",
+					},
+					NetworkSettings: networkSettings{
+						Ports: nat.PortMap{
+							nat.Port("80/tcp"): []nat.PortBinding{},
+						},
+						Networks: map[string]*networkData{
+							"bridge": {
+								Name: "bridge",
+								Addr: "127.0.0.1",
+							},
+						},
+					},
+				},
+			},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers: map[string]*config.TCPRouter{
+						"foo": {
+							Service: "foo",
+							Rule:    "HostSNI(`foo.bar`)",
+							TLS:     &config.RouterTCPTLSConfig{},
+						},
+					},
+					Services: map[string]*config.TCPService{
+						"foo": {
+							LoadBalancer: &config.TCPLoadBalancerService{
+								Servers: []config.TCPServer{
+									{
+										Address: "127.0.0.1:8080",
+									},
+								},
+							},
+						},
+					},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
+			},
+		},
+		{
+			desc: "tcp with label and port and http service",
+			containers: []dockerData{
+				{
+					ServiceName: "Test",
+					Name:        "Test",
+					Labels: map[string]string{
+						"traefik.tcp.routers.foo.rule":                               "HostSNI(`foo.bar`)",
+						"traefik.tcp.routers.foo.tls":                                "true",
+						"traefik.tcp.services.foo.loadbalancer.server.port":          "8080",
+						"traefik.http.services.Service1.loadbalancer.passhostheader": "true",
+					},
+					NetworkSettings: networkSettings{
+						Ports: nat.PortMap{
+							nat.Port("80/tcp"): []nat.PortBinding{},
+						},
+						Networks: map[string]*networkData{
+							"bridge": {
+								Name: "bridge",
+								Addr: "127.0.0.1",
+							},
+						},
+					},
+				},
+				{
+					ID:          "2",
+					ServiceName: "Test",
+					Name:        "Test",
+					Labels: map[string]string{
+						"traefik.tcp.routers.foo.rule":                               "HostSNI(`foo.bar`)",
+						"traefik.tcp.routers.foo.tls":                                "true",
+						"traefik.tcp.services.foo.loadbalancer.server.port":          "8080",
+						"traefik.http.services.Service1.loadbalancer.passhostheader": "true",
+					},
+					NetworkSettings: networkSettings{
+						Ports: nat.PortMap{
+							nat.Port("80/tcp"): []nat.PortBinding{},
+						},
+						Networks: map[string]*networkData{
+							"bridge": {
+								Name: "bridge",
+								Addr: "127.0.0.2",
+							},
+						},
+					},
+				},
+			},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers: map[string]*config.TCPRouter{
+						"foo": {
+							Service: "foo",
+							Rule:    "HostSNI(`foo.bar`)",
+							TLS:     &config.RouterTCPTLSConfig{},
+						},
+					},
+					Services: map[string]*config.TCPService{
+						"foo": {
+							LoadBalancer: &config.TCPLoadBalancerService{
+								Servers: []config.TCPServer{
+									{
+										Address: "127.0.0.1:8080",
+									},
+									{
+										Address: "127.0.0.2:8080",
+									},
+								},
+							},
+						},
+					},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"Test": {
+							Service: "Service1",
+							Rule:    "Host(`Test.traefik.wtf`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Service1": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://127.0.0.1:80",
+									},
+									{
+										URL: "http://127.0.0.2:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "tcp with label for tcp service",
+			containers: []dockerData{
+				{
+					ServiceName: "Test",
+					Name:        "Test",
+					Labels: map[string]string{
+						"traefik.tcp.services.foo.loadbalancer.server.port": "8080",
+					},
+					NetworkSettings: networkSettings{
+						Ports: nat.PortMap{
+							nat.Port("80/tcp"): []nat.PortBinding{},
+						},
+						Networks: map[string]*networkData{
+							"bridge": {
+								Name: "bridge",
+								Addr: "127.0.0.1",
+							},
+						},
+					},
+				},
+			},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers: map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{
+						"foo": {
+							LoadBalancer: &config.TCPLoadBalancerService{
+								Servers: []config.TCPServer{
+									{
+										Address: "127.0.0.1:8080",
+									},
+								},
+							},
+						},
+					},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
+			},
+		},
+		{
+			desc: "one container with host network and host ports label",
+			containers: []dockerData{
+				{
+					ServiceName: "Test",
+					Name:        "Test",
+					Labels: map[string]string{
+						"traefik.docker.hostPorts": "9090,8080",
+					},
+					NetworkSettings: networkSettings{
+						NetworkMode: "host",
+					},
+				},
+			},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"Test": {
+							Service: "Test",
+							Rule:    "Host(`Test.traefik.wtf`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Test": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://127.0.0.1:9090",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "one container with host network, host ports label and port index",
+			containers: []dockerData{
+				{
+					ServiceName: "Test",
+					Name:        "Test",
+					Labels: map[string]string{
+						"traefik.docker.hostPorts":                                "9090,8080",
+						"traefik.http.services.Service1.loadbalancer.server.port": "index:1",
+					},
+					NetworkSettings: networkSettings{
+						NetworkMode: "host",
+					},
+				},
+			},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"Test": {
+							Service: "Service1",
+							Rule:    "Host(`Test.traefik.wtf`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Service1": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://127.0.0.1:8080",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := Provider{
+				ExposedByDefault:        true,
+				CreateDefaultRouter:     true,
+				CreateDefaultTCPService: true,
+				DefaultRule:             "Host(`{{ normalize .Name }}.traefik.wtf`)",
+			}
+			p.Constraints = test.constraints
+
+			err := p.Init()
+			require.NoError(t, err)
+
+			for i := 0; i < len(test.containers); i++ {
+				var err error
+				test.containers[i].ExtraConf, err = p.getConfiguration(test.containers[i])
+				require.NoError(t, err)
+			}
+
+			configuration := p.buildConfiguration(context.Background(), test.containers)
+
+			assert.Equal(t, test.expected, configuration)
+		})
+	}
+}
+
+func TestBuildConfigurationServersOrder(t *testing.T) {
+	newContainer := func(id, ip string) dockerData {
+		return dockerData{
+			ID:          id,
+			ServiceName: "Test",
+			Name:        "Test",
+			Labels: map[string]string{
+				"traefik.http.services.Test.loadbalancer.server.port": "80",
+				"traefik.tcp.routers.foo.rule":                        "HostSNI(`foo.bar`)",
+				"traefik.tcp.services.foo.loadbalancer.server.port":   "8080",
+			},
+			NetworkSettings: networkSettings{
+				Ports: nat.PortMap{
+					nat.Port("80/tcp"): []nat.PortBinding{},
+				},
+				Networks: map[string]*networkData{
+					"bridge": {
+						Name: "bridge",
+						Addr: ip,
+					},
+				},
+			},
+		}
+	}
+
+	p := Provider{
+		ExposedByDefault: true,
+		DefaultRule:      "Host(`{{ normalize .Name }}.traefik.wtf`)",
+	}
+	err := p.Init()
+	require.NoError(t, err)
+
+	build := func(containers []dockerData) *config.Configuration {
+		for i := 0; i < len(containers); i++ {
+			var err error
+			containers[i].ExtraConf, err = p.getConfiguration(containers[i])
+			require.NoError(t, err)
+		}
+		return p.buildConfiguration(context.Background(), containers)
+	}
+
+	configuration := build([]dockerData{
+		newContainer("1", "127.0.0.3"),
+		newContainer("2", "127.0.0.1"),
+		newContainer("3", "127.0.0.2"),
+	})
+
+	shuffled := build([]dockerData{
+		newContainer("3", "127.0.0.2"),
+		newContainer("1", "127.0.0.3"),
+		newContainer("2", "127.0.0.1"),
+	})
+
+	assert.Equal(t, configuration, shuffled)
+
+	expectedServers := []config.Server{
+		{URL: "http://127.0.0.1:80"},
+		{URL: "http://127.0.0.2:80"},
+		{URL: "http://127.0.0.3:80"},
+	}
+	require.Contains(t, configuration.HTTP.Services, "Test")
+	assert.Equal(t, expectedServers, configuration.HTTP.Services["Test"].LoadBalancer.Servers)
+
+	expectedTCPServers := []config.TCPServer{
+		{Address: "127.0.0.1:8080"},
+		{Address: "127.0.0.2:8080"},
+		{Address: "127.0.0.3:8080"},
+	}
+	require.Contains(t, configuration.TCP.Services, "foo")
+	assert.Equal(t, expectedTCPServers, configuration.TCP.Services["foo"].LoadBalancer.Servers)
+}
+
+func TestConcurrencyMiddleware(t *testing.T) {
+	testCases := []struct {
+		desc                string
+		labels              map[string]string
+		expectedMiddlewares map[string]*config.Middleware
+		expectedRouters     map[string]*config.Router
+	}{
+		{
+			desc:                "no concurrency label",
+			labels:              map[string]string{},
+			expectedMiddlewares: map[string]*config.Middleware{},
+			expectedRouters: map[string]*config.Router{
+				"Test": {
+					Service: "Test",
+					Rule:    "Host(`Test.traefik.wtf`)",
+				},
+			},
+		},
+		{
+			desc: "concurrency label",
+			labels: map[string]string{
+				"traefik.autoscale.maxConcurrent": "50",
+			},
+			expectedMiddlewares: map[string]*config.Middleware{
+				"Test-concurrency": {
+					MaxConn: &config.MaxConn{
+						Amount:        50,
+						ExtractorFunc: "request.host",
+					},
+				},
+			},
+			expectedRouters: map[string]*config.Router{
+				"Test": {
+					Service:     "Test",
+					Rule:        "Host(`Test.traefik.wtf`)",
+					Middlewares: []string{"Test-concurrency"},
+				},
+			},
+		},
+		{
+			desc: "explicit middleware takes precedence",
+			labels: map[string]string{
+				"traefik.autoscale.maxConcurrent":                          "50",
+				"traefik.http.middlewares.Test-concurrency.maxconn.amount": "10",
+			},
+			expectedMiddlewares: map[string]*config.Middleware{
+				"Test-concurrency": {
+					MaxConn: &config.MaxConn{
+						Amount:        10,
+						ExtractorFunc: "request.host",
+					},
+				},
+			},
+			expectedRouters: map[string]*config.Router{
+				"Test": {
+					Service:     "Test",
+					Rule:        "Host(`Test.traefik.wtf`)",
+					Middlewares: []string{"Test-concurrency"},
+				},
+			},
+		},
+		{
+			desc: "invalid concurrency label",
+			labels: map[string]string{
+				"traefik.autoscale.maxConcurrent": "many",
+			},
+			expectedMiddlewares: map[string]*config.Middleware{},
+			expectedRouters: map[string]*config.Router{
+				"Test": {
+					Service: "Test",
+					Rule:    "Host(`Test.traefik.wtf`)",
+				},
+			},
+		},
+		{
+			desc: "no default router",
+			labels: map[string]string{
+				"traefik.autoscale.maxConcurrent":   "50",
+				"traefik.http.routers.Router1.rule": "Host(`foo.bar`)",
+			},
+			expectedMiddlewares: map[string]*config.Middleware{
+				"Test-concurrency": {
+					MaxConn: &config.MaxConn{
+						Amount:        50,
+						ExtractorFunc: "request.host",
+					},
+				},
+			},
+			expectedRouters: map[string]*config.Router{
+				"Router1": {
+					Service: "Test",
+					Rule:    "Host(`foo.bar`)",
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
 		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := Provider{
+				ExposedByDefault:    true,
+				CreateDefaultRouter: true,
+				DefaultRule:         "Host(`{{ normalize .Name }}.traefik.wtf`)",
+				ConcurrencyLabel:    "traefik.autoscale.maxConcurrent",
+			}
+
+			err := p.Init()
+			require.NoError(t, err)
+
+			container := dockerData{
+				ServiceName: "Test",
+				Name:        "Test",
+				Labels:      test.labels,
+				NetworkSettings: networkSettings{
+					Ports: nat.PortMap{
+						nat.Port("80/tcp"): []nat.PortBinding{},
+					},
+					Networks: map[string]*networkData{
+						"bridge": {
+							Name: "bridge",
+							Addr: "127.0.0.1",
+						},
+					},
+				},
+			}
+			container.ExtraConf, err = p.getConfiguration(container)
+			require.NoError(t, err)
+
+			configuration := p.buildConfiguration(context.Background(), []dockerData{container})
+
+			assert.Equal(t, test.expectedMiddlewares, configuration.HTTP.Middlewares)
+			assert.Equal(t, test.expectedRouters, configuration.HTTP.Routers)
+		})
+	}
+}
+
+type labelErrorsRegistry struct {
+	metrics.Registry
+	counter *testhelpers.CollectingCounter
+}
+
+func (r labelErrorsRegistry) ProviderLabelErrorsCounter() gokitmetrics.Counter {
+	return r.counter
+}
+
+func TestLabelErrorsPerElement(t *testing.T) {
+	counter := &testhelpers.CollectingCounter{}
+
+	p := Provider{
+		ExposedByDefault: true,
+		DefaultRule:      "Host(`{{ normalize .Name }}.traefik.wtf`)",
+	}
+	p.SetMetricsRegistry(labelErrorsRegistry{Registry: metrics.NewVoidRegistry(), counter: counter})
+
+	err := p.Init()
+	require.NoError(t, err)
+
+	container := dockerData{
+		ServiceName: "Test",
+		Name:        "Test",
+		Labels: map[string]string{
+			"traefik.http.routers.Router1.rule":                                  "Host(`foo.bar`)",
+			"traefik.http.routers.Router2.rule":                                  "Host(`bar.foo`)",
+			"traefik.http.routers.Router2.unknown":                               "foobar",
+			"traefik.http.middlewares.Middleware1.addprefix.prefix":              "/foo",
+			"traefik.http.middlewares.Middleware2.buffering.maxrequestbodybytes": "foobar",
+		},
+		NetworkSettings: networkSettings{
+			Ports: nat.PortMap{
+				nat.Port("80/tcp"): []nat.PortBinding{},
+			},
+			Networks: map[string]*networkData{
+				"bridge": {
+					Name: "bridge",
+					Addr: "127.0.0.1",
+				},
+			},
+		},
+	}
+	container.ExtraConf, err = p.getConfiguration(container)
+	require.NoError(t, err)
+
+	configuration := p.buildConfiguration(context.Background(), []dockerData{container})
+
+	expectedRouters := map[string]*config.Router{
+		"Router1": {
+			Service: "Test",
+			Rule:    "Host(`foo.bar`)",
+		},
+	}
+	expectedMiddlewares := map[string]*config.Middleware{
+		"Middleware1": {
+			AddPrefix: &config.AddPrefix{Prefix: "/foo"},
+		},
+	}
+
+	assert.Equal(t, expectedRouters, configuration.HTTP.Routers)
+	assert.Equal(t, expectedMiddlewares, configuration.HTTP.Middlewares)
+	assert.Contains(t, configuration.HTTP.Services, "Test")
+
+	assert.Equal(t, float64(2), counter.CounterValue)
+	assert.Equal(t, []string{"provider", "docker"}, counter.LastLabelValues)
+}
+
+func TestWeightedServices(t *testing.T) {
+	container := func(name, ip string, lbls map[string]string) dockerData {
+		return dockerData{
+			ID:          ip,
+			ServiceName: name,
+			Name:        name,
+			Labels:      lbls,
+			NetworkSettings: networkSettings{
+				Ports: nat.PortMap{
+					nat.Port("80/tcp"): []nat.PortBinding{},
+				},
+				Networks: map[string]*networkData{
+					"bridge": {
+						Name: "bridge",
+						Addr: ip,
+					},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		desc       string
+		containers []dockerData
+		expected   []config.Server
+	}{
+		{
+			desc: "weights sum per deployment",
+			containers: []dockerData{
+				container("app-blue", "127.0.0.1", map[string]string{
+					"traefik.http.services.app.loadbalancer.server.weight": "80",
+				}),
+				container("app-blue", "127.0.0.2", map[string]string{
+					"traefik.http.services.app.loadbalancer.server.weight": "80",
+				}),
+				container("app-green", "127.0.0.3", map[string]string{
+					"traefik.http.services.app.loadbalancer.server.weight": "20",
+				}),
+			},
+			expected: []config.Server{
+				{URL: "http://127.0.0.1:80", Weight: 80},
+				{URL: "http://127.0.0.2:80", Weight: 80},
+				{URL: "http://127.0.0.3:80", Weight: 40},
+			},
+		},
+		{
+			desc: "deployment label",
+			containers: []dockerData{
+				container("app-1", "127.0.0.1", map[string]string{
+					"traefik.http.services.app.loadbalancer.server.weight": "50",
+					"traefik.docker.deployment":                            "blue",
+				}),
+				container("app-2", "127.0.0.2", map[string]string{
+					"traefik.http.services.app.loadbalancer.server.weight": "50",
+					"traefik.docker.deployment":                            "blue",
+				}),
+				container("app-3", "127.0.0.3", map[string]string{
+					"traefik.http.services.app.loadbalancer.server.weight": "50",
+					"traefik.docker.deployment":                            "green",
+				}),
+				container("app-4", "127.0.0.4", map[string]string{
+					"traefik.http.services.app.loadbalancer.server.weight": "50",
+					"traefik.docker.deployment":                            "green",
+				}),
+				container("app-5", "127.0.0.5", map[string]string{
+					"traefik.http.services.app.loadbalancer.server.weight": "50",
+					"traefik.docker.deployment":                            "green",
+				}),
+			},
+			expected: []config.Server{
+				{URL: "http://127.0.0.1:80", Weight: 150},
+				{URL: "http://127.0.0.2:80", Weight: 150},
+				{URL: "http://127.0.0.3:80", Weight: 100},
+				{URL: "http://127.0.0.4:80", Weight: 100},
+				{URL: "http://127.0.0.5:80", Weight: 100},
+			},
+		},
+		{
+			desc: "weighted and unweighted servers",
+			containers: []dockerData{
+				container("app-blue", "127.0.0.1", map[string]string{
+					"traefik.http.services.app.loadbalancer.server.weight": "3",
+				}),
+				container("app-blue", "127.0.0.2", map[string]string{
+					"traefik.http.services.app.loadbalancer.server.weight": "3",
+				}),
+				container("app-blue", "127.0.0.3", map[string]string{
+					"traefik.http.services.app.loadbalancer.server.weight": "3",
+				}),
+				container("app-green", "127.0.0.4", map[string]string{
+					"traefik.http.services.app.loadbalancer.server.port": "80",
+				}),
+			},
+			expected: []config.Server{
+				{URL: "http://127.0.0.1:80", Weight: 3},
+				{URL: "http://127.0.0.2:80", Weight: 3},
+				{URL: "http://127.0.0.3:80", Weight: 3},
+				{URL: "http://127.0.0.4:80", Weight: 3},
+			},
+		},
+		{
+			desc: "without weight",
+			containers: []dockerData{
+				container("app-blue", "127.0.0.1", map[string]string{
+					"traefik.http.services.app.loadbalancer.server.port": "80",
+				}),
+				container("app-green", "127.0.0.2", map[string]string{
+					"traefik.http.services.app.loadbalancer.server.port": "80",
+				}),
+			},
+			expected: []config.Server{
+				{URL: "http://127.0.0.1:80"},
+				{URL: "http://127.0.0.2:80"},
+			},
+		},
+	}
 
+	for _, test := range testCases {
+		test := test
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
@@ -2361,7 +3254,6 @@ func Test_buildConfiguration(t *testing.T) {
 				ExposedByDefault: true,
 				DefaultRule:      "Host(`{{ normalize .Name }}.traefik.wtf`)",
 			}
-			p.Constraints = test.constraints
 
 			err := p.Init()
 			require.NoError(t, err)
@@ -2374,7 +3266,178 @@ func Test_buildConfiguration(t *testing.T) {
 
 			configuration := p.buildConfiguration(context.Background(), test.containers)
 
-			assert.Equal(t, test.expected, configuration)
+			require.Contains(t, configuration.HTTP.Services, "app")
+			assert.Equal(t, test.expected, configuration.HTTP.Services["app"].LoadBalancer.Servers)
+		})
+	}
+}
+
+func TestAutoRedirect(t *testing.T) {
+	container := func(name, ip string, lbls map[string]string) dockerData {
+		return dockerData{
+			ID:          ip,
+			ServiceName: name,
+			Name:        name,
+			Labels:      lbls,
+			NetworkSettings: networkSettings{
+				Ports: nat.PortMap{
+					nat.Port("80/tcp"): []nat.PortBinding{},
+				},
+				Networks: map[string]*networkData{
+					"bridge": {
+						Name: "bridge",
+						Addr: ip,
+					},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		desc                string
+		autoRedirect        *AutoRedirect
+		expectedRouters     map[string]*config.Router
+		expectedMiddlewares map[string]*config.Middleware
+	}{
+		{
+			desc: "disabled",
+			autoRedirect: &AutoRedirect{
+				EntryPoint: "web",
+			},
+			expectedRouters: map[string]*config.Router{
+				"Test1": {
+					Service: "Test1",
+					Rule:    "Host(`Test1.traefik.wtf`)",
+				},
+				"Test2": {
+					Service: "Test2",
+					Rule:    "Host(`Test2.traefik.wtf`)",
+				},
+				"Router3": {
+					EntryPoints: []string{"web"},
+					Service:     "Test3",
+					Rule:        "Host(`Test3.traefik.wtf`)",
+				},
+			},
+			expectedMiddlewares: map[string]*config.Middleware{},
+		},
+		{
+			desc: "enabled",
+			autoRedirect: &AutoRedirect{
+				Enable:     true,
+				EntryPoint: "web",
+				Permanent:  true,
+			},
+			expectedRouters: map[string]*config.Router{
+				"Test1": {
+					Service: "Test1",
+					Rule:    "Host(`Test1.traefik.wtf`)",
+					TLS:     &config.RouterTLSConfig{},
+				},
+				"Test1-redirect": {
+					EntryPoints: []string{"web"},
+					Middlewares: []string{"autoredirect-https"},
+					Service:     "Test1",
+					Rule:        "Host(`Test1.traefik.wtf`)",
+				},
+				"Test2": {
+					Service: "Test2",
+					Rule:    "Host(`Test2.traefik.wtf`)",
+					TLS:     &config.RouterTLSConfig{},
+				},
+				"Test2-redirect": {
+					EntryPoints: []string{"web"},
+					Middlewares: []string{"autoredirect-https"},
+					Service:     "Test2",
+					Rule:        "Host(`Test2.traefik.wtf`)",
+				},
+				"Router3": {
+					EntryPoints: []string{"web"},
+					Service:     "Test3",
+					Rule:        "Host(`Test3.traefik.wtf`)",
+				},
+			},
+			expectedMiddlewares: map[string]*config.Middleware{
+				"autoredirect-https": {
+					RedirectScheme: &config.RedirectScheme{
+						Scheme:    "https",
+						Permanent: true,
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := Provider{
+				ExposedByDefault:    true,
+				CreateDefaultRouter: true,
+				DefaultRule:         "Host(`{{ normalize .Name }}.traefik.wtf`)",
+				AutoRedirect:        test.autoRedirect,
+				EntryPoints:         []string{"web", "websecure"},
+			}
+
+			err := p.Init()
+			require.NoError(t, err)
+
+			containers := []dockerData{
+				container("Test1", "127.0.0.1", map[string]string{}),
+				container("Test1", "127.0.0.2", map[string]string{}),
+				container("Test2", "127.0.0.3", map[string]string{}),
+				container("Test3", "127.0.0.4", map[string]string{
+					"traefik.http.routers.Router3.entrypoints": "web",
+				}),
+			}
+			for i := range containers {
+				containers[i].ExtraConf, err = p.getConfiguration(containers[i])
+				require.NoError(t, err)
+			}
+
+			configuration := p.buildConfiguration(context.Background(), containers)
+
+			assert.Equal(t, test.expectedRouters, configuration.HTTP.Routers)
+			assert.Equal(t, test.expectedMiddlewares, configuration.HTTP.Middlewares)
+		})
+	}
+}
+
+func TestAutoRedirect_invalidEntryPoint(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		entryPoint       string
+		expectedErrorMsg string
+	}{
+		{
+			desc:             "empty entry point",
+			expectedErrorMsg: "invalid autoRedirect configuration: the entry point is required",
+		},
+		{
+			desc:             "unknown entry point",
+			entryPoint:       "foo",
+			expectedErrorMsg: `invalid autoRedirect configuration: unknown entry point "foo"`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := Provider{
+				DefaultRule: "Host(`{{ normalize .Name }}.traefik.wtf`)",
+				AutoRedirect: &AutoRedirect{
+					Enable:     true,
+					EntryPoint: test.entryPoint,
+				},
+				EntryPoints: []string{"web", "websecure"},
+			}
+
+			err := p.Init()
+			assert.EqualError(t, err, test.expectedErrorMsg)
 		})
 	}
 }
@@ -2608,6 +3671,53 @@ func TestDockerGetPort(t *testing.T) {
 			serverPort: "8080",
 			expected:   "8080",
 		},
+		{
+			desc: "binding, multiple ports, server port index",
+			container: containerJSON(ports(nat.PortMap{
+				"8080/tcp": {},
+				"80/tcp":   {},
+			})),
+			serverPort: "index:1",
+			expected:   "8080",
+		},
+		{
+			desc: "binding, server port index out of range",
+			container: containerJSON(ports(nat.PortMap{
+				"80/tcp": {},
+			})),
+			serverPort: "index:1",
+			expected:   "",
+		},
+		{
+			desc: "host network, host ports label",
+			container: containerJSON(
+				networkMode("host"),
+				labels(map[string]string{
+					"traefik.docker.hostPorts": "9090,8080",
+				})),
+			expected: "9090",
+		},
+		{
+			desc: "host network, host ports label, server port index",
+			container: containerJSON(
+				networkMode("host"),
+				labels(map[string]string{
+					"traefik.docker.hostPorts": "9090,8080",
+				})),
+			serverPort: "index:1",
+			expected:   "8080",
+		},
+		{
+			desc: "bridge network, host ports label",
+			container: containerJSON(
+				ports(nat.PortMap{
+					"80/tcp": {},
+				}),
+				labels(map[string]string{
+					"traefik.docker.hostPorts": "9090,8080",
+				})),
+			expected: "80",
+		},
 	}
 
 	for _, test := range testCases {
@@ -2617,12 +3727,37 @@ func TestDockerGetPort(t *testing.T) {
 
 			dData := parseContainer(test.container)
 
+			var err error
+			dData.ExtraConf, err = (&Provider{}).getConfiguration(dData)
+			require.NoError(t, err)
+
 			actual := getPort(dData, test.serverPort)
 			assert.Equal(t, test.expected, actual)
 		})
 	}
 }
 
+func TestDockerGetConfigurationInvalidHostPorts(t *testing.T) {
+	testCases := []string{"foo", "0", "65536", "8080,bar"}
+
+	for _, hostPorts := range testCases {
+		hostPorts := hostPorts
+		t.Run(hostPorts, func(t *testing.T) {
+			t.Parallel()
+
+			dData := parseContainer(containerJSON(
+				networkMode("host"),
+				labels(map[string]string{
+					"traefik.docker.hostPorts": hostPorts,
+				})))
+
+			_, err := (&Provider{}).getConfiguration(dData)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "traefik.docker.hostPorts")
+		})
+	}
+}
+
 func TestDockerGetIPAddress(t *testing.T) {
 	testCases := []struct {
 		desc      string
@@ -2814,3 +3949,140 @@ func TestSwarmGetPort(t *testing.T) {
 		})
 	}
 }
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func TestQueryRule(t *testing.T) {
+	p := Provider{
+		ExposedByDefault: true,
+		DefaultRule:      "Host(`{{ normalize .Name }}.traefik.wtf`)",
+	}
+	err := p.Init()
+	require.NoError(t, err)
+
+	container := dockerData{
+		ServiceName: "Test",
+		Name:        "Test",
+		Labels: map[string]string{
+			"traefik.http.routers.R.rule": "Host(`x`) && Query(`v=2`)",
+		},
+		NetworkSettings: networkSettings{
+			Ports: nat.PortMap{
+				nat.Port("80/tcp"): []nat.PortBinding{},
+			},
+			Networks: map[string]*networkData{
+				"bridge": {
+					Name: "bridge",
+					Addr: "127.0.0.1",
+				},
+			},
+		},
+	}
+	container.ExtraConf, err = p.getConfiguration(container)
+	require.NoError(t, err)
+
+	configuration := p.buildConfiguration(context.Background(), []dockerData{container})
+	require.Contains(t, configuration.HTTP.Routers, "R")
+
+	router, err := rules.NewRouter()
+	require.NoError(t, err)
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {})
+	err = router.AddRoute(configuration.HTTP.Routers["R"].Rule, 0, handler)
+	require.NoError(t, err)
+
+	// RequestDecorator is necessary for the host rule
+	reqHost := requestdecorator.New(nil)
+
+	expected := map[string]int{
+		"http://x/?v=2": http.StatusOK,
+		"http://x/?v=1": http.StatusNotFound,
+		"http://y/?v=2": http.StatusNotFound,
+	}
+	for testURL, status := range expected {
+		rw := httptest.NewRecorder()
+		reqHost.ServeHTTP(rw, testhelpers.MustNewRequest(http.MethodGet, testURL, nil), router.ServeHTTP)
+		assert.Equal(t, status, rw.Code, testURL)
+	}
+}
+
+func TestRouterPriority(t *testing.T) {
+	p := Provider{
+		ExposedByDefault: true,
+		DefaultRule:      "Host(`{{ normalize .Name }}.traefik.wtf`)",
+	}
+	err := p.Init()
+	require.NoError(t, err)
+
+	newContainer := func(name, ip string, labels map[string]string) dockerData {
+		return dockerData{
+			ServiceName: name,
+			Name:        name,
+			Labels:      labels,
+			NetworkSettings: networkSettings{
+				Ports: nat.PortMap{
+					nat.Port("80/tcp"): []nat.PortBinding{},
+				},
+				Networks: map[string]*networkData{
+					"bridge": {
+						Name: "bridge",
+						Addr: ip,
+					},
+				},
+			},
+		}
+	}
+
+	containers := []dockerData{
+		newContainer("Web", "127.0.0.1", map[string]string{
+			"traefik.http.routers.web.rule":     "PathPrefix(`/`)",
+			"traefik.http.routers.web.priority": "1",
+		}),
+		newContainer("API", "127.0.0.2", map[string]string{
+			"traefik.http.routers.api.rule":     "PathPrefix(`/api`)",
+			"traefik.http.routers.api.priority": "100",
+		}),
+		newContainer("Docs", "127.0.0.3", map[string]string{
+			"traefik.http.routers.docs.rule":     "PathPrefix(`/api/docs`)",
+			"traefik.http.routers.docs.priority": "10",
+		}),
+	}
+	for i := range containers {
+		containers[i].ExtraConf, err = p.getConfiguration(containers[i])
+		require.NoError(t, err)
+	}
+
+	configuration := p.buildConfiguration(context.Background(), containers)
+
+	expectedPriorities := map[string]int{"web": 1, "api": 100, "docs": 10}
+	require.Len(t, configuration.HTTP.Routers, len(expectedPriorities))
+	for name, priority := range expectedPriorities {
+		require.Contains(t, configuration.HTTP.Routers, name)
+		assert.Equal(t, priority, configuration.HTTP.Routers[name].Priority, name)
+	}
+
+	router, err := rules.NewRouter()
+	require.NoError(t, err)
+
+	for name, rt := range configuration.HTTP.Routers {
+		name := name
+		handler := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.Header().Set("X-Router", name)
+		})
+		require.NoError(t, router.AddRoute(rt.Rule, rt.Priority, handler))
+	}
+	router.SortRoutes()
+
+	expected := map[string]string{
+		"http://localhost/":             "web",
+		"http://localhost/api/users":    "api",
+		"http://localhost/api/docs/foo": "api",
+	}
+	for testURL, name := range expected {
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, testhelpers.MustNewRequest(http.MethodGet, testURL, nil))
+		assert.Equal(t, name, rw.Header().Get("X-Router"), testURL)
+	}
+}
@@ -2,6 +2,7 @@ package docker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -15,6 +16,7 @@ import (
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/job"
 	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/metrics"
 	"github.com/containous/traefik/pkg/provider"
 	"github.com/containous/traefik/pkg/safe"
 	"github.com/containous/traefik/pkg/types"
@@ -52,16 +54,51 @@ type Provider struct {
 	TLS                     *types.ClientTLS `description:"Enable Docker TLS support." export:"true"`
 	ExposedByDefault        bool             `description:"Expose containers by default." export:"true"`
 	UseBindPortIP           bool             `description:"Use the ip address from the bound port, rather than from the inner network." export:"true"`
+	CreateDefaultRouter     bool             `description:"Create a default router, using the default rule, for the containers declaring no router." export:"true"`
+	CreateDefaultTCPService bool             `description:"Create a default TCP service for the containers declaring TCP routers without service." export:"true"`
+	ConcurrencyLabel        string           `description:"Label defining the maximum number of concurrent requests of a container, through a generated MaxConn middleware." export:"true"`
 	SwarmMode               bool             `description:"Use Docker on Swarm Mode." export:"true"`
 	Network                 string           `description:"Default Docker network used." export:"true"`
 	SwarmModeRefreshSeconds types.Duration   `description:"Polling interval for swarm mode." export:"true"`
+	SwarmKeepReplacedTasks  bool             `description:"Keep the tasks being replaced by a stop-first service update until their replacement is running." export:"true"`
+	UsePublishedPort        bool             `description:"Use the ports published by the swarm services on the address of each node, rather than the tasks addresses." export:"true"`
+	UseContainerLabels      bool             `description:"Use the labels of the containers of the swarm services, overridden by the labels of the services." export:"true"`
+	AutoRedirect            *AutoRedirect    `description:"Generate, for each default router, a sibling router redirecting to HTTPS." export:"true"`
+	EntryPoints             []string         `description:"-"`
 	defaultRuleTpl          *template.Template
+	clock                   clock
+	newBackOff              func() backoff.BackOff
+	metricsRegistry         metrics.Registry
+}
+
+// AutoRedirect holds the configuration of the routers redirecting to HTTPS, generated for the default routers.
+type AutoRedirect struct {
+	Enable     bool   `description:"Enable the generation of the redirecting routers." export:"true"`
+	EntryPoint string `description:"Insecure entry point the redirecting routers are bound to." export:"true"`
+	Permanent  bool   `description:"Use a permanent redirection." export:"true"`
+}
+
+// validate checks that the redirecting routers are bound to one of the entry points.
+func (a *AutoRedirect) validate(entryPoints []string) error {
+	if a.EntryPoint == "" {
+		return errors.New("the entry point is required")
+	}
+
+	for _, entryPoint := range entryPoints {
+		if entryPoint == a.EntryPoint {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown entry point %q", a.EntryPoint)
 }
 
 // SetDefaults sets the default values.
 func (p *Provider) SetDefaults() {
 	p.Watch = true
 	p.ExposedByDefault = true
+	p.CreateDefaultRouter = true
+	p.CreateDefaultTCPService = true
 	p.Endpoint = "unix:///var/run/docker.sock"
 	p.SwarmMode = false
 	p.SwarmModeRefreshSeconds = types.Duration(15 * time.Second)
@@ -76,9 +113,35 @@ func (p *Provider) Init() error {
 	}
 
 	p.defaultRuleTpl = defaultRuleTpl
+
+	if p.AutoRedirect != nil && p.AutoRedirect.Enable {
+		if err := p.AutoRedirect.validate(p.EntryPoints); err != nil {
+			return fmt.Errorf("invalid autoRedirect configuration: %v", err)
+		}
+	}
+
+	if p.clock == nil {
+		p.clock = realClock{}
+	}
+
+	if p.newBackOff == nil {
+		p.newBackOff = func() backoff.BackOff {
+			return job.NewBackOff(backoff.NewExponentialBackOff())
+		}
+	}
+
+	if p.metricsRegistry == nil {
+		p.metricsRegistry = metrics.NewVoidRegistry()
+	}
+
 	return nil
 }
 
+// SetMetricsRegistry sets the registry used to report the metrics of the provider.
+func (p *Provider) SetMetricsRegistry(registry metrics.Registry) {
+	p.metricsRegistry = registry
+}
+
 // dockerData holds the need data to the provider.
 type dockerData struct {
 	ID              string
@@ -89,6 +152,7 @@ type dockerData struct {
 	Health          string
 	Node            *dockertypes.ContainerNode
 	ExtraConf       configuration
+	Published       bool // Published tells that the ports are the ones published by a swarm service on a node.
 }
 
 // NetworkSettings holds the networks data to the provider.
@@ -194,7 +258,7 @@ func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.P
 				if p.SwarmMode {
 					errChan := make(chan error)
 					// TODO: This need to be change. Linked to Swarm events docker/docker#23827
-					ticker := time.NewTicker(time.Duration(p.SwarmModeRefreshSeconds))
+					ticker := p.clock.NewTicker(time.Duration(p.SwarmModeRefreshSeconds))
 					pool.GoCtx(func(ctx context.Context) {
 
 						ctx = log.With(ctx, log.Str(log.ProviderName, "docker"))
@@ -203,7 +267,7 @@ func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.P
 						defer close(errChan)
 						for {
 							select {
-							case <-ticker.C:
+							case <-ticker.Chan():
 								services, err := p.listServices(ctx, dockerClient)
 								if err != nil {
 									logger.Errorf("Failed to list services for docker, error %s", err)
@@ -266,6 +330,7 @@ func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.P
 						case event := <-eventsc:
 							if event.Action == "start" ||
 								event.Action == "die" ||
+								event.Action == "update" ||
 								strings.HasPrefix(event.Action, "health_status") {
 								startStopHandle(event)
 							}
@@ -286,7 +351,7 @@ func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.P
 		notify := func(err error, time time.Duration) {
 			logger.Errorf("Provider connection error %+v, retrying in %s", err, time)
 		}
-		err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctxLog), notify)
+		err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithContext(p.newBackOff(), ctxLog), notify)
 		if err != nil {
 			logger.Errorf("Cannot connect to docker server %+v", err)
 		}
@@ -304,6 +369,10 @@ func (p *Provider) listContainers(ctx context.Context, dockerClient client.Conta
 	var inspectedContainers []dockerData
 	// get inspect containers
 	for _, container := range containerList {
+		if !p.keepListedContainer(ctx, container) {
+			continue
+		}
+
 		dData := inspectContainers(ctx, dockerClient, container.ID)
 		if len(dData.Name) == 0 {
 			continue
@@ -321,6 +390,32 @@ func (p *Provider) listContainers(ctx context.Context, dockerClient client.Conta
 	return inspectedContainers, nil
 }
 
+// keepListedContainer filters a container using only the labels returned by the container list,
+// to avoid inspecting containers which would be filtered out anyway.
+// As constraints only match tags, they can always be evaluated from the labels;
+// the filters needing inspected data (e.g. health) are applied later by keepContainer.
+func (p *Provider) keepListedContainer(ctx context.Context, container dockertypes.Container) bool {
+	extraConf, err := p.getConfiguration(dockerData{Labels: container.Labels})
+	if err != nil {
+		// Let the inspected container report the error.
+		return true
+	}
+
+	if !extraConf.Enable {
+		log.FromContext(ctx).Debugf("Filtering disabled container %s before inspection", container.ID)
+		return false
+	}
+
+	if ok, failingConstraint := p.MatchConstraints(extraConf.Tags); !ok {
+		if failingConstraint != nil {
+			log.FromContext(ctx).Debugf("Container %s pruned by %q constraint before inspection", container.ID, failingConstraint.String())
+		}
+		return false
+	}
+
+	return true
+}
+
 func inspectContainers(ctx context.Context, dockerClient client.ContainerAPIClient, containerID string) dockerData {
 	containerInspected, err := dockerClient.ContainerInspect(ctx, containerID)
 	if err != nil {
@@ -412,6 +507,15 @@ func (p *Provider) listServices(ctx context.Context, dockerClient client.APIClie
 		networkMap[network.ID] = &networkToAdd
 	}
 
+	var nodes []swarmtypes.Node
+	if p.UsePublishedPort {
+		nodes, err = dockerClient.NodeList(ctx, dockertypes.NodeListOptions{})
+		if err != nil {
+			logger.Debugf("Failed to list the nodes for docker, error: %s", err)
+			return nil, err
+		}
+	}
+
 	var dockerDataList []dockerData
 	var dockerDataListTasks []dockerData
 
@@ -422,13 +526,21 @@ func (p *Provider) listServices(ctx context.Context, dockerClient client.APIClie
 			continue
 		}
 
+		if p.UsePublishedPort {
+			publishedDataList := listPublishedNodes(service, dData, nodes)
+			if len(publishedDataList) > 0 {
+				dockerDataList = append(dockerDataList, publishedDataList...)
+				continue
+			}
+			logger.Debugf("No port published by the service %s, using its tasks", service.Spec.Annotations.Name)
+		}
+
 		if dData.ExtraConf.Docker.LBSwarm {
 			if len(dData.NetworkSettings.Networks) > 0 {
 				dockerDataList = append(dockerDataList, dData)
 			}
 		} else {
-			isGlobalSvc := service.Spec.Mode.Global != nil
-			dockerDataListTasks, err = listTasks(ctx, dockerClient, service.ID, dData, networkMap, isGlobalSvc)
+			dockerDataListTasks, err = listTasks(ctx, dockerClient, service, dData, networkMap, p.SwarmKeepReplacedTasks)
 			if err != nil {
 				logger.Warn(err)
 			} else {
@@ -450,6 +562,10 @@ func (p *Provider) parseService(ctx context.Context, service swarmtypes.Service,
 		NetworkSettings: networkSettings{},
 	}
 
+	if p.UseContainerLabels && service.Spec.TaskTemplate.ContainerSpec != nil {
+		dData.Labels = mergeLabels(service.Spec.TaskTemplate.ContainerSpec.Labels, service.Spec.Annotations.Labels)
+	}
+
 	extraConf, err := p.getConfiguration(dData)
 	if err != nil {
 		return dockerData{}, err
@@ -486,22 +602,115 @@ func (p *Provider) parseService(ctx context.Context, service swarmtypes.Service,
 	return dData, nil
 }
 
-func listTasks(ctx context.Context, dockerClient client.APIClient, serviceID string,
-	serviceDockerData dockerData, networkMap map[string]*dockertypes.NetworkResource, isGlobalSvc bool) ([]dockerData, error) {
+// mergeLabels returns the labels of the containers, overridden by the labels of the service.
+// As the labels are decoded case-insensitively, so are they overridden.
+func mergeLabels(containerLabels, serviceLabels map[string]string) map[string]string {
+	if len(containerLabels) == 0 {
+		return serviceLabels
+	}
+
+	labels := make(map[string]string, len(containerLabels)+len(serviceLabels))
+	serviceKeys := make(map[string]bool, len(serviceLabels))
+	for key, value := range serviceLabels {
+		labels[key] = value
+		serviceKeys[strings.ToLower(key)] = true
+	}
+
+	for key, value := range containerLabels {
+		if !serviceKeys[strings.ToLower(key)] {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
+// listPublishedNodes returns, for each ready node, the ports published by the service through the ingress routing mesh,
+// bound to the address of the node.
+func listPublishedNodes(service swarmtypes.Service, serviceDockerData dockerData, nodes []swarmtypes.Node) []dockerData {
+	var publishedPorts []swarmtypes.PortConfig
+	for _, port := range service.Endpoint.Ports {
+		if port.PublishedPort == 0 || port.PublishMode == swarmtypes.PortConfigPublishModeHost {
+			continue
+		}
+		publishedPorts = append(publishedPorts, port)
+	}
+
+	if len(publishedPorts) == 0 {
+		return nil
+	}
+
+	var dockerDataList []dockerData
+	for _, node := range nodes {
+		if node.Status.State != swarmtypes.NodeStateReady || len(node.Status.Addr) == 0 || node.Status.Addr == "0.0.0.0" {
+			continue
+		}
+
+		ports := nat.PortMap{}
+		for _, port := range publishedPorts {
+			protocol := string(port.Protocol)
+			if len(protocol) == 0 {
+				protocol = string(swarmtypes.PortConfigProtocolTCP)
+			}
+
+			targetPort := nat.Port(fmt.Sprintf("%d/%s", port.TargetPort, protocol))
+			ports[targetPort] = append(ports[targetPort], nat.PortBinding{
+				HostIP:   node.Status.Addr,
+				HostPort: strconv.FormatUint(uint64(port.PublishedPort), 10),
+			})
+		}
+
+		dockerDataList = append(dockerDataList, dockerData{
+			ID:              service.ID + "." + node.ID,
+			ServiceName:     serviceDockerData.Name,
+			Name:            serviceDockerData.Name + "." + node.ID,
+			Labels:          serviceDockerData.Labels,
+			ExtraConf:       serviceDockerData.ExtraConf,
+			NetworkSettings: networkSettings{Ports: ports},
+			Published:       true,
+		})
+	}
+	return dockerDataList
+}
+
+func listTasks(ctx context.Context, dockerClient client.APIClient, service swarmtypes.Service,
+	serviceDockerData dockerData, networkMap map[string]*dockertypes.NetworkResource, keepReplacedTasks bool) ([]dockerData, error) {
+	isGlobalSvc := service.Spec.Mode.Global != nil
+
 	serviceIDFilter := filters.NewArgs()
-	serviceIDFilter.Add("service", serviceID)
-	serviceIDFilter.Add("desired-state", "running")
+	serviceIDFilter.Add("service", service.ID)
+
+	keepReplaced := keepReplacedTasks && isStopFirstUpdateInProgress(service)
+	if !keepReplaced {
+		serviceIDFilter.Add("desired-state", "running")
+	}
 
 	taskList, err := dockerClient.TaskList(ctx, dockertypes.TaskListOptions{Filters: serviceIDFilter})
 	if err != nil {
 		return nil, err
 	}
 
+	// The tasks being replaced are the ones which still run, but are not desired to.
+	// They are kept only while no task desired to run on the same slot (or node, for a global service) is running.
+	runningSlots := make(map[string]bool)
+	for _, task := range taskList {
+		if task.DesiredState == swarmtypes.TaskStateRunning && task.Status.State == swarmtypes.TaskStateRunning {
+			runningSlots[getTaskSlotKey(task, isGlobalSvc)] = true
+		}
+	}
+
 	var dockerDataList []dockerData
 	for _, task := range taskList {
 		if task.Status.State != swarmtypes.TaskStateRunning {
 			continue
 		}
+
+		if task.DesiredState != swarmtypes.TaskStateRunning {
+			if !keepReplaced || runningSlots[getTaskSlotKey(task, isGlobalSvc)] {
+				log.FromContext(ctx).Debugf("Filtering task %s of service %s being shut down", task.ID, service.Spec.Annotations.Name)
+				continue
+			}
+		}
+
 		dData := parseTasks(ctx, task, serviceDockerData, networkMap, isGlobalSvc)
 		if len(dData.NetworkSettings.Networks) > 0 {
 			dockerDataList = append(dockerDataList, dData)
@@ -510,6 +719,34 @@ func listTasks(ctx context.Context, dockerClient client.APIClient, serviceID str
 	return dockerDataList, err
 }
 
+// isStopFirstUpdateInProgress tells whether an update (or a rollback) of the service is in progress,
+// and stops the old tasks before starting their replacement.
+func isStopFirstUpdateInProgress(service swarmtypes.Service) bool {
+	if service.UpdateStatus == nil {
+		return false
+	}
+
+	var updateConfig *swarmtypes.UpdateConfig
+	switch service.UpdateStatus.State {
+	case swarmtypes.UpdateStateUpdating:
+		updateConfig = service.Spec.UpdateConfig
+	case swarmtypes.UpdateStateRollbackStarted:
+		updateConfig = service.Spec.RollbackConfig
+	default:
+		return false
+	}
+
+	// stop-first is the default order.
+	return updateConfig == nil || updateConfig.Order != swarmtypes.UpdateOrderStartFirst
+}
+
+func getTaskSlotKey(task swarmtypes.Task, isGlobalSvc bool) string {
+	if isGlobalSvc {
+		return task.NodeID
+	}
+	return strconv.Itoa(task.Slot)
+}
+
 func parseTasks(ctx context.Context, task swarmtypes.Task, serviceDockerData dockerData,
 	networkMap map[string]*dockertypes.NetworkResource, isGlobalSvc bool) dockerData {
 	dData := dockerData{
@@ -0,0 +1,100 @@
+package docker
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/containous/traefik/pkg/types"
+	dockertypes "github.com/docker/docker/api/types"
+	dockerclient "github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeContainersClient struct {
+	dockerclient.APIClient
+	containers []dockertypes.ContainerJSON
+	inspected  int
+}
+
+func (c *fakeContainersClient) ContainerList(ctx context.Context, options dockertypes.ContainerListOptions) ([]dockertypes.Container, error) {
+	var containers []dockertypes.Container
+	for _, container := range c.containers {
+		containers = append(containers, dockertypes.Container{
+			ID:     container.ID,
+			Labels: container.Config.Labels,
+		})
+	}
+	return containers, nil
+}
+
+func (c *fakeContainersClient) ContainerInspect(ctx context.Context, containerID string) (dockertypes.ContainerJSON, error) {
+	c.inspected++
+	for _, container := range c.containers {
+		if container.ID == containerID {
+			return container, nil
+		}
+	}
+	return dockertypes.ContainerJSON{}, nil
+}
+
+func TestListContainersFiltersBeforeInspection(t *testing.T) {
+	var containers []dockertypes.ContainerJSON
+	for i := 0; i < 20; i++ {
+		containerLabels := map[string]string{}
+		switch i % 4 {
+		case 0:
+			containerLabels["traefik.tags"] = "public"
+		case 1:
+			containerLabels["traefik.tags"] = "private"
+		case 2:
+			containerLabels["traefik.tags"] = "public"
+			containerLabels["traefik.enable"] = "false"
+		}
+
+		container := containerJSON(
+			name("test"+strconv.Itoa(i)),
+			labels(containerLabels),
+			ports(nat.PortMap{"80/tcp": {}}),
+			withNetwork("bridge", ipv4("127.0.0."+strconv.Itoa(i+1))),
+		)
+		container.ID = "id" + strconv.Itoa(i)
+		container.State = &dockertypes.ContainerState{Running: true}
+		containers = append(containers, container)
+	}
+
+	constraint, err := types.NewConstraint("tag==public")
+	require.NoError(t, err)
+
+	p := &Provider{
+		ExposedByDefault: true,
+		DefaultRule:      "Host(`{{ normalize .Name }}.traefik.wtf`)",
+	}
+	p.Constraints = []*types.Constraint{constraint}
+
+	err = p.Init()
+	require.NoError(t, err)
+
+	dockerClient := &fakeContainersClient{containers: containers}
+
+	dockerDataList, err := p.listContainers(context.Background(), dockerClient)
+	require.NoError(t, err)
+
+	// Only the enabled containers tagged public are inspected.
+	assert.Equal(t, 5, dockerClient.inspected)
+
+	// Inspecting every container gives the same configuration.
+	var unfiltered []dockerData
+	for _, container := range containers {
+		dData := parseContainer(container)
+		dData.ExtraConf, err = p.getConfiguration(dData)
+		require.NoError(t, err)
+		unfiltered = append(unfiltered, dData)
+	}
+
+	expected := p.buildConfiguration(context.Background(), unfiltered)
+	assert.Equal(t, expected, p.buildConfiguration(context.Background(), dockerDataList))
+	assert.Len(t, expected.HTTP.Services, 5)
+}
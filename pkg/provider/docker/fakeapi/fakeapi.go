@@ -0,0 +1,373 @@
+// Package fakeapi provides an in-memory fake Docker API, serving the subset of the endpoints used by the Docker provider.
+// It is meant to run the provider against scripted timelines in the tests.
+package fakeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	dockertypes "github.com/docker/docker/api/types"
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	swarmtypes "github.com/docker/docker/api/types/swarm"
+)
+
+// APIVersion is the API version reported by the fake server.
+const APIVersion = "1.24"
+
+var versionPrefix = regexp.MustCompile(`^/v[0-9.]+`)
+
+// Server is an in-memory fake Docker API.
+type Server struct {
+	server *httptest.Server
+
+	mu          sync.Mutex
+	containers  []dockertypes.ContainerJSON
+	services    []swarmtypes.Service
+	tasks       []swarmtypes.Task
+	networks    []dockertypes.NetworkResource
+	nodes       []swarmtypes.Node
+	streams     map[*eventStream]struct{}
+	connections int
+	requests    map[string]int
+}
+
+// NewServer starts a new fake Docker API.
+func NewServer() *Server {
+	s := &Server{
+		streams:  make(map[*eventStream]struct{}),
+		requests: make(map[string]int),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close drops the event streams, and shuts the server down.
+func (s *Server) Close() {
+	s.DropEventStreams()
+	s.server.Close()
+}
+
+// Endpoint returns the endpoint to configure the Docker provider with.
+func (s *Server) Endpoint() string {
+	return "tcp://" + s.server.Listener.Addr().String()
+}
+
+// Step is a step of a timeline.
+type Step struct {
+	Desc string
+	Do   func(s *Server)
+}
+
+// Apply applies the given step to the server.
+func (s *Server) Apply(step Step) {
+	step.Do(s)
+}
+
+// StartContainer adds (or replaces) a running container, and emits a start event.
+func (s *Server) StartContainer(container dockertypes.ContainerJSON) {
+	s.mu.Lock()
+	replaced := false
+	for i, c := range s.containers {
+		if c.ID == container.ID {
+			s.containers[i] = container
+			replaced = true
+		}
+	}
+	if !replaced {
+		s.containers = append(s.containers, container)
+	}
+	s.mu.Unlock()
+
+	s.Emit(containerEvent("start", container.ID))
+}
+
+// StopContainer removes a container, and emits a die event.
+func (s *Server) StopContainer(id string) {
+	s.mu.Lock()
+	var containers []dockertypes.ContainerJSON
+	for _, c := range s.containers {
+		if c.ID != id {
+			containers = append(containers, c)
+		}
+	}
+	s.containers = containers
+	s.mu.Unlock()
+
+	s.Emit(containerEvent("die", id))
+}
+
+// UpdateContainer replaces an existing container, and emits an update event.
+func (s *Server) UpdateContainer(container dockertypes.ContainerJSON) {
+	s.mu.Lock()
+	for i, c := range s.containers {
+		if c.ID == container.ID {
+			s.containers[i] = container
+		}
+	}
+	s.mu.Unlock()
+
+	s.Emit(containerEvent("update", container.ID))
+}
+
+// SetHealth changes the health status of a container, and emits the corresponding health_status event.
+func (s *Server) SetHealth(id, status string) {
+	s.mu.Lock()
+	for _, c := range s.containers {
+		if c.ID == id && c.ContainerJSONBase != nil && c.State != nil {
+			c.State.Health = &dockertypes.Health{Status: status}
+		}
+	}
+	s.mu.Unlock()
+
+	s.Emit(containerEvent("health_status: "+status, id))
+}
+
+// SetContainersSilently replaces the containers without emitting any event,
+// e.g. to simulate changes happening while the provider is disconnected.
+func (s *Server) SetContainersSilently(containers ...dockertypes.ContainerJSON) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.containers = containers
+}
+
+// SetSwarm replaces the swarm services, tasks and networks.
+func (s *Server) SetSwarm(services []swarmtypes.Service, tasks []swarmtypes.Task, networks []dockertypes.NetworkResource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.services = services
+	s.tasks = tasks
+	s.networks = networks
+}
+
+// SetNodes replaces the swarm nodes.
+func (s *Server) SetNodes(nodes ...swarmtypes.Node) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nodes = nodes
+}
+
+// Emit sends an event to all the connected event streams.
+func (s *Server) Emit(event eventtypes.Message) {
+	s.mu.Lock()
+	var streams []*eventStream
+	for stream := range s.streams {
+		streams = append(streams, stream)
+	}
+	s.mu.Unlock()
+
+	for _, stream := range streams {
+		select {
+		case stream.events <- event:
+		case <-stream.done:
+		}
+	}
+}
+
+// DropEventStreams closes the connected event streams, e.g. to simulate a restart of the daemon.
+func (s *Server) DropEventStreams() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for stream := range s.streams {
+		close(stream.drop)
+		delete(s.streams, stream)
+	}
+}
+
+// EventConnections returns the number of connections made to the event stream so far.
+func (s *Server) EventConnections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.connections
+}
+
+// Requests returns the number of requests made so far to the given path (without the version prefix), e.g. "/containers/json".
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[path]
+}
+
+func (s *Server) serveHTTP(rw http.ResponseWriter, req *http.Request) {
+	path := versionPrefix.ReplaceAllString(req.URL.Path, "")
+
+	s.mu.Lock()
+	s.requests[path]++
+	s.mu.Unlock()
+
+	switch {
+	case path == "/version":
+		writeJSON(rw, dockertypes.Version{Version: "fake", APIVersion: APIVersion})
+	case path == "/events":
+		s.serveEvents(rw, req)
+	case path == "/containers/json":
+		s.serveContainerList(rw)
+	case strings.HasPrefix(path, "/containers/") && strings.HasSuffix(path, "/json"):
+		s.serveContainerInspect(rw, strings.TrimSuffix(strings.TrimPrefix(path, "/containers/"), "/json"))
+	case path == "/services":
+		s.mu.Lock()
+		services := s.services
+		s.mu.Unlock()
+		writeJSON(rw, services)
+	case path == "/tasks":
+		s.serveTaskList(rw, req)
+	case path == "/networks":
+		s.mu.Lock()
+		networks := s.networks
+		s.mu.Unlock()
+		writeJSON(rw, networks)
+	case path == "/nodes":
+		s.mu.Lock()
+		nodes := s.nodes
+		s.mu.Unlock()
+		writeJSON(rw, nodes)
+	default:
+		writeError(rw, http.StatusNotFound, fmt.Sprintf("page not found: %s", req.URL.Path))
+	}
+}
+
+func (s *Server) serveContainerList(rw http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	containers := []dockertypes.Container{}
+	for _, c := range s.containers {
+		container := dockertypes.Container{ID: c.ID}
+		if c.ContainerJSONBase != nil {
+			container.Names = []string{c.Name}
+		}
+		if c.Config != nil {
+			container.Labels = c.Config.Labels
+		}
+		containers = append(containers, container)
+	}
+
+	writeJSON(rw, containers)
+}
+
+func (s *Server) serveContainerInspect(rw http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range s.containers {
+		if c.ID == id {
+			writeJSON(rw, c)
+			return
+		}
+	}
+
+	writeError(rw, http.StatusNotFound, fmt.Sprintf("No such container: %s", id))
+}
+
+func (s *Server) serveTaskList(rw http.ResponseWriter, req *http.Request) {
+	args, err := filters.FromJSON(req.URL.Query().Get("filters"))
+	if err != nil {
+		writeError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := []swarmtypes.Task{}
+	for _, task := range s.tasks {
+		if args.Contains("service") && !args.ExactMatch("service", task.ServiceID) {
+			continue
+		}
+		if args.Contains("desired-state") && !args.ExactMatch("desired-state", string(task.DesiredState)) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+
+	writeJSON(rw, tasks)
+}
+
+func (s *Server) serveEvents(rw http.ResponseWriter, req *http.Request) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		writeError(rw, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	stream := &eventStream{
+		events: make(chan eventtypes.Message),
+		drop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	s.streams[stream] = struct{}{}
+	s.connections++
+	s.mu.Unlock()
+
+	defer func() {
+		close(stream.done)
+
+		s.mu.Lock()
+		delete(s.streams, stream)
+		s.mu.Unlock()
+	}()
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	encoder := json.NewEncoder(rw)
+	for {
+		select {
+		case event := <-stream.events:
+			if err := encoder.Encode(event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-stream.drop:
+			return
+		case <-req.Context().Done():
+			return
+		}
+	}
+}
+
+// eventStream is a connection to the event stream.
+type eventStream struct {
+	events chan eventtypes.Message
+	drop   chan struct{}
+	done   chan struct{}
+}
+
+func containerEvent(action, id string) eventtypes.Message {
+	now := time.Now()
+	return eventtypes.Message{
+		Type:     eventtypes.ContainerEventType,
+		Action:   action,
+		Actor:    eventtypes.Actor{ID: id},
+		Time:     now.Unix(),
+		TimeNano: now.UnixNano(),
+	}
+}
+
+func writeJSON(rw http.ResponseWriter, data interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(data); err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeError(rw http.ResponseWriter, status int, message string) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_ = json.NewEncoder(rw).Encode(map[string]string{"message": message})
+}
@@ -0,0 +1,417 @@
+package docker
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/provider/docker/fakeapi"
+	"github.com/containous/traefik/pkg/safe"
+	"github.com/containous/traefik/pkg/types"
+	docker "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a clock which only moves forward when advanced by the tests.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTicker{
+		c:      make(chan time.Time, 1),
+		period: d,
+		next:   c.now.Add(d),
+	}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+func (c *fakeClock) tickerCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.tickers)
+}
+
+// Advance moves the clock forward, and fires the tickers which are due.
+// As with a time.Ticker, the ticks are dropped when the receiver is too slow.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	for _, t := range c.tickers {
+		t.fire(c.now)
+	}
+}
+
+type fakeTicker struct {
+	mu      sync.Mutex
+	c       chan time.Time
+	period  time.Duration
+	next    time.Time
+	stopped bool
+}
+
+func (t *fakeTicker) Chan() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stopped = true
+}
+
+func (t *fakeTicker) fire(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.stopped || now.Before(t.next) {
+		return
+	}
+
+	for !now.Before(t.next) {
+		t.next = t.next.Add(t.period)
+	}
+
+	select {
+	case t.c <- now:
+	default:
+	}
+}
+
+// publishedConfiguration is a configuration published by the provider, along with the (fake) time of its publication.
+type publishedConfiguration struct {
+	At            time.Time
+	Configuration *config.Configuration
+}
+
+// harness runs the real provider against a fake Docker API, and captures the published configurations.
+type harness struct {
+	t              *testing.T
+	api            *fakeapi.Server
+	clock          *fakeClock
+	pool           *safe.Pool
+	configurations chan config.Message
+	published      []publishedConfiguration
+}
+
+func newHarness(t *testing.T) *harness {
+	return &harness{
+		t:              t,
+		api:            fakeapi.NewServer(),
+		clock:          newFakeClock(),
+		configurations: make(chan config.Message, 100),
+	}
+}
+
+// start runs the provider against the fake Docker API.
+func (h *harness) start(p *Provider) {
+	h.t.Helper()
+
+	p.Endpoint = h.api.Endpoint()
+	p.Watch = true
+	p.clock = h.clock
+	p.newBackOff = func() backoff.BackOff {
+		return backoff.NewConstantBackOff(10 * time.Millisecond)
+	}
+
+	err := p.Init()
+	require.NoError(h.t, err)
+
+	h.pool = safe.NewPool(context.Background())
+
+	err = p.Provide(h.configurations, h.pool)
+	require.NoError(h.t, err)
+}
+
+func (h *harness) stop() {
+	if h.pool != nil {
+		h.pool.Stop()
+	}
+	h.api.Close()
+}
+
+// next waits for the next published configuration.
+func (h *harness) next() *config.Configuration {
+	h.t.Helper()
+
+	select {
+	case message := <-h.configurations:
+		h.published = append(h.published, publishedConfiguration{At: h.clock.Now(), Configuration: message.Configuration})
+		return message.Configuration
+	case <-time.After(5 * time.Second):
+		h.t.Fatal("Timeout while waiting for a configuration")
+		return nil
+	}
+}
+
+// waitEventConnections waits for the provider to be connected the given number of times to the event stream.
+func (h *harness) waitEventConnections(connections int) {
+	h.t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for h.api.EventConnections() < connections {
+		if time.Now().After(deadline) {
+			h.t.Fatalf("Timeout while waiting for %d connections to the event stream, got %d", connections, h.api.EventConnections())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// waitTickers waits for the provider to create the given number of tickers.
+func (h *harness) waitTickers(tickers int) {
+	h.t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for h.clock.tickerCount() < tickers {
+		if time.Now().After(deadline) {
+			h.t.Fatalf("Timeout while waiting for %d tickers, got %d", tickers, h.clock.tickerCount())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// play applies the steps of the timeline one by one, and returns the configuration published after each of them.
+func (h *harness) play(steps ...fakeapi.Step) []*config.Configuration {
+	h.t.Helper()
+
+	var configurations []*config.Configuration
+	for _, step := range steps {
+		h.api.Apply(step)
+
+		configuration := h.next()
+		require.NotNil(h.t, configuration, step.Desc)
+		configurations = append(configurations, configuration)
+	}
+	return configurations
+}
+
+func runningContainer(id string, ops ...func(*docker.ContainerJSON)) docker.ContainerJSON {
+	c := containerJSON(append([]func(*docker.ContainerJSON){running}, ops...)...)
+	c.ID = id
+	return c
+}
+
+func running(c *docker.ContainerJSON) {
+	c.State = &docker.ContainerState{Running: true}
+}
+
+func health(status string) func(*docker.ContainerJSON) {
+	return func(c *docker.ContainerJSON) {
+		c.State.Health = &docker.Health{Status: status}
+	}
+}
+
+func serverURLs(configuration *config.Configuration, serviceName string) []string {
+	service, ok := configuration.HTTP.Services[serviceName]
+	if !ok || service.LoadBalancer == nil {
+		return nil
+	}
+
+	var urls []string
+	for _, server := range service.LoadBalancer.Servers {
+		urls = append(urls, server.URL)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+func TestHarnessHealthFlap(t *testing.T) {
+	h := newHarness(t)
+	defer h.stop()
+
+	// The container is created before the provider connects, with a health check still starting.
+	h.api.SetContainersSilently(runningContainer("c1",
+		name("whoami"),
+		ports(nat.PortMap{"80/tcp": {}}),
+		withNetwork("bridge", ipv4("127.0.0.1")),
+		health(docker.Starting),
+	))
+
+	h.start(&Provider{ExposedByDefault: true, DefaultRule: DefaultTemplateRule})
+
+	assert.Empty(t, serverURLs(h.next(), "whoami"))
+	h.waitEventConnections(1)
+
+	configurations := h.play(
+		fakeapi.Step{Desc: "healthy", Do: func(s *fakeapi.Server) { s.SetHealth("c1", docker.Healthy) }},
+		fakeapi.Step{Desc: "unhealthy", Do: func(s *fakeapi.Server) { s.SetHealth("c1", docker.Unhealthy) }},
+		fakeapi.Step{Desc: "healthy again", Do: func(s *fakeapi.Server) { s.SetHealth("c1", docker.Healthy) }},
+		fakeapi.Step{Desc: "stopped", Do: func(s *fakeapi.Server) { s.StopContainer("c1") }},
+	)
+
+	expected := [][]string{
+		{"http://127.0.0.1:80"},
+		nil,
+		{"http://127.0.0.1:80"},
+		nil,
+	}
+	for i, configuration := range configurations {
+		assert.Equal(t, expected[i], serverURLs(configuration, "whoami"), "step %d", i)
+	}
+
+	assert.Equal(t, 1, h.api.EventConnections())
+}
+
+func TestHarnessEventReconnect(t *testing.T) {
+	h := newHarness(t)
+	defer h.stop()
+
+	h.api.SetContainersSilently(runningContainer("c1",
+		name("whoami"),
+		ports(nat.PortMap{"80/tcp": {}}),
+		withNetwork("bridge", ipv4("127.0.0.1")),
+	))
+
+	h.start(&Provider{ExposedByDefault: true, DefaultRule: DefaultTemplateRule})
+
+	assert.Equal(t, []string{"http://127.0.0.1:80"}, serverURLs(h.next(), "whoami"))
+	h.waitEventConnections(1)
+
+	// The changes happening while the event stream is down are picked up on reconnection.
+	configurations := h.play(fakeapi.Step{
+		Desc: "daemon restart",
+		Do: func(s *fakeapi.Server) {
+			s.SetContainersSilently(
+				runningContainer("c1",
+					name("whoami"),
+					ports(nat.PortMap{"80/tcp": {}}),
+					withNetwork("bridge", ipv4("127.0.0.1")),
+				),
+				runningContainer("c2",
+					name("whoami2"),
+					labels(map[string]string{"traefik.http.services.whoami.loadbalancer.server.port": "80"}),
+					withNetwork("bridge", ipv4("127.0.0.2")),
+				),
+			)
+			s.DropEventStreams()
+		},
+	})
+
+	assert.Equal(t, []string{"http://127.0.0.1:80", "http://127.0.0.2:80"}, serverURLs(configurations[0], "whoami"))
+
+	h.waitEventConnections(2)
+
+	// The events are received again once reconnected.
+	configurations = h.play(fakeapi.Step{Desc: "stopped", Do: func(s *fakeapi.Server) { s.StopContainer("c1") }})
+	assert.Equal(t, []string{"http://127.0.0.2:80"}, serverURLs(configurations[0], "whoami"))
+}
+
+func TestHarnessWeightUpdate(t *testing.T) {
+	h := newHarness(t)
+	defer h.stop()
+
+	container := func(id, ip, weight string) docker.ContainerJSON {
+		return runningContainer(id,
+			name("app-"+id),
+			labels(map[string]string{"traefik.http.services.app.loadbalancer.server.weight": weight}),
+			ports(nat.PortMap{"80/tcp": {}}),
+			withNetwork("bridge", ipv4(ip)),
+		)
+	}
+
+	h.api.SetContainersSilently(container("blue", "127.0.0.1", "100"), container("green", "127.0.0.2", "1"))
+
+	h.start(&Provider{ExposedByDefault: true, DefaultRule: DefaultTemplateRule})
+
+	weights := func(configuration *config.Configuration) map[string]int {
+		result := make(map[string]int)
+		for _, server := range configuration.HTTP.Services["app"].LoadBalancer.Servers {
+			result[server.URL] = server.Weight
+		}
+		return result
+	}
+
+	assert.Equal(t, map[string]int{"http://127.0.0.1:80": 100, "http://127.0.0.2:80": 1}, weights(h.next()))
+	h.waitEventConnections(1)
+
+	// Changing only the weight is picked up through the update event.
+	configurations := h.play(
+		fakeapi.Step{Desc: "canary", Do: func(s *fakeapi.Server) { s.UpdateContainer(container("green", "127.0.0.2", "10")) }},
+		fakeapi.Step{Desc: "flip", Do: func(s *fakeapi.Server) {
+			s.UpdateContainer(container("blue", "127.0.0.1", "1"))
+		}},
+	)
+
+	assert.Equal(t, map[string]int{"http://127.0.0.1:80": 100, "http://127.0.0.2:80": 10}, weights(configurations[0]))
+	assert.Equal(t, map[string]int{"http://127.0.0.1:80": 1, "http://127.0.0.2:80": 10}, weights(configurations[1]))
+}
+
+func TestHarnessSwarmPolling(t *testing.T) {
+	networks := []docker.NetworkResource{{ID: "net1", Name: "overlay"}}
+
+	service := swarmService(
+		serviceName("whoami"),
+		serviceLabels(map[string]string{"traefik.http.services.whoami.loadbalancer.server.port": "80"}),
+		withEndpointSpec(modeDNSSR),
+	)
+
+	task := func(id string, slot int, addr string) swarm.Task {
+		t := swarmTask(id,
+			taskSlot(slot),
+			taskNetworkAttachment("net1", "overlay", "overlay", []string{addr}),
+			taskStatus(taskState(swarm.TaskStateRunning)),
+		)
+		t.ServiceID = service.ID
+		return t
+	}
+
+	p := &Provider{
+		ExposedByDefault:        true,
+		DefaultRule:             DefaultTemplateRule,
+		SwarmMode:               true,
+		SwarmModeRefreshSeconds: types.Duration(15 * time.Second),
+	}
+	h := newHarness(t)
+	defer h.stop()
+
+	h.api.SetSwarm([]swarm.Service{service}, []swarm.Task{task("t1", 1, "10.0.0.1/24")}, networks)
+
+	h.start(p)
+
+	assert.Equal(t, []string{"http://10.0.0.1:80"}, serverURLs(h.next(), "whoami"))
+	h.waitTickers(1)
+
+	h.api.SetSwarm([]swarm.Service{service}, []swarm.Task{task("t1", 1, "10.0.0.1/24"), task("t2", 2, "10.0.0.2/24")}, networks)
+
+	// Nothing is published until the refresh interval elapses.
+	h.clock.Advance(10 * time.Second)
+	select {
+	case <-h.configurations:
+		t.Fatal("Unexpected configuration published before the refresh interval")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	h.clock.Advance(5 * time.Second)
+	assert.Equal(t, []string{"http://10.0.0.1:80", "http://10.0.0.2:80"}, serverURLs(h.next(), "whoami"))
+
+	require.Len(t, h.published, 2)
+	assert.Equal(t, 15*time.Second, h.published[1].At.Sub(h.published[0].At))
+}
@@ -2,6 +2,7 @@ package docker
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/containous/traefik/pkg/config/label"
 )
@@ -9,6 +10,7 @@ import (
 const (
 	labelDockerComposeProject = "com.docker.compose.project"
 	labelDockerComposeService = "com.docker.compose.service"
+	labelDockerHostPorts      = "traefik.docker.hostPorts"
 )
 
 // configuration Contains information from the labels that are globals (not related to the dynamic configuration) or specific to the provider.
@@ -19,15 +21,21 @@ type configuration struct {
 }
 
 type specificConfiguration struct {
-	Network string
-	LBSwarm bool
+	Network                 string
+	LBSwarm                 bool
+	HostPorts               []string
+	CreateDefaultRouter     bool
+	CreateDefaultTCPService bool
+	Deployment              string
 }
 
 func (p *Provider) getConfiguration(container dockerData) (configuration, error) {
 	conf := configuration{
 		Enable: p.ExposedByDefault,
 		Docker: specificConfiguration{
-			Network: p.Network,
+			Network:                 p.Network,
+			CreateDefaultRouter:     p.CreateDefaultRouter,
+			CreateDefaultTCPService: p.CreateDefaultTCPService,
 		},
 	}
 
@@ -36,6 +44,13 @@ func (p *Provider) getConfiguration(container dockerData) (configuration, error)
 		return configuration{}, err
 	}
 
+	for _, hostPort := range conf.Docker.HostPorts {
+		port, err := strconv.Atoi(hostPort)
+		if err != nil || port <= 0 || port > 65535 {
+			return configuration{}, fmt.Errorf("invalid port %q in label %s", hostPort, labelDockerHostPorts)
+		}
+	}
+
 	return conf, nil
 }
 
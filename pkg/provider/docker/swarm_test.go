@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/containous/traefik/pkg/config"
 	"github.com/davecgh/go-spew/spew"
 	docker "github.com/docker/docker/api/types"
 	dockertypes "github.com/docker/docker/api/types"
@@ -88,8 +89,12 @@ func TestListTasks(t *testing.T) {
 			dockerData, err := p.parseService(context.Background(), test.service, test.networks)
 			require.NoError(t, err)
 
+			if test.isGlobalSVC {
+				test.service.Spec.Mode.Global = &swarm.GlobalService{}
+			}
+
 			dockerClient := &fakeTasksClient{tasks: test.tasks}
-			taskDockerData, _ := listTasks(context.Background(), dockerClient, test.service.ID, dockerData, test.networks, test.isGlobalSVC)
+			taskDockerData, _ := listTasks(context.Background(), dockerClient, test.service, dockerData, test.networks, false)
 
 			if len(test.expectedTasks) != len(taskDockerData) {
 				t.Errorf("expected tasks %v, got %v", spew.Sdump(test.expectedTasks), spew.Sdump(taskDockerData))
@@ -104,12 +109,183 @@ func TestListTasks(t *testing.T) {
 	}
 }
 
+func TestListTasksDuringUpdate(t *testing.T) {
+	networks := map[string]*docker.NetworkResource{
+		"1": {
+			Name: "foo",
+		},
+	}
+
+	// Slot 1 is being replaced: the old task is still running while the new one is starting.
+	// Slot 2 has been replaced: the old task is still running while the new one is running.
+	// Slot 3 is not updated yet.
+	replicatedTasks := []swarm.Task{
+		swarmTask("old1",
+			taskSlot(1),
+			taskDesiredState(swarm.TaskStateShutdown),
+			taskNetworkAttachment("1", "network1", "overlay", []string{"127.0.0.1"}),
+			taskStatus(taskState(swarm.TaskStateRunning)),
+		),
+		swarmTask("new1",
+			taskSlot(1),
+			taskNetworkAttachment("1", "network1", "overlay", []string{"127.0.0.11"}),
+			taskStatus(taskState(swarm.TaskStateStarting)),
+		),
+		swarmTask("old2",
+			taskSlot(2),
+			taskDesiredState(swarm.TaskStateShutdown),
+			taskNetworkAttachment("1", "network1", "overlay", []string{"127.0.0.2"}),
+			taskStatus(taskState(swarm.TaskStateRunning)),
+		),
+		swarmTask("new2",
+			taskSlot(2),
+			taskNetworkAttachment("1", "network1", "overlay", []string{"127.0.0.12"}),
+			taskStatus(taskState(swarm.TaskStateRunning)),
+		),
+		swarmTask("old3",
+			taskSlot(3),
+			taskNetworkAttachment("1", "network1", "overlay", []string{"127.0.0.3"}),
+			taskStatus(taskState(swarm.TaskStateRunning)),
+		),
+		swarmTask("older3",
+			taskSlot(3),
+			taskDesiredState(swarm.TaskStateShutdown),
+			taskNetworkAttachment("1", "network1", "overlay", []string{"127.0.0.23"}),
+			taskStatus(taskState(swarm.TaskStateShutdown)),
+		),
+	}
+
+	globalTasks := []swarm.Task{
+		swarmTask("old1",
+			taskNodeID("node1"),
+			taskDesiredState(swarm.TaskStateShutdown),
+			taskNetworkAttachment("1", "network1", "overlay", []string{"127.0.0.1"}),
+			taskStatus(taskState(swarm.TaskStateRunning)),
+		),
+		swarmTask("new1",
+			taskNodeID("node1"),
+			taskNetworkAttachment("1", "network1", "overlay", []string{"127.0.0.11"}),
+			taskStatus(taskState(swarm.TaskStatePreparing)),
+		),
+		swarmTask("old2",
+			taskNodeID("node2"),
+			taskDesiredState(swarm.TaskStateShutdown),
+			taskNetworkAttachment("1", "network1", "overlay", []string{"127.0.0.2"}),
+			taskStatus(taskState(swarm.TaskStateRunning)),
+		),
+		swarmTask("new2",
+			taskNodeID("node2"),
+			taskNetworkAttachment("1", "network1", "overlay", []string{"127.0.0.12"}),
+			taskStatus(taskState(swarm.TaskStateRunning)),
+		),
+	}
+
+	testCases := []struct {
+		desc              string
+		service           swarm.Service
+		tasks             []swarm.Task
+		keepReplacedTasks bool
+		expectedTasks     []string
+	}{
+		{
+			desc:          "no update in progress",
+			service:       swarmService(serviceName("container")),
+			tasks:         replicatedTasks,
+			expectedTasks: []string{"new2", "old3"},
+		},
+		{
+			desc: "stop-first update in progress",
+			service: swarmService(
+				serviceName("container"),
+				serviceUpdateState(swarm.UpdateStateUpdating),
+			),
+			tasks:         replicatedTasks,
+			expectedTasks: []string{"new2", "old3"},
+		},
+		{
+			desc: "stop-first update in progress, keeping replaced tasks",
+			service: swarmService(
+				serviceName("container"),
+				serviceUpdateState(swarm.UpdateStateUpdating),
+			),
+			tasks:             replicatedTasks,
+			keepReplacedTasks: true,
+			expectedTasks:     []string{"old1", "new2", "old3"},
+		},
+		{
+			desc: "stop-first rollback in progress, keeping replaced tasks",
+			service: swarmService(
+				serviceName("container"),
+				serviceUpdateState(swarm.UpdateStateRollbackStarted),
+				serviceUpdateOrder(swarm.UpdateOrderStopFirst),
+			),
+			tasks:             replicatedTasks,
+			keepReplacedTasks: true,
+			expectedTasks:     []string{"old1", "new2", "old3"},
+		},
+		{
+			desc: "start-first update in progress, keeping replaced tasks",
+			service: swarmService(
+				serviceName("container"),
+				serviceUpdateState(swarm.UpdateStateUpdating),
+				serviceUpdateOrder(swarm.UpdateOrderStartFirst),
+			),
+			tasks:             replicatedTasks,
+			keepReplacedTasks: true,
+			expectedTasks:     []string{"new2", "old3"},
+		},
+		{
+			desc: "paused update, keeping replaced tasks",
+			service: swarmService(
+				serviceName("container"),
+				serviceUpdateState(swarm.UpdateStatePaused),
+			),
+			tasks:             replicatedTasks,
+			keepReplacedTasks: true,
+			expectedTasks:     []string{"new2", "old3"},
+		},
+		{
+			desc: "global service, stop-first update in progress, keeping replaced tasks",
+			service: swarmService(
+				serviceName("container"),
+				globalMode(),
+				serviceUpdateState(swarm.UpdateStateUpdating),
+			),
+			tasks:             globalTasks,
+			keepReplacedTasks: true,
+			expectedTasks:     []string{"old1", "new2"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := Provider{}
+			dockerData, err := p.parseService(context.Background(), test.service, networks)
+			require.NoError(t, err)
+
+			dockerClient := &fakeTasksClient{tasks: test.tasks}
+			taskDockerData, err := listTasks(context.Background(), dockerClient, test.service, dockerData, networks, test.keepReplacedTasks)
+			require.NoError(t, err)
+
+			var taskIDs []string
+			for _, task := range taskDockerData {
+				taskIDs = append(taskIDs, task.ID)
+			}
+			assert.Equal(t, test.expectedTasks, taskIDs)
+		})
+	}
+}
+
 type fakeServicesClient struct {
 	dockerclient.APIClient
 	dockerVersion string
 	networks      []dockertypes.NetworkResource
 	services      []swarm.Service
 	tasks         []swarm.Task
+	nodes         []swarm.Node
 	err           error
 }
 
@@ -129,6 +305,10 @@ func (c *fakeServicesClient) TaskList(ctx context.Context, options dockertypes.T
 	return c.tasks, c.err
 }
 
+func (c *fakeServicesClient) NodeList(ctx context.Context, options dockertypes.NodeListOptions) ([]swarm.Node, error) {
+	return c.nodes, c.err
+}
+
 func TestListServices(t *testing.T) {
 	testCases := []struct {
 		desc             string
@@ -410,3 +590,100 @@ func TestSwarmTaskParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestParseServiceUseContainerLabels(t *testing.T) {
+	service := swarmService(
+		serviceName("whoami"),
+		serviceLabels(map[string]string{
+			"traefik.http.routers.whoami.rule": "Host(`service.localhost`)",
+			"traefik.docker.network":           "overlay",
+		}),
+		containerLabels(map[string]string{
+			"traefik.http.routers.whoami.Rule":                      "Host(`container.localhost`)",
+			"traefik.http.services.whoami.loadbalancer.server.port": "8080",
+		}),
+	)
+
+	testCases := []struct {
+		desc               string
+		useContainerLabels bool
+		expected           map[string]string
+	}{
+		{
+			desc: "container labels ignored",
+			expected: map[string]string{
+				"traefik.http.routers.whoami.rule": "Host(`service.localhost`)",
+				"traefik.docker.network":           "overlay",
+			},
+		},
+		{
+			desc:               "container labels overridden by the service labels",
+			useContainerLabels: true,
+			expected: map[string]string{
+				"traefik.http.routers.whoami.rule":                      "Host(`service.localhost`)",
+				"traefik.docker.network":                                "overlay",
+				"traefik.http.services.whoami.loadbalancer.server.port": "8080",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := Provider{UseContainerLabels: test.useContainerLabels}
+
+			dData, err := p.parseService(context.Background(), service, nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, dData.Labels)
+			assert.Equal(t, "overlay", dData.ExtraConf.Docker.Network)
+		})
+	}
+}
+
+func TestListServicesUsePublishedPort(t *testing.T) {
+	services := []swarm.Service{
+		swarmService(
+			serviceName("whoami"),
+			serviceLabels(map[string]string{
+				"traefik.http.services.whoami.loadbalancer.server.port": "80",
+			}),
+			withEndpointSpec(modeVIP),
+			withEndpoint(
+				virtualIP("1", "10.11.12.13/24"),
+				publishedPort(80, 30080),
+			)),
+	}
+
+	nodes := []swarm.Node{
+		swarmNode("node1", "192.168.0.1", swarm.NodeStateReady),
+		swarmNode("node2", "192.168.0.2", swarm.NodeStateReady),
+		swarmNode("node3", "192.168.0.3", swarm.NodeStateDown),
+	}
+
+	dockerClient := &fakeServicesClient{services: services, nodes: nodes, dockerVersion: "1.30"}
+
+	p := Provider{
+		SwarmMode:        true,
+		UsePublishedPort: true,
+		ExposedByDefault: true,
+		DefaultRule:      "Host(`{{ normalize .Name }}.traefik.wtf`)",
+	}
+	err := p.Init()
+	require.NoError(t, err)
+
+	dockerDataList, err := p.listServices(context.Background(), dockerClient)
+	require.NoError(t, err)
+	require.Len(t, dockerDataList, 2)
+
+	configuration := p.buildConfiguration(context.Background(), dockerDataList)
+
+	require.Contains(t, configuration.HTTP.Services, "whoami")
+	expected := []config.Server{
+		{URL: "http://192.168.0.1:30080"},
+		{URL: "http://192.168.0.2:30080"},
+	}
+	assert.Equal(t, expected, configuration.HTTP.Services["whoami"].LoadBalancer.Servers)
+}
@@ -0,0 +1,146 @@
+package file
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/config/parser"
+)
+
+// collectDecodeErrors decodes the TOML content key by key, down to the values, into the fields of the configuration,
+// so that the strict validation reports all the values which do not fit their field at once,
+// rather than the first one the TOML decoder stops at.
+// Each error is located by the key of the value, e.g. http.routers.router1.priority.
+// The keys which do not match any field are skipped, as they are reported by checkUnknownFields.
+// It returns no error for a TOML syntax error, which the TOML decoder reports as is.
+func collectDecodeErrors(content string) parser.DecodeErrors {
+	var root map[string]toml.Primitive
+
+	metadata, err := toml.Decode(content, &root)
+	if err != nil {
+		return nil
+	}
+
+	var errs parser.DecodeErrors
+
+	rootType := reflect.TypeOf(config.Configuration{})
+	for _, key := range sortedKeys(root) {
+		field, ok := lookupTOMLField(rootType, key)
+		if !ok {
+			continue
+		}
+
+		errs = append(errs, decodePrimitive(&metadata, root[key], field.Type, toml.Key{key}.String())...)
+	}
+
+	return errs
+}
+
+// decodePrimitive decodes the primitive, at the given key, into a value of type rType,
+// going down the tables into their keys, and returns the errors of all the values.
+func decodePrimitive(metadata *toml.MetaData, primitive toml.Primitive, rType reflect.Type, key string) []error {
+	for rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+
+	switch {
+	case isTable(rType):
+		var fields map[string]toml.Primitive
+		if err := metadata.PrimitiveDecode(primitive, &fields); err != nil {
+			return []error{fmt.Errorf("%s: %v", key, err)}
+		}
+
+		var errs []error
+		for _, name := range sortedKeys(fields) {
+			field, ok := lookupTOMLField(rType, name)
+			if !ok {
+				continue
+			}
+
+			errs = append(errs, decodePrimitive(metadata, fields[name], field.Type, key+"."+toml.Key{name}.String())...)
+		}
+		return errs
+
+	case rType.Kind() == reflect.Map && rType.Key().Kind() == reflect.String && isTable(rType.Elem()):
+		var entries map[string]toml.Primitive
+		if err := metadata.PrimitiveDecode(primitive, &entries); err != nil {
+			return []error{fmt.Errorf("%s: %v", key, err)}
+		}
+
+		var errs []error
+		for _, name := range sortedKeys(entries) {
+			errs = append(errs, decodePrimitive(metadata, entries[name], rType.Elem(), key+"."+toml.Key{name}.String())...)
+		}
+		return errs
+
+	case rType.Kind() == reflect.Slice && isTable(rType.Elem()):
+		var items []toml.Primitive
+		if err := metadata.PrimitiveDecode(primitive, &items); err != nil {
+			return []error{fmt.Errorf("%s: %v", key, err)}
+		}
+
+		var errs []error
+		for i, item := range items {
+			errs = append(errs, decodePrimitive(metadata, item, rType.Elem(), key+"["+strconv.Itoa(i)+"]")...)
+		}
+		return errs
+
+	default:
+		if err := metadata.PrimitiveDecode(primitive, reflect.New(rType).Interface()); err != nil {
+			return []error{fmt.Errorf("%s: %v", key, err)}
+		}
+		return nil
+	}
+}
+
+// isTable tells whether the values of the type are TOML tables, decoded key by key.
+func isTable(rType reflect.Type) bool {
+	for rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+
+	if rType.Kind() != reflect.Struct || rType == reflect.TypeOf(time.Time{}) {
+		return false
+	}
+
+	return !reflect.PtrTo(rType).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}
+
+// lookupTOMLField returns the field of the struct matching the key, as the TOML decoder does:
+// by the name of its toml tag, or by its name, case-insensitively, the fields of the embedded structs included.
+func lookupTOMLField(rType reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < rType.NumField(); i++ {
+		field := rType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("toml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && name == "" {
+			if embedded, ok := lookupTOMLField(field.Type, key); ok {
+				return embedded, true
+			}
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,118 @@
+package file
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+)
+
+// envVarRegexp matches ${VAR} and ${VAR:-default}.
+// Anything else starting with a $, such as a regular expression anchor in a rule, is left untouched.
+var envVarRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-[^}]*)?\}`)
+
+// substituteEnv expands the environment variables referenced by the string values of the TOML content.
+// The content is decoded as an untyped tree, so that only the values are expanded, and encoded back.
+func substituteEnv(content string) (string, error) {
+	data := make(map[string]interface{})
+	if _, err := toml.Decode(content, &data); err != nil {
+		return "", err
+	}
+
+	if err := substituteEnvMap(data, ""); err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	if err := toml.NewEncoder(&buffer).Encode(data); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+func substituteEnvMap(data map[string]interface{}, path string) error {
+	// The keys are sorted, so that the first undefined variable is always the same one.
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := data[key]
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+
+		expanded, err := substituteEnvValue(value, keyPath)
+		if err != nil {
+			return err
+		}
+		data[key] = expanded
+	}
+
+	return nil
+}
+
+func substituteEnvValue(value interface{}, path string) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return expandEnv(v, path)
+
+	case map[string]interface{}:
+		return v, substituteEnvMap(v, path)
+
+	case []map[string]interface{}:
+		for i, item := range v {
+			if err := substituteEnvMap(item, path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return nil, err
+			}
+		}
+		return v, nil
+
+	case []interface{}:
+		for i, item := range v {
+			expanded, err := substituteEnvValue(item, path+"["+strconv.Itoa(i)+"]")
+			if err != nil {
+				return nil, err
+			}
+			v[i] = expanded
+		}
+		return v, nil
+
+	default:
+		return v, nil
+	}
+}
+
+// expandEnv replaces the references to environment variables in value.
+// As in a shell, the default value is used when the variable is either undefined or empty.
+func expandEnv(value, path string) (string, error) {
+	var err error
+
+	expanded := envVarRegexp.ReplaceAllStringFunc(value, func(match string) string {
+		groups := envVarRegexp.FindStringSubmatch(match)
+		name, defaultValue := groups[1], groups[2]
+
+		envValue, ok := os.LookupEnv(name)
+		if defaultValue != "" && envValue == "" {
+			return defaultValue[len(":-"):]
+		}
+
+		if !ok && err == nil {
+			err = fmt.Errorf("undefined environment variable %s in %s", name, path)
+		}
+		return envValue
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return expanded, nil
+}
@@ -0,0 +1,175 @@
+package file
+
+import (
+	"os"
+	"testing"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envConfiguration = `
+[http.routers]
+  [http.routers.router1]
+    service = "service1"
+    rule = "Host(` + "`${TEST_FILE_HOST:-example.com}`" + `) && PathPrefix(` + "`/{id:[0-9]+}$`" + `)"
+
+[http.services]
+  [http.services.service1]
+    [http.services.service1.loadBalancer]
+      [[http.services.service1.loadBalancer.servers]]
+        url = "http://${TEST_FILE_SERVER}:80"
+      [[http.services.service1.loadBalancer.servers]]
+        url = "http://${TEST_FILE_BACKUP:-10.0.0.2}:80"
+`
+
+func TestDecodeConfigurationEnvSubstitution(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		envSubstitution  bool
+		env              map[string]string
+		expectedRule     string
+		expectedURLs     []string
+		expectedErrorMsg string
+	}{
+		{
+			desc:         "disabled",
+			expectedRule: "Host(`${TEST_FILE_HOST:-example.com}`) && PathPrefix(`/{id:[0-9]+}$`)",
+			expectedURLs: []string{"http://${TEST_FILE_SERVER}:80", "http://${TEST_FILE_BACKUP:-10.0.0.2}:80"},
+		},
+		{
+			desc:            "defined variables",
+			envSubstitution: true,
+			env: map[string]string{
+				"TEST_FILE_HOST":   "traefik.io",
+				"TEST_FILE_SERVER": "10.0.0.1",
+				"TEST_FILE_BACKUP": "10.0.0.3",
+			},
+			expectedRule: "Host(`traefik.io`) && PathPrefix(`/{id:[0-9]+}$`)",
+			expectedURLs: []string{"http://10.0.0.1:80", "http://10.0.0.3:80"},
+		},
+		{
+			desc:            "default values",
+			envSubstitution: true,
+			env: map[string]string{
+				"TEST_FILE_HOST":   "",
+				"TEST_FILE_SERVER": "10.0.0.1",
+			},
+			expectedRule: "Host(`example.com`) && PathPrefix(`/{id:[0-9]+}$`)",
+			expectedURLs: []string{"http://10.0.0.1:80", "http://10.0.0.2:80"},
+		},
+		{
+			desc:             "undefined variable",
+			envSubstitution:  true,
+			expectedErrorMsg: "undefined environment variable TEST_FILE_SERVER in http.services.service1.loadBalancer.servers[0].url",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			for _, name := range []string{"TEST_FILE_HOST", "TEST_FILE_SERVER", "TEST_FILE_BACKUP"} {
+				if value, ok := test.env[name]; ok {
+					require.NoError(t, os.Setenv(name, value))
+				} else {
+					require.NoError(t, os.Unsetenv(name))
+				}
+				defer func(name string) { _ = os.Unsetenv(name) }(name)
+			}
+
+			provider := &Provider{EnvSubstitution: test.envSubstitution}
+
+			conf, err := provider.DecodeConfiguration(envConfiguration)
+			if test.expectedErrorMsg != "" {
+				assert.EqualError(t, err, test.expectedErrorMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedRule, conf.HTTP.Routers["router1"].Rule)
+
+			var urls []string
+			for _, server := range conf.HTTP.Services["service1"].LoadBalancer.Servers {
+				urls = append(urls, server.URL)
+			}
+			assert.Equal(t, test.expectedURLs, urls)
+		})
+	}
+}
+
+func TestLoadFileConfigEnvSubstitutionError(t *testing.T) {
+	require.NoError(t, os.Unsetenv("TEST_FILE_SERVER"))
+
+	tempDir := createTempDir(t, "testfiletemplate")
+	defer os.RemoveAll(tempDir)
+
+	file := createFile(t, tempDir, "dynamic.toml", envConfiguration)
+
+	provider := &Provider{
+		Filename:        file.Name(),
+		EnvSubstitution: true,
+	}
+
+	_, err := provider.BuildConfiguration()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), file.Name())
+	assert.Contains(t, err.Error(), "http.services.service1.loadBalancer.servers[0].url")
+}
+
+func TestLoadFileConfigEnvSubstitutionYAML(t *testing.T) {
+	require.NoError(t, os.Setenv("TEST_FILE_SERVER", "10.0.0.1"))
+	defer func() { _ = os.Unsetenv("TEST_FILE_SERVER") }()
+
+	tempDir := createTempDir(t, "testfiletemplate")
+	defer os.RemoveAll(tempDir)
+
+	file := createFile(t, tempDir, "dynamic.yml", `
+http:
+  routers:
+    router1:
+      service: service1
+      rule: "Host(`+"`${TEST_FILE_HOST:-example.com}`"+`)"
+  services:
+    service1:
+      loadBalancer:
+        servers:
+          - url: "http://${TEST_FILE_SERVER}:80"
+`)
+
+	provider := &Provider{
+		Filename:        file.Name(),
+		EnvSubstitution: true,
+	}
+
+	conf, err := provider.BuildConfiguration()
+	require.NoError(t, err)
+
+	assert.Equal(t, "Host(`example.com`)", conf.HTTP.Routers["router1"].Rule)
+	assert.Equal(t, []config.Server{{URL: "http://10.0.0.1:80"}}, conf.HTTP.Services["service1"].LoadBalancer.Servers)
+}
+
+// The template is rendered before the environment variables are expanded.
+func TestCreateConfigurationEnvSubstitution(t *testing.T) {
+	require.NoError(t, os.Setenv("TEST_FILE_SERVER", "10.0.0.1"))
+	defer func() { _ = os.Unsetenv("TEST_FILE_SERVER") }()
+
+	provider := &Provider{EnvSubstitution: true}
+
+	content := `
+[http.services]
+{{ range $i, $port := list 80 81 }}
+  [http.services.service{{ $i }}.loadBalancer]
+    [[http.services.service{{ $i }}.loadBalancer.servers]]
+      url = "http://${TEST_FILE_SERVER}:{{ $port }}"
+{{ end }}
+`
+
+	conf, err := provider.CreateConfiguration(content, nil, nil)
+	require.NoError(t, err)
+
+	expected := map[string]*config.Service{
+		"service0": {LoadBalancer: &config.LoadBalancerService{Servers: []config.Server{{URL: "http://10.0.0.1:80"}}}},
+		"service1": {LoadBalancer: &config.LoadBalancerService{Servers: []config.Server{{URL: "http://10.0.0.1:81"}}}},
+	}
+	assert.Equal(t, expected, conf.HTTP.Services)
+}
@@ -3,22 +3,30 @@ package file
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/Masterminds/sprig"
 	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/config/parser"
 	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/metrics"
 	"github.com/containous/traefik/pkg/provider"
 	"github.com/containous/traefik/pkg/safe"
-	"github.com/containous/traefik/pkg/tls"
+	traefiktls "github.com/containous/traefik/pkg/tls"
+	"github.com/containous/traefik/pkg/types"
 	"gopkg.in/fsnotify.v1"
 )
 
@@ -28,32 +36,66 @@ var _ provider.Provider = (*Provider)(nil)
 
 // Provider holds configurations of the provider.
 type Provider struct {
-	Directory                 string `description:"Load configuration from one or more .toml files in a directory." export:"true"`
-	Watch                     bool   `description:"Watch provider." export:"true"`
-	Filename                  string `description:"Override default configuration template. For advanced users :)" export:"true"`
-	DebugLogGeneratedTemplate bool   `description:"Enable debug logging of generated configuration template." export:"true"`
-	TraefikFile               string `description:"-"`
+	Directory                 string         `description:"Load configuration from one or more .toml files in a directory." export:"true"`
+	Watch                     bool           `description:"Watch provider." export:"true"`
+	Filename                  string         `description:"Override default configuration template. For advanced users :)" export:"true"`
+	DebugLogGeneratedTemplate bool           `description:"Enable debug logging of generated configuration template." export:"true"`
+	EnvSubstitution           bool           `description:"Expand the ${VAR} and ${VAR:-default} environment variables in the values of the configuration files." export:"true"`
+	AllowOverride             bool           `description:"Let the last file of the directory win when an element is defined in several files, instead of dropping it." export:"true"`
+	AllowUnknownFields        bool           `description:"Ignore the unknown fields of the configuration files, instead of rejecting them." export:"true"`
+	DebounceDuration          types.Duration `description:"Duration during which the changes of the files are coalesced into a single reload, once the writes settle (0 reloads on each change)." export:"true"`
+	TraefikFile               string         `description:"-"`
+	StaticKeys                []string       `description:"-"`
+
+	states          *fileStates
+	metricsRegistry metrics.Registry
+	// checksums holds the checksums of the configuration files of the last configuration sent.
+	checksums map[string]string
 }
 
 // SetDefaults sets the default values.
 func (p *Provider) SetDefaults() {
 	p.Watch = true
 	p.Filename = ""
+	p.DebounceDuration = types.Duration(100 * time.Millisecond)
 }
 
 // Init the provider
 func (p *Provider) Init() error {
+	p.states = newFileStates()
+
+	if p.metricsRegistry == nil {
+		p.metricsRegistry = metrics.NewVoidRegistry()
+	}
+
 	return nil
 }
 
+// SetMetricsRegistry sets the registry used to report the metrics of the provider.
+func (p *Provider) SetMetricsRegistry(registry metrics.Registry) {
+	p.metricsRegistry = registry
+}
+
+// FileErrors returns, per file, the error of the configuration files which are failing to load.
+// The last valid configuration of such a file is used until it loads successfully again.
+func (p *Provider) FileErrors() map[string]FileError {
+	return p.states.getErrors()
+}
+
 // Provide allows the file provider to provide configurations to traefik
 // using the given configuration channel.
 func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.Pool) error {
+	checksums, err := p.computeChecksums()
+	if err != nil {
+		return err
+	}
+
 	configuration, err := p.BuildConfiguration()
 
 	if err != nil {
 		return err
 	}
+	p.checksums = checksums
 
 	if p.Watch {
 		var watchItem string
@@ -81,8 +123,24 @@ func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.P
 func (p *Provider) BuildConfiguration() (*config.Configuration, error) {
 	ctx := log.With(context.Background(), log.Str(log.ProviderName, providerName))
 
+	configuration, err := p.buildConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The problems are only reported, as the elements may be fixed by the next changes of the files.
+	logger := log.FromContext(ctx)
+	for _, err := range checkConfiguration(configuration) {
+		logger.Warn(err)
+	}
+
+	return configuration, nil
+}
+
+func (p *Provider) buildConfiguration(ctx context.Context) (*config.Configuration, error) {
+
 	if len(p.Directory) > 0 {
-		return p.loadFileConfigFromDirectory(ctx, p.Directory, nil)
+		return p.loadFileConfigFromDirectory(ctx, p.Directory)
 	}
 
 	if len(p.Filename) > 0 {
@@ -107,30 +165,65 @@ func (p *Provider) addWatcher(pool *safe.Pool, directory string, configurationCh
 		return fmt.Errorf("error adding file watcher: %s", err)
 	}
 
+	var filename, target string
+	if p.Directory == "" {
+		if len(p.Filename) > 0 {
+			filename = p.Filename
+		} else {
+			filename = p.TraefikFile
+		}
+
+		target = resolveTarget(filename, "")
+	}
+
+	watches := newWatchSet(watcher)
+	updateWatches := func() {
+		watches.update(p.watchPaths(filename, target))
+	}
+	updateWatches()
+
 	// Process events
 	pool.Go(func(stop chan bool) {
 		defer watcher.Close()
+
+		var pending fsnotify.Event
+		var debounce <-chan time.Time
+
 		for {
 			select {
 			case <-stop:
 				return
 			case evt := <-watcher.Events:
 				if p.Directory == "" {
-					var filename string
-					if len(p.Filename) > 0 {
-						filename = p.Filename
-					} else {
-						filename = p.TraefikFile
-					}
+					// The file may be a symlink swapped atomically, as the files of a Kubernetes ConfigMap,
+					// in which case the events are about the directories its target is part of.
+					previous := target
+					target = resolveTarget(filename, previous)
 
 					_, evtFileName := filepath.Split(evt.Name)
 					_, confFileName := filepath.Split(filename)
-					if evtFileName == confFileName {
-						callback(configurationChan, evt)
+					_, isSecret := p.secretDirs()[filepath.Dir(evt.Name)]
+					if evtFileName != confFileName && evt.Name != previous && target == previous && !isSecret {
+						updateWatches()
+						continue
 					}
-				} else {
-					callback(configurationChan, evt)
 				}
+
+				if p.DebounceDuration > 0 {
+					// The events of a burst of writes are coalesced into a single reload, with the last event,
+					// once no event happened during the debounce duration.
+					pending = evt
+					debounce = time.After(time.Duration(p.DebounceDuration))
+					updateWatches()
+					continue
+				}
+
+				callback(configurationChan, evt)
+				updateWatches()
+			case <-debounce:
+				debounce = nil
+				callback(configurationChan, pending)
+				updateWatches()
 			case err := <-watcher.Errors:
 				log.WithoutContext().WithField(log.ProviderName, providerName).Errorf("Watcher event error: %s", err)
 			}
@@ -149,20 +242,89 @@ func (p *Provider) watcherCallback(configurationChan chan<- config.Message, even
 
 	logger := log.WithoutContext().WithField(log.ProviderName, providerName)
 
+	// The configuration of a removed file is dropped, once, and loaded again when the file is created again.
+	// A dangling symlink is not a removal, as it may be in the middle of a swap.
+	if _, err := os.Lstat(watchItem); os.IsNotExist(err) {
+		if p.checksums != nil {
+			logger.Warnf("%s was removed, dropping its configuration", watchItem)
+			p.checksums = nil
+			p.states.prune(nil)
+			sendConfigToChannel(configurationChan, newConfiguration())
+		}
+		return
+	}
+
 	if _, err := os.Stat(watchItem); err != nil {
 		logger.Errorf("Unable to watch %s : %v", watchItem, err)
 		return
 	}
 
+	// An event does not mean that the configuration files changed:
+	// editors and tools touch, rewrite, or write files the provider does not read next to them.
+	checksums, err := p.computeChecksums()
+	if err != nil {
+		logger.Errorf("Error occurred during watcher callback: %s", err)
+		return
+	}
+
+	if p.checksums != nil && reflect.DeepEqual(checksums, p.checksums) {
+		logger.Debugf("Skipping the reload triggered by %s, the configuration files did not change", event)
+		if p.metricsRegistry != nil {
+			p.metricsRegistry.ProviderSuppressedReloadsCounter().With("provider", providerName).Add(1)
+		}
+		return
+	}
+
 	configuration, err := p.BuildConfiguration()
 	if err != nil {
 		logger.Errorf("Error occurred during watcher callback: %s", err)
 		return
 	}
+	p.checksums = checksums
 
 	sendConfigToChannel(configurationChan, configuration)
 }
 
+// computeChecksums returns, per configuration file and per secret file, the checksum of its content.
+// A missing secret file has an empty checksum, as it only fails the elements referencing it.
+func (p *Provider) computeChecksums() (map[string]string, error) {
+	var filenames []string
+	switch {
+	case len(p.Directory) > 0:
+		var err error
+		filenames, err = listFiles(p.Directory)
+		if err != nil {
+			return nil, err
+		}
+	case len(p.Filename) > 0:
+		filenames = []string{p.Filename}
+	case len(p.TraefikFile) > 0:
+		filenames = []string{p.TraefikFile}
+	}
+
+	checksums := make(map[string]string, len(filenames))
+	for _, filename := range filenames {
+		content, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("error reading configuration file: %s - %s", filename, err)
+		}
+
+		checksums[filename] = fmt.Sprintf("%x", sha256.Sum256(content))
+	}
+
+	for _, secret := range p.states.getSecretFiles() {
+		content, err := ioutil.ReadFile(secret)
+		if err != nil {
+			checksums[secret] = ""
+			continue
+		}
+
+		checksums[secret] = fmt.Sprintf("%x", sha256.Sum256(content))
+	}
+
+	return checksums, nil
+}
+
 func sendConfigToChannel(configurationChan chan<- config.Message, configuration *config.Configuration) {
 	configurationChan <- config.Message{
 		ProviderName:  "file",
@@ -181,144 +343,412 @@ func readFile(filename string) (string, error) {
 	return "", fmt.Errorf("invalid filename: %s", filename)
 }
 
+// loadFileConfig loads the configuration of the file.
+// If the file fails to load, its last valid configuration, if any, is used instead.
 func (p *Provider) loadFileConfig(filename string, parseTemplate bool) (*config.Configuration, error) {
+	logger := log.WithoutContext().WithField(log.ProviderName, providerName)
+
+	decoded, err := p.decodeFileConfig(filename, parseTemplate)
+	if err != nil {
+		lastValid, ok := p.states.failed(filename, err, time.Now())
+		if !ok {
+			return nil, err
+		}
+
+		logger.Errorf("Unable to load the configuration file %s, keeping its last valid configuration: %v", filename, err)
+		return lastValid, nil
+	}
+
+	for _, err := range decoded.skipped {
+		logger.Errorf("Skipping an invalid element: %v", err)
+	}
+
+	p.states.loaded(filename, decoded.configuration, decoded.secrets)
+	return decoded.configuration, nil
+}
+
+// decodedFile is the configuration decoded from a file.
+type decodedFile struct {
+	configuration *config.Configuration
+	// secrets are the secret files referenced by the configuration.
+	secrets []string
+	// skipped are the errors of the elements removed from the configuration.
+	skipped []error
+}
+
+// decodeFileConfig decodes the configuration of the file.
+// The invalid TLS certificates, and the middlewares whose secrets cannot be read, are removed from the configuration.
+func (p *Provider) decodeFileConfig(filename string, parseTemplate bool) (*decodedFile, error) {
 	fileContent, err := readFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("error reading configuration file: %s - %s", filename, err)
 	}
 
-	var configuration *config.Configuration
+	content := fileContent
 	if parseTemplate {
-		configuration, err = p.CreateConfiguration(fileContent, template.FuncMap{}, false)
-	} else {
-		configuration, err = p.DecodeConfiguration(fileContent)
+		content, err = p.renderTemplate(fileContent, template.FuncMap{}, false)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding configuration file: %s - %s", filename, err)
+		}
+	}
+
+	configuration, err := p.decodeConfiguration(filename, content, !parseTemplate)
+	if errs, ok := err.(parser.DecodeErrors); ok {
+		for i, e := range errs {
+			errs[i] = fmt.Errorf("error decoding configuration file: %s - %s", filename, e)
+		}
+		return nil, errs
 	}
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error decoding configuration file: %s - %s", filename, err)
 	}
 
-	var tlsConfigs []*tls.Configuration
+	decoded := &decodedFile{configuration: configuration}
+
+	var tlsConfigs []*traefiktls.Configuration
 	for _, conf := range configuration.TLS {
-		bytes, err := conf.Certificate.CertFile.Read()
+		inline := conf.Certificate.CertFileContent != "" || conf.Certificate.KeyFileContent != ""
+
+		certContent, err := conf.Certificate.ReadCert()
 		if err != nil {
-			log.Error(err)
+			decoded.skipped = append(decoded.skipped, fmt.Errorf("invalid TLS certificate in %s: %v", filename, err))
 			continue
 		}
-		conf.Certificate.CertFile = tls.FileOrContent(string(bytes))
 
-		bytes, err = conf.Certificate.KeyFile.Read()
+		keyContent, err := conf.Certificate.ReadKey()
 		if err != nil {
-			log.Error(err)
+			decoded.skipped = append(decoded.skipped, fmt.Errorf("invalid TLS certificate in %s: %v", filename, err))
 			continue
 		}
-		conf.Certificate.KeyFile = tls.FileOrContent(string(bytes))
+
+		// An inline certificate is checked against its key right away,
+		// so that a copy and paste mistake is reported along with the file.
+		if inline {
+			if _, err = tls.X509KeyPair(certContent, keyContent); err != nil {
+				decoded.skipped = append(decoded.skipped, fmt.Errorf("invalid inline TLS certificate in %s: %v", filename, err))
+				continue
+			}
+		}
+
+		conf.Certificate = &traefiktls.Certificate{
+			CertFile: traefiktls.FileOrContent(certContent),
+			KeyFile:  traefiktls.FileOrContent(keyContent),
+		}
 		tlsConfigs = append(tlsConfigs, conf)
 	}
 	configuration.TLS = tlsConfigs
 
-	return configuration, nil
+	secrets, errs := resolveSecrets(filename, configuration)
+	decoded.secrets = secrets
+	decoded.skipped = append(decoded.skipped, errs...)
+
+	// The lines are only located in the TOML files.
+	if isYAML(filename) {
+		fileContent = ""
+	}
+	configuration.Sources = newSources(filename, fileContent, configuration)
+
+	return decoded, nil
 }
 
-func (p *Provider) loadFileConfigFromDirectory(ctx context.Context, directory string, configuration *config.Configuration) (*config.Configuration, error) {
-	logger := log.FromContext(ctx)
+// fileConfiguration is the configuration decoded from a file of the directory.
+type fileConfiguration struct {
+	filename      string
+	configuration *config.Configuration
+}
 
-	fileList, err := ioutil.ReadDir(directory)
+func (p *Provider) loadFileConfigFromDirectory(ctx context.Context, directory string) (*config.Configuration, error) {
+	fileConfigs, err := p.loadFileConfigsFromDirectory(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	filenames := make(map[string]struct{}, len(fileConfigs))
+	for _, fc := range fileConfigs {
+		filenames[fc.filename] = struct{}{}
+	}
+	p.states.prune(filenames)
 
+	return p.mergeFileConfigs(ctx, fileConfigs), nil
+}
+
+// loadFileConfigsFromDirectory loads the configuration files of the directory and its subdirectories, in order.
+func (p *Provider) loadFileConfigsFromDirectory(directory string) ([]fileConfiguration, error) {
+	filenames, err := listFiles(directory)
 	if err != nil {
-		return configuration, fmt.Errorf("unable to read directory %s: %v", directory, err)
+		return nil, err
 	}
 
-	if configuration == nil {
-		configuration = &config.Configuration{
-			HTTP: &config.HTTPConfiguration{
-				Routers:     make(map[string]*config.Router),
-				Middlewares: make(map[string]*config.Middleware),
-				Services:    make(map[string]*config.Service),
-			},
-			TCP: &config.TCPConfiguration{
-				Routers:  make(map[string]*config.TCPRouter),
-				Services: make(map[string]*config.TCPService),
-			},
+	var fileConfigs []fileConfiguration
+	for _, filename := range filenames {
+		c, err := p.loadFileConfig(filename, true)
+		if err != nil {
+			return nil, err
 		}
+
+		fileConfigs = append(fileConfigs, fileConfiguration{filename: filename, configuration: c})
 	}
 
-	configTLSMaps := make(map[*tls.Configuration]struct{})
-	for _, item := range fileList {
+	return fileConfigs, nil
+}
+
+// listFiles returns the configuration files of the directory and its subdirectories, in order.
+func listFiles(directory string) ([]string, error) {
+	fileList, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read directory %s: %v", directory, err)
+	}
 
+	var filenames []string
+	for _, item := range fileList {
 		if item.IsDir() {
-			configuration, err = p.loadFileConfigFromDirectory(ctx, filepath.Join(directory, item.Name()), configuration)
+			var subFilenames []string
+			subFilenames, err = listFiles(filepath.Join(directory, item.Name()))
 			if err != nil {
-				return configuration, fmt.Errorf("unable to load content configuration from subdirectory %s: %v", item, err)
+				return nil, fmt.Errorf("unable to load content configuration from subdirectory %s: %v", item, err)
 			}
+			filenames = append(filenames, subFilenames...)
 			continue
-		} else if !strings.HasSuffix(item.Name(), ".toml") && !strings.HasSuffix(item.Name(), ".tmpl") {
+		} else if !strings.HasSuffix(item.Name(), ".toml") && !strings.HasSuffix(item.Name(), ".tmpl") && !isYAML(item.Name()) {
 			continue
 		}
 
-		var c *config.Configuration
-		c, err = p.loadFileConfig(path.Join(directory, item.Name()), true)
+		filenames = append(filenames, path.Join(directory, item.Name()))
+	}
 
-		if err != nil {
-			return configuration, err
+	return filenames, nil
+}
+
+// elementFiles holds, per element name, the files defining the element, with the line when known, in the order they are read.
+type elementFiles map[string][]string
+
+func (e elementFiles) add(name, filename string) {
+	files := e[name]
+	if len(files) > 0 && files[len(files)-1] == filename {
+		return
+	}
+	e[name] = append(files, filename)
+}
+
+// mergeFileConfigs merges the configurations of the files.
+// An element defined in several files is a conflict: it is dropped, unless AllowOverride is set,
+// in which case the definition of the last file wins.
+func (p *Provider) mergeFileConfigs(ctx context.Context, fileConfigs []fileConfiguration) *config.Configuration {
+	logger := log.FromContext(ctx)
+
+	httpRouters := make(elementFiles)
+	httpMiddlewares := make(elementFiles)
+	httpServices := make(elementFiles)
+	httpTransports := make(elementFiles)
+	tcpRouters := make(elementFiles)
+	tcpServices := make(elementFiles)
+	udpRouters := make(elementFiles)
+	udpServices := make(elementFiles)
+	tlsCertificates := make(elementFiles)
+
+	for _, fc := range fileConfigs {
+		sources := fileSources(fc)
+
+		for name := range fc.configuration.HTTP.Routers {
+			httpRouters.add(name, sources.Routers[name].String())
+		}
+		for name := range fc.configuration.HTTP.Middlewares {
+			httpMiddlewares.add(name, sources.Middlewares[name].String())
+		}
+		for name := range fc.configuration.HTTP.Services {
+			httpServices.add(name, sources.Services[name].String())
+		}
+		for name := range fc.configuration.HTTP.ServersTransports {
+			httpTransports.add(name, sources.ServersTransports[name].String())
+		}
+		for name := range fc.configuration.TCP.Routers {
+			tcpRouters.add(name, sources.TCPRouters[name].String())
+		}
+		for name := range fc.configuration.TCP.Services {
+			tcpServices.add(name, sources.TCPServices[name].String())
+		}
+		for name := range fc.configuration.UDP.Routers {
+			udpRouters.add(name, sources.UDPRouters[name].String())
+		}
+		for name := range fc.configuration.UDP.Services {
+			udpServices.add(name, sources.UDPServices[name].String())
+		}
+		for _, conf := range fc.configuration.TLS {
+			tlsCertificates.add(string(conf.Certificate.CertFile), fc.filename)
+		}
+	}
+
+	p.logConflicts(logger, log.RouterName, "HTTP router", httpRouters)
+	p.logConflicts(logger, log.MiddlewareName, "HTTP middleware", httpMiddlewares)
+	p.logConflicts(logger, log.ServiceName, "HTTP service", httpServices)
+	p.logConflicts(logger, log.ServersTransport, "HTTP servers transport", httpTransports)
+	p.logConflicts(logger, log.RouterName, "TCP router", tcpRouters)
+	p.logConflicts(logger, log.ServiceName, "TCP service", tcpServices)
+	p.logConflicts(logger, log.RouterName, "UDP router", udpRouters)
+	p.logConflicts(logger, log.ServiceName, "UDP service", udpServices)
+
+	for _, name := range sortedConflicts(tlsCertificates) {
+		files := strings.Join(tlsCertificates[name], ", ")
+		if p.AllowOverride {
+			logger.Debugf("TLS certificate defined in several files (%s), using the last one", files)
+		} else {
+			logger.Errorf("TLS certificate defined in several files (%s), skipping", files)
 		}
+	}
+
+	configuration := newConfiguration()
+
+	tlsIndexes := make(map[string]int)
+
+	for _, fc := range fileConfigs {
+		c := fc.configuration
+		sources := fileSources(fc)
 
 		for name, conf := range c.HTTP.Routers {
-			if _, exists := configuration.HTTP.Routers[name]; exists {
-				logger.WithField(log.RouterName, name).Warn("HTTP router already configured, skipping")
-			} else {
+			if p.keepElement(httpRouters[name]) {
 				configuration.HTTP.Routers[name] = conf
+				configuration.Sources.Routers[name] = sources.Routers[name]
 			}
 		}
 
 		for name, conf := range c.HTTP.Middlewares {
-			if _, exists := configuration.HTTP.Middlewares[name]; exists {
-				logger.WithField(log.MiddlewareName, name).Warn("HTTP middleware already configured, skipping")
-			} else {
+			if p.keepElement(httpMiddlewares[name]) {
 				configuration.HTTP.Middlewares[name] = conf
+				configuration.Sources.Middlewares[name] = sources.Middlewares[name]
 			}
 		}
 
 		for name, conf := range c.HTTP.Services {
-			if _, exists := configuration.HTTP.Services[name]; exists {
-				logger.WithField(log.ServiceName, name).Warn("HTTP service already configured, skipping")
-			} else {
+			if p.keepElement(httpServices[name]) {
 				configuration.HTTP.Services[name] = conf
+				configuration.Sources.Services[name] = sources.Services[name]
+			}
+		}
+
+		for name, conf := range c.HTTP.ServersTransports {
+			if p.keepElement(httpTransports[name]) {
+				configuration.HTTP.ServersTransports[name] = conf
+				configuration.Sources.ServersTransports[name] = sources.ServersTransports[name]
 			}
 		}
 
 		for name, conf := range c.TCP.Routers {
-			if _, exists := configuration.TCP.Routers[name]; exists {
-				logger.WithField(log.RouterName, name).Warn("TCP router already configured, skipping")
-			} else {
+			if p.keepElement(tcpRouters[name]) {
 				configuration.TCP.Routers[name] = conf
+				configuration.Sources.TCPRouters[name] = sources.TCPRouters[name]
 			}
 		}
 
 		for name, conf := range c.TCP.Services {
-			if _, exists := configuration.TCP.Services[name]; exists {
-				logger.WithField(log.ServiceName, name).Warn("TCP service already configured, skipping")
-			} else {
+			if p.keepElement(tcpServices[name]) {
 				configuration.TCP.Services[name] = conf
+				configuration.Sources.TCPServices[name] = sources.TCPServices[name]
+			}
+		}
+
+		for name, conf := range c.UDP.Routers {
+			if p.keepElement(udpRouters[name]) {
+				configuration.UDP.Routers[name] = conf
+				configuration.Sources.UDPRouters[name] = sources.UDPRouters[name]
+			}
+		}
+
+		for name, conf := range c.UDP.Services {
+			if p.keepElement(udpServices[name]) {
+				configuration.UDP.Services[name] = conf
+				configuration.Sources.UDPServices[name] = sources.UDPServices[name]
 			}
 		}
 
 		for _, conf := range c.TLS {
-			if _, exists := configTLSMaps[conf]; exists {
-				logger.Warnf("TLS configuration %v already configured, skipping", conf)
-			} else {
-				configTLSMaps[conf] = struct{}{}
+			certificate := string(conf.Certificate.CertFile)
+			if !p.keepElement(tlsCertificates[certificate]) {
+				continue
+			}
+
+			if i, ok := tlsIndexes[certificate]; ok {
+				configuration.TLS[i] = conf
+				continue
 			}
+
+			tlsIndexes[certificate] = len(configuration.TLS)
+			configuration.TLS = append(configuration.TLS, conf)
 		}
 	}
 
-	for conf := range configTLSMaps {
-		configuration.TLS = append(configuration.TLS, conf)
+	return configuration
+}
+
+// newConfiguration returns a configuration without elements.
+func newConfiguration() *config.Configuration {
+	return &config.Configuration{
+		HTTP: &config.HTTPConfiguration{
+			Routers:           make(map[string]*config.Router),
+			Middlewares:       make(map[string]*config.Middleware),
+			Services:          make(map[string]*config.Service),
+			ServersTransports: make(map[string]*config.ServersTransport),
+		},
+		TCP: &config.TCPConfiguration{
+			Routers:  make(map[string]*config.TCPRouter),
+			Services: make(map[string]*config.TCPService),
+		},
+		UDP: &config.UDPConfiguration{
+			Routers:  make(map[string]*config.UDPRouter),
+			Services: make(map[string]*config.UDPService),
+		},
+		Sources: config.NewSources(),
 	}
-	return configuration, nil
+}
+
+// fileSources returns where the elements of the file are defined.
+// The elements of a configuration without sources are located in the file, without line.
+func fileSources(fc fileConfiguration) *config.Sources {
+	if fc.configuration.Sources != nil {
+		return fc.configuration.Sources
+	}
+	return newSources(fc.filename, "", fc.configuration)
+}
+
+// keepElement tells whether an element defined in the given files is merged.
+func (p *Provider) keepElement(files []string) bool {
+	return len(files) < 2 || p.AllowOverride
+}
+
+func (p *Provider) logConflicts(logger log.Logger, field, kind string, elements elementFiles) {
+	for _, name := range sortedConflicts(elements) {
+		files := strings.Join(elements[name], ", ")
+		if p.AllowOverride {
+			logger.WithField(field, name).Debugf("%s defined in several files (%s), using the last one", kind, files)
+		} else {
+			logger.WithField(field, name).Errorf("%s defined in several files (%s), skipping", kind, files)
+		}
+	}
+}
+
+// sortedConflicts returns the names of the elements defined in several files, sorted.
+func sortedConflicts(elements elementFiles) []string {
+	var names []string
+	for name, files := range elements {
+		if len(files) > 1 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
 }
 
 // CreateConfiguration creates a provider configuration from content using templating.
 func (p *Provider) CreateConfiguration(tmplContent string, funcMap template.FuncMap, templateObjects interface{}) (*config.Configuration, error) {
+	renderedTemplate, err := p.renderTemplate(tmplContent, funcMap, templateObjects)
+	if err != nil {
+		return nil, err
+	}
+	return p.DecodeConfiguration(renderedTemplate)
+}
+
+// renderTemplate renders the content as a template.
+func (p *Provider) renderTemplate(tmplContent string, funcMap template.FuncMap, templateObjects interface{}) (string, error) {
 	var defaultFuncMap = sprig.TxtFuncMap()
 	defaultFuncMap["normalize"] = provider.Normalize
 	defaultFuncMap["split"] = strings.Split
@@ -330,13 +760,13 @@ func (p *Provider) CreateConfiguration(tmplContent string, funcMap template.Func
 
 	_, err := tmpl.Parse(tmplContent)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	var buffer bytes.Buffer
 	err = tmpl.Execute(&buffer, templateObjects)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	var renderedTemplate = buffer.String()
@@ -344,27 +774,135 @@ func (p *Provider) CreateConfiguration(tmplContent string, funcMap template.Func
 		log.Debugf("Template content: %s", tmplContent)
 		log.Debugf("Rendering results: %s", renderedTemplate)
 	}
-	return p.DecodeConfiguration(renderedTemplate)
+	return renderedTemplate, nil
 }
 
 // DecodeConfiguration Decodes a *types.Configuration from a content.
 func (p *Provider) DecodeConfiguration(content string) (*config.Configuration, error) {
+	return p.decodeConfiguration("", content, false)
+}
+
+// decodeConfiguration decodes the dynamic configuration from the content of the file,
+// which is in YAML when the file has a YAML extension, and in TOML otherwise.
+// The content of the Traefik configuration file also holds the static configuration,
+// which is only allowed there.
+func (p *Provider) decodeConfiguration(filename, content string, traefikFile bool) (*config.Configuration, error) {
 	configuration := &config.Configuration{
 		HTTP: &config.HTTPConfiguration{
-			Routers:     make(map[string]*config.Router),
-			Middlewares: make(map[string]*config.Middleware),
-			Services:    make(map[string]*config.Service),
+			Routers:           make(map[string]*config.Router),
+			Middlewares:       make(map[string]*config.Middleware),
+			Services:          make(map[string]*config.Service),
+			ServersTransports: make(map[string]*config.ServersTransport),
 		},
 		TCP: &config.TCPConfiguration{
 			Routers:  make(map[string]*config.TCPRouter),
 			Services: make(map[string]*config.TCPService),
 		},
-		TLS:        make([]*tls.Configuration, 0),
-		TLSStores:  make(map[string]tls.Store),
-		TLSOptions: make(map[string]tls.TLS),
+		UDP: &config.UDPConfiguration{
+			Routers:  make(map[string]*config.UDPRouter),
+			Services: make(map[string]*config.UDPService),
+		},
+		TLS:        make([]*traefiktls.Configuration, 0),
+		TLSStores:  make(map[string]traefiktls.Store),
+		TLSOptions: make(map[string]traefiktls.TLS),
+	}
+
+	// The YAML content is converted first, since the environment variables are expanded in the TOML content.
+	if isYAML(filename) {
+		var err error
+		content, err = yamlToTOML(content)
+		if err != nil {
+			return nil, err
+		}
 	}
-	if _, err := toml.Decode(content, configuration); err != nil {
+
+	if p.EnvSubstitution {
+		var err error
+		content, err = substituteEnv(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	metadata, err := toml.Decode(content, configuration)
+	if err != nil {
+		if !p.AllowUnknownFields {
+			if errs := collectDecodeErrors(content); len(errs) > 0 {
+				return nil, errs
+			}
+		}
 		return nil, err
 	}
+
+	if !p.AllowUnknownFields {
+		if err := checkUnknownFields(metadata, p.StaticKeys, traefikFile); err != nil {
+			return nil, err
+		}
+	}
+
 	return configuration, nil
 }
+
+// checkUnknownFields returns an error naming the keys which do not match any field of the configuration.
+// The roots which are among the static keys are reported as static configuration options,
+// and any other unknown root is reported as an unknown field.
+// In the Traefik configuration file, the unknown roots belong to the static configuration, and are skipped.
+// The keys under an unknown key are skipped, the unknown key being reported once.
+func checkUnknownFields(metadata toml.MetaData, staticKeys []string, traefikFile bool) error {
+	rootType := reflect.TypeOf(config.Configuration{})
+
+	var unknownFields, staticFields []string
+	reported := make(map[string]struct{})
+
+	for _, key := range metadata.Undecoded() {
+		if _, ok := rootType.FieldByNameFunc(func(name string) bool { return strings.EqualFold(name, key[0]) }); !ok {
+			if traefikFile || isReported(reported, key[:1]) {
+				continue
+			}
+
+			reported[key[0]] = struct{}{}
+			if isStaticKey(staticKeys, key[0]) {
+				staticFields = append(staticFields, key[0])
+			} else {
+				unknownFields = append(unknownFields, key[0])
+			}
+			continue
+		}
+
+		if isReported(reported, key) {
+			continue
+		}
+
+		reported[key.String()] = struct{}{}
+		unknownFields = append(unknownFields, key.String())
+	}
+
+	if len(staticFields) > 0 {
+		return fmt.Errorf("static configuration options, only allowed in the Traefik configuration file: %s", strings.Join(staticFields, ", "))
+	}
+
+	if len(unknownFields) > 0 {
+		return fmt.Errorf("unknown fields: %s", strings.Join(unknownFields, ", "))
+	}
+
+	return nil
+}
+
+func isStaticKey(staticKeys []string, root string) bool {
+	for _, key := range staticKeys {
+		if strings.EqualFold(key, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// isReported tells whether the key, or one of its parents, is already reported.
+func isReported(reported map[string]struct{}, key toml.Key) bool {
+	for i := 1; i <= len(key); i++ {
+		if _, ok := reported[key[:i].String()]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -1,18 +1,26 @@
 package file
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/metrics"
 	"github.com/containous/traefik/pkg/safe"
+	traefiktls "github.com/containous/traefik/pkg/tls"
+	"github.com/containous/traefik/pkg/types"
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/fsnotify.v1"
 )
 
 type ProvideTestCase struct {
@@ -188,8 +196,8 @@ func getTestCases() []ProvideTestCase {
 				`
 [http.services]
 {{ range $i, $e := until 20 }}
-  [http.services.application-{{ $e }}]
-	[[http.services.application-{{ $e }}.servers]]
+  [http.services.application-{{ $e }}.loadbalancer]
+	[[http.services.application-{{ $e }}.loadbalancer.servers]]
 	url="http://127.0.0.1"
 {{ end }}
 `,
@@ -327,7 +335,6 @@ func createTLS(n int) string {
 	var conf string
 	for i := 1; i <= n; i++ {
 		conf += fmt.Sprintf(`[[TLS]]
-	EntryPoints = ["https"]
 	[TLS.Certificate]
 	CertFile = "integration/fixtures/https/snitest%[1]d.com.cert"
 	KeyFile = "integration/fixtures/https/snitest%[1]d.com.key"
@@ -343,7 +350,6 @@ func TestTLSContent(t *testing.T) {
 	fileTLS := createRandomFile(t, tempDir, "CONTENT")
 	fileConfig := createRandomFile(t, tempDir, `
 [[tls]]
-entryPoints = ["https"]
   [tls.certificate]
     certFile = "`+fileTLS.Name()+`"
     keyFile = "`+fileTLS.Name()+`"
@@ -356,3 +362,1057 @@ entryPoints = ["https"]
 	require.Equal(t, "CONTENT", configuration.TLS[0].Certificate.CertFile.String())
 	require.Equal(t, "CONTENT", configuration.TLS[0].Certificate.KeyFile.String())
 }
+
+func TestLoadFileConfigFromDirectoryConflicts(t *testing.T) {
+	first := `
+[http.routers]
+  [http.routers.router1]
+    service = "service1"
+  [http.routers.conflict]
+    service = "first"
+
+[http.middlewares]
+  [http.middlewares.conflict.addPrefix]
+    prefix = "/first"
+
+[http.services]
+  [http.services.conflict.loadBalancer]
+    [[http.services.conflict.loadBalancer.servers]]
+      url = "http://10.0.0.1:80"
+
+[[tls]]
+  [tls.certificate]
+    certFile = "conflict.cert"
+    keyFile = "first.key"
+`
+
+	second := `
+[http.routers]
+  [http.routers.router2]
+    service = "service2"
+  [http.routers.conflict]
+    service = "second"
+
+[http.middlewares]
+  [http.middlewares.conflict.addPrefix]
+    prefix = "/second"
+
+[http.services]
+  [http.services.conflict.loadBalancer]
+    [[http.services.conflict.loadBalancer.servers]]
+      url = "http://10.0.0.2:80"
+
+[[tls]]
+  [tls.certificate]
+    certFile = "conflict.cert"
+    keyFile = "second.key"
+
+[[tls]]
+  [tls.certificate]
+    certFile = "second.cert"
+    keyFile = "second.key"
+`
+
+	testCases := []struct {
+		desc                string
+		allowOverride       bool
+		expectedRouters     map[string]string
+		expectedSources     map[string]string
+		expectedMiddlewares map[string]string
+		expectedServices    map[string]string
+		expectedKeyFiles    map[string]string
+	}{
+		{
+			desc: "conflicting elements are dropped",
+			expectedRouters: map[string]string{
+				"router1": "service1",
+				"router2": "service2",
+			},
+			expectedSources: map[string]string{
+				"router1": "a.toml:3",
+				"router2": "b/b.toml:3",
+			},
+			expectedMiddlewares: map[string]string{},
+			expectedServices:    map[string]string{},
+			expectedKeyFiles: map[string]string{
+				"second.cert": "second.key",
+			},
+		},
+		{
+			desc:          "the last file wins",
+			allowOverride: true,
+			expectedRouters: map[string]string{
+				"router1":  "service1",
+				"router2":  "service2",
+				"conflict": "second",
+			},
+			expectedSources: map[string]string{
+				"router1":  "a.toml:3",
+				"router2":  "b/b.toml:3",
+				"conflict": "b/b.toml:5",
+			},
+			expectedMiddlewares: map[string]string{
+				"conflict": "/second",
+			},
+			expectedServices: map[string]string{
+				"conflict": "http://10.0.0.2:80",
+			},
+			expectedKeyFiles: map[string]string{
+				"conflict.cert": "second.key",
+				"second.cert":   "second.key",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			tempDir := createTempDir(t, "testdir")
+			defer os.RemoveAll(tempDir)
+
+			createFile(t, tempDir, "a.toml", first)
+
+			err := os.Mkdir(path.Join(tempDir, "b"), 0755)
+			require.NoError(t, err)
+			createFile(t, path.Join(tempDir, "b"), "b.toml", second)
+
+			provider := &Provider{
+				Directory:     tempDir,
+				AllowOverride: test.allowOverride,
+			}
+
+			configuration, err := provider.BuildConfiguration()
+			require.NoError(t, err)
+
+			routers := make(map[string]string)
+			for name, router := range configuration.HTTP.Routers {
+				routers[name] = router.Service
+			}
+			assert.Equal(t, test.expectedRouters, routers)
+
+			sources := make(map[string]string)
+			for name, source := range configuration.Sources.Routers {
+				sources[name] = strings.TrimPrefix(source.String(), tempDir+"/")
+			}
+			assert.Equal(t, test.expectedSources, sources)
+
+			middlewares := make(map[string]string)
+			for name, middleware := range configuration.HTTP.Middlewares {
+				middlewares[name] = middleware.AddPrefix.Prefix
+			}
+			assert.Equal(t, test.expectedMiddlewares, middlewares)
+
+			services := make(map[string]string)
+			for name, service := range configuration.HTTP.Services {
+				services[name] = service.LoadBalancer.Servers[0].URL
+			}
+			assert.Equal(t, test.expectedServices, services)
+
+			keyFiles := make(map[string]string)
+			for _, conf := range configuration.TLS {
+				keyFiles[conf.Certificate.CertFile.String()] = conf.Certificate.KeyFile.String()
+			}
+			assert.Equal(t, test.expectedKeyFiles, keyFiles)
+		})
+	}
+}
+
+func TestDecodeConfigurationUnknownFields(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		allowUnknownFields bool
+		staticKeys         []string
+		content            string
+		expectedErrorMsg   string
+	}{
+		{
+			desc: "unknown field",
+			content: `
+[http.services]
+  [http.services.api.loadBalancer]
+    passHostHeaders = true
+    [[http.services.api.loadBalancer.servers]]
+      url = "http://10.0.0.1:80"
+      weigth = 2
+`,
+			expectedErrorMsg: "unknown fields: http.services.api.loadBalancer.passHostHeaders, http.services.api.loadBalancer.servers.weigth",
+		},
+		{
+			desc: "unknown section",
+			content: `
+[http.services]
+  [http.services.api.loadBalancer]
+    [http.services.api.loadBalancer.healthCheks]
+      path = "/health"
+      interval = "10s"
+`,
+			expectedErrorMsg: "unknown fields: http.services.api.loadBalancer.healthCheks",
+		},
+		{
+			desc:               "unknown field allowed",
+			allowUnknownFields: true,
+			content: `
+[http.services]
+  [http.services.api.loadBalancer]
+    passHostHeaders = true
+`,
+		},
+		{
+			desc: "unknown root",
+			content: `
+debug = true
+
+[entryPoints]
+  [entryPoints.web]
+    address = ":80"
+
+[http.services]
+  [http.services.api.loadBalancer]
+    passHostHeader = true
+`,
+			expectedErrorMsg: "unknown fields: debug, entryPoints",
+		},
+		{
+			desc: "misspelled root",
+			content: `
+[htpp.routers]
+  [htpp.routers.router1]
+    rule = "Host(` + "`foo`" + `)"
+    service = "service1"
+
+[http.services]
+  [http.services.service1.loadBalancer]
+    [[http.services.service1.loadBalancer.servers]]
+      url = "http://10.0.0.1:80"
+`,
+			expectedErrorMsg: "unknown fields: htpp",
+		},
+		{
+			desc:       "static root",
+			staticKeys: []string{"Global", "EntryPoints", "Providers"},
+			content: `
+debug = true
+
+[entryPoints]
+  [entryPoints.web]
+    address = ":80"
+
+[providers.docker]
+
+[http.services]
+  [http.services.api.loadBalancer]
+    passHostHeaders = true
+`,
+			expectedErrorMsg: "static configuration options, only allowed in the Traefik configuration file: entryPoints, providers",
+		},
+		{
+			desc:               "static root allowed",
+			allowUnknownFields: true,
+			staticKeys:         []string{"Global", "EntryPoints", "Providers"},
+			content: `
+[entryPoints]
+  [entryPoints.web]
+    address = ":80"
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			provider := &Provider{
+				AllowUnknownFields: test.allowUnknownFields,
+				StaticKeys:         test.staticKeys,
+			}
+
+			_, err := provider.DecodeConfiguration(test.content)
+			if test.expectedErrorMsg != "" {
+				assert.EqualError(t, err, test.expectedErrorMsg)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestDecodeConfigurationRouterTLS(t *testing.T) {
+	provider := &Provider{}
+
+	conf, err := provider.DecodeConfiguration(`
+[http.routers]
+  [http.routers.router1]
+    rule = "PathPrefix(` + "`/foo`" + `)"
+    service = "service1"
+    [http.routers.router1.tls]
+      options = "modern"
+      certResolver = "letsencrypt"
+      [[http.routers.router1.tls.domains]]
+        main = "example.com"
+        sans = ["*.example.com"]
+`)
+	require.NoError(t, err)
+
+	expected := &config.RouterTLSConfig{
+		Options:      "modern",
+		CertResolver: "letsencrypt",
+		Domains:      []types.Domain{{Main: "example.com", SANs: []string{"*.example.com"}}},
+	}
+	assert.Equal(t, expected, conf.HTTP.Routers["router1"].TLS)
+}
+
+func TestDecodeConfigurationWeightedService(t *testing.T) {
+	provider := &Provider{}
+
+	conf, err := provider.DecodeConfiguration(`
+[http.services]
+  [http.services.canary.weighted]
+    [[http.services.canary.weighted.services]]
+      name = "v1"
+      weight = 3
+    [[http.services.canary.weighted.services]]
+      name = "docker.v2"
+`)
+	require.NoError(t, err)
+
+	weight := 3
+	expected := &config.Service{
+		Weighted: &config.WeightedService{
+			Services: []config.WeightedServiceRef{
+				{Name: "v1", Weight: &weight},
+				{Name: "docker.v2"},
+			},
+		},
+	}
+	assert.Equal(t, expected, conf.HTTP.Services["canary"])
+
+	// The configuration written back is decoded the same way.
+	buffer := &bytes.Buffer{}
+	require.NoError(t, toml.NewEncoder(buffer).Encode(conf))
+
+	decoded, err := provider.DecodeConfiguration(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, expected, decoded.HTTP.Services["canary"])
+}
+
+func TestDecodeConfigurationMirroring(t *testing.T) {
+	provider := &Provider{}
+
+	conf, err := provider.DecodeConfiguration(`
+[http.services]
+  [http.services.app.mirroring]
+    service = "v1"
+    maxBodySize = 1024
+    [[http.services.app.mirroring.mirrors]]
+      name = "v2"
+      percent = 10
+`)
+	require.NoError(t, err)
+
+	maxBodySize := int64(1024)
+	expected := &config.Service{
+		Mirroring: &config.Mirroring{
+			Service:     "v1",
+			MaxBodySize: &maxBodySize,
+			Mirrors:     []config.MirrorService{{Name: "v2", Percent: 10}},
+		},
+	}
+	assert.Equal(t, expected, conf.HTTP.Services["app"])
+
+	// The configuration written back is decoded the same way.
+	buffer := &bytes.Buffer{}
+	require.NoError(t, toml.NewEncoder(buffer).Encode(conf))
+
+	decoded, err := provider.DecodeConfiguration(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, expected, decoded.HTTP.Services["app"])
+}
+
+func TestDecodeConfigurationFailover(t *testing.T) {
+	provider := &Provider{}
+
+	conf, err := provider.DecodeConfiguration(`
+[http.services]
+  [http.services.app.failover]
+    service = "docker.app"
+    fallback = "maintenance"
+`)
+	require.NoError(t, err)
+
+	expected := &config.Service{
+		Failover: &config.Failover{Service: "docker.app", Fallback: "maintenance"},
+	}
+	assert.Equal(t, expected, conf.HTTP.Services["app"])
+
+	// The configuration written back is decoded the same way.
+	buffer := &bytes.Buffer{}
+	require.NoError(t, toml.NewEncoder(buffer).Encode(conf))
+
+	decoded, err := provider.DecodeConfiguration(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, expected, decoded.HTTP.Services["app"])
+}
+
+func TestDecodeConfigurationRetry(t *testing.T) {
+	provider := &Provider{}
+
+	conf, err := provider.DecodeConfiguration(`
+[http.middlewares]
+  [http.middlewares.retry.retry]
+    attempts = 3
+    initialInterval = "100ms"
+    perTryTimeout = "2s"
+`)
+	require.NoError(t, err)
+
+	expected := &config.Retry{
+		Attempts:        3,
+		InitialInterval: types.Duration(100 * time.Millisecond),
+		PerTryTimeout:   types.Duration(2 * time.Second),
+	}
+	assert.Equal(t, expected, conf.HTTP.Middlewares["retry"].Retry)
+}
+
+func TestDecodeConfigurationServersTransport(t *testing.T) {
+	provider := &Provider{}
+
+	conf, err := provider.DecodeConfiguration(`
+[http.services]
+  [http.services.app.loadBalancer]
+    serversTransport = "mytransport"
+    [[http.services.app.loadBalancer.servers]]
+      url = "https://10.0.0.1"
+
+[http.serversTransports]
+  [http.serversTransports.mytransport]
+    serverName = "app.internal"
+    rootCAs = ["ca.pem"]
+    maxIdleConnsPerHost = 7
+    [[http.serversTransports.mytransport.certificates]]
+      certFile = "cert.pem"
+      keyFile = "key.pem"
+    [http.serversTransports.mytransport.forwardingTimeouts]
+      dialTimeout = "5s"
+`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "mytransport", conf.HTTP.Services["app"].LoadBalancer.ServersTransport)
+
+	expected := &config.ServersTransport{
+		ServerName:          "app.internal",
+		RootCAs:             []traefiktls.FileOrContent{"ca.pem"},
+		Certificates:        traefiktls.Certificates{{CertFile: "cert.pem", KeyFile: "key.pem"}},
+		MaxIdleConnsPerHost: 7,
+		ForwardingTimeouts:  &config.ForwardingTimeouts{DialTimeout: types.Duration(5 * time.Second)},
+	}
+	assert.Equal(t, expected, conf.HTTP.ServersTransports["mytransport"])
+}
+
+func TestDecodeConfigurationStickyCookie(t *testing.T) {
+	provider := &Provider{}
+
+	conf, err := provider.DecodeConfiguration(`
+[http.services]
+  [http.services.app.loadBalancer]
+    [http.services.app.loadBalancer.sticky.cookie]
+      name = "app_cookie"
+      secure = true
+      httpOnly = true
+      sameSite = "lax"
+    [[http.services.app.loadBalancer.servers]]
+      url = "http://10.0.0.1"
+`)
+	require.NoError(t, err)
+
+	expected := &config.Sticky{
+		Cookie: &config.Cookie{
+			Name:     "app_cookie",
+			Secure:   true,
+			HTTPOnly: true,
+			SameSite: "lax",
+		},
+	}
+	assert.Equal(t, expected, conf.HTTP.Services["app"].LoadBalancer.Sticky)
+
+	// The configuration written back is decoded the same way.
+	buffer := &bytes.Buffer{}
+	require.NoError(t, toml.NewEncoder(buffer).Encode(conf))
+
+	decoded, err := provider.DecodeConfiguration(buffer.String())
+	require.NoError(t, err)
+	assert.Equal(t, expected, decoded.HTTP.Services["app"].LoadBalancer.Sticky)
+}
+
+func TestLoadFileConfigUnknownFields(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	file := createFile(t, tempDir, "dynamic.toml", `
+[http.routers]
+  [http.routers.router1]
+    service = "service1"
+    entrypoint = ["web"]
+`)
+
+	provider := &Provider{}
+
+	_, err := provider.loadFileConfig(file.Name(), true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), file.Name())
+	assert.Contains(t, err.Error(), "http.routers.router1.entrypoint")
+}
+
+func TestLoadFileConfigStaticKeys(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	file := createFile(t, tempDir, "traefik.toml", `
+[entryPoints]
+  [entryPoints.web]
+    address = ":80"
+
+[http.routers]
+  [http.routers.router1]
+    service = "service1"
+`)
+
+	provider := &Provider{StaticKeys: []string{"EntryPoints"}}
+
+	// The Traefik configuration file holds both the static and the dynamic configuration.
+	configuration, err := provider.loadFileConfig(file.Name(), false)
+	require.NoError(t, err)
+	assert.Contains(t, configuration.HTTP.Routers, "router1")
+
+	_, err = provider.loadFileConfig(file.Name(), true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), file.Name())
+	assert.Contains(t, err.Error(), "only allowed in the Traefik configuration file: entryPoints")
+}
+
+// The files of a Kubernetes ConfigMap are symlinks to a ..data symlink,
+// which is swapped atomically to a new timestamped directory on every update.
+func TestProvideWithWatchSymlinkSwaps(t *testing.T) {
+	tempDir := createTempDir(t, "testconfigmap")
+	defer os.RemoveAll(tempDir)
+
+	writeConfigMap := func(version int) {
+		t.Helper()
+
+		dataDir := fmt.Sprintf("..%d", version)
+		require.NoError(t, os.Mkdir(path.Join(tempDir, dataDir), 0755))
+		createFile(t, path.Join(tempDir, dataDir), "dynamic.toml", createRoutersConfiguration(version))
+
+		require.NoError(t, os.Symlink(dataDir, path.Join(tempDir, "..data_tmp")))
+		require.NoError(t, os.Rename(path.Join(tempDir, "..data_tmp"), path.Join(tempDir, "..data")))
+
+		if version > 1 {
+			require.NoError(t, os.RemoveAll(path.Join(tempDir, fmt.Sprintf("..%d", version-1))))
+		}
+	}
+
+	writeConfigMap(1)
+	require.NoError(t, os.Symlink(path.Join("..data", "dynamic.toml"), path.Join(tempDir, "dynamic.toml")))
+
+	provider := &Provider{
+		Filename: path.Join(tempDir, "dynamic.toml"),
+		Watch:    true,
+	}
+
+	configChan := make(chan config.Message)
+
+	go func() {
+		err := provider.Provide(configChan, safe.NewPool(context.Background()))
+		assert.NoError(t, err)
+	}()
+
+	waitForRouters := func(expected int) {
+		t.Helper()
+
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case conf := <-configChan:
+				if len(conf.Configuration.HTTP.Routers) == expected {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("timeout while waiting for %d routers", expected)
+			}
+		}
+	}
+
+	waitForRouters(1)
+
+	for version := 2; version <= 4; version++ {
+		writeConfigMap(version)
+		waitForRouters(version)
+	}
+}
+
+func TestBuildConfigurationKeepsLastValidConfiguration(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	writeFile := func(name, content string) string {
+		t.Helper()
+
+		filename := path.Join(tempDir, name)
+		require.NoError(t, ioutil.WriteFile(filename, []byte(content), 0644))
+		return filename
+	}
+
+	first := writeFile("a.toml", createRoutersConfiguration(2))
+	second := writeFile("b.toml", createServicesConfiguration(1))
+
+	provider := &Provider{Directory: tempDir}
+	require.NoError(t, provider.Init())
+
+	configuration, err := provider.BuildConfiguration()
+	require.NoError(t, err)
+	assert.Len(t, configuration.HTTP.Routers, 2)
+	assert.Len(t, configuration.HTTP.Services, 1)
+	assert.Empty(t, provider.FileErrors())
+
+	// The broken file keeps contributing its last valid configuration, while the others are reloaded.
+	writeFile("a.toml", "[http.routers\n")
+	writeFile("b.toml", createServicesConfiguration(3))
+
+	configuration, err = provider.BuildConfiguration()
+	require.NoError(t, err)
+	assert.Len(t, configuration.HTTP.Routers, 2)
+	assert.Len(t, configuration.HTTP.Services, 3)
+
+	fileErrors := provider.FileErrors()
+	require.Len(t, fileErrors, 1)
+	require.Contains(t, fileErrors, first)
+	assert.Contains(t, fileErrors[first].Error, first)
+	assert.NotContains(t, fileErrors, second)
+
+	since := fileErrors[first].Since
+	assert.False(t, since.IsZero())
+
+	// The failure keeps its start time while the file is broken.
+	writeFile("a.toml", createRoutersConfiguration(1)+"\n[http.routers.broken\n")
+
+	configuration, err = provider.BuildConfiguration()
+	require.NoError(t, err)
+	assert.Len(t, configuration.HTTP.Routers, 2)
+	assert.Equal(t, since, provider.FileErrors()[first].Since)
+
+	// The file contribution is replaced once it loads successfully again.
+	writeFile("a.toml", createRoutersConfiguration(3))
+
+	configuration, err = provider.BuildConfiguration()
+	require.NoError(t, err)
+	assert.Len(t, configuration.HTTP.Routers, 3)
+	assert.Empty(t, provider.FileErrors())
+
+	// The removed files are forgotten.
+	writeFile("a.toml", "[http.routers\n")
+	_, err = provider.BuildConfiguration()
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(first))
+
+	configuration, err = provider.BuildConfiguration()
+	require.NoError(t, err)
+	assert.Empty(t, configuration.HTTP.Routers)
+	assert.Empty(t, provider.FileErrors())
+}
+
+func TestBuildConfigurationWithoutLastValidConfiguration(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	file := createFile(t, tempDir, "dynamic.toml", "[http.routers\n")
+
+	provider := &Provider{Filename: file.Name()}
+	require.NoError(t, provider.Init())
+
+	_, err := provider.BuildConfiguration()
+	assert.Error(t, err)
+	assert.Contains(t, provider.FileErrors(), file.Name())
+}
+
+func TestTLSInlineContent(t *testing.T) {
+	readFixture := func(name string) string {
+		t.Helper()
+
+		content, err := ioutil.ReadFile(path.Join("..", "..", "..", "integration", "fixtures", "https", name))
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	comCert, comKey := readFixture("snitest.com.cert"), readFixture("snitest.com.key")
+	orgKey := readFixture("snitest.org.key")
+
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	orgCertFile := createFile(t, tempDir, "snitest.org.cert", readFixture("snitest.org.cert"))
+
+	fileConfig := createFile(t, tempDir, "dynamic.toml", `
+[[tls]]
+  [tls.certificate]
+    certFileContent = """
+`+comCert+`"""
+    keyFileContent = """
+`+comKey+`"""
+
+[[tls]]
+  [tls.certificate]
+    certFile = "`+orgCertFile.Name()+`"
+    keyFileContent = """
+`+orgKey+`"""
+
+[[tls]]
+  [tls.certificate]
+    certFileContent = """
+`+comCert+`"""
+    keyFileContent = """
+`+orgKey+`"""
+`)
+
+	provider := &Provider{}
+	configuration, err := provider.loadFileConfig(fileConfig.Name(), true)
+	require.NoError(t, err)
+
+	// The inline certificate which does not match its key is dropped.
+	require.Len(t, configuration.TLS, 2)
+
+	assert.Equal(t, comCert, configuration.TLS[0].Certificate.CertFile.String())
+	assert.Equal(t, comKey, configuration.TLS[0].Certificate.KeyFile.String())
+	assert.Empty(t, configuration.TLS[0].Certificate.CertFileContent)
+	assert.Empty(t, configuration.TLS[0].Certificate.KeyFileContent)
+
+	assert.Equal(t, readFixture("snitest.org.cert"), configuration.TLS[1].Certificate.CertFile.String())
+	assert.Equal(t, orgKey, configuration.TLS[1].Certificate.KeyFile.String())
+}
+
+// suppressedCounter counts the suppressed reloads, whatever the label values.
+type suppressedCounter struct {
+	value float64
+}
+
+func (c *suppressedCounter) With(labelValues ...string) gokitmetrics.Counter {
+	return c
+}
+
+func (c *suppressedCounter) Add(delta float64) {
+	c.value += delta
+}
+
+type suppressedRegistry struct {
+	metrics.Registry
+	counter *suppressedCounter
+}
+
+func (r suppressedRegistry) ProviderSuppressedReloadsCounter() gokitmetrics.Counter {
+	return r.counter
+}
+
+func TestWatcherCallbackSuppressesUnchangedReloads(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	writeFile := func(name, content string) {
+		t.Helper()
+		require.NoError(t, ioutil.WriteFile(path.Join(tempDir, name), []byte(content), 0644))
+	}
+
+	writeFile("a.toml", createRoutersConfiguration(2))
+	writeFile("b.toml", createServicesConfiguration(1))
+
+	counter := &suppressedCounter{}
+
+	provider := &Provider{Directory: tempDir}
+	require.NoError(t, provider.Init())
+	provider.SetMetricsRegistry(suppressedRegistry{Registry: metrics.NewVoidRegistry(), counter: counter})
+
+	configChan := make(chan config.Message, 1)
+	require.NoError(t, provider.Provide(configChan, safe.NewPool(context.Background())))
+	<-configChan
+
+	testCases := []struct {
+		desc               string
+		change             func()
+		expectedReload     bool
+		expectedSuppressed float64
+	}{
+		{
+			desc:               "file rewritten with the same content",
+			change:             func() { writeFile("a.toml", createRoutersConfiguration(2)) },
+			expectedSuppressed: 1,
+		},
+		{
+			desc:               "unrelated file written",
+			change:             func() { writeFile("notes.txt", "nothing to see") },
+			expectedSuppressed: 2,
+		},
+		{
+			desc:               "file changed",
+			change:             func() { writeFile("a.toml", createRoutersConfiguration(3)) },
+			expectedReload:     true,
+			expectedSuppressed: 2,
+		},
+		{
+			desc:               "file changed back",
+			change:             func() { writeFile("a.toml", createRoutersConfiguration(2)) },
+			expectedReload:     true,
+			expectedSuppressed: 2,
+		},
+		{
+			desc:               "file added",
+			change:             func() { writeFile("c.toml", createServicesConfiguration(0)) },
+			expectedReload:     true,
+			expectedSuppressed: 2,
+		},
+	}
+
+	// The test cases are applied in order, each on top of the previous ones.
+	for _, test := range testCases {
+		test.change()
+		provider.watcherCallback(configChan, fsnotify.Event{Name: tempDir, Op: fsnotify.Write})
+
+		select {
+		case <-configChan:
+			assert.True(t, test.expectedReload, "%s: unexpected reload", test.desc)
+		default:
+			assert.False(t, test.expectedReload, "%s: missing reload", test.desc)
+		}
+		assert.Equal(t, test.expectedSuppressed, counter.value, test.desc)
+	}
+}
+
+func TestLoadFileConfigTCPAndUDP(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		filename string
+		content  string
+	}{
+		{
+			desc:     "TOML",
+			filename: "dynamic.toml",
+			content: `
+[tcp.routers]
+  [tcp.routers.router1]
+    entryPoints = ["websecure"]
+    rule = "HostSNI(` + "`traefik.io`" + `)"
+    service = "service1"
+    [tcp.routers.router1.tls]
+      passthrough = true
+
+[tcp.services]
+  [tcp.services.service1.loadBalancer]
+    [[tcp.services.service1.loadBalancer.servers]]
+      address = "10.0.0.1:8443"
+    [[tcp.services.service1.loadBalancer.servers]]
+      address = "10.0.0.2:8443"
+
+[udp.routers]
+  [udp.routers.router1]
+    entryPoints = ["dns"]
+    service = "service1"
+
+[udp.services]
+  [udp.services.service1.loadBalancer]
+    [[udp.services.service1.loadBalancer.servers]]
+      address = "10.0.0.1:53"
+    [[udp.services.service1.loadBalancer.servers]]
+      address = "10.0.0.2:53"
+`,
+		},
+		{
+			desc:     "YAML",
+			filename: "dynamic.yaml",
+			content: `
+tcp:
+  routers:
+    router1:
+      entryPoints:
+        - websecure
+      rule: "HostSNI(` + "`traefik.io`" + `)"
+      service: service1
+      tls:
+        passthrough: true
+  services:
+    service1:
+      loadBalancer:
+        servers:
+          - address: "10.0.0.1:8443"
+          - address: "10.0.0.2:8443"
+
+udp:
+  routers:
+    router1:
+      entryPoints:
+        - dns
+      service: service1
+  services:
+    service1:
+      loadBalancer:
+        servers:
+          - address: "10.0.0.1:53"
+          - address: "10.0.0.2:53"
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			tempDir := createTempDir(t, "testfile")
+			defer os.RemoveAll(tempDir)
+
+			file := createFile(t, tempDir, test.filename, test.content)
+
+			provider := &Provider{}
+			configuration, err := provider.loadFileConfig(file.Name(), true)
+			require.NoError(t, err)
+
+			expectedTCP := &config.TCPConfiguration{
+				Routers: map[string]*config.TCPRouter{
+					"router1": {
+						EntryPoints: []string{"websecure"},
+						Rule:        "HostSNI(`traefik.io`)",
+						Service:     "service1",
+						TLS:         &config.RouterTCPTLSConfig{Passthrough: true},
+					},
+				},
+				Services: map[string]*config.TCPService{
+					"service1": {
+						LoadBalancer: &config.TCPLoadBalancerService{
+							Servers: []config.TCPServer{
+								{Address: "10.0.0.1:8443"},
+								{Address: "10.0.0.2:8443"},
+							},
+						},
+					},
+				},
+			}
+			assert.Equal(t, expectedTCP, configuration.TCP)
+
+			expectedUDP := &config.UDPConfiguration{
+				Routers: map[string]*config.UDPRouter{
+					"router1": {
+						EntryPoints: []string{"dns"},
+						Service:     "service1",
+					},
+				},
+				Services: map[string]*config.UDPService{
+					"service1": {
+						LoadBalancer: &config.UDPLoadBalancerService{
+							Servers: []config.UDPServer{
+								{Address: "10.0.0.1:53"},
+								{Address: "10.0.0.2:53"},
+							},
+						},
+					},
+				},
+			}
+			assert.Equal(t, expectedUDP, configuration.UDP)
+		})
+	}
+}
+
+func TestLoadFileConfigTCPAndUDPUnknownFields(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		filename      string
+		content       string
+		expectedError string
+	}{
+		{
+			desc:     "TOML",
+			filename: "dynamic.toml",
+			content: `
+[tcp.routers]
+  [tcp.routers.router1]
+    service = "service1"
+    [tcp.routers.router1.tls]
+      passthru = true
+
+[udp.services]
+  [udp.services.service1.loadBalancer]
+    [[udp.services.service1.loadBalancer.servers]]
+      url = "10.0.0.1:53"
+`,
+			expectedError: "unknown fields: tcp.routers.router1.tls.passthru, udp.services.service1.loadBalancer.servers.url",
+		},
+		{
+			desc:     "YAML",
+			filename: "dynamic.yml",
+			content: `
+tcp:
+  routers:
+    router1:
+      service: service1
+      tls:
+        passthru: true
+udp:
+  services:
+    service1:
+      loadBalancer:
+        servers:
+          - url: "10.0.0.1:53"
+`,
+			expectedError: "unknown fields: tcp.routers.router1.tls.passthru, udp.services.service1.loadBalancer.servers.url",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			tempDir := createTempDir(t, "testfile")
+			defer os.RemoveAll(tempDir)
+
+			file := createFile(t, tempDir, test.filename, test.content)
+
+			_, err := (&Provider{}).loadFileConfig(file.Name(), true)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.expectedError)
+
+			_, err = (&Provider{AllowUnknownFields: true}).loadFileConfig(file.Name(), true)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLoadFileConfigFromDirectoryTOMLAndYAML(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "a.toml", `
+[udp.services]
+  [udp.services.service1.loadBalancer]
+    [[udp.services.service1.loadBalancer.servers]]
+      address = "10.0.0.1:53"
+  [udp.services.conflict.loadBalancer]
+    [[udp.services.conflict.loadBalancer.servers]]
+      address = "10.0.0.1:53"
+`)
+	createFile(t, tempDir, "b.yaml", `
+udp:
+  services:
+    service2:
+      loadBalancer:
+        servers:
+          - address: "10.0.0.2:53"
+    conflict:
+      loadBalancer:
+        servers:
+          - address: "10.0.0.2:53"
+`)
+	createFile(t, tempDir, "c.json", `{"udp": {}}`)
+
+	provider := &Provider{Directory: tempDir}
+
+	configuration, err := provider.BuildConfiguration()
+	require.NoError(t, err)
+
+	assert.Len(t, configuration.UDP.Services, 2)
+	assert.Contains(t, configuration.UDP.Services, "service1")
+	assert.Contains(t, configuration.UDP.Services, "service2")
+
+	assert.Equal(t, config.Source{File: path.Join(tempDir, "a.toml"), Line: 3}, configuration.Sources.UDPServices["service1"])
+	assert.Equal(t, config.Source{File: path.Join(tempDir, "b.yaml")}, configuration.Sources.UDPServices["service2"])
+}
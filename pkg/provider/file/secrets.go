@@ -0,0 +1,116 @@
+package file
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/containous/traefik/pkg/config"
+)
+
+// secretFilePrefix is the prefix of the values of the secret-bearing fields which reference a file.
+const secretFilePrefix = "file://"
+
+// resolveSecrets replaces the values of the secret-bearing fields of the middlewares which reference a file,
+// e.g. file:///run/secrets/users.htpasswd, with the content of the file.
+// A middleware referencing a file which cannot be read is removed from the configuration, and its error returned.
+// It returns the referenced files, including the ones which cannot be read, so that they are watched.
+func resolveSecrets(filename string, configuration *config.Configuration) ([]string, []error) {
+	if configuration.HTTP == nil {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(configuration.HTTP.Middlewares))
+	for name := range configuration.HTTP.Middlewares {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var secretFiles []string
+	var errs []error
+	for _, name := range names {
+		resolver := &secretResolver{}
+		resolver.resolveMiddleware(configuration.HTTP.Middlewares[name])
+
+		secretFiles = append(secretFiles, resolver.files...)
+
+		if resolver.err != nil {
+			errs = append(errs, fmt.Errorf("unable to read a secret of the middleware %s in %s: %v", name, filename, resolver.err))
+			delete(configuration.HTTP.Middlewares, name)
+		}
+	}
+
+	return secretFiles, errs
+}
+
+// secretResolver resolves the secret-bearing fields of a middleware.
+// It keeps the first error, and every file referenced.
+type secretResolver struct {
+	files []string
+	err   error
+}
+
+func (r *secretResolver) resolveMiddleware(middleware *config.Middleware) {
+	if middleware == nil {
+		return
+	}
+
+	if middleware.BasicAuth != nil {
+		middleware.BasicAuth.Users = r.resolveList(middleware.BasicAuth.Users)
+	}
+
+	if middleware.DigestAuth != nil {
+		middleware.DigestAuth.Users = r.resolveList(middleware.DigestAuth.Users)
+	}
+
+	if middleware.ForwardAuth != nil && middleware.ForwardAuth.TLS != nil {
+		clientTLS := middleware.ForwardAuth.TLS
+		clientTLS.CA = r.resolve(clientTLS.CA)
+		clientTLS.Cert = r.resolve(clientTLS.Cert)
+		clientTLS.Key = r.resolve(clientTLS.Key)
+	}
+}
+
+// resolveList replaces the items referencing a file with the lines of the file.
+func (r *secretResolver) resolveList(values []string) []string {
+	if values == nil {
+		return nil
+	}
+
+	resolved := make([]string, 0, len(values))
+	for _, value := range values {
+		if !strings.HasPrefix(value, secretFilePrefix) {
+			resolved = append(resolved, value)
+			continue
+		}
+
+		for _, line := range strings.Split(r.resolve(value), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				resolved = append(resolved, line)
+			}
+		}
+	}
+
+	return resolved
+}
+
+// resolve returns the trimmed content of the file referenced by the value, or the value itself.
+func (r *secretResolver) resolve(value string) string {
+	if !strings.HasPrefix(value, secretFilePrefix) {
+		return value
+	}
+
+	path := strings.TrimPrefix(value, secretFilePrefix)
+	r.files = append(r.files, path)
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		if r.err == nil {
+			r.err = err
+		}
+		return ""
+	}
+
+	return strings.TrimSpace(string(content))
+}
@@ -0,0 +1,163 @@
+package file
+
+import (
+	"strings"
+
+	"github.com/containous/traefik/pkg/config"
+)
+
+// elementKey identifies an element of the dynamic configuration, e.g. {"http.routers", "router1"}.
+type elementKey struct {
+	section string
+	name    string
+}
+
+// elementSections are the sections holding the elements whose definitions are located.
+var elementSections = map[string]struct{}{
+	"http.routers":           {},
+	"http.middlewares":       {},
+	"http.services":          {},
+	"http.serverstransports": {},
+	"tcp.routers":            {},
+	"tcp.services":           {},
+	"udp.routers":            {},
+	"udp.services":           {},
+}
+
+// findElementLines returns the line of the first key defining each element in the TOML content.
+// It only follows the tables and the key/value pairs, which is enough to locate the elements,
+// and skips the content of the multi-line strings, e.g. of the inline certificates.
+func findElementLines(content string) map[elementKey]int {
+	lines := make(map[elementKey]int)
+
+	var table []string
+	var multiline string
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+
+		if multiline != "" {
+			if strings.Count(line, multiline)%2 == 1 {
+				multiline = ""
+			}
+			continue
+		}
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var path []string
+		switch {
+		case strings.HasPrefix(line, "[["):
+			table = splitKey(strings.TrimSuffix(strings.TrimPrefix(line, "[["), "]]"))
+			path = table
+		case strings.HasPrefix(line, "["):
+			table = splitKey(strings.TrimSuffix(strings.TrimPrefix(line, "["), "]"))
+			path = table
+		default:
+			index := strings.Index(line, "=")
+			if index < 0 {
+				continue
+			}
+
+			path = append(append([]string{}, table...), splitKey(line[:index])...)
+
+			for _, delimiter := range []string{`"""`, `'''`} {
+				if strings.Count(line[index:], delimiter)%2 == 1 {
+					multiline = delimiter
+				}
+			}
+		}
+
+		if len(path) < 3 {
+			continue
+		}
+
+		section := strings.ToLower(path[0] + "." + path[1])
+		if _, ok := elementSections[section]; !ok {
+			continue
+		}
+
+		key := elementKey{section: section, name: path[2]}
+		if _, ok := lines[key]; !ok {
+			lines[key] = i + 1
+		}
+	}
+
+	return lines
+}
+
+// splitKey splits the dotted key into its parts, which may be quoted.
+func splitKey(key string) []string {
+	var parts []string
+	var part strings.Builder
+	var quote rune
+
+	for _, r := range strings.TrimSpace(key) {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+			part.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '.':
+			parts = append(parts, strings.TrimSpace(part.String()))
+			part.Reset()
+		default:
+			part.WriteRune(r)
+		}
+	}
+
+	return append(parts, strings.TrimSpace(part.String()))
+}
+
+// newSources locates the elements of the configuration decoded from the file.
+// The elements generated by a template, which are not found in the content of the file, have no line.
+func newSources(filename, content string, configuration *config.Configuration) *config.Sources {
+	lines := findElementLines(content)
+
+	sources := config.NewSources()
+
+	source := func(section, name string) config.Source {
+		return config.Source{File: filename, Line: lines[elementKey{section: section, name: name}]}
+	}
+
+	if configuration.HTTP != nil {
+		for name := range configuration.HTTP.Routers {
+			sources.Routers[name] = source("http.routers", name)
+		}
+		for name := range configuration.HTTP.Middlewares {
+			sources.Middlewares[name] = source("http.middlewares", name)
+		}
+		for name := range configuration.HTTP.Services {
+			sources.Services[name] = source("http.services", name)
+		}
+		for name := range configuration.HTTP.ServersTransports {
+			sources.ServersTransports[name] = source("http.serverstransports", name)
+		}
+	}
+
+	if configuration.TCP != nil {
+		for name := range configuration.TCP.Routers {
+			sources.TCPRouters[name] = source("tcp.routers", name)
+		}
+		for name := range configuration.TCP.Services {
+			sources.TCPServices[name] = source("tcp.services", name)
+		}
+	}
+
+	if configuration.UDP != nil {
+		for name := range configuration.UDP.Routers {
+			sources.UDPRouters[name] = source("udp.routers", name)
+		}
+		for name := range configuration.UDP.Services {
+			sources.UDPServices[name] = source("udp.services", name)
+		}
+	}
+
+	return sources
+}
@@ -0,0 +1,79 @@
+package file
+
+import (
+	"testing"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindElementLines(t *testing.T) {
+	content := `
+# [http.routers.commented]
+[http.routers]
+  [http.routers.router1]
+    rule = "Host(` + "`traefik.io`" + `)"
+    service = "service1"
+
+  [http.routers."router.2"]
+    service = "service1"
+
+[http.middlewares]
+  foo.headers.customRequestHeaders.X-Foo = "bar"
+
+[HTTP.Services]
+  [HTTP.Services.service1.loadBalancer]
+    [[HTTP.Services.service1.loadBalancer.servers]]
+      url = "http://10.0.0.1:80"
+
+[[tls]]
+  [tls.certificate]
+    certFile = """-----BEGIN CERTIFICATE-----
+[http.routers.fake]
+-----END CERTIFICATE-----"""
+
+[tcp]
+  routers.router1.service = "service1"
+  [tcp.services.service1.loadBalancer]
+    [[tcp.services.service1.loadBalancer.servers]]
+      address = "10.0.0.1:8080"
+`
+
+	expected := map[elementKey]int{
+		{section: "http.routers", name: "router1"}:   4,
+		{section: "http.routers", name: "router.2"}:  8,
+		{section: "http.middlewares", name: "foo"}:   12,
+		{section: "http.services", name: "service1"}: 15,
+		{section: "tcp.routers", name: "router1"}:    26,
+		{section: "tcp.services", name: "service1"}:  27,
+	}
+
+	assert.Equal(t, expected, findElementLines(content))
+}
+
+func TestNewSources(t *testing.T) {
+	content := `
+[http.routers]
+  [http.routers.router1]
+    service = "service1"
+`
+
+	configuration := &config.Configuration{
+		HTTP: &config.HTTPConfiguration{
+			Routers: map[string]*config.Router{
+				"router1":   {Service: "service1"},
+				"generated": {Service: "service1"},
+			},
+		},
+	}
+
+	sources := newSources("dynamic.toml", content, configuration)
+
+	expected := map[string]config.Source{
+		"router1":   {File: "dynamic.toml", Line: 3},
+		"generated": {File: "dynamic.toml"},
+	}
+	assert.Equal(t, expected, sources.Routers)
+	assert.Equal(t, "dynamic.toml:3", sources.Routers["router1"].String())
+	assert.Equal(t, "dynamic.toml", sources.Routers["generated"].String())
+}
@@ -0,0 +1,129 @@
+package file
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+)
+
+// FileError describes a configuration file which is failing to load.
+type FileError struct {
+	Since time.Time `json:"since"`
+	Error string    `json:"error"`
+}
+
+// fileStates remembers, per file, the last configuration loaded successfully along with the secret files it references,
+// and since when the files which are failing to load are failing.
+type fileStates struct {
+	mu        sync.Mutex
+	lastValid map[string]*config.Configuration
+	secrets   map[string][]string
+	errors    map[string]FileError
+}
+
+func newFileStates() *fileStates {
+	return &fileStates{
+		lastValid: make(map[string]*config.Configuration),
+		secrets:   make(map[string][]string),
+		errors:    make(map[string]FileError),
+	}
+}
+
+// loaded records the configuration loaded from the file, which is no longer failing.
+func (s *fileStates) loaded(filename string, configuration *config.Configuration, secrets []string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastValid[filename] = configuration
+	s.secrets[filename] = secrets
+	delete(s.errors, filename)
+}
+
+// failed records the error of the file, and returns its last valid configuration, if any.
+func (s *fileStates) failed(filename string, err error, now time.Time) (*config.Configuration, bool) {
+	if s == nil {
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	since := now
+	if fileErr, ok := s.errors[filename]; ok {
+		since = fileErr.Since
+	}
+	s.errors[filename] = FileError{Since: since, Error: err.Error()}
+
+	configuration, ok := s.lastValid[filename]
+	return configuration, ok
+}
+
+// prune forgets the files which are no longer loaded.
+func (s *fileStates) prune(filenames map[string]struct{}) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for filename := range s.lastValid {
+		if _, ok := filenames[filename]; !ok {
+			delete(s.lastValid, filename)
+			delete(s.secrets, filename)
+		}
+	}
+
+	for filename := range s.errors {
+		if _, ok := filenames[filename]; !ok {
+			delete(s.errors, filename)
+		}
+	}
+}
+
+func (s *fileStates) getErrors() map[string]FileError {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	errors := make(map[string]FileError, len(s.errors))
+	for filename, fileErr := range s.errors {
+		errors[filename] = fileErr
+	}
+
+	return errors
+}
+
+// getSecretFiles returns the secret files referenced by the last valid configurations, sorted.
+func (s *fileStates) getSecretFiles() []string {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unique := make(map[string]struct{})
+	for _, secrets := range s.secrets {
+		for _, secret := range secrets {
+			unique[secret] = struct{}{}
+		}
+	}
+
+	files := make([]string, 0, len(unique))
+	for file := range unique {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	return files
+}
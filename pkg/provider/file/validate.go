@@ -0,0 +1,162 @@
+package file
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/config/parser"
+	"github.com/containous/traefik/pkg/rules"
+)
+
+// Validate loads the configuration file at path into element, which must be a *config.Configuration,
+// as the provider does with its default options, and checks the configuration.
+// It returns all the problems found, rather than stopping at the first one.
+func Validate(path string, element interface{}) []error {
+	configuration, ok := element.(*config.Configuration)
+	if !ok {
+		return []error{fmt.Errorf("unsupported element %T, a dynamic configuration is expected", element)}
+	}
+
+	decoded, err := (&Provider{}).decodeFileConfig(path, true)
+	if errs, ok := err.(parser.DecodeErrors); ok {
+		return errs
+	}
+	if err != nil {
+		return []error{err}
+	}
+
+	*configuration = *decoded.configuration
+
+	return append(decoded.skipped, checkConfiguration(configuration)...)
+}
+
+// checkConfiguration checks the references of the routers and of the chains to the services and the middlewares,
+// and the syntax of the rules of the routers.
+// The references to the elements of the other providers, qualified with the name of the provider, are not checked.
+func checkConfiguration(configuration *config.Configuration) []error {
+	var errs []error
+
+	if configuration.HTTP != nil {
+		errs = append(errs, checkHTTPConfiguration(configuration.HTTP)...)
+	}
+
+	if configuration.TCP != nil {
+		errs = append(errs, checkTCPConfiguration(configuration.TCP)...)
+	}
+
+	if configuration.UDP != nil {
+		errs = append(errs, checkUDPConfiguration(configuration.UDP)...)
+	}
+
+	return errs
+}
+
+func checkHTTPConfiguration(configuration *config.HTTPConfiguration) []error {
+	var errs []error
+
+	router, err := rules.NewRouter()
+	if err != nil {
+		return []error{err}
+	}
+
+	for _, name := range sortedKeys(configuration.Routers) {
+		rt := configuration.Routers[name]
+
+		if !isQualified(rt.Service) {
+			if _, ok := configuration.Services[rt.Service]; !ok {
+				errs = append(errs, fmt.Errorf("router %s: unknown service %q", name, rt.Service))
+			}
+		}
+
+		for _, middleware := range rt.Middlewares {
+			if _, ok := configuration.Middlewares[middleware]; !ok && !isQualified(middleware) {
+				errs = append(errs, fmt.Errorf("router %s: unknown middleware %q", name, middleware))
+			}
+		}
+
+		if rt.Fallback {
+			if err := rt.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("router %s: %v", name, err))
+			}
+		} else if err := router.AddRoute(rt.Rule, 0, http.NotFoundHandler()); err != nil {
+			errs = append(errs, fmt.Errorf("router %s: invalid rule: %v", name, err))
+		}
+	}
+
+	for _, name := range sortedKeys(configuration.Middlewares) {
+		middleware := configuration.Middlewares[name]
+		if middleware == nil || middleware.Chain == nil {
+			continue
+		}
+
+		for _, link := range middleware.Chain.Middlewares {
+			if _, ok := configuration.Middlewares[link]; !ok && !isQualified(link) {
+				errs = append(errs, fmt.Errorf("middleware %s: unknown middleware %q in the chain", name, link))
+			}
+		}
+	}
+
+	return errs
+}
+
+func checkTCPConfiguration(configuration *config.TCPConfiguration) []error {
+	var errs []error
+
+	for _, name := range sortedKeys(configuration.Routers) {
+		rt := configuration.Routers[name]
+
+		if !isQualified(rt.Service) {
+			if _, ok := configuration.Services[rt.Service]; !ok {
+				errs = append(errs, fmt.Errorf("TCP router %s: unknown service %q", name, rt.Service))
+			}
+		}
+
+		if rt.Fallback {
+			if err := rt.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("TCP router %s: %v", name, err))
+			}
+		} else if _, err := rules.ParseHostSNI(rt.Rule); err != nil {
+			errs = append(errs, fmt.Errorf("TCP router %s: invalid rule %s: %v", name, rt.Rule, err))
+		}
+	}
+
+	return errs
+}
+
+func checkUDPConfiguration(configuration *config.UDPConfiguration) []error {
+	var errs []error
+
+	for _, name := range sortedKeys(configuration.Routers) {
+		rt := configuration.Routers[name]
+
+		if !isQualified(rt.Service) {
+			if _, ok := configuration.Services[rt.Service]; !ok {
+				errs = append(errs, fmt.Errorf("UDP router %s: unknown service %q", name, rt.Service))
+			}
+		}
+	}
+
+	return errs
+}
+
+// isQualified tells whether the name references an element of another provider, as in docker.auth.
+func isQualified(name string) bool {
+	return strings.Contains(name, ".")
+}
+
+// sortedKeys returns the keys of the map, which must have string keys, sorted.
+func sortedKeys(m interface{}) []string {
+	value := reflect.ValueOf(m)
+
+	keys := make([]string, 0, value.Len())
+	for _, key := range value.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,184 @@
+package file
+
+import (
+	"os"
+	"testing"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		content        string
+		expectedErrors []string
+	}{
+		{
+			desc: "valid",
+			content: `
+[http.routers]
+  [http.routers.router1]
+    rule = "Host(` + "`traefik.io`" + `)"
+    service = "service1"
+    middlewares = ["chain", "docker.auth"]
+
+  [http.routers.router2]
+    rule = "Path(` + "`/api`" + `)"
+    service = "docker.api"
+
+  [http.routers.router3]
+    rule = "Host(` + "`traefik.io`" + `) && Query(` + "`utm_source=internal`" + `) && Query(` + "`debug`" + `)"
+    service = "service1"
+
+[http.middlewares]
+  [http.middlewares.chain.chain]
+    middlewares = ["retry"]
+  [http.middlewares.retry.retry]
+    attempts = 3
+
+[http.services]
+  [http.services.service1.loadBalancer]
+    [[http.services.service1.loadBalancer.servers]]
+      url = "http://10.0.0.1:80"
+
+[tcp.routers]
+  [tcp.routers.router1]
+    rule = "HostSNI(` + "`traefik.io`" + `)"
+    service = "service1"
+
+[tcp.services]
+  [tcp.services.service1.loadBalancer]
+    [[tcp.services.service1.loadBalancer.servers]]
+      address = "10.0.0.1:8080"
+`,
+		},
+		{
+			desc: "all the problems",
+			content: `
+[http.routers]
+  [http.routers.router1]
+    rule = "Host(` + "`traefik.io`" + `"
+    service = "service2"
+    middlewares = ["chain", "unknown"]
+
+  [http.routers.router2]
+    rule = "Path(` + "`/api`" + `)"
+    service = "service1"
+
+[http.middlewares]
+  [http.middlewares.auth.basicAuth]
+    users = ["file:///does/not/exist"]
+  [http.middlewares.chain.chain]
+    middlewares = ["auth", "retry"]
+
+[http.services]
+  [http.services.service1.loadBalancer]
+    [[http.services.service1.loadBalancer.servers]]
+      url = "http://10.0.0.1:80"
+
+[tcp.routers]
+  [tcp.routers.router1]
+    rule = "Host(` + "`traefik.io`" + `)"
+    service = "service1"
+
+[udp.routers]
+  [udp.routers.router1]
+    service = "service1"
+`,
+			expectedErrors: []string{
+				"unable to read a secret of the middleware auth in",
+				`router router1: unknown service "service2"`,
+				`router router1: unknown middleware "unknown"`,
+				"router router1: invalid rule: error while parsing rule Host(`traefik.io`",
+				`middleware chain: unknown middleware "auth" in the chain`,
+				`middleware chain: unknown middleware "retry" in the chain`,
+				`TCP router router1: unknown service "service1"`,
+				"TCP router router1: invalid rule Host(`traefik.io`)",
+				`UDP router router1: unknown service "service1"`,
+			},
+		},
+		{
+			desc: "unknown fields",
+			content: `
+[http.routers]
+  [http.routers.router1]
+    rule = "Host(` + "`traefik.io`" + `)"
+    service = "service1"
+    entrypoint = ["web"]
+`,
+			expectedErrors: []string{
+				"unknown fields: http.routers.router1.entrypoint",
+			},
+		},
+		{
+			desc: "unknown matcher",
+			content: `
+[http.routers]
+  [http.routers.router1]
+    rule = "Host(` + "`traefik.io`" + `) && Queries(` + "`v=2`" + `)"
+    service = "service1"
+
+[http.services]
+  [http.services.service1.loadBalancer]
+    [[http.services.service1.loadBalancer.servers]]
+      url = "http://10.0.0.1:80"
+`,
+			expectedErrors: []string{
+				"router router1: invalid rule: error while parsing rule Host(`traefik.io`) && Queries(`v=2`): unsupported function: Queries (known matchers: ",
+			},
+		},
+		{
+			desc: "invalid values",
+			content: `
+[http.routers]
+  [http.routers.router1]
+    rule = "Host(` + "`traefik.io`" + `)"
+    service = "service1"
+    priority = "high"
+
+[http.services]
+  [http.services.service1.loadBalancer]
+    passHostHeader = "yes"
+    [[http.services.service1.loadBalancer.servers]]
+      url = "http://10.0.0.1:80"
+    [[http.services.service1.loadBalancer.servers]]
+      url = 80
+`,
+			expectedErrors: []string{
+				"http.routers.router1.priority: ",
+				"http.services.service1.loadBalancer.passHostHeader: ",
+				"http.services.service1.loadBalancer.servers[1].url: ",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			tempDir := createTempDir(t, "testvalidate")
+			defer os.RemoveAll(tempDir)
+
+			file := createFile(t, tempDir, "dynamic.toml", test.content)
+
+			configuration := &config.Configuration{}
+			errs := Validate(file.Name(), configuration)
+
+			require.Len(t, errs, len(test.expectedErrors), "%v", errs)
+			for i, expected := range test.expectedErrors {
+				assert.Contains(t, errs[i].Error(), expected)
+			}
+
+			if len(test.expectedErrors) == 0 {
+				assert.Contains(t, configuration.HTTP.Routers, "router1")
+			}
+		})
+	}
+}
+
+func TestValidateUnsupportedElement(t *testing.T) {
+	errs := Validate("dynamic.toml", &struct{}{})
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "unsupported element *struct {}, a dynamic configuration is expected")
+}
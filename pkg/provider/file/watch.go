@@ -0,0 +1,136 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/containous/traefik/pkg/log"
+	"gopkg.in/fsnotify.v1"
+)
+
+// watchSet holds the paths watched by the watcher.
+// It is re-evaluated after each event, so that the files which appear are watched,
+// and the files which are no longer part of the configuration are not.
+type watchSet struct {
+	watcher *fsnotify.Watcher
+	paths   map[string]struct{}
+}
+
+func newWatchSet(watcher *fsnotify.Watcher) *watchSet {
+	return &watchSet{
+		watcher: watcher,
+		paths:   make(map[string]struct{}),
+	}
+}
+
+// update watches the given paths, and stops watching the other ones.
+// The paths are added again on each update, which is a no-op for the watched ones,
+// as a path removed and created again between two updates lost its watch.
+// A watch is removed asynchronously, as removing it waits for its pending events to be consumed by the events loop.
+func (w *watchSet) update(paths []string) {
+	logger := log.WithoutContext().WithField(log.ProviderName, providerName)
+
+	wanted := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		wanted[path] = struct{}{}
+
+		if err := w.watcher.Add(path); err != nil {
+			logger.Errorf("Unable to watch %s: %v", path, err)
+			delete(w.paths, path)
+			continue
+		}
+
+		if _, ok := w.paths[path]; !ok {
+			logger.Debugf("Watching %s", path)
+			w.paths[path] = struct{}{}
+		}
+	}
+
+	for path := range w.paths {
+		if _, ok := wanted[path]; ok {
+			continue
+		}
+
+		logger.Debugf("No longer watching %s", path)
+		delete(w.paths, path)
+
+		go func(path string) {
+			// The watch of a removed path is already gone.
+			if err := w.watcher.Remove(path); err != nil {
+				logger.Debugf("Unable to stop watching %s: %v", path, err)
+			}
+		}(path)
+	}
+}
+
+// watchPaths returns the paths to watch: the directory and its subdirectories in the directory mode,
+// the directory of the file and the target of the file when it is a symlink otherwise,
+// and the directories of the secret files.
+// The paths which do not exist are skipped, they are watched once they appear in the watched directories.
+func (p *Provider) watchPaths(filename, target string) []string {
+	var paths []string
+
+	if len(p.Directory) > 0 {
+		paths = append(paths, p.Directory)
+		paths = append(paths, listDirs(p.Directory)...)
+	} else {
+		paths = append(paths, filepath.Dir(filename))
+
+		if target != "" && target != filepath.Clean(filename) {
+			paths = append(paths, target)
+		}
+	}
+
+	for dir := range p.secretDirs() {
+		paths = append(paths, dir)
+	}
+
+	existing := paths[:0]
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			existing = append(existing, path)
+		}
+	}
+
+	return existing
+}
+
+// secretDirs returns the directories of the secret files referenced by the configuration,
+// so that the rotation of a secret, which may be a symlink swap as for a Kubernetes secret, triggers a reload.
+func (p *Provider) secretDirs() map[string]struct{} {
+	dirs := make(map[string]struct{})
+	for _, secret := range p.states.getSecretFiles() {
+		dirs[filepath.Dir(secret)] = struct{}{}
+	}
+	return dirs
+}
+
+// resolveTarget resolves the symlinks of filename.
+// The previous target is kept while the target cannot be resolved, as the symlink may be in the middle of a swap.
+func resolveTarget(filename, previous string) string {
+	target, err := filepath.EvalSymlinks(filename)
+	if err != nil {
+		return previous
+	}
+	return target
+}
+
+// listDirs returns the subdirectories of the directory, recursively.
+func listDirs(directory string) []string {
+	items, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	for _, item := range items {
+		if item.IsDir() {
+			dir := filepath.Join(directory, item.Name())
+			dirs = append(dirs, dir)
+			dirs = append(dirs, listDirs(dir)...)
+		}
+	}
+
+	return dirs
+}
@@ -0,0 +1,188 @@
+package file
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/safe"
+	"github.com/containous/traefik/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/fsnotify.v1"
+)
+
+func TestWatchSetUpdate(t *testing.T) {
+	tempDir := createTempDir(t, "testwatch")
+	defer os.RemoveAll(tempDir)
+
+	first := path.Join(tempDir, "first")
+	second := path.Join(tempDir, "second")
+	require.NoError(t, os.Mkdir(first, 0755))
+	require.NoError(t, os.Mkdir(second, 0755))
+
+	watcher, err := fsnotify.NewWatcher()
+	require.NoError(t, err)
+	defer watcher.Close()
+
+	watches := newWatchSet(watcher)
+
+	watches.update([]string{first, second})
+	assert.Equal(t, map[string]struct{}{first: {}, second: {}}, watches.paths)
+
+	watches.update([]string{first})
+	assert.Equal(t, map[string]struct{}{first: {}}, watches.paths)
+
+	watches.update([]string{first, path.Join(tempDir, "missing")})
+	assert.Equal(t, map[string]struct{}{first: {}}, watches.paths)
+}
+
+func TestProvideWithWatchFileRemoval(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	content := createRoutersConfiguration(2)
+	file := createFile(t, tempDir, "dynamic.toml", content)
+
+	provider := &Provider{
+		Filename: file.Name(),
+		Watch:    true,
+	}
+	require.NoError(t, provider.Init())
+
+	configChan := make(chan config.Message)
+
+	go func() {
+		err := provider.Provide(configChan, safe.NewPool(context.Background()))
+		assert.NoError(t, err)
+	}()
+
+	waitForRouters := func(expected int) {
+		t.Helper()
+
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case conf := <-configChan:
+				if len(conf.Configuration.HTTP.Routers) == expected {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("timeout while waiting for %d routers", expected)
+			}
+		}
+	}
+
+	waitForRouters(2)
+
+	require.NoError(t, os.Remove(file.Name()))
+	waitForRouters(0)
+
+	require.NoError(t, ioutil.WriteFile(file.Name(), []byte(content), 0644))
+	waitForRouters(2)
+}
+
+func TestProvideWithWatchNewSubdirectory(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	createFile(t, tempDir, "a.toml", createRoutersConfiguration(1))
+
+	provider := &Provider{
+		Directory: tempDir,
+		Watch:     true,
+	}
+	require.NoError(t, provider.Init())
+
+	configChan := make(chan config.Message)
+
+	go func() {
+		err := provider.Provide(configChan, safe.NewPool(context.Background()))
+		assert.NoError(t, err)
+	}()
+
+	waitForRouters := func(expected int) {
+		t.Helper()
+
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case conf := <-configChan:
+				if len(conf.Configuration.HTTP.Routers) == expected {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("timeout while waiting for %d routers", expected)
+			}
+		}
+	}
+
+	waitForRouters(1)
+
+	subDir := path.Join(tempDir, "sub")
+	require.NoError(t, os.Mkdir(subDir, 0755))
+	// The creation of the subdirectory adds its watch, with the next event.
+	createFile(t, tempDir, "b.toml", `
+[http.routers]
+  [http.routers.routerb]
+    service = "application-b"
+`)
+	waitForRouters(2)
+
+	createFile(t, subDir, "c.toml", `
+[http.routers]
+  [http.routers.routerc]
+    service = "application-c"
+`)
+	waitForRouters(3)
+}
+
+func TestProvideWithWatchDebounce(t *testing.T) {
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	file := createFile(t, tempDir, "dynamic.toml", createRoutersConfiguration(1))
+
+	debounce := 300 * time.Millisecond
+	provider := &Provider{
+		Filename:         file.Name(),
+		Watch:            true,
+		DebounceDuration: types.Duration(debounce),
+	}
+	require.NoError(t, provider.Init())
+
+	configChan := make(chan config.Message)
+
+	go func() {
+		err := provider.Provide(configChan, safe.NewPool(context.Background()))
+		assert.NoError(t, err)
+	}()
+
+	select {
+	case conf := <-configChan:
+		assert.Len(t, conf.Configuration.HTTP.Routers, 1)
+	case <-time.After(time.Second):
+		t.Fatal("timeout while waiting for the initial configuration")
+	}
+
+	for i := 1; i <= 10; i++ {
+		require.NoError(t, ioutil.WriteFile(file.Name(), []byte(createRoutersConfiguration(i)), 0644))
+	}
+
+	select {
+	case conf := <-configChan:
+		assert.Len(t, conf.Configuration.HTTP.Routers, 10)
+	case <-time.After(debounce + time.Second):
+		t.Fatal("timeout while waiting for the configuration")
+	}
+
+	select {
+	case conf := <-configChan:
+		t.Fatalf("unexpected configuration with %d routers", len(conf.Configuration.HTTP.Routers))
+	case <-time.After(2 * debounce):
+	}
+}
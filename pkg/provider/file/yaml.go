@@ -0,0 +1,44 @@
+package file
+
+import (
+	"bytes"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/containous/traefik/pkg/config/file"
+	"gopkg.in/yaml.v2"
+)
+
+// isYAML tells whether the file holds a YAML configuration, after its extension.
+func isYAML(filename string) bool {
+	switch filepath.Ext(filename) {
+	case ".yml", ".yaml":
+		return true
+	default:
+		return false
+	}
+}
+
+// yamlToTOML converts the YAML content into TOML,
+// so that it goes through the same decoding, and the same checks of the unknown fields, as a TOML file.
+func yamlToTOML(content string) (string, error) {
+	data := make(map[string]interface{})
+
+	err := yaml.Unmarshal([]byte(content), data)
+	if err != nil {
+		return "", err
+	}
+
+	err = file.NormalizeYAML(data)
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	err = toml.NewEncoder(&buffer).Encode(data)
+	if err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
@@ -0,0 +1,220 @@
+package marathon
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/pkg/log"
+	"github.com/dgrijalva/jwt-go"
+)
+
+// dcosLoginTokenValidity is the validity of the token signed with the service account private key to log in.
+const dcosLoginTokenValidity = 5 * time.Minute
+
+// dcosCredentials is a DC/OS service account credentials file.
+type dcosCredentials struct {
+	Scheme        string `json:"scheme"`
+	UID           string `json:"uid"`
+	PrivateKey    string `json:"private_key"`
+	LoginEndpoint string `json:"login_endpoint"`
+}
+
+// dcosAuth provides the DC/OS token, either read from a file, or obtained by logging in with a service account,
+// and renews it when Marathon rejects it.
+type dcosAuth struct {
+	tokenFile       string
+	credentialsFile string
+	client          *http.Client
+	clock           clock
+
+	mu    sync.Mutex
+	token string
+}
+
+func newDCOSAuth(tokenFile, credentialsFile string, transport http.RoundTripper, clk clock) *dcosAuth {
+	return &dcosAuth{
+		tokenFile:       tokenFile,
+		credentialsFile: credentialsFile,
+		client:          &http.Client{Transport: transport},
+		clock:           clk,
+	}
+}
+
+// get returns the current token, loading it if needed.
+func (a *dcosAuth) get() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.token) > 0 {
+		return a.token, nil
+	}
+
+	return a.load()
+}
+
+// renew loads a new token, unless the rejected token has already been renewed in the meantime.
+func (a *dcosAuth) renew(rejected string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.token) > 0 && a.token != rejected {
+		return a.token, nil
+	}
+
+	return a.load()
+}
+
+// load must be called with the lock held.
+func (a *dcosAuth) load() (string, error) {
+	var token string
+	var err error
+	if len(a.credentialsFile) > 0 {
+		token, err = a.login()
+	} else {
+		token, err = a.readTokenFile()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	a.token = token
+	return token, nil
+}
+
+func (a *dcosAuth) readTokenFile() (string, error) {
+	content, err := ioutil.ReadFile(a.tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the DC/OS token file: %v", err)
+	}
+
+	token := strings.TrimSpace(string(content))
+	if len(token) == 0 {
+		return "", fmt.Errorf("the DC/OS token file %s is empty", a.tokenFile)
+	}
+
+	return token, nil
+}
+
+// login logs in to the DC/OS IAM with the service account, and returns the obtained token.
+func (a *dcosAuth) login() (string, error) {
+	content, err := ioutil.ReadFile(a.credentialsFile)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the DC/OS credentials file: %v", err)
+	}
+
+	var credentials dcosCredentials
+	if err = json.Unmarshal(content, &credentials); err != nil {
+		return "", fmt.Errorf("unable to parse the DC/OS credentials file: %v", err)
+	}
+
+	if len(credentials.Scheme) > 0 && credentials.Scheme != "RS256" {
+		return "", fmt.Errorf("unsupported DC/OS credentials scheme %q", credentials.Scheme)
+	}
+
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(credentials.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("unable to parse the DC/OS credentials private key: %v", err)
+	}
+
+	loginToken, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"uid": credentials.UID,
+		"exp": a.clock.Now().Add(dcosLoginTokenValidity).Unix(),
+	}).SignedString(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("unable to sign the DC/OS login token: %v", err)
+	}
+
+	body, err := json.Marshal(map[string]string{"uid": credentials.UID, "token": loginToken})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := a.client.Post(credentials.LoginEndpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("unable to log in to DC/OS: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to log in to DC/OS: %s", resp.Status)
+	}
+
+	var result struct {
+		Token string `json:"token"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("unable to parse the DC/OS login response: %v", err)
+	}
+
+	if len(result.Token) == 0 {
+		return "", errors.New("no token in the DC/OS login response")
+	}
+
+	return result.Token, nil
+}
+
+// authTransport sets the DC/OS token on the requests,
+// and renews the token before retrying a request once when Marathon rejects it.
+type authTransport struct {
+	auth   *dcosAuth
+	next   http.RoundTripper
+	logger log.Logger
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.auth.get()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(withToken(req, token))
+	if err != nil || (resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusForbidden) {
+		return resp, err
+	}
+
+	// The body of the request cannot be sent again.
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	t.logger.Debugf("Marathon rejected the DC/OS token (%s), renewing it", resp.Status)
+
+	renewed, err := t.auth.renew(token)
+	if err != nil {
+		t.logger.Errorf("Unable to renew the DC/OS token: %v", err)
+		return resp, nil
+	}
+
+	retry := withToken(req, renewed)
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+	}
+
+	_ = resp.Body.Close()
+
+	return t.next.RoundTrip(retry)
+}
+
+// withToken returns a copy of the request, with the given DC/OS token.
+func withToken(req *http.Request, token string) *http.Request {
+	r := new(http.Request)
+	*r = *req
+
+	r.Header = make(http.Header, len(req.Header))
+	for key, values := range req.Header {
+		r.Header[key] = append([]string(nil), values...)
+	}
+	r.Header.Set("Authorization", "token="+token)
+
+	return r
+}
@@ -0,0 +1,195 @@
+package marathon
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/containous/traefik/pkg/log"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuthMaster is a fake Marathon master only accepting a given DC/OS token.
+type fakeAuthMaster struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	token    string
+	requests int
+}
+
+func newFakeAuthMaster(token string) *fakeAuthMaster {
+	master := &fakeAuthMaster{token: token}
+	master.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		master.mu.Lock()
+		defer master.mu.Unlock()
+
+		master.requests++
+		if req.Header.Get("Authorization") != "token="+master.token {
+			http.Error(rw, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(rw, `{"apps": []}`)
+	}))
+	return master
+}
+
+func (m *fakeAuthMaster) setToken(token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.token = token
+}
+
+func (m *fakeAuthMaster) getRequests() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.requests
+}
+
+func newAuthClient(auth *dcosAuth) *http.Client {
+	return &http.Client{
+		Transport: &authTransport{
+			auth:   auth,
+			next:   http.DefaultTransport,
+			logger: log.WithoutContext(),
+		},
+	}
+}
+
+func get(t *testing.T, client *http.Client, url string) int {
+	t.Helper()
+
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+func TestAuthTokenFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traefik-marathon-auth")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	tokenFile := filepath.Join(dir, "token")
+	err = ioutil.WriteFile(tokenFile, []byte("first\n"), 0600)
+	require.NoError(t, err)
+
+	master := newFakeAuthMaster("first")
+	defer master.Close()
+
+	client := newAuthClient(newDCOSAuth(tokenFile, "", http.DefaultTransport, realClock{}))
+
+	assert.Equal(t, http.StatusOK, get(t, client, master.URL+"/v2/apps"))
+
+	// The token expires, and a new one is written to the file.
+	master.setToken("second")
+	err = ioutil.WriteFile(tokenFile, []byte("second\n"), 0600)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, get(t, client, master.URL+"/v2/apps"))
+	assert.Equal(t, 3, master.getRequests())
+
+	// The request is retried only once.
+	master.setToken("third")
+
+	assert.Equal(t, http.StatusUnauthorized, get(t, client, master.URL+"/v2/apps"))
+	assert.Equal(t, 5, master.getRequests())
+}
+
+func TestAuthCredentialsFile(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	master := newFakeAuthMaster("")
+	defer master.Close()
+
+	var mu sync.Mutex
+	logins := 0
+	iam := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var login struct {
+			UID   string `json:"uid"`
+			Token string `json:"token"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&login); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(login.Token, claims, func(*jwt.Token) (interface{}, error) {
+			return &privateKey.PublicKey, nil
+		})
+		if err != nil || login.UID != "traefik" || claims["uid"] != "traefik" {
+			http.Error(rw, "invalid login", http.StatusUnauthorized)
+			return
+		}
+
+		mu.Lock()
+		logins++
+		token := fmt.Sprintf("token-%d", logins)
+		mu.Unlock()
+
+		master.setToken(token)
+		fmt.Fprintf(rw, `{"token": %q}`, token)
+	}))
+	defer iam.Close()
+
+	dir, err := ioutil.TempDir("", "traefik-marathon-auth")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	credentials, err := json.Marshal(dcosCredentials{
+		Scheme: "RS256",
+		UID:    "traefik",
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+		})),
+		LoginEndpoint: iam.URL + "/acs/api/v1/auth/login",
+	})
+	require.NoError(t, err)
+
+	credentialsFile := filepath.Join(dir, "credentials.json")
+	err = ioutil.WriteFile(credentialsFile, credentials, 0600)
+	require.NoError(t, err)
+
+	client := newAuthClient(newDCOSAuth("", credentialsFile, http.DefaultTransport, realClock{}))
+
+	assert.Equal(t, http.StatusOK, get(t, client, master.URL+"/v2/apps"))
+	assert.Equal(t, http.StatusOK, get(t, client, master.URL+"/v2/apps"))
+
+	// The token expires.
+	master.setToken("expired")
+
+	assert.Equal(t, http.StatusOK, get(t, client, master.URL+"/v2/apps"))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, logins)
+}
+
+func TestAuthInvalidConfiguration(t *testing.T) {
+	p := &Provider{
+		DefaultRule:         DefaultTemplateRule,
+		DCOSTokenFile:       "token",
+		DCOSCredentialsFile: "credentials.json",
+	}
+
+	err := p.Init()
+	assert.Error(t, err)
+}
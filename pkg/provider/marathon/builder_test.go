@@ -41,6 +41,18 @@ func appPorts(ports ...int) func(*marathon.Application) {
 	}
 }
 
+func instances(count int) func(*marathon.Application) {
+	return func(app *marathon.Application) {
+		app.Count(count)
+	}
+}
+
+func appVersion(version string) func(*marathon.Application) {
+	return func(app *marathon.Application) {
+		app.Version = version
+	}
+}
+
 func withLabel(key, value string) func(*marathon.Application) {
 	return func(app *marathon.Application) {
 		app.AddLabel(key, value)
@@ -61,6 +73,27 @@ func portDefinition(port int) func(*marathon.Application) {
 	}
 }
 
+func namedPortDefinition(port int, name string) func(*marathon.Application) {
+	return func(app *marathon.Application) {
+		app.AddPortDefinition(marathon.PortDefinition{
+			Port: &port,
+			Name: name,
+		})
+	}
+}
+
+func portMapping(containerPort int, name string) func(*marathon.Application) {
+	return func(app *marathon.Application) {
+		if app.Container == nil {
+			app.Container = marathon.NewDockerContainer()
+		}
+		app.Container.ExposePort(marathon.PortMapping{
+			ContainerPort: containerPort,
+			Name:          name,
+		})
+	}
+}
+
 func bridgeNetwork() func(*marathon.Application) {
 	return func(app *marathon.Application) {
 		app.SetNetwork("bridge", marathon.BridgeNetworkMode)
@@ -73,14 +106,15 @@ func containerNetwork() func(*marathon.Application) {
 	}
 }
 
-func ipAddrPerTask(port int) func(*marathon.Application) {
+func ipAddrPerTask(ports ...int) func(*marathon.Application) {
 	return func(app *marathon.Application) {
-		p := marathon.Port{
-			Number: port,
-			Name:   "port",
-		}
 		disc := marathon.Discovery{}
-		disc.AddPort(p)
+		for _, port := range ports {
+			disc.AddPort(marathon.Port{
+				Number: port,
+				Name:   "port",
+			})
+		}
 		ipAddr := marathon.IPAddressPerTask{}
 		ipAddr.SetDiscovery(disc)
 		app.SetIPAddressPerTask(ipAddr)
@@ -97,6 +131,14 @@ func deployments(ids ...string) func(*marathon.Application) {
 	}
 }
 
+func healthChecks() func(*marathon.Application) {
+	return func(app *marathon.Application) {
+		app.HealthChecks = &[]marathon.HealthCheck{
+			*marathon.NewDefaultHealthCheck(),
+		}
+	}
+}
+
 func readinessCheck(timeout time.Duration) func(*marathon.Application) {
 	return func(app *marathon.Application) {
 		app.ReadinessChecks = &[]marathon.ReadinessCheck{
@@ -174,6 +216,35 @@ func taskState(state TaskState) func(*marathon.Task) {
 	}
 }
 
+func taskRegion(region, zone string) func(*marathon.Task) {
+	return func(t *marathon.Task) {
+		t.Region = region
+		t.Zone = zone
+	}
+}
+
+func taskAttributes(attributes ...string) func(*marathon.Task) {
+	return func(t *marathon.Task) {
+		t.Attributes = append(t.Attributes, attributes...)
+	}
+}
+
+func taskVersion(version string) func(*marathon.Task) {
+	return func(t *marathon.Task) {
+		t.Version = version
+	}
+}
+
+func healthCheckResultLiveness(alive ...bool) func(*marathon.Task) {
+	return func(t *marathon.Task) {
+		for _, a := range alive {
+			t.HealthCheckResults = append(t.HealthCheckResults, &marathon.HealthCheckResult{
+				Alive: a,
+			})
+		}
+	}
+}
+
 func host(h string) func(*marathon.Task) {
 	return func(t *marathon.Task) {
 		t.Host = h
@@ -202,3 +273,83 @@ func startedAtFromNow(offset time.Duration) func(*marathon.Task) {
 		t.StartedAt = time.Now().Add(-offset).Format(time.RFC3339)
 	}
 }
+
+// Functions related to building pods.
+
+func pod(ops ...func(*marathon.PodStatus)) *marathon.PodStatus {
+	p := &marathon.PodStatus{
+		Spec: marathon.NewPod(),
+	}
+
+	for _, op := range ops {
+		op(p)
+	}
+
+	return p
+}
+
+func podID(name string) func(*marathon.PodStatus) {
+	return func(p *marathon.PodStatus) {
+		p.ID = name
+		p.Spec.ID = name
+	}
+}
+
+func withPodLabel(key, value string) func(*marathon.PodStatus) {
+	return func(p *marathon.PodStatus) {
+		p.Spec.Labels[key] = value
+	}
+}
+
+func podContainerNetwork() func(*marathon.PodStatus) {
+	return func(p *marathon.PodStatus) {
+		p.Spec.Networks = append(p.Spec.Networks, marathon.NewContainerPodNetwork("cni"))
+	}
+}
+
+func podContainer(name string, endpoints ...*marathon.PodEndpoint) func(*marathon.PodStatus) {
+	return func(p *marathon.PodStatus) {
+		p.Spec.Containers = append(p.Spec.Containers, &marathon.PodContainer{
+			Name:      name,
+			Endpoints: endpoints,
+		})
+	}
+}
+
+func podEndpoint(name string, containerPort int) *marathon.PodEndpoint {
+	return &marathon.PodEndpoint{
+		Name:          name,
+		ContainerPort: containerPort,
+	}
+}
+
+// podInstance adds an instance to the pod, running on localhost (127.0.0.1),
+// with the given host ports allocated to the endpoints, in order.
+func podInstance(id string, state marathon.PodInstanceState, hostPorts ...int) func(*marathon.PodStatus) {
+	return func(p *marathon.PodStatus) {
+		instance := &marathon.PodInstanceStatus{
+			ID:            id,
+			AgentHostname: "localhost",
+			Status:        state,
+			Networks: []*marathon.PodNetworkStatus{
+				{Addresses: []string{"127.0.0.1"}},
+			},
+		}
+
+		i := 0
+		for _, container := range p.Spec.Containers {
+			status := &marathon.ContainerStatus{}
+			for _, endpoint := range container.Endpoints {
+				allocated := *endpoint
+				if i < len(hostPorts) {
+					allocated.HostPort = hostPorts[i]
+				}
+				status.Endpoints = append(status.Endpoints, &allocated)
+				i++
+			}
+			instance.Containers = append(instance.Containers, status)
+		}
+
+		p.Instances = append(p.Instances, instance)
+	}
+}
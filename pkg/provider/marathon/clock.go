@@ -0,0 +1,21 @@
+package marathon
+
+import (
+	"time"
+)
+
+// clock abstracts the time, so that the time-dependent behaviors of the provider can be driven by the tests.
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
@@ -8,74 +8,306 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/config/label"
 	"github.com/containous/traefik/pkg/log"
 	"github.com/containous/traefik/pkg/provider"
 	"github.com/gambol99/go-marathon"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
+// maxBuildWorkers is the maximum number of applications whose configuration is built concurrently.
+const maxBuildWorkers = 8
+
 func (p *Provider) buildConfiguration(ctx context.Context, applications *marathon.Applications) *config.Configuration {
-	configurations := make(map[string]*config.Configuration)
+	p.skipped = newSkipped()
 
+	taskIDs := make(map[string]struct{})
 	for _, app := range applications.Apps {
-		ctxApp := log.With(ctx, log.Str("applicationID", app.ID))
-		logger := log.FromContext(ctxApp)
+		for _, task := range app.Tasks {
+			if task != nil {
+				taskIDs[task.ID] = struct{}{}
+			}
+		}
+	}
 
-		extraConf, err := p.getConfiguration(app)
-		if err != nil {
-			logger.Errorf("Skip application: %v", err)
-			continue
+	// Each configuration is stored at the index of its application,
+	// so that the result does not depend on the order in which the builds complete.
+	type result struct {
+		configuration *config.Configuration
+		kept          bool
+	}
+	results := make([]result, len(applications.Apps))
+
+	workers := maxBuildWorkers
+	if len(applications.Apps) < workers {
+		workers = len(applications.Apps)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				results[index].configuration, results[index].kept = p.buildApplicationConfiguration(ctx, applications.Apps[index])
+			}
+		}()
+	}
+
+feed:
+	for index := range applications.Apps {
+		select {
+		case indexes <- index:
+		case <-ctx.Done():
+			break feed
 		}
+	}
+	close(indexes)
+	wg.Wait()
 
-		if !p.keepApplication(ctxApp, extraConf) {
-			continue
+	if ctx.Err() != nil {
+		log.FromContext(ctx).Debug("Provider stopped, giving up the build of the configuration")
+		return nil
+	}
+
+	configurations := make(map[string]*config.Configuration)
+	appIDs := make(map[string]struct{})
+	for index, app := range applications.Apps {
+		if results[index].kept {
+			appIDs[app.ID] = struct{}{}
+		}
+		if results[index].configuration != nil {
+			configurations[app.ID] = results[index].configuration
 		}
+	}
+
+	p.lastServers.prune(appIDs)
+	p.deploymentServers.prune(appIDs)
+	p.drainingTasks.prune(taskIDs)
+	p.reportSkipped(ctx)
+
+	return provider.Merge(ctx, configurations)
+}
+
+// buildApplicationConfiguration builds the configuration of the application, if any,
+// and tells whether the application is kept, i.e. whether its last known servers must be kept too.
+// It is called concurrently for several applications.
+func (p *Provider) buildApplicationConfiguration(ctx context.Context, app marathon.Application) (*config.Configuration, bool) {
+	ctxApp := log.With(ctx, log.Str("applicationID", app.ID))
+	logger := log.FromContext(ctxApp)
 
-		confFromLabel, err := label.DecodeConfiguration(stringValueMap(app.Labels))
+	if !p.matchAppIDPrefixes(app.ID) {
+		logger.Debugf("Filtering Marathon application, not under any of the groups %v", p.AppIDPrefixes)
+		return nil, false
+	}
+
+	extraConf, err := p.getConfiguration(app)
+	if err != nil {
+		logger.Errorf("Skip application: %v", err)
+		return nil, false
+	}
+
+	if !p.keepApplication(ctxApp, app, extraConf) {
+		return nil, false
+	}
+
+	confFromLabel, err := label.DecodeConfiguration(stringValueMap(app.Labels))
+	if err != nil {
+		logger.Error(err)
+		return nil, true
+	}
+
+	hasTCP := len(confFromLabel.TCP.Routers) > 0 || len(confFromLabel.TCP.Services) > 0
+	if hasTCP {
+		err := p.buildTCPServiceConfiguration(ctxApp, app, extraConf, confFromLabel.TCP)
 		if err != nil {
 			logger.Error(err)
+			return nil, true
+		}
+		provider.BuildTCPRouterConfiguration(ctxApp, confFromLabel.TCP)
+	}
+
+	hasUDP := confFromLabel.UDP != nil
+	if hasUDP {
+		p.buildUDPServiceConfiguration(ctxApp, app, extraConf, confFromLabel.UDP)
+	}
+
+	if (hasTCP || hasUDP) &&
+		len(confFromLabel.HTTP.Routers) == 0 &&
+		len(confFromLabel.HTTP.Middlewares) == 0 &&
+		len(confFromLabel.HTTP.ServersTransports) == 0 &&
+		len(confFromLabel.HTTP.Services) == 0 {
+		return confFromLabel, true
+	}
+
+	var portNames []string
+	if p.RouterPerPort && len(confFromLabel.HTTP.Routers) == 0 && len(confFromLabel.HTTP.Services) == 0 {
+		portNames = getPortNames(app)
+		addPortServices(app, confFromLabel.HTTP, portNames)
+	}
+
+	err = p.buildServiceConfiguration(ctxApp, app, extraConf, confFromLabel.HTTP)
+	if err != nil {
+		logger.Error(err)
+		return nil, true
+	}
+
+	model := newDefaultRuleModel(app)
+
+	if len(portNames) > 0 {
+		p.buildPortRouters(ctxApp, app, confFromLabel.HTTP, portNames)
+		return confFromLabel, true
+	}
+
+	serviceName := getServiceName(app)
+
+	provider.BuildRouterConfiguration(ctxApp, confFromLabel.HTTP, serviceName, p.defaultRuleTpl, model)
+
+	return confFromLabel, true
+}
+
+// defaultRuleModel holds the data the default rule is rendered with.
+// Region, Zone and Attributes are the ones of the agent of the first running task of the application,
+// and are empty when Marathon does not supply them.
+type defaultRuleModel struct {
+	Name       string
+	Labels     map[string]string
+	Region     string
+	Zone       string
+	Attributes map[string]string
+	PortName   string
+}
+
+func newDefaultRuleModel(app marathon.Application) defaultRuleModel {
+	model := defaultRuleModel{
+		Name:       app.ID,
+		Labels:     stringValueMap(app.Labels),
+		Attributes: map[string]string{},
+	}
+
+	for _, task := range app.Tasks {
+		if task == nil || task.State != string(taskStateRunning) {
 			continue
 		}
 
-		if len(confFromLabel.TCP.Routers) > 0 || len(confFromLabel.TCP.Services) > 0 {
-			err := p.buildTCPServiceConfiguration(ctxApp, app, extraConf, confFromLabel.TCP)
-			if err != nil {
-				logger.Error(err)
-				continue
-			}
-			provider.BuildTCPRouterConfiguration(ctxApp, confFromLabel.TCP)
-			if len(confFromLabel.HTTP.Routers) == 0 &&
-				len(confFromLabel.HTTP.Middlewares) == 0 &&
-				len(confFromLabel.HTTP.Services) == 0 {
-				configurations[app.ID] = confFromLabel
-				continue
+		model.Region = task.Region
+		model.Zone = task.Zone
+
+		// The agent attributes are given as name:value pairs.
+		for _, attribute := range task.Attributes {
+			parts := strings.SplitN(attribute, ":", 2)
+			if len(parts) == 2 {
+				model.Attributes[parts[0]] = parts[1]
+			} else {
+				model.Attributes[parts[0]] = ""
 			}
 		}
 
-		err = p.buildServiceConfiguration(ctxApp, app, extraConf, confFromLabel.HTTP)
-		if err != nil {
-			logger.Error(err)
-			continue
+		break
+	}
+
+	return model
+}
+
+// getPortNames returns the names of the ports of the application, in order, without duplicates.
+func getPortNames(app marathon.Application) []string {
+	var names []string
+
+	if app.PortDefinitions != nil {
+		for _, def := range *app.PortDefinitions {
+			names = appendPortName(names, def.Name)
 		}
+	}
+
+	for _, mapping := range getPortMappings(app) {
+		names = appendPortName(names, mapping.Name)
+	}
 
-		model := struct {
-			Name   string
-			Labels map[string]string
-		}{
-			Name:   app.ID,
-			Labels: stringValueMap(app.Labels),
+	if app.IPAddressPerTask != nil && app.IPAddressPerTask.Discovery != nil && app.IPAddressPerTask.Discovery.Ports != nil {
+		for _, port := range *app.IPAddressPerTask.Discovery.Ports {
+			names = appendPortName(names, port.Name)
 		}
+	}
 
-		serviceName := getServiceName(app)
+	seen := make(map[string]struct{})
+	var unique []string
+	for _, name := range names {
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			unique = append(unique, name)
+		}
+	}
 
-		provider.BuildRouterConfiguration(ctxApp, confFromLabel.HTTP, serviceName, p.defaultRuleTpl, model)
+	return unique
+}
 
-		configurations[app.ID] = confFromLabel
+func getPortServiceName(app marathon.Application, portName string) string {
+	return getServiceName(app) + "-" + portName
+}
+
+// addPortServices adds a service per named port of the application, reaching its tasks on this port.
+func addPortServices(app marathon.Application, conf *config.HTTPConfiguration, portNames []string) {
+	if len(portNames) == 0 {
+		return
 	}
 
-	return provider.Merge(ctx, configurations)
+	conf.Services = make(map[string]*config.Service)
+	for _, portName := range portNames {
+		server := config.Server{}
+		server.SetDefaults()
+		server.Port = "name:" + portName
+
+		lb := &config.LoadBalancerService{}
+		lb.SetDefaults()
+		lb.Servers = []config.Server{server}
+
+		conf.Services[getPortServiceName(app, portName)] = &config.Service{
+			LoadBalancer: lb,
+		}
+	}
+}
+
+// buildPortRouters adds a router per named port of the application, bound to the service of the port,
+// with the default rule rendered with the name of the port.
+func (p *Provider) buildPortRouters(ctx context.Context, app marathon.Application, conf *config.HTTPConfiguration, portNames []string) {
+	conf.Routers = make(map[string]*config.Router)
+
+	for _, portName := range portNames {
+		name := getPortServiceName(app, portName)
+
+		service, ok := conf.Services[name]
+		if !ok {
+			continue
+		}
+
+		model := newDefaultRuleModel(app)
+		model.PortName = portName
+
+		portConf := &config.HTTPConfiguration{
+			Services: map[string]*config.Service{name: service},
+		}
+		provider.BuildRouterConfiguration(ctx, portConf, name, p.defaultRuleTpl, model)
+
+		if router, ok := portConf.Routers[name]; ok {
+			conf.Routers[name] = router
+		}
+	}
+}
+
+// hasServiceLabel tells whether the given load balancer option of the HTTP service is explicitly set by a label of the application.
+func hasServiceLabel(app marathon.Application, serviceName, option string) bool {
+	key := "traefik.http.services." + serviceName + ".loadbalancer." + option
+	for name := range stringValueMap(app.Labels) {
+		if strings.EqualFold(name, key) {
+			return true
+		}
+	}
+	return false
 }
 
 func getServiceName(app marathon.Application) string {
@@ -96,8 +328,17 @@ func (p *Provider) buildServiceConfiguration(ctx context.Context, app marathon.A
 	}
 
 	for serviceName, service := range conf.Services {
+		// The weighted, mirroring, and failover services reference other services, and have no servers of their own.
+		if service.LoadBalancer == nil {
+			continue
+		}
+
 		var servers []config.Server
 
+		if p.DefaultPassHostHeader != nil && !hasServiceLabel(app, serviceName, "passhostheader") {
+			service.LoadBalancer.PassHostHeader = *p.DefaultPassHostHeader
+		}
+
 		defaultServer := config.Server{}
 		defaultServer.SetDefaults()
 
@@ -105,19 +346,48 @@ func (p *Provider) buildServiceConfiguration(ctx context.Context, app marathon.A
 			defaultServer = service.LoadBalancer.Servers[0]
 		}
 
+		if len(p.DefaultServerScheme) > 0 && !hasServiceLabel(app, serviceName, "server.scheme") {
+			defaultServer.Scheme = p.DefaultServerScheme
+		}
+
 		for _, task := range app.Tasks {
-			if p.taskFilter(ctx, *task, app) {
+			if p.taskFilter(ctx, *task, app, extraConf) {
 				server, err := p.getServer(app, *task, extraConf, defaultServer)
 				if err != nil {
 					log.FromContext(appCtx).Errorf("Skip task: %v", err)
+					if _, ok := err.(noPortError); ok {
+						p.skipped.add(skipReasonWithoutPort, task.ID)
+					}
 					continue
 				}
 				servers = append(servers, server)
 			}
 		}
+
+		servers = p.keepServersDuringDeploymentHTTP(appCtx, app, serviceName, servers)
+
 		if len(servers) == 0 {
-			return fmt.Errorf("no server for the service %s", serviceName)
+			lastServers, staleness, ok := p.lastServers.loadHTTP(app.ID, serviceName, p.clock.Now())
+			if !ok {
+				return fmt.Errorf("no server for the service %s", serviceName)
+			}
+
+			log.FromContext(appCtx).Warnf("No server for the service %s, keeping the last known servers (stale since %s)", serviceName, staleness)
+			for i := range lastServers {
+				lastServers[i].Stale = true
+			}
+			servers = lastServers
+		} else {
+			p.lastServers.storeHTTP(app.ID, serviceName, servers, p.clock.Now())
 		}
+
+		// The servers of an application with a zero weight are not added to its services,
+		// so that the application does not receive any request, even in the services it shares with other applications.
+		if extraConf.Marathon.Weight != nil && *extraConf.Marathon.Weight == 0 {
+			log.FromContext(appCtx).Debugf("Zero weight, the servers are not added to the service %s", serviceName)
+			servers = nil
+		}
+
 		service.LoadBalancer.Servers = servers
 	}
 
@@ -146,30 +416,148 @@ func (p *Provider) buildTCPServiceConfiguration(ctx context.Context, app maratho
 		}
 
 		for _, task := range app.Tasks {
-			if p.taskFilter(ctx, *task, app) {
+			if p.taskFilter(ctx, *task, app, extraConf) {
 				server, err := p.getTCPServer(app, *task, extraConf, defaultServer)
 				if err != nil {
 					log.FromContext(appCtx).Errorf("Skip task: %v", err)
+					if _, ok := err.(noPortError); ok {
+						p.skipped.add(skipReasonWithoutPort, task.ID)
+					}
 					continue
 				}
 				servers = append(servers, server)
 			}
 		}
+
+		servers = p.keepServersDuringDeploymentTCP(appCtx, app, serviceName, servers)
+
 		if len(servers) == 0 {
-			return fmt.Errorf("no server for the service %s", serviceName)
+			lastServers, staleness, ok := p.lastServers.loadTCP(app.ID, serviceName, p.clock.Now())
+			if !ok {
+				return fmt.Errorf("no server for the service %s", serviceName)
+			}
+
+			log.FromContext(appCtx).Warnf("No server for the service %s, keeping the last known servers (stale since %s)", serviceName, staleness)
+			servers = lastServers
+		} else {
+			p.lastServers.storeTCP(app.ID, serviceName, servers, p.clock.Now())
 		}
+
 		service.LoadBalancer.Servers = servers
 	}
 
 	return nil
 }
 
-func (p *Provider) keepApplication(ctx context.Context, extraConf configuration) bool {
+// keepServersDuringDeploymentHTTP returns the servers of the service from before the in-flight deployment of the application, if any,
+// instead of the given fresh servers, which are recorded otherwise.
+func (p *Provider) keepServersDuringDeploymentHTTP(ctx context.Context, app marathon.Application, serviceName string, servers []config.Server) []config.Server {
+	if !isDeploying(app) {
+		if len(servers) > 0 {
+			p.deploymentServers.storeHTTP(app.ID, serviceName, servers, p.clock.Now())
+		}
+		return servers
+	}
+
+	previousServers, _, ok := p.deploymentServers.loadHTTP(app.ID, serviceName, p.clock.Now())
+	if !ok {
+		return servers
+	}
+
+	log.FromContext(ctx).Debugf("Deployment in progress, keeping the servers of the service %s from before the deployment", serviceName)
+	return previousServers
+}
+
+// keepServersDuringDeploymentTCP is the TCP counterpart of keepServersDuringDeploymentHTTP.
+func (p *Provider) keepServersDuringDeploymentTCP(ctx context.Context, app marathon.Application, serviceName string, servers []config.TCPServer) []config.TCPServer {
+	if !isDeploying(app) {
+		if len(servers) > 0 {
+			p.deploymentServers.storeTCP(app.ID, serviceName, servers, p.clock.Now())
+		}
+		return servers
+	}
+
+	previousServers, _, ok := p.deploymentServers.loadTCP(app.ID, serviceName, p.clock.Now())
+	if !ok {
+		return servers
+	}
+
+	log.FromContext(ctx).Debugf("Deployment in progress, keeping the servers of the service %s from before the deployment", serviceName)
+	return previousServers
+}
+
+func isDeploying(app marathon.Application) bool {
+	return len(app.Deployments) > 0
+}
+
+// buildUDPServiceConfiguration builds the UDP services, and binds the routers to them.
+// Unlike for HTTP and TCP, a service without any server is dropped along with its routers,
+// without skipping the rest of the application.
+func (p *Provider) buildUDPServiceConfiguration(ctx context.Context, app marathon.Application, extraConf configuration, conf *config.UDPConfiguration) {
+	appName := getServiceName(app)
+	appCtx := log.With(ctx, log.Str("ApplicationID", appName))
+
+	if len(conf.Services) == 0 {
+		conf.Services = make(map[string]*config.UDPService)
+		lb := &config.UDPLoadBalancerService{}
+		conf.Services[appName] = &config.UDPService{
+			LoadBalancer: lb,
+		}
+	}
+
+	provider.BuildUDPRouterConfiguration(ctx, conf)
+
+	for serviceName, service := range conf.Services {
+		var servers []config.UDPServer
+
+		defaultServer := config.UDPServer{}
+
+		if len(service.LoadBalancer.Servers) > 0 {
+			defaultServer = service.LoadBalancer.Servers[0]
+		}
+
+		for _, task := range app.Tasks {
+			if p.taskFilter(ctx, *task, app, extraConf) {
+				server, err := p.getUDPServer(app, *task, extraConf, defaultServer)
+				if err != nil {
+					log.FromContext(appCtx).Errorf("Skip task: %v", err)
+					if _, ok := err.(noPortError); ok {
+						p.skipped.add(skipReasonWithoutPort, task.ID)
+					}
+					continue
+				}
+				servers = append(servers, server)
+			}
+		}
+
+		if len(servers) == 0 {
+			log.FromContext(appCtx).Errorf("No server for the UDP service %s, skipping it", serviceName)
+			delete(conf.Services, serviceName)
+			for routerName, router := range conf.Routers {
+				if router.Service == serviceName {
+					delete(conf.Routers, routerName)
+				}
+			}
+			continue
+		}
+
+		service.LoadBalancer.Servers = servers
+	}
+}
+
+func (p *Provider) keepApplication(ctx context.Context, app marathon.Application, extraConf configuration) bool {
 	logger := log.FromContext(ctx)
 
+	// Filter suspended application, i.e. scaled to zero instances.
+	if app.Instances != nil && *app.Instances == 0 {
+		logger.Debug("Filtering suspended Marathon application")
+		return false
+	}
+
 	// Filter disabled application.
 	if !extraConf.Enable {
 		logger.Debug("Filtering disabled Marathon application")
+		p.skipped.add(skipReasonDisabled, app.ID)
 		return false
 	}
 
@@ -178,17 +566,77 @@ func (p *Provider) keepApplication(ctx context.Context, extraConf configuration)
 		if failingConstraint != nil {
 			logger.Debugf("Filtering Marathon application, pruned by %q constraint", failingConstraint.String())
 		}
+		p.skipped.add(skipReasonConstraints, app.ID)
 		return false
 	}
 
+	// Filter by label selector.
+	if p.labelSelector != nil && !p.labelSelector.Matches(labels.Set(stringValueMap(app.Labels))) {
+		logger.Debugf("Filtering Marathon application, not matching the label selector %q", p.LabelSelector)
+		p.skipped.add(skipReasonConstraints, app.ID)
+		return false
+	}
+
+	return true
+}
+
+// matchAppIDPrefixes checks whether the application ID is under one of the allowed groups, if any.
+func (p *Provider) matchAppIDPrefixes(appID string) bool {
+	if len(p.AppIDPrefixes) == 0 {
+		return true
+	}
+
+	for _, prefix := range p.AppIDPrefixes {
+		group := "/" + strings.Trim(prefix, "/")
+		if group == "/" || appID == group || strings.HasPrefix(appID, group+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Provider) taskFilter(ctx context.Context, task marathon.Task, application marathon.Application, extraConf configuration) bool {
+	if !p.keepTask(ctx, task, application, extraConf) {
+		p.skipped.add(skipReasonFilteredTask, task.ID)
+		return false
+	}
 	return true
 }
 
-func (p *Provider) taskFilter(ctx context.Context, task marathon.Task, application marathon.Application) bool {
+func (p *Provider) keepTask(ctx context.Context, task marathon.Task, application marathon.Application, extraConf configuration) bool {
+	// Keep the killed task while it drains its connections, whatever its health.
+	if task.State == string(taskStateKilling) && extraConf.Marathon.DrainSeconds > 0 {
+		drainPeriod := time.Duration(extraConf.Marathon.DrainSeconds) * time.Second
+		if p.drainingTasks.draining(task.ID, drainPeriod, p.clock.Now()) {
+			log.FromContext(ctx).Debugf("Keeping killed task %s from application %s while it drains its connections", task.ID, application.ID)
+			return true
+		}
+		return false
+	}
+
 	if task.State != string(taskStateRunning) {
 		return false
 	}
 
+	// Filter task with existing, bad health check results.
+	if application.HasHealthChecks() && task.HasHealthCheckResults() {
+		for _, healthCheck := range task.HealthCheckResults {
+			// When a task is flapping in Marathon, the result is sometimes nil.
+			if healthCheck == nil || !healthCheck.Alive {
+				log.FromContext(ctx).Debugf("Filtering task %s from application %s with bad health check", task.ID, application.ID)
+				return false
+			}
+		}
+	}
+
+	// Filter the tasks left over from a previous version, e.g. after a failed deployment,
+	// unless none of the running tasks is of the latest version.
+	if p.OnlyTasksOfLatestVersion && task.Version != application.Version && hasRunningTaskOfVersion(application, application.Version) {
+		log.FromContext(ctx).Debugf("Filtering task %s from application %s of version %s, not of the latest version %s", task.ID, application.ID, task.Version, application.Version)
+		return false
+	}
+
 	if ready := p.readyChecker.Do(task, application); !ready {
 		log.FromContext(ctx).Infof("Filtering unready task %s from application %s", task.ID, application.ID)
 		return false
@@ -197,42 +645,75 @@ func (p *Provider) taskFilter(ctx context.Context, task marathon.Task, applicati
 	return true
 }
 
-func (p *Provider) getTCPServer(app marathon.Application, task marathon.Task, extraConf configuration, defaultServer config.TCPServer) (config.TCPServer, error) {
-	host, err := p.getServerHost(task, app, extraConf)
-	if len(host) == 0 {
-		return config.TCPServer{}, err
+func hasRunningTaskOfVersion(app marathon.Application, version string) bool {
+	for _, task := range app.Tasks {
+		if task != nil && task.State == string(taskStateRunning) && task.Version == version {
+			return true
+		}
 	}
+	return false
+}
 
-	port, err := getPort(task, app, defaultServer.Port)
-	if err != nil {
+func (p *Provider) getTCPServer(app marathon.Application, task marathon.Task, extraConf configuration, defaultServer config.TCPServer) (config.TCPServer, error) {
+	address, err := p.getServerAddress(app, task, extraConf, defaultServer.Port)
+	if len(address) == 0 {
 		return config.TCPServer{}, err
 	}
 
 	server := config.TCPServer{
-		Address: net.JoinHostPort(host, port),
+		Address: address,
 	}
 
 	return server, nil
 }
 
-func (p *Provider) getServer(app marathon.Application, task marathon.Task, extraConf configuration, defaultServer config.Server) (config.Server, error) {
-	host, err := p.getServerHost(task, app, extraConf)
-	if len(host) == 0 {
-		return config.Server{}, err
+func (p *Provider) getUDPServer(app marathon.Application, task marathon.Task, extraConf configuration, defaultServer config.UDPServer) (config.UDPServer, error) {
+	address, err := p.getServerAddress(app, task, extraConf, defaultServer.Port)
+	if len(address) == 0 {
+		return config.UDPServer{}, err
 	}
 
-	port, err := getPort(task, app, defaultServer.Port)
-	if err != nil {
+	server := config.UDPServer{
+		Address: address,
+	}
+
+	return server, nil
+}
+
+func (p *Provider) getServer(app marathon.Application, task marathon.Task, extraConf configuration, defaultServer config.Server) (config.Server, error) {
+	address, err := p.getServerAddress(app, task, extraConf, defaultServer.Port)
+	if len(address) == 0 {
 		return config.Server{}, err
 	}
 
 	server := config.Server{
-		URL: fmt.Sprintf("%s://%s", defaultServer.Scheme, net.JoinHostPort(host, port)),
+		URL: fmt.Sprintf("%s://%s", defaultServer.Scheme, address),
+	}
+
+	// All the servers of the application get its weight, in the services it shares with other applications too.
+	if extraConf.Marathon.Weight != nil {
+		server.Weight = *extraConf.Marathon.Weight
 	}
 
 	return server, nil
 }
 
+// getServerAddress resolves the host and the port of the server of the task, for the HTTP, TCP, and UDP services alike.
+// The port of the service (e.g. 8080, index:1, or name:admin) is resolved against the ports of the task and of the application.
+func (p *Provider) getServerAddress(app marathon.Application, task marathon.Task, extraConf configuration, servicePort string) (string, error) {
+	host, err := p.getServerHost(task, app, extraConf)
+	if len(host) == 0 {
+		return "", err
+	}
+
+	port, err := getPort(task, app, servicePort)
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
 func (p *Provider) getServerHost(task marathon.Task, app marathon.Application, extraConf configuration) (string, error) {
 	networks := app.Networks
 	var hostFlag bool
@@ -244,6 +725,9 @@ func (p *Provider) getServerHost(task marathon.Task, app marathon.Application, e
 	}
 
 	if hostFlag || p.ForceTaskHostname {
+		if len(p.DialerDNSSuffix) > 0 {
+			return getMesosDNSName(app.ID, p.DialerDNSSuffix), nil
+		}
 		if len(task.Host) == 0 {
 			return "", fmt.Errorf("host is undefined for task %q app %q", task.ID, app.ID)
 		}
@@ -270,10 +754,25 @@ func (p *Provider) getServerHost(task marathon.Task, app marathon.Application, e
 	}
 }
 
+// getMesosDNSName returns the Mesos-DNS name of the tasks of an application:
+// the groups of the application ID are reversed and joined with hyphens, e.g. /group/app gives app-group.<suffix>.
+func getMesosDNSName(appID, suffix string) string {
+	parts := strings.Split(strings.Trim(appID, "/"), "/")
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, "-") + "." + strings.Trim(suffix, ".")
+}
+
+// noPortError is returned when no port can be selected for a task.
+type noPortError struct {
+	error
+}
+
 func getPort(task marathon.Task, app marathon.Application, serverPort string) (string, error) {
 	port, err := processPorts(app, task, serverPort)
 	if err != nil {
-		return "", fmt.Errorf("unable to process ports for %s %s: %v", app.ID, task.ID, err)
+		return "", noPortError{fmt.Errorf("unable to process ports for %s %s: %v", app.ID, task.ID, err)}
 	}
 
 	return strconv.Itoa(port), nil
@@ -282,8 +781,12 @@ func getPort(task marathon.Task, app marathon.Application, serverPort string) (s
 // processPorts returns the configured port.
 // An explicitly specified port is preferred. If none is specified, it selects
 // one of the available port. The first such found port is returned unless an
-// optional index is provided.
+// optional index, or an optional name, is provided.
 func processPorts(app marathon.Application, task marathon.Task, serverPort string) (int, error) {
+	if strings.HasPrefix(serverPort, "name:") {
+		return processNamedPort(app, task, strings.TrimPrefix(serverPort, "name:"))
+	}
+
 	if len(serverPort) > 0 && !strings.HasPrefix(serverPort, "index:") {
 		port, err := strconv.Atoi(serverPort)
 		if err != nil {
@@ -318,6 +821,76 @@ func processPorts(app marathon.Application, task marathon.Task, serverPort strin
 	return ports[portIndex], nil
 }
 
+// processNamedPort returns the port with the given name,
+// looked up across the port definitions, the container port mappings, and the IP-per-task discovery ports.
+func processNamedPort(app marathon.Application, task marathon.Task, name string) (int, error) {
+	var names []string
+
+	if app.PortDefinitions != nil {
+		for i, def := range *app.PortDefinitions {
+			if def.Name == name {
+				if i < len(task.Ports) {
+					return task.Ports[i], nil
+				}
+				if def.Port != nil {
+					return *def.Port, nil
+				}
+			}
+			names = appendPortName(names, def.Name)
+		}
+	}
+
+	for i, mapping := range getPortMappings(app) {
+		if mapping.Name == name {
+			// On a container network, the task is reached on its own IP address, so on the container port.
+			if !isContainerNetwork(app) && i < len(task.Ports) {
+				return task.Ports[i], nil
+			}
+			return mapping.ContainerPort, nil
+		}
+		names = appendPortName(names, mapping.Name)
+	}
+
+	if app.IPAddressPerTask != nil && app.IPAddressPerTask.Discovery != nil && app.IPAddressPerTask.Discovery.Ports != nil {
+		for _, port := range *app.IPAddressPerTask.Discovery.Ports {
+			if port.Name == name {
+				return port.Number, nil
+			}
+			names = appendPortName(names, port.Name)
+		}
+	}
+
+	return 0, fmt.Errorf("name %q must be within names (%s)", name, strings.Join(names, ", "))
+}
+
+func appendPortName(names []string, name string) []string {
+	if len(name) == 0 {
+		return names
+	}
+	return append(names, name)
+}
+
+func getPortMappings(app marathon.Application) []marathon.PortMapping {
+	if app.Container == nil {
+		return nil
+	}
+
+	if app.Container.PortMappings != nil && len(*app.Container.PortMappings) > 0 {
+		return *app.Container.PortMappings
+	}
+
+	// Before Marathon 1.5, the port mappings were defined on the Docker container.
+	if app.Container.Docker != nil && app.Container.Docker.PortMappings != nil {
+		return *app.Container.Docker.PortMappings
+	}
+
+	return nil
+}
+
+func isContainerNetwork(app marathon.Application) bool {
+	return app.Networks != nil && len(*app.Networks) > 0 && (*app.Networks)[0].Mode == marathon.ContainerNetworkMode
+}
+
 func retrieveAvailablePorts(app marathon.Application, task marathon.Task) []int {
 	// Using default port configuration
 	if len(task.Ports) > 0 {
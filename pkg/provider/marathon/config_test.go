@@ -2,8 +2,10 @@ package marathon
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/types"
@@ -33,7 +35,13 @@ func TestBuildConfiguration(t *testing.T) {
 		applications              *marathon.Applications
 		constraints               []*types.Constraint
 		filterMarathonConstraints bool
+		respectReadinessChecks    bool
+		onlyTasksOfLatestVersion  bool
+		appIDPrefixes             []string
+		labelSelector             string
 		defaultRule               string
+		defaultServerScheme       string
+		defaultPassHostHeader     *bool
 		expected                  *config.Configuration
 	}{
 		{
@@ -91,12 +99,55 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "multiple ports",
+			desc: "suspended application",
 			applications: withApplications(
 				application(
 					appID("/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
+					appPorts(80),
+					instances(0),
+					withLabel("traefik.http.routers.app.rule", "Host(`app.localhost`)"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
+			},
+		},
+		{
+			desc: "task with a failing health check",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80),
+					healthChecks(),
+					withTasks(localhostTask(taskPorts(80), healthCheckResultLiveness(true, false))),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
+			},
+		},
+		{
+			desc: "task with a passing health check",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80),
+					healthChecks(),
+					withTasks(localhostTask(taskPorts(80), healthCheckResultLiveness(true))),
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
@@ -125,14 +176,15 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "with basic auth",
+			desc: "task with a nil health check result",
 			applications: withApplications(
 				application(
 					appID("/app"),
 					appPorts(80),
-					withLabel("traefik.http.middlewares.Middleware1.basicauth.users", "test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/,test2:$apr1$d9hr9HBB$4HxwgUir3HP4EsggP/QNo0"),
-					withLabel("traefik.http.routers.app.middlewares", "Middleware1"),
-					withTasks(localhostTask(taskPorts(80))),
+					healthChecks(),
+					withTasks(localhostTask(taskPorts(80), func(t *marathon.Task) {
+						t.HealthCheckResults = []*marathon.HealthCheckResult{nil}
+					})),
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
@@ -140,103 +192,47 @@ func TestBuildConfiguration(t *testing.T) {
 					Services: map[string]*config.TCPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
-					Routers: map[string]*config.Router{
-						"app": {
-							Service:     "app",
-							Rule:        "Host(`app.marathon.localhost`)",
-							Middlewares: []string{"Middleware1"},
-						},
-					},
-					Middlewares: map[string]*config.Middleware{
-						"Middleware1": {
-							BasicAuth: &config.BasicAuth{
-								Users: []string{
-									"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/",
-									"test2:$apr1$d9hr9HBB$4HxwgUir3HP4EsggP/QNo0",
-								},
-							},
-						},
-					},
-					Services: map[string]*config.Service{
-						"app": {LoadBalancer: &config.LoadBalancerService{
-							Servers: []config.Server{
-								{
-									URL: "http://localhost:80",
-								},
-							},
-							PassHostHeader: true,
-						}},
-					},
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
 				},
 			},
 		},
 		{
-			desc: "2 applications in the same service",
+			desc: "unready task during a deployment",
 			applications: withApplications(
 				application(
-					appID("/foo-v000"),
-					withTasks(localhostTask(taskPorts(8080))),
-
-					withLabel("traefik.http.services.Service1.LoadBalancer.server.port", "index:0"),
-					withLabel("traefik.http.routers.Router1.rule", "Host(`app.marathon.localhost`)"),
-				),
-				application(
-					appID("/foo-v001"),
-					withTasks(localhostTask(taskPorts(8081))),
-
-					withLabel("traefik.http.services.Service1.LoadBalancer.server.port", "index:0"),
-					withLabel("traefik.http.routers.Router1.rule", "Host(`app.marathon.localhost`)"),
-				),
-			),
+					appID("/app"),
+					appPorts(80),
+					deployments("deployment-1"),
+					readinessCheck(time.Minute),
+					readinessCheckResult(testTaskName, false),
+					withTasks(localhostTask(taskPorts(80))),
+				)),
+			respectReadinessChecks: true,
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
 					Routers:  map[string]*config.TCPRouter{},
 					Services: map[string]*config.TCPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
-					Routers: map[string]*config.Router{
-						"Router1": {
-							Service: "Service1",
-							Rule:    "Host(`app.marathon.localhost`)",
-						},
-					},
+					Routers:     map[string]*config.Router{},
 					Middlewares: map[string]*config.Middleware{},
-					Services: map[string]*config.Service{
-						"Service1": {LoadBalancer: &config.LoadBalancerService{
-							Servers: []config.Server{
-								{
-									URL: "http://localhost:8080",
-								},
-								{
-									URL: "http://localhost:8081",
-								},
-							},
-							PassHostHeader: true,
-						}},
-					},
+					Services:    map[string]*config.Service{},
 				},
 			},
 		},
 		{
-			desc: "2 applications with 2 tasks in the same service",
+			desc: "unready task once the deployment is completed",
 			applications: withApplications(
 				application(
-					appID("/foo-v000"),
-					withTasks(localhostTask(taskPorts(8080))),
-					withTasks(localhostTask(taskPorts(8081))),
-
-					withLabel("traefik.http.services.Service1.LoadBalancer.server.port", "index:0"),
-					withLabel("traefik.http.routers.Router1.rule", "Host(`app.marathon.localhost`)"),
-				),
-				application(
-					appID("/foo-v001"),
-					withTasks(localhostTask(taskPorts(8082))),
-					withTasks(localhostTask(taskPorts(8083))),
-
-					withLabel("traefik.http.services.Service1.LoadBalancer.server.port", "index:0"),
-					withLabel("traefik.http.routers.Router1.rule", "Host(`app.marathon.localhost`)"),
-				),
-			),
+					appID("/app"),
+					appPorts(80),
+					readinessCheck(time.Minute),
+					readinessCheckResult(testTaskName, false),
+					withTasks(localhostTask(taskPorts(80))),
+				)),
+			respectReadinessChecks: true,
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
 					Routers:  map[string]*config.TCPRouter{},
@@ -244,26 +240,17 @@ func TestBuildConfiguration(t *testing.T) {
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
-						"Router1": {
-							Service: "Service1",
+						"app": {
+							Service: "app",
 							Rule:    "Host(`app.marathon.localhost`)",
 						},
 					},
 					Middlewares: map[string]*config.Middleware{},
 					Services: map[string]*config.Service{
-						"Service1": {LoadBalancer: &config.LoadBalancerService{
+						"app": {LoadBalancer: &config.LoadBalancerService{
 							Servers: []config.Server{
 								{
-									URL: "http://localhost:8080",
-								},
-								{
-									URL: "http://localhost:8081",
-								},
-								{
-									URL: "http://localhost:8082",
-								},
-								{
-									URL: "http://localhost:8083",
+									URL: "http://localhost:80",
 								},
 							},
 							PassHostHeader: true,
@@ -273,17 +260,17 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "2 applications",
+			desc: "tasks of different versions",
 			applications: withApplications(
 				application(
-					appID("/foo"),
-					withTasks(localhostTask(taskPorts(8080))),
-				),
-				application(
-					appID("/bar"),
-					withTasks(localhostTask(taskPorts(8081))),
-				),
-			),
+					appID("/app"),
+					appPorts(80),
+					appVersion("2019-06-01T10:00:00.000Z"),
+					withTasks(
+						localhostTask(withTaskID("old"), taskPorts(80), taskVersion("2019-05-01T10:00:00.000Z")),
+						localhostTask(withTaskID("new"), taskPorts(81), taskVersion("2019-06-01T10:00:00.000Z")),
+					),
+				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
 					Routers:  map[string]*config.TCPRouter{},
@@ -291,29 +278,20 @@ func TestBuildConfiguration(t *testing.T) {
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
-						"foo": {
-							Service: "foo",
-							Rule:    "Host(`foo.marathon.localhost`)",
-						},
-						"bar": {
-							Service: "bar",
-							Rule:    "Host(`bar.marathon.localhost`)",
+						"app": {
+							Service: "app",
+							Rule:    "Host(`app.marathon.localhost`)",
 						},
 					},
 					Middlewares: map[string]*config.Middleware{},
 					Services: map[string]*config.Service{
-						"foo": {LoadBalancer: &config.LoadBalancerService{
+						"app": {LoadBalancer: &config.LoadBalancerService{
 							Servers: []config.Server{
 								{
-									URL: "http://localhost:8080",
+									URL: "http://localhost:80",
 								},
-							},
-							PassHostHeader: true,
-						}},
-						"bar": {LoadBalancer: &config.LoadBalancerService{
-							Servers: []config.Server{
 								{
-									URL: "http://localhost:8081",
+									URL: "http://localhost:81",
 								},
 							},
 							PassHostHeader: true,
@@ -323,13 +301,18 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "two tasks no labels",
+			desc: "tasks of different versions with only the tasks of the latest version",
 			applications: withApplications(
 				application(
 					appID("/app"),
 					appPorts(80),
-					withTasks(localhostTask(taskPorts(80)), localhostTask(taskPorts(81))),
+					appVersion("2019-06-01T10:00:00.000Z"),
+					withTasks(
+						localhostTask(withTaskID("old"), taskPorts(80), taskVersion("2019-05-01T10:00:00.000Z")),
+						localhostTask(withTaskID("new"), taskPorts(81), taskVersion("2019-06-01T10:00:00.000Z")),
+					),
 				)),
+			onlyTasksOfLatestVersion: true,
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
 					Routers:  map[string]*config.TCPRouter{},
@@ -344,32 +327,31 @@ func TestBuildConfiguration(t *testing.T) {
 					},
 					Middlewares: map[string]*config.Middleware{},
 					Services: map[string]*config.Service{
-						"app": {
-							LoadBalancer: &config.LoadBalancerService{
-								Servers: []config.Server{
-									{
-										URL: "http://localhost:80",
-									},
-									{
-										URL: "http://localhost:81",
-									},
+						"app": {LoadBalancer: &config.LoadBalancerService{
+							Servers: []config.Server{
+								{
+									URL: "http://localhost:81",
 								},
-								PassHostHeader: true,
 							},
-						},
+							PassHostHeader: true,
+						}},
 					},
 				},
 			},
 		},
 		{
-			desc: "simple application with label on service",
+			desc: "no running task of the latest version with only the tasks of the latest version",
 			applications: withApplications(
 				application(
 					appID("/app"),
 					appPorts(80),
-					withTasks(localhostTask(taskPorts(80))),
-					withLabel("traefik.http.services.Service1.loadbalancer.passhostheader", "true"),
+					appVersion("2019-06-01T10:00:00.000Z"),
+					withTasks(
+						localhostTask(withTaskID("old"), taskPorts(80), taskVersion("2019-05-01T10:00:00.000Z")),
+						localhostTask(withTaskID("new"), taskPorts(81), taskVersion("2019-06-01T10:00:00.000Z"), taskState(taskStateStaging)),
+					),
 				)),
+			onlyTasksOfLatestVersion: true,
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
 					Routers:  map[string]*config.TCPRouter{},
@@ -378,13 +360,13 @@ func TestBuildConfiguration(t *testing.T) {
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
 						"app": {
-							Service: "Service1",
+							Service: "app",
 							Rule:    "Host(`app.marathon.localhost`)",
 						},
 					},
 					Middlewares: map[string]*config.Middleware{},
 					Services: map[string]*config.Service{
-						"Service1": {LoadBalancer: &config.LoadBalancerService{
+						"app": {LoadBalancer: &config.LoadBalancerService{
 							Servers: []config.Server{
 								{
 									URL: "http://localhost:80",
@@ -397,15 +379,12 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "one app with labels",
+			desc: "multiple ports",
 			applications: withApplications(
 				application(
 					appID("/app"),
 					appPorts(80, 81),
 					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.services.Service1.loadbalancer.passhostheader", "true"),
-					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
-					withLabel("traefik.http.routers.Router1.service", "Service1"),
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
@@ -414,35 +393,34 @@ func TestBuildConfiguration(t *testing.T) {
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
-						"Router1": {
-							Service: "Service1",
-							Rule:    "Host(`foo.com`)",
+						"app": {
+							Service: "app",
+							Rule:    "Host(`app.marathon.localhost`)",
 						},
 					},
 					Middlewares: map[string]*config.Middleware{},
 					Services: map[string]*config.Service{
-						"Service1": {
-							LoadBalancer: &config.LoadBalancerService{
-								Servers: []config.Server{
-									{
-										URL: "http://localhost:80",
-									},
+						"app": {LoadBalancer: &config.LoadBalancerService{
+							Servers: []config.Server{
+								{
+									URL: "http://localhost:80",
 								},
-								PassHostHeader: true,
 							},
-						},
+							PassHostHeader: true,
+						}},
 					},
 				},
 			},
 		},
 		{
-			desc: "one app with rule label",
+			desc: "with basic auth",
 			applications: withApplications(
 				application(
 					appID("/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
+					appPorts(80),
+					withLabel("traefik.http.middlewares.Middleware1.basicauth.users", "test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/,test2:$apr1$d9hr9HBB$4HxwgUir3HP4EsggP/QNo0"),
+					withLabel("traefik.http.routers.app.middlewares", "Middleware1"),
+					withTasks(localhostTask(taskPorts(80))),
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
@@ -450,38 +428,1125 @@ func TestBuildConfiguration(t *testing.T) {
 					Services: map[string]*config.TCPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
-					Middlewares: map[string]*config.Middleware{},
-					Services: map[string]*config.Service{
-						"app": {
-							LoadBalancer: &config.LoadBalancerService{
-								Servers: []config.Server{
-									{
-										URL: "http://localhost:80",
-									},
-								},
-								PassHostHeader: true,
-							},
-						},
-					},
+					Routers: map[string]*config.Router{
+						"app": {
+							Service:     "app",
+							Rule:        "Host(`app.marathon.localhost`)",
+							Middlewares: []string{"Middleware1"},
+						},
+					},
+					Middlewares: map[string]*config.Middleware{
+						"Middleware1": {
+							BasicAuth: &config.BasicAuth{
+								Users: []string{
+									"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/",
+									"test2:$apr1$d9hr9HBB$4HxwgUir3HP4EsggP/QNo0",
+								},
+							},
+						},
+					},
+					Services: map[string]*config.Service{
+						"app": {LoadBalancer: &config.LoadBalancerService{
+							Servers: []config.Server{
+								{
+									URL: "http://localhost:80",
+								},
+							},
+							PassHostHeader: true,
+						}},
+					},
+				},
+			},
+		},
+		{
+			desc: "2 applications in the same service",
+			applications: withApplications(
+				application(
+					appID("/foo-v000"),
+					withTasks(localhostTask(taskPorts(8080))),
+
+					withLabel("traefik.http.services.Service1.LoadBalancer.server.port", "index:0"),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`app.marathon.localhost`)"),
+				),
+				application(
+					appID("/foo-v001"),
+					withTasks(localhostTask(taskPorts(8081))),
+
+					withLabel("traefik.http.services.Service1.LoadBalancer.server.port", "index:0"),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`app.marathon.localhost`)"),
+				),
+			),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"Router1": {
+							Service: "Service1",
+							Rule:    "Host(`app.marathon.localhost`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Service1": {LoadBalancer: &config.LoadBalancerService{
+							Servers: []config.Server{
+								{
+									URL: "http://localhost:8080",
+								},
+								{
+									URL: "http://localhost:8081",
+								},
+							},
+							PassHostHeader: true,
+						}},
+					},
+				},
+			},
+		},
+		{
+			desc: "2 applications with weights in the same service",
+			applications: withApplications(
+				application(
+					appID("/foo-v000"),
+					withTasks(localhostTask(taskPorts(8080))),
+					withTasks(localhostTask(taskPorts(8081))),
+
+					withLabel("traefik.marathon.weight", "90"),
+					withLabel("traefik.http.services.Service1.LoadBalancer.server.port", "index:0"),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`app.marathon.localhost`)"),
+				),
+				application(
+					appID("/foo-v001"),
+					withTasks(localhostTask(taskPorts(8082))),
+
+					withLabel("traefik.marathon.weight", "10"),
+					withLabel("traefik.http.services.Service1.LoadBalancer.server.port", "index:0"),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`app.marathon.localhost`)"),
+				),
+			),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"Router1": {
+							Service: "Service1",
+							Rule:    "Host(`app.marathon.localhost`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Service1": {LoadBalancer: &config.LoadBalancerService{
+							Servers: []config.Server{
+								{
+									URL:    "http://localhost:8080",
+									Weight: 90,
+								},
+								{
+									URL:    "http://localhost:8081",
+									Weight: 90,
+								},
+								{
+									URL:    "http://localhost:8082",
+									Weight: 10,
+								},
+							},
+							PassHostHeader: true,
+						}},
+					},
+				},
+			},
+		},
+		{
+			desc: "2 applications in the same service, one of them with a zero weight",
+			applications: withApplications(
+				application(
+					appID("/foo-v000"),
+					withTasks(localhostTask(taskPorts(8080))),
+
+					withLabel("traefik.http.services.Service1.LoadBalancer.server.port", "index:0"),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`app.marathon.localhost`)"),
+				),
+				application(
+					appID("/foo-v001"),
+					withTasks(localhostTask(taskPorts(8081))),
+
+					withLabel("traefik.marathon.weight", "0"),
+					withLabel("traefik.http.services.Service1.LoadBalancer.server.port", "index:0"),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`app.marathon.localhost`)"),
+				),
+			),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
 						"Router1": {
+							Service: "Service1",
+							Rule:    "Host(`app.marathon.localhost`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Service1": {LoadBalancer: &config.LoadBalancerService{
+							Servers: []config.Server{
+								{
+									URL: "http://localhost:8080",
+								},
+							},
+							PassHostHeader: true,
+						}},
+					},
+				},
+			},
+		},
+		{
+			desc: "one application with a zero weight, alone in its service",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80),
+					withTasks(localhostTask(taskPorts(80))),
+
+					withLabel("traefik.marathon.weight", "0"),
+				),
+			),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"app": {
 							Service: "app",
-							Rule:    "Host(`foo.com`)",
+							Rule:    "Host(`app.marathon.localhost`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
+			},
+		},
+		{
+			desc: "2 applications with 2 tasks in the same service",
+			applications: withApplications(
+				application(
+					appID("/foo-v000"),
+					withTasks(localhostTask(taskPorts(8080))),
+					withTasks(localhostTask(taskPorts(8081))),
+
+					withLabel("traefik.http.services.Service1.LoadBalancer.server.port", "index:0"),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`app.marathon.localhost`)"),
+				),
+				application(
+					appID("/foo-v001"),
+					withTasks(localhostTask(taskPorts(8082))),
+					withTasks(localhostTask(taskPorts(8083))),
+
+					withLabel("traefik.http.services.Service1.LoadBalancer.server.port", "index:0"),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`app.marathon.localhost`)"),
+				),
+			),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"Router1": {
+							Service: "Service1",
+							Rule:    "Host(`app.marathon.localhost`)",
 						},
 					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Service1": {LoadBalancer: &config.LoadBalancerService{
+							Servers: []config.Server{
+								{
+									URL: "http://localhost:8080",
+								},
+								{
+									URL: "http://localhost:8081",
+								},
+								{
+									URL: "http://localhost:8082",
+								},
+								{
+									URL: "http://localhost:8083",
+								},
+							},
+							PassHostHeader: true,
+						}},
+					},
+				},
+			},
+		},
+		{
+			desc: "2 applications",
+			applications: withApplications(
+				application(
+					appID("/foo"),
+					withTasks(localhostTask(taskPorts(8080))),
+				),
+				application(
+					appID("/bar"),
+					withTasks(localhostTask(taskPorts(8081))),
+				),
+			),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"foo": {
+							Service: "foo",
+							Rule:    "Host(`foo.marathon.localhost`)",
+						},
+						"bar": {
+							Service: "bar",
+							Rule:    "Host(`bar.marathon.localhost`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"foo": {LoadBalancer: &config.LoadBalancerService{
+							Servers: []config.Server{
+								{
+									URL: "http://localhost:8080",
+								},
+							},
+							PassHostHeader: true,
+						}},
+						"bar": {LoadBalancer: &config.LoadBalancerService{
+							Servers: []config.Server{
+								{
+									URL: "http://localhost:8081",
+								},
+							},
+							PassHostHeader: true,
+						}},
+					},
+				},
+			},
+		},
+		{
+			desc: "two tasks no labels",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80),
+					withTasks(localhostTask(taskPorts(80)), localhostTask(taskPorts(81))),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"app": {
+							Service: "app",
+							Rule:    "Host(`app.marathon.localhost`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"app": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+									{
+										URL: "http://localhost:81",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "simple application with label on service",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80),
+					withTasks(localhostTask(taskPorts(80))),
+					withLabel("traefik.http.services.Service1.loadbalancer.passhostheader", "true"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"app": {
+							Service: "Service1",
+							Rule:    "Host(`app.marathon.localhost`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Service1": {LoadBalancer: &config.LoadBalancerService{
+							Servers: []config.Server{
+								{
+									URL: "http://localhost:80",
+								},
+							},
+							PassHostHeader: true,
+						}},
+					},
+				},
+			},
+		},
+		{
+			desc: "one app with labels",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.services.Service1.loadbalancer.passhostheader", "true"),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
+					withLabel("traefik.http.routers.Router1.service", "Service1"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"Router1": {
+							Service: "Service1",
+							Rule:    "Host(`foo.com`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Service1": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "one app with rule label",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"app": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+					Routers: map[string]*config.Router{
+						"Router1": {
+							Service: "app",
+							Rule:    "Host(`foo.com`)",
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "one app with rule label and one service",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
+					withLabel("traefik.http.services.Service1.loadbalancer.passhostheader", "true"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"Router1": {
+							Service: "Service1",
+							Rule:    "Host(`foo.com`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Service1": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "one app with rule label and two services",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
+					withLabel("traefik.http.services.Service1.loadbalancer.passhostheader", "true"),
+					withLabel("traefik.http.services.Service2.loadbalancer.passhostheader", "true"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Service1": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+						"Service2": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "two apps with same service name and different passhostheader",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.services.Service1.loadbalancer.passhostheader", "false"),
+				),
+				application(
+					appID("/app2"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.services.Service1.loadbalancer.passhostheader", "true"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"app": {
+							Service: "Service1",
+							Rule:    "Host(`app.marathon.localhost`)",
+						},
+						"app2": {
+							Service: "Service1",
+							Rule:    "Host(`app2.marathon.localhost`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
+			},
+		},
+		{
+			desc: "two apps with two identical middleware",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.middlewares.Middleware1.maxconn.amount", "42"),
+				),
+				application(
+					appID("/app2"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.middlewares.Middleware1.maxconn.amount", "42"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"app": {
+							Service: "app",
+							Rule:    "Host(`app.marathon.localhost`)",
+						},
+						"app2": {
+							Service: "app2",
+							Rule:    "Host(`app2.marathon.localhost`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{
+						"Middleware1": {
+							MaxConn: &config.MaxConn{
+								Amount:        42,
+								ExtractorFunc: "request.host",
+							},
+						},
+					},
+					Services: map[string]*config.Service{
+						"app": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+						"app2": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "two apps with two different middlewares",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.middlewares.Middleware1.maxconn.amount", "42"),
+				),
+				application(
+					appID("/app2"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.middlewares.Middleware1.maxconn.amount", "41"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"app": {
+							Service: "app",
+							Rule:    "Host(`app.marathon.localhost`)",
+						},
+						"app2": {
+							Service: "app2",
+							Rule:    "Host(`app2.marathon.localhost`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"app": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+						"app2": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "two apps with two different routers with same name",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
+				),
+				application(
+					appID("/app2"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`bar.com`)"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"app": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+						"app2": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "two apps with two identical routers with same name",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
+					withLabel("traefik.http.services.Service1.LoadBalancer.passhostheader", "true"),
+				),
+				application(
+					appID("/app2"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
+					withLabel("traefik.http.services.Service1.LoadBalancer.passhostheader", "true"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"Router1": {
+							Service: "Service1",
+							Rule:    "Host(`foo.com`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Service1": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "two apps with two identical routers with same name",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
+				),
+				application(
+					appID("/app2"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"app": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+						"app2": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "one app with wrong label",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.wrong.label", "tchouk"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"app": {
+							Service: "app",
+							Rule:    "Host(`app.marathon.localhost`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"app": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "one app with label port",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.services.Service1.LoadBalancer.server.scheme", "h2c"),
+					withLabel("traefik.http.services.Service1.LoadBalancer.server.port", "90"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"app": {
+							Service: "Service1",
+							Rule:    "Host(`app.marathon.localhost`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Service1": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "h2c://localhost:90",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "one app with label port on two services",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.http.services.Service1.LoadBalancer.server.port", ""),
+					withLabel("traefik.http.services.Service2.LoadBalancer.server.port", "8080"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"Service1": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+						"Service2": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:8080",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "one app without port",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask()),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
+			},
+		},
+		{
+			desc: "one app without port with middleware",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask()),
+					withLabel("traefik.http.middlewares.Middleware1.basicauth.users", "test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/,test2:$apr1$d9hr9HBB$4HxwgUir3HP4EsggP/QNo0"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
+			},
+		},
+		{
+			desc: "one app with traefik.enable=false",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask()),
+					withLabel("traefik.enable", "false"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
+			},
+		},
+		{
+			desc: "one app with traefik.enable=false",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask()),
+					withLabel("traefik.enable", "false"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
+			},
+		},
+		{
+			desc: "one app with non matching constraint",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.tags", "foo"),
+				)),
+			constraints: []*types.Constraint{
+				{
+					Key:       "tag",
+					MustMatch: true,
+					Value:     "bar",
+				},
+			},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
+			},
+		},
+		{
+			desc: "one app with non matching marathon constraint",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					constraint("rack_id:CLUSTER:rack-1"),
+				)),
+			filterMarathonConstraints: true,
+			constraints: []*types.Constraint{
+				{
+					Key:       "tag",
+					MustMatch: true,
+					Value:     "rack_id:CLUSTER:rack-2",
+				},
+			},
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
 				},
 			},
 		},
 		{
-			desc: "one app with rule label and one service",
+			desc: "one app with matching marathon constraint",
 			applications: withApplications(
 				application(
 					appID("/app"),
 					appPorts(80, 81),
 					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
-					withLabel("traefik.http.services.Service1.loadbalancer.passhostheader", "true"),
+					constraint("rack_id:CLUSTER:rack-1"),
 				)),
+			filterMarathonConstraints: true,
+			constraints: []*types.Constraint{
+				{
+					Key:       "tag",
+					MustMatch: true,
+					Value:     "rack_id:CLUSTER:rack-1",
+				},
+			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
 					Routers:  map[string]*config.TCPRouter{},
@@ -489,14 +1554,14 @@ func TestBuildConfiguration(t *testing.T) {
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
-						"Router1": {
-							Service: "Service1",
-							Rule:    "Host(`foo.com`)",
+						"app": {
+							Service: "app",
+							Rule:    "Host(`app.marathon.localhost`)",
 						},
 					},
 					Middlewares: map[string]*config.Middleware{},
 					Services: map[string]*config.Service{
-						"Service1": {
+						"app": {
 							LoadBalancer: &config.LoadBalancerService{
 								Servers: []config.Server{
 									{
@@ -511,36 +1576,39 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "one app with rule label and two services",
+			desc: "applications filtered by group",
 			applications: withApplications(
 				application(
-					appID("/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
-					withLabel("traefik.http.services.Service1.loadbalancer.passhostheader", "true"),
-					withLabel("traefik.http.services.Service2.loadbalancer.passhostheader", "true"),
+					appID("/public/app"),
+					appPorts(80),
+					withTasks(localhostTask(taskPorts(80))),
+				),
+				application(
+					appID("/publicity/app"),
+					appPorts(80),
+					withTasks(localhostTask(taskPorts(80))),
+				),
+				application(
+					appID("/private/app"),
+					appPorts(80),
+					withTasks(localhostTask(taskPorts(80))),
 				)),
+			appIDPrefixes: []string{"/public/"},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
 					Routers:  map[string]*config.TCPRouter{},
 					Services: map[string]*config.TCPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
-					Routers:     map[string]*config.Router{},
+					Routers: map[string]*config.Router{
+						"public_app": {
+							Service: "public_app",
+							Rule:    "Host(`public-app.marathon.localhost`)",
+						},
+					},
 					Middlewares: map[string]*config.Middleware{},
 					Services: map[string]*config.Service{
-						"Service1": {
-							LoadBalancer: &config.LoadBalancerService{
-								Servers: []config.Server{
-									{
-										URL: "http://localhost:80",
-									},
-								},
-								PassHostHeader: true,
-							},
-						},
-						"Service2": {
+						"public_app": {
 							LoadBalancer: &config.LoadBalancerService{
 								Servers: []config.Server{
 									{
@@ -555,56 +1623,51 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "two apps with same service name and different passhostheader",
+			desc: "application in an allowed group with non matching constraint",
 			applications: withApplications(
 				application(
-					appID("/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.services.Service1.loadbalancer.passhostheader", "false"),
-				),
-				application(
-					appID("/app2"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.services.Service1.loadbalancer.passhostheader", "true"),
+					appID("/public/app"),
+					appPorts(80),
+					withTasks(localhostTask(taskPorts(80))),
+					withLabel("traefik.tags", "foo"),
 				)),
+			appIDPrefixes: []string{"/public"},
+			constraints: []*types.Constraint{
+				{
+					Key:       "tag",
+					MustMatch: true,
+					Value:     "bar",
+				},
+			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
 					Routers:  map[string]*config.TCPRouter{},
 					Services: map[string]*config.TCPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
-					Routers: map[string]*config.Router{
-						"app": {
-							Service: "Service1",
-							Rule:    "Host(`app.marathon.localhost`)",
-						},
-						"app2": {
-							Service: "Service1",
-							Rule:    "Host(`app2.marathon.localhost`)",
-						},
-					},
+					Routers:     map[string]*config.Router{},
 					Middlewares: map[string]*config.Middleware{},
 					Services:    map[string]*config.Service{},
 				},
 			},
 		},
 		{
-			desc: "two apps with two identical middleware",
+			desc: "one app with matching constraint",
 			applications: withApplications(
 				application(
 					appID("/app"),
 					appPorts(80, 81),
 					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.middlewares.Middleware1.maxconn.amount", "42"),
-				),
-				application(
-					appID("/app2"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.middlewares.Middleware1.maxconn.amount", "42"),
+					withLabel("traefik.tags", "bar"),
 				)),
+
+			constraints: []*types.Constraint{
+				{
+					Key:       "tag",
+					MustMatch: true,
+					Value:     "bar",
+				},
+			},
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
 					Routers:  map[string]*config.TCPRouter{},
@@ -616,19 +1679,8 @@ func TestBuildConfiguration(t *testing.T) {
 							Service: "app",
 							Rule:    "Host(`app.marathon.localhost`)",
 						},
-						"app2": {
-							Service: "app2",
-							Rule:    "Host(`app2.marathon.localhost`)",
-						},
-					},
-					Middlewares: map[string]*config.Middleware{
-						"Middleware1": {
-							MaxConn: &config.MaxConn{
-								Amount:        42,
-								ExtractorFunc: "request.host",
-							},
-						},
 					},
+					Middlewares: map[string]*config.Middleware{},
 					Services: map[string]*config.Service{
 						"app": {
 							LoadBalancer: &config.LoadBalancerService{
@@ -640,34 +1692,18 @@ func TestBuildConfiguration(t *testing.T) {
 								PassHostHeader: true,
 							},
 						},
-						"app2": {
-							LoadBalancer: &config.LoadBalancerService{
-								Servers: []config.Server{
-									{
-										URL: "http://localhost:80",
-									},
-								},
-								PassHostHeader: true,
-							},
-						},
 					},
 				},
 			},
 		},
 		{
-			desc: "two apps with two different middlewares",
+			desc:        "one app with group as subdomain rule",
+			defaultRule: `Host("{{ .Name | trimPrefix "/" | splitList "/" | strsToItfs | reverse | join "." }}.marathon.localhost")`,
 			applications: withApplications(
 				application(
-					appID("/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.middlewares.Middleware1.maxconn.amount", "42"),
-				),
-				application(
-					appID("/app2"),
+					appID("/a/b/app"),
 					appPorts(80, 81),
 					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.middlewares.Middleware1.maxconn.amount", "41"),
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
@@ -676,28 +1712,14 @@ func TestBuildConfiguration(t *testing.T) {
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
-						"app": {
-							Service: "app",
-							Rule:    "Host(`app.marathon.localhost`)",
-						},
-						"app2": {
-							Service: "app2",
-							Rule:    "Host(`app2.marathon.localhost`)",
+						"a_b_app": {
+							Service: "a_b_app",
+							Rule:    `Host("app.b.a.marathon.localhost")`,
 						},
 					},
 					Middlewares: map[string]*config.Middleware{},
 					Services: map[string]*config.Service{
-						"app": {
-							LoadBalancer: &config.LoadBalancerService{
-								Servers: []config.Server{
-									{
-										URL: "http://localhost:80",
-									},
-								},
-								PassHostHeader: true,
-							},
-						},
-						"app2": {
+						"a_b_app": {
 							LoadBalancer: &config.LoadBalancerService{
 								Servers: []config.Server{
 									{
@@ -712,19 +1734,16 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "two apps with two different routers with same name",
+			desc:        "one app with the region and the zone of its first running task in the default rule",
+			defaultRule: `Host("{{ normalize .Name }}.{{ .Region }}-{{ .Zone }}.{{ index .Attributes "rack" }}.example.com")`,
 			applications: withApplications(
 				application(
 					appID("/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
-				),
-				application(
-					appID("/app2"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.routers.Router1.rule", "Host(`bar.com`)"),
+					appPorts(80),
+					withTasks(
+						task(withTaskID("staging"), host("10.0.0.1"), taskPorts(80), taskState(taskStateStaging), taskRegion("us-east", "us-east-1a"), taskAttributes("rack:r0")),
+						localhostTask(taskPorts(80), taskRegion("eu-west", "eu-west-1b"), taskAttributes("rack:r1", "ssd")),
+					),
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
@@ -732,7 +1751,12 @@ func TestBuildConfiguration(t *testing.T) {
 					Services: map[string]*config.TCPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
-					Routers:     map[string]*config.Router{},
+					Routers: map[string]*config.Router{
+						"app": {
+							Service: "app",
+							Rule:    `Host("app.eu-west-eu-west-1b.r1.example.com")`,
+						},
+					},
 					Middlewares: map[string]*config.Middleware{},
 					Services: map[string]*config.Service{
 						"app": {
@@ -745,36 +1769,18 @@ func TestBuildConfiguration(t *testing.T) {
 								PassHostHeader: true,
 							},
 						},
-						"app2": {
-							LoadBalancer: &config.LoadBalancerService{
-								Servers: []config.Server{
-									{
-										URL: "http://localhost:80",
-									},
-								},
-								PassHostHeader: true,
-							},
-						},
 					},
 				},
 			},
 		},
 		{
-			desc: "two apps with two identical routers with same name",
+			desc:        "one app without region nor zone in the default rule",
+			defaultRule: `Host("{{ normalize .Name }}.{{ default "global" .Region }}{{ .Zone }}{{ index .Attributes "rack" }}.example.com")`,
 			applications: withApplications(
 				application(
 					appID("/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
-					withLabel("traefik.http.services.Service1.LoadBalancer.passhostheader", "true"),
-				),
-				application(
-					appID("/app2"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
-					withLabel("traefik.http.services.Service1.LoadBalancer.passhostheader", "true"),
+					appPorts(80),
+					withTasks(localhostTask(taskPorts(80))),
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
@@ -783,22 +1789,19 @@ func TestBuildConfiguration(t *testing.T) {
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
-						"Router1": {
-							Service: "Service1",
-							Rule:    "Host(`foo.com`)",
+						"app": {
+							Service: "app",
+							Rule:    `Host("app.global.example.com")`,
 						},
 					},
 					Middlewares: map[string]*config.Middleware{},
 					Services: map[string]*config.Service{
-						"Service1": {
+						"app": {
 							LoadBalancer: &config.LoadBalancerService{
 								Servers: []config.Server{
 									{
 										URL: "http://localhost:80",
 									},
-									{
-										URL: "http://localhost:80",
-									},
 								},
 								PassHostHeader: true,
 							},
@@ -808,81 +1811,88 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "two apps with two identical routers with same name",
+			desc: "one app with tcp labels",
 			applications: withApplications(
 				application(
 					appID("/app"),
 					appPorts(80, 81),
 					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
-				),
-				application(
-					appID("/app2"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.routers.Router1.rule", "Host(`foo.com`)"),
+					withLabel("traefik.tcp.routers.foo.rule", "HostSNI(`foo.bar`)"),
+					withLabel("traefik.tcp.routers.foo.tls", "true"),
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
-				},
-				HTTP: &config.HTTPConfiguration{
-					Routers:     map[string]*config.Router{},
-					Middlewares: map[string]*config.Middleware{},
-					Services: map[string]*config.Service{
-						"app": {
-							LoadBalancer: &config.LoadBalancerService{
-								Servers: []config.Server{
-									{
-										URL: "http://localhost:80",
-									},
-								},
-								PassHostHeader: true,
-							},
+					Routers: map[string]*config.TCPRouter{
+						"foo": {
+							Service: "app",
+							Rule:    "HostSNI(`foo.bar`)",
+							TLS:     &config.RouterTCPTLSConfig{},
 						},
-						"app2": {
-							LoadBalancer: &config.LoadBalancerService{
-								Servers: []config.Server{
+					},
+					Services: map[string]*config.TCPService{
+						"app": {
+							LoadBalancer: &config.TCPLoadBalancerService{
+								Servers: []config.TCPServer{
 									{
-										URL: "http://localhost:80",
+										Address: "localhost:80",
 									},
 								},
-								PassHostHeader: true,
 							},
 						},
 					},
 				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
 			},
 		},
 		{
-			desc: "one app with wrong label",
+			desc: "one app with HTTP and TCP services on different port indexes",
 			applications: withApplications(
 				application(
 					appID("/app"),
 					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.wrong.label", "tchouk"),
+					withTasks(localhostTask(taskPorts(31000, 31001))),
+					withLabel("traefik.http.services.web.loadbalancer.server.port", "index:0"),
+					withLabel("traefik.tcp.services.db.loadbalancer.server.port", "index:1"),
+					withLabel("traefik.tcp.routers.db.rule", "HostSNI(`*`)"),
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers: map[string]*config.TCPRouter{
+						"db": {
+							Service: "db",
+							Rule:    "HostSNI(`*`)",
+						},
+					},
+					Services: map[string]*config.TCPService{
+						"db": {
+							LoadBalancer: &config.TCPLoadBalancerService{
+								Servers: []config.TCPServer{
+									{
+										Address: "localhost:31001",
+									},
+								},
+							},
+						},
+					},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
 						"app": {
-							Service: "app",
+							Service: "web",
 							Rule:    "Host(`app.marathon.localhost`)",
 						},
 					},
 					Middlewares: map[string]*config.Middleware{},
 					Services: map[string]*config.Service{
-						"app": {
+						"web": {
 							LoadBalancer: &config.LoadBalancerService{
 								Servers: []config.Server{
 									{
-										URL: "http://localhost:80",
+										URL: "http://localhost:31000",
 									},
 								},
 								PassHostHeader: true,
@@ -893,34 +1903,50 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "one app with label port",
+			desc: "one app with IP-per-task, and HTTP and TCP services on different port indexes",
 			applications: withApplications(
 				application(
 					appID("/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.services.Service1.LoadBalancer.server.scheme", "h2c"),
-					withLabel("traefik.http.services.Service1.LoadBalancer.server.port", "90"),
+					ipAddrPerTask(8080, 5432),
+					withTasks(task(withTaskID("task"), host("agent"), ipAddresses("10.0.0.1"))),
+					withLabel("traefik.http.services.web.loadbalancer.server.port", "index:0"),
+					withLabel("traefik.tcp.services.db.loadbalancer.server.port", "index:1"),
+					withLabel("traefik.tcp.routers.db.rule", "HostSNI(`*`)"),
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers: map[string]*config.TCPRouter{
+						"db": {
+							Service: "db",
+							Rule:    "HostSNI(`*`)",
+						},
+					},
+					Services: map[string]*config.TCPService{
+						"db": {
+							LoadBalancer: &config.TCPLoadBalancerService{
+								Servers: []config.TCPServer{
+									{
+										Address: "10.0.0.1:5432",
+									},
+								},
+							},
+						},
+					},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
 						"app": {
-							Service: "Service1",
+							Service: "web",
 							Rule:    "Host(`app.marathon.localhost`)",
 						},
 					},
 					Middlewares: map[string]*config.Middleware{},
 					Services: map[string]*config.Service{
-						"Service1": {
+						"web": {
 							LoadBalancer: &config.LoadBalancerService{
 								Servers: []config.Server{
 									{
-										URL: "h2c://localhost:90",
+										URL: "http://10.0.0.1:8080",
 									},
 								},
 								PassHostHeader: true,
@@ -931,81 +1957,164 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "one app with label port on two services",
+			desc: "one app with tcp labels without rule",
 			applications: withApplications(
 				application(
 					appID("/app"),
 					appPorts(80, 81),
 					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.http.services.Service1.LoadBalancer.server.port", ""),
-					withLabel("traefik.http.services.Service2.LoadBalancer.server.port", "8080"),
+					withLabel("traefik.tcp.routers.foo.tls", "true"),
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
-				},
-				HTTP: &config.HTTPConfiguration{
-					Routers:     map[string]*config.Router{},
-					Middlewares: map[string]*config.Middleware{},
-					Services: map[string]*config.Service{
-						"Service1": {
-							LoadBalancer: &config.LoadBalancerService{
-								Servers: []config.Server{
+					Routers: map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{
+						"app": {
+							LoadBalancer: &config.TCPLoadBalancerService{
+								Servers: []config.TCPServer{
 									{
-										URL: "http://localhost:80",
+										Address: "localhost:80",
 									},
 								},
-								PassHostHeader: true,
 							},
 						},
-						"Service2": {
-							LoadBalancer: &config.LoadBalancerService{
-								Servers: []config.Server{
+					},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
+			},
+		},
+		{
+			desc: "one app with tcp labels with port",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80, 81),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.tcp.routers.foo.rule", "HostSNI(`foo.bar`)"),
+					withLabel("traefik.tcp.routers.foo.tls", "true"),
+					withLabel("traefik.tcp.services.foo.loadbalancer.server.port", "8080"),
+				)),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers: map[string]*config.TCPRouter{
+						"foo": {
+							Service: "foo",
+							Rule:    "HostSNI(`foo.bar`)",
+							TLS:     &config.RouterTCPTLSConfig{},
+						},
+					},
+					Services: map[string]*config.TCPService{
+						"foo": {
+							LoadBalancer: &config.TCPLoadBalancerService{
+								Servers: []config.TCPServer{
 									{
-										URL: "http://localhost:8080",
+										Address: "localhost:8080",
 									},
 								},
-								PassHostHeader: true,
 							},
 						},
 					},
 				},
+				HTTP: &config.HTTPConfiguration{
+					Routers:     map[string]*config.Router{},
+					Middlewares: map[string]*config.Middleware{},
+					Services:    map[string]*config.Service{},
+				},
 			},
 		},
 		{
-			desc: "one app without port",
+			desc: "one app with tcp labels with port and http service",
 			applications: withApplications(
 				application(
 					appID("/app"),
 					appPorts(80, 81),
-					withTasks(localhostTask()),
+					withTasks(localhostTask(taskPorts(80, 81))),
+					withLabel("traefik.tcp.routers.foo.rule", "HostSNI(`foo.bar`)"),
+					withLabel("traefik.tcp.routers.foo.tls", "true"),
+					withLabel("traefik.tcp.services.foo.loadbalancer.server.port", "8080"),
+					withLabel("traefik.http.services.bar.loadbalancer.passhostheader", "true"),
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers: map[string]*config.TCPRouter{
+						"foo": {
+							Service: "foo",
+							Rule:    "HostSNI(`foo.bar`)",
+							TLS:     &config.RouterTCPTLSConfig{},
+						},
+					},
+					Services: map[string]*config.TCPService{
+						"foo": {
+							LoadBalancer: &config.TCPLoadBalancerService{
+								Servers: []config.TCPServer{
+									{
+										Address: "localhost:8080",
+									},
+								},
+							},
+						},
+					},
 				},
 				HTTP: &config.HTTPConfiguration{
-					Routers:     map[string]*config.Router{},
+					Routers: map[string]*config.Router{
+						"app": {
+							Service: "bar",
+							Rule:    "Host(`app.marathon.localhost`)",
+						},
+					},
 					Middlewares: map[string]*config.Middleware{},
-					Services:    map[string]*config.Service{},
+					Services: map[string]*config.Service{
+						"bar": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
 				},
 			},
 		},
 		{
-			desc: "one app without port with middleware",
+			desc: "one app with tcp labels with tls passthrough",
 			applications: withApplications(
 				application(
 					appID("/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask()),
-					withLabel("traefik.http.middlewares.Middleware1.basicauth.users", "test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/,test2:$apr1$d9hr9HBB$4HxwgUir3HP4EsggP/QNo0"),
+					appPorts(80, 8443),
+					withTasks(localhostTask(taskPorts(80, 8443))),
+					withLabel("traefik.tcp.routers.foo.rule", "HostSNI(`foo.bar`)"),
+					withLabel("traefik.tcp.routers.foo.tls.passthrough", "true"),
+					withLabel("traefik.tcp.services.foo.loadbalancer.server.port", "index:1"),
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers: map[string]*config.TCPRouter{
+						"foo": {
+							Service: "foo",
+							Rule:    "HostSNI(`foo.bar`)",
+							TLS: &config.RouterTCPTLSConfig{
+								Passthrough: true,
+							},
+						},
+					},
+					Services: map[string]*config.TCPService{
+						"foo": {
+							LoadBalancer: &config.TCPLoadBalancerService{
+								Servers: []config.TCPServer{
+									{
+										Address: "localhost:8443",
+									},
+								},
+							},
+						},
+					},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -1015,18 +2124,29 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "one app with traefik.enable=false",
+			desc: "one app with tcp labels with tls passthrough without rule",
 			applications: withApplications(
 				application(
 					appID("/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask()),
-					withLabel("traefik.enable", "false"),
+					appPorts(80, 8443),
+					withTasks(localhostTask(taskPorts(80, 8443))),
+					withLabel("traefik.tcp.routers.foo.tls.passthrough", "true"),
+					withLabel("traefik.tcp.services.foo.loadbalancer.server.port", "8443"),
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers: map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{
+						"foo": {
+							LoadBalancer: &config.TCPLoadBalancerService{
+								Servers: []config.TCPServer{
+									{
+										Address: "localhost:8443",
+									},
+								},
+							},
+						},
+					},
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers:     map[string]*config.Router{},
@@ -1036,43 +2156,92 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "one app with traefik.enable=false",
+			desc: "one app with tcp labels with tls passthrough and http router",
 			applications: withApplications(
 				application(
 					appID("/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask()),
-					withLabel("traefik.enable", "false"),
+					appPorts(80, 8443),
+					withTasks(localhostTask(taskPorts(80, 8443))),
+					withLabel("traefik.tcp.routers.foo.rule", "HostSNI(`foo.bar`)"),
+					withLabel("traefik.tcp.routers.foo.tls.passthrough", "true"),
+					withLabel("traefik.tcp.services.foo.loadbalancer.server.port", "8443"),
+					withLabel("traefik.http.routers.bar.rule", "Host(`bar.localhost`)"),
 				)),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers:  map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{},
+					Routers: map[string]*config.TCPRouter{
+						"foo": {
+							Service: "foo",
+							Rule:    "HostSNI(`foo.bar`)",
+							TLS: &config.RouterTCPTLSConfig{
+								Passthrough: true,
+							},
+						},
+					},
+					Services: map[string]*config.TCPService{
+						"foo": {
+							LoadBalancer: &config.TCPLoadBalancerService{
+								Servers: []config.TCPServer{
+									{
+										Address: "localhost:8443",
+									},
+								},
+							},
+						},
+					},
 				},
 				HTTP: &config.HTTPConfiguration{
-					Routers:     map[string]*config.Router{},
+					Routers: map[string]*config.Router{
+						"bar": {
+							Service: "app",
+							Rule:    "Host(`bar.localhost`)",
+						},
+					},
 					Middlewares: map[string]*config.Middleware{},
-					Services:    map[string]*config.Service{},
+					Services: map[string]*config.Service{
+						"app": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
+									{
+										URL: "http://localhost:80",
+									},
+								},
+								PassHostHeader: true,
+							},
+						},
+					},
 				},
 			},
 		},
 		{
-			desc: "one app with non matching constraint",
+			desc: "one app with udp labels",
 			applications: withApplications(
 				application(
 					appID("/app"),
 					appPorts(80, 81),
 					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.tags", "foo"),
+					withLabel("traefik.udp.routers.foo.entrypoints", "dns"),
 				)),
-			constraints: []*types.Constraint{
-				{
-					Key:       "tag",
-					MustMatch: true,
-					Value:     "bar",
-				},
-			},
 			expected: &config.Configuration{
+				UDP: &config.UDPConfiguration{
+					Routers: map[string]*config.UDPRouter{
+						"foo": {
+							EntryPoints: []string{"dns"},
+							Service:     "app",
+						},
+					},
+					Services: map[string]*config.UDPService{
+						"app": {
+							LoadBalancer: &config.UDPLoadBalancerService{
+								Servers: []config.UDPServer{
+									{
+										Address: "localhost:80",
+									},
+								},
+							},
+						},
+					},
+				},
 				TCP: &config.TCPConfiguration{
 					Routers:  map[string]*config.TCPRouter{},
 					Services: map[string]*config.TCPService{},
@@ -1085,23 +2254,35 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "one app with non matching marathon constraint",
+			desc: "one app with udp labels with index port",
 			applications: withApplications(
 				application(
 					appID("/app"),
 					appPorts(80, 81),
 					withTasks(localhostTask(taskPorts(80, 81))),
-					constraint("rack_id:CLUSTER:rack-1"),
+					withLabel("traefik.udp.routers.foo.entrypoints", "dns"),
+					withLabel("traefik.udp.services.foo.loadbalancer.server.port", "index:1"),
 				)),
-			filterMarathonConstraints: true,
-			constraints: []*types.Constraint{
-				{
-					Key:       "tag",
-					MustMatch: true,
-					Value:     "rack_id:CLUSTER:rack-2",
-				},
-			},
 			expected: &config.Configuration{
+				UDP: &config.UDPConfiguration{
+					Routers: map[string]*config.UDPRouter{
+						"foo": {
+							EntryPoints: []string{"dns"},
+							Service:     "foo",
+						},
+					},
+					Services: map[string]*config.UDPService{
+						"foo": {
+							LoadBalancer: &config.UDPLoadBalancerService{
+								Servers: []config.UDPServer{
+									{
+										Address: "localhost:81",
+									},
+								},
+							},
+						},
+					},
+				},
 				TCP: &config.TCPConfiguration{
 					Routers:  map[string]*config.TCPRouter{},
 					Services: map[string]*config.TCPService{},
@@ -1114,23 +2295,37 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "one app with matching marathon constraint",
+			desc: "one app with udp labels with named port and http service",
 			applications: withApplications(
 				application(
 					appID("/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					constraint("rack_id:CLUSTER:rack-1"),
+					namedPortDefinition(80, "http"),
+					namedPortDefinition(53, "dns"),
+					withTasks(localhostTask(taskPorts(31000, 31001))),
+					withLabel("traefik.udp.routers.foo.entrypoints", "dns"),
+					withLabel("traefik.udp.services.foo.loadbalancer.server.port", "name:dns"),
+					withLabel("traefik.http.services.bar.loadbalancer.server.port", "name:http"),
 				)),
-			filterMarathonConstraints: true,
-			constraints: []*types.Constraint{
-				{
-					Key:       "tag",
-					MustMatch: true,
-					Value:     "rack_id:CLUSTER:rack-1",
-				},
-			},
 			expected: &config.Configuration{
+				UDP: &config.UDPConfiguration{
+					Routers: map[string]*config.UDPRouter{
+						"foo": {
+							EntryPoints: []string{"dns"},
+							Service:     "foo",
+						},
+					},
+					Services: map[string]*config.UDPService{
+						"foo": {
+							LoadBalancer: &config.UDPLoadBalancerService{
+								Servers: []config.UDPServer{
+									{
+										Address: "localhost:31001",
+									},
+								},
+							},
+						},
+					},
+				},
 				TCP: &config.TCPConfiguration{
 					Routers:  map[string]*config.TCPRouter{},
 					Services: map[string]*config.TCPService{},
@@ -1138,17 +2333,17 @@ func TestBuildConfiguration(t *testing.T) {
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
 						"app": {
-							Service: "app",
+							Service: "bar",
 							Rule:    "Host(`app.marathon.localhost`)",
 						},
 					},
 					Middlewares: map[string]*config.Middleware{},
 					Services: map[string]*config.Service{
-						"app": {
+						"bar": {
 							LoadBalancer: &config.LoadBalancerService{
 								Servers: []config.Server{
 									{
-										URL: "http://localhost:80",
+										URL: "http://localhost:31000",
 									},
 								},
 								PassHostHeader: true,
@@ -1159,23 +2354,39 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "one app with matching constraint",
+			desc: "one app with udp labels without resolvable port",
 			applications: withApplications(
 				application(
 					appID("/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.tags", "bar"),
+					namedPortDefinition(80, "http"),
+					withTasks(localhostTask(taskPorts(31000))),
+					withLabel("traefik.udp.routers.foo.entrypoints", "dns"),
+					withLabel("traefik.udp.services.foo.loadbalancer.server.port", "name:dns"),
+					withLabel("traefik.udp.routers.bar.entrypoints", "other"),
+					withLabel("traefik.udp.routers.bar.service", "bar"),
+					withLabel("traefik.udp.services.bar.loadbalancer.server.port", "index:0"),
+					withLabel("traefik.http.routers.app.rule", "Host(`app.localhost`)"),
 				)),
-
-			constraints: []*types.Constraint{
-				{
-					Key:       "tag",
-					MustMatch: true,
-					Value:     "bar",
-				},
-			},
 			expected: &config.Configuration{
+				UDP: &config.UDPConfiguration{
+					Routers: map[string]*config.UDPRouter{
+						"bar": {
+							EntryPoints: []string{"other"},
+							Service:     "bar",
+						},
+					},
+					Services: map[string]*config.UDPService{
+						"bar": {
+							LoadBalancer: &config.UDPLoadBalancerService{
+								Servers: []config.UDPServer{
+									{
+										Address: "localhost:31000",
+									},
+								},
+							},
+						},
+					},
+				},
 				TCP: &config.TCPConfiguration{
 					Routers:  map[string]*config.TCPRouter{},
 					Services: map[string]*config.TCPService{},
@@ -1184,7 +2395,7 @@ func TestBuildConfiguration(t *testing.T) {
 					Routers: map[string]*config.Router{
 						"app": {
 							Service: "app",
-							Rule:    "Host(`app.marathon.localhost`)",
+							Rule:    "Host(`app.localhost`)",
 						},
 					},
 					Middlewares: map[string]*config.Middleware{},
@@ -1193,7 +2404,7 @@ func TestBuildConfiguration(t *testing.T) {
 							LoadBalancer: &config.LoadBalancerService{
 								Servers: []config.Server{
 									{
-										URL: "http://localhost:80",
+										URL: "http://localhost:31000",
 									},
 								},
 								PassHostHeader: true,
@@ -1204,14 +2415,27 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc:        "one app with group as subdomain rule",
-			defaultRule: `Host("{{ .Name | trimPrefix "/" | splitList "/" | strsToItfs | reverse | join "." }}.marathon.localhost")`,
+			desc: "label selector",
 			applications: withApplications(
 				application(
-					appID("/a/b/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-				)),
+					appID("/web"),
+					appPorts(80),
+					withLabel("team", "web"),
+					withTasks(localhostTask(taskPorts(80))),
+				),
+				application(
+					appID("/api"),
+					appPorts(80),
+					withLabel("team", "api"),
+					withTasks(localhostTask(taskPorts(80))),
+				),
+				application(
+					appID("/other"),
+					appPorts(80),
+					withTasks(localhostTask(taskPorts(80))),
+				),
+			),
+			labelSelector: "team=web",
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
 					Routers:  map[string]*config.TCPRouter{},
@@ -1219,14 +2443,14 @@ func TestBuildConfiguration(t *testing.T) {
 				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
-						"a_b_app": {
-							Service: "a_b_app",
-							Rule:    `Host("app.b.a.marathon.localhost")`,
+						"web": {
+							Service: "web",
+							Rule:    "Host(`web.marathon.localhost`)",
 						},
 					},
 					Middlewares: map[string]*config.Middleware{},
 					Services: map[string]*config.Service{
-						"a_b_app": {
+						"web": {
 							LoadBalancer: &config.LoadBalancerService{
 								Servers: []config.Server{
 									{
@@ -1241,160 +2465,163 @@ func TestBuildConfiguration(t *testing.T) {
 			},
 		},
 		{
-			desc: "one app with tcp labels",
+			desc: "label selector with a set based requirement",
 			applications: withApplications(
 				application(
-					appID("/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.tcp.routers.foo.rule", "HostSNI(`foo.bar`)"),
-					withLabel("traefik.tcp.routers.foo.tls", "true"),
-				)),
+					appID("/web"),
+					appPorts(80),
+					withLabel("team", "web"),
+					withTasks(localhostTask(taskPorts(80))),
+				),
+				application(
+					appID("/api"),
+					appPorts(80),
+					withLabel("team", "api"),
+					withTasks(localhostTask(taskPorts(80))),
+				),
+				application(
+					appID("/other"),
+					appPorts(80),
+					withTasks(localhostTask(taskPorts(80))),
+				),
+			),
+			labelSelector: "team,team notin (api)",
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers: map[string]*config.TCPRouter{
-						"foo": {
-							Service: "app",
-							Rule:    "HostSNI(`foo.bar`)",
-							TLS:     &config.RouterTCPTLSConfig{},
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"web": {
+							Service: "web",
+							Rule:    "Host(`web.marathon.localhost`)",
 						},
 					},
-					Services: map[string]*config.TCPService{
-						"app": {
-							LoadBalancer: &config.TCPLoadBalancerService{
-								Servers: []config.TCPServer{
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"web": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
 									{
-										Address: "localhost:80",
+										URL: "http://localhost:80",
 									},
 								},
+								PassHostHeader: true,
 							},
 						},
 					},
 				},
-				HTTP: &config.HTTPConfiguration{
-					Routers:     map[string]*config.Router{},
-					Middlewares: map[string]*config.Middleware{},
-					Services:    map[string]*config.Service{},
-				},
 			},
 		},
 		{
-			desc: "one app with tcp labels without rule",
+			desc: "application without labels, with the provider default scheme and pass host header",
 			applications: withApplications(
 				application(
 					appID("/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.tcp.routers.foo.tls", "true"),
+					appPorts(80),
+					withTasks(localhostTask(taskPorts(80))),
 				)),
+			defaultServerScheme:   "https",
+			defaultPassHostHeader: boolPtr(false),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers: map[string]*config.TCPRouter{},
-					Services: map[string]*config.TCPService{
-						"app": {
-							LoadBalancer: &config.TCPLoadBalancerService{
-								Servers: []config.TCPServer{
-									{
-										Address: "localhost:80",
-									},
-								},
-							},
-						},
-					},
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
 				},
 				HTTP: &config.HTTPConfiguration{
-					Routers:     map[string]*config.Router{},
-					Middlewares: map[string]*config.Middleware{},
-					Services:    map[string]*config.Service{},
-				},
-			},
-		},
-		{
-			desc: "one app with tcp labels with port",
-			applications: withApplications(
-				application(
-					appID("/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.tcp.routers.foo.rule", "HostSNI(`foo.bar`)"),
-					withLabel("traefik.tcp.routers.foo.tls", "true"),
-					withLabel("traefik.tcp.services.foo.loadbalancer.server.port", "8080"),
-				)),
-			expected: &config.Configuration{
-				TCP: &config.TCPConfiguration{
-					Routers: map[string]*config.TCPRouter{
-						"foo": {
-							Service: "foo",
-							Rule:    "HostSNI(`foo.bar`)",
-							TLS:     &config.RouterTCPTLSConfig{},
+					Routers: map[string]*config.Router{
+						"app": {
+							Service: "app",
+							Rule:    "Host(`app.marathon.localhost`)",
 						},
 					},
-					Services: map[string]*config.TCPService{
-						"foo": {
-							LoadBalancer: &config.TCPLoadBalancerService{
-								Servers: []config.TCPServer{
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"app": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
 									{
-										Address: "localhost:8080",
+										URL: "https://localhost:80",
 									},
 								},
+								PassHostHeader: false,
 							},
 						},
 					},
 				},
-				HTTP: &config.HTTPConfiguration{
-					Routers:     map[string]*config.Router{},
-					Middlewares: map[string]*config.Middleware{},
-					Services:    map[string]*config.Service{},
-				},
 			},
 		},
 		{
-			desc: "one app with tcp labels with port and http service",
+			desc: "application with a service set by labels, with the provider default scheme and pass host header",
 			applications: withApplications(
 				application(
 					appID("/app"),
-					appPorts(80, 81),
-					withTasks(localhostTask(taskPorts(80, 81))),
-					withLabel("traefik.tcp.routers.foo.rule", "HostSNI(`foo.bar`)"),
-					withLabel("traefik.tcp.routers.foo.tls", "true"),
-					withLabel("traefik.tcp.services.foo.loadbalancer.server.port", "8080"),
-					withLabel("traefik.http.services.bar.loadbalancer.passhostheader", "true"),
+					appPorts(80),
+					withTasks(localhostTask(taskPorts(80))),
+					withLabel("traefik.http.services.app.loadbalancer.server.port", "80"),
 				)),
+			defaultServerScheme:   "https",
+			defaultPassHostHeader: boolPtr(false),
 			expected: &config.Configuration{
 				TCP: &config.TCPConfiguration{
-					Routers: map[string]*config.TCPRouter{
-						"foo": {
-							Service: "foo",
-							Rule:    "HostSNI(`foo.bar`)",
-							TLS:     &config.RouterTCPTLSConfig{},
-						},
-					},
-					Services: map[string]*config.TCPService{
-						"foo": {
-							LoadBalancer: &config.TCPLoadBalancerService{
-								Servers: []config.TCPServer{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
+				HTTP: &config.HTTPConfiguration{
+					Routers: map[string]*config.Router{
+						"app": {
+							Service: "app",
+							Rule:    "Host(`app.marathon.localhost`)",
+						},
+					},
+					Middlewares: map[string]*config.Middleware{},
+					Services: map[string]*config.Service{
+						"app": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{
 									{
-										Address: "localhost:8080",
+										URL: "https://localhost:80",
 									},
 								},
+								PassHostHeader: false,
 							},
 						},
 					},
 				},
+			},
+		},
+		{
+			desc: "application overriding the provider default scheme and pass host header with labels",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80),
+					withTasks(localhostTask(taskPorts(80))),
+					withLabel("traefik.http.services.app.loadbalancer.server.scheme", "h2c"),
+					withLabel("traefik.http.services.app.loadbalancer.passhostheader", "true"),
+				)),
+			defaultServerScheme:   "https",
+			defaultPassHostHeader: boolPtr(false),
+			expected: &config.Configuration{
+				TCP: &config.TCPConfiguration{
+					Routers:  map[string]*config.TCPRouter{},
+					Services: map[string]*config.TCPService{},
+				},
 				HTTP: &config.HTTPConfiguration{
 					Routers: map[string]*config.Router{
 						"app": {
-							Service: "bar",
+							Service: "app",
 							Rule:    "Host(`app.marathon.localhost`)",
 						},
 					},
 					Middlewares: map[string]*config.Middleware{},
 					Services: map[string]*config.Service{
-						"bar": {
+						"app": {
 							LoadBalancer: &config.LoadBalancerService{
 								Servers: []config.Server{
 									{
-										URL: "http://localhost:80",
+										URL: "h2c://localhost:80",
 									},
 								},
 								PassHostHeader: true,
@@ -1420,6 +2647,12 @@ func TestBuildConfiguration(t *testing.T) {
 				DefaultRule:               defaultRule,
 				ExposedByDefault:          true,
 				FilterMarathonConstraints: test.filterMarathonConstraints,
+				RespectReadinessChecks:    test.respectReadinessChecks,
+				OnlyTasksOfLatestVersion:  test.onlyTasksOfLatestVersion,
+				AppIDPrefixes:             test.appIDPrefixes,
+				LabelSelector:             test.labelSelector,
+				DefaultServerScheme:       test.defaultServerScheme,
+				DefaultPassHostHeader:     test.defaultPassHostHeader,
 			}
 			p.Constraints = test.constraints
 
@@ -1434,6 +2667,273 @@ func TestBuildConfiguration(t *testing.T) {
 	}
 }
 
+// syntheticApplications creates applications, sharing their services by groups of ten,
+// with some of them disabled, and some tasks not running.
+func syntheticApplications(count int) *marathon.Applications {
+	var apps []marathon.Application
+	for i := 0; i < count; i++ {
+		app := application(
+			appID(fmt.Sprintf("/group-%d/app-%03d", i%7, i)),
+			appPorts(80),
+			withLabel("traefik.http.services."+fmt.Sprintf("shared-%d", i/10)+".loadbalancer.server.port", "index:0"),
+			withTasks(
+				task(withTaskID(fmt.Sprintf("task-%03d-1", i)), host(fmt.Sprintf("10.0.%d.1", i)), taskPorts(8080)),
+				task(withTaskID(fmt.Sprintf("task-%03d-2", i)), host(fmt.Sprintf("10.0.%d.2", i)), taskPorts(8080)),
+			),
+		)
+		if i%11 == 0 {
+			app.AddLabel("traefik.enable", "false")
+		}
+		if i%13 == 0 {
+			app.Tasks[1].State = string(taskStateStaging)
+		}
+		apps = append(apps, app)
+	}
+	return &marathon.Applications{Apps: apps}
+}
+
+func TestBuildConfigurationDeterministic(t *testing.T) {
+	p := &Provider{
+		DefaultRule:      "Host(`{{ normalize .Name }}.marathon.localhost`)",
+		ExposedByDefault: true,
+	}
+
+	err := p.Init()
+	require.NoError(t, err)
+
+	applications := syntheticApplications(300)
+
+	expected := p.buildConfiguration(context.Background(), applications)
+	require.NotNil(t, expected)
+	require.Len(t, expected.HTTP.Services, 30)
+
+	// The servers of a shared service are ordered by application ID, whatever the order in which the applications are built.
+	var urls []string
+	for _, server := range expected.HTTP.Services["shared-1"].LoadBalancer.Servers {
+		urls = append(urls, server.URL)
+	}
+	assert.Equal(t, []string{
+		"http://10.0.14.1:8080", "http://10.0.14.2:8080", // /group-0/app-014
+		"http://10.0.15.1:8080", "http://10.0.15.2:8080", // /group-1/app-015
+		"http://10.0.16.1:8080", "http://10.0.16.2:8080", // /group-2/app-016
+		"http://10.0.10.1:8080", "http://10.0.10.2:8080", // /group-3/app-010
+		"http://10.0.17.1:8080", "http://10.0.17.2:8080", // /group-3/app-017
+		"http://10.0.18.1:8080", "http://10.0.18.2:8080", // /group-4/app-018
+		"http://10.0.12.1:8080", "http://10.0.12.2:8080", // /group-5/app-012
+		"http://10.0.19.1:8080", "http://10.0.19.2:8080", // /group-5/app-019
+		"http://10.0.13.1:8080", // /group-6/app-013
+	}, urls)
+
+	for i := 0; i < 10; i++ {
+		actual := p.buildConfiguration(context.Background(), applications)
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestBuildConfigurationCanceled(t *testing.T) {
+	p := &Provider{
+		DefaultRule:      "Host(`{{ normalize .Name }}.marathon.localhost`)",
+		ExposedByDefault: true,
+	}
+
+	err := p.Init()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.Nil(t, p.buildConfiguration(ctx, syntheticApplications(300)))
+}
+
+func BenchmarkBuildConfiguration(b *testing.B) {
+	p := &Provider{
+		DefaultRule:      "Host(`{{ normalize .Name }}.marathon.localhost`)",
+		ExposedByDefault: true,
+	}
+
+	err := p.Init()
+	require.NoError(b, err)
+
+	applications := syntheticApplications(500)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.buildConfiguration(context.Background(), applications)
+	}
+}
+
+func TestInitInvalidLabelSelector(t *testing.T) {
+	p := &Provider{
+		DefaultRule:   DefaultTemplateRule,
+		LabelSelector: "team in (web",
+	}
+
+	err := p.Init()
+	assert.Error(t, err)
+}
+
+func TestBuildConfigurationRouterPerPort(t *testing.T) {
+	threePorts := []func(*marathon.Application){
+		appID("/app"),
+		namedPortDefinition(80, "web"),
+		namedPortDefinition(8080, "admin"),
+		namedPortDefinition(9090, "metrics"),
+		withTasks(localhostTask(taskPorts(31000, 31001, 31002))),
+	}
+
+	server := func(url string) *config.Service {
+		return &config.Service{
+			LoadBalancer: &config.LoadBalancerService{
+				Servers:        []config.Server{{URL: url}},
+				PassHostHeader: true,
+			},
+		}
+	}
+
+	testCases := []struct {
+		desc             string
+		routerPerPort    bool
+		defaultRule      string
+		applications     *marathon.Applications
+		expectedRouters  map[string]*config.Router
+		expectedServices map[string]*config.Service
+	}{
+		{
+			desc:         "single router by default",
+			defaultRule:  "Host(`{{ normalize .Name }}.marathon.localhost`)",
+			applications: withApplications(application(threePorts...)),
+			expectedRouters: map[string]*config.Router{
+				"app": {
+					Service: "app",
+					Rule:    "Host(`app.marathon.localhost`)",
+				},
+			},
+			expectedServices: map[string]*config.Service{
+				"app": server("http://localhost:31000"),
+			},
+		},
+		{
+			desc:        "routers and services bound to named ports with labels",
+			defaultRule: "Host(`{{ normalize .Name }}.marathon.localhost`)",
+			applications: withApplications(application(append(threePorts,
+				withLabel("traefik.http.routers.web.rule", "Host(`web.localhost`)"),
+				withLabel("traefik.http.routers.web.service", "web"),
+				withLabel("traefik.http.services.web.loadbalancer.server.port", "name:web"),
+				withLabel("traefik.http.routers.admin.rule", "Host(`admin.localhost`)"),
+				withLabel("traefik.http.routers.admin.service", "admin"),
+				withLabel("traefik.http.services.admin.loadbalancer.server.port", "name:admin"),
+				withLabel("traefik.http.routers.metrics.rule", "Host(`metrics.localhost`)"),
+				withLabel("traefik.http.routers.metrics.service", "metrics"),
+				withLabel("traefik.http.services.metrics.loadbalancer.server.port", "name:metrics"),
+			)...)),
+			expectedRouters: map[string]*config.Router{
+				"web":     {Service: "web", Rule: "Host(`web.localhost`)"},
+				"admin":   {Service: "admin", Rule: "Host(`admin.localhost`)"},
+				"metrics": {Service: "metrics", Rule: "Host(`metrics.localhost`)"},
+			},
+			expectedServices: map[string]*config.Service{
+				"web":     server("http://localhost:31000"),
+				"admin":   server("http://localhost:31001"),
+				"metrics": server("http://localhost:31002"),
+			},
+		},
+		{
+			desc:          "router per named port",
+			routerPerPort: true,
+			defaultRule:   "Host(`{{ .PortName }}.{{ normalize .Name }}.marathon.localhost`)",
+			applications:  withApplications(application(threePorts...)),
+			expectedRouters: map[string]*config.Router{
+				"app-web":     {Service: "app-web", Rule: "Host(`web.app.marathon.localhost`)"},
+				"app-admin":   {Service: "app-admin", Rule: "Host(`admin.app.marathon.localhost`)"},
+				"app-metrics": {Service: "app-metrics", Rule: "Host(`metrics.app.marathon.localhost`)"},
+			},
+			expectedServices: map[string]*config.Service{
+				"app-web":     server("http://localhost:31000"),
+				"app-admin":   server("http://localhost:31001"),
+				"app-metrics": server("http://localhost:31002"),
+			},
+		},
+		{
+			desc:          "router per named port of the port mappings",
+			routerPerPort: true,
+			defaultRule:   "Host(`{{ .PortName }}.{{ normalize .Name }}.marathon.localhost`)",
+			applications: withApplications(application(
+				appID("/app"),
+				bridgeNetwork(),
+				portMapping(80, "web"),
+				portMapping(8080, "admin"),
+				withTasks(localhostTask(taskPorts(31000, 31001))),
+			)),
+			expectedRouters: map[string]*config.Router{
+				"app-web":   {Service: "app-web", Rule: "Host(`web.app.marathon.localhost`)"},
+				"app-admin": {Service: "app-admin", Rule: "Host(`admin.app.marathon.localhost`)"},
+			},
+			expectedServices: map[string]*config.Service{
+				"app-web":   server("http://localhost:31000"),
+				"app-admin": server("http://localhost:31001"),
+			},
+		},
+		{
+			desc:          "single router without named ports",
+			routerPerPort: true,
+			defaultRule:   "Host(`{{ normalize .Name }}.marathon.localhost`)",
+			applications: withApplications(application(
+				appID("/app"),
+				appPorts(80, 81),
+				withTasks(localhostTask(taskPorts(31000, 31001))),
+			)),
+			expectedRouters: map[string]*config.Router{
+				"app": {
+					Service: "app",
+					Rule:    "Host(`app.marathon.localhost`)",
+				},
+			},
+			expectedServices: map[string]*config.Service{
+				"app": server("http://localhost:31000"),
+			},
+		},
+		{
+			desc:          "labels take precedence over the router per named port",
+			routerPerPort: true,
+			defaultRule:   "Host(`{{ .PortName }}.{{ normalize .Name }}.marathon.localhost`)",
+			applications: withApplications(application(append(threePorts,
+				withLabel("traefik.http.services.app.loadbalancer.server.port", "name:admin"),
+			)...)),
+			expectedRouters: map[string]*config.Router{
+				"app": {
+					Service: "app",
+					Rule:    "Host(`.app.marathon.localhost`)",
+				},
+			},
+			expectedServices: map[string]*config.Service{
+				"app": server("http://localhost:31001"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := &Provider{
+				DefaultRule:      test.defaultRule,
+				ExposedByDefault: true,
+				RouterPerPort:    test.routerPerPort,
+			}
+
+			err := p.Init()
+			require.NoError(t, err)
+
+			conf := p.buildConfiguration(context.Background(), test.applications)
+			require.NotNil(t, conf)
+
+			assert.Equal(t, test.expectedRouters, conf.HTTP.Routers)
+			assert.Equal(t, test.expectedServices, conf.HTTP.Services)
+		})
+	}
+}
+
 func TestApplicationFilterEnabled(t *testing.T) {
 	testCases := []struct {
 		desc             string
@@ -1473,7 +2973,7 @@ func TestApplicationFilterEnabled(t *testing.T) {
 			extraConf, err := provider.getConfiguration(app)
 			require.NoError(t, err)
 
-			if provider.keepApplication(context.Background(), extraConf) != test.expected {
+			if provider.keepApplication(context.Background(), app, extraConf) != test.expected {
 				t.Errorf("got unexpected filtering = %t", !test.expected)
 			}
 		})
@@ -1644,6 +3144,106 @@ func TestGetServer(t *testing.T) {
 				error: `unable to process ports for /app taskID: strconv.Atoi: parsing "aaa": invalid syntax`,
 			},
 		},
+		{
+			desc:     "with port name on port definitions",
+			provider: Provider{},
+			app: application(
+				appID("/app"),
+				namedPortDefinition(80, "http"),
+				namedPortDefinition(81, "admin"),
+				withTasks(localhostTask(taskPorts(31000, 31001))),
+			),
+			extraConf: configuration{},
+			defaultServer: config.Server{
+				Scheme: "http",
+				Port:   "name:admin",
+			},
+			expected: expected{
+				server: config.Server{
+					URL: "http://localhost:31001",
+				},
+			},
+		},
+		{
+			desc:     "with port name on bridge network",
+			provider: Provider{},
+			app: application(
+				bridgeNetwork(),
+				appID("/app"),
+				portMapping(8080, "http"),
+				portMapping(9090, "admin"),
+				withTasks(localhostTask(taskPorts(31000, 31001))),
+			),
+			extraConf: configuration{},
+			defaultServer: config.Server{
+				Scheme: "http",
+				Port:   "name:admin",
+			},
+			expected: expected{
+				server: config.Server{
+					URL: "http://localhost:31001",
+				},
+			},
+		},
+		{
+			desc:     "with port name on container network",
+			provider: Provider{},
+			app: application(
+				containerNetwork(),
+				appID("/app"),
+				portMapping(8080, "http"),
+				portMapping(9090, "admin"),
+				withTasks(localhostTask()),
+			),
+			extraConf: configuration{},
+			defaultServer: config.Server{
+				Scheme: "http",
+				Port:   "name:admin",
+			},
+			expected: expected{
+				server: config.Server{
+					URL: "http://127.0.0.1:9090",
+				},
+			},
+		},
+		{
+			desc:     "with port name on IP per task",
+			provider: Provider{},
+			app: application(
+				appID("/app"),
+				ipAddrPerTask(88),
+				withTasks(localhostTask()),
+			),
+			extraConf: configuration{},
+			defaultServer: config.Server{
+				Scheme: "http",
+				Port:   "name:port",
+			},
+			expected: expected{
+				server: config.Server{
+					URL: "http://127.0.0.1:88",
+				},
+			},
+		},
+		{
+			desc:     "with unknown port name",
+			provider: Provider{},
+			app: application(
+				bridgeNetwork(),
+				appID("/app"),
+				portMapping(8080, "http"),
+				portMapping(9090, "admin"),
+				withTasks(localhostTask(taskPorts(31000, 31001))),
+			),
+			extraConf: configuration{},
+			defaultServer: config.Server{
+				Scheme: "http",
+				Port:   "name:metrics",
+			},
+			expected: expected{
+				error: `unable to process ports for /app taskID: name "metrics" must be within names (http, admin)`,
+			},
+		},
 		{
 			desc:     "with application port and no task port",
 			provider: Provider{},
@@ -1822,6 +3422,80 @@ func TestGetServer(t *testing.T) {
 				error: "missing IP address for Marathon application /app on task taskID",
 			},
 		},
+		{
+			desc:     "with Mesos-DNS suffix",
+			provider: Provider{DialerDNSSuffix: "marathon.mesos"},
+			app: application(
+				appID("/app"),
+				appPorts(80),
+				withTasks(localhostTask(taskPorts(31000))),
+			),
+			extraConf: configuration{},
+			defaultServer: config.Server{
+				Scheme: "http",
+			},
+			expected: expected{
+				server: config.Server{
+					URL: "http://app.marathon.mesos:31000",
+				},
+			},
+		},
+		{
+			desc:     "with Mesos-DNS suffix and groups",
+			provider: Provider{DialerDNSSuffix: "marathon.mesos"},
+			app: application(
+				appID("/group/subgroup/app"),
+				appPorts(80),
+				withTasks(localhostTask(taskPorts(31000))),
+			),
+			extraConf: configuration{},
+			defaultServer: config.Server{
+				Scheme: "http",
+			},
+			expected: expected{
+				server: config.Server{
+					URL: "http://app-subgroup-group.marathon.mesos:31000",
+				},
+			},
+		},
+		{
+			desc:     "with Mesos-DNS suffix and IP per task",
+			provider: Provider{DialerDNSSuffix: "marathon.mesos"},
+			app: application(
+				appID("/app"),
+				appPorts(80),
+				ipAddrPerTask(88),
+				withTasks(localhostTask()),
+			),
+			extraConf: configuration{},
+			defaultServer: config.Server{
+				Scheme: "http",
+			},
+			expected: expected{
+				server: config.Server{
+					URL: "http://127.0.0.1:88",
+				},
+			},
+		},
+		{
+			desc:     "with Mesos-DNS suffix and container network",
+			provider: Provider{DialerDNSSuffix: "marathon.mesos"},
+			app: application(
+				containerNetwork(),
+				appID("/app"),
+				appPorts(80),
+				withTasks(localhostTask(taskPorts(80, 81))),
+			),
+			extraConf: configuration{},
+			defaultServer: config.Server{
+				Scheme: "http",
+			},
+			expected: expected{
+				server: config.Server{
+					URL: "http://127.0.0.1:80",
+				},
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -1845,3 +3519,11 @@ func TestGetServer(t *testing.T) {
 		})
 	}
 }
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+func intPtr(value int) *int {
+	return &value
+}
@@ -0,0 +1,54 @@
+package marathon
+
+import (
+	"sync"
+	"time"
+)
+
+// drainingTasks remembers, per task ID, since when a task is being killed,
+// so that its server is kept while it drains its connections.
+type drainingTasks struct {
+	mu       sync.Mutex
+	killedAt map[string]time.Time
+}
+
+func newDrainingTasks() *drainingTasks {
+	return &drainingTasks{
+		killedAt: make(map[string]time.Time),
+	}
+}
+
+// draining tells whether the killed task is still within its draining period.
+// The task is considered killed since the first time it is seen killing.
+func (d *drainingTasks) draining(taskID string, period time.Duration, now time.Time) bool {
+	if d == nil {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	killedAt, ok := d.killedAt[taskID]
+	if !ok {
+		killedAt = now
+		d.killedAt[taskID] = now
+	}
+
+	return now.Sub(killedAt) < period
+}
+
+// prune forgets the tasks which are no longer known by Marathon.
+func (d *drainingTasks) prune(taskIDs map[string]struct{}) {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for taskID := range d.killedAt {
+		if _, ok := taskIDs[taskID]; !ok {
+			delete(d.killedAt, taskID)
+		}
+	}
+}
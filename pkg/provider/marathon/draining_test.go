@@ -0,0 +1,118 @@
+package marathon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/gambol99/go-marathon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrainingTasks(t *testing.T) {
+	now := time.Now()
+
+	d := newDrainingTasks()
+
+	assert.True(t, d.draining("task-1", time.Minute, now))
+	assert.True(t, d.draining("task-1", time.Minute, now.Add(30*time.Second)))
+	assert.False(t, d.draining("task-1", time.Minute, now.Add(time.Minute)))
+
+	// The kill time is kept until the task disappears.
+	d.prune(map[string]struct{}{"task-1": {}})
+	assert.False(t, d.draining("task-1", time.Minute, now.Add(2*time.Minute)))
+
+	d.prune(nil)
+	assert.True(t, d.draining("task-1", time.Minute, now.Add(2*time.Minute)))
+}
+
+func TestDrainingTasksDisabled(t *testing.T) {
+	var d *drainingTasks
+
+	d.prune(nil)
+	assert.False(t, d.draining("task-1", time.Minute, time.Now()))
+}
+
+func TestBuildConfigurationDrainingTasks(t *testing.T) {
+	p := &Provider{
+		DefaultRule:      "Host(`{{ normalize .Name }}.marathon.localhost`)",
+		ExposedByDefault: true,
+	}
+
+	clock := newFakeClock()
+	p.clock = clock
+
+	err := p.Init()
+	require.NoError(t, err)
+
+	withTaskStates := func(drainSeconds string, states map[string]TaskState) *marathon.Applications {
+		var tasks []marathon.Task
+		for _, ip := range []string{"10.0.0.1", "10.0.0.2"} {
+			if state, ok := states[ip]; ok {
+				tasks = append(tasks, task(withTaskID(ip), host(ip), taskPorts(80), taskState(state), healthCheckResultLiveness(state == taskStateRunning)))
+			}
+		}
+		return withApplications(
+			application(
+				appID("/app"),
+				appPorts(80),
+				withLabel("traefik.marathon.drainSeconds", drainSeconds),
+				healthChecks(),
+				withTasks(tasks...),
+			))
+	}
+
+	serverURLs := func(conf *config.Configuration) []string {
+		var urls []string
+		if service, ok := conf.HTTP.Services["app"]; ok {
+			for _, server := range service.LoadBalancer.Servers {
+				urls = append(urls, server.URL)
+			}
+		}
+		return urls
+	}
+
+	conf := p.buildConfiguration(context.Background(), withTaskStates("30", map[string]TaskState{
+		"10.0.0.1": taskStateRunning,
+		"10.0.0.2": taskStateRunning,
+	}))
+	assert.Equal(t, []string{"http://10.0.0.1:80", "http://10.0.0.2:80"}, serverURLs(conf))
+
+	// The killed task is kept while it drains, even though it is no longer healthy.
+	conf = p.buildConfiguration(context.Background(), withTaskStates("30", map[string]TaskState{
+		"10.0.0.1": taskStateKilling,
+		"10.0.0.2": taskStateRunning,
+	}))
+	assert.Equal(t, []string{"http://10.0.0.1:80", "http://10.0.0.2:80"}, serverURLs(conf))
+
+	clock.Advance(29 * time.Second)
+	conf = p.buildConfiguration(context.Background(), withTaskStates("30", map[string]TaskState{
+		"10.0.0.1": taskStateKilling,
+		"10.0.0.2": taskStateRunning,
+	}))
+	assert.Equal(t, []string{"http://10.0.0.1:80", "http://10.0.0.2:80"}, serverURLs(conf))
+
+	// The draining period is over.
+	clock.Advance(time.Second)
+	conf = p.buildConfiguration(context.Background(), withTaskStates("30", map[string]TaskState{
+		"10.0.0.1": taskStateKilling,
+		"10.0.0.2": taskStateRunning,
+	}))
+	assert.Equal(t, []string{"http://10.0.0.2:80"}, serverURLs(conf))
+
+	// The task is gone, and its kill time is forgotten.
+	conf = p.buildConfiguration(context.Background(), withTaskStates("30", map[string]TaskState{
+		"10.0.0.2": taskStateRunning,
+	}))
+	assert.Equal(t, []string{"http://10.0.0.2:80"}, serverURLs(conf))
+	assert.Empty(t, p.drainingTasks.killedAt)
+
+	// Without the label, a killed task is dropped right away.
+	conf = p.buildConfiguration(context.Background(), withTaskStates("0", map[string]TaskState{
+		"10.0.0.1": taskStateRunning,
+		"10.0.0.2": taskStateKilling,
+	}))
+	assert.Equal(t, []string{"http://10.0.0.1:80"}, serverURLs(conf))
+}
@@ -2,6 +2,10 @@ package marathon
 
 import (
 	"errors"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
 
 	"github.com/containous/traefik/pkg/provider/marathon/mocks"
 	"github.com/gambol99/go-marathon"
@@ -22,3 +26,145 @@ func newFakeClient(applicationsError bool, applications marathon.Applications) *
 	}
 	return fakeClient
 }
+
+// fakeClock is a clock which only moves forward when advanced by the tests.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+type fakeWaiter struct {
+	duration time.Duration
+	deadline time.Time
+	c        chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := &fakeWaiter{duration: d, deadline: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.c <- c.now
+		return w.c
+	}
+
+	c.waiters = append(c.waiters, w)
+	return w.c
+}
+
+// Advance moves the clock forward, and wakes the waiters which are due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	var waiters []*fakeWaiter
+	for _, w := range c.waiters {
+		if c.now.Before(w.deadline) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.c <- c.now
+	}
+	c.waiters = waiters
+}
+
+// waitForWaiter waits for someone to wait on the clock, and returns the duration they wait for.
+func (c *fakeClock) waitForWaiter(t *testing.T) time.Duration {
+	t.Helper()
+
+	return c.waitForWaiters(t, 1)[0]
+}
+
+// waitForWaiters waits for at least n waiters on the clock, and returns the durations they wait for, in order of arrival.
+func (c *fakeClock) waitForWaiters(t *testing.T, n int) []time.Duration {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c.mu.Lock()
+		if len(c.waiters) >= n {
+			var durations []time.Duration
+			for _, w := range c.waiters {
+				durations = append(durations, w.duration)
+			}
+			c.mu.Unlock()
+			return durations
+		}
+		c.mu.Unlock()
+
+		if time.Now().After(deadline) {
+			t.Fatal("Timeout while waiting for a waiter on the clock")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// snapshot is the state of the Marathon applications from a given point in time.
+type snapshot struct {
+	at           time.Duration
+	applications *marathon.Applications
+	pods         []*marathon.PodStatus
+	err          error
+}
+
+// scriptedClient is a fake client serving sequences of application snapshots over the (fake) time.
+type scriptedClient struct {
+	mocks.Marathon
+
+	clock     *fakeClock
+	start     time.Time
+	snapshots []snapshot
+}
+
+func newScriptedClient(clock *fakeClock, snapshots ...snapshot) *scriptedClient {
+	return &scriptedClient{
+		clock:     clock,
+		start:     clock.Now(),
+		snapshots: snapshots,
+	}
+}
+
+// Applications returns the applications of the latest snapshot taken before the current time.
+func (c *scriptedClient) Applications(url.Values) (*marathon.Applications, error) {
+	current := c.current()
+	if current == nil {
+		return nil, errors.New("no snapshot yet")
+	}
+	return current.applications, current.err
+}
+
+// PodStatuses returns the pods of the latest snapshot taken before the current time.
+func (c *scriptedClient) PodStatuses() ([]*marathon.PodStatus, error) {
+	current := c.current()
+	if current == nil {
+		return nil, errors.New("no snapshot yet")
+	}
+	return current.pods, current.err
+}
+
+func (c *scriptedClient) current() *snapshot {
+	elapsed := c.clock.Now().Sub(c.start)
+
+	var current *snapshot
+	for i := range c.snapshots {
+		if c.snapshots[i].at <= elapsed {
+			current = &c.snapshots[i]
+		}
+	}
+	return current
+}
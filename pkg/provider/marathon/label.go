@@ -1,6 +1,7 @@
 package marathon
 
 import (
+	"fmt"
 	"math"
 	"strings"
 
@@ -16,6 +17,8 @@ type configuration struct {
 
 type specificConfiguration struct {
 	IPAddressIdx int
+	DrainSeconds int
+	Weight       *int
 }
 
 func (p *Provider) getConfiguration(app marathon.Application) (configuration, error) {
@@ -34,6 +37,10 @@ func (p *Provider) getConfiguration(app marathon.Application) (configuration, er
 		return configuration{}, err
 	}
 
+	if conf.Marathon.Weight != nil && *conf.Marathon.Weight < 0 {
+		return configuration{}, fmt.Errorf("invalid weight %d: must be positive or zero", *conf.Marathon.Weight)
+	}
+
 	if p.FilterMarathonConstraints && app.Constraints != nil {
 		for _, constraintParts := range *app.Constraints {
 			conf.Tags = append(conf.Tags, strings.Join(constraintParts, ":"))
@@ -154,6 +154,27 @@ func TestGetConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "Use weight",
+			app: marathon.Application{
+				Constraints: &[][]string{},
+				Labels: &map[string]string{
+					"traefik.marathon.weight": "10",
+				},
+			},
+			p: Provider{
+				ExposedByDefault:          true,
+				FilterMarathonConstraints: false,
+			},
+			expected: configuration{
+				Enable: true,
+				Tags:   nil,
+				Marathon: specificConfiguration{
+					IPAddressIdx: math.MinInt32,
+					Weight:       intPtr(10),
+				},
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -168,3 +189,14 @@ func TestGetConfiguration(t *testing.T) {
 		})
 	}
 }
+
+func TestGetConfigurationNegativeWeight(t *testing.T) {
+	p := Provider{ExposedByDefault: true}
+
+	_, err := p.getConfiguration(marathon.Application{
+		Labels: &map[string]string{
+			"traefik.marathon.weight": "-1",
+		},
+	})
+	assert.Error(t, err)
+}
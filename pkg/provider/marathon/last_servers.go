@@ -0,0 +1,153 @@
+package marathon
+
+import (
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+)
+
+// lastServers remembers, per application ID, the servers last built for each of its services.
+// It is used to bridge short periods during which Marathon reports no healthy task for an application
+// (e.g. during a master failover), instead of removing and re-adding the servers.
+type lastServers struct {
+	mu           sync.Mutex
+	maxStaleness time.Duration
+	apps         map[string]*appServers
+}
+
+type appServers struct {
+	http map[string]httpServers
+	tcp  map[string]tcpServers
+}
+
+type httpServers struct {
+	servers []config.Server
+	seenAt  time.Time
+}
+
+type tcpServers struct {
+	servers []config.TCPServer
+	seenAt  time.Time
+}
+
+func newLastServers(maxStaleness time.Duration) *lastServers {
+	return &lastServers{
+		maxStaleness: maxStaleness,
+		apps:         make(map[string]*appServers),
+	}
+}
+
+func (l *lastServers) getApp(appID string) *appServers {
+	app, ok := l.apps[appID]
+	if !ok {
+		app = &appServers{
+			http: make(map[string]httpServers),
+			tcp:  make(map[string]tcpServers),
+		}
+		l.apps[appID] = app
+	}
+	return app
+}
+
+// storeHTTP records the fresh servers of an HTTP service.
+func (l *lastServers) storeHTTP(appID, serviceName string, servers []config.Server, now time.Time) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.getApp(appID).http[serviceName] = httpServers{servers: servers, seenAt: now}
+}
+
+// loadHTTP returns the last known servers of an HTTP service, and since when they are stale.
+// Servers older than the maximum staleness are forgotten.
+func (l *lastServers) loadHTTP(appID, serviceName string, now time.Time) ([]config.Server, time.Duration, bool) {
+	if l == nil {
+		return nil, 0, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	app, ok := l.apps[appID]
+	if !ok {
+		return nil, 0, false
+	}
+
+	last, ok := app.http[serviceName]
+	if !ok {
+		return nil, 0, false
+	}
+
+	staleness := now.Sub(last.seenAt)
+	if staleness > l.maxStaleness {
+		delete(app.http, serviceName)
+		return nil, staleness, false
+	}
+
+	servers := make([]config.Server, len(last.servers))
+	copy(servers, last.servers)
+	return servers, staleness, true
+}
+
+// storeTCP records the fresh servers of a TCP service.
+func (l *lastServers) storeTCP(appID, serviceName string, servers []config.TCPServer, now time.Time) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.getApp(appID).tcp[serviceName] = tcpServers{servers: servers, seenAt: now}
+}
+
+// loadTCP returns the last known servers of a TCP service, and since when they are stale.
+// Servers older than the maximum staleness are forgotten.
+func (l *lastServers) loadTCP(appID, serviceName string, now time.Time) ([]config.TCPServer, time.Duration, bool) {
+	if l == nil {
+		return nil, 0, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	app, ok := l.apps[appID]
+	if !ok {
+		return nil, 0, false
+	}
+
+	last, ok := app.tcp[serviceName]
+	if !ok {
+		return nil, 0, false
+	}
+
+	staleness := now.Sub(last.seenAt)
+	if staleness > l.maxStaleness {
+		delete(app.tcp, serviceName)
+		return nil, staleness, false
+	}
+
+	servers := make([]config.TCPServer, len(last.servers))
+	copy(servers, last.servers)
+	return servers, staleness, true
+}
+
+// prune forgets the applications which are no longer known by Marathon.
+func (l *lastServers) prune(appIDs map[string]struct{}) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for appID := range l.apps {
+		if _, ok := appIDs[appID]; !ok {
+			delete(l.apps, appID)
+		}
+	}
+}
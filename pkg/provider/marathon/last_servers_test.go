@@ -0,0 +1,317 @@
+package marathon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/types"
+	"github.com/gambol99/go-marathon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastServers(t *testing.T) {
+	now := time.Now()
+	servers := []config.Server{{URL: "http://10.0.0.1:80"}}
+
+	testCases := []struct {
+		desc              string
+		storedAt          time.Time
+		loadedAt          time.Time
+		expectedServers   []config.Server
+		expectedStaleness time.Duration
+		expectedOK        bool
+	}{
+		{
+			desc:              "fresh servers",
+			storedAt:          now,
+			loadedAt:          now,
+			expectedServers:   servers,
+			expectedStaleness: 0,
+			expectedOK:        true,
+		},
+		{
+			desc:              "stale servers within the max staleness",
+			storedAt:          now,
+			loadedAt:          now.Add(30 * time.Second),
+			expectedServers:   servers,
+			expectedStaleness: 30 * time.Second,
+			expectedOK:        true,
+		},
+		{
+			desc:              "stale servers beyond the max staleness",
+			storedAt:          now,
+			loadedAt:          now.Add(2 * time.Minute),
+			expectedStaleness: 2 * time.Minute,
+			expectedOK:        false,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			l := newLastServers(time.Minute)
+			l.storeHTTP("/app", "app", servers, test.storedAt)
+
+			actual, staleness, ok := l.loadHTTP("/app", "app", test.loadedAt)
+			assert.Equal(t, test.expectedOK, ok)
+			assert.Equal(t, test.expectedStaleness, staleness)
+			assert.Equal(t, test.expectedServers, actual)
+
+			if !test.expectedOK {
+				_, _, ok = l.loadHTTP("/app", "app", test.storedAt)
+				assert.False(t, ok, "expired servers should have been forgotten")
+			}
+		})
+	}
+}
+
+func TestLastServersDisabled(t *testing.T) {
+	var l *lastServers
+
+	l.storeHTTP("/app", "app", []config.Server{{URL: "http://10.0.0.1:80"}}, time.Now())
+	l.storeTCP("/app", "app", []config.TCPServer{{Address: "10.0.0.1:80"}}, time.Now())
+	l.prune(nil)
+
+	_, _, ok := l.loadHTTP("/app", "app", time.Now())
+	assert.False(t, ok)
+
+	_, _, ok = l.loadTCP("/app", "app", time.Now())
+	assert.False(t, ok)
+}
+
+func TestLastServersPrune(t *testing.T) {
+	l := newLastServers(time.Minute)
+	l.storeHTTP("/app", "app", []config.Server{{URL: "http://10.0.0.1:80"}}, time.Now())
+	l.storeTCP("/other", "other", []config.TCPServer{{Address: "10.0.0.2:80"}}, time.Now())
+
+	l.prune(map[string]struct{}{"/app": {}})
+
+	_, _, ok := l.loadHTTP("/app", "app", time.Now())
+	assert.True(t, ok)
+
+	_, _, ok = l.loadTCP("/other", "other", time.Now())
+	assert.False(t, ok)
+}
+
+func TestBuildConfigurationKeepLastServersOnEmpty(t *testing.T) {
+	p := &Provider{
+		DefaultRule:             "Host(`{{ normalize .Name }}.marathon.localhost`)",
+		ExposedByDefault:        true,
+		KeepLastServersOnEmpty:  true,
+		LastServersMaxStaleness: types.Duration(time.Minute),
+	}
+
+	clock := newFakeClock()
+	p.clock = clock
+
+	err := p.Init()
+	require.NoError(t, err)
+
+	withServers := func(ips ...string) *marathon.Applications {
+		var tasks []marathon.Task
+		for _, ip := range ips {
+			tasks = append(tasks, task(withTaskID(ip), host(ip), taskPorts(80)))
+		}
+		return withApplications(
+			application(
+				appID("/app"),
+				appPorts(80),
+				withLabel("traefik.tcp.routers.app.rule", "HostSNI(`app.marathon.localhost`)"),
+				withLabel("traefik.http.routers.app.rule", "Host(`app.marathon.localhost`)"),
+				withTasks(tasks...),
+			))
+	}
+
+	assertServers := func(t *testing.T, conf *config.Configuration, expected ...string) {
+		t.Helper()
+
+		if len(expected) == 0 {
+			assert.Empty(t, conf.HTTP.Services)
+			assert.Empty(t, conf.TCP.Services)
+			return
+		}
+
+		require.Contains(t, conf.HTTP.Services, "app")
+		require.Contains(t, conf.TCP.Services, "app")
+
+		var urls, addresses []string
+		for _, server := range conf.HTTP.Services["app"].LoadBalancer.Servers {
+			urls = append(urls, server.URL)
+		}
+		for _, server := range conf.TCP.Services["app"].LoadBalancer.Servers {
+			addresses = append(addresses, server.Address)
+		}
+
+		var expectedURLs, expectedAddresses []string
+		for _, ip := range expected {
+			expectedURLs = append(expectedURLs, "http://"+ip+":80")
+			expectedAddresses = append(expectedAddresses, ip+":80")
+		}
+
+		assert.Equal(t, expectedURLs, urls)
+		assert.Equal(t, expectedAddresses, addresses)
+	}
+
+	// Servers are known.
+	conf := p.buildConfiguration(context.Background(), withServers("10.0.0.1", "10.0.0.2"))
+	assertServers(t, conf, "10.0.0.1", "10.0.0.2")
+
+	// Marathon suddenly reports no task: the last known servers are kept.
+	conf = p.buildConfiguration(context.Background(), withServers())
+	assertServers(t, conf, "10.0.0.1", "10.0.0.2")
+
+	// Fresh tasks appear again: they replace the last known servers.
+	conf = p.buildConfiguration(context.Background(), withServers("10.0.0.3"))
+	assertServers(t, conf, "10.0.0.3")
+
+	// The last known servers expire.
+	clock.Advance(2 * time.Minute)
+
+	conf = p.buildConfiguration(context.Background(), withServers())
+	assertServers(t, conf)
+}
+
+func TestGetConfigurationsLastServersOverTime(t *testing.T) {
+	p := &Provider{
+		DefaultRule:             "Host(`{{ normalize .Name }}.marathon.localhost`)",
+		ExposedByDefault:        true,
+		KeepLastServersOnEmpty:  true,
+		LastServersMaxStaleness: types.Duration(time.Minute),
+	}
+
+	clock := newFakeClock()
+	p.clock = clock
+
+	err := p.Init()
+	require.NoError(t, err)
+
+	app := func(tasks ...marathon.Task) *marathon.Applications {
+		return withApplications(application(appID("/app"), appPorts(80), withTasks(tasks...)))
+	}
+
+	p.marathonClient = newScriptedClient(clock,
+		snapshot{at: 0, applications: app(localhostTask(taskPorts(80)))},
+		// The tasks become unreachable.
+		snapshot{at: 20 * time.Second, applications: app()},
+	)
+
+	testCases := []struct {
+		desc            string
+		at              time.Duration
+		expectedServers []config.Server
+	}{
+		{
+			desc:            "healthy tasks",
+			at:              0,
+			expectedServers: []config.Server{{URL: "http://localhost:80"}},
+		},
+		{
+			desc:            "unreachable tasks within the grace period",
+			at:              50 * time.Second,
+			expectedServers: []config.Server{{URL: "http://localhost:80", Stale: true}},
+		},
+		{
+			desc: "unreachable tasks beyond the grace period",
+			at:   61 * time.Second,
+		},
+	}
+
+	var elapsed time.Duration
+	for _, test := range testCases {
+		clock.Advance(test.at - elapsed)
+		elapsed = test.at
+
+		conf := p.getConfigurations(context.Background())
+		require.NotNil(t, conf, test.desc)
+
+		if test.expectedServers == nil {
+			assert.Empty(t, conf.HTTP.Services, test.desc)
+			continue
+		}
+
+		require.Contains(t, conf.HTTP.Services, "app", test.desc)
+		assert.Equal(t, test.expectedServers, conf.HTTP.Services["app"].LoadBalancer.Servers, test.desc)
+	}
+}
+
+func TestBuildConfigurationKeepConfigDuringDeployment(t *testing.T) {
+	p := &Provider{
+		DefaultRule:                "Host(`{{ normalize .Name }}.marathon.localhost`)",
+		ExposedByDefault:           true,
+		KeepConfigDuringDeployment: true,
+	}
+
+	clock := newFakeClock()
+	p.clock = clock
+
+	err := p.Init()
+	require.NoError(t, err)
+
+	withServers := func(deploymentIDs []string, ips ...string) *marathon.Applications {
+		var tasks []marathon.Task
+		for _, ip := range ips {
+			tasks = append(tasks, task(withTaskID(ip), host(ip), taskPorts(80)))
+		}
+		return withApplications(
+			application(
+				appID("/app"),
+				appPorts(80),
+				deployments(deploymentIDs...),
+				withTasks(tasks...),
+			))
+	}
+
+	assertServers := func(t *testing.T, conf *config.Configuration, expected ...string) {
+		t.Helper()
+
+		if len(expected) == 0 {
+			assert.Empty(t, conf.HTTP.Services)
+			return
+		}
+
+		require.Contains(t, conf.HTTP.Services, "app")
+
+		var urls []string
+		for _, server := range conf.HTTP.Services["app"].LoadBalancer.Servers {
+			urls = append(urls, server.URL)
+		}
+
+		var expectedURLs []string
+		for _, ip := range expected {
+			expectedURLs = append(expectedURLs, "http://"+ip+":80")
+		}
+
+		assert.Equal(t, expectedURLs, urls)
+	}
+
+	// No deployment.
+	conf := p.buildConfiguration(context.Background(), withServers(nil, "10.0.0.1", "10.0.0.2"))
+	assertServers(t, conf, "10.0.0.1", "10.0.0.2")
+
+	// A deployment starts, and the tasks are replaced: the servers from before the deployment are kept.
+	conf = p.buildConfiguration(context.Background(), withServers([]string{"deployment-1"}, "10.0.0.2", "10.0.0.3"))
+	assertServers(t, conf, "10.0.0.1", "10.0.0.2")
+
+	conf = p.buildConfiguration(context.Background(), withServers([]string{"deployment-1"}))
+	assertServers(t, conf, "10.0.0.1", "10.0.0.2")
+
+	// The deployment is over.
+	conf = p.buildConfiguration(context.Background(), withServers(nil, "10.0.0.3", "10.0.0.4"))
+	assertServers(t, conf, "10.0.0.3", "10.0.0.4")
+
+	// An application deployed for the first time has no servers to keep.
+	conf = p.buildConfiguration(context.Background(), withApplications(
+		application(
+			appID("/other"),
+			appPorts(80),
+			deployments("deployment-2"),
+			withTasks(task(withTaskID("10.0.0.5"), host("10.0.0.5"), taskPorts(80))),
+		)))
+	require.Contains(t, conf.HTTP.Services, "other")
+	assert.Equal(t, []config.Server{{URL: "http://10.0.0.5:80"}}, conf.HTTP.Services["other"].LoadBalancer.Servers)
+}
@@ -0,0 +1,208 @@
+package marathon
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/pkg/log"
+	"github.com/gambol99/go-marathon"
+)
+
+// leaderClient pins the requests to the leading Marathon master,
+// as the other masters may answer with stale data after a failover.
+// When the requests fail, it can also pin them to each of the configured endpoints in turn.
+type leaderClient struct {
+	mu              sync.RWMutex
+	clock           clock
+	config          marathon.Config
+	newClient       func(config marathon.Config) (marathon.Marathon, error)
+	endpoints       marathon.Marathon
+	endpointURLs    []string
+	endpointClients []marathon.Marathon
+	endpointIdx     int
+	client          marathon.Marathon
+	current         string
+	leader          string
+	verifiedAt      time.Time
+}
+
+// newLeaderClient creates the clients of the leading master and of the configured endpoints with newClient,
+// from the given configuration, so that they share its transport, e.g. the TLS and the authentication settings.
+func newLeaderClient(config marathon.Config, endpoints marathon.Marathon, newClient func(config marathon.Config) (marathon.Marathon, error), clk clock) *leaderClient {
+	var endpointURLs []string
+	var scheme string
+	for _, endpoint := range strings.Split(config.URL, ",") {
+		endpoint = strings.TrimSpace(endpoint)
+
+		// As for the Marathon client, the endpoints after the first one may omit the scheme.
+		if i := strings.Index(endpoint, "://"); i > 0 {
+			if len(scheme) == 0 {
+				scheme = endpoint[:i]
+			}
+		} else if len(scheme) > 0 {
+			endpoint = scheme + "://" + endpoint
+		}
+
+		endpointURLs = append(endpointURLs, endpoint)
+	}
+
+	return &leaderClient{
+		clock:           clk,
+		config:          config,
+		newClient:       newClient,
+		endpoints:       endpoints,
+		endpointURLs:    endpointURLs,
+		endpointClients: make([]marathon.Marathon, len(endpointURLs)),
+		client:          endpoints,
+		current:         config.URL,
+	}
+}
+
+// resolve asks the configured endpoints for the leading master, and pins the subsequent requests to it.
+// If the leader cannot be resolved, the requests fall back to the configured endpoints.
+func (l *leaderClient) resolve(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	client, leader, err := l.leaderMasterClient()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err != nil {
+		endpoint := l.endpointURLs[l.endpointIdx]
+		logger.Warnf("Unable to resolve the leading Marathon master, using the configured endpoint %s: %v", endpoint, err)
+
+		l.leader = ""
+		l.verifiedAt = time.Time{}
+
+		client, err = l.endpointClient(l.endpointIdx)
+		if err != nil {
+			logger.Errorf("Unable to create a client for the Marathon endpoint %s, using all the configured endpoints: %v", endpoint, err)
+			l.client = l.endpoints
+			l.current = l.config.URL
+			return
+		}
+
+		l.client = client
+		l.current = endpoint
+		return
+	}
+
+	logger.Debugf("Using the leading Marathon master %s", leader)
+
+	l.client = client
+	l.leader = leader
+	l.current = leader
+	l.verifiedAt = l.clock.Now()
+}
+
+// next pins the requests to the configured endpoint following the one last pinned, and returns its URL.
+func (l *leaderClient) next(ctx context.Context) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.endpointIdx = (l.endpointIdx + 1) % len(l.endpointURLs)
+	endpoint := l.endpointURLs[l.endpointIdx]
+
+	client, err := l.endpointClient(l.endpointIdx)
+	if err != nil {
+		return endpoint, err
+	}
+
+	log.FromContext(ctx).Infof("Using the Marathon endpoint %s", endpoint)
+
+	l.client = client
+	l.current = endpoint
+	l.leader = ""
+	l.verifiedAt = time.Time{}
+
+	return endpoint, nil
+}
+
+// endpointClient returns the client of the i-th configured endpoint, creating it if needed.
+// It must be called with the lock held.
+func (l *leaderClient) endpointClient(i int) (marathon.Marathon, error) {
+	if len(l.endpointURLs) == 1 {
+		return l.endpoints, nil
+	}
+
+	if l.endpointClients[i] == nil {
+		config := l.config
+		config.URL = l.endpointURLs[i]
+
+		client, err := l.newClient(config)
+		if err != nil {
+			return nil, err
+		}
+		l.endpointClients[i] = client
+	}
+
+	return l.endpointClients[i], nil
+}
+
+// size returns the number of configured endpoints.
+func (l *leaderClient) size() int {
+	return len(l.endpointURLs)
+}
+
+// leaderMasterClient asks the configured endpoints for the leading master, and returns a client to it, along with its URL.
+func (l *leaderClient) leaderMasterClient() (marathon.Marathon, string, error) {
+	endpoint, err := url.Parse(l.endpointURLs[0])
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Endpoints with a path (e.g. the DC/OS admin router) are proxies which already forward the requests to the leader.
+	if strings.Trim(endpoint.Path, "/") != "" || len(l.config.DCOSToken) > 0 {
+		return nil, "", fmt.Errorf("endpoint %s is a proxy", endpoint)
+	}
+
+	leader, err := l.endpoints.Leader()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(leader) == 0 {
+		return nil, "", fmt.Errorf("no leader")
+	}
+
+	config := l.config
+	config.URL = fmt.Sprintf("%s://%s", endpoint.Scheme, leader)
+
+	client, err := l.newClient(config)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return client, config.URL, nil
+}
+
+// get returns the client to use for the requests.
+func (l *leaderClient) get() marathon.Marathon {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.client
+}
+
+// endpoint returns the URL the requests are sent to: the leading master, a configured endpoint,
+// or the list of the configured endpoints.
+func (l *leaderClient) endpoint() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.current
+}
+
+// status returns the URL of the leading master the requests are pinned to, and when it was last verified.
+// An empty URL means that the requests are sent to the configured endpoints.
+func (l *leaderClient) status() (string, time.Time) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.leader, l.verifiedAt
+}
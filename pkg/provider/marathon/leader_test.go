@@ -0,0 +1,269 @@
+package marathon
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gambol99/go-marathon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMaster is a fake Marathon master serving a single application, and pointing to a leader.
+// It can also be made to fail, or to redirect, the requests for the applications.
+type fakeMaster struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	appID    string
+	leader   *fakeMaster
+	failing  bool
+	redirect *fakeMaster
+}
+
+func newFakeMaster(appID string) *fakeMaster {
+	master := &fakeMaster{appID: appID}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/leader", func(rw http.ResponseWriter, req *http.Request) {
+		master.mu.Lock()
+		defer master.mu.Unlock()
+
+		if master.leader == nil {
+			http.Error(rw, "no leader", http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(rw, `{"leader": %q}`, strings.TrimPrefix(master.leader.URL, "http://"))
+	})
+	mux.HandleFunc("/v2/apps", func(rw http.ResponseWriter, req *http.Request) {
+		master.mu.Lock()
+		defer master.mu.Unlock()
+
+		if master.failing {
+			http.Error(rw, "leader unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if master.redirect != nil {
+			http.Redirect(rw, req, master.redirect.URL+req.URL.RequestURI(), http.StatusTemporaryRedirect)
+			return
+		}
+		fmt.Fprintf(rw, `{"apps": [{"id": %q, "ports": [80], "tasks": [{"id": "task", "host": "localhost", "ports": [80], "state": "TASK_RUNNING"}]}]}`, master.appID)
+	})
+	master.Server = httptest.NewServer(mux)
+
+	return master
+}
+
+func (m *fakeMaster) setLeader(leader *fakeMaster) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.leader = leader
+}
+
+func (m *fakeMaster) setFailing(failing bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.failing = failing
+}
+
+func (m *fakeMaster) setRedirect(redirect *fakeMaster) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.redirect = redirect
+}
+
+func newTestLeaderProvider(t *testing.T, endpoint string) *Provider {
+	t.Helper()
+
+	p := &Provider{
+		DefaultRule:      DefaultTemplateRule,
+		ExposedByDefault: true,
+	}
+	err := p.Init()
+	require.NoError(t, err)
+
+	confg := marathon.NewDefaultConfig()
+	confg.URL = endpoint
+	client, err := p.newClient(confg)
+	require.NoError(t, err)
+
+	p.marathonClient = client
+	p.leader = newLeaderClient(confg, client, p.newClient, p.clock)
+	p.leader.resolve(context.Background())
+
+	return p
+}
+
+func getRouterNames(t *testing.T, p *Provider) []string {
+	t.Helper()
+
+	conf := p.getConfigurations(context.Background())
+	require.NotNil(t, conf)
+
+	var names []string
+	for name := range conf.HTTP.Routers {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestLeaderDiscovery(t *testing.T) {
+	follower := newFakeMaster("/stale")
+	defer follower.Close()
+	leader := newFakeMaster("/fresh")
+	defer leader.Close()
+
+	follower.setLeader(leader)
+
+	p := newTestLeaderProvider(t, follower.URL)
+
+	leaderURL, verifiedAt := p.Leader()
+	assert.Equal(t, leader.URL, leaderURL)
+	assert.False(t, verifiedAt.IsZero())
+
+	assert.Equal(t, []string{"fresh"}, getRouterNames(t, p))
+}
+
+func TestLeaderClientFactory(t *testing.T) {
+	follower := newFakeMaster("/stale")
+	defer follower.Close()
+	leader := newFakeMaster("/fresh")
+	defer leader.Close()
+
+	follower.setLeader(leader)
+
+	p := &Provider{
+		DefaultRule:      DefaultTemplateRule,
+		ExposedByDefault: true,
+	}
+	err := p.Init()
+	require.NoError(t, err)
+
+	var configs []marathon.Config
+	p.newClient = func(config marathon.Config) (marathon.Marathon, error) {
+		configs = append(configs, config)
+		return marathon.NewClient(config)
+	}
+
+	confg := marathon.NewDefaultConfig()
+	confg.URL = follower.URL
+	confg.HTTPBasicAuthUser = "user"
+	confg.HTTPBasicPassword = "password"
+
+	client, err := p.newClient(confg)
+	require.NoError(t, err)
+
+	p.marathonClient = client
+	p.leader = newLeaderClient(confg, client, p.newClient, p.clock)
+	p.leader.resolve(context.Background())
+
+	// The client of the leading master is created by the factory of the provider, with the same settings.
+	require.Len(t, configs, 2)
+	assert.Equal(t, leader.URL, configs[1].URL)
+	assert.Equal(t, "user", configs[1].HTTPBasicAuthUser)
+	assert.Equal(t, "password", configs[1].HTTPBasicPassword)
+
+	assert.Equal(t, []string{"fresh"}, getRouterNames(t, p))
+}
+
+func TestLeaderFailover(t *testing.T) {
+	follower := newFakeMaster("/stale")
+	defer follower.Close()
+	oldLeader := newFakeMaster("/old")
+	newLeader := newFakeMaster("/new")
+	defer newLeader.Close()
+
+	follower.setLeader(oldLeader)
+
+	p := newTestLeaderProvider(t, follower.URL)
+	assert.Equal(t, []string{"old"}, getRouterNames(t, p))
+
+	oldLeader.Close()
+	follower.setLeader(newLeader)
+
+	assert.Equal(t, []string{"new"}, getRouterNames(t, p))
+
+	leaderURL, _ := p.Leader()
+	assert.Equal(t, newLeader.URL, leaderURL)
+}
+
+func TestLeaderFallback(t *testing.T) {
+	endpoint := newFakeMaster("/app")
+	defer endpoint.Close()
+
+	p := newTestLeaderProvider(t, endpoint.URL)
+
+	leaderURL, verifiedAt := p.Leader()
+	assert.Empty(t, leaderURL)
+	assert.True(t, verifiedAt.IsZero())
+
+	assert.Equal(t, []string{"app"}, getRouterNames(t, p))
+}
+
+func TestLeaderProxyEndpoint(t *testing.T) {
+	leader := newFakeMaster("/app")
+	defer leader.Close()
+
+	p := newTestLeaderProvider(t, leader.URL+"/marathon")
+
+	leaderURL, _ := p.Leader()
+	assert.Empty(t, leaderURL)
+}
+
+func TestEndpointsFailover(t *testing.T) {
+	first := newFakeMaster("/first")
+	defer first.Close()
+	second := newFakeMaster("/second")
+	defer second.Close()
+	third := newFakeMaster("/third")
+	defer third.Close()
+
+	first.setFailing(true)
+
+	p := newTestLeaderProvider(t, strings.Join([]string{first.URL, second.URL, third.URL}, ","))
+
+	assert.Equal(t, []string{"second"}, getRouterNames(t, p))
+	assert.Equal(t, second.URL, p.CurrentEndpoint())
+
+	// The endpoint in use is kept as long as it answers.
+	assert.Equal(t, []string{"second"}, getRouterNames(t, p))
+
+	second.setFailing(true)
+
+	assert.Equal(t, []string{"third"}, getRouterNames(t, p))
+	assert.Equal(t, third.URL, p.CurrentEndpoint())
+
+	third.setFailing(true)
+
+	conf := p.getConfigurations(context.Background())
+	assert.Nil(t, conf)
+}
+
+func TestEndpointsWithoutScheme(t *testing.T) {
+	l := newLeaderClient(marathon.Config{URL: "https://10.241.1.71:8080, 10.241.1.72:8080,http://10.241.1.73:8080"}, nil, marathon.NewClient, realClock{})
+
+	expected := []string{"https://10.241.1.71:8080", "https://10.241.1.72:8080", "http://10.241.1.73:8080"}
+	assert.Equal(t, expected, l.endpointURLs)
+}
+
+func TestLeaderRedirect(t *testing.T) {
+	follower := newFakeMaster("/stale")
+	defer follower.Close()
+	leader := newFakeMaster("/fresh")
+	defer leader.Close()
+
+	follower.setRedirect(leader)
+
+	p := newTestLeaderProvider(t, follower.URL)
+
+	assert.Equal(t, []string{"fresh"}, getRouterNames(t, p))
+	assert.Equal(t, follower.URL, p.CurrentEndpoint())
+}
@@ -2,10 +2,14 @@ package marathon
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
 	"net/url"
+	"sync/atomic"
 	"text/template"
 	"time"
 
@@ -13,24 +17,32 @@ import (
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/job"
 	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/metrics"
 	"github.com/containous/traefik/pkg/provider"
 	"github.com/containous/traefik/pkg/safe"
 	"github.com/containous/traefik/pkg/types"
 	"github.com/gambol99/go-marathon"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 const (
 	// DefaultTemplateRule The default template for the default rule.
 	DefaultTemplateRule   = "Host(`{{ normalize .Name }}`)"
 	traceMaxScanTokenSize = 1024 * 1024
-	marathonEventIDs      = marathon.EventIDApplications |
+	// failuresBeforeError is the number of consecutive failed retrievals of the applications
+	// after which the failure is reported again as an error, instead of on each retrieval.
+	failuresBeforeError = 5
+	// The stream attached event is also received when the provider (re)connects to the event stream,
+	// so that the changes missed while being disconnected are caught up.
+	marathonEventIDs = marathon.EventIDApplications |
 		marathon.EventIDAddHealthCheck |
 		marathon.EventIDDeploymentSuccess |
 		marathon.EventIDDeploymentFailed |
 		marathon.EventIDDeploymentInfo |
 		marathon.EventIDDeploymentStepSuccess |
-		marathon.EventIDDeploymentStepFailed
+		marathon.EventIDDeploymentStepFailed |
+		marathon.EventIDStreamAttached
 )
 
 // TaskState denotes the Mesos state a task can have.
@@ -39,6 +51,7 @@ type TaskState string
 const (
 	taskStateRunning TaskState = "TASK_RUNNING"
 	taskStateStaging TaskState = "TASK_STAGING"
+	taskStateKilling TaskState = "TASK_KILLING"
 )
 
 var _ provider.Provider = (*Provider)(nil)
@@ -47,24 +60,50 @@ var _ provider.Provider = (*Provider)(nil)
 type Provider struct {
 	provider.Constrainer `description:"List of constraints used to filter out some containers." export:"true"`
 
-	Trace                     bool             `description:"Display additional provider logs." export:"true"`
-	Watch                     bool             `description:"Watch provider." export:"true"`
-	Endpoint                  string           `description:"Marathon server endpoint. You can also specify multiple endpoint for Marathon." export:"true"`
-	DefaultRule               string           `description:"Default rule."`
-	ExposedByDefault          bool             `description:"Expose Marathon apps by default." export:"true"`
-	DCOSToken                 string           `description:"DCOSToken for DCOS environment, This will override the Authorization header." export:"true"`
-	FilterMarathonConstraints bool             `description:"Enable use of Marathon constraints in constraint filtering." export:"true"`
-	TLS                       *types.ClientTLS `description:"Enable TLS support." export:"true"`
-	DialerTimeout             types.Duration   `description:"Set a dialer timeout for Marathon." export:"true"`
-	ResponseHeaderTimeout     types.Duration   `description:"Set a response header timeout for Marathon." export:"true"`
-	TLSHandshakeTimeout       types.Duration   `description:"Set a TLS handshake timeout for Marathon." export:"true"`
-	KeepAlive                 types.Duration   `description:"Set a TCP Keep Alive time." export:"true"`
-	ForceTaskHostname         bool             `description:"Force to use the task's hostname." export:"true"`
-	Basic                     *Basic           `description:"Enable basic authentication." export:"true"`
-	RespectReadinessChecks    bool             `description:"Filter out tasks with non-successful readiness checks during deployments." export:"true"`
-	readyChecker              *readinessChecker
-	marathonClient            marathon.Marathon
-	defaultRuleTpl            *template.Template
+	Trace                      bool             `description:"Display additional provider logs." export:"true"`
+	Watch                      bool             `description:"Watch provider." export:"true"`
+	Endpoint                   string           `description:"Marathon server endpoint. You can also specify multiple endpoint for Marathon." export:"true"`
+	DefaultRule                string           `description:"Default rule."`
+	DefaultServerScheme        string           `description:"Default scheme of the servers, unless set by the labels of the application (http if not specified)." export:"true"`
+	DefaultPassHostHeader      *bool            `description:"Default pass host header behavior of the services, unless set by the labels of the application. If the option is not specified, it will be enabled by default." export:"true"`
+	ExposedByDefault           bool             `description:"Expose Marathon apps by default." export:"true"`
+	DCOSToken                  string           `description:"DCOSToken for DCOS environment, This will override the Authorization header." export:"true"`
+	DCOSTokenFile              string           `description:"Path to a file holding the DC/OS token, read again whenever Marathon rejects the current token." export:"true"`
+	DCOSCredentialsFile        string           `description:"Path to a DC/OS service account credentials file, used to log in, and to log in again whenever Marathon rejects the current token." export:"true"`
+	FilterMarathonConstraints  bool             `description:"Enable use of Marathon constraints in constraint filtering." export:"true"`
+	LabelSelector              string           `description:"Only expose the applications whose labels match the given selector (e.g. team=web), in the Kubernetes label selector syntax." export:"true"`
+	TLS                        *types.ClientTLS `description:"Enable TLS support." export:"true"`
+	DialerTimeout              types.Duration   `description:"Set a dialer timeout for Marathon." export:"true"`
+	ResponseHeaderTimeout      types.Duration   `description:"Set a response header timeout for Marathon." export:"true"`
+	TLSHandshakeTimeout        types.Duration   `description:"Set a TLS handshake timeout for Marathon." export:"true"`
+	KeepAlive                  types.Duration   `description:"Set a TCP Keep Alive time." export:"true"`
+	ForceTaskHostname          bool             `description:"Force to use the task's hostname." export:"true"`
+	DialerDNSSuffix            string           `description:"Mesos-DNS domain of the framework (e.g. marathon.mesos), used to build the hostnames of the tasks instead of using the hostnames of the agents." export:"true"`
+	Basic                      *Basic           `description:"Enable basic authentication." export:"true"`
+	RespectReadinessChecks     bool             `description:"Filter out tasks with non-successful readiness checks during deployments." export:"true"`
+	OnlyTasksOfLatestVersion   bool             `description:"Filter out the tasks of the previous versions of an application, as long as it has running tasks of its latest version." export:"true"`
+	KeepConfigDuringDeployment bool             `description:"Keep the servers of an application from before its in-flight deployment, until the deployment is over." export:"true"`
+	KeepLastServersOnEmpty     bool             `description:"Keep the last known servers of an application when Marathon suddenly reports no healthy task." export:"true"`
+	LastServersMaxStaleness    types.Duration   `description:"Maximum duration during which the last known servers of an application are kept." export:"true"`
+	RouterPerPort              bool             `description:"Generate a service and a router per named port of the applications which declare neither HTTP routers nor HTTP services." export:"true"`
+	AppIDPrefixes              []string         `description:"Only expose the applications whose ID is under one of the given groups (e.g. /public). An empty list exposes the applications of all the groups." export:"true"`
+	PollInterval               types.Duration   `description:"Interval at which the Marathon applications are also polled when watching, as a fallback to the event stream (0 disables the polling)." export:"true"`
+	PollTimeout                types.Duration   `description:"Maximum duration of the retrieval of the Marathon applications from an endpoint, before giving up on it (0 means no limit)." export:"true"`
+	readyChecker               *readinessChecker
+	lastServers                *lastServers
+	deploymentServers          *lastServers
+	drainingTasks              *drainingTasks
+	skipped                    *skipped
+	metricsRegistry            metrics.Registry
+	marathonClient             marathon.Marathon
+	leader                     *leaderClient
+	defaultRuleTpl             *template.Template
+	labelSelector              labels.Selector
+	tlsConfig                  *tls.Config
+	clock                      clock
+	newClient                  func(config marathon.Config) (marathon.Marathon, error)
+	newBackOff                 func() *backoff.ExponentialBackOff
+	failures                   int32
 }
 
 // SetDefaults sets the default values.
@@ -76,6 +115,7 @@ func (p *Provider) SetDefaults() {
 	p.ResponseHeaderTimeout = types.Duration(60 * time.Second)
 	p.TLSHandshakeTimeout = types.Duration(5 * time.Second)
 	p.KeepAlive = types.Duration(10 * time.Second)
+	p.LastServersMaxStaleness = types.Duration(60 * time.Second)
 	p.DefaultRule = DefaultTemplateRule
 }
 
@@ -103,13 +143,72 @@ func (p *Provider) Init() error {
 	}
 
 	p.defaultRuleTpl = defaultRuleTpl
+
+	if len(p.LabelSelector) > 0 {
+		selector, err := labels.Parse(p.LabelSelector)
+		if err != nil {
+			return fmt.Errorf("invalid label selector %q: %v", p.LabelSelector, err)
+		}
+		p.labelSelector = selector
+	}
+
+	if len(p.DCOSTokenFile) > 0 && len(p.DCOSCredentialsFile) > 0 {
+		return errors.New("only one of dcosTokenFile and dcosCredentialsFile can be set")
+	}
+
+	if p.TLS != nil {
+		tlsConfig, err := p.TLS.CreateTLSConfig(log.With(context.Background(), log.Str(log.ProviderName, "marathon")))
+		if err != nil {
+			return fmt.Errorf("unable to create the TLS configuration for Marathon: %v", err)
+		}
+		p.tlsConfig = tlsConfig
+	}
+
+	if p.clock == nil {
+		p.clock = realClock{}
+	}
+
+	if p.newClient == nil {
+		p.newClient = marathon.NewClient
+	}
+
+	if p.newBackOff == nil {
+		p.newBackOff = backoff.NewExponentialBackOff
+	}
+
+	if p.RespectReadinessChecks {
+		log.WithoutContext().Debug("Enabling Marathon readiness checker")
+		p.readyChecker = defaultReadinessChecker(p.Trace, p.clock)
+	}
+
+	if p.KeepLastServersOnEmpty {
+		p.lastServers = newLastServers(time.Duration(p.LastServersMaxStaleness))
+	}
+
+	p.drainingTasks = newDrainingTasks()
+
+	if p.KeepConfigDuringDeployment {
+		// The servers from before a deployment are kept for as long as the deployment lasts.
+		p.deploymentServers = newLastServers(time.Duration(math.MaxInt64))
+	}
+
+	if p.metricsRegistry == nil {
+		p.metricsRegistry = metrics.NewVoidRegistry()
+	}
+
 	return nil
 }
 
+// SetMetricsRegistry sets the registry used to report the metrics of the provider.
+func (p *Provider) SetMetricsRegistry(registry metrics.Registry) {
+	p.metricsRegistry = registry
+}
+
 // Provide allows the marathon provider to provide configurations to traefik
 // using the given configuration channel.
 func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.Pool) error {
-	ctx := log.With(context.Background(), log.Str(log.ProviderName, "marathon"))
+	// The context is canceled when the provider is stopped, so that no retrieval of the applications is waited for anymore.
+	ctx, cancel := context.WithCancel(log.With(context.Background(), log.Str(log.ProviderName, "marathon")))
 	logger := log.FromContext(ctx)
 
 	operation := func() error {
@@ -124,37 +223,36 @@ func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.P
 			confg.HTTPBasicAuthUser = p.Basic.HTTPBasicAuthUser
 			confg.HTTPBasicPassword = p.Basic.HTTPBasicPassword
 		}
-		var rc *readinessChecker
-		if p.RespectReadinessChecks {
-			logger.Debug("Enabling Marathon readiness checker")
-			rc = defaultReadinessChecker(p.Trace)
-		}
-		p.readyChecker = rc
-
 		if len(p.DCOSToken) > 0 {
 			confg.DCOSToken = p.DCOSToken
 		}
-		TLSConfig, err := p.TLS.CreateTLSConfig(ctx)
-		if err != nil {
-			return err
+		var transport http.RoundTripper = &http.Transport{
+			DialContext: (&net.Dialer{
+				KeepAlive: time.Duration(p.KeepAlive),
+				Timeout:   time.Duration(p.DialerTimeout),
+			}).DialContext,
+			ResponseHeaderTimeout: time.Duration(p.ResponseHeaderTimeout),
+			TLSHandshakeTimeout:   time.Duration(p.TLSHandshakeTimeout),
+			TLSClientConfig:       p.tlsConfig,
+		}
+		if len(p.DCOSTokenFile) > 0 || len(p.DCOSCredentialsFile) > 0 {
+			transport = &authTransport{
+				auth:   newDCOSAuth(p.DCOSTokenFile, p.DCOSCredentialsFile, transport, p.clock),
+				next:   transport,
+				logger: logger,
+			}
 		}
 		confg.HTTPClient = &http.Client{
-			Transport: &http.Transport{
-				DialContext: (&net.Dialer{
-					KeepAlive: time.Duration(p.KeepAlive),
-					Timeout:   time.Duration(p.DialerTimeout),
-				}).DialContext,
-				ResponseHeaderTimeout: time.Duration(p.ResponseHeaderTimeout),
-				TLSHandshakeTimeout:   time.Duration(p.TLSHandshakeTimeout),
-				TLSClientConfig:       TLSConfig,
-			},
-		}
-		client, err := marathon.NewClient(confg)
+			Transport: transport,
+		}
+		client, err := p.newClient(confg)
 		if err != nil {
 			logger.Errorf("Failed to create a client for marathon, error: %s", err)
 			return err
 		}
 		p.marathonClient = client
+		p.leader = newLeaderClient(confg, client, p.newClient, p.clock)
+		p.leader.resolve(ctx)
 
 		if p.Watch {
 			update, err := client.AddEventsListener(marathonEventIDs)
@@ -163,59 +261,247 @@ func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.P
 				return err
 			}
 			pool.Go(func(stop chan bool) {
-				defer close(update)
-				for {
-					select {
-					case <-stop:
-						return
-					case event := <-update:
-						logger.Debugf("Received provider event %s", event)
-
-						conf := p.getConfigurations(ctx)
-						if conf != nil {
-							configurationChan <- config.Message{
-								ProviderName:  "marathon",
-								Configuration: conf,
-							}
-						}
-					}
-				}
+				defer cancel()
+				p.watch(ctx, client, update, configurationChan, stop)
 			})
 		}
 
-		configuration := p.getConfigurations(ctx)
-		configurationChan <- config.Message{
-			ProviderName:  "marathon",
-			Configuration: configuration,
-		}
+		p.publish(ctx, configurationChan)
 		return nil
 	}
 
 	notify := func(err error, time time.Duration) {
 		logger.Errorf("Provider connection error %+v, retrying in %s", err, time)
 	}
-	err := backoff.RetryNotify(safe.OperationWithRecover(operation), job.NewBackOff(backoff.NewExponentialBackOff()), notify)
+	exponentialBackOff := p.newBackOff()
+	exponentialBackOff.Clock = p.clock
+
+	err := backoff.RetryNotify(safe.OperationWithRecover(operation), job.NewBackOff(exponentialBackOff), notify)
 	if err != nil {
 		logger.Errorf("Cannot connect to Provider server: %+v", err)
 	}
+
+	if !p.Watch {
+		cancel()
+	}
 	return nil
 }
 
+// watch publishes a new configuration on each event received from the event stream,
+// and on each polling interval if the polling is enabled.
+// When the events channel gets closed, it subscribes again to the event stream, backing off between the attempts.
+func (p *Provider) watch(ctx context.Context, client marathon.Marathon, update marathon.EventsChannel, configurationChan chan<- config.Message, stop chan bool) {
+	logger := log.FromContext(ctx)
+
+	defer func() {
+		if update != nil {
+			close(update)
+		}
+	}()
+
+	var poll <-chan time.Time
+	if p.PollInterval > 0 {
+		poll = p.clock.After(time.Duration(p.PollInterval))
+	}
+
+	for {
+		select {
+		case <-stop:
+			return
+		case event, ok := <-update:
+			if !ok {
+				logger.Error("Marathon event stream closed, subscribing again")
+				update = p.subscribe(ctx, client, stop)
+				if update == nil {
+					return
+				}
+				// Catches up with the changes missed while not subscribed.
+				break
+			}
+			logger.Debugf("Received provider event %s", event)
+		case <-poll:
+			logger.Debug("Polling Marathon applications")
+			poll = p.clock.After(time.Duration(p.PollInterval))
+		}
+
+		p.publish(ctx, configurationChan)
+	}
+}
+
+// subscribe registers again for the events, until it succeeds or the provider is stopped, in which case it returns nil.
+func (p *Provider) subscribe(ctx context.Context, client marathon.Marathon, stop chan bool) marathon.EventsChannel {
+	exponentialBackOff := p.newBackOff()
+	exponentialBackOff.Clock = p.clock
+	b := job.NewBackOff(exponentialBackOff)
+	b.Reset()
+
+	for {
+		update, err := client.AddEventsListener(marathonEventIDs)
+		if err == nil {
+			return update
+		}
+
+		next := b.NextBackOff()
+		log.FromContext(ctx).Errorf("Failed to register for events %v, retrying in %s", err, next)
+
+		select {
+		case <-stop:
+			return nil
+		case <-p.clock.After(next):
+		}
+	}
+}
+
+// publish sends the current configuration, unless it could not be built,
+// so that no empty configuration is published while Marathon is unreachable.
+func (p *Provider) publish(ctx context.Context, configurationChan chan<- config.Message) {
+	conf := p.getConfigurations(ctx)
+	if conf == nil {
+		return
+	}
+
+	select {
+	case configurationChan <- config.Message{
+		ProviderName:  "marathon",
+		Configuration: conf,
+	}:
+	case <-ctx.Done():
+	}
+}
+
+// Leader returns the URL of the leading Marathon master the provider is pinned to, and when it was last verified.
+// An empty URL means that the provider is talking to the configured endpoints.
+func (p *Provider) Leader() (string, time.Time) {
+	if p.leader == nil {
+		return "", time.Time{}
+	}
+	return p.leader.status()
+}
+
+// CurrentEndpoint returns the URL of the Marathon endpoint the provider is currently talking to.
+func (p *Provider) CurrentEndpoint() string {
+	if p.leader == nil {
+		return p.Endpoint
+	}
+	return p.leader.endpoint()
+}
+
 func (p *Provider) getConfigurations(ctx context.Context) *config.Configuration {
-	applications, err := p.getApplications()
+	logger := log.FromContext(ctx)
+
+	applications, err := p.fetchApplications(ctx)
+	if err != nil && ctx.Err() == nil && p.leader != nil {
+		logger.Debugf("Failed to retrieve Marathon applications, resolving the leading master again: %v", err)
+		p.leader.resolve(ctx)
+		applications, err = p.fetchApplications(ctx)
+
+		// Tries each of the other configured endpoints before giving up for this cycle.
+		for i := 1; err != nil && ctx.Err() == nil && i < p.leader.size(); i++ {
+			logger.Warnf("Failed to retrieve Marathon applications from %s: %v", p.leader.endpoint(), err)
+
+			var endpoint string
+			endpoint, err = p.leader.next(ctx)
+			if err != nil {
+				err = fmt.Errorf("unable to create a client for the Marathon endpoint %s: %v", endpoint, err)
+				continue
+			}
+
+			applications, err = p.fetchApplications(ctx)
+		}
+	}
 	if err != nil {
-		log.FromContext(ctx).Errorf("Failed to retrieve Marathon applications: %v", err)
+		if ctx.Err() != nil {
+			logger.Debug("Provider stopped, giving up the retrieval of the Marathon applications")
+			return nil
+		}
+
+		p.countFailure(logger, err)
 		return nil
 	}
 
+	if failures := atomic.SwapInt32(&p.failures, 0); failures > 0 {
+		logger.Infof("Retrieved Marathon applications again, after %d failed attempts", failures)
+	}
+
 	return p.buildConfiguration(ctx, applications)
 }
 
+// countFailure reports a failed retrieval of the applications,
+// as an error on the first one and then every failuresBeforeError consecutive ones, and as a debug message otherwise.
+func (p *Provider) countFailure(logger log.Logger, err error) {
+	failures := atomic.AddInt32(&p.failures, 1)
+
+	switch {
+	case failures == 1:
+		logger.Errorf("Failed to retrieve Marathon applications: %v", err)
+	case failures%failuresBeforeError == 0:
+		logger.Errorf("Failed to retrieve Marathon applications %d times in a row: %v", failures, err)
+	default:
+		logger.Debugf("Failed to retrieve Marathon applications (%d times in a row): %v", failures, err)
+	}
+}
+
+// fetchApplications retrieves the applications, giving up when the poll timeout is reached, or when the provider is stopped.
+// As the Marathon client does not support contexts, a retrieval given up on still completes in the background,
+// within the limits of the timeouts of the HTTP transport.
+func (p *Provider) fetchApplications(ctx context.Context) (*marathon.Applications, error) {
+	type result struct {
+		applications *marathon.Applications
+		err          error
+	}
+
+	results := make(chan result, 1)
+	go func() {
+		applications, err := p.getApplications()
+		results <- result{applications: applications, err: err}
+	}()
+
+	var timeout <-chan time.Time
+	if p.PollTimeout > 0 {
+		timeout = p.clock.After(time.Duration(p.PollTimeout))
+	}
+
+	select {
+	case r := <-results:
+		return r.applications, r.err
+	case <-timeout:
+		return nil, fmt.Errorf("timed out after %s", time.Duration(p.PollTimeout))
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 func (p *Provider) getApplications() (*marathon.Applications, error) {
 	v := url.Values{}
 	v.Add("embed", "apps.tasks")
 	v.Add("embed", "apps.deployments")
 	v.Add("embed", "apps.readiness")
 
-	return p.marathonClient.Applications(v)
+	client := p.marathonClient
+	if p.leader != nil {
+		client = p.leader.get()
+	}
+
+	applications, err := client.Applications(v)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := client.PodStatuses()
+	if err != nil {
+		// The Marathon versions without the support of the pods answer with a 404.
+		if apiErr, ok := err.(*marathon.APIError); !ok || apiErr.ErrCode != marathon.ErrCodeNotFound {
+			return nil, err
+		}
+	}
+
+	if len(pods) == 0 {
+		return applications, nil
+	}
+
+	apps := make([]marathon.Application, 0, len(applications.Apps)+len(pods))
+	apps = append(apps, applications.Apps...)
+	apps = append(apps, podApplications(pods)...)
+
+	return &marathon.Applications{Apps: apps}, nil
 }
@@ -0,0 +1,295 @@
+package marathon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/safe"
+	"github.com/containous/traefik/pkg/types"
+	"github.com/gambol99/go-marathon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvideRetryBackOff(t *testing.T) {
+	clock := newFakeClock()
+
+	client := newScriptedClient(clock, snapshot{
+		applications: withApplications(application(appID("/app"), appPorts(80), withTasks(localhostTask(taskPorts(80))))),
+	})
+	client.On("Leader").Return("", errors.New("no leader"))
+	// The event stream is unavailable twice before the provider manages to subscribe to it.
+	client.On("AddEventsListener", mock.Anything).Return(nil, errors.New("events unavailable")).Twice()
+	client.On("AddEventsListener", mock.Anything).Return(make(marathon.EventsChannel), nil)
+
+	p := &Provider{
+		Endpoint:         "http://127.0.0.1:8080",
+		Watch:            true,
+		ExposedByDefault: true,
+		DefaultRule:      DefaultTemplateRule,
+	}
+	p.clock = clock
+	p.newClient = func(marathon.Config) (marathon.Marathon, error) {
+		return client, nil
+	}
+	// The attempts are 100ms, then 200ms apart.
+	p.newBackOff = func() *backoff.ExponentialBackOff {
+		b := backoff.NewExponentialBackOff()
+		b.InitialInterval = 100 * time.Millisecond
+		b.RandomizationFactor = 0
+		b.Multiplier = 2
+		return b
+	}
+
+	err := p.Init()
+	require.NoError(t, err)
+
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+
+	configurationChan := make(chan config.Message, 10)
+	start := time.Now()
+	go func() {
+		_ = p.Provide(configurationChan, pool)
+	}()
+
+	// Nothing is published while waiting for the next attempts.
+	select {
+	case <-configurationChan:
+		t.Fatal("Unexpected configuration published before the next attempts")
+	case <-time.After(250 * time.Millisecond):
+	}
+
+	select {
+	case message := <-configurationChan:
+		require.NotNil(t, message.Configuration)
+		assert.Contains(t, message.Configuration.HTTP.Services, "app")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout while waiting for a configuration")
+	}
+
+	assert.True(t, time.Since(start) >= 300*time.Millisecond)
+	client.AssertNumberOfCalls(t, "AddEventsListener", 3)
+}
+
+func TestProvideWatch(t *testing.T) {
+	clock := newFakeClock()
+
+	client := newScriptedClient(clock,
+		snapshot{
+			applications: withApplications(application(appID("/app"), appPorts(80), withTasks(localhostTask(taskPorts(80))))),
+		},
+		snapshot{
+			at:  10 * time.Second,
+			err: errors.New("fake Marathon server error"),
+		},
+		snapshot{
+			at:           20 * time.Second,
+			applications: withApplications(application(appID("/other"), appPorts(80), withTasks(localhostTask(taskPorts(80))))),
+		},
+	)
+	client.On("Leader").Return("", errors.New("no leader"))
+
+	first := make(marathon.EventsChannel)
+	second := make(marathon.EventsChannel)
+	client.On("AddEventsListener", mock.Anything).Return(first, nil).Once()
+	client.On("AddEventsListener", mock.Anything).Return(nil, errors.New("events unavailable")).Once()
+	client.On("AddEventsListener", mock.Anything).Return(second, nil)
+
+	p := &Provider{
+		Endpoint:         "http://127.0.0.1:8080",
+		Watch:            true,
+		ExposedByDefault: true,
+		DefaultRule:      DefaultTemplateRule,
+		PollInterval:     types.Duration(time.Minute),
+	}
+	p.clock = clock
+	p.newClient = func(marathon.Config) (marathon.Marathon, error) {
+		return client, nil
+	}
+
+	err := p.Init()
+	require.NoError(t, err)
+
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+
+	configurationChan := make(chan config.Message, 10)
+	go func() {
+		_ = p.Provide(configurationChan, pool)
+	}()
+
+	assertServices(t, configurationChan, "app")
+
+	// An event triggers a new configuration.
+	first <- &marathon.Event{ID: marathon.EventIDStatusUpdate, Name: "status_update_event"}
+	assertServices(t, configurationChan, "app")
+
+	// No configuration is published while Marathon is unreachable.
+	clock.Advance(10 * time.Second)
+	first <- &marathon.Event{ID: marathon.EventIDChangedHealthCheck, Name: "health_status_changed_event"}
+	assertNoConfiguration(t, configurationChan)
+
+	// When the events channel is closed, the provider subscribes again, backing off between the attempts,
+	// and then catches up with the current state.
+	clock.Advance(10 * time.Second)
+	close(first)
+	// The first waiter is the polling one.
+	backOff := clock.waitForWaiters(t, 2)[1]
+	assertNoConfiguration(t, configurationChan)
+	clock.Advance(backOff)
+	assertServices(t, configurationChan, "other")
+
+	second <- &marathon.Event{ID: marathon.EventIDDeploymentSuccess, Name: "deployment_success"}
+	assertServices(t, configurationChan, "other")
+
+	// The applications are also polled.
+	clock.Advance(time.Minute)
+	assertServices(t, configurationChan, "other")
+	assertNoConfiguration(t, configurationChan)
+
+	client.AssertNumberOfCalls(t, "AddEventsListener", 3)
+}
+
+func assertServices(t *testing.T, configurationChan <-chan config.Message, services ...string) {
+	t.Helper()
+
+	select {
+	case message := <-configurationChan:
+		require.NotNil(t, message.Configuration)
+
+		var actual []string
+		for name := range message.Configuration.HTTP.Services {
+			actual = append(actual, name)
+		}
+		assert.ElementsMatch(t, services, actual)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout while waiting for a configuration")
+	}
+}
+
+func assertNoConfiguration(t *testing.T, configurationChan <-chan config.Message) {
+	t.Helper()
+
+	select {
+	case message := <-configurationChan:
+		t.Fatalf("Unexpected configuration published: %v", message.Configuration)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestGetConfigurationsPollTimeout(t *testing.T) {
+	block := make(chan time.Time)
+	defer close(block)
+
+	fakeClient := new(fakeClient)
+	fakeClient.On("Applications", mock.Anything).WaitUntil(block).Return(withApplications(), nil)
+	fakeClient.On("PodStatuses").Return(nil, nil)
+
+	clock := newFakeClock()
+	p := &Provider{
+		DefaultRule:      DefaultTemplateRule,
+		ExposedByDefault: true,
+		PollTimeout:      types.Duration(500 * time.Millisecond),
+		marathonClient:   fakeClient,
+		clock:            clock,
+	}
+
+	err := p.Init()
+	require.NoError(t, err)
+
+	confs := make(chan *config.Configuration)
+	go func() {
+		confs <- p.getConfigurations(context.Background())
+	}()
+
+	assert.Equal(t, 500*time.Millisecond, clock.waitForWaiter(t))
+	clock.Advance(500 * time.Millisecond)
+
+	select {
+	case conf := <-confs:
+		assert.Nil(t, conf)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout while waiting for the retrieval of the applications to be given up")
+	}
+}
+
+func TestGetConfigurationsStopped(t *testing.T) {
+	block := make(chan time.Time)
+	defer close(block)
+
+	fakeClient := new(fakeClient)
+	fakeClient.On("Applications", mock.Anything).WaitUntil(block).Return(withApplications(), nil)
+	fakeClient.On("PodStatuses").Return(nil, nil)
+
+	p := &Provider{
+		DefaultRule:      DefaultTemplateRule,
+		ExposedByDefault: true,
+		marathonClient:   fakeClient,
+	}
+
+	err := p.Init()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	confs := make(chan *config.Configuration)
+	go func() {
+		confs <- p.getConfigurations(ctx)
+	}()
+
+	cancel()
+
+	select {
+	case conf := <-confs:
+		assert.Nil(t, conf)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout while waiting for the retrieval of the applications to be given up")
+	}
+
+	// A stopped provider does not count as a failure.
+	assert.Equal(t, int32(0), p.failures)
+}
+
+func TestGetConfigurationsConsecutiveFailures(t *testing.T) {
+	clock := newFakeClock()
+	client := newScriptedClient(clock,
+		snapshot{err: errors.New("fake Marathon server error")},
+		snapshot{at: time.Minute, applications: withApplications()},
+	)
+
+	p := &Provider{
+		DefaultRule:      DefaultTemplateRule,
+		ExposedByDefault: true,
+		marathonClient:   client,
+		clock:            clock,
+	}
+
+	err := p.Init()
+	require.NoError(t, err)
+
+	for i := 1; i <= 2*failuresBeforeError; i++ {
+		assert.Nil(t, p.getConfigurations(context.Background()))
+		assert.Equal(t, int32(i), p.failures)
+	}
+
+	clock.Advance(time.Minute)
+
+	assert.NotNil(t, p.getConfigurations(context.Background()))
+	assert.Equal(t, int32(0), p.failures)
+}
+
+func TestInitInvalidTLS(t *testing.T) {
+	p := &Provider{
+		DefaultRule: DefaultTemplateRule,
+		TLS:         &types.ClientTLS{Cert: "/etc/traefik/marathon.crt"},
+	}
+
+	err := p.Init()
+	assert.Error(t, err)
+}
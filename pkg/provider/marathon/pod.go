@@ -0,0 +1,129 @@
+package marathon
+
+import (
+	"github.com/gambol99/go-marathon"
+)
+
+// podApplications converts the pods into applications,
+// so that the labels of a pod produce routers and services exactly as the ones of an application do.
+func podApplications(pods []*marathon.PodStatus) []marathon.Application {
+	var apps []marathon.Application
+	for _, pod := range pods {
+		if pod == nil || pod.Spec == nil {
+			continue
+		}
+		apps = append(apps, podApplication(pod))
+	}
+	return apps
+}
+
+// podApplication converts a pod into an application, with a task per instance of the pod.
+// The endpoints of all the containers of the pod are mapped to named ports,
+// in order, so that they can be selected by index or by name.
+func podApplication(pod *marathon.PodStatus) marathon.Application {
+	labels := make(map[string]string, len(pod.Spec.Labels))
+	for key, value := range pod.Spec.Labels {
+		labels[key] = value
+	}
+
+	app := marathon.Application{
+		ID:     pod.ID,
+		Labels: &labels,
+	}
+
+	if len(pod.Spec.Networks) > 0 {
+		var networks []marathon.PodNetwork
+		for _, network := range pod.Spec.Networks {
+			if network != nil {
+				networks = append(networks, *network)
+			}
+		}
+		app.Networks = &networks
+	}
+
+	var endpoints []*marathon.PodEndpoint
+	for _, container := range pod.Spec.Containers {
+		if container == nil {
+			continue
+		}
+		for _, endpoint := range container.Endpoints {
+			if endpoint != nil {
+				endpoints = append(endpoints, endpoint)
+			}
+		}
+	}
+
+	if len(endpoints) > 0 {
+		app.Container = &marathon.Container{}
+		for _, endpoint := range endpoints {
+			app.Container.ExposePort(marathon.PortMapping{
+				Name:          endpoint.Name,
+				ContainerPort: endpoint.ContainerPort,
+				HostPort:      endpoint.HostPort,
+			})
+		}
+	}
+
+	for _, instance := range pod.Instances {
+		if instance == nil {
+			continue
+		}
+		task := podTask(app, instance, endpoints)
+		app.Tasks = append(app.Tasks, &task)
+	}
+
+	return app
+}
+
+// podTask converts an instance of a pod into a task.
+// Only the stable instances, i.e. the ones whose containers are all running and healthy, are considered running.
+func podTask(app marathon.Application, instance *marathon.PodInstanceStatus, endpoints []*marathon.PodEndpoint) marathon.Task {
+	task := marathon.Task{
+		ID:     instance.ID,
+		AppID:  app.ID,
+		Host:   instance.AgentHostname,
+		State:  string(instance.Status),
+		Region: instance.AgentRegion,
+		Zone:   instance.AgentZone,
+	}
+
+	if instance.Status == marathon.PodInstanceStateStable {
+		task.State = string(taskStateRunning)
+	}
+
+	for _, network := range instance.Networks {
+		if network == nil {
+			continue
+		}
+		for _, address := range network.Addresses {
+			task.IPAddresses = append(task.IPAddresses, &marathon.IPAddress{IPAddress: address})
+		}
+	}
+
+	// The host ports are allocated per instance.
+	hostPorts := make(map[string]int)
+	for _, container := range instance.Containers {
+		if container == nil {
+			continue
+		}
+		for _, endpoint := range container.Endpoints {
+			if endpoint != nil && endpoint.HostPort > 0 {
+				hostPorts[endpoint.Name] = endpoint.HostPort
+			}
+		}
+	}
+
+	// On a container network, the instance is reached on its own IP address, so on the container ports.
+	for _, endpoint := range endpoints {
+		port := endpoint.ContainerPort
+		if !isContainerNetwork(app) {
+			port = endpoint.HostPort
+			if hostPort, ok := hostPorts[endpoint.Name]; ok {
+				port = hostPort
+			}
+		}
+		task.Ports = append(task.Ports, port)
+	}
+
+	return task
+}
@@ -0,0 +1,264 @@
+package marathon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/gambol99/go-marathon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetConfigurationsWithPods(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		applications *marathon.Applications
+		pods         []*marathon.PodStatus
+		expected     *config.HTTPConfiguration
+	}{
+		{
+			desc:         "pod with a single endpoint",
+			applications: withApplications(),
+			pods: []*marathon.PodStatus{
+				pod(
+					podID("/pod"),
+					podContainer("web", podEndpoint("http", 80)),
+					podInstance("instance-1", marathon.PodInstanceStateStable, 31000),
+				),
+			},
+			expected: &config.HTTPConfiguration{
+				Routers: map[string]*config.Router{
+					"pod": {
+						Service: "pod",
+						Rule:    "Host(`pod.marathon.localhost`)",
+					},
+				},
+				Middlewares: map[string]*config.Middleware{},
+				Services: map[string]*config.Service{
+					"pod": {LoadBalancer: &config.LoadBalancerService{
+						Servers: []config.Server{
+							{URL: "http://localhost:31000"},
+						},
+						PassHostHeader: true,
+					}},
+				},
+			},
+		},
+		{
+			desc:         "pod endpoints selected by name",
+			applications: withApplications(),
+			pods: []*marathon.PodStatus{
+				pod(
+					podID("/pod"),
+					withPodLabel("traefik.http.services.web.loadbalancer.server.port", "name:http"),
+					withPodLabel("traefik.http.routers.web.rule", "Host(`web.localhost`)"),
+					withPodLabel("traefik.http.routers.web.service", "web"),
+					withPodLabel("traefik.http.services.admin.loadbalancer.server.port", "name:admin"),
+					withPodLabel("traefik.http.routers.admin.rule", "Host(`admin.localhost`)"),
+					withPodLabel("traefik.http.routers.admin.service", "admin"),
+					podContainer("web", podEndpoint("http", 80)),
+					podContainer("sidecar", podEndpoint("admin", 9090)),
+					podInstance("instance-1", marathon.PodInstanceStateStable, 31000, 31001),
+				),
+			},
+			expected: &config.HTTPConfiguration{
+				Routers: map[string]*config.Router{
+					"web": {
+						Service: "web",
+						Rule:    "Host(`web.localhost`)",
+					},
+					"admin": {
+						Service: "admin",
+						Rule:    "Host(`admin.localhost`)",
+					},
+				},
+				Middlewares: map[string]*config.Middleware{},
+				Services: map[string]*config.Service{
+					"web": {LoadBalancer: &config.LoadBalancerService{
+						Servers: []config.Server{
+							{URL: "http://localhost:31000"},
+						},
+						PassHostHeader: true,
+					}},
+					"admin": {LoadBalancer: &config.LoadBalancerService{
+						Servers: []config.Server{
+							{URL: "http://localhost:31001"},
+						},
+						PassHostHeader: true,
+					}},
+				},
+			},
+		},
+		{
+			desc:         "pod on a container network",
+			applications: withApplications(),
+			pods: []*marathon.PodStatus{
+				pod(
+					podID("/pod"),
+					withPodLabel("traefik.http.services.pod.loadbalancer.server.port", "name:admin"),
+					podContainerNetwork(),
+					podContainer("web", podEndpoint("http", 80), podEndpoint("admin", 9090)),
+					podInstance("instance-1", marathon.PodInstanceStateStable),
+				),
+			},
+			expected: &config.HTTPConfiguration{
+				Routers: map[string]*config.Router{
+					"pod": {
+						Service: "pod",
+						Rule:    "Host(`pod.marathon.localhost`)",
+					},
+				},
+				Middlewares: map[string]*config.Middleware{},
+				Services: map[string]*config.Service{
+					"pod": {LoadBalancer: &config.LoadBalancerService{
+						Servers: []config.Server{
+							{URL: "http://127.0.0.1:9090"},
+						},
+						PassHostHeader: true,
+					}},
+				},
+			},
+		},
+		{
+			desc:         "unstable pod instances are filtered",
+			applications: withApplications(),
+			pods: []*marathon.PodStatus{
+				pod(
+					podID("/pod"),
+					podContainer("web", podEndpoint("http", 80)),
+					podInstance("instance-1", marathon.PodInstanceStateStable, 31000),
+					podInstance("instance-2", marathon.PodInstanceStateDegraded, 31001),
+					podInstance("instance-3", marathon.PodInstanceStateStaging, 31002),
+				),
+			},
+			expected: &config.HTTPConfiguration{
+				Routers: map[string]*config.Router{
+					"pod": {
+						Service: "pod",
+						Rule:    "Host(`pod.marathon.localhost`)",
+					},
+				},
+				Middlewares: map[string]*config.Middleware{},
+				Services: map[string]*config.Service{
+					"pod": {LoadBalancer: &config.LoadBalancerService{
+						Servers: []config.Server{
+							{URL: "http://localhost:31000"},
+						},
+						PassHostHeader: true,
+					}},
+				},
+			},
+		},
+		{
+			desc: "applications and pods",
+			applications: withApplications(
+				application(
+					appID("/app"),
+					appPorts(80),
+					withTasks(localhostTask(taskPorts(80))),
+				)),
+			pods: []*marathon.PodStatus{
+				pod(
+					podID("/pod"),
+					podContainer("web", podEndpoint("http", 80)),
+					podInstance("instance-1", marathon.PodInstanceStateStable, 31000),
+				),
+			},
+			expected: &config.HTTPConfiguration{
+				Routers: map[string]*config.Router{
+					"app": {
+						Service: "app",
+						Rule:    "Host(`app.marathon.localhost`)",
+					},
+					"pod": {
+						Service: "pod",
+						Rule:    "Host(`pod.marathon.localhost`)",
+					},
+				},
+				Middlewares: map[string]*config.Middleware{},
+				Services: map[string]*config.Service{
+					"app": {LoadBalancer: &config.LoadBalancerService{
+						Servers: []config.Server{
+							{URL: "http://localhost:80"},
+						},
+						PassHostHeader: true,
+					}},
+					"pod": {LoadBalancer: &config.LoadBalancerService{
+						Servers: []config.Server{
+							{URL: "http://localhost:31000"},
+						},
+						PassHostHeader: true,
+					}},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := newScriptedClient(newFakeClock(), snapshot{
+				applications: test.applications,
+				pods:         test.pods,
+			})
+
+			p := &Provider{
+				DefaultRule:      "Host(`{{ normalize .Name }}.marathon.localhost`)",
+				ExposedByDefault: true,
+				marathonClient:   client,
+			}
+
+			err := p.Init()
+			require.NoError(t, err)
+
+			conf := p.getConfigurations(context.Background())
+			require.NotNil(t, conf)
+
+			assert.Equal(t, test.expected, conf.HTTP)
+		})
+	}
+}
+
+func TestGetConfigurationsPodsUnsupported(t *testing.T) {
+	fakeClient := newFakeClient(false, *withApplications(
+		application(
+			appID("/app"),
+			appPorts(80),
+			withTasks(localhostTask(taskPorts(80))),
+		)))
+	fakeClient.On("PodStatuses").Return(nil, marathon.NewAPIError(404, []byte(`{"message": "not found"}`)))
+
+	p := &Provider{
+		DefaultRule:      DefaultTemplateRule,
+		ExposedByDefault: true,
+		marathonClient:   fakeClient,
+	}
+
+	err := p.Init()
+	require.NoError(t, err)
+
+	conf := p.getConfigurations(context.Background())
+	require.NotNil(t, conf)
+	assert.Contains(t, conf.HTTP.Services, "app")
+}
+
+func TestGetConfigurationsPodsError(t *testing.T) {
+	fakeClient := newFakeClient(false, *withApplications())
+	fakeClient.On("PodStatuses").Return(nil, errors.New("fake Marathon server error"))
+
+	p := &Provider{
+		DefaultRule:      DefaultTemplateRule,
+		ExposedByDefault: true,
+		marathonClient:   fakeClient,
+	}
+
+	err := p.Init()
+	require.NoError(t, err)
+
+	conf := p.getConfigurations(context.Background())
+	assert.Nil(t, conf)
+}
@@ -22,13 +22,15 @@ type readinessChecker struct {
 	checkDefaultTimeout time.Duration
 	checkSafetyMargin   time.Duration
 	traceLogging        bool
+	clock               clock
 }
 
-func defaultReadinessChecker(isTraceLogging bool) *readinessChecker {
+func defaultReadinessChecker(isTraceLogging bool, clk clock) *readinessChecker {
 	return &readinessChecker{
 		checkDefaultTimeout: readinessCheckDefaultTimeout,
 		checkSafetyMargin:   readinessCheckSafetyMargin,
 		traceLogging:        isTraceLogging,
+		clock:               clk,
 	}
 }
 
@@ -102,7 +104,7 @@ func (rc *readinessChecker) Do(task marathon.Task, app marathon.Application) boo
 		return false
 	}
 
-	since := time.Since(startTime)
+	since := rc.clock.Now().Sub(startTime)
 	if since < readinessCheckTimeout {
 		rc.tracef("task %s app %s: ready = false [task with start-time %s not within assumed check timeout window of %s (elapsed time since task start: %s)]", task.ID, app.ID, startTime.Format(time.RFC3339), readinessCheckTimeout, since)
 		return false
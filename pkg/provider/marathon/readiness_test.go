@@ -8,7 +8,7 @@ import (
 )
 
 func testReadinessChecker() *readinessChecker {
-	return defaultReadinessChecker(false)
+	return defaultReadinessChecker(false, realClock{})
 }
 
 func TestDisabledReadinessChecker(t *testing.T) {
@@ -75,6 +75,7 @@ func TestEnabledReadinessChecker(t *testing.T) {
 				readinessCheck(0),
 			),
 			rc: readinessChecker{
+				clock:               realClock{},
 				checkDefaultTimeout: 5 * time.Minute,
 			},
 			expectedReady: false,
@@ -87,6 +88,7 @@ func TestEnabledReadinessChecker(t *testing.T) {
 				readinessCheck(3*time.Minute),
 			),
 			rc: readinessChecker{
+				clock:             realClock{},
 				checkSafetyMargin: 3 * time.Minute,
 			},
 			expectedReady: false,
@@ -108,6 +110,7 @@ func TestEnabledReadinessChecker(t *testing.T) {
 				readinessCheck(0),
 			),
 			rc: readinessChecker{
+				clock:               realClock{},
 				checkDefaultTimeout: 10 * time.Second,
 			},
 			expectedReady: true,
@@ -0,0 +1,70 @@
+package marathon
+
+import (
+	"context"
+	"sync"
+
+	"github.com/containous/traefik/pkg/log"
+	"github.com/sirupsen/logrus"
+)
+
+// The reasons for which the applications and the tasks are skipped while building the configuration.
+const (
+	skipReasonDisabled     = "disabled"
+	skipReasonConstraints  = "constraints"
+	skipReasonFilteredTask = "filtered_task"
+	skipReasonWithoutPort  = "without_port"
+)
+
+var skipReasons = []string{skipReasonDisabled, skipReasonConstraints, skipReasonFilteredTask, skipReasonWithoutPort}
+
+// skipped holds, per reason, the IDs of the applications and the tasks skipped during a configuration build.
+// An ID is counted once per reason, even if it is skipped for several services.
+type skipped struct {
+	mu  sync.Mutex
+	ids map[string]map[string]struct{}
+}
+
+func newSkipped() *skipped {
+	return &skipped{
+		ids: make(map[string]map[string]struct{}),
+	}
+}
+
+func (s *skipped) add(reason, id string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ids[reason] == nil {
+		s.ids[reason] = make(map[string]struct{})
+	}
+	s.ids[reason][id] = struct{}{}
+}
+
+func (s *skipped) count(reason string) int {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.ids[reason])
+}
+
+// reportSkipped publishes the number of applications and tasks skipped during the last configuration build,
+// per reason, both as metrics and as a log line.
+func (p *Provider) reportSkipped(ctx context.Context) {
+	fields := make(logrus.Fields, len(skipReasons))
+	for _, reason := range skipReasons {
+		count := p.skipped.count(reason)
+		fields[reason] = count
+		p.metricsRegistry.ProviderSkippedGauge().With("provider", "marathon", "reason", reason).Set(float64(count))
+	}
+
+	log.FromContext(ctx).WithFields(fields).Debug("Skipped Marathon applications and tasks")
+}
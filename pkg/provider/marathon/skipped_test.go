@@ -0,0 +1,138 @@
+package marathon
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/pkg/metrics"
+	"github.com/containous/traefik/pkg/types"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// skippedGauge records the last value set for each set of label values.
+type skippedGauge struct {
+	values      map[string]float64
+	labelValues []string
+}
+
+func (g *skippedGauge) With(labelValues ...string) gokitmetrics.Gauge {
+	return &skippedGauge{values: g.values, labelValues: labelValues}
+}
+
+func (g *skippedGauge) Set(value float64) {
+	g.values[strings.Join(g.labelValues, ",")] = value
+}
+
+func (g *skippedGauge) Add(delta float64) {
+	g.values[strings.Join(g.labelValues, ",")] += delta
+}
+
+type skippedRegistry struct {
+	metrics.Registry
+	gauge *skippedGauge
+}
+
+func (r skippedRegistry) ProviderSkippedGauge() gokitmetrics.Gauge {
+	return r.gauge
+}
+
+func TestBuildConfigurationSkipped(t *testing.T) {
+	gauge := &skippedGauge{values: make(map[string]float64)}
+
+	p := &Provider{
+		DefaultRule:      "Host(`{{ normalize .Name }}.marathon.localhost`)",
+		ExposedByDefault: true,
+	}
+	p.Constraints = []*types.Constraint{
+		{
+			Key:       "tag",
+			MustMatch: false,
+			Value:     "private",
+		},
+	}
+	p.SetMetricsRegistry(skippedRegistry{Registry: metrics.NewVoidRegistry(), gauge: gauge})
+
+	err := p.Init()
+	require.NoError(t, err)
+
+	app := application(
+		appID("/app"),
+		appPorts(80),
+		withTasks(localhostTask(withTaskID("running"), taskPorts(80))),
+	)
+
+	applications := withApplications(
+		app,
+		application(
+			appID("/disabled"),
+			appPorts(80),
+			withLabel("traefik.enable", "false"),
+			withTasks(localhostTask(taskPorts(80))),
+		),
+		application(
+			appID("/private"),
+			appPorts(80),
+			withLabel("traefik.tags", "private"),
+			withTasks(localhostTask(taskPorts(80))),
+		),
+		application(
+			appID("/staging"),
+			appPorts(80),
+			withTasks(
+				localhostTask(withTaskID("staging-1"), taskPorts(80), taskState(taskStateStaging)),
+				localhostTask(withTaskID("staging-2"), taskPorts(80), taskState(taskStateStaging)),
+			),
+		),
+		application(
+			appID("/noport"),
+			appPorts(80, 81),
+			withTasks(localhostTask(withTaskID("noport"))),
+		),
+	)
+
+	conf := p.buildConfiguration(context.Background(), applications)
+	assert.Contains(t, conf.HTTP.Services, "app")
+
+	expected := map[string]float64{
+		"provider,marathon,reason,disabled":      1,
+		"provider,marathon,reason,constraints":   1,
+		"provider,marathon,reason,filtered_task": 2,
+		"provider,marathon,reason,without_port":  1,
+	}
+	assert.Equal(t, expected, gauge.values)
+
+	// The counts are reset on every configuration build.
+	conf = p.buildConfiguration(context.Background(), withApplications(app))
+	assert.Contains(t, conf.HTTP.Services, "app")
+
+	expected = map[string]float64{
+		"provider,marathon,reason,disabled":      0,
+		"provider,marathon,reason,constraints":   0,
+		"provider,marathon,reason,filtered_task": 0,
+		"provider,marathon,reason,without_port":  0,
+	}
+	assert.Equal(t, expected, gauge.values)
+}
+
+func TestSkippedDisabled(t *testing.T) {
+	var s *skipped
+
+	s.add(skipReasonDisabled, "/app")
+	assert.Zero(t, s.count(skipReasonDisabled))
+}
+
+func TestSkippedOncePerReason(t *testing.T) {
+	s := newSkipped()
+
+	s.add(skipReasonFilteredTask, "task-1")
+	s.add(skipReasonFilteredTask, "task-1")
+	s.add(skipReasonFilteredTask, "task-2")
+	s.add(skipReasonWithoutPort, "task-1")
+
+	assert.Equal(t, 2, s.count(skipReasonFilteredTask))
+	assert.Equal(t, 1, s.count(skipReasonWithoutPort))
+	assert.Zero(t, s.count(skipReasonDisabled))
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/metrics"
 	"github.com/containous/traefik/pkg/safe"
 )
 
@@ -12,3 +13,8 @@ type Provider interface {
 	Provide(configurationChan chan<- config.Message, pool *safe.Pool) error
 	Init() error
 }
+
+// MetricsAware is implemented by the providers which report metrics.
+type MetricsAware interface {
+	SetMetricsRegistry(registry metrics.Registry)
+}
@@ -40,6 +40,7 @@ func (p *Provider) buildConfiguration(ctx context.Context, services []rancherDat
 			provider.BuildTCPRouterConfiguration(ctxService, confFromLabel.TCP)
 			if len(confFromLabel.HTTP.Routers) == 0 &&
 				len(confFromLabel.HTTP.Middlewares) == 0 &&
+				len(confFromLabel.HTTP.ServersTransports) == 0 &&
 				len(confFromLabel.HTTP.Services) == 0 {
 				configurations[service.Name] = confFromLabel
 				continue
@@ -103,6 +104,11 @@ func (p *Provider) buildServiceConfiguration(ctx context.Context, service ranche
 	}
 
 	for _, confService := range configuration.Services {
+		// The weighted, mirroring, and failover services reference other services, and have no servers of their own.
+		if confService.LoadBalancer == nil {
+			continue
+		}
+
 		err := p.addServers(ctx, service, confService.LoadBalancer)
 		if err != nil {
 			return err
@@ -2,6 +2,11 @@ package rules
 
 import (
 	"errors"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"strconv"
 	"strings"
 
 	"github.com/vulcand/predicate"
@@ -63,6 +68,9 @@ func parseDomain(tree *tree) []string {
 	case "and", "or":
 		return append(parseDomain(tree.ruleLeft), parseDomain(tree.ruleRight)...)
 	case "Host", "HostSNI":
+		if tree.not {
+			return nil
+		}
 		return tree.value
 	default:
 		return nil
@@ -89,8 +97,15 @@ func orFunc(left, right treeBuilder) treeBuilder {
 	}
 }
 
+// notFunc negates the tree.
+func notFunc(t treeBuilder) treeBuilder {
+	return func() *tree {
+		return t().notTree()
+	}
+}
+
 func newParser() (predicate.Parser, error) {
-	parserFuncs := make(map[string]interface{})
+	parserFuncs := make(map[string]func(...string) treeBuilder)
 
 	for matcherName := range funcs {
 		matcherName := matcherName
@@ -108,13 +123,90 @@ func newParser() (predicate.Parser, error) {
 		parserFuncs[strings.Title(strings.ToLower(matcherName))] = fn
 	}
 
-	return predicate.NewParser(predicate.Def{
-		Operators: predicate.Operators{
-			AND: andFunc,
-			OR:  orFunc,
-		},
-		Functions: parserFuncs,
-	})
+	return &ruleParser{functions: parserFuncs}, nil
+}
+
+// ruleParser parses the rules of the HTTP routers,
+// made of matchers combined with the AND (&&), OR (||) and NOT (!) operators, and parentheses.
+// It parses the rules as Go expressions, as the predicate parser does, which does not support the NOT operator.
+type ruleParser struct {
+	functions map[string]func(...string) treeBuilder
+}
+
+// Parse parses the rule into a treeBuilder.
+func (p *ruleParser) Parse(rule string) (interface{}, error) {
+	expr, err := goparser.ParseExpr(rule)
+	if err != nil {
+		return nil, err
+	}
+	return p.parseNode(expr)
+}
+
+func (p *ruleParser) parseNode(node ast.Expr) (treeBuilder, error) {
+	switch n := node.(type) {
+	case *ast.BinaryExpr:
+		x, err := p.parseNode(n.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := p.parseNode(n.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		switch n.Op {
+		case token.LAND:
+			return andFunc(x, y), nil
+		case token.LOR:
+			return orFunc(x, y), nil
+		default:
+			return nil, fmt.Errorf("%v is not supported", n.Op)
+		}
+
+	case *ast.UnaryExpr:
+		if n.Op != token.NOT {
+			return nil, fmt.Errorf("%v is not supported", n.Op)
+		}
+
+		x, err := p.parseNode(n.X)
+		if err != nil {
+			return nil, err
+		}
+		return notFunc(x), nil
+
+	case *ast.ParenExpr:
+		return p.parseNode(n.X)
+
+	case *ast.CallExpr:
+		ident, ok := n.Fun.(*ast.Ident)
+		if !ok {
+			return nil, fmt.Errorf("expected identifier, got: %T", n.Fun)
+		}
+
+		fn, ok := p.functions[ident.Name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported function: %s", ident.Name)
+		}
+
+		var values []string
+		for _, arg := range n.Args {
+			lit, ok := arg.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return nil, fmt.Errorf("unsupported argument of %s: a string is expected", ident.Name)
+			}
+
+			value, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse argument: %s, error: %v", lit.Value, err)
+			}
+			values = append(values, value)
+		}
+		return fn(values...), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported %T", node)
+	}
 }
 
 func newTCPParser() (predicate.Parser, error) {
@@ -2,18 +2,25 @@ package rules
 
 import (
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
 
 	"github.com/containous/mux"
+	"github.com/containous/traefik/pkg/ip"
 	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/middlewares/forwardedheaders"
 	"github.com/containous/traefik/pkg/middlewares/requestdecorator"
+	"github.com/containous/traefik/pkg/types"
 	"github.com/vulcand/predicate"
 )
 
 var funcs = map[string]func(*mux.Route, ...string) error{
 	"Host":          host,
 	"HostRegexp":    hostRegexp,
+	"ClientIP":      clientIP,
 	"Path":          path,
 	"PathPrefix":    pathPrefix,
 	"Method":        methods,
@@ -22,10 +29,29 @@ var funcs = map[string]func(*mux.Route, ...string) error{
 	"Query":         query,
 }
 
+// matcherNames returns the sorted names of the matchers of the HTTP rules.
+func matcherNames() []string {
+	names := make([]string, 0, len(funcs))
+	for name := range funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Router handle routing with rules
 type Router struct {
 	*mux.Router
 	parser predicate.Parser
+	routes []*prioritizedRoute
+}
+
+// prioritizedRoute holds what orders a route among the others, see SortRoutes.
+type prioritizedRoute struct {
+	route          *mux.Route
+	priority       int
+	ruleLen        int
+	hostPrecedence int
 }
 
 // NewRouter returns a new router instance.
@@ -45,6 +71,9 @@ func NewRouter() (*Router, error) {
 func (r *Router) AddRoute(rule string, priority int, handler http.Handler) error {
 	parse, err := r.parser.Parse(rule)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "unsupported function") {
+			return fmt.Errorf("error while parsing rule %s: %v (known matchers: %s)", rule, err, strings.Join(matcherNames(), ", "))
+		}
 		return fmt.Errorf("error while parsing rule %s: %v", rule, err)
 	}
 
@@ -53,21 +82,101 @@ func (r *Router) AddRoute(rule string, priority int, handler http.Handler) error
 		return fmt.Errorf("error while parsing rule %s", rule)
 	}
 
+	ruleTree := buildTree()
+	if !ruleTree.hasPositiveMatcher() {
+		return fmt.Errorf("error while parsing rule %s: the rule, or one of its alternatives, only has negated matchers, and would match almost any request", rule)
+	}
+
 	if priority == 0 {
 		priority = len(rule)
 	}
 
 	route := r.NewRoute().Handler(handler).Priority(priority)
-	return addRuleOnRoute(route, buildTree())
+	r.routes = append(r.routes, &prioritizedRoute{route: route, priority: priority, ruleLen: len(rule), hostPrecedence: ruleTree.hostPrecedence()})
+
+	return addRuleOnRoute(route, ruleTree)
+}
+
+// SortRoutes sorts the routes by priority, the highest first, the ties being broken by the precedence of their hosts,
+// the most specific first, e.g. Host(`a.example.com`) before Host(`*.example.com`), and then by the length of the rules, the longest first.
+// The routes with the same priority, hosts of the same precedence, and rules of the same length keep the order in which they were added.
+func (r *Router) SortRoutes() {
+	sort.SliceStable(r.routes, func(i, j int) bool {
+		if r.routes[i].priority != r.routes[j].priority {
+			return r.routes[i].priority > r.routes[j].priority
+		}
+		if r.routes[i].hostPrecedence != r.routes[j].hostPrecedence {
+			return r.routes[i].hostPrecedence > r.routes[j].hostPrecedence
+		}
+		return r.routes[i].ruleLen > r.routes[j].ruleLen
+	})
+
+	// The routes are sorted by mux on their priority only, which is then their rank.
+	for i, rt := range r.routes {
+		rt.route.Priority(len(r.routes) - i)
+	}
+
+	r.Router.SortRoutes()
 }
 
 type tree struct {
 	matcher   string
+	not       bool
 	value     []string
 	ruleLeft  *tree
 	ruleRight *tree
 }
 
+// notTree negates the tree: a matcher is negated, and the operands of an operator are negated, the operator being inverted.
+func (t *tree) notTree() *tree {
+	switch t.matcher {
+	case "and", "or":
+		t.matcher = map[string]string{"and": "or", "or": "and"}[t.matcher]
+		t.ruleLeft = t.ruleLeft.notTree()
+		t.ruleRight = t.ruleRight.notTree()
+	default:
+		t.not = !t.not
+	}
+	return t
+}
+
+// hasPositiveMatcher tells whether any request matching the tree has to match a matcher which is not negated.
+func (t *tree) hasPositiveMatcher() bool {
+	switch t.matcher {
+	case "and":
+		return t.ruleLeft.hasPositiveMatcher() || t.ruleRight.hasPositiveMatcher()
+	case "or":
+		return t.ruleLeft.hasPositiveMatcher() && t.ruleRight.hasPositiveMatcher()
+	default:
+		return !t.not
+	}
+}
+
+// hostPrecedence returns the highest precedence of the domains of the Host matchers of the tree which are not negated, see types.DomainPrecedence.
+func (t *tree) hostPrecedence() int {
+	switch t.matcher {
+	case "and", "or":
+		left, right := t.ruleLeft.hostPrecedence(), t.ruleRight.hostPrecedence()
+		if left > right {
+			return left
+		}
+		return right
+	case "Host":
+		precedence := 0
+		if t.not {
+			return precedence
+		}
+		for _, host := range t.value {
+			if p := types.DomainPrecedence(host); p > precedence {
+				precedence = p
+			}
+		}
+		return precedence
+	default:
+		return 0
+	}
+}
+
 func path(route *mux.Route, paths ...string) error {
 	rt := route.Subrouter()
 
@@ -92,9 +201,11 @@ func pathPrefix(route *mux.Route, paths ...string) error {
 	return nil
 }
 
+// host matches the host of the request against domains, which can be wildcard domains such as *.example.com.
+// The host is compared case-insensitively, without its port, and without its trailing period.
 func host(route *mux.Route, hosts ...string) error {
 	for i, host := range hosts {
-		hosts[i] = strings.ToLower(host)
+		hosts[i] = types.CanonicalDomain(host)
 	}
 
 	route.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
@@ -107,7 +218,7 @@ func host(route *mux.Route, hosts ...string) error {
 		flatH := requestdecorator.GetCNAMEFlatten(req.Context())
 		if len(flatH) > 0 {
 			for _, host := range hosts {
-				if strings.EqualFold(reqHost, host) || strings.EqualFold(flatH, host) {
+				if types.MatchDomain(reqHost, host) || types.MatchDomain(flatH, host) {
 					return true
 				}
 				log.FromContext(req.Context()).Debugf("CNAMEFlattening: request %s which resolved to %s, is not matched to route %s", reqHost, flatH, host)
@@ -116,39 +227,86 @@ func host(route *mux.Route, hosts ...string) error {
 		}
 
 		for _, host := range hosts {
-			if reqHost == host {
+			if types.MatchDomain(reqHost, host) {
 				return true
 			}
-
-			// Check for match on trailing period on host
-			if last := len(host) - 1; last >= 0 && host[last] == '.' {
-				h := host[:last]
-				if reqHost == h {
-					return true
-				}
-			}
-
-			// Check for match on trailing period on request
-			if last := len(reqHost) - 1; last >= 0 && reqHost[last] == '.' {
-				h := reqHost[:last]
-				if h == host {
-					return true
-				}
-			}
 		}
 		return false
 	})
 	return nil
 }
 
+// hostRegexp matches the host of the request against templates such as {tenant:[a-z0-9]+}.example.com,
+// whose named groups are set as the variables of the route.
+// The host is compared case-insensitively, without its port, and without its trailing period, as Host does.
 func hostRegexp(route *mux.Route, hosts ...string) error {
-	router := route.Subrouter()
+	templates := make([]*mux.Route, 0, len(hosts))
 	for _, host := range hosts {
-		tmpRt := router.Host(host)
+		tmpRt := new(mux.Route).Host(strings.TrimSuffix(host, "."))
 		if tmpRt.GetError() != nil {
 			return tmpRt.GetError()
 		}
+		templates = append(templates, tmpRt)
+	}
+
+	route.MatcherFunc(func(req *http.Request, match *mux.RouteMatch) bool {
+		// The templates only look at the host of the request.
+		hostReq := &http.Request{Host: requestHost(req), URL: &url.URL{}}
+
+		for _, tmpRt := range templates {
+			tmpMatch := &mux.RouteMatch{}
+			if !tmpRt.Match(hostReq, tmpMatch) {
+				continue
+			}
+
+			if match.Vars == nil {
+				match.Vars = make(map[string]string)
+			}
+			for name, value := range tmpMatch.Vars {
+				match.Vars[name] = value
+			}
+			return true
+		}
+		return false
+	})
+	return nil
+}
+
+// requestHost returns the host of the request, without its port and its trailing period.
+func requestHost(req *http.Request) string {
+	host := req.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+// clientIP matches the client IP of the request, see forwardedheaders.ClientIP,
+// against IPs or CIDRs, IPv4 or IPv6, e.g. 10.0.0.0/8, 192.168.1.5, or fd00::/8.
+func clientIP(route *mux.Route, clientIPs ...string) error {
+	for _, clientIP := range clientIPs {
+		if net.ParseIP(clientIP) != nil {
+			continue
+		}
+
+		if _, _, err := net.ParseCIDR(clientIP); err != nil {
+			return fmt.Errorf("invalid ClientIP %q: an IP or a CIDR, such as 192.168.1.5 or 10.0.0.0/8, is expected", clientIP)
+		}
 	}
+
+	checker, err := ip.NewChecker(clientIPs)
+	if err != nil {
+		return err
+	}
+
+	route.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
+		ok, err := checker.Contains(forwardedheaders.ClientIP(req))
+		if err != nil {
+			log.FromContext(req.Context()).Debugf("Could not retrieve the client IP of the request: %v", err)
+			return false
+		}
+		return ok
+	})
 	return nil
 }
 
@@ -164,10 +322,17 @@ func headersRegexp(route *mux.Route, headers ...string) error {
 	return route.HeadersRegexp(headers...).GetError()
 }
 
+// query matches the parameters of the query string, each one given as key=value, or as key to only require its presence.
+// The parameters of the request are compared once decoded, and all of them must match.
 func query(route *mux.Route, query ...string) error {
 	var queries []string
 	for _, elem := range query {
-		queries = append(queries, strings.Split(elem, "=")...)
+		kv := strings.SplitN(elem, "=", 2)
+		if len(kv) == 1 {
+			// An empty value matches any value of the key, which must be present.
+			kv = append(kv, "")
+		}
+		queries = append(queries, kv...)
 	}
 
 	route.Queries(queries...)
@@ -198,7 +363,7 @@ func addRuleOnRouter(router *mux.Router, rule *tree) error {
 			return err
 		}
 
-		return funcs[rule.matcher](router.NewRoute(), rule.value...)
+		return addMatcher(router.NewRoute(), rule)
 	}
 }
 
@@ -226,8 +391,25 @@ func addRuleOnRoute(route *mux.Route, rule *tree) error {
 			return err
 		}
 
+		return addMatcher(route, rule)
+	}
+}
+
+// addMatcher adds the matcher of the rule to the route, negated if need be.
+func addMatcher(route *mux.Route, rule *tree) error {
+	if !rule.not {
 		return funcs[rule.matcher](route, rule.value...)
 	}
+
+	router := mux.NewRouter().SkipClean(true)
+	if err := funcs[rule.matcher](router.NewRoute(), rule.value...); err != nil {
+		return err
+	}
+
+	route.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
+		return !router.Match(req, &mux.RouteMatch{})
+	})
+	return nil
 }
 
 func checkRule(rule *tree) error {
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/containous/mux"
+	"github.com/containous/traefik/pkg/middlewares/forwardedheaders"
 	"github.com/containous/traefik/pkg/middlewares/requestdecorator"
 	"github.com/containous/traefik/pkg/testhelpers"
 	"github.com/stretchr/testify/assert"
@@ -57,6 +58,16 @@ func Test_addRoute(t *testing.T) {
 				"http://localhost/foo": http.StatusOK,
 			},
 		},
+		{
+			desc: "Host with wildcard",
+			rule: "Host(`*.example.com`)",
+			expected: map[string]int{
+				"http://foo.example.com/foo":     http.StatusOK,
+				"http://FOO.Example.com./foo":    http.StatusOK,
+				"http://example.com/foo":         http.StatusNotFound,
+				"http://bar.foo.example.com/foo": http.StatusNotFound,
+			},
+		},
 		{
 			desc: "Host with trailing period in domain",
 			rule: "Host(`localhost`)",
@@ -142,6 +153,17 @@ func Test_addRoute(t *testing.T) {
 				"http://barcom":      http.StatusNotFound,
 			},
 		},
+		{
+			desc: "HostRegexp with named group AND PathPrefix",
+			rule: "HostRegexp(`{tenant:[a-z0-9]+}.example.com`) && PathPrefix(`/api`)",
+			expected: map[string]int{
+				"http://acme.example.com/api/users":      http.StatusOK,
+				"http://ACME.example.com:8080/api/users": http.StatusOK,
+				"http://acme.example.com./api":           http.StatusOK,
+				"http://acme.example.com/web":            http.StatusNotFound,
+				"http://acme.example.org/api":            http.StatusNotFound,
+			},
+		},
 		{
 			desc: "Methods with GET",
 			rule: "Method(`GET`)",
@@ -221,6 +243,119 @@ func Test_addRoute(t *testing.T) {
 				"http://localhost/foo?bar=baz":         http.StatusNotFound,
 			},
 		},
+		{
+			desc: "Query with key only",
+			rule: "Query(`debug`)",
+			expected: map[string]int{
+				"http://localhost/foo?debug":         http.StatusOK,
+				"http://localhost/foo?debug=1":       http.StatusOK,
+				"http://localhost/foo?foo=bar&debug": http.StatusOK,
+				"http://localhost/foo?foo=debug":     http.StatusNotFound,
+				"http://localhost/foo":               http.StatusNotFound,
+			},
+		},
+		{
+			desc: "Query with encoded value",
+			rule: "Query(`q=a b&c`)",
+			expected: map[string]int{
+				"http://localhost/foo?q=a%20b%26c": http.StatusOK,
+				"http://localhost/foo?q=a+b%26c":   http.StatusOK,
+				"http://localhost/foo?q=a%20b":     http.StatusNotFound,
+			},
+		},
+		{
+			desc: "Query with value containing an equal sign",
+			rule: "Query(`token=a=b`)",
+			expected: map[string]int{
+				"http://localhost/foo?token=a%3Db": http.StatusOK,
+				"http://localhost/foo?token=a":     http.StatusNotFound,
+			},
+		},
+		{
+			desc: "Query AND Query AND Host AND Path",
+			rule: "Host(`localhost`) && Path(`/foo`) && Query(`utm_source=internal`) && Query(`v=2`)",
+			expected: map[string]int{
+				"http://localhost/foo?utm_source=internal&v=2":   http.StatusOK,
+				"http://localhost/foo?v=2&utm_source=internal":   http.StatusOK,
+				"http://localhost/foo?utm_source=internal":       http.StatusNotFound,
+				"http://localhost/foo?v=2":                       http.StatusNotFound,
+				"http://localhost/bar?utm_source=internal&v=2":   http.StatusNotFound,
+				"http://example.com/foo?utm_source=internal&v=2": http.StatusNotFound,
+			},
+		},
+		{
+			desc: "PathPrefix AND NOT Path",
+			rule: "PathPrefix(`/app`) && !Path(`/app/metrics`)",
+			expected: map[string]int{
+				"http://localhost/app":           http.StatusOK,
+				"http://localhost/app/users":     http.StatusOK,
+				"http://localhost/app/metrics":   http.StatusNotFound,
+				"http://localhost/app/metrics/a": http.StatusOK,
+				"http://localhost/other":         http.StatusNotFound,
+			},
+		},
+		{
+			desc: "NOT binds tighter than AND and OR",
+			rule: "Host(`foo`) || Host(`localhost`) && !PathPrefix(`/private`)",
+			expected: map[string]int{
+				"http://foo/private":       http.StatusOK,
+				"http://localhost/public":  http.StatusOK,
+				"http://localhost/private": http.StatusNotFound,
+			},
+		},
+		{
+			desc: "NOT of parenthesis",
+			rule: "PathPrefix(`/`) && !(Host(`foo`) || Method(`POST`))",
+			expected: map[string]int{
+				"http://localhost/a": http.StatusOK,
+				"http://foo/a":       http.StatusNotFound,
+			},
+		},
+		{
+			desc: "NOT of parenthesis with AND",
+			rule: "Host(`localhost`) && !(PathPrefix(`/a`) && Query(`debug`))",
+			expected: map[string]int{
+				"http://localhost/a":         http.StatusOK,
+				"http://localhost/b?debug=1": http.StatusOK,
+				"http://localhost/a?debug=1": http.StatusNotFound,
+			},
+		},
+		{
+			desc: "double NOT",
+			rule: "!!Host(`localhost`)",
+			expected: map[string]int{
+				"http://localhost/a": http.StatusOK,
+				"http://foo/a":       http.StatusNotFound,
+			},
+		},
+		{
+			desc: "NOT with the lowercase matcher",
+			rule: "Host(`localhost`) && !path(`/a`)",
+			expected: map[string]int{
+				"http://localhost/a": http.StatusNotFound,
+				"http://localhost/b": http.StatusOK,
+			},
+		},
+		{
+			desc:          "NOT only",
+			rule:          "!Path(`/metrics`)",
+			expectedError: true,
+		},
+		{
+			desc:          "NOT only, in one of the alternatives",
+			rule:          "Host(`localhost`) || !Path(`/metrics`)",
+			expectedError: true,
+		},
+		{
+			desc:          "NOT of parenthesis only",
+			rule:          "!(Host(`localhost`) && Path(`/metrics`))",
+			expectedError: true,
+		},
+		{
+			desc:          "unsupported unary operator",
+			rule:          "Host(`localhost`) && -Path(`/metrics`)",
+			expectedError: true,
+		},
 		{
 			desc: "Rule with simple path",
 			rule: `Path("/a")`,
@@ -372,6 +507,11 @@ func Test_addRoute(t *testing.T) {
 			rule:          `HostRegexp("{test")`,
 			expectedError: true,
 		},
+		{
+			desc:          "Rule HostRegexp with invalid regexp",
+			rule:          "HostRegexp(`{tenant:[a-z}.example.com`)",
+			expectedError: true,
+		},
 		{
 			desc:          "Rule Headers with error",
 			rule:          `Headers("titi")`,
@@ -382,11 +522,6 @@ func Test_addRoute(t *testing.T) {
 			rule:          `HeadersRegexp("titi")`,
 			expectedError: true,
 		},
-		{
-			desc:          "Rule Query",
-			rule:          `Query("titi")`,
-			expectedError: true,
-		},
 		{
 			desc:          "Rule Query with bad syntax",
 			rule:          `Query("titi={test")`,
@@ -508,6 +643,118 @@ func Test_addRoutePriority(t *testing.T) {
 			},
 			expected: "header2",
 		},
+		{
+			desc: "Explicit priority over the longest rule",
+			path: "/api/users",
+			cases: []Case{
+				{
+					xFrom:    "catch-all",
+					rule:     "PathPrefix(`/`)",
+					priority: 100,
+				},
+				{
+					xFrom: "api",
+					rule:  "PathPrefix(`/api`)",
+				},
+			},
+			expected: "catch-all",
+		},
+		{
+			desc: "Same explicit priority broken by the longest rule",
+			path: "/api/users",
+			cases: []Case{
+				{
+					xFrom:    "catch-all",
+					rule:     "PathPrefix(`/`)",
+					priority: 100,
+				},
+				{
+					xFrom:    "api",
+					rule:     "PathPrefix(`/api`)",
+					priority: 100,
+				},
+			},
+			expected: "api",
+		},
+		{
+			desc: "Same priority and rule length in the order of addition",
+			path: "/api",
+			cases: []Case{
+				{
+					xFrom:    "first",
+					rule:     "PathPrefix(`/a`)",
+					priority: 10,
+				},
+				{
+					xFrom:    "second",
+					rule:     "PathPrefix(`/`) ",
+					priority: 10,
+				},
+			},
+			expected: "first",
+		},
+		{
+			desc: "Exact host over wildcard host added first",
+			path: "http://a.example.com/",
+			cases: []Case{
+				{
+					xFrom: "wildcard",
+					rule:  "Host(`*.example.com`)",
+				},
+				{
+					xFrom: "exact",
+					rule:  "Host(`a.example.com`)",
+				},
+			},
+			expected: "exact",
+		},
+		{
+			desc: "Exact host, case insensitive, over wildcard host",
+			path: "http://a.example.com./",
+			cases: []Case{
+				{
+					xFrom: "wildcard",
+					rule:  "Host(`*.example.com`)",
+				},
+				{
+					xFrom: "exact",
+					rule:  "Host(`A.example.com`)",
+				},
+			},
+			expected: "exact",
+		},
+		{
+			desc: "Longest wildcard host over the shortest",
+			path: "http://a.b.example.com/",
+			cases: []Case{
+				{
+					xFrom: "shortest",
+					rule:  "Host(`*.*.example.com`)",
+				},
+				{
+					xFrom: "longest",
+					rule:  "Host(`*.b.example.com`)",
+				},
+			},
+			expected: "longest",
+		},
+		{
+			desc: "Exact host over longer wildcard rule with the same priority",
+			path: "http://a.example.com/foo",
+			cases: []Case{
+				{
+					xFrom:    "wildcard",
+					rule:     "Host(`*.example.com`) && PathPrefix(`/foo`)",
+					priority: 10,
+				},
+				{
+					xFrom:    "exact",
+					rule:     "Host(`a.example.com`)",
+					priority: 10,
+				},
+			},
+			expected: "exact",
+		},
 		{
 			desc: "Higher priority on longest rule (longest first)",
 			path: "/mypath",
@@ -581,13 +828,140 @@ func Test_addRoutePriority(t *testing.T) {
 			w := httptest.NewRecorder()
 			req := testhelpers.MustNewRequest(http.MethodGet, test.path, nil)
 
-			router.ServeHTTP(w, req)
+			// RequestDecorator is necessary for the host rule
+			reqHost := requestdecorator.New(nil)
+			reqHost.ServeHTTP(w, req, router.ServeHTTP)
 
 			assert.Equal(t, test.expected, w.Header().Get("X-From"))
 		})
 	}
 }
 
+func TestAddRoute_unknownMatcher(t *testing.T) {
+	router, err := NewRouter()
+	require.NoError(t, err)
+
+	err = router.AddRoute("Host(`localhost`) && Queries(`v=2`)", 0, http.NotFoundHandler())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported function: Queries")
+	assert.Contains(t, err.Error(), "known matchers: ClientIP, Headers, HeadersRegexp, Host, HostRegexp, Method, Path, PathPrefix, Query")
+}
+
+func TestAddRoute_negationOnly(t *testing.T) {
+	router, err := NewRouter()
+	require.NoError(t, err)
+
+	err = router.AddRoute("!PathPrefix(`/metrics`)", 0, http.NotFoundHandler())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only has negated matchers")
+}
+
+func TestClientIP(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		rule          string
+		depth         int
+		remoteAddr    string
+		xForwardedFor string
+		expected      int
+	}{
+		{
+			desc:       "IPv4 CIDR",
+			rule:       "ClientIP(`10.0.0.0/8`, `192.168.1.5`)",
+			remoteAddr: "10.1.2.3:4567",
+			expected:   http.StatusOK,
+		},
+		{
+			desc:       "IPv4",
+			rule:       "ClientIP(`10.0.0.0/8`, `192.168.1.5`)",
+			remoteAddr: "192.168.1.5:4567",
+			expected:   http.StatusOK,
+		},
+		{
+			desc:       "IPv4 not matching",
+			rule:       "ClientIP(`10.0.0.0/8`, `192.168.1.5`)",
+			remoteAddr: "192.168.1.6:4567",
+			expected:   http.StatusNotFound,
+		},
+		{
+			desc:       "IPv6 CIDR",
+			rule:       "ClientIP(`fd00::/8`)",
+			remoteAddr: "[fd12:3456::1]:4567",
+			expected:   http.StatusOK,
+		},
+		{
+			desc:       "IPv6 CIDR not matching",
+			rule:       "ClientIP(`fd00::/8`)",
+			remoteAddr: "[2001:db8::1]:4567",
+			expected:   http.StatusNotFound,
+		},
+		{
+			desc:          "X-Forwarded-For ignored without depth",
+			rule:          "ClientIP(`10.0.0.0/8`)",
+			remoteAddr:    "192.168.1.6:4567",
+			xForwardedFor: "10.1.2.3",
+			expected:      http.StatusNotFound,
+		},
+		{
+			desc:          "X-Forwarded-For with depth",
+			rule:          "ClientIP(`10.0.0.0/8`)",
+			depth:         2,
+			remoteAddr:    "192.168.1.6:4567",
+			xForwardedFor: "10.1.2.3, 192.168.1.7",
+			expected:      http.StatusOK,
+		},
+		{
+			desc:          "X-Forwarded-For shorter than the depth",
+			rule:          "ClientIP(`10.0.0.0/8`)",
+			depth:         2,
+			remoteAddr:    "10.1.2.3:4567",
+			xForwardedFor: "192.168.1.7",
+			expected:      http.StatusNotFound,
+		},
+		{
+			desc:       "ClientIP AND Path",
+			rule:       "ClientIP(`10.0.0.0/8`) && Path(`/internal`)",
+			remoteAddr: "10.1.2.3:4567",
+			expected:   http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			router, err := NewRouter()
+			require.NoError(t, err)
+
+			err = router.AddRoute(test.rule, 0, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+			require.NoError(t, err)
+
+			handler, err := forwardedheaders.NewXForwarded(true, nil, test.depth, router)
+			require.NoError(t, err)
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost/internal", nil)
+			req.RemoteAddr = test.remoteAddr
+			if test.xForwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", test.xForwardedFor)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expected, w.Code)
+		})
+	}
+}
+
+func TestClientIP_invalid(t *testing.T) {
+	router, err := NewRouter()
+	require.NoError(t, err)
+
+	err = router.AddRoute("ClientIP(`10.0.0.0/8`, `10.0.0/33`)", 0, http.NotFoundHandler())
+	assert.EqualError(t, err, `invalid ClientIP "10.0.0/33": an IP or a CIDR, such as 192.168.1.5 or 10.0.0.0/8, is expected`)
+}
+
 func TestHostRegexp(t *testing.T) {
 	testCases := []struct {
 		desc    string
@@ -638,6 +1012,28 @@ func TestHostRegexp(t *testing.T) {
 				"http://barcom":      false,
 			},
 		},
+		{
+			desc:    "named group",
+			hostExp: "{tenant:[a-z0-9]+}.example.com",
+			urls: map[string]bool{
+				"http://acme.example.com":      true,
+				"http://ACME.Example.com":      true,
+				"http://acme.example.com:8080": true,
+				"http://acme.example.com.":     true,
+				"http://acme.example.com.:443": true,
+				"http://a.b.example.com":       false,
+				"http://example.com":           false,
+			},
+		},
+		{
+			desc:    "trailing period in the template",
+			hostExp: "{tenant:[a-z0-9]+}.example.com.",
+			urls: map[string]bool{
+				"http://acme.example.com":  true,
+				"http://acme.example.com.": true,
+				"http://acme.example.org":  false,
+			},
+		},
 		{
 			desc:    "insensitive host simple",
 			hostExp: "foo.bar.com",
@@ -698,6 +1094,12 @@ func TestParseDomains(t *testing.T) {
 			domain:        []string{"foo.bar"},
 			errorExpected: false,
 		},
+		{
+			description:   "negated Host rule",
+			expression:    "Host(`foo.bar`) && !Host(`test.bar`)",
+			domain:        []string{"foo.bar"},
+			errorExpected: false,
+		},
 		{
 			description:   "Host rule with no domain",
 			expression:    "Host() && Path(`/test`)",
@@ -9,16 +9,22 @@ import (
 func mergeConfiguration(configurations config.Configurations) config.Configuration {
 	conf := config.Configuration{
 		HTTP: &config.HTTPConfiguration{
-			Routers:     make(map[string]*config.Router),
-			Middlewares: make(map[string]*config.Middleware),
-			Services:    make(map[string]*config.Service),
+			Routers:           make(map[string]*config.Router),
+			Middlewares:       make(map[string]*config.Middleware),
+			Services:          make(map[string]*config.Service),
+			ServersTransports: make(map[string]*config.ServersTransport),
 		},
 		TCP: &config.TCPConfiguration{
 			Routers:  make(map[string]*config.TCPRouter),
 			Services: make(map[string]*config.TCPService),
 		},
+		UDP: &config.UDPConfiguration{
+			Routers:  make(map[string]*config.UDPRouter),
+			Services: make(map[string]*config.UDPService),
+		},
 		TLSOptions: make(map[string]tls.TLS),
 		TLSStores:  make(map[string]tls.Store),
+		Sources:    config.NewSources(),
 	}
 
 	for provider, configuration := range configurations {
@@ -32,6 +38,9 @@ func mergeConfiguration(configurations config.Configurations) config.Configurati
 			for serviceName, service := range configuration.HTTP.Services {
 				conf.HTTP.Services[internal.MakeQualifiedName(provider, serviceName)] = service
 			}
+			for transportName, transport := range configuration.HTTP.ServersTransports {
+				conf.HTTP.ServersTransports[internal.MakeQualifiedName(provider, transportName)] = transport
+			}
 		}
 
 		if configuration.TCP != nil {
@@ -42,16 +51,46 @@ func mergeConfiguration(configurations config.Configurations) config.Configurati
 				conf.TCP.Services[internal.MakeQualifiedName(provider, serviceName)] = service
 			}
 		}
+
+		if configuration.UDP != nil {
+			for routerName, router := range configuration.UDP.Routers {
+				conf.UDP.Routers[internal.MakeQualifiedName(provider, routerName)] = router
+			}
+			for serviceName, service := range configuration.UDP.Services {
+				conf.UDP.Services[internal.MakeQualifiedName(provider, serviceName)] = service
+			}
+		}
 		conf.TLS = append(conf.TLS, configuration.TLS...)
 
+		if configuration.Sources != nil {
+			mergeSources(conf.Sources.Routers, configuration.Sources.Routers, provider)
+			mergeSources(conf.Sources.Middlewares, configuration.Sources.Middlewares, provider)
+			mergeSources(conf.Sources.Services, configuration.Sources.Services, provider)
+			mergeSources(conf.Sources.ServersTransports, configuration.Sources.ServersTransports, provider)
+			mergeSources(conf.Sources.TCPRouters, configuration.Sources.TCPRouters, provider)
+			mergeSources(conf.Sources.TCPServices, configuration.Sources.TCPServices, provider)
+			mergeSources(conf.Sources.UDPRouters, configuration.Sources.UDPRouters, provider)
+			mergeSources(conf.Sources.UDPServices, configuration.Sources.UDPServices, provider)
+		}
+
 		for key, store := range configuration.TLSStores {
 			conf.TLSStores[key] = store
 		}
 
 		for key, config := range configuration.TLSOptions {
-			conf.TLSOptions[key] = config
+			if key == tls.DefaultTLSConfigName {
+				conf.TLSOptions[key] = config
+				continue
+			}
+			conf.TLSOptions[internal.MakeQualifiedName(provider, key)] = config
 		}
 	}
 
 	return conf
 }
+
+func mergeSources(merged, sources map[string]config.Source, provider string) {
+	for name, source := range sources {
+		merged[internal.MakeQualifiedName(provider, name)] = source
+	}
+}
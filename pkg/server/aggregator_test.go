@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/tls"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,9 +18,10 @@ func TestAggregator(t *testing.T) {
 			desc:  "Nil returns an empty configuration",
 			given: nil,
 			expected: &config.HTTPConfiguration{
-				Routers:     make(map[string]*config.Router),
-				Middlewares: make(map[string]*config.Middleware),
-				Services:    make(map[string]*config.Service),
+				Routers:           make(map[string]*config.Router),
+				Middlewares:       make(map[string]*config.Middleware),
+				Services:          make(map[string]*config.Service),
+				ServersTransports: make(map[string]*config.ServersTransport),
 			},
 		},
 		{
@@ -36,6 +38,9 @@ func TestAggregator(t *testing.T) {
 						Services: map[string]*config.Service{
 							"service-1": {},
 						},
+						ServersTransports: map[string]*config.ServersTransport{
+							"transport-1": {},
+						},
 					},
 				},
 			},
@@ -49,6 +54,9 @@ func TestAggregator(t *testing.T) {
 				Services: map[string]*config.Service{
 					"provider-1.service-1": {},
 				},
+				ServersTransports: map[string]*config.ServersTransport{
+					"provider-1.transport-1": {},
+				},
 			},
 		},
 		{
@@ -94,6 +102,7 @@ func TestAggregator(t *testing.T) {
 					"provider-1.service-1": {},
 					"provider-2.service-1": {},
 				},
+				ServersTransports: make(map[string]*config.ServersTransport),
 			},
 		},
 	}
@@ -108,3 +117,59 @@ func TestAggregator(t *testing.T) {
 		})
 	}
 }
+
+func TestAggregatorTLSOptions(t *testing.T) {
+	given := config.Configurations{
+		"file": &config.Configuration{
+			TLSOptions: map[string]tls.TLS{
+				"default": {MinVersion: "VersionTLS12"},
+				"modern":  {MinVersion: "VersionTLS13"},
+			},
+		},
+		"docker": &config.Configuration{
+			TLSOptions: map[string]tls.TLS{
+				"modern": {MinVersion: "VersionTLS11"},
+			},
+		},
+	}
+
+	expected := map[string]tls.TLS{
+		"default":       {MinVersion: "VersionTLS12"},
+		"file.modern":   {MinVersion: "VersionTLS13"},
+		"docker.modern": {MinVersion: "VersionTLS11"},
+	}
+
+	actual := mergeConfiguration(given)
+	assert.Equal(t, expected, actual.TLSOptions)
+}
+
+func TestAggregatorSources(t *testing.T) {
+	given := config.Configurations{
+		"file": &config.Configuration{
+			HTTP: &config.HTTPConfiguration{
+				Routers: map[string]*config.Router{
+					"router-1": {},
+				},
+			},
+			Sources: &config.Sources{
+				Routers: map[string]config.Source{
+					"router-1": {File: "/etc/traefik/dynamic.toml", Line: 3},
+				},
+			},
+		},
+		"provider-1": &config.Configuration{
+			HTTP: &config.HTTPConfiguration{
+				Routers: map[string]*config.Router{
+					"router-1": {},
+				},
+			},
+		},
+	}
+
+	actual := mergeConfiguration(given)
+
+	expected := map[string]config.Source{
+		"file.router-1": {File: "/etc/traefik/dynamic.toml", Line: 3},
+	}
+	assert.Equal(t, expected, actual.Sources.Routers)
+}
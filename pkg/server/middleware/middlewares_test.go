@@ -369,3 +369,185 @@ func TestBuilder_buildConstructor(t *testing.T) {
 		})
 	}
 }
+
+func TestBuilder_BuildChainPathMiddlewares(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		buildChain         []string
+		path               string
+		expectedPath       string
+		expectedRawPath    string
+		expectedRequestURI string
+		expectedHeader     string
+		expectedStatus     int
+	}{
+		{
+			desc:               "stripPrefix then addPrefix",
+			buildChain:         []string{"strip", "add"},
+			path:               "/api/users",
+			expectedPath:       "/v2/users",
+			expectedRequestURI: "/v2/users",
+			expectedStatus:     http.StatusOK,
+		},
+		{
+			// The prefix to strip is no longer at the beginning of the path.
+			desc:           "addPrefix then stripPrefix",
+			buildChain:     []string{"add", "strip"},
+			path:           "/api/users",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:               "stripPrefix then addPrefix with an encoded slash",
+			buildChain:         []string{"strip", "add"},
+			path:               "/api/users%2Fadmin",
+			expectedPath:       "/v2/users/admin",
+			expectedRawPath:    "/v2/users%2Fadmin",
+			expectedRequestURI: "/v2/users%2Fadmin",
+			expectedStatus:     http.StatusOK,
+		},
+		{
+			desc:               "stripPrefix then replacePathRegex then addPrefix",
+			buildChain:         []string{"strip", "replace-regex", "add"},
+			path:               "/api/users%2Fadmin",
+			expectedPath:       "/v2/accounts/users/admin",
+			expectedRawPath:    "/v2/accounts/users%2Fadmin",
+			expectedRequestURI: "/v2/accounts/users%2Fadmin",
+			expectedHeader:     "/users%2Fadmin",
+			expectedStatus:     http.StatusOK,
+		},
+		{
+			desc:               "replacePath then addPrefix",
+			buildChain:         []string{"replace", "add"},
+			path:               "/api/users",
+			expectedPath:       "/v2/foo/bar",
+			expectedRawPath:    "/v2/foo%2Fbar",
+			expectedRequestURI: "/v2/foo%2Fbar",
+			expectedHeader:     "/api/users",
+			expectedStatus:     http.StatusOK,
+		},
+	}
+
+	rtConf := config.NewRuntimeConfig(config.Configuration{
+		HTTP: &config.HTTPConfiguration{
+			Middlewares: map[string]*config.Middleware{
+				"strip": {
+					StripPrefix: &config.StripPrefix{Prefixes: []string{"/api"}},
+				},
+				"add": {
+					AddPrefix: &config.AddPrefix{Prefix: "/v2"},
+				},
+				"replace": {
+					ReplacePath: &config.ReplacePath{Path: "/foo%2Fbar"},
+				},
+				"replace-regex": {
+					ReplacePathRegex: &config.ReplacePathRegex{Regex: "^/(.*)", Replacement: "/accounts/$1"},
+				},
+			},
+		},
+	})
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			builder := NewBuilder(rtConf.Middlewares, nil)
+
+			var actualPath, actualRawPath, actualRequestURI, actualHeader string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				actualPath = req.URL.Path
+				actualRawPath = req.URL.RawPath
+				actualRequestURI = req.RequestURI
+				actualHeader = req.Header.Get("X-Replaced-Path")
+			})
+
+			handler, err := builder.BuildChain(context.Background(), test.buildChain).Then(next)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://foo"+test.path, nil)
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedPath, actualPath)
+			assert.Equal(t, test.expectedRawPath, actualRawPath)
+			assert.Equal(t, test.expectedRequestURI, actualRequestURI)
+			assert.Equal(t, test.expectedHeader, actualHeader)
+		})
+	}
+}
+
+func TestBuilder_BuildChainNestedChains(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		buildChain   []string
+		expectedPath string
+	}{
+		{
+			desc:         "inline middlewares",
+			buildChain:   []string{"a", "b", "c", "other.d"},
+			expectedPath: "/d/c/b/a/foo",
+		},
+		{
+			desc:         "chain of the same middlewares",
+			buildChain:   []string{"flat"},
+			expectedPath: "/d/c/b/a/foo",
+		},
+		{
+			desc:         "nested chains of the same middlewares",
+			buildChain:   []string{"nested-3"},
+			expectedPath: "/d/c/b/a/foo",
+		},
+		{
+			desc:         "nested chain between inline middlewares",
+			buildChain:   []string{"a", "nested-1", "other.d"},
+			expectedPath: "/d/b/a/a/foo",
+		},
+		{
+			desc:         "chain of another provider",
+			buildChain:   []string{"other.chain"},
+			expectedPath: "/d/b/a/foo",
+		},
+	}
+
+	middlewares := map[string]*config.Middleware{
+		"file.a":        {AddPrefix: &config.AddPrefix{Prefix: "/a"}},
+		"file.b":        {AddPrefix: &config.AddPrefix{Prefix: "/b"}},
+		"file.c":        {AddPrefix: &config.AddPrefix{Prefix: "/c"}},
+		"other.d":       {AddPrefix: &config.AddPrefix{Prefix: "/d"}},
+		"file.flat":     {Chain: &config.Chain{Middlewares: []string{"a", "b", "c", "other.d"}}},
+		"file.nested-1": {Chain: &config.Chain{Middlewares: []string{"a", "b"}}},
+		"file.nested-2": {Chain: &config.Chain{Middlewares: []string{"nested-1", "c"}}},
+		"file.nested-3": {Chain: &config.Chain{Middlewares: []string{"nested-2", "other.d"}}},
+		"other.chain":   {Chain: &config.Chain{Middlewares: []string{"file.nested-1", "d"}}},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rtConf := config.NewRuntimeConfig(config.Configuration{
+				HTTP: &config.HTTPConfiguration{
+					Middlewares: middlewares,
+				},
+			})
+			builder := NewBuilder(rtConf.Middlewares, nil)
+
+			var actualPath string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				actualPath = req.URL.Path
+			})
+
+			ctx := internal.AddProviderInContext(context.Background(), "file.router")
+
+			handler, err := builder.BuildChain(ctx, test.buildChain).Then(next)
+			require.NoError(t, err)
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo/foo", nil))
+
+			assert.Equal(t, test.expectedPath, actualPath)
+		})
+	}
+}
@@ -11,11 +11,11 @@ import (
 )
 
 // NewRouteAppenderFactory Creates a new RouteAppenderFactory
-func NewRouteAppenderFactory(staticConfiguration static.Configuration, entryPointName string, acmeProvider *acme.Provider) *RouteAppenderFactory {
+func NewRouteAppenderFactory(staticConfiguration static.Configuration, entryPointName string, acmeProviders []*acme.Provider) *RouteAppenderFactory {
 	return &RouteAppenderFactory{
 		staticConfiguration: staticConfiguration,
 		entryPointName:      entryPointName,
-		acmeProvider:        acmeProvider,
+		acmeProviders:       acmeProviders,
 	}
 }
 
@@ -23,15 +23,17 @@ func NewRouteAppenderFactory(staticConfiguration static.Configuration, entryPoin
 type RouteAppenderFactory struct {
 	staticConfiguration static.Configuration
 	entryPointName      string
-	acmeProvider        *acme.Provider
+	acmeProviders       []*acme.Provider
 }
 
 // NewAppender Creates a new RouteAppender
 func (r *RouteAppenderFactory) NewAppender(ctx context.Context, middlewaresBuilder *middleware.Builder, runtimeConfiguration *config.RuntimeConfiguration) types.RouteAppender {
 	aggregator := NewRouteAppenderAggregator(ctx, middlewaresBuilder, r.staticConfiguration, r.entryPointName, runtimeConfiguration)
 
-	if r.acmeProvider != nil && r.acmeProvider.HTTPChallenge != nil && r.acmeProvider.HTTPChallenge.EntryPoint == r.entryPointName {
-		aggregator.AddAppender(r.acmeProvider)
+	for _, acmeProvider := range r.acmeProviders {
+		if acmeProvider.HTTPChallenge != nil && acmeProvider.HTTPChallenge.EntryPoint == r.entryPointName {
+			aggregator.AddAppender(acmeProvider)
+		}
 	}
 
 	return aggregator
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sort"
 
 	"github.com/containous/alice"
 	"github.com/containous/traefik/pkg/config"
@@ -27,7 +28,7 @@ func NewManager(routers map[string]*config.RouterInfo,
 	serviceManager *service.Manager, middlewaresBuilder *middleware.Builder, modifierBuilder *responsemodifiers.Builder,
 ) *Manager {
 	return &Manager{
-		routerHandlers:     make(map[string]http.Handler),
+		routerHandlers:     make(map[routerHandlerKey]http.Handler),
 		configs:            routers,
 		serviceManager:     serviceManager,
 		middlewaresBuilder: middlewaresBuilder,
@@ -37,11 +38,26 @@ func NewManager(routers map[string]*config.RouterInfo,
 
 // Manager A route/router manager
 type Manager struct {
-	routerHandlers     map[string]http.Handler
-	configs            map[string]*config.RouterInfo
-	serviceManager     *service.Manager
-	middlewaresBuilder *middleware.Builder
-	modifierBuilder    *responsemodifiers.Builder
+	routerHandlers         map[routerHandlerKey]http.Handler
+	configs                map[string]*config.RouterInfo
+	serviceManager         *service.Manager
+	middlewaresBuilder     *middleware.Builder
+	modifierBuilder        *responsemodifiers.Builder
+	entryPointsMiddlewares map[string][]string
+}
+
+// routerHandlerKey identifies the handler of a router.
+// The entry point is only set when the router gets its default middlewares,
+// so that the routers without them share their handler across their entry points.
+type routerHandlerKey struct {
+	entryPointName string
+	routerName     string
+}
+
+// SetEntryPointsMiddlewares sets the default middlewares of the entry points, by entry point name.
+// They are prepended to the middlewares of the routers of the entry point, unless a router skips them.
+func (m *Manager) SetEntryPointsMiddlewares(entryPointsMiddlewares map[string][]string) {
+	m.entryPointsMiddlewares = entryPointsMiddlewares
 }
 
 // BuildHandlers Builds handler for all entry points
@@ -53,7 +69,7 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string, t
 		entryPointName := entryPointName
 		ctx := log.With(rootCtx, log.Str(log.EntryPointName, entryPointName))
 
-		handler, err := m.buildEntryPointHandler(ctx, routers)
+		handler, err := m.buildEntryPointHandler(ctx, entryPointName, routers)
 		if err != nil {
 			log.FromContext(ctx).Error(err)
 			continue
@@ -114,23 +130,45 @@ func (m *Manager) filteredRouters(ctx context.Context, entryPoints []string, tls
 	return entryPointsRouters
 }
 
-func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string]*config.RouterInfo) (http.Handler, error) {
+func (m *Manager) buildEntryPointHandler(ctx context.Context, entryPointName string, configs map[string]*config.RouterInfo) (http.Handler, error) {
 	router, err := rules.NewRouter()
 	if err != nil {
 		return nil, err
 	}
 
-	for routerName, routerConfig := range configs {
+	// The routers are added by name, so that the routers of a same priority are always evaluated in a same order.
+	routerNames := make([]string, 0, len(configs))
+	for routerName := range configs {
+		routerNames = append(routerNames, routerName)
+	}
+	sort.Strings(routerNames)
+
+	var fallbackName string
+	for _, routerName := range routerNames {
+		routerConfig := configs[routerName]
 		ctxRouter := log.With(internal.AddProviderInContext(ctx, routerName), log.Str(log.RouterName, routerName))
 		logger := log.FromContext(ctxRouter)
 
-		handler, err := m.buildRouterHandler(ctxRouter, routerName)
+		if routerConfig.Fallback && fallbackName != "" {
+			routerConfig.Err = fmt.Sprintf("the fallback router %s is already set on the entry point %s", fallbackName, entryPointName)
+			logger.Error(routerConfig.Err)
+			continue
+		}
+
+		handler, err := m.buildRouterHandler(ctxRouter, entryPointName, routerName)
 		if err != nil {
 			routerConfig.Err = err.Error()
 			logger.Error(err)
 			continue
 		}
 
+		// The fallback router only serves the requests which match no route, whatever the priority of the routes.
+		if routerConfig.Fallback {
+			fallbackName = routerName
+			router.NotFoundHandler = handler
+			continue
+		}
+
 		err = router.AddRoute(routerConfig.Rule, routerConfig.Priority, handler)
 		if err != nil {
 			routerConfig.Err = err.Error()
@@ -149,17 +187,24 @@ func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string
 	return chain.Then(router)
 }
 
-func (m *Manager) buildRouterHandler(ctx context.Context, routerName string) (http.Handler, error) {
-	if handler, ok := m.routerHandlers[routerName]; ok {
-		return handler, nil
-	}
-
+func (m *Manager) buildRouterHandler(ctx context.Context, entryPointName string, routerName string) (http.Handler, error) {
 	configRouter, ok := m.configs[routerName]
 	if !ok {
 		return nil, fmt.Errorf("no configuration for %s", routerName)
 	}
 
-	handler, err := m.buildHTTPHandler(ctx, configRouter, routerName)
+	key := routerHandlerKey{routerName: routerName}
+	middlewares := configRouter.Middlewares
+	if defaultMiddlewares := m.entryPointsMiddlewares[entryPointName]; len(defaultMiddlewares) > 0 && !configRouter.SkipEntryPointMiddlewares {
+		key.entryPointName = entryPointName
+		middlewares = append(append([]string{}, defaultMiddlewares...), configRouter.Middlewares...)
+	}
+
+	if handler, ok := m.routerHandlers[key]; ok {
+		return handler, nil
+	}
+
+	handler, err := m.buildHTTPHandler(ctx, configRouter, routerName, middlewares)
 	if err != nil {
 		return nil, err
 	}
@@ -169,17 +214,19 @@ func (m *Manager) buildRouterHandler(ctx context.Context, routerName string) (ht
 	}).Then(handler)
 	if err != nil {
 		log.FromContext(ctx).Error(err)
-		m.routerHandlers[routerName] = handler
+		m.routerHandlers[key] = handler
 	} else {
-		m.routerHandlers[routerName] = handlerWithAccessLog
+		m.routerHandlers[key] = handlerWithAccessLog
 	}
 
-	return m.routerHandlers[routerName], nil
+	return m.routerHandlers[key], nil
 }
 
-func (m *Manager) buildHTTPHandler(ctx context.Context, router *config.RouterInfo, routerName string) (http.Handler, error) {
-	qualifiedNames := make([]string, len(router.Middlewares))
-	for i, name := range router.Middlewares {
+// buildHTTPHandler builds the handler of the router, with the given middlewares:
+// the default middlewares of the entry point, if any, followed by the middlewares of the router.
+func (m *Manager) buildHTTPHandler(ctx context.Context, router *config.RouterInfo, routerName string, middlewares []string) (http.Handler, error) {
+	qualifiedNames := make([]string, len(middlewares))
+	for i, name := range middlewares {
 		qualifiedNames[i] = internal.GetQualifiedName(ctx, name)
 	}
 	rm := m.modifierBuilder.Build(ctx, qualifiedNames)
@@ -189,7 +236,7 @@ func (m *Manager) buildHTTPHandler(ctx context.Context, router *config.RouterInf
 		return nil, err
 	}
 
-	mHandler := m.middlewaresBuilder.BuildChain(ctx, router.Middlewares)
+	mHandler := m.middlewaresBuilder.BuildChain(ctx, middlewares)
 
 	tHandler := func(next http.Handler) (http.Handler, error) {
 		return tracing.NewForwarder(ctx, routerName, router.Service, next), nil
@@ -2,6 +2,7 @@ package router
 
 import (
 	"context"
+	"crypto/tls"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -305,7 +306,7 @@ func TestRouterManager_Get(t *testing.T) {
 					Middlewares: test.middlewaresConfig,
 				},
 			})
-			serviceManager := service.NewManager(rtConf.Services, http.DefaultTransport)
+			serviceManager := service.NewManager(rtConf.Services, service.NewRoundTripperManager(http.DefaultTransport))
 			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
 			responseModifierFactory := responsemodifiers.NewBuilder(rtConf.Middlewares)
 			routerManager := NewManager(rtConf.Routers, serviceManager, middlewaresBuilder, responseModifierFactory)
@@ -327,6 +328,299 @@ func TestRouterManager_Get(t *testing.T) {
 	}
 }
 
+func TestRouterManager_EntryPointsMiddlewares(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Path", req.URL.Path)
+	}))
+	defer server.Close()
+
+	rtConf := config.NewRuntimeConfig(config.Configuration{
+		HTTP: &config.HTTPConfiguration{
+			Services: map[string]*config.Service{
+				"file.foo-service": {
+					LoadBalancer: &config.LoadBalancerService{
+						Servers: []config.Server{{URL: server.URL}},
+					},
+				},
+			},
+			Routers: map[string]*config.Router{
+				"file.foo": {
+					EntryPoints: []string{"web", "websecure"},
+					Middlewares: []string{"router-prefix"},
+					Service:     "foo-service",
+					Rule:        "Host(`foo.bar`)",
+				},
+				"file.bar": {
+					EntryPoints:               []string{"websecure"},
+					Middlewares:               []string{"router-prefix"},
+					Service:                   "foo-service",
+					Rule:                      "Host(`bar.foo`)",
+					SkipEntryPointMiddlewares: true,
+				},
+			},
+			Middlewares: map[string]*config.Middleware{
+				"file.entrypoint-prefix": {
+					AddPrefix: &config.AddPrefix{Prefix: "/entrypoint"},
+				},
+				"file.router-prefix": {
+					AddPrefix: &config.AddPrefix{Prefix: "/router"},
+				},
+				"file.entrypoint-headers": {
+					Headers: &config.Headers{
+						CustomResponseHeaders: map[string]string{"X-Entrypoint": "websecure"},
+					},
+				},
+			},
+		},
+	})
+
+	serviceManager := service.NewManager(rtConf.Services, service.NewRoundTripperManager(http.DefaultTransport))
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
+	responseModifierFactory := responsemodifiers.NewBuilder(rtConf.Middlewares)
+	routerManager := NewManager(rtConf.Routers, serviceManager, middlewaresBuilder, responseModifierFactory)
+	routerManager.SetEntryPointsMiddlewares(map[string][]string{
+		"websecure": {"file.entrypoint-prefix", "file.entrypoint-headers"},
+	})
+
+	handlers := routerManager.BuildHandlers(context.Background(), []string{"web", "websecure"}, false)
+
+	testCases := []struct {
+		desc               string
+		entryPoint         string
+		url                string
+		expectedPath       string
+		expectedEntrypoint string
+	}{
+		{
+			desc:         "entry point without default middlewares",
+			entryPoint:   "web",
+			url:          "http://foo.bar/",
+			expectedPath: "/router/",
+		},
+		{
+			desc:               "default middlewares before the router middlewares",
+			entryPoint:         "websecure",
+			url:                "http://foo.bar/",
+			expectedPath:       "/router/entrypoint/",
+			expectedEntrypoint: "websecure",
+		},
+		{
+			desc:         "router skipping the default middlewares",
+			entryPoint:   "websecure",
+			url:          "http://bar.foo/",
+			expectedPath: "/router/",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := testhelpers.MustNewRequest(http.MethodGet, test.url, nil)
+
+			reqHost := requestdecorator.New(nil)
+			reqHost.ServeHTTP(w, req, handlers[test.entryPoint].ServeHTTP)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, test.expectedPath, w.Header().Get("X-Path"))
+			assert.Equal(t, test.expectedEntrypoint, w.Header().Get("X-Entrypoint"))
+		})
+	}
+}
+
+func TestRouterManager_Fallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Path", req.URL.Path)
+	}))
+	defer server.Close()
+
+	rtConf := config.NewRuntimeConfig(config.Configuration{
+		HTTP: &config.HTTPConfiguration{
+			Services: map[string]*config.Service{
+				"file.foo-service": {
+					LoadBalancer: &config.LoadBalancerService{
+						Servers: []config.Server{{URL: server.URL}},
+					},
+				},
+			},
+			Routers: map[string]*config.Router{
+				"file.foo": {
+					EntryPoints: []string{"web", "websecure"},
+					Service:     "foo-service",
+					Rule:        "Host(`foo.bar`)",
+					Priority:    1,
+				},
+				"file.fallback": {
+					EntryPoints: []string{"web"},
+					Middlewares: []string{"fallback-prefix"},
+					Service:     "foo-service",
+					Fallback:    true,
+				},
+				"file.other-fallback": {
+					EntryPoints: []string{"web"},
+					Service:     "foo-service",
+					Fallback:    true,
+				},
+			},
+			Middlewares: map[string]*config.Middleware{
+				"file.fallback-prefix": {
+					AddPrefix: &config.AddPrefix{Prefix: "/fallback"},
+				},
+			},
+		},
+	})
+
+	serviceManager := service.NewManager(rtConf.Services, service.NewRoundTripperManager(http.DefaultTransport))
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
+	responseModifierFactory := responsemodifiers.NewBuilder(rtConf.Middlewares)
+	routerManager := NewManager(rtConf.Routers, serviceManager, middlewaresBuilder, responseModifierFactory)
+
+	handlers := routerManager.BuildHandlers(context.Background(), []string{"web", "websecure"}, false)
+
+	assert.Equal(t, "the fallback router file.fallback is already set on the entry point web", rtConf.Routers["file.other-fallback"].Err)
+
+	testCases := []struct {
+		desc           string
+		entryPoint     string
+		url            string
+		expectedStatus int
+		expectedPath   string
+	}{
+		{
+			desc:           "router with the lowest priority, not shadowed by the fallback router",
+			entryPoint:     "web",
+			url:            "http://foo.bar/",
+			expectedStatus: http.StatusOK,
+			expectedPath:   "/",
+		},
+		{
+			desc:           "request matching no router, served by the fallback router",
+			entryPoint:     "web",
+			url:            "http://bar.foo/",
+			expectedStatus: http.StatusOK,
+			expectedPath:   "/fallback/",
+		},
+		{
+			desc:           "request matching no router, on an entry point without fallback router",
+			entryPoint:     "websecure",
+			url:            "http://bar.foo/",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := testhelpers.MustNewRequest(http.MethodGet, test.url, nil)
+
+			reqHost := requestdecorator.New(nil)
+			reqHost.ServeHTTP(w, req, handlers[test.entryPoint].ServeHTTP)
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+			assert.Equal(t, test.expectedPath, w.Header().Get("X-Path"))
+		})
+	}
+}
+
+func TestRouterManager_Headers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Powered-By", "backend")
+		rw.Header().Set("X-Request-Token", req.Header.Get("X-Token"))
+		rw.Header().Set("X-Request-Script-Name", req.Header.Get("X-Script-Name"))
+	}))
+	defer server.Close()
+
+	rtConf := config.NewRuntimeConfig(config.Configuration{
+		HTTP: &config.HTTPConfiguration{
+			Services: map[string]*config.Service{
+				"file.foo-service": {
+					LoadBalancer: &config.LoadBalancerService{
+						Servers: []config.Server{{URL: server.URL}},
+					},
+				},
+			},
+			Routers: map[string]*config.Router{
+				"file.foo": {
+					EntryPoints: []string{"web"},
+					Middlewares: []string{"headers"},
+					Service:     "foo-service",
+					Rule:        "Host(`foo.bar`)",
+				},
+			},
+			Middlewares: map[string]*config.Middleware{
+				"file.headers": {
+					Headers: &config.Headers{
+						CustomRequestHeaders:  map[string]string{"X-Script-Name": "test", "X-Token": ""},
+						CustomResponseHeaders: map[string]string{"X-Powered-By": "", "X-Custom": "foo"},
+						STSSeconds:            31536000,
+						STSIncludeSubdomains:  true,
+						STSPreload:            true,
+						FrameDeny:             true,
+						ContentTypeNosniff:    true,
+						BrowserXSSFilter:      true,
+						ContentSecurityPolicy: "default-src 'self'",
+					},
+				},
+			},
+		},
+	})
+
+	serviceManager := service.NewManager(rtConf.Services, service.NewRoundTripperManager(http.DefaultTransport))
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
+	responseModifierFactory := responsemodifiers.NewBuilder(rtConf.Middlewares)
+	routerManager := NewManager(rtConf.Routers, serviceManager, middlewaresBuilder, responseModifierFactory)
+
+	handlers := routerManager.BuildHandlers(context.Background(), []string{"web"}, false)
+
+	testCases := []struct {
+		desc        string
+		secured     bool
+		expectedSTS string
+	}{
+		{
+			desc: "HTTP response without HSTS",
+		},
+		{
+			desc:        "HTTPS response with HSTS",
+			secured:     true,
+			expectedSTS: "max-age=31536000; includeSubdomains; preload",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://foo.bar/", nil)
+			req.Header.Set("X-Token", "secret")
+			if test.secured {
+				req.TLS = &tls.ConnectionState{}
+			}
+
+			reqHost := requestdecorator.New(nil)
+			reqHost.ServeHTTP(w, req, handlers["web"].ServeHTTP)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			// The header set by the backend is deleted, and the custom one is added.
+			_, ok := w.Header()["X-Powered-By"]
+			assert.False(t, ok)
+			assert.Equal(t, "foo", w.Header().Get("X-Custom"))
+
+			// The request header is deleted before the request reaches the backend.
+			assert.Equal(t, "", w.Header().Get("X-Request-Token"))
+			assert.Equal(t, "test", w.Header().Get("X-Request-Script-Name"))
+
+			assert.Equal(t, test.expectedSTS, w.Header().Get("Strict-Transport-Security"))
+			assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+			assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+			assert.Equal(t, "1; mode=block", w.Header().Get("X-XSS-Protection"))
+			assert.Equal(t, "default-src 'self'", w.Header().Get("Content-Security-Policy"))
+		})
+	}
+}
+
 func TestAccessLog(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 
@@ -406,7 +700,7 @@ func TestAccessLog(t *testing.T) {
 					Middlewares: test.middlewaresConfig,
 				},
 			})
-			serviceManager := service.NewManager(rtConf.Services, http.DefaultTransport)
+			serviceManager := service.NewManager(rtConf.Services, service.NewRoundTripperManager(http.DefaultTransport))
 			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
 			responseModifierFactory := responsemodifiers.NewBuilder(rtConf.Middlewares)
 			routerManager := NewManager(rtConf.Routers, serviceManager, middlewaresBuilder, responseModifierFactory)
@@ -645,6 +939,29 @@ func TestRuntimeConfiguration(t *testing.T) {
 			expectedError: 1,
 		},
 
+		{
+			desc: "Router with invalid HostRegexp",
+			serviceConfig: map[string]*config.Service{
+				"foo-service": {
+					LoadBalancer: &config.LoadBalancerService{
+						Servers: []config.Server{
+							{
+								URL: "http://127.0.0.1",
+							},
+						},
+					},
+				},
+			},
+			routerConfig: map[string]*config.Router{
+				"bar": {
+					EntryPoints: []string{"web"},
+					Service:     "foo-service",
+					Rule:        "HostRegexp(`{tenant:[a-z}.foo.bar`)",
+				},
+			},
+			expectedError: 1,
+		},
+
 		{
 			desc: "Router with broken middleware",
 			serviceConfig: map[string]*config.Service{
@@ -692,7 +1009,7 @@ func TestRuntimeConfiguration(t *testing.T) {
 					Middlewares: test.middlewareConfig,
 				},
 			})
-			serviceManager := service.NewManager(rtConf.Services, http.DefaultTransport)
+			serviceManager := service.NewManager(rtConf.Services, service.NewRoundTripperManager(http.DefaultTransport))
 			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
 			responseModifierFactory := responsemodifiers.NewBuilder(map[string]*config.MiddlewareInfo{})
 			routerManager := NewManager(rtConf.Routers, serviceManager, middlewaresBuilder, responseModifierFactory)
@@ -766,7 +1083,7 @@ func BenchmarkRouterServe(b *testing.B) {
 			Middlewares: map[string]*config.Middleware{},
 		},
 	})
-	serviceManager := service.NewManager(rtConf.Services, &staticTransport{res})
+	serviceManager := service.NewManager(rtConf.Services, service.NewRoundTripperManager(&staticTransport{res}))
 	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
 	responseModifierFactory := responsemodifiers.NewBuilder(rtConf.Middlewares)
 	routerManager := NewManager(rtConf.Routers, serviceManager, middlewaresBuilder, responseModifierFactory)
@@ -807,7 +1124,7 @@ func BenchmarkService(b *testing.B) {
 			Services: serviceConfig,
 		},
 	})
-	serviceManager := service.NewManager(rtConf.Services, &staticTransport{res})
+	serviceManager := service.NewManager(rtConf.Services, service.NewRoundTripperManager(&staticTransport{res}))
 	w := httptest.NewRecorder()
 	req := testhelpers.MustNewRequest(http.MethodGet, "http://foo.bar/", nil)
 
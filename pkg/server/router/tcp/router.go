@@ -2,9 +2,10 @@ package tcp
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/log"
@@ -12,6 +13,7 @@ import (
 	"github.com/containous/traefik/pkg/server/internal"
 	tcpservice "github.com/containous/traefik/pkg/server/service/tcp"
 	"github.com/containous/traefik/pkg/tcp"
+	traefiktls "github.com/containous/traefik/pkg/tls"
 )
 
 // NewManager Creates a new Manager
@@ -19,29 +21,32 @@ func NewManager(conf *config.RuntimeConfiguration,
 	serviceManager *tcpservice.Manager,
 	httpHandlers map[string]http.Handler,
 	httpsHandlers map[string]http.Handler,
-	tlsConfig *tls.Config,
+	tlsManager *traefiktls.Manager,
 ) *Manager {
 	return &Manager{
 		configs:        conf.TCPRouters,
+		httpConfigs:    conf.Routers,
 		serviceManager: serviceManager,
 		httpHandlers:   httpHandlers,
 		httpsHandlers:  httpsHandlers,
-		tlsConfig:      tlsConfig,
+		tlsManager:     tlsManager,
 	}
 }
 
 // Manager is a route/router manager
 type Manager struct {
 	configs        map[string]*config.TCPRouterInfo
+	httpConfigs    map[string]*config.RouterInfo
 	serviceManager *tcpservice.Manager
 	httpHandlers   map[string]http.Handler
 	httpsHandlers  map[string]http.Handler
-	tlsConfig      *tls.Config
+	tlsManager     *traefiktls.Manager
 }
 
 // BuildHandlers builds the handlers for the given entrypoints
 func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string) map[string]*tcp.Router {
 	entryPointsRouters := m.filteredRouters(rootCtx, entryPoints)
+	entryPointsRoutersHTTP := m.filteredHTTPRouters(entryPoints)
 
 	entryPointHandlers := make(map[string]*tcp.Router)
 	for _, entryPointName := range entryPoints {
@@ -51,7 +56,7 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string) m
 
 		ctx := log.With(rootCtx, log.Str(log.EntryPointName, entryPointName))
 
-		handler, err := m.buildEntryPointHandler(ctx, routers, m.httpHandlers[entryPointName], m.httpsHandlers[entryPointName])
+		handler, err := m.buildEntryPointHandler(ctx, routers, entryPointsRoutersHTTP[entryPointName], m.httpHandlers[entryPointName], m.httpsHandlers[entryPointName])
 		if err != nil {
 			log.FromContext(ctx).Error(err)
 			continue
@@ -61,15 +66,39 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string) m
 	return entryPointHandlers
 }
 
-func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string]*config.TCPRouterInfo, handlerHTTP http.Handler, handlerHTTPS http.Handler) (*tcp.Router, error) {
+func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string]*config.TCPRouterInfo, configsHTTP map[string]*config.RouterInfo, handlerHTTP http.Handler, handlerHTTPS http.Handler) (*tcp.Router, error) {
 	router := &tcp.Router{}
 	router.HTTPHandler(handlerHTTP)
-	router.HTTPSHandler(handlerHTTPS, m.tlsConfig)
 
-	for routerName, routerConfig := range configs {
+	defaultTLSConf, err := m.tlsManager.Get("default", traefiktls.DefaultTLSConfigName)
+	if err != nil {
+		return nil, err
+	}
+	router.HTTPSHandler(handlerHTTPS, defaultTLSConf)
+
+	m.addHTTPTLSConfigs(ctx, router, configsHTTP)
+
+	// The routers are added by name, so that the first fallback router by name is always the one which is kept.
+	routerNames := make([]string, 0, len(configs))
+	for routerName := range configs {
+		routerNames = append(routerNames, routerName)
+	}
+	sort.Strings(routerNames)
+
+	var fallbackNoTLS, fallbackTLS string
+	for _, routerName := range routerNames {
+		routerConfig := configs[routerName]
 		ctxRouter := log.With(internal.AddProviderInContext(ctx, routerName), log.Str(log.RouterName, routerName))
 		logger := log.FromContext(ctxRouter)
 
+		if routerConfig.Fallback {
+			if err := checkFallback(routerConfig, configsHTTP, fallbackNoTLS, fallbackTLS); err != nil {
+				routerConfig.Err = err.Error()
+				logger.Error(err)
+				continue
+			}
+		}
+
 		handler, err := m.serviceManager.BuildTCP(ctxRouter, routerConfig.Service)
 		if err != nil {
 			routerConfig.Err = err.Error()
@@ -77,6 +106,22 @@ func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string
 			continue
 		}
 
+		// The fallback router only serves the connections which match no route.
+		if routerConfig.Fallback {
+			switch {
+			case routerConfig.TLS == nil:
+				fallbackNoTLS = routerName
+				router.AddCatchAllNoTLS(handler)
+			case routerConfig.TLS.Passthrough:
+				fallbackTLS = routerName
+				router.AddCatchAllTLS(handler)
+			default:
+				fallbackTLS = routerName
+				router.AddCatchAllTLS(&tcp.TLSHandler{Next: handler, Config: defaultTLSConf})
+			}
+			continue
+		}
+
 		domains, err := rules.ParseHostSNI(routerConfig.Rule)
 		if err != nil {
 			routerErr := fmt.Errorf("unknown rule %s", routerConfig.Rule)
@@ -92,7 +137,7 @@ func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string
 				if routerConfig.TLS.Passthrough {
 					router.AddRoute(domain, handler)
 				} else {
-					router.AddRouteTLS(domain, handler, m.tlsConfig)
+					router.AddRouteTLS(domain, handler, defaultTLSConf)
 				}
 			case domain == "*":
 				router.AddCatchAllNoTLS(handler)
@@ -105,6 +150,116 @@ func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string
 	return router, nil
 }
 
+// checkFallback checks that the fallback router can serve the connections which match no route of its entry point,
+// i.e. that it is the only fallback router of the entry point for its kind of connections,
+// and that these connections are not served by an HTTP router of the entry point.
+func checkFallback(routerConfig *config.TCPRouterInfo, configsHTTP map[string]*config.RouterInfo, fallbackNoTLS, fallbackTLS string) error {
+	if routerConfig.TLS == nil && fallbackNoTLS != "" {
+		return fmt.Errorf("the fallback router %s is already set for the connections without TLS", fallbackNoTLS)
+	}
+	if routerConfig.TLS != nil && fallbackTLS != "" {
+		return fmt.Errorf("the fallback router %s is already set for the TLS connections", fallbackTLS)
+	}
+
+	httpRouterNames := make([]string, 0, len(configsHTTP))
+	for routerName := range configsHTTP {
+		httpRouterNames = append(httpRouterNames, routerName)
+	}
+	sort.Strings(httpRouterNames)
+
+	for _, routerName := range httpRouterNames {
+		httpConfig := configsHTTP[routerName]
+
+		// The connections without TLS can not be routed by host, so any HTTP router without TLS would be shadowed.
+		if routerConfig.TLS == nil && httpConfig.TLS == nil {
+			return fmt.Errorf("the fallback router would shadow the HTTP router %s, which serves the connections without TLS", routerName)
+		}
+
+		if routerConfig.TLS == nil || httpConfig.TLS == nil {
+			continue
+		}
+
+		if httpConfig.Fallback {
+			return fmt.Errorf("the fallback router would shadow the HTTP fallback router %s", routerName)
+		}
+
+		// The TLS connections are routed by their server name, so only the HTTP routers without domain would be shadowed.
+		domains, err := rules.ParseDomains(httpConfig.Rule)
+		if err == nil && len(domains) == 0 {
+			return fmt.Errorf("the fallback router would shadow the HTTP router %s, which has no domain", routerName)
+		}
+	}
+
+	return nil
+}
+
+// addHTTPTLSConfigs sets the TLS options of the HTTPS connections for the hosts of the HTTP routers with TLS,
+// which are resolved by their SNI when the connections are accepted.
+// When the routers of a same host reference different TLS options, the default TLS options are used for the host.
+// The hosts with the default TLS options are set too, so that they do not get the TLS options of a wildcard host matching them.
+func (m *Manager) addHTTPTLSConfigs(ctx context.Context, router *tcp.Router, configs map[string]*config.RouterInfo) {
+	// the names of the routers, by TLS options, by host.
+	hostsOptions := make(map[string]map[string][]string)
+
+	for routerName, routerConfig := range configs {
+		if routerConfig.TLS == nil {
+			continue
+		}
+
+		ctxRouter := log.With(internal.AddProviderInContext(ctx, routerName), log.Str(log.RouterName, routerName))
+
+		tlsOptionsName := traefiktls.DefaultTLSConfigName
+		if len(routerConfig.TLS.Options) > 0 && routerConfig.TLS.Options != traefiktls.DefaultTLSConfigName {
+			tlsOptionsName = internal.GetQualifiedName(ctxRouter, routerConfig.TLS.Options)
+		}
+
+		// the errors of the rules are reported by the HTTP routers.
+		domains, err := rules.ParseDomains(routerConfig.Rule)
+		if err != nil {
+			continue
+		}
+
+		if len(domains) == 0 && tlsOptionsName != traefiktls.DefaultTLSConfigName {
+			log.FromContext(ctxRouter).Warnf("No domain found in rule %s, the TLS options %s are not applied", routerConfig.Rule, tlsOptionsName)
+		}
+
+		for _, domain := range domains {
+			if _, ok := hostsOptions[domain]; !ok {
+				hostsOptions[domain] = make(map[string][]string)
+			}
+			hostsOptions[domain][tlsOptionsName] = append(hostsOptions[domain][tlsOptionsName], routerName)
+		}
+	}
+
+	for host, options := range hostsOptions {
+		if len(options) > 1 {
+			var routerNames []string
+			for _, names := range options {
+				routerNames = append(routerNames, names...)
+			}
+			sort.Strings(routerNames)
+
+			log.FromContext(ctx).Warnf("Found different TLS options for the routers %s on the same host %s, so using the default TLS options instead",
+				strings.Join(routerNames, ", "), host)
+
+			options = map[string][]string{traefiktls.DefaultTLSConfigName: routerNames}
+		}
+
+		for tlsOptionsName, routerNames := range options {
+			tlsConf, err := m.tlsManager.Get("default", tlsOptionsName)
+			if err != nil {
+				for _, routerName := range routerNames {
+					configs[routerName].Err = err.Error()
+					log.FromContext(log.With(ctx, log.Str(log.RouterName, routerName))).Error(err)
+				}
+				continue
+			}
+
+			router.AddRouteHTTPTLS(host, tlsConf)
+		}
+	}
+}
+
 func contains(entryPoints []string, entryPointName string) bool {
 	for _, name := range entryPoints {
 		if name == entryPointName {
@@ -140,3 +295,29 @@ func (m *Manager) filteredRouters(ctx context.Context, entryPoints []string) map
 
 	return entryPointsRouters
 }
+
+func (m *Manager) filteredHTTPRouters(entryPoints []string) map[string]map[string]*config.RouterInfo {
+	entryPointsRouters := make(map[string]map[string]*config.RouterInfo)
+
+	for rtName, rt := range m.httpConfigs {
+		eps := rt.EntryPoints
+		if len(eps) == 0 {
+			eps = entryPoints
+		}
+
+		for _, entryPointName := range eps {
+			if !contains(entryPoints, entryPointName) {
+				// the unknown entry points are reported by the HTTP routers.
+				continue
+			}
+
+			if _, ok := entryPointsRouters[entryPointName]; !ok {
+				entryPointsRouters[entryPointName] = make(map[string]*config.RouterInfo)
+			}
+
+			entryPointsRouters[entryPointName][rtName] = rt
+		}
+	}
+
+	return entryPointsRouters
+}
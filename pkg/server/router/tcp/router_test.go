@@ -2,11 +2,20 @@ package tcp
 
 import (
 	"context"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/server/service/tcp"
+	tcpcore "github.com/containous/traefik/pkg/tcp"
+	traefiktls "github.com/containous/traefik/pkg/tls"
+	"github.com/containous/traefik/pkg/tls/generate"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRuntimeConfiguration(t *testing.T) {
@@ -192,7 +201,7 @@ func TestRuntimeConfiguration(t *testing.T) {
 			}
 			serviceManager := tcp.NewManager(conf)
 			routerManager := NewManager(conf, serviceManager,
-				nil, nil, nil)
+				nil, nil, traefiktls.NewManager())
 
 			_ = routerManager.BuildHandlers(context.Background(), entryPoints)
 
@@ -215,3 +224,369 @@ func TestRuntimeConfiguration(t *testing.T) {
 	}
 
 }
+
+func TestHTTPTLSOptions(t *testing.T) {
+	caCert, _, err := generate.KeyPair("ca.localhost", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc          string
+		routerConfig  map[string]*config.RouterInfo
+		serverName    string
+		expectedError bool
+		routerError   string
+	}{
+		{
+			desc: "default TLS options",
+			routerConfig: map[string]*config.RouterInfo{
+				"file.foo": {
+					Router: &config.Router{Rule: "Host(`foo.localhost`)", TLS: &config.RouterTLSConfig{}},
+				},
+			},
+			serverName: "foo.localhost",
+		},
+		{
+			desc: "TLS options of the host",
+			routerConfig: map[string]*config.RouterInfo{
+				"file.foo": {
+					Router: &config.Router{Rule: "Host(`foo.localhost`)", TLS: &config.RouterTLSConfig{Options: "mtls"}},
+				},
+			},
+			serverName:    "foo.localhost",
+			expectedError: true,
+		},
+		{
+			desc: "TLS options of another host",
+			routerConfig: map[string]*config.RouterInfo{
+				"file.foo": {
+					Router: &config.Router{Rule: "Host(`foo.localhost`)", TLS: &config.RouterTLSConfig{Options: "mtls"}},
+				},
+			},
+			serverName: "bar.localhost",
+		},
+		{
+			desc: "TLS options of another provider",
+			routerConfig: map[string]*config.RouterInfo{
+				"docker.foo": {
+					Router: &config.Router{Rule: "Host(`foo.localhost`)", TLS: &config.RouterTLSConfig{Options: "file.mtls"}},
+				},
+			},
+			serverName:    "foo.localhost",
+			expectedError: true,
+		},
+		{
+			desc: "TLS options of a router without TLS",
+			routerConfig: map[string]*config.RouterInfo{
+				"file.foo": {
+					Router: &config.Router{Rule: "Host(`foo.localhost`)"},
+				},
+			},
+			serverName: "foo.localhost",
+		},
+		{
+			desc: "same TLS options for the same host",
+			routerConfig: map[string]*config.RouterInfo{
+				"file.foo": {
+					Router: &config.Router{Rule: "Host(`foo.localhost`)", TLS: &config.RouterTLSConfig{Options: "mtls"}},
+				},
+				"file.bar": {
+					Router: &config.Router{Rule: "Host(`foo.localhost`) && Path(`/bar`)", TLS: &config.RouterTLSConfig{Options: "mtls"}},
+				},
+			},
+			serverName:    "foo.localhost",
+			expectedError: true,
+		},
+		{
+			desc: "conflicting TLS options for the same host",
+			routerConfig: map[string]*config.RouterInfo{
+				"file.foo": {
+					Router: &config.Router{Rule: "Host(`foo.localhost`)", TLS: &config.RouterTLSConfig{Options: "mtls"}},
+				},
+				"file.bar": {
+					Router: &config.Router{Rule: "Host(`foo.localhost`) && Path(`/bar`)", TLS: &config.RouterTLSConfig{}},
+				},
+			},
+			serverName: "foo.localhost",
+		},
+		{
+			desc: "TLS options of a wildcard host",
+			routerConfig: map[string]*config.RouterInfo{
+				"docker.wildcard": {
+					Router: &config.Router{Rule: "Host(`*.localhost`)", TLS: &config.RouterTLSConfig{Options: "file.mtls"}},
+				},
+			},
+			serverName:    "foo.localhost",
+			expectedError: true,
+		},
+		{
+			desc: "exact host of another provider over wildcard host",
+			routerConfig: map[string]*config.RouterInfo{
+				"docker.wildcard": {
+					Router: &config.Router{Rule: "Host(`*.localhost`)", TLS: &config.RouterTLSConfig{Options: "file.mtls"}},
+				},
+				"file.foo": {
+					Router: &config.Router{Rule: "Host(`Foo.localhost.`)", TLS: &config.RouterTLSConfig{}},
+				},
+			},
+			serverName: "foo.localhost",
+		},
+		{
+			desc: "longest wildcard host over the shortest",
+			routerConfig: map[string]*config.RouterInfo{
+				"docker.wildcard": {
+					Router: &config.Router{Rule: "Host(`*.*.localhost`)", TLS: &config.RouterTLSConfig{}},
+				},
+				"file.wildcard": {
+					Router: &config.Router{Rule: "Host(`*.bar.localhost`)", TLS: &config.RouterTLSConfig{Options: "mtls"}},
+				},
+			},
+			serverName:    "foo.bar.localhost",
+			expectedError: true,
+		},
+		{
+			desc: "unknown TLS options",
+			routerConfig: map[string]*config.RouterInfo{
+				"file.foo": {
+					Router: &config.Router{Rule: "Host(`foo.localhost`)", TLS: &config.RouterTLSConfig{Options: "unknown"}},
+				},
+			},
+			serverName:  "foo.localhost",
+			routerError: "unknown TLS options: file.unknown",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tlsManager := traefiktls.NewManager()
+			tlsManager.UpdateConfigs(nil, map[string]traefiktls.TLS{
+				"file.mtls": {
+					ClientCA: traefiktls.ClientCA{Files: []traefiktls.FileOrContent{traefiktls.FileOrContent(caCert)}},
+				},
+			}, nil)
+
+			conf := &config.RuntimeConfiguration{Routers: test.routerConfig}
+			routerManager := NewManager(conf, tcp.NewManager(conf), nil, nil, tlsManager)
+
+			router := routerManager.BuildHandlers(context.Background(), []string{"websecure"})["websecure"]
+			require.NotNil(t, router)
+
+			handshakeErr := make(chan error, 1)
+			router.HTTPSForwarder(tcpcore.HandlerFunc(func(conn net.Conn) {
+				handshakeErr <- conn.(*tls.Conn).Handshake()
+				conn.Close()
+			}))
+
+			serverConn, clientConn := net.Pipe()
+			go router.ServeTCP(serverConn)
+
+			client := tls.Client(clientConn, &tls.Config{ServerName: test.serverName, InsecureSkipVerify: true})
+			defer client.Close()
+			go func() {
+				// reads the alert sent by the server, as the pipe is synchronous.
+				_, _ = io.Copy(ioutil.Discard, client)
+			}()
+
+			// the server fails the handshake only when it requires a client certificate.
+			if test.expectedError {
+				assert.Error(t, <-handshakeErr)
+			} else {
+				assert.NoError(t, <-handshakeErr)
+			}
+
+			for _, routerConfig := range test.routerConfig {
+				if test.routerError != "" {
+					assert.Equal(t, test.routerError, routerConfig.Err)
+				}
+			}
+		})
+	}
+}
+
+func TestFallback(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		routerConfig   map[string]*config.TCPRouterInfo
+		httpConfig     map[string]*config.RouterInfo
+		expectedErrors map[string]string
+	}{
+		{
+			desc: "fallback routers with and without TLS",
+			routerConfig: map[string]*config.TCPRouterInfo{
+				"file.foo": {TCPRouter: &config.TCPRouter{Service: "foo-service", Fallback: true}},
+				"file.bar": {TCPRouter: &config.TCPRouter{Service: "foo-service", Fallback: true, TLS: &config.RouterTCPTLSConfig{}}},
+			},
+			httpConfig: map[string]*config.RouterInfo{
+				"file.foo": {Router: &config.Router{Rule: "Host(`foo.localhost`)", TLS: &config.RouterTLSConfig{}}},
+			},
+		},
+		{
+			desc: "several fallback routers",
+			routerConfig: map[string]*config.TCPRouterInfo{
+				"file.foo": {TCPRouter: &config.TCPRouter{Service: "foo-service", Fallback: true}},
+				"file.bar": {TCPRouter: &config.TCPRouter{Service: "foo-service", Fallback: true}},
+			},
+			expectedErrors: map[string]string{
+				"file.foo": "the fallback router file.bar is already set for the connections without TLS",
+			},
+		},
+		{
+			desc: "fallback router without TLS, and HTTP router without TLS",
+			routerConfig: map[string]*config.TCPRouterInfo{
+				"file.foo": {TCPRouter: &config.TCPRouter{Service: "foo-service", Fallback: true}},
+			},
+			httpConfig: map[string]*config.RouterInfo{
+				"file.foo": {Router: &config.Router{Rule: "Host(`foo.localhost`)"}},
+			},
+			expectedErrors: map[string]string{
+				"file.foo": "the fallback router would shadow the HTTP router file.foo, which serves the connections without TLS",
+			},
+		},
+		{
+			desc: "fallback router with TLS, and HTTP router with TLS without domain",
+			routerConfig: map[string]*config.TCPRouterInfo{
+				"file.foo": {TCPRouter: &config.TCPRouter{Service: "foo-service", Fallback: true, TLS: &config.RouterTCPTLSConfig{Passthrough: true}}},
+			},
+			httpConfig: map[string]*config.RouterInfo{
+				"file.foo": {Router: &config.Router{Rule: "PathPrefix(`/foo`)", TLS: &config.RouterTLSConfig{}}},
+			},
+			expectedErrors: map[string]string{
+				"file.foo": "the fallback router would shadow the HTTP router file.foo, which has no domain",
+			},
+		},
+		{
+			desc: "fallback router with TLS, and HTTP fallback router with TLS",
+			routerConfig: map[string]*config.TCPRouterInfo{
+				"file.foo": {TCPRouter: &config.TCPRouter{Service: "foo-service", Fallback: true, TLS: &config.RouterTCPTLSConfig{}}},
+			},
+			httpConfig: map[string]*config.RouterInfo{
+				"file.foo": {Router: &config.Router{Fallback: true, TLS: &config.RouterTLSConfig{}}},
+			},
+			expectedErrors: map[string]string{
+				"file.foo": "the fallback router would shadow the HTTP fallback router file.foo",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			conf := &config.RuntimeConfiguration{
+				TCPServices: map[string]*config.TCPServiceInfo{
+					"file.foo-service": {
+						TCPService: &config.TCPService{
+							LoadBalancer: &config.TCPLoadBalancerService{
+								Servers: []config.TCPServer{{Address: "127.0.0.1:8085"}},
+							},
+						},
+					},
+				},
+				TCPRouters: test.routerConfig,
+				Routers:    test.httpConfig,
+			}
+			routerManager := NewManager(conf, tcp.NewManager(conf), nil, nil, traefiktls.NewManager())
+
+			_ = routerManager.BuildHandlers(context.Background(), []string{"web"})
+
+			for routerName, routerConfig := range test.routerConfig {
+				assert.Equal(t, test.expectedErrors[routerName], routerConfig.Err, routerName)
+			}
+		})
+	}
+}
+
+func TestFallback_routing(t *testing.T) {
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer backend.Close()
+
+	accepted := make(chan struct{}, 1)
+	go func() {
+		for {
+			conn, err := backend.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- struct{}{}
+			conn.Close()
+		}
+	}()
+
+	conf := &config.RuntimeConfiguration{
+		TCPServices: map[string]*config.TCPServiceInfo{
+			"file.fallback-service": {
+				TCPService: &config.TCPService{
+					LoadBalancer: &config.TCPLoadBalancerService{
+						Servers: []config.TCPServer{{Address: backend.Addr().String()}},
+					},
+				},
+			},
+		},
+		TCPRouters: map[string]*config.TCPRouterInfo{
+			"file.fallback": {
+				TCPRouter: &config.TCPRouter{
+					Service:  "fallback-service",
+					Fallback: true,
+					TLS:      &config.RouterTCPTLSConfig{Passthrough: true},
+				},
+			},
+		},
+		Routers: map[string]*config.RouterInfo{
+			"file.foo": {Router: &config.Router{Rule: "Host(`foo.localhost`)", TLS: &config.RouterTLSConfig{}}},
+		},
+	}
+	routerManager := NewManager(conf, tcp.NewManager(conf), nil, nil, traefiktls.NewManager())
+
+	router := routerManager.BuildHandlers(context.Background(), []string{"websecure"})["websecure"]
+	require.NotNil(t, router)
+
+	forwarded := make(chan struct{}, 1)
+	router.HTTPSForwarder(tcpcore.HandlerFunc(func(conn net.Conn) {
+		forwarded <- struct{}{}
+		conn.Close()
+	}))
+
+	testCases := []struct {
+		desc       string
+		serverName string
+		expected   chan struct{}
+	}{
+		{
+			desc:       "server name of an HTTP router",
+			serverName: "foo.localhost",
+			expected:   forwarded,
+		},
+		{
+			desc:       "server name matching no router",
+			serverName: "bar.localhost",
+			expected:   accepted,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			serverConn, clientConn := net.Pipe()
+			go router.ServeTCP(serverConn)
+
+			client := tls.Client(clientConn, &tls.Config{ServerName: test.serverName, InsecureSkipVerify: true})
+			defer client.Close()
+			go func() {
+				_ = client.Handshake()
+			}()
+
+			select {
+			case <-test.expected:
+			case <-time.After(5 * time.Second):
+				t.Fatal("the connection was not routed to the expected handler")
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/containous/traefik/pkg/provider"
 	"github.com/containous/traefik/pkg/safe"
 	"github.com/containous/traefik/pkg/server/middleware"
+	"github.com/containous/traefik/pkg/server/service"
 	"github.com/containous/traefik/pkg/tls"
 	"github.com/containous/traefik/pkg/tracing"
 	"github.com/containous/traefik/pkg/tracing/datadog"
@@ -39,13 +40,14 @@ type Server struct {
 	accessLoggerMiddleware     *accesslog.Handler
 	tracer                     *tracing.Tracing
 	routinesPool               *safe.Pool
-	defaultRoundTripper        http.RoundTripper
+	roundTripperManager        *service.RoundTripperManager
 	metricsRegistry            metrics.Registry
 	provider                   provider.Provider
 	configurationListeners     []func(config.Configuration)
 	requestDecorator           *requestdecorator.RequestDecorator
 	providersThrottleDuration  time.Duration
 	tlsManager                 *tls.Manager
+	entryPointsMiddlewares     map[string][]string
 }
 
 // RouteAppenderFactory the route appender factory interface
@@ -85,6 +87,13 @@ func NewServer(staticConfiguration static.Configuration, provider provider.Provi
 	server.providerConfigUpdateMap = make(map[string]chan config.Message)
 	server.tlsManager = tlsManager
 
+	server.entryPointsMiddlewares = make(map[string][]string)
+	for entryPointName, entryPoint := range staticConfiguration.EntryPoints {
+		if entryPoint != nil && len(entryPoint.Middlewares) > 0 {
+			server.entryPointsMiddlewares[entryPointName] = entryPoint.Middlewares
+		}
+	}
+
 	if staticConfiguration.Providers != nil {
 		server.providersThrottleDuration = time.Duration(staticConfiguration.Providers.ProvidersThrottleDuration)
 	}
@@ -92,9 +101,9 @@ func NewServer(staticConfiguration static.Configuration, provider provider.Provi
 	transport, err := createHTTPTransport(staticConfiguration.ServersTransport)
 	if err != nil {
 		log.WithoutContext().Errorf("Could not configure HTTP Transport, fallbacking on default transport: %v", err)
-		server.defaultRoundTripper = http.DefaultTransport
+		server.roundTripperManager = service.NewRoundTripperManager(http.DefaultTransport)
 	} else {
-		server.defaultRoundTripper = transport
+		server.roundTripperManager = service.NewRoundTripperManager(transport)
 	}
 
 	server.routinesPool = safe.NewPool(context.Background())
@@ -252,6 +261,10 @@ func (s *Server) startProvider() {
 	log.WithoutContext().Infof("Starting provider %T %s", s.provider, jsonConf)
 	currentProvider := s.provider
 
+	if metricsAware, ok := currentProvider.(provider.MetricsAware); ok {
+		metricsAware.SetMetricsRegistry(s.metricsRegistry)
+	}
+
 	safe.Go(func() {
 		err := currentProvider.Provide(s.configurationChan, s.routinesPool)
 		if err != nil {
@@ -2,7 +2,6 @@ package server
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"net/http"
 	"reflect"
@@ -67,34 +66,43 @@ func (s *Server) loadConfigurationTCP(configurations config.Configurations) map[
 
 	conf := mergeConfiguration(configurations)
 
+	// The UDP routers are part of the dynamic configuration, but the entry points do not serve UDP yet.
+	for routerName := range conf.UDP.Routers {
+		log.FromContext(ctx).WithField(log.RouterName, routerName).
+			Warn("UDP routing is not supported by the entry points yet, skipping the router")
+	}
+
 	s.tlsManager.UpdateConfigs(conf.TLSStores, conf.TLSOptions, conf.TLS)
+	s.roundTripperManager.Update(conf.HTTP.ServersTransports)
 
 	rtConf := config.NewRuntimeConfig(conf)
 	handlersNonTLS, handlersTLS := s.createHTTPHandlers(ctx, rtConf, entryPoints)
-	routersTCP := s.createTCPRouters(ctx, rtConf, entryPoints, handlersNonTLS, handlersTLS, s.tlsManager.Get("default", "default"))
+	routersTCP := s.createTCPRouters(ctx, rtConf, entryPoints, handlersNonTLS, handlersTLS)
 	rtConf.PopulateUsedBy()
 
 	return routersTCP
 }
 
 // the given configuration must not be nil. its fields will get mutated.
-func (s *Server) createTCPRouters(ctx context.Context, configuration *config.RuntimeConfiguration, entryPoints []string, handlers map[string]http.Handler, handlersTLS map[string]http.Handler, tlsConfig *tls.Config) map[string]*tcpCore.Router {
+func (s *Server) createTCPRouters(ctx context.Context, configuration *config.RuntimeConfiguration, entryPoints []string, handlers map[string]http.Handler, handlersTLS map[string]http.Handler) map[string]*tcpCore.Router {
 	if configuration == nil {
 		return make(map[string]*tcpCore.Router)
 	}
 
 	serviceManager := tcp.NewManager(configuration)
-	routerManager := routertcp.NewManager(configuration, serviceManager, handlers, handlersTLS, tlsConfig)
+	routerManager := routertcp.NewManager(configuration, serviceManager, handlers, handlersTLS, s.tlsManager)
 
 	return routerManager.BuildHandlers(ctx, entryPoints)
 }
 
 // createHTTPHandlers returns, for the given configuration and entryPoints, the HTTP handlers for non-TLS connections, and for the TLS ones. the given configuration must not be nil. its fields will get mutated.
 func (s *Server) createHTTPHandlers(ctx context.Context, configuration *config.RuntimeConfiguration, entryPoints []string) (map[string]http.Handler, map[string]http.Handler) {
-	serviceManager := service.NewManager(configuration.Services, s.defaultRoundTripper)
+	serviceManager := service.NewManager(configuration.Services, s.roundTripperManager)
+	serviceManager.SetMetricsRegistry(s.metricsRegistry)
 	middlewaresBuilder := middleware.NewBuilder(configuration.Middlewares, serviceManager)
 	responseModifierFactory := responsemodifiers.NewBuilder(configuration.Middlewares)
 	routerManager := router.NewManager(configuration.Routers, serviceManager, middlewaresBuilder, responseModifierFactory)
+	routerManager.SetEntryPointsMiddlewares(s.entryPointsMiddlewares)
 
 	handlersNonTLS := routerManager.BuildHandlers(ctx, entryPoints, false)
 	handlersTLS := routerManager.BuildHandlers(ctx, entryPoints, true)
@@ -163,13 +171,18 @@ func isEmptyConfiguration(conf *config.Configuration) bool {
 	if conf.HTTP == nil {
 		conf.HTTP = &config.HTTPConfiguration{}
 	}
+	if conf.UDP == nil {
+		conf.UDP = &config.UDPConfiguration{}
+	}
 
 	return conf.HTTP.Routers == nil &&
 		conf.HTTP.Services == nil &&
 		conf.HTTP.Middlewares == nil &&
 		conf.TLS == nil &&
 		conf.TCP.Routers == nil &&
-		conf.TCP.Services == nil
+		conf.TCP.Services == nil &&
+		conf.UDP.Routers == nil &&
+		conf.UDP.Services == nil
 }
 
 func (s *Server) preLoadConfiguration(configMsg config.Message) {
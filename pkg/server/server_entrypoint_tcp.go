@@ -339,6 +339,7 @@ func createHTTPServer(ln net.Listener, configuration *static.EntryPoint, withH2c
 	handler, err := forwardedheaders.NewXForwarded(
 		configuration.ForwardedHeaders.Insecure,
 		configuration.ForwardedHeaders.TrustedIPs,
+		configuration.ForwardedHeaders.Depth,
 		httpSwitcher)
 	if err != nil {
 		return nil, err
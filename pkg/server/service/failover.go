@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/containous/traefik/pkg/healthcheck"
+	"github.com/containous/traefik/pkg/log"
+	"github.com/go-kit/kit/metrics"
+)
+
+// healthReporter is implemented by the handlers of the services which know whether they have servers up,
+// according to the health checks of their load-balancers.
+type healthReporter interface {
+	healthy() bool
+}
+
+// isHealthy tells whether the handler of a service has servers up.
+// The handlers which do not know it are considered healthy.
+func isHealthy(handler http.Handler) bool {
+	if reporter, ok := handler.(healthReporter); ok {
+		return reporter.healthy()
+	}
+	return true
+}
+
+// loadBalancerHandler is the handler of a load-balancer service, which is healthy as long as its balancer has servers,
+// i.e. as long as its health check did not remove all of them.
+type loadBalancerHandler struct {
+	http.Handler
+	balancer healthcheck.Balancer
+}
+
+func (l *loadBalancerHandler) healthy() bool {
+	return len(l.balancer.Servers()) > 0
+}
+
+// failover sends the requests to the handler of the main service while it is healthy,
+// and to the handler of the fallback service otherwise.
+// The active service is exposed by a gauge, set to 1 for the active service and to 0 for the other one.
+type failover struct {
+	serviceName  string
+	handler      http.Handler
+	handlerName  string
+	fallback     http.Handler
+	fallbackName string
+	gauge        metrics.Gauge
+
+	mu         sync.Mutex
+	onFallback bool
+}
+
+// newFailover creates a failover, starting on the main service, or on the fallback service if the main one is not healthy.
+func newFailover(serviceName, handlerName string, handler http.Handler, fallbackName string, fallback http.Handler, gauge metrics.Gauge) *failover {
+	f := &failover{
+		serviceName:  serviceName,
+		handler:      handler,
+		handlerName:  handlerName,
+		fallback:     fallback,
+		fallbackName: fallbackName,
+		gauge:        gauge,
+		onFallback:   !isHealthy(handler),
+	}
+
+	f.setGauge()
+
+	return f
+}
+
+func (f *failover) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if f.switchTo(!isHealthy(f.handler)) {
+		f.fallback.ServeHTTP(rw, req)
+		return
+	}
+
+	f.handler.ServeHTTP(rw, req)
+}
+
+// healthy tells whether the failover can serve the requests, with either of its services.
+func (f *failover) healthy() bool {
+	return isHealthy(f.handler) || isHealthy(f.fallback)
+}
+
+// switchTo sets the active service, i.e. the fallback service when onFallback is true, and returns onFallback.
+func (f *failover) switchTo(onFallback bool) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.onFallback == onFallback {
+		return onFallback
+	}
+
+	f.onFallback = onFallback
+	f.setGauge()
+
+	logger := log.FromContext(log.With(context.Background(), log.Str(log.ServiceName, f.serviceName)))
+	if onFallback {
+		logger.Warnf("The main service %s has no server up: failing over to the service %s", f.handlerName, f.fallbackName)
+	} else {
+		logger.Infof("The main service %s has servers up again: recovering from the service %s", f.handlerName, f.fallbackName)
+	}
+
+	return onFallback
+}
+
+func (f *failover) setGauge() {
+	active, inactive := f.handlerName, f.fallbackName
+	if f.onFallback {
+		active, inactive = inactive, active
+	}
+
+	f.gauge.With("backend", f.serviceName, "service", active).Set(1)
+	f.gauge.With("backend", f.serviceName, "service", inactive).Set(0)
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/metrics"
+	"github.com/containous/traefik/pkg/server/internal"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failoverGauge records the last value set for each set of label values.
+type failoverGauge struct {
+	values      map[string]float64
+	labelValues []string
+}
+
+func (g *failoverGauge) With(labelValues ...string) gokitmetrics.Gauge {
+	return &failoverGauge{values: g.values, labelValues: labelValues}
+}
+
+func (g *failoverGauge) Set(value float64) {
+	g.values[strings.Join(g.labelValues, ",")] = value
+}
+
+func (g *failoverGauge) Add(delta float64) {
+	g.values[strings.Join(g.labelValues, ",")] += delta
+}
+
+type failoverRegistry struct {
+	metrics.Registry
+	gauge *failoverGauge
+}
+
+func (r failoverRegistry) BackendFailoverActiveGauge() gokitmetrics.Gauge {
+	return r.gauge
+}
+
+// healthHandler is the handler of a service, answering with its status code while it is healthy.
+type healthHandler struct {
+	status int
+	up     bool
+}
+
+func (h *healthHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	rw.WriteHeader(h.status)
+}
+
+func (h *healthHandler) healthy() bool {
+	return h.up
+}
+
+func TestFailover(t *testing.T) {
+	main := &healthHandler{status: http.StatusOK, up: true}
+	fallback := &healthHandler{status: http.StatusTeapot, up: true}
+	gauge := &failoverGauge{values: make(map[string]float64)}
+
+	f := newFailover("failover", "main", main, "fallback", fallback, gauge)
+
+	serve := func() int {
+		recorder := httptest.NewRecorder()
+		f.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve())
+	assert.Equal(t, map[string]float64{"backend,failover,service,main": 1, "backend,failover,service,fallback": 0}, gauge.values)
+	assert.True(t, f.healthy())
+
+	// The main service has no server up.
+	main.up = false
+	assert.Equal(t, http.StatusTeapot, serve())
+	assert.Equal(t, map[string]float64{"backend,failover,service,main": 0, "backend,failover,service,fallback": 1}, gauge.values)
+	assert.True(t, f.healthy())
+
+	fallback.up = false
+	assert.False(t, f.healthy())
+
+	// The main service recovers.
+	main.up = true
+	assert.Equal(t, http.StatusOK, serve())
+	assert.Equal(t, map[string]float64{"backend,failover,service,main": 1, "backend,failover,service,fallback": 0}, gauge.values)
+}
+
+func TestFailover_startsOnFallback(t *testing.T) {
+	gauge := &failoverGauge{values: make(map[string]float64)}
+
+	newFailover("failover", "main", &healthHandler{}, "fallback", &healthHandler{up: true}, gauge)
+
+	assert.Equal(t, map[string]float64{"backend,failover,service,main": 0, "backend,failover,service,fallback": 1}, gauge.values)
+}
+
+func TestIsHealthy(t *testing.T) {
+	up := &healthHandler{up: true}
+	down := &healthHandler{}
+
+	testCases := []struct {
+		desc     string
+		handler  http.Handler
+		expected bool
+	}{
+		{
+			desc:     "handler without health",
+			handler:  http.NotFoundHandler(),
+			expected: true,
+		},
+		{
+			desc:     "weighted service with a service up",
+			handler:  &weightedBalancer{handlers: []*weightedHandler{{handler: down, weight: 1}, {handler: up, weight: 1}}},
+			expected: true,
+		},
+		{
+			desc:     "weighted service without service up",
+			handler:  &weightedBalancer{handlers: []*weightedHandler{{handler: down, weight: 1}}},
+			expected: false,
+		},
+		{
+			desc:     "mirroring service with the main service down",
+			handler:  newMirroring(down, -1),
+			expected: false,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, isHealthy(test.handler))
+		})
+	}
+}
+
+func TestManager_BuildFailover(t *testing.T) {
+	main := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer main.Close()
+
+	fallback := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+	}))
+	defer fallback.Close()
+
+	// The services are defined by two providers.
+	configs := map[string]*config.ServiceInfo{
+		"provider-1.failover": {
+			Service: &config.Service{
+				Failover: &config.Failover{Service: "main", Fallback: "provider-2.fallback"},
+			},
+		},
+		"provider-1.main": {
+			Service: &config.Service{
+				LoadBalancer: &config.LoadBalancerService{
+					Servers:     []config.Server{{URL: main.URL}},
+					HealthCheck: &config.HealthCheck{Path: "/health"},
+				},
+			},
+		},
+		"provider-2.fallback": {
+			Service: &config.Service{
+				LoadBalancer: &config.LoadBalancerService{Servers: []config.Server{{URL: fallback.URL}}},
+			},
+		},
+	}
+
+	gauge := &failoverGauge{values: make(map[string]float64)}
+
+	manager := NewManager(configs, NewRoundTripperManager(http.DefaultTransport))
+	manager.SetMetricsRegistry(failoverRegistry{Registry: metrics.NewVoidRegistry(), gauge: gauge})
+
+	ctx := internal.AddProviderInContext(context.Background(), "provider-1.foobar")
+
+	handler, err := manager.BuildHTTP(ctx, "failover", nil)
+	require.NoError(t, err)
+
+	serve := func() int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.com/", nil))
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve())
+	assert.Equal(t, float64(1), gauge.values["backend,provider-1.failover,service,provider-1.main"])
+
+	// The health check removes the server of the main service.
+	mainURL, err := url.Parse(main.URL)
+	require.NoError(t, err)
+	require.NoError(t, manager.balancers["provider-1.main"][0].RemoveServer(mainURL))
+
+	assert.Equal(t, http.StatusTeapot, serve())
+	assert.Equal(t, float64(1), gauge.values["backend,provider-1.failover,service,provider-2.fallback"])
+	assert.Equal(t, float64(0), gauge.values["backend,provider-1.failover,service,provider-1.main"])
+
+	// The health check restores the server of the main service.
+	require.NoError(t, manager.balancers["provider-1.main"][0].UpsertServer(mainURL))
+
+	assert.Equal(t, http.StatusOK, serve())
+	assert.Equal(t, float64(1), gauge.values["backend,provider-1.failover,service,provider-1.main"])
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/safe"
+	"github.com/vulcand/oxy/forward"
+	"github.com/vulcand/oxy/utils"
+)
+
+type mirrorHandler struct {
+	name    string
+	handler http.Handler
+	percent int
+	count   uint64
+}
+
+// mirroring sends the requests to the handler of the main service,
+// and a copy of a percentage of them to the handlers of the mirrors, in the background.
+// The responses of the mirrors are discarded, and their failures never affect the client.
+type mirroring struct {
+	handler     http.Handler
+	mirrors     []*mirrorHandler
+	maxBodySize int64
+
+	mu    sync.Mutex
+	total uint64
+}
+
+// newMirroring creates a mirroring, without any mirror.
+// The requests whose body is larger than maxBodySize are not mirrored, unless maxBodySize is negative.
+func newMirroring(handler http.Handler, maxBodySize int64) *mirroring {
+	return &mirroring{handler: handler, maxBodySize: maxBodySize}
+}
+
+// addMirror adds the handler of a mirror, receiving a copy of the given percentage of the requests.
+func (m *mirroring) addMirror(name string, handler http.Handler, percent int) {
+	m.mirrors = append(m.mirrors, &mirrorHandler{name: name, handler: handler, percent: percent})
+}
+
+// selectMirrors returns the mirrors receiving a copy of the next request:
+// a mirror receives a copy as long as its share of the requests is lower than its percentage,
+// so that the requests are sampled evenly, e.g. one request out of two for 50%.
+func (m *mirroring) selectMirrors() []*mirrorHandler {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.total++
+
+	var selected []*mirrorHandler
+	for _, mirror := range m.mirrors {
+		if mirror.count*100 < m.total*uint64(mirror.percent) {
+			mirror.count++
+			selected = append(selected, mirror)
+		}
+	}
+
+	return selected
+}
+
+// healthy tells whether the main service is healthy, as the mirrors never answer the requests.
+func (m *mirroring) healthy() bool {
+	return isHealthy(m.handler)
+}
+
+func (m *mirroring) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	mirrors := m.selectMirrors()
+	if len(mirrors) == 0 {
+		m.handler.ServeHTTP(rw, req)
+		return
+	}
+
+	logger := log.FromContext(req.Context())
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		reader := io.Reader(req.Body)
+		if m.maxBodySize >= 0 {
+			reader = io.LimitReader(req.Body, m.maxBodySize+1)
+		}
+
+		var err error
+		body, err = ioutil.ReadAll(reader)
+		tooLarge := m.maxBodySize >= 0 && int64(len(body)) > m.maxBodySize
+
+		if err != nil || tooLarge {
+			if err != nil {
+				logger.Debugf("Not mirroring the request: error while reading its body: %v", err)
+			} else {
+				logger.Debugf("Not mirroring the request: its body is larger than %d bytes", m.maxBodySize)
+			}
+
+			// The main service reads the body as if it had not been read.
+			req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), req.Body), Closer: req.Body}
+			m.handler.ServeHTTP(rw, req)
+			return
+		}
+
+		req.Body = readCloser{Reader: bytes.NewReader(body), Closer: req.Body}
+	}
+
+	for _, mirror := range mirrors {
+		mirror := mirror
+
+		// The request is copied before the main service handles it, as it can modify it.
+		mirrorReq := newMirrorRequest(req, body)
+		safe.GoWithRecover(func() {
+			mirror.handler.ServeHTTP(newDiscardResponseWriter(), mirrorReq)
+		}, func(err interface{}) {
+			logger.Errorf("Error while mirroring the request to %s: %v", mirror.name, err)
+		})
+	}
+
+	m.handler.ServeHTTP(rw, req)
+}
+
+// newMirrorRequest copies the request for a mirror, with its own body, URL, and headers, without the hop-by-hop headers.
+// It is not canceled with the request of the client.
+func newMirrorRequest(req *http.Request, body []byte) *http.Request {
+	mirrorReq := req.WithContext(context.Background())
+
+	u := *req.URL
+	mirrorReq.URL = &u
+
+	mirrorReq.Header = make(http.Header, len(req.Header))
+	for name, values := range req.Header {
+		mirrorReq.Header[name] = append([]string(nil), values...)
+	}
+
+	for _, value := range mirrorReq.Header["Connection"] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				mirrorReq.Header.Del(name)
+			}
+		}
+	}
+	utils.RemoveHeaders(mirrorReq.Header, forward.HopHeaders...)
+
+	if req.Body != nil && req.Body != http.NoBody {
+		mirrorReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	return mirrorReq
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// discardResponseWriter discards the responses of the mirrors.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func newDiscardResponseWriter() *discardResponseWriter {
+	return &discardResponseWriter{header: make(http.Header)}
+}
+
+func (d *discardResponseWriter) Header() http.Header {
+	return d.header
+}
+
+func (d *discardResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (d *discardResponseWriter) WriteHeader(int) {}
+
+func (d *discardResponseWriter) Flush() {}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirroring_percentages(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		percents map[string]int
+		requests int
+		expected map[string]int32
+	}{
+		{
+			desc:     "all the requests",
+			percents: map[string]int{"a": 100},
+			requests: 10,
+			expected: map[string]int32{"a": 10},
+		},
+		{
+			desc:     "no request",
+			percents: map[string]int{"a": 0},
+			requests: 10,
+			expected: map[string]int32{"a": 0},
+		},
+		{
+			desc:     "percentages of the requests",
+			percents: map[string]int{"a": 50, "b": 10, "c": 25},
+			requests: 20,
+			expected: map[string]int32{"a": 10, "b": 2, "c": 5},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var mainCount int32
+			mirroring := newMirroring(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+				atomic.AddInt32(&mainCount, 1)
+			}), -1)
+
+			var wg sync.WaitGroup
+			counts := make(map[string]*int32)
+			for name, percent := range test.percents {
+				count := new(int32)
+				counts[name] = count
+				wg.Add(int(test.expected[name]))
+
+				mirroring.addMirror(name, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+					atomic.AddInt32(count, 1)
+					wg.Done()
+				}), percent)
+			}
+
+			for i := 0; i < test.requests; i++ {
+				mirroring.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			}
+
+			wg.Wait()
+
+			assert.Equal(t, int32(test.requests), atomic.LoadInt32(&mainCount))
+			for name, count := range counts {
+				assert.Equal(t, test.expected[name], atomic.LoadInt32(count), name)
+			}
+		})
+	}
+}
+
+func TestMirroring_body(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		maxBodySize    int64
+		expectMirrored bool
+	}{
+		{
+			desc:           "no limit",
+			maxBodySize:    -1,
+			expectMirrored: true,
+		},
+		{
+			desc:           "body within the limit",
+			maxBodySize:    5,
+			expectMirrored: true,
+		},
+		{
+			desc:           "body larger than the limit",
+			maxBodySize:    4,
+			expectMirrored: false,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			mirroring := newMirroring(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, err := ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+				assert.Equal(t, "hello", string(body))
+			}), test.maxBodySize)
+
+			mirrored := make(chan string, 1)
+			mirroring.addMirror("mirror", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, err := ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+				mirrored <- string(body)
+			}), 100)
+
+			recorder := httptest.NewRecorder()
+			mirroring.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hello")))
+			assert.Equal(t, http.StatusOK, recorder.Code)
+
+			select {
+			case body := <-mirrored:
+				assert.True(t, test.expectMirrored, "the request should not be mirrored")
+				assert.Equal(t, "hello", body)
+			case <-time.After(100 * time.Millisecond):
+				assert.False(t, test.expectMirrored, "the request should be mirrored")
+			}
+		})
+	}
+}
+
+func TestMirroring_hopByHopHeaders(t *testing.T) {
+	mirroring := newMirroring(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "bar", req.Header.Get("X-Foo"))
+		assert.Equal(t, "timeout=5", req.Header.Get("Keep-Alive"))
+	}), -1)
+
+	mirrored := make(chan http.Header, 1)
+	mirroring.addMirror("mirror", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mirrored <- req.Header
+	}), 100)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Connection", "X-Foo")
+	req.Header.Set("X-Foo", "bar")
+	req.Header.Set("Keep-Alive", "timeout=5")
+	req.Header.Set("X-Bar", "foo")
+
+	mirroring.ServeHTTP(httptest.NewRecorder(), req)
+
+	header := <-mirrored
+	assert.Equal(t, "foo", header.Get("X-Bar"))
+	for _, name := range []string{"Connection", "X-Foo", "Keep-Alive"} {
+		assert.Empty(t, header.Get(name), name)
+	}
+}
+
+func TestManager_BuildMirroring(t *testing.T) {
+	var redirected int32
+	target := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		atomic.AddInt32(&redirected, 1)
+	}))
+	defer target.Close()
+
+	mirrored := make(chan struct{}, 1)
+	mirror := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Redirect(rw, req, target.URL, http.StatusFound)
+		mirrored <- struct{}{}
+	}))
+	defer mirror.Close()
+
+	main := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+	}))
+	defer main.Close()
+
+	// The mirror which cannot be reached does not affect the client.
+	unreachable := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	unreachable.Close()
+
+	configs := map[string]*config.ServiceInfo{
+		"mirroring": {
+			Service: &config.Service{
+				Mirroring: &config.Mirroring{
+					Service: "main",
+					Mirrors: []config.MirrorService{
+						{Name: "mirror", Percent: 100},
+						{Name: "unreachable", Percent: 100},
+					},
+				},
+			},
+		},
+		"main": {
+			Service: &config.Service{
+				LoadBalancer: &config.LoadBalancerService{Servers: []config.Server{{URL: main.URL}}},
+			},
+		},
+		"mirror": {
+			Service: &config.Service{
+				LoadBalancer: &config.LoadBalancerService{Servers: []config.Server{{URL: mirror.URL}}},
+			},
+		},
+		"unreachable": {
+			Service: &config.Service{
+				LoadBalancer: &config.LoadBalancerService{Servers: []config.Server{{URL: unreachable.URL}}},
+			},
+		},
+	}
+
+	manager := NewManager(configs, NewRoundTripperManager(http.DefaultTransport))
+
+	handler, err := manager.BuildHTTP(context.Background(), "mirroring", nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.com/", nil))
+	assert.Equal(t, http.StatusTeapot, recorder.Code)
+
+	<-mirrored
+
+	// The redirection of the mirror is not followed.
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&redirected))
+}
@@ -21,7 +21,7 @@ const StatusClientClosedRequest = 499
 // StatusClientClosedRequestText non-standard HTTP status for client disconnection
 const StatusClientClosedRequestText = "Client Closed Request"
 
-func buildProxy(passHostHeader bool, responseForwarding *config.ResponseForwarding, defaultRoundTripper http.RoundTripper, bufferPool httputil.BufferPool, responseModifier func(*http.Response) error) (http.Handler, error) {
+func buildProxy(passHostHeader bool, responseForwarding *config.ResponseForwarding, roundTripper http.RoundTripper, bufferPool httputil.BufferPool, responseModifier func(*http.Response) error) (http.Handler, error) {
 	var flushInterval types.Duration
 	if responseForwarding != nil {
 		err := flushInterval.Set(responseForwarding.FlushInterval)
@@ -29,6 +29,9 @@ func buildProxy(passHostHeader bool, responseForwarding *config.ResponseForwardi
 			return nil, fmt.Errorf("error creating flush interval: %v", err)
 		}
 	}
+	// A negative interval flushes immediately after each write.
+	// Whatever the interval, the reverse proxy flushes the streamed responses immediately:
+	// the text/event-stream ones, and the ones without a known length.
 	if flushInterval == 0 {
 		flushInterval = types.Duration(100 * time.Millisecond)
 	}
@@ -58,7 +61,7 @@ func buildProxy(passHostHeader bool, responseForwarding *config.ResponseForwardi
 			}
 
 		},
-		Transport:      defaultRoundTripper,
+		Transport:      roundTripper,
 		FlushInterval:  time.Duration(flushInterval),
 		ModifyResponse: responseModifier,
 		BufferPool:     bufferPool,
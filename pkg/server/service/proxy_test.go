@@ -1,13 +1,18 @@
 package service
 
 import (
+	"bufio"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type staticTransport struct {
@@ -35,3 +40,89 @@ func BenchmarkProxy(b *testing.B) {
 		handler.ServeHTTP(w, req)
 	}
 }
+
+func TestProxyFlushInterval(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		flushInterval string
+		contentType   string
+		expectFlushed bool
+	}{
+		{
+			desc:          "flushed immediately",
+			flushInterval: "-1",
+			contentType:   "text/plain",
+			expectFlushed: true,
+		},
+		{
+			desc:          "flushed after the interval",
+			flushInterval: "10s",
+			contentType:   "text/plain",
+			expectFlushed: false,
+		},
+		{
+			desc:          "event stream flushed immediately whatever the interval",
+			flushInterval: "10s",
+			contentType:   "text/event-stream",
+			expectFlushed: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			// The slow backend sends a first line, and waits for the test to end before sending the second one.
+			done := make(chan struct{})
+
+			backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", test.contentType)
+				// The length is known, so that the response is not flushed as an unknown length chunked response.
+				rw.Header().Set("Content-Length", "12")
+				rw.WriteHeader(http.StatusOK)
+
+				_, _ = rw.Write([]byte("first\n"))
+				rw.(http.Flusher).Flush()
+
+				select {
+				case <-done:
+				case <-req.Context().Done():
+				}
+				_, _ = rw.Write([]byte("last!\n"))
+			}))
+			defer backend.Close()
+
+			handler, err := buildProxy(true, &config.ResponseForwarding{FlushInterval: test.flushInterval}, http.DefaultTransport, nil, nil)
+			require.NoError(t, err)
+
+			proxy := createProxyWithForwarder(t, handler, backend.URL)
+			defer proxy.Close()
+
+			// The backend ends before the servers are closed.
+			defer close(done)
+
+			// The response headers are not flushed before the first line either.
+			lines := make(chan string, 1)
+			go func() {
+				resp, err := http.Get(proxy.URL)
+				if err != nil {
+					lines <- err.Error()
+					return
+				}
+				defer resp.Body.Close()
+
+				line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+				lines <- line
+			}()
+
+			select {
+			case line := <-lines:
+				assert.True(t, test.expectFlushed, "the first line should not be flushed yet")
+				assert.Equal(t, "first\n", line)
+			case <-time.After(500 * time.Millisecond):
+				assert.False(t, test.expectFlushed, "the first line should be flushed")
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/log"
+	traefiktls "github.com/containous/traefik/pkg/tls"
+	"golang.org/x/net/http2"
+)
+
+// RoundTripperManager holds the round trippers of the servers transports.
+// They are kept across the configuration reloads, so that they keep their connections to the servers,
+// and only the round trippers whose servers transport changed are created again.
+type RoundTripperManager struct {
+	rtLock              sync.RWMutex
+	defaultRoundTripper http.RoundTripper
+	roundTrippers       map[string]http.RoundTripper
+	configs             map[string]*config.ServersTransport
+}
+
+// NewRoundTripperManager creates a RoundTripperManager, without any servers transport.
+// The default round tripper is used by the load-balancers which do not reference a servers transport.
+func NewRoundTripperManager(defaultRoundTripper http.RoundTripper) *RoundTripperManager {
+	return &RoundTripperManager{
+		defaultRoundTripper: defaultRoundTripper,
+		roundTrippers:       make(map[string]http.RoundTripper),
+		configs:             make(map[string]*config.ServersTransport),
+	}
+}
+
+// Update updates the round trippers with the given servers transports, named by their qualified name.
+// The round trippers of the servers transports which did not change are kept.
+func (r *RoundTripperManager) Update(newConfigs map[string]*config.ServersTransport) {
+	r.rtLock.Lock()
+	defer r.rtLock.Unlock()
+
+	for name, conf := range r.configs {
+		if newConfig, ok := newConfigs[name]; ok && reflect.DeepEqual(newConfig, conf) {
+			continue
+		}
+
+		if transport, ok := r.roundTrippers[name].(*http.Transport); ok {
+			transport.CloseIdleConnections()
+		}
+
+		delete(r.configs, name)
+		delete(r.roundTrippers, name)
+	}
+
+	for name, newConfig := range newConfigs {
+		if _, ok := r.configs[name]; ok {
+			continue
+		}
+
+		transport, err := NewTransport(newConfig)
+		if err != nil {
+			log.WithoutContext().Errorf("Could not configure the servers transport %s, fallbacking on the default transport: %v", name, err)
+			r.roundTrippers[name] = r.defaultRoundTripper
+		} else {
+			r.roundTrippers[name] = transport
+		}
+
+		r.configs[name] = newConfig
+	}
+}
+
+// Get returns the round tripper of the servers transport, or the default round tripper when the name is empty.
+func (r *RoundTripperManager) Get(name string) (http.RoundTripper, error) {
+	if name == "" {
+		return r.defaultRoundTripper, nil
+	}
+
+	r.rtLock.RLock()
+	defer r.rtLock.RUnlock()
+
+	if roundTripper, ok := r.roundTrippers[name]; ok {
+		return roundTripper, nil
+	}
+
+	return nil, fmt.Errorf("the servers transport %q does not exist", name)
+}
+
+type h2cTransportWrapper struct {
+	*http2.Transport
+}
+
+func (t *h2cTransportWrapper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = "http"
+	return t.Transport.RoundTrip(req)
+}
+
+// NewTransport creates an http.Transport configured with the servers transport settings.
+// For the settings that can't be configured in Traefik it uses the default http.Transport settings.
+// An exception to this is the MaxIdleConns setting as we only provide the option MaxIdleConnsPerHost
+// in Traefik at this point in time. Setting this value to the default of 100 could lead to confusing
+// behavior and backwards compatibility issues.
+func NewTransport(transportConfiguration *config.ServersTransport) (*http.Transport, error) {
+	if transportConfiguration == nil {
+		return nil, errors.New("no transport configuration given")
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		DualStack: true,
+	}
+
+	if transportConfiguration.ForwardingTimeouts != nil {
+		dialer.Timeout = time.Duration(transportConfiguration.ForwardingTimeouts.DialTimeout)
+	}
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		MaxIdleConnsPerHost:   transportConfiguration.MaxIdleConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	transport.RegisterProtocol("h2c", &h2cTransportWrapper{
+		Transport: &http2.Transport{
+			DialTLS: func(netw, addr string, cfg *tls.Config) (net.Conn, error) {
+				return net.Dial(netw, addr)
+			},
+			AllowHTTP: true,
+		},
+	})
+
+	if transportConfiguration.ForwardingTimeouts != nil {
+		transport.ResponseHeaderTimeout = time.Duration(transportConfiguration.ForwardingTimeouts.ResponseHeaderTimeout)
+	}
+
+	tlsConfig, err := createTLSConfig(transportConfiguration)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	err = http2.ConfigureTransport(transport)
+	if err != nil {
+		return nil, err
+	}
+
+	return transport, nil
+}
+
+// createTLSConfig creates the TLS configuration of the connections to the servers,
+// or nil when the servers transport does not configure them.
+func createTLSConfig(transportConfiguration *config.ServersTransport) (*tls.Config, error) {
+	if transportConfiguration.ServerName == "" && !transportConfiguration.InsecureSkipVerify &&
+		len(transportConfiguration.RootCAs) == 0 && len(transportConfiguration.Certificates) == 0 {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         transportConfiguration.ServerName,
+		InsecureSkipVerify: transportConfiguration.InsecureSkipVerify,
+	}
+
+	if len(transportConfiguration.RootCAs) > 0 {
+		tlsConfig.RootCAs = createRootCACertPool(transportConfiguration.RootCAs)
+	}
+
+	for _, certificate := range transportConfiguration.Certificates {
+		cert, err := loadClientCertificate(certificate)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the client certificate %s: %v", certificate.GetTruncatedCertificateName(), err)
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	}
+
+	return tlsConfig, nil
+}
+
+func loadClientCertificate(certificate traefiktls.Certificate) (tls.Certificate, error) {
+	certContent, err := certificate.ReadCert()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	keyContent, err := certificate.ReadKey()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.X509KeyPair(certContent, keyContent)
+}
+
+func createRootCACertPool(rootCAs []traefiktls.FileOrContent) *x509.CertPool {
+	roots := x509.NewCertPool()
+
+	for _, cert := range rootCAs {
+		certContent, err := cert.Read()
+		if err != nil {
+			log.WithoutContext().Error("Error while read RootCAs", err)
+			continue
+		}
+		roots.AppendCertsFromPEM(certContent)
+	}
+
+	return roots
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/pkg/config"
+	traefiktls "github.com/containous/traefik/pkg/tls"
+	"github.com/containous/traefik/pkg/tls/generate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransport_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	rootCA := traefiktls.FileOrContent(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	testCases := []struct {
+		desc        string
+		transport   *config.ServersTransport
+		expectError bool
+	}{
+		{
+			desc:        "without root CA",
+			transport:   &config.ServersTransport{},
+			expectError: true,
+		},
+		{
+			desc:      "with the root CA",
+			transport: &config.ServersTransport{RootCAs: []traefiktls.FileOrContent{rootCA}},
+		},
+		{
+			desc: "with the root CA and the server name of the certificate",
+			transport: &config.ServersTransport{
+				RootCAs:    []traefiktls.FileOrContent{rootCA},
+				ServerName: "example.com",
+			},
+		},
+		{
+			desc: "with the root CA and another server name",
+			transport: &config.ServersTransport{
+				RootCAs:    []traefiktls.FileOrContent{rootCA},
+				ServerName: "foo.com",
+			},
+			expectError: true,
+		},
+		{
+			desc:      "insecure skip verify",
+			transport: &config.ServersTransport{InsecureSkipVerify: true},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			transport, err := NewTransport(test.transport)
+			require.NoError(t, err)
+
+			client := http.Client{Transport: transport}
+
+			resp, err := client.Get(server.URL)
+			if test.expectError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func TestNewTransport_clientCertificate(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if len(req.TLS.PeerCertificates) == 0 {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	cert, key, err := generate.KeyPair("client.com", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	transport, err := NewTransport(&config.ServersTransport{
+		InsecureSkipVerify: true,
+		Certificates: traefiktls.Certificates{
+			{CertFile: traefiktls.FileOrContent(cert), KeyFile: traefiktls.FileOrContent(key)},
+		},
+	})
+	require.NoError(t, err)
+
+	client := http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestNewTransport_invalidClientCertificate(t *testing.T) {
+	_, err := NewTransport(&config.ServersTransport{
+		Certificates: traefiktls.Certificates{
+			{CertFile: "not a certificate", KeyFile: "not a key"},
+		},
+	})
+	require.Error(t, err)
+}
+
+func TestRoundTripperManager(t *testing.T) {
+	manager := NewRoundTripperManager(http.DefaultTransport)
+
+	roundTripper, err := manager.Get("")
+	require.NoError(t, err)
+	assert.Equal(t, http.DefaultTransport, roundTripper)
+
+	manager.Update(map[string]*config.ServersTransport{
+		"file.a": {MaxIdleConnsPerHost: 10},
+		"file.b": {MaxIdleConnsPerHost: 10},
+	})
+
+	a, err := manager.Get("file.a")
+	require.NoError(t, err)
+	b, err := manager.Get("file.b")
+	require.NoError(t, err)
+	assert.True(t, a != b)
+
+	// The round tripper of the servers transport which did not change is kept.
+	manager.Update(map[string]*config.ServersTransport{
+		"file.a": {MaxIdleConnsPerHost: 10},
+		"file.b": {MaxIdleConnsPerHost: 20},
+	})
+
+	newA, err := manager.Get("file.a")
+	require.NoError(t, err)
+	assert.True(t, a == newA)
+
+	newB, err := manager.Get("file.b")
+	require.NoError(t, err)
+	assert.True(t, b != newB)
+	assert.Equal(t, 20, newB.(*http.Transport).MaxIdleConnsPerHost)
+
+	manager.Update(map[string]*config.ServersTransport{
+		"file.b": {MaxIdleConnsPerHost: 20},
+	})
+
+	_, err = manager.Get("file.a")
+	assert.EqualError(t, err, `the servers transport "file.a" does not exist`)
+}
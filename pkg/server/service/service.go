@@ -6,17 +6,20 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/containous/alice"
 	"github.com/containous/traefik/pkg/config"
 	"github.com/containous/traefik/pkg/healthcheck"
 	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/metrics"
 	"github.com/containous/traefik/pkg/middlewares/accesslog"
 	"github.com/containous/traefik/pkg/middlewares/emptybackendhandler"
 	"github.com/containous/traefik/pkg/middlewares/pipelining"
 	"github.com/containous/traefik/pkg/server/cookie"
 	"github.com/containous/traefik/pkg/server/internal"
+	"github.com/containous/traefik/pkg/types"
 	"github.com/vulcand/oxy/roundrobin"
 )
 
@@ -26,21 +29,31 @@ const (
 )
 
 // NewManager creates a new Manager
-func NewManager(configs map[string]*config.ServiceInfo, defaultRoundTripper http.RoundTripper) *Manager {
+func NewManager(configs map[string]*config.ServiceInfo, roundTripperManager *RoundTripperManager) *Manager {
 	return &Manager{
 		bufferPool:          newBufferPool(),
-		defaultRoundTripper: defaultRoundTripper,
-		balancers:           make(map[string][]healthcheck.BalancerHandler),
+		roundTripperManager: roundTripperManager,
+		balancers:           make(map[string]healthcheck.Balancers),
+		roundTrippers:       make(map[string]http.RoundTripper),
 		configs:             configs,
+		metricsRegistry:     metrics.NewVoidRegistry(),
 	}
 }
 
 // Manager The service manager
 type Manager struct {
 	bufferPool          httputil.BufferPool
-	defaultRoundTripper http.RoundTripper
-	balancers           map[string][]healthcheck.BalancerHandler
-	configs             map[string]*config.ServiceInfo
+	roundTripperManager *RoundTripperManager
+	balancers           map[string]healthcheck.Balancers
+	// roundTrippers are the round trippers of the load-balancers, also used by their health checks.
+	roundTrippers   map[string]http.RoundTripper
+	configs         map[string]*config.ServiceInfo
+	metricsRegistry metrics.Registry
+}
+
+// SetMetricsRegistry sets the registry of the metrics of the services, e.g. the active services of the failover services.
+func (m *Manager) SetMetricsRegistry(registry metrics.Registry) {
+	m.metricsRegistry = registry
 }
 
 // BuildHTTP Creates a http.Handler for a service configuration.
@@ -55,20 +68,142 @@ func (m *Manager) BuildHTTP(rootCtx context.Context, serviceName string, respons
 		return nil, fmt.Errorf("the service %q does not exist", serviceName)
 	}
 
-	// TODO Should handle multiple service types
-	// FIXME Check if the service is declared multiple times with different types
-	if conf.LoadBalancer == nil {
-		conf.Err = fmt.Errorf("the service %q doesn't have any load balancer", serviceName)
-		return nil, conf.Err
+	var kinds int
+	for _, set := range []bool{conf.LoadBalancer != nil, conf.Weighted != nil, conf.Mirroring != nil, conf.Failover != nil} {
+		if set {
+			kinds++
+		}
+	}
+
+	var handler http.Handler
+	var err error
+	switch {
+	case kinds > 1:
+		err = fmt.Errorf("the service %q has more than one type", serviceName)
+	case conf.LoadBalancer != nil:
+		handler, err = m.getLoadBalancerServiceHandler(ctx, serviceName, conf.LoadBalancer, responseModifier)
+	case conf.Weighted != nil:
+		handler, err = m.getWeightedServiceHandler(withParent(ctx, serviceName), serviceName, conf.Weighted, responseModifier)
+	case conf.Mirroring != nil:
+		handler, err = m.getMirroringServiceHandler(withParent(ctx, serviceName), serviceName, conf.Mirroring, responseModifier)
+	case conf.Failover != nil:
+		handler, err = m.getFailoverServiceHandler(withParent(ctx, serviceName), serviceName, conf.Failover, responseModifier)
+	default:
+		err = fmt.Errorf("the service %q doesn't have any load balancer", serviceName)
 	}
 
-	lb, err := m.getLoadBalancerServiceHandler(ctx, serviceName, conf.LoadBalancer, responseModifier)
 	if err != nil {
 		conf.Err = err
 		return nil, err
 	}
 
-	return lb, nil
+	return handler, nil
+}
+
+// servicesKey is the key, in the context, of the names of the services referencing other services being built,
+// from the outermost to the innermost, to detect the services referencing themselves.
+type servicesKey struct{}
+
+// withParent adds the service to the parents of the services it references.
+func withParent(ctx context.Context, serviceName string) context.Context {
+	parents, _ := ctx.Value(servicesKey{}).([]string)
+
+	// The slice is copied, so that the sibling services do not share their parents.
+	return context.WithValue(ctx, servicesKey{}, append(append([]string{}, parents...), serviceName))
+}
+
+// buildChild builds a service referenced by a weighted, a mirroring, or a failover service,
+// unless it is one of the services referencing it.
+func (m *Manager) buildChild(ctx context.Context, serviceName string, responseModifier func(*http.Response) error) (http.Handler, error) {
+	qualifiedName := internal.GetQualifiedName(ctx, serviceName)
+
+	parents, _ := ctx.Value(servicesKey{}).([]string)
+	for _, parent := range parents {
+		if parent == qualifiedName {
+			return nil, fmt.Errorf("the service %q references itself through %s", qualifiedName, strings.Join(append(parents, qualifiedName), " -> "))
+		}
+	}
+
+	return m.BuildHTTP(ctx, serviceName, responseModifier)
+}
+
+func (m *Manager) getWeightedServiceHandler(
+	ctx context.Context,
+	serviceName string,
+	service *config.WeightedService,
+	responseModifier func(*http.Response) error,
+) (http.Handler, error) {
+	balancer := newWeightedBalancer()
+	for _, child := range service.Services {
+		handler, err := m.buildChild(ctx, child.Name, responseModifier)
+		if err != nil {
+			return nil, fmt.Errorf("error building the service %s of the weighted service %s: %v", child.Name, serviceName, err)
+		}
+
+		weight := 1
+		if child.Weight != nil {
+			weight = *child.Weight
+		}
+
+		log.FromContext(ctx).Debugf("Adding the service %s with a weight of %d", child.Name, weight)
+		balancer.add(handler, weight)
+	}
+
+	return balancer, nil
+}
+
+func (m *Manager) getMirroringServiceHandler(
+	ctx context.Context,
+	serviceName string,
+	service *config.Mirroring,
+	responseModifier func(*http.Response) error,
+) (http.Handler, error) {
+	handler, err := m.buildChild(ctx, service.Service, responseModifier)
+	if err != nil {
+		return nil, fmt.Errorf("error building the main service %s of the mirroring service %s: %v", service.Service, serviceName, err)
+	}
+
+	maxBodySize := int64(-1)
+	if service.MaxBodySize != nil {
+		maxBodySize = *service.MaxBodySize
+	}
+
+	mirroring := newMirroring(handler, maxBodySize)
+	for _, mirror := range service.Mirrors {
+		mirrorHandler, err := m.buildChild(ctx, mirror.Name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error building the mirror %s of the mirroring service %s: %v", mirror.Name, serviceName, err)
+		}
+
+		log.FromContext(ctx).Debugf("Adding the mirror %s for %d%% of the requests", mirror.Name, mirror.Percent)
+		mirroring.addMirror(mirror.Name, mirrorHandler, mirror.Percent)
+	}
+
+	return mirroring, nil
+}
+
+func (m *Manager) getFailoverServiceHandler(
+	ctx context.Context,
+	serviceName string,
+	service *config.Failover,
+	responseModifier func(*http.Response) error,
+) (http.Handler, error) {
+	handler, err := m.buildChild(ctx, service.Service, responseModifier)
+	if err != nil {
+		return nil, fmt.Errorf("error building the main service %s of the failover service %s: %v", service.Service, serviceName, err)
+	}
+
+	fallback, err := m.buildChild(ctx, service.Fallback, responseModifier)
+	if err != nil {
+		return nil, fmt.Errorf("error building the fallback service %s of the failover service %s: %v", service.Fallback, serviceName, err)
+	}
+
+	handlerName := internal.GetQualifiedName(ctx, service.Service)
+	if conf, ok := m.configs[handlerName]; ok && conf.LoadBalancer != nil && conf.LoadBalancer.HealthCheck == nil {
+		log.FromContext(ctx).Warnf("The main service %s has no health check: the requests are never sent to the fallback service %s", handlerName, service.Fallback)
+	}
+
+	return newFailover(serviceName, handlerName, handler, internal.GetQualifiedName(ctx, service.Fallback), fallback, m.metricsRegistry.BackendFailoverActiveGauge()), nil
 }
 
 func (m *Manager) getLoadBalancerServiceHandler(
@@ -77,7 +212,17 @@ func (m *Manager) getLoadBalancerServiceHandler(
 	service *config.LoadBalancerService,
 	responseModifier func(*http.Response) error,
 ) (http.Handler, error) {
-	fwd, err := buildProxy(service.PassHostHeader, service.ResponseForwarding, m.defaultRoundTripper, m.bufferPool, responseModifier)
+	var transportName string
+	if service.ServersTransport != "" {
+		transportName = internal.GetQualifiedName(ctx, service.ServersTransport)
+	}
+
+	roundTripper, err := m.roundTripperManager.Get(transportName)
+	if err != nil {
+		return nil, err
+	}
+
+	fwd, err := buildProxy(service.PassHostHeader, service.ResponseForwarding, roundTripper, m.bufferPool, responseModifier)
 	if err != nil {
 		return nil, err
 	}
@@ -96,11 +241,12 @@ func (m *Manager) getLoadBalancerServiceHandler(
 		return nil, err
 	}
 
-	// TODO rename and checks
+	// The balancers of a service, one per router using it, share the health check of the service.
 	m.balancers[serviceName] = append(m.balancers[serviceName], balancer)
+	m.roundTrippers[serviceName] = roundTripper
 
 	// Empty (backend with no servers)
-	return emptybackendhandler.New(balancer), nil
+	return &loadBalancerHandler{Handler: emptybackendhandler.New(balancer), balancer: balancer}, nil
 }
 
 // LaunchHealthCheck Launches the health checks.
@@ -110,18 +256,15 @@ func (m *Manager) LaunchHealthCheck() {
 	for serviceName, balancers := range m.balancers {
 		ctx := log.With(context.Background(), log.Str(log.ServiceName, serviceName))
 
-		// TODO aggregate
-		balancer := balancers[0]
-
-		// TODO Should all the services handle healthcheck? Handle different types
+		// Only the load-balancers have balancers: the other services rely on the health checks of the services they reference.
 		service := m.configs[serviceName].LoadBalancer
 
 		// Health Check
 		var backendHealthCheck *healthcheck.BackendConfig
-		if hcOpts := buildHealthCheckOptions(ctx, balancer, serviceName, service.HealthCheck); hcOpts != nil {
+		if hcOpts := buildHealthCheckOptions(ctx, balancers, serviceName, service.HealthCheck); hcOpts != nil {
 			log.FromContext(ctx).Debugf("Setting up healthcheck for service %s with %s", serviceName, *hcOpts)
 
-			hcOpts.Transport = m.defaultRoundTripper
+			hcOpts.Transport = m.roundTrippers[serviceName]
 			backendHealthCheck = healthcheck.NewBackendConfig(*hcOpts, serviceName)
 		}
 
@@ -134,7 +277,7 @@ func (m *Manager) LaunchHealthCheck() {
 	healthcheck.GetHealthCheck().SetBackendsConfiguration(context.TODO(), backendConfigs)
 }
 
-func buildHealthCheckOptions(ctx context.Context, lb healthcheck.BalancerHandler, backend string, hc *config.HealthCheck) *healthcheck.Options {
+func buildHealthCheckOptions(ctx context.Context, lb healthcheck.Balancer, backend string, hc *config.HealthCheck) *healthcheck.Options {
 	if hc == nil || hc.Path == "" {
 		return nil
 	}
@@ -143,7 +286,7 @@ func buildHealthCheckOptions(ctx context.Context, lb healthcheck.BalancerHandler
 
 	interval := defaultHealthCheckInterval
 	if hc.Interval != "" {
-		intervalOverride, err := time.ParseDuration(hc.Interval)
+		intervalOverride, err := types.ParseDuration(hc.Interval)
 		switch {
 		case err != nil:
 			logger.Errorf("Illegal health check interval for '%s': %s", backend, err)
@@ -156,7 +299,7 @@ func buildHealthCheckOptions(ctx context.Context, lb healthcheck.BalancerHandler
 
 	timeout := defaultHealthCheckTimeout
 	if hc.Timeout != "" {
-		timeoutOverride, err := time.ParseDuration(hc.Timeout)
+		timeoutOverride, err := types.ParseDuration(hc.Timeout)
 		switch {
 		case err != nil:
 			logger.Errorf("Illegal health check timeout for backend '%s': %s", backend, err)
@@ -168,6 +311,7 @@ func buildHealthCheckOptions(ctx context.Context, lb healthcheck.BalancerHandler
 	}
 
 	if timeout >= interval {
+		interval = timeout + time.Second
 		logger.Warnf("Health check timeout for backend '%s' should be lower than the health check interval. Interval set to timeout + 1 second (%s).", backend, interval)
 	}
 
@@ -187,30 +331,28 @@ func (m *Manager) getLoadBalancer(ctx context.Context, serviceName string, servi
 	logger := log.FromContext(ctx)
 	logger.Debug("Creating load-balancer")
 
-	var options []roundrobin.LBOption
-
-	var cookieName string
-	if stickiness := service.Stickiness; stickiness != nil {
-		cookieName = cookie.GetName(stickiness.CookieName, serviceName)
-		opts := roundrobin.CookieOptions{HTTPOnly: stickiness.HTTPOnlyCookie, Secure: stickiness.SecureCookie}
-		options = append(options, roundrobin.EnableStickySession(roundrobin.NewStickySessionWithOptions(cookieName, opts)))
-		logger.Debugf("Sticky session cookie name: %v", cookieName)
-	}
-
-	lb, err := roundrobin.New(fwd, options...)
+	lb, err := roundrobin.New(fwd)
 	if err != nil {
 		return nil, err
 	}
 
+	// The status updater is returned, so that the servers removed, and restored, by the health check update the status of the service.
 	lbsu := healthcheck.NewLBStatusUpdater(lb, m.configs[serviceName])
 	if err := m.upsertServers(ctx, lbsu, service.Servers); err != nil {
 		return nil, fmt.Errorf("error configuring load balancer for service %s: %v", serviceName, err)
 	}
 
-	return lb, nil
+	if service.Sticky != nil && service.Sticky.Cookie != nil {
+		cookieName := cookie.GetName(service.Sticky.Cookie.Name, serviceName)
+		logger.Debugf("Sticky session cookie name: %v", cookieName)
+
+		return newStickyBalancer(lbsu, lb, fwd, cookieName, service.Sticky.Cookie), nil
+	}
+
+	return lbsu, nil
 }
 
-func (m *Manager) upsertServers(ctx context.Context, lb healthcheck.BalancerHandler, servers []config.Server) error {
+func (m *Manager) upsertServers(ctx context.Context, lb *healthcheck.LbStatusUpdater, servers []config.Server) error {
 	logger := log.FromContext(ctx)
 
 	for name, srv := range servers {
@@ -221,10 +363,19 @@ func (m *Manager) upsertServers(ctx context.Context, lb healthcheck.BalancerHand
 
 		logger.WithField(log.ServerName, name).Debugf("Creating server %d %s", name, u)
 
-		if err := lb.UpsertServer(u, roundrobin.Weight(1)); err != nil {
+		weight := 1
+		if srv.Weight > 0 {
+			weight = srv.Weight
+		}
+
+		if err := lb.UpsertServer(u, roundrobin.Weight(weight)); err != nil {
 			return fmt.Errorf("error adding server %s to load balancer: %v", srv.URL, err)
 		}
 
+		if srv.Stale {
+			lb.MarkStale(u)
+		}
+
 		// FIXME Handle Metrics
 	}
 	return nil
@@ -6,8 +6,10 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/healthcheck"
 	"github.com/containous/traefik/pkg/server/internal"
 	"github.com/containous/traefik/pkg/testhelpers"
 	"github.com/stretchr/testify/assert"
@@ -51,10 +53,10 @@ func TestGetLoadBalancer(t *testing.T) {
 			expectError: false,
 		},
 		{
-			desc:        "Succeeds when stickiness is set",
+			desc:        "Succeeds when sticky.cookie is set",
 			serviceName: "test",
 			service: &config.LoadBalancerService{
-				Stickiness: &config.Stickiness{},
+				Sticky: &config.Sticky{Cookie: &config.Cookie{}},
 			},
 			fwd:         &MockForwarder{},
 			expectError: false,
@@ -79,7 +81,7 @@ func TestGetLoadBalancer(t *testing.T) {
 }
 
 func TestGetLoadBalancerServiceHandler(t *testing.T) {
-	sm := NewManager(nil, http.DefaultTransport)
+	sm := NewManager(nil, NewRoundTripperManager(http.DefaultTransport))
 
 	server1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("X-From", "first")
@@ -108,6 +110,7 @@ func TestGetLoadBalancerServiceHandler(t *testing.T) {
 		XFrom          string
 		SecureCookie   bool
 		HTTPOnlyCookie bool
+		SameSite       string
 	}
 
 	testCases := []struct {
@@ -171,10 +174,10 @@ func TestGetLoadBalancerServiceHandler(t *testing.T) {
 			},
 		},
 		{
-			desc:        "Always call the same server when stickiness is true",
+			desc:        "Always call the same server when sticky.cookie is set",
 			serviceName: "test",
 			service: &config.LoadBalancerService{
-				Stickiness: &config.Stickiness{},
+				Sticky: &config.Sticky{Cookie: &config.Cookie{}},
 				Servers: []config.Server{
 					{
 						URL: server1.URL,
@@ -199,7 +202,9 @@ func TestGetLoadBalancerServiceHandler(t *testing.T) {
 			desc:        "Sticky Cookie's options set correctly",
 			serviceName: "test",
 			service: &config.LoadBalancerService{
-				Stickiness: &config.Stickiness{HTTPOnlyCookie: true, SecureCookie: true},
+				Sticky: &config.Sticky{
+					Cookie: &config.Cookie{HTTPOnly: true, Secure: true, SameSite: "strict"},
+				},
 				Servers: []config.Server{
 					{
 						URL: server1.URL,
@@ -212,6 +217,7 @@ func TestGetLoadBalancerServiceHandler(t *testing.T) {
 					XFrom:          "first",
 					SecureCookie:   true,
 					HTTPOnlyCookie: true,
+					SameSite:       "Strict",
 				},
 			},
 		},
@@ -219,7 +225,7 @@ func TestGetLoadBalancerServiceHandler(t *testing.T) {
 			desc:        "PassHost passes the host instead of the IP",
 			serviceName: "test",
 			service: &config.LoadBalancerService{
-				Stickiness:     &config.Stickiness{},
+				Sticky:         &config.Sticky{Cookie: &config.Cookie{}},
 				PassHostHeader: true,
 				Servers: []config.Server{
 					{
@@ -238,7 +244,7 @@ func TestGetLoadBalancerServiceHandler(t *testing.T) {
 			desc:        "PassHost doesn't passe the host instead of the IP",
 			serviceName: "test",
 			service: &config.LoadBalancerService{
-				Stickiness: &config.Stickiness{},
+				Sticky: &config.Sticky{Cookie: &config.Cookie{}},
 				Servers: []config.Server{
 					{
 						URL: serverPassHostFalse.URL,
@@ -277,6 +283,7 @@ func TestGetLoadBalancerServiceHandler(t *testing.T) {
 					req.Header.Set("Cookie", cookieHeader)
 					assert.Equal(t, expected.SecureCookie, strings.Contains(cookieHeader, "Secure"))
 					assert.Equal(t, expected.HTTPOnlyCookie, strings.Contains(cookieHeader, "HttpOnly"))
+					assert.Equal(t, expected.SameSite != "", strings.Contains(cookieHeader, "SameSite="+expected.SameSite))
 				}
 			}
 		})
@@ -324,6 +331,33 @@ func TestManager_Build(t *testing.T) {
 			},
 			providerName: "provider-1",
 		},
+		{
+			desc:        "Weighted service",
+			serviceName: "canary",
+			configs: map[string]*config.ServiceInfo{
+				"provider-1.canary": {
+					Service: &config.Service{
+						Weighted: &config.WeightedService{
+							Services: []config.WeightedServiceRef{
+								{Name: "v1", Weight: intPtr(3)},
+								{Name: "provider-2.v2"},
+							},
+						},
+					},
+				},
+				"provider-1.v1": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{},
+					},
+				},
+				"provider-2.v2": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{},
+					},
+				},
+			},
+			providerName: "provider-1",
+		},
 	}
 
 	for _, test := range testCases {
@@ -331,7 +365,7 @@ func TestManager_Build(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			manager := NewManager(test.configs, http.DefaultTransport)
+			manager := NewManager(test.configs, NewRoundTripperManager(http.DefaultTransport))
 
 			ctx := context.Background()
 			if len(test.providerName) > 0 {
@@ -344,4 +378,340 @@ func TestManager_Build(t *testing.T) {
 	}
 }
 
-// FIXME Add healthcheck tests
+func TestManager_Build_errors(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		configs     map[string]*config.ServiceInfo
+		expectedErr string
+	}{
+		{
+			desc: "unknown service",
+			configs: map[string]*config.ServiceInfo{
+				"canary": {
+					Service: &config.Service{
+						Weighted: &config.WeightedService{
+							Services: []config.WeightedServiceRef{{Name: "v1"}},
+						},
+					},
+				},
+			},
+			expectedErr: `error building the service v1 of the weighted service canary: the service "v1" does not exist`,
+		},
+		{
+			desc: "recursive service",
+			configs: map[string]*config.ServiceInfo{
+				"canary": {
+					Service: &config.Service{
+						Weighted: &config.WeightedService{
+							Services: []config.WeightedServiceRef{{Name: "split"}},
+						},
+					},
+				},
+				"split": {
+					Service: &config.Service{
+						Weighted: &config.WeightedService{
+							Services: []config.WeightedServiceRef{{Name: "canary"}},
+						},
+					},
+				},
+			},
+			expectedErr: `error building the service split of the weighted service canary: ` +
+				`error building the service canary of the weighted service split: ` +
+				`the service "canary" references itself through canary -> split -> canary`,
+		},
+		{
+			desc: "service with two types",
+			configs: map[string]*config.ServiceInfo{
+				"canary": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{},
+						Weighted: &config.WeightedService{
+							Services: []config.WeightedServiceRef{{Name: "v1"}},
+						},
+					},
+				},
+			},
+			expectedErr: `the service "canary" has more than one type`,
+		},
+		{
+			desc: "unknown servers transport",
+			configs: map[string]*config.ServiceInfo{
+				"canary": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{
+							Servers:          []config.Server{{URL: "http://10.10.10.10:80"}},
+							ServersTransport: "mytransport",
+						},
+					},
+				},
+			},
+			expectedErr: `the servers transport "mytransport" does not exist`,
+		},
+		{
+			desc: "mirroring service with an unknown mirror",
+			configs: map[string]*config.ServiceInfo{
+				"canary": {
+					Service: &config.Service{
+						Mirroring: &config.Mirroring{
+							Service: "v1",
+							Mirrors: []config.MirrorService{{Name: "v2", Percent: 10}},
+						},
+					},
+				},
+				"v1": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{},
+					},
+				},
+			},
+			expectedErr: `error building the mirror v2 of the mirroring service canary: the service "v2" does not exist`,
+		},
+		{
+			desc: "mirroring service mirroring itself",
+			configs: map[string]*config.ServiceInfo{
+				"canary": {
+					Service: &config.Service{
+						Mirroring: &config.Mirroring{
+							Service: "v1",
+							Mirrors: []config.MirrorService{{Name: "canary", Percent: 10}},
+						},
+					},
+				},
+				"v1": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{},
+					},
+				},
+			},
+			expectedErr: `error building the mirror canary of the mirroring service canary: the service "canary" references itself through canary -> canary`,
+		},
+		{
+			desc: "failover service with an unknown fallback service",
+			configs: map[string]*config.ServiceInfo{
+				"canary": {
+					Service: &config.Service{
+						Failover: &config.Failover{Service: "v1", Fallback: "v2"},
+					},
+				},
+				"v1": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{},
+					},
+				},
+			},
+			expectedErr: `error building the fallback service v2 of the failover service canary: the service "v2" does not exist`,
+		},
+		{
+			desc: "failover service falling back on itself",
+			configs: map[string]*config.ServiceInfo{
+				"canary": {
+					Service: &config.Service{
+						Failover: &config.Failover{Service: "v1", Fallback: "canary"},
+					},
+				},
+				"v1": {
+					Service: &config.Service{
+						LoadBalancer: &config.LoadBalancerService{},
+					},
+				},
+			},
+			expectedErr: `error building the fallback service canary of the failover service canary: the service "canary" references itself through canary -> canary`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			manager := NewManager(test.configs, NewRoundTripperManager(http.DefaultTransport))
+
+			_, err := manager.BuildHTTP(context.Background(), "canary", nil)
+			require.EqualError(t, err, test.expectedErr)
+			assert.EqualError(t, test.configs["canary"].Err, test.expectedErr)
+		})
+	}
+}
+
+func TestManager_LaunchHealthCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/health" {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	configs := map[string]*config.ServiceInfo{
+		"serviceName": {
+			Service: &config.Service{
+				LoadBalancer: &config.LoadBalancerService{
+					Servers:     []config.Server{{URL: server.URL}},
+					HealthCheck: &config.HealthCheck{Path: "/health", Interval: "1s", Timeout: "500ms"},
+				},
+			},
+		},
+	}
+
+	manager := NewManager(configs, NewRoundTripperManager(http.DefaultTransport))
+
+	// The service is used by two routers.
+	var handlers []http.Handler
+	for i := 0; i < 2; i++ {
+		handler, err := manager.BuildHTTP(context.Background(), "serviceName", nil)
+		require.NoError(t, err)
+		handlers = append(handlers, handler)
+	}
+
+	manager.LaunchHealthCheck()
+	defer healthcheck.GetHealthCheck().SetBackendsConfiguration(context.Background(), nil)
+
+	// The service whose servers are all down responds with a 503, for all the routers using it.
+	for _, handler := range handlers {
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.com/", nil))
+			if recorder.Code == http.StatusServiceUnavailable {
+				break
+			}
+
+			require.True(t, time.Now().Before(deadline), "the server is not removed from the rotation")
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	assert.Equal(t, map[string]string{server.URL: "DOWN"}, configs["serviceName"].GetAllStatus())
+}
+
+func TestManager_Build_staleServers(t *testing.T) {
+	configs := map[string]*config.ServiceInfo{
+		"serviceName": {
+			Service: &config.Service{
+				LoadBalancer: &config.LoadBalancerService{
+					Servers: []config.Server{
+						{URL: "http://10.0.0.1:80"},
+						{URL: "http://10.0.0.2:80", Stale: true},
+					},
+				},
+			},
+		},
+	}
+
+	manager := NewManager(configs, NewRoundTripperManager(http.DefaultTransport))
+
+	_, err := manager.BuildHTTP(context.Background(), "serviceName", nil)
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"http://10.0.0.1:80": "UP",
+		"http://10.0.0.2:80": "STALE",
+	}
+	assert.Equal(t, expected, configs["serviceName"].GetAllStatus())
+}
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func TestBuildHealthCheckOptions(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		interval         string
+		timeout          string
+		expectedInterval time.Duration
+		expectedTimeout  time.Duration
+	}{
+		{
+			desc:             "defaults",
+			expectedInterval: defaultHealthCheckInterval,
+			expectedTimeout:  defaultHealthCheckTimeout,
+		},
+		{
+			desc:             "duration strings",
+			interval:         "1m",
+			timeout:          "500ms",
+			expectedInterval: time.Minute,
+			expectedTimeout:  500 * time.Millisecond,
+		},
+		{
+			desc:             "numbers of seconds",
+			interval:         "10",
+			timeout:          "3",
+			expectedInterval: 10 * time.Second,
+			expectedTimeout:  3 * time.Second,
+		},
+		{
+			desc:             "invalid durations",
+			interval:         "foobar",
+			timeout:          "10 seconds",
+			expectedInterval: defaultHealthCheckInterval,
+			expectedTimeout:  defaultHealthCheckTimeout,
+		},
+		{
+			desc:             "negative durations",
+			interval:         "-10",
+			timeout:          "-1s",
+			expectedInterval: defaultHealthCheckInterval,
+			expectedTimeout:  defaultHealthCheckTimeout,
+		},
+		{
+			desc:             "timeout greater than the interval",
+			interval:         "2s",
+			timeout:          "3s",
+			expectedInterval: 4 * time.Second,
+			expectedTimeout:  3 * time.Second,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			hc := &config.HealthCheck{
+				Path:     "/health",
+				Interval: test.interval,
+				Timeout:  test.timeout,
+			}
+
+			options := buildHealthCheckOptions(context.Background(), nil, "foobar", hc)
+			require.NotNil(t, options)
+
+			assert.Equal(t, test.expectedInterval, options.Interval)
+			assert.Equal(t, test.expectedTimeout, options.Timeout)
+		})
+	}
+}
+
+func TestManager_BuildServersTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	configs := map[string]*config.ServiceInfo{
+		"file.app": {
+			Service: &config.Service{
+				LoadBalancer: &config.LoadBalancerService{
+					Servers:          []config.Server{{URL: server.URL}},
+					ServersTransport: "insecure",
+				},
+			},
+		},
+	}
+
+	roundTripperManager := NewRoundTripperManager(http.DefaultTransport)
+	roundTripperManager.Update(map[string]*config.ServersTransport{
+		"file.insecure": {InsecureSkipVerify: true},
+	})
+
+	manager := NewManager(configs, roundTripperManager)
+
+	// The servers transport is referenced by its name in the provider of the service.
+	handler, err := manager.BuildHTTP(context.Background(), "file.app", nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.com/", nil))
+	assert.Equal(t, http.StatusTeapot, recorder.Code)
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/containous/traefik/pkg/healthcheck"
+	"github.com/vulcand/oxy/utils"
+)
+
+// nextServer returns the next server of a load-balancer, by weighted round-robin.
+type nextServer interface {
+	NextServer() (*url.URL, error)
+}
+
+// stickyBalancer pins the clients to the servers of a load-balancer with a cookie, holding the URL of their server.
+// A client whose cookie references a server which is no longer in the load-balancer, e.g. removed by its health check,
+// is assigned to the next server, and its cookie is updated.
+type stickyBalancer struct {
+	healthcheck.BalancerHandler
+	roundRobin nextServer
+	fwd        http.Handler
+	cookie     http.Cookie
+}
+
+// newStickyBalancer creates a stickyBalancer, forwarding the requests to the servers of the balancer with fwd.
+func newStickyBalancer(balancer healthcheck.BalancerHandler, roundRobin nextServer, fwd http.Handler, cookieName string, conf *config.Cookie) *stickyBalancer {
+	return &stickyBalancer{
+		BalancerHandler: balancer,
+		roundRobin:      roundRobin,
+		fwd:             fwd,
+		cookie: http.Cookie{
+			Name:     cookieName,
+			Path:     "/",
+			Secure:   conf.Secure,
+			HttpOnly: conf.HTTPOnly,
+			SameSite: convertSameSite(conf.SameSite),
+		},
+	}
+}
+
+func (s *stickyBalancer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	server, ok := s.stuckServer(req)
+	if !ok {
+		var err error
+		server, err = s.roundRobin.NextServer()
+		if err != nil {
+			utils.DefaultHandler.ServeHTTP(rw, req, err)
+			return
+		}
+
+		cookie := s.cookie
+		cookie.Value = server.String()
+		http.SetCookie(rw, &cookie)
+	}
+
+	// The request is copied, so that the server is only set for the forwarder.
+	newReq := *req
+	newReq.URL = server
+	s.fwd.ServeHTTP(rw, &newReq)
+}
+
+// stuckServer returns the server referenced by the cookie of the request, if it is still a server of the load-balancer.
+func (s *stickyBalancer) stuckServer(req *http.Request) (*url.URL, bool) {
+	cookie, err := req.Cookie(s.cookie.Name)
+	if err != nil {
+		return nil, false
+	}
+
+	cookieURL, err := url.Parse(cookie.Value)
+	if err != nil {
+		return nil, false
+	}
+
+	for _, server := range s.Servers() {
+		if server.Scheme == cookieURL.Scheme && server.Host == cookieURL.Host && server.Path == cookieURL.Path {
+			return server, true
+		}
+	}
+
+	return nil, false
+}
+
+func convertSameSite(sameSite string) http.SameSite {
+	switch strings.ToLower(sameSite) {
+	case "none":
+		return http.SameSiteNoneMode
+	case "lax":
+		return http.SameSiteLaxMode
+	case "strict":
+		return http.SameSiteStrictMode
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/containous/traefik/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStickyBalancer_serverRemoved(t *testing.T) {
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("X-From", name)
+		}))
+	}
+
+	first := newServer("first")
+	defer first.Close()
+	second := newServer("second")
+	defer second.Close()
+
+	manager := NewManager(nil, NewRoundTripperManager(http.DefaultTransport))
+
+	handler, err := manager.getLoadBalancerServiceHandler(context.Background(), "test", &config.LoadBalancerService{
+		Sticky: &config.Sticky{Cookie: &config.Cookie{Name: "sticky"}},
+		Servers: []config.Server{
+			{URL: first.URL},
+			{URL: second.URL},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	send := func(cookie *http.Cookie) (string, *http.Cookie) {
+		req := httptest.NewRequest(http.MethodGet, "http://foo.com/", nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		cookies := recorder.Result().Cookies()
+		if len(cookies) == 0 {
+			return recorder.Header().Get("X-From"), nil
+		}
+		return recorder.Header().Get("X-From"), cookies[0]
+	}
+
+	from, cookie := send(nil)
+	require.NotNil(t, cookie)
+	assert.Equal(t, "sticky", cookie.Name)
+	assert.Equal(t, first.URL, cookie.Value)
+	assert.Equal(t, "first", from)
+
+	// The client is pinned to its server, without a new cookie.
+	from, newCookie := send(cookie)
+	assert.Equal(t, "first", from)
+	assert.Nil(t, newCookie)
+
+	// The server of the client disappears, e.g. removed by the health check.
+	firstURL, err := url.Parse(first.URL)
+	require.NoError(t, err)
+	require.NoError(t, manager.balancers["test"][0].RemoveServer(firstURL))
+
+	// The client is pinned to another server.
+	from, cookie = send(cookie)
+	require.NotNil(t, cookie)
+	assert.Equal(t, second.URL, cookie.Value)
+	assert.Equal(t, "second", from)
+
+	// The client stays on its new server when the former one comes back.
+	require.NoError(t, manager.balancers["test"][0].UpsertServer(firstURL))
+
+	from, newCookie = send(cookie)
+	assert.Equal(t, "second", from)
+	assert.Nil(t, newCookie)
+}
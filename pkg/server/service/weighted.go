@@ -0,0 +1,80 @@
+package service
+
+import (
+	"net/http"
+	"sync"
+)
+
+type weightedHandler struct {
+	handler http.Handler
+	weight  int
+	current int
+}
+
+// weightedBalancer is a smooth weighted round-robin between the handlers of the services of a weighted service:
+// the requests are spread over the handlers, in proportion to their weights,
+// without sending a burst of requests to the handler with the highest weight.
+type weightedBalancer struct {
+	mu       sync.Mutex
+	handlers []*weightedHandler
+}
+
+// newWeightedBalancer creates a weightedBalancer, without any handler.
+func newWeightedBalancer() *weightedBalancer {
+	return &weightedBalancer{}
+}
+
+// add adds the handler of a service. A handler with a zero weight does not receive any request.
+func (b *weightedBalancer) add(handler http.Handler, weight int) {
+	if weight <= 0 {
+		return
+	}
+
+	b.handlers = append(b.handlers, &weightedHandler{handler: handler, weight: weight})
+}
+
+func (b *weightedBalancer) next() *weightedHandler {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var total int
+	var best *weightedHandler
+	for _, h := range b.handlers {
+		h.current += h.weight
+		total += h.weight
+
+		if best == nil || h.current > best.current {
+			best = h
+		}
+	}
+
+	if best != nil {
+		best.current -= total
+	}
+
+	return best
+}
+
+// healthy tells whether one of the handlers receiving requests is healthy.
+func (b *weightedBalancer) healthy() bool {
+	for _, h := range b.handlers {
+		if isHealthy(h.handler) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *weightedBalancer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	h := b.next()
+	if h == nil {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		_, err := rw.Write([]byte(http.StatusText(http.StatusServiceUnavailable)))
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.handler.ServeHTTP(rw, req)
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeightedBalancer(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		weights  map[string]int
+		requests int
+		expected map[string]int
+		status   int
+	}{
+		{
+			desc:     "same weights",
+			weights:  map[string]int{"a": 1, "b": 1},
+			requests: 4,
+			expected: map[string]int{"a": 2, "b": 2},
+			status:   http.StatusOK,
+		},
+		{
+			desc:     "different weights",
+			weights:  map[string]int{"a": 3, "b": 1},
+			requests: 8,
+			expected: map[string]int{"a": 6, "b": 2},
+			status:   http.StatusOK,
+		},
+		{
+			desc:     "zero weight",
+			weights:  map[string]int{"a": 1, "b": 0},
+			requests: 3,
+			expected: map[string]int{"a": 3},
+			status:   http.StatusOK,
+		},
+		{
+			desc:     "only zero weights",
+			weights:  map[string]int{"a": 0},
+			requests: 1,
+			expected: map[string]int{},
+			status:   http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			counts := make(map[string]int)
+
+			balancer := newWeightedBalancer()
+			for name, weight := range test.weights {
+				name := name
+				balancer.add(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { counts[name]++ }), weight)
+			}
+
+			for i := 0; i < test.requests; i++ {
+				recorder := httptest.NewRecorder()
+				balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+				assert.Equal(t, test.status, recorder.Code)
+			}
+
+			assert.Equal(t, test.expected, counts)
+		})
+	}
+}
+
+func TestWeightedBalancer_smooth(t *testing.T) {
+	var sequence []string
+
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(http.ResponseWriter, *http.Request) { sequence = append(sequence, name) })
+	}
+
+	balancer := newWeightedBalancer()
+	balancer.add(handler("a"), 2)
+	balancer.add(handler("b"), 1)
+
+	for i := 0; i < 6; i++ {
+		balancer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.Equal(t, []string{"a", "b", "a", "a", "b", "a"}, sequence)
+}
@@ -10,24 +10,27 @@ import (
 	"strings"
 
 	"github.com/containous/traefik/pkg/log"
+	"github.com/containous/traefik/pkg/types"
 )
 
 // Router is a TCP router
 type Router struct {
-	routingTable   map[string]Handler
-	httpForwarder  Handler
-	httpsForwarder Handler
-	httpHandler    http.Handler
-	httpsHandler   http.Handler
-	httpsTLSConfig *tls.Config
-	catchAllNoTLS  Handler
+	routingTable      map[string]Handler
+	hostHTTPTLSConfig map[string]*tls.Config
+	httpForwarder     Handler
+	httpsForwarder    Handler
+	httpHandler       http.Handler
+	httpsHandler      http.Handler
+	httpsTLSConfig    *tls.Config
+	catchAllNoTLS     Handler
+	catchAllTLS       Handler
 }
 
 // ServeTCP forwards the connection to the right TCP/HTTP handler
 func (r *Router) ServeTCP(conn net.Conn) {
 	// FIXME -- Check if ProxyProtocol changes the first bytes of the request
 
-	if r.catchAllNoTLS != nil && len(r.routingTable) == 0 && r.httpsHandler == nil {
+	if r.catchAllNoTLS != nil && len(r.routingTable) == 0 && r.httpsHandler == nil && r.catchAllTLS == nil {
 		r.catchAllNoTLS.ServeTCP(conn)
 		return
 	}
@@ -47,9 +50,9 @@ func (r *Router) ServeTCP(conn net.Conn) {
 	}
 
 	// FIXME Optimize and test the routing table before helloServerName
-	serverName = strings.ToLower(serverName)
+	serverName = types.CanonicalDomain(serverName)
 	if r.routingTable != nil && serverName != "" {
-		if target, ok := r.routingTable[serverName]; ok {
+		if target, ok := r.getRoute(serverName); ok {
 			target.ServeTCP(r.GetConn(conn, peeked))
 			return
 		}
@@ -61,6 +64,11 @@ func (r *Router) ServeTCP(conn net.Conn) {
 		return
 	}
 
+	if r.catchAllTLS != nil {
+		r.catchAllTLS.ServeTCP(r.GetConn(conn, peeked))
+		return
+	}
+
 	if r.httpsForwarder != nil {
 		r.httpsForwarder.ServeTCP(r.GetConn(conn, peeked))
 	} else {
@@ -68,12 +76,31 @@ func (r *Router) ServeTCP(conn net.Conn) {
 	}
 }
 
-// AddRoute defines a handler for a given sniHost (* is the only valid option)
+// getRoute returns the handler of the server name: the handler of the server name itself if any,
+// otherwise the handler of its most specific wildcard, e.g. *.b.example.com before *.example.com.
+func (r *Router) getRoute(serverName string) (Handler, bool) {
+	if target, ok := r.routingTable[serverName]; ok {
+		return target, true
+	}
+
+	labels := strings.Split(serverName, ".")
+	for i := 0; i < len(labels)-1; i++ {
+		labels[i] = "*"
+		if target, ok := r.routingTable[strings.Join(labels, ".")]; ok {
+			return target, true
+		}
+	}
+
+	return nil, false
+}
+
+// AddRoute defines a handler for a given sniHost: a domain, which can be a wildcard domain such as *.example.com,
+// or * for all the connections.
 func (r *Router) AddRoute(sniHost string, target Handler) {
 	if r.routingTable == nil {
 		r.routingTable = map[string]Handler{}
 	}
-	r.routingTable[strings.ToLower(sniHost)] = target
+	r.routingTable[types.CanonicalDomain(sniHost)] = target
 }
 
 // AddRouteTLS defines a handler for a given sniHost and sets the matching tlsConfig
@@ -84,11 +111,25 @@ func (r *Router) AddRouteTLS(sniHost string, target Handler, config *tls.Config)
 	})
 }
 
+// AddRouteHTTPTLS defines the tlsConfig of the HTTPS connections for a given sniHost,
+// instead of the tlsConfig of the HTTPS handler.
+func (r *Router) AddRouteHTTPTLS(sniHost string, config *tls.Config) {
+	if r.hostHTTPTLSConfig == nil {
+		r.hostHTTPTLSConfig = map[string]*tls.Config{}
+	}
+	r.hostHTTPTLSConfig[types.CanonicalDomain(sniHost)] = config
+}
+
 // AddCatchAllNoTLS defines the fallback tcp handler
 func (r *Router) AddCatchAllNoTLS(handler Handler) {
 	r.catchAllNoTLS = handler
 }
 
+// AddCatchAllTLS defines the fallback tcp handler of the TLS connections, whose server name matches no route.
+func (r *Router) AddCatchAllTLS(handler Handler) {
+	r.catchAllTLS = handler
+}
+
 // GetConn creates a connection proxy with a peeked string
 func (r *Router) GetConn(conn net.Conn, peeked string) net.Conn {
 	// FIXME should it really be on Router ?
@@ -114,8 +155,16 @@ func (r *Router) HTTPForwarder(handler Handler) {
 	r.httpForwarder = handler
 }
 
-// HTTPSForwarder sets the tcp handler that will forward the TLS connections to an http handler
+// HTTPSForwarder sets the tcp handler that will forward the TLS connections to an http handler,
+// terminating them with the tlsConfig of their sniHost if any, the routes of the TCP routers taking precedence.
 func (r *Router) HTTPSForwarder(handler Handler) {
+	for sniHost, config := range r.hostHTTPTLSConfig {
+		if _, ok := r.routingTable[sniHost]; ok {
+			continue
+		}
+		r.AddRouteTLS(sniHost, handler, config)
+	}
+
 	r.httpsForwarder = &TLSHandler{
 		Next:   handler,
 		Config: r.httpsTLSConfig,
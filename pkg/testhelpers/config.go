@@ -140,8 +140,8 @@ func WithServer(url string, opts ...func(*config.Server)) func(*config.Server) {
 // WithStickiness is a helper to create a configuration.
 func WithStickiness(cookieName string) func(*config.LoadBalancerService) {
 	return func(b *config.LoadBalancerService) {
-		b.Stickiness = &config.Stickiness{
-			CookieName: cookieName,
+		b.Sticky = &config.Sticky{
+			Cookie: &config.Cookie{Name: cookieName},
 		}
 	}
 }
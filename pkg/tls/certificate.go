@@ -52,13 +52,50 @@ var (
 		"TLS_CHACHA20_POLY1305_SHA256":            tls.TLS_CHACHA20_POLY1305_SHA256,
 		"TLS_FALLBACK_SCSV":                       tls.TLS_FALLBACK_SCSV,
 	}
+
+	// Curves Map of the elliptic curves from crypto/tls
+	// Available Curves defined at https://golang.org/pkg/crypto/tls/#CurveID
+	Curves = map[string]tls.CurveID{
+		`secp256r1`: tls.CurveP256,
+		`CurveP256`: tls.CurveP256,
+		`secp384r1`: tls.CurveP384,
+		`CurveP384`: tls.CurveP384,
+		`secp521r1`: tls.CurveP521,
+		`CurveP521`: tls.CurveP521,
+		`X25519`:    tls.X25519,
+	}
 )
 
 // Certificate holds a SSL cert/key pair
 // Certs and Key could be either a file path, or the file content itself
+// CertFileContent and KeyFileContent hold the PEM content inline, instead of CertFile and KeyFile
 type Certificate struct {
-	CertFile FileOrContent
-	KeyFile  FileOrContent
+	CertFile        FileOrContent
+	KeyFile         FileOrContent
+	CertFileContent string
+	KeyFileContent  string
+}
+
+// ReadCert returns the PEM content of the certificate, either inline or read from CertFile.
+func (c *Certificate) ReadCert() ([]byte, error) {
+	return readFileOrInline("cert", c.CertFile, c.CertFileContent)
+}
+
+// ReadKey returns the PEM content of the key, either inline or read from KeyFile.
+func (c *Certificate) ReadKey() ([]byte, error) {
+	return readFileOrInline("key", c.KeyFile, c.KeyFileContent)
+}
+
+func readFileOrInline(name string, file FileOrContent, content string) ([]byte, error) {
+	if content == "" {
+		return file.Read()
+	}
+
+	if file != "" {
+		return nil, fmt.Errorf("both the %[1]s file and the %[1]s file content are set", name)
+	}
+
+	return []byte(content), nil
 }
 
 // Certificates defines traefik certificates type
@@ -141,12 +178,12 @@ func (c *Certificates) isEmpty() bool {
 // AppendCertificate appends a Certificate to a certificates map keyed by entrypoint.
 func (c *Certificate) AppendCertificate(certs map[string]map[string]*tls.Certificate, ep string) error {
 
-	certContent, err := c.CertFile.Read()
+	certContent, err := c.ReadCert()
 	if err != nil {
 		return fmt.Errorf("unable to read CertFile : %v", err)
 	}
 
-	keyContent, err := c.KeyFile.Read()
+	keyContent, err := c.ReadKey()
 	if err != nil {
 		return fmt.Errorf("unable to read KeyFile : %v", err)
 	}
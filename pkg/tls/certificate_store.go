@@ -4,12 +4,12 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"net"
-	"sort"
 	"strings"
 	"time"
 
 	"github.com/containous/traefik/pkg/log"
 	"github.com/containous/traefik/pkg/safe"
+	"github.com/containous/traefik/pkg/types"
 	"github.com/patrickmn/go-cache"
 )
 
@@ -68,9 +68,11 @@ func (c CertificateStore) GetAllDomains() []string {
 	return allCerts
 }
 
-// GetBestCertificate returns the best match certificate, and caches the response
+// GetBestCertificate returns the best match certificate, and caches the response.
+// An exact domain takes precedence over the wildcards, and the most specific wildcard over the others,
+// the ties being broken by the domains of the certificates, so that the choice does not depend on the order of the certificates.
 func (c CertificateStore) GetBestCertificate(clientHello *tls.ClientHelloInfo) *tls.Certificate {
-	domainToCheck := strings.ToLower(strings.TrimSpace(clientHello.ServerName))
+	domainToCheck := types.CanonicalDomain(clientHello.ServerName)
 	if len(domainToCheck) == 0 {
 		// If no ServerName is provided, Check for local IP address matches
 		host, _, err := net.SplitHostPort(clientHello.Conn.LocalAddr().String())
@@ -84,31 +86,32 @@ func (c CertificateStore) GetBestCertificate(clientHello *tls.ClientHelloInfo) *
 		return cert.(*tls.Certificate)
 	}
 
-	matchedCerts := map[string]*tls.Certificate{}
+	var bestCert *tls.Certificate
+	bestPrecedence := -1
+	bestDomains := ""
 	if c.DynamicCerts != nil && c.DynamicCerts.Get() != nil {
 		for domains, cert := range c.DynamicCerts.Get().(map[string]*tls.Certificate) {
 			for _, certDomain := range strings.Split(domains, ",") {
-				if MatchDomain(domainToCheck, certDomain) {
-					matchedCerts[certDomain] = cert
+				if !types.MatchDomain(domainToCheck, certDomain) {
+					continue
+				}
+
+				precedence := types.DomainPrecedence(certDomain)
+				if precedence > bestPrecedence || (precedence == bestPrecedence && domains < bestDomains) {
+					bestCert = cert
+					bestPrecedence = precedence
+					bestDomains = domains
 				}
 			}
 		}
 	}
 
-	if len(matchedCerts) > 0 {
-		// sort map by keys
-		keys := make([]string, 0, len(matchedCerts))
-		for k := range matchedCerts {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
-
+	if bestCert != nil {
 		// cache best match
-		c.CertCache.SetDefault(domainToCheck, matchedCerts[keys[len(keys)-1]])
-		return matchedCerts[keys[len(keys)-1]]
+		c.CertCache.SetDefault(domainToCheck, bestCert)
 	}
 
-	return nil
+	return bestCert
 }
 
 // ResetCache clears the cache in the store
@@ -120,21 +123,5 @@ func (c CertificateStore) ResetCache() {
 
 // MatchDomain return true if a domain match the cert domain
 func MatchDomain(domain string, certDomain string) bool {
-	if domain == certDomain {
-		return true
-	}
-
-	for len(certDomain) > 0 && certDomain[len(certDomain)-1] == '.' {
-		certDomain = certDomain[:len(certDomain)-1]
-	}
-
-	labels := strings.Split(domain, ".")
-	for i := range labels {
-		labels[i] = "*"
-		candidate := strings.Join(labels, ".")
-		if certDomain == candidate {
-			return true
-		}
-	}
-	return false
+	return types.MatchDomain(domain, certDomain)
 }
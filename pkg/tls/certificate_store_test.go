@@ -89,6 +89,60 @@ func TestGetBestCertificate(t *testing.T) {
 	}
 }
 
+func TestGetBestCertificate_precedence(t *testing.T) {
+	dynamicMap := map[string]*tls.Certificate{}
+	for _, certName := range []string{"*.snitest.com", "www.snitest.com", "*.www.snitest.com"} {
+		cert, err := loadTestCert(certName, false)
+		require.NoError(t, err)
+		dynamicMap[certName] = cert
+	}
+
+	testCases := []struct {
+		desc          string
+		domainToCheck string
+		expectedCert  string
+	}{
+		{
+			desc:          "exact domain over wildcard",
+			domainToCheck: "www.snitest.com",
+			expectedCert:  "www.snitest.com",
+		},
+		{
+			desc:          "exact domain, case insensitive, with a trailing period",
+			domainToCheck: "WWW.snitest.com.",
+			expectedCert:  "www.snitest.com",
+		},
+		{
+			desc:          "wildcard",
+			domainToCheck: "foo.snitest.com",
+			expectedCert:  "*.snitest.com",
+		},
+		{
+			desc:          "longest wildcard",
+			domainToCheck: "foo.www.snitest.com",
+			expectedCert:  "*.www.snitest.com",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			// The certificates are looked up in a map, so the lookup is repeated to check that its result does not depend on the map order.
+			for i := 0; i < 20; i++ {
+				store := &CertificateStore{
+					DynamicCerts: safe.New(dynamicMap),
+					CertCache:    cache.New(1*time.Hour, 10*time.Minute),
+				}
+
+				actual := store.GetBestCertificate(&tls.ClientHelloInfo{ServerName: test.domainToCheck})
+				assert.Equal(t, dynamicMap[test.expectedCert], actual)
+			}
+		})
+	}
+}
+
 func loadTestCert(certName string, uppercase bool) (*tls.Certificate, error) {
 	replacement := "wildcard"
 	if uppercase {
@@ -11,10 +11,11 @@ type ClientCA struct {
 
 // TLS configures TLS for an entry point
 type TLS struct {
-	MinVersion   string `export:"true"`
-	CipherSuites []string
-	ClientCA     ClientCA
-	SniStrict    bool `export:"true"`
+	MinVersion       string `export:"true"`
+	CipherSuites     []string
+	CurvePreferences []string
+	ClientCA         ClientCA
+	SniStrict        bool `export:"true"`
 }
 
 // Store holds the options for a given Store
@@ -13,6 +13,9 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// DefaultTLSConfigName is the name of the default TLS options, used by the routers which do not reference any.
+const DefaultTLSConfigName = "default"
+
 // Manager is the TLS option/store/configuration factory
 type Manager struct {
 	storesConfig  map[string]Store
@@ -25,7 +28,9 @@ type Manager struct {
 
 // NewManager creates a new Manager
 func NewManager() *Manager {
-	return &Manager{}
+	return &Manager{
+		stores: make(map[string]*CertificateStore),
+	}
 }
 
 // UpdateConfigs updates the TLS* configuration options
@@ -67,14 +72,21 @@ func (m *Manager) UpdateConfigs(stores map[string]Store, configs map[string]TLS,
 	}
 }
 
-// Get gets the tls configuration to use for a given store / configuration
-func (m *Manager) Get(storeName string, configName string) *tls.Config {
+// Get gets the tls configuration to use for a given store / configuration.
+// The default configuration is always defined, even when it is not configured,
+// whereas an unknown configuration is an error.
+func (m *Manager) Get(storeName string, configName string) (*tls.Config, error) {
 	m.lock.RLock()
 	defer m.lock.RUnlock()
 
 	store := m.getStore(storeName)
 
-	tlsConfig, err := buildTLSConfig(m.configs[configName])
+	config, ok := m.configs[configName]
+	if !ok && configName != DefaultTLSConfigName {
+		return nil, fmt.Errorf("unknown TLS options: %s", configName)
+	}
+
+	tlsConfig, err := buildTLSConfig(config)
 	if err != nil {
 		log.Error(err)
 		tlsConfig = &tls.Config{}
@@ -99,14 +111,14 @@ func (m *Manager) Get(storeName string, configName string) *tls.Config {
 			return bestCertificate, nil
 		}
 
-		if m.configs[configName].SniStrict {
+		if config.SniStrict {
 			return nil, fmt.Errorf("strict SNI enabled - No certificate found for domain: %q, closing connection", domainToCheck)
 		}
 
 		log.WithoutContext().Debugf("Serving default certificate for request: %q", domainToCheck)
 		return store.DefaultCertificate, nil
 	}
-	return tlsConfig
+	return tlsConfig, nil
 }
 
 func (m *Manager) getStore(storeName string) *CertificateStore {
@@ -193,16 +205,28 @@ func buildTLSConfig(tlsOption TLS) (*tls.Config, error) {
 		}
 	}
 
+	// Set the list of CurvePreferences if set in the config TOML
+	if tlsOption.CurvePreferences != nil {
+		conf.CurvePreferences = make([]tls.CurveID, 0)
+		for _, curve := range tlsOption.CurvePreferences {
+			if curveID, exists := Curves[curve]; exists {
+				conf.CurvePreferences = append(conf.CurvePreferences, curveID)
+			} else {
+				return nil, fmt.Errorf("invalid CurvePreferences: %s", curve)
+			}
+		}
+	}
+
 	return conf, nil
 }
 
 func buildDefaultCertificate(defaultCertificate *Certificate) (*tls.Certificate, error) {
-	certFile, err := defaultCertificate.CertFile.Read()
+	certFile, err := defaultCertificate.ReadCert()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cert file content: %v", err)
 	}
 
-	keyFile, err := defaultCertificate.KeyFile.Read()
+	keyFile, err := defaultCertificate.ReadKey()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get key file content: %v", err)
 	}
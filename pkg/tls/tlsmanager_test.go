@@ -62,3 +62,81 @@ func TestTLSInStore(t *testing.T) {
 		t.Fatal("got error: default store must have TLS certificates.")
 	}
 }
+
+func TestTLSInStoreInlineContent(t *testing.T) {
+	dynamicConfigs :=
+		[]*Configuration{
+			{
+				Certificate: &Certificate{
+					CertFileContent: localhostCert.String(),
+					KeyFileContent:  localhostKey.String(),
+				},
+			},
+		}
+
+	stores := map[string]Store{
+		"default": {
+			DefaultCertificate: &Certificate{
+				CertFileContent: localhostCert.String(),
+				KeyFileContent:  localhostKey.String(),
+			},
+		},
+	}
+
+	tlsManager := NewManager()
+	tlsManager.UpdateConfigs(stores, nil, dynamicConfigs)
+
+	store := tlsManager.GetStore("default")
+
+	certs := store.DynamicCerts.Get().(map[string]*tls.Certificate)
+	if len(certs) == 0 {
+		t.Fatal("got error: default store must have TLS certificates.")
+	}
+
+	if store.DefaultCertificate == nil {
+		t.Fatal("got error: default store must have a default certificate.")
+	}
+}
+
+func TestCertificateReadBothFileAndContent(t *testing.T) {
+	certificate := &Certificate{
+		CertFile:        localhostCert,
+		CertFileContent: localhostCert.String(),
+		KeyFileContent:  localhostKey.String(),
+	}
+
+	if _, err := certificate.ReadCert(); err == nil {
+		t.Fatal("expected an error when both the cert file and the cert file content are set")
+	}
+
+	key, err := certificate.ReadKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(key) != localhostKey.String() {
+		t.Fatal("got an unexpected key content")
+	}
+}
+
+func TestManagerGet(t *testing.T) {
+	tlsManager := NewManager()
+	tlsManager.UpdateConfigs(nil, map[string]TLS{
+		"file.curves": {CurvePreferences: []string{"CurveP521", "X25519"}},
+	}, nil)
+
+	if _, err := tlsManager.Get("default", "default"); err != nil {
+		t.Fatalf("the default TLS options must be defined even when they are not configured: %v", err)
+	}
+
+	config, err := tlsManager.Get("default", "file.curves")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.CurvePreferences) != 2 || config.CurvePreferences[0] != tls.CurveP521 || config.CurvePreferences[1] != tls.X25519 {
+		t.Fatalf("got unexpected curve preferences: %v", config.CurvePreferences)
+	}
+
+	if _, err := tlsManager.Get("default", "file.unknown"); err == nil {
+		t.Fatal("expected an error for unknown TLS options")
+	}
+}
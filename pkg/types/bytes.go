@@ -0,0 +1,70 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// byteUnits are the multipliers of the suffixes of the byte quantities:
+// the SI ones, powers of 1000, and the binary ones, powers of 1024, each one with or without a trailing B.
+var byteUnits = map[string]int64{
+	"":   1,
+	"B":  1,
+	"k":  1e3,
+	"kB": 1e3,
+	"K":  1e3,
+	"KB": 1e3,
+	"M":  1e6,
+	"MB": 1e6,
+	"G":  1e9,
+	"GB": 1e9,
+	"T":  1e12,
+	"TB": 1e12,
+	"P":  1e15,
+	"PB": 1e15,
+	"E":  1e18,
+	"EB": 1e18,
+
+	"Ki":  1 << 10,
+	"KiB": 1 << 10,
+	"Mi":  1 << 20,
+	"MiB": 1 << 20,
+	"Gi":  1 << 30,
+	"GiB": 1 << 30,
+	"Ti":  1 << 40,
+	"TiB": 1 << 40,
+	"Pi":  1 << 50,
+	"PiB": 1 << 50,
+	"Ei":  1 << 60,
+	"EiB": 1 << 60,
+}
+
+// ParseByteQuantity parses a byte quantity, which is a number of bytes, e.g. 1048576,
+// optionally followed by a SI suffix, e.g. 10kB or 10MB, or by a binary suffix, e.g. 10KiB or 10Mi.
+// It is shared by all the configuration sources (labels, files, flags, and environment variables),
+// so that a byte quantity is written, and rejected, the same way whatever its source.
+// A negative quantity, or a quantity which does not fit in an int64, is an error.
+func ParseByteQuantity(value string) (int64, error) {
+	number := strings.TrimRightFunc(value, func(r rune) bool { return r < '0' || r > '9' })
+
+	multiplier, ok := byteUnits[strings.TrimSpace(value[len(number):])]
+	if !ok || number == "" {
+		return 0, fmt.Errorf("invalid byte quantity %q: a number of bytes, such as 1024, 10MB or 10MiB, is expected", value)
+	}
+
+	if strings.HasPrefix(number, "-") {
+		return 0, fmt.Errorf("invalid byte quantity %q: a byte quantity cannot be negative", value)
+	}
+
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n > math.MaxInt64/multiplier {
+		if err == nil || err.(*strconv.NumError).Err == strconv.ErrRange {
+			return 0, fmt.Errorf("invalid byte quantity %q: it overflows the maximum of %d bytes", value, int64(math.MaxInt64))
+		}
+		return 0, fmt.Errorf("invalid byte quantity %q: a number of bytes, such as 1024, 10MB or 10MiB, is expected", value)
+	}
+
+	return n * multiplier, nil
+}
@@ -0,0 +1,109 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteQuantity(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		value         string
+		expected      int64
+		expectedError string
+	}{
+		{
+			desc:     "number of bytes",
+			value:    "1048576",
+			expected: 1048576,
+		},
+		{
+			desc:     "bytes suffix",
+			value:    "512B",
+			expected: 512,
+		},
+		{
+			desc:     "SI suffix",
+			value:    "10kB",
+			expected: 10000,
+		},
+		{
+			desc:     "SI suffix without B",
+			value:    "2M",
+			expected: 2000000,
+		},
+		{
+			desc:     "binary suffix",
+			value:    "10KiB",
+			expected: 10240,
+		},
+		{
+			desc:     "binary suffix without B",
+			value:    "10Mi",
+			expected: 10485760,
+		},
+		{
+			desc:     "space before the suffix",
+			value:    "1 GiB",
+			expected: 1073741824,
+		},
+		{
+			desc:     "maximum",
+			value:    "7EiB",
+			expected: 7 << 60,
+		},
+		{
+			desc:     "zero",
+			value:    "0",
+			expected: 0,
+		},
+		{
+			desc:          "empty",
+			value:         "",
+			expectedError: `invalid byte quantity "": a number of bytes, such as 1024, 10MB or 10MiB, is expected`,
+		},
+		{
+			desc:          "unknown suffix",
+			value:         "10mb",
+			expectedError: `invalid byte quantity "10mb": a number of bytes, such as 1024, 10MB or 10MiB, is expected`,
+		},
+		{
+			desc:          "fractional number",
+			value:         "1.5MiB",
+			expectedError: `invalid byte quantity "1.5MiB": a number of bytes, such as 1024, 10MB or 10MiB, is expected`,
+		},
+		{
+			desc:          "negative",
+			value:         "-10MB",
+			expectedError: `invalid byte quantity "-10MB": a byte quantity cannot be negative`,
+		},
+		{
+			desc:          "overflow of the suffix",
+			value:         "8EiB",
+			expectedError: `invalid byte quantity "8EiB": it overflows the maximum of 9223372036854775807 bytes`,
+		},
+		{
+			desc:          "overflow of the number",
+			value:         "9223372036854775808",
+			expectedError: `invalid byte quantity "9223372036854775808": it overflows the maximum of 9223372036854775807 bytes`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			quantity, err := ParseByteQuantity(test.value)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, quantity)
+		})
+	}
+}
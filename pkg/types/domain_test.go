@@ -168,6 +168,24 @@ func TestMatchDomain(t *testing.T) {
 			domain:     "*.*.traefik.wtf",
 			expected:   false,
 		},
+		{
+			desc:       "case insensitive",
+			certDomain: "*.Traefik.wtf",
+			domain:     "SUB.traefik.WTF",
+			expected:   true,
+		},
+		{
+			desc:       "trailing periods",
+			certDomain: "traefik.wtf.",
+			domain:     "traefik.wtf.",
+			expected:   true,
+		},
+		{
+			desc:       "domain with a trailing period",
+			certDomain: "*.traefik.wtf",
+			domain:     "sub.traefik.wtf.",
+			expected:   true,
+		},
 	}
 
 	for _, test := range testCases {
@@ -180,3 +198,36 @@ func TestMatchDomain(t *testing.T) {
 		})
 	}
 }
+
+func TestDomainPrecedence(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		higherDomain string
+		lowerDomain  string
+	}{
+		{
+			desc:         "exact domain over wildcard",
+			higherDomain: "sub.traefik.wtf",
+			lowerDomain:  "*.traefik.wtf",
+		},
+		{
+			desc:         "longest wildcard over shortest wildcard",
+			higherDomain: "*.sub.traefik.wtf",
+			lowerDomain:  "*.*.traefik.wtf",
+		},
+		{
+			desc:         "exact domain with a trailing period over wildcard",
+			higherDomain: "Sub.Traefik.wtf.",
+			lowerDomain:  "*.traefik.wtf",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.True(t, DomainPrecedence(test.higherDomain) > DomainPrecedence(test.lowerDomain))
+		})
+	}
+}
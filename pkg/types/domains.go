@@ -67,15 +67,15 @@ func (ds *Domains) SetValue(val interface{}) {
 }
 
 // MatchDomain returns true if a domain match the cert domain.
+// They are compared case-insensitively, without their trailing periods.
 func MatchDomain(domain string, certDomain string) bool {
+	domain = CanonicalDomain(domain)
+	certDomain = CanonicalDomain(certDomain)
+
 	if domain == certDomain {
 		return true
 	}
 
-	for len(certDomain) > 0 && certDomain[len(certDomain)-1] == '.' {
-		certDomain = certDomain[:len(certDomain)-1]
-	}
-
 	labels := strings.Split(domain, ".")
 	for i := range labels {
 		labels[i] = "*"
@@ -87,7 +87,20 @@ func MatchDomain(domain string, certDomain string) bool {
 	return false
 }
 
-// CanonicalDomain returns a lower case domain with trim space.
+// CanonicalDomain returns a lower case domain with trim space, and without its trailing periods.
 func CanonicalDomain(domain string) string {
-	return strings.ToLower(strings.TrimSpace(domain))
+	return strings.TrimRight(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+// DomainPrecedence returns the precedence of a cert domain among the others matching a same domain,
+// which is its number of labels that are not wildcards: an exact domain takes precedence over the wildcards,
+// and a wildcard over the wildcards with more wildcard labels, e.g. a.example.com over *.example.com, and *.b.example.com over *.*.example.com.
+func DomainPrecedence(certDomain string) int {
+	precedence := 0
+	for _, label := range strings.Split(CanonicalDomain(certDomain), ".") {
+		if label != "*" {
+			precedence++
+		}
+	}
+	return precedence
 }
@@ -2,6 +2,7 @@ package types
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
 	"time"
 )
@@ -11,16 +12,32 @@ import (
 // the latter case, seconds are assumed.
 type Duration time.Duration
 
+// ParseDuration parses a duration value, which is either a `time.ParseDuration`-compatible value, e.g. 1m30s,
+// or suffix-less digits, which are a number of seconds.
+// It is shared by all the configuration sources (labels, files, flags, and environment variables),
+// so that a duration is written, and rejected, the same way whatever its source.
+func ParseDuration(value string) (time.Duration, error) {
+	if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(v) * time.Second, nil
+	}
+
+	v, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: a duration such as 10s or 1m30s, or a number of seconds, is expected", value)
+	}
+
+	return v, nil
+}
+
 // Set sets the duration from the given string value.
 func (d *Duration) Set(s string) error {
-	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
-		*d = Duration(time.Duration(v) * time.Second)
-		return nil
+	v, err := ParseDuration(s)
+	if err != nil {
+		return err
 	}
 
-	v, err := time.ParseDuration(s)
 	*d = Duration(v)
-	return err
+	return nil
 }
 
 // Get returns the duration value.
@@ -63,7 +80,6 @@ func (d *Duration) UnmarshalJSON(text []byte) error {
 	if err != nil {
 		return err
 	}
-	v, err := time.ParseDuration(value)
-	*d = Duration(v)
-	return err
+
+	return d.Set(value)
 }
@@ -0,0 +1,79 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuration(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		value         string
+		expected      time.Duration
+		expectedError string
+	}{
+		{
+			desc:     "duration string",
+			value:    "1m30s",
+			expected: 90 * time.Second,
+		},
+		{
+			desc:     "sub-second duration string",
+			value:    "100ms",
+			expected: 100 * time.Millisecond,
+		},
+		{
+			desc:     "number of seconds",
+			value:    "10",
+			expected: 10 * time.Second,
+		},
+		{
+			desc:     "zero",
+			value:    "0",
+			expected: 0,
+		},
+		{
+			desc:          "empty",
+			value:         "",
+			expectedError: `invalid duration "": a duration such as 10s or 1m30s, or a number of seconds, is expected`,
+		},
+		{
+			desc:          "invalid",
+			value:         "10 seconds",
+			expectedError: `invalid duration "10 seconds": a duration such as 10s or 1m30s, or a number of seconds, is expected`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			duration, err := ParseDuration(test.value)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, duration)
+		})
+	}
+}
+
+func TestDuration_UnmarshalText(t *testing.T) {
+	d := Duration(5 * time.Second)
+
+	require.NoError(t, d.UnmarshalText([]byte("10")))
+	assert.Equal(t, Duration(10*time.Second), d)
+
+	require.NoError(t, d.UnmarshalText([]byte("1m")))
+	assert.Equal(t, Duration(time.Minute), d)
+
+	// An invalid value leaves the duration unchanged.
+	require.Error(t, d.UnmarshalText([]byte("foobar")))
+	assert.Equal(t, Duration(time.Minute), d)
+}
@@ -17,7 +17,7 @@ type ClientTLS struct {
 	CA                 string `description:"TLS CA" json:"ca,omitempty"`
 	CAOptional         bool   `description:"TLS CA.Optional" json:"caOptional,omitempty"`
 	Cert               string `description:"TLS cert" json:"cert,omitempty"`
-	Key                string `description:"TLS key" json:"key,omitempty"`
+	Key                string `description:"TLS key" json:"key,omitempty" export:"redact"`
 	InsecureSkipVerify bool   `description:"TLS insecure skip verify" json:"insecureSkipVerify,omitempty"`
 }
 
@@ -53,8 +53,9 @@ func (clientTLS *ClientTLS) CreateTLSConfig(ctx context.Context) (*tls.Config, e
 		}
 	}
 
-	if !clientTLS.InsecureSkipVerify && (len(clientTLS.Cert) == 0 || len(clientTLS.Key) == 0) {
-		return nil, fmt.Errorf("TLS Certificate or Key file must be set when TLS configuration is created")
+	// The client certificate is optional, e.g. to only verify the server with a CA, but its key must come with it.
+	if (len(clientTLS.Cert) == 0) != (len(clientTLS.Key) == 0) {
+		return nil, fmt.Errorf("TLS Certificate and Key must be set together when TLS configuration is created")
 	}
 
 	cert := tls.Certificate{}
@@ -83,11 +84,18 @@ func (clientTLS *ClientTLS) CreateTLSConfig(ctx context.Context) (*tls.Config, e
 		}
 	}
 
-	TLSConfig := &tls.Config{
-		Certificates:       []tls.Certificate{cert},
-		RootCAs:            caPool,
+	tlsConfig := &tls.Config{
 		InsecureSkipVerify: clientTLS.InsecureSkipVerify,
 		ClientAuth:         clientAuth,
 	}
-	return TLSConfig, nil
+
+	if clientTLS.CA != "" {
+		tlsConfig.RootCAs = caPool
+	}
+
+	if len(clientTLS.Cert) > 0 {
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
 }
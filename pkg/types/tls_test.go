@@ -0,0 +1,173 @@
+package types
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTLSServer(t *testing.T, clientCAs *x509.CertPool) (*httptest.Server, string) {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(rw, "ok")
+	}))
+	if clientCAs != nil {
+		server.TLS = &tls.Config{
+			ClientCAs:  clientCAs,
+			ClientAuth: tls.RequireAndVerifyClientCert,
+		}
+	}
+	server.StartTLS()
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	return server, string(ca)
+}
+
+// newClientCertificate creates a self-signed client certificate, and returns it along with its key, PEM encoded.
+func newClientCertificate(t *testing.T) (string, string, *x509.Certificate) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "traefik"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	require.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	key := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+
+	return string(cert), string(key), certificate
+}
+
+func getWithTLS(t *testing.T, tlsConfig *tls.Config, url string) error {
+	t.Helper()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	return nil
+}
+
+func TestClientTLS_CreateTLSConfig_customCA(t *testing.T) {
+	server, ca := newTLSServer(t, nil)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "traefik-client-tls")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	caFile := filepath.Join(dir, "ca.crt")
+	err = ioutil.WriteFile(caFile, []byte(ca), 0600)
+	require.NoError(t, err)
+
+	// Without the CA, the certificate of the server is not trusted.
+	tlsConfig, err := (&ClientTLS{}).CreateTLSConfig(context.Background())
+	require.NoError(t, err)
+	assert.Error(t, getWithTLS(t, tlsConfig, server.URL))
+
+	// The CA can be given as a file, without any client certificate.
+	tlsConfig, err = (&ClientTLS{CA: caFile}).CreateTLSConfig(context.Background())
+	require.NoError(t, err)
+	assert.NoError(t, getWithTLS(t, tlsConfig, server.URL))
+
+	// Or as PEM content.
+	tlsConfig, err = (&ClientTLS{CA: ca}).CreateTLSConfig(context.Background())
+	require.NoError(t, err)
+	assert.NoError(t, getWithTLS(t, tlsConfig, server.URL))
+}
+
+func TestClientTLS_CreateTLSConfig_clientCertificate(t *testing.T) {
+	cert, key, certificate := newClientCertificate(t)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(certificate)
+
+	server, ca := newTLSServer(t, clientCAs)
+	defer server.Close()
+
+	tlsConfig, err := (&ClientTLS{CA: ca}).CreateTLSConfig(context.Background())
+	require.NoError(t, err)
+	assert.Error(t, getWithTLS(t, tlsConfig, server.URL))
+
+	tlsConfig, err = (&ClientTLS{CA: ca, Cert: cert, Key: key}).CreateTLSConfig(context.Background())
+	require.NoError(t, err)
+	assert.NoError(t, getWithTLS(t, tlsConfig, server.URL))
+}
+
+func TestClientTLS_CreateTLSConfig_invalid(t *testing.T) {
+	cert, _, _ := newClientCertificate(t)
+
+	testCases := []struct {
+		desc string
+		TLS  *ClientTLS
+	}{
+		{
+			desc: "unreadable CA file",
+			TLS:  &ClientTLS{CA: "/does/not/exist/ca.crt"},
+		},
+		{
+			desc: "CA without certificate",
+			TLS:  &ClientTLS{CA: "-----BEGIN CERTIFICATE-----\n-----END CERTIFICATE-----\n"},
+		},
+		{
+			desc: "certificate without key",
+			TLS:  &ClientTLS{Cert: cert},
+		},
+		{
+			desc: "key without certificate",
+			TLS:  &ClientTLS{Key: "/etc/traefik/client.key", InsecureSkipVerify: true},
+		},
+		{
+			desc: "unreadable key file",
+			TLS:  &ClientTLS{Cert: cert, Key: "/does/not/exist/client.key"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := test.TLS.CreateTLSConfig(context.Background())
+			assert.Error(t, err)
+		})
+	}
+}